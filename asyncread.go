@@ -0,0 +1,96 @@
+package serial
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// asyncReader runs the reads of cancelable ReadContext calls on one
+// long-lived goroutine per port
+// Starting a goroutine and a result channel for every read allocated on
+// each call, which at high baud rates adds up to noticeable GC work. The
+// goroutine is started by the first cancelable read and exits on Close.
+// As before, a read abandoned by a cancelled caller still completes into
+// that caller's buffer; the next read waits for it and discards it.
+type asyncReader struct {
+	fd  int
+	tap *trafficTap
+
+	start sync.Once
+	slot  chan struct{} // Held by the caller whose read is in flight
+	req   chan []byte
+	res   chan asyncReadResult
+	done  chan struct{}
+
+	abandoned bool // A cancelled read is still running; guarded by slot
+}
+
+type asyncReadResult struct {
+	n   int
+	at  time.Time
+	err error
+}
+
+func newAsyncReader(fd int, tap *trafficTap) *asyncReader {
+	return &asyncReader{
+		fd:   fd,
+		tap:  tap,
+		slot: make(chan struct{}, 1),
+		req:  make(chan []byte),
+		res:  make(chan asyncReadResult, 1),
+		done: make(chan struct{}),
+	}
+}
+
+// read reads into buf on the reader goroutine until the read returns or
+// ctx is done
+func (r *asyncReader) read(ctx context.Context, buf []byte) (int, time.Time, error) {
+	select {
+	case r.slot <- struct{}{}:
+	case <-ctx.Done():
+		return 0, time.Time{}, ctx.Err()
+	}
+	defer func() { <-r.slot }()
+
+	r.start.Do(func() { go r.loop() })
+
+	if r.abandoned {
+		select {
+		case <-r.res:
+			r.abandoned = false
+		case <-ctx.Done():
+			return 0, time.Time{}, ctx.Err()
+		}
+	}
+
+	r.req <- buf
+	select {
+	case res := <-r.res:
+		return res.n, res.at, res.err
+	case <-ctx.Done():
+		r.abandoned = true
+		return 0, time.Time{}, ctx.Err()
+	}
+}
+
+func (r *asyncReader) loop() {
+	for {
+		select {
+		case buf := <-r.req:
+			n, err := readFD(r.fd, buf)
+			at := time.Now()
+			if n > 0 {
+				r.tap.rx(buf[:n])
+			}
+			r.res <- asyncReadResult{n: n, at: at, err: err}
+		case <-r.done:
+			return
+		}
+	}
+}
+
+// stop ends the goroutine once a read still in flight returns
+func (r *asyncReader) stop() {
+	close(r.done)
+}
@@ -0,0 +1,97 @@
+package serial
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func openPTYPort(t *testing.T, opts ...Option) (Port, func([]byte)) {
+	t.Helper()
+	master, slavePath, err := OpenPTY()
+	if err != nil {
+		t.Skipf("PTY not available: %v", err)
+	}
+	t.Cleanup(func() { master.Close() })
+
+	port, err := Open(slavePath, opts...)
+	if err != nil {
+		t.Fatalf("Open(%s) failed: %v", slavePath, err)
+	}
+	t.Cleanup(func() { port.Close() })
+	return port, func(data []byte) {
+		if _, err := master.Write(data); err != nil {
+			t.Fatalf("master write failed: %v", err)
+		}
+	}
+}
+
+func TestReadAllocations(t *testing.T) {
+	port, send := openPTYPort(t, WithReadTimeout(500*time.Millisecond))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	buf := make([]byte, 64)
+	x := []byte("x")
+	tests := []struct {
+		name string
+		read func() (int, error)
+	}{
+		{"Read", func() (int, error) { return port.Read(buf) }},
+		{"ReadContext", func() (int, error) { return port.ReadContext(ctx, buf) }},
+		{"ReadTimestamped", func() (int, error) {
+			n, _, err := ReadTimestamped(ctx, port, buf)
+			return n, err
+		}},
+	}
+	for _, tt := range tests {
+		// Starts the reader goroutine outside the measurement
+		send(x)
+		tt.read()
+		allocs := testing.AllocsPerRun(100, func() {
+			send(x)
+			if n, err := tt.read(); n != 1 || err != nil {
+				t.Fatalf("%s = %d, %v", tt.name, n, err)
+			}
+		})
+		if allocs > 0 {
+			t.Errorf("%s allocated %.1f times per read, want 0", tt.name, allocs)
+		}
+	}
+}
+
+func TestReadContextAfterCancel(t *testing.T) {
+	port, send := openPTYPort(t, WithReadTimeout(200*time.Millisecond))
+
+	// The cancelled read keeps running until the read timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := port.ReadContext(ctx, make([]byte, 16)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ReadContext = %v, want DeadlineExceeded", err)
+	}
+
+	// Data arriving while it runs goes to the abandoned buffer, as with
+	// any abandoned read; later reads work as usual
+	time.Sleep(300 * time.Millisecond)
+	send([]byte("ok"))
+	buf := make([]byte, 16)
+	var got []byte
+	for deadline := time.Now().Add(time.Second); len(got) < 2 && time.Now().Before(deadline); {
+		n, err := port.ReadContext(context.Background(), buf)
+		if err != nil {
+			t.Fatalf("ReadContext failed: %v", err)
+		}
+		got = append(got, buf[:n]...)
+	}
+	if string(got) != "ok" {
+		t.Errorf("read %q after a cancelled read, want \"ok\"", got)
+	}
+
+	if err := port.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if _, err := port.ReadContext(context.Background(), buf); !errors.Is(err, ErrPortClosed) {
+		t.Errorf("ReadContext after Close = %v, want ErrPortClosed", err)
+	}
+}
@@ -0,0 +1,417 @@
+// Package at implements an AT command engine for cellular and other Hayes-style modems.
+//
+// A Client owns the read side of the port: it splits incoming data into lines,
+// matches them to the command currently in flight, and routes unsolicited
+// result codes (URCs such as "+CREG: 1" or "RING") to subscribers.
+//
+//	port, _ := serial.Open("/dev/ttyUSB2", serial.WithReadTimeout(100*time.Millisecond))
+//	client := at.New(port)
+//	defer client.Close()
+//
+//	resp, err := client.SendCommand(ctx, "AT+CSQ")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Println(resp.Value("+CSQ")) // "23,99"
+package at
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Predefined errors for AT command handling
+var (
+	ErrCommandFailed = errors.New("AT command failed")
+	ErrTimeout       = errors.New("AT command timed out")
+	ErrClosed        = errors.New("AT client is closed")
+)
+
+// DefaultTimeout is used for commands when no WithTimeout option is given
+const DefaultTimeout = 5 * time.Second
+
+// urcBufferSize is the per-subscriber channel capacity; URCs are dropped when a subscriber falls behind
+const urcBufferSize = 16
+
+// Error describes a failing final result code
+// errors.Is(err, ErrCommandFailed) reports true for all result code errors.
+type Error struct {
+	Result string // Final result code (e.g., "ERROR", "+CME ERROR", "NO CARRIER")
+	Code   int    // Numeric error code for +CME/+CMS errors (-1 if textual or absent)
+	Text   string // Error text for +CME/+CMS errors in verbose mode
+}
+
+func (e *Error) Error() string {
+	switch {
+	case e.Code >= 0:
+		return fmt.Sprintf("%s: %d", e.Result, e.Code)
+	case e.Text != "":
+		return fmt.Sprintf("%s: %s", e.Result, e.Text)
+	default:
+		return e.Result
+	}
+}
+
+// Unwrap allows errors.Is(err, ErrCommandFailed)
+func (e *Error) Unwrap() error {
+	return ErrCommandFailed
+}
+
+// Response holds the information lines and final result code of a command
+type Response struct {
+	Lines  []string // Information response lines (echo and URCs excluded)
+	Result string   // Final result code (e.g., "OK", "CONNECT")
+}
+
+// Value returns the payload of the first line starting with prefix followed by ':'
+// For "+CSQ: 23,99", Value("+CSQ") returns "23,99". Returns "" if no line matches.
+func (r *Response) Value(prefix string) string {
+	for _, line := range r.Lines {
+		if rest, ok := strings.CutPrefix(line, prefix+":"); ok {
+			return strings.TrimSpace(rest)
+		}
+	}
+	return ""
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithTimeout sets the default command timeout
+// A context deadline shorter than this timeout takes precedence.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// Client sends AT commands and dispatches unsolicited result codes
+type Client struct {
+	rw      io.ReadWriter
+	timeout time.Duration
+
+	cmdMu sync.Mutex // Serializes commands; only one may be in flight
+
+	mu      sync.Mutex
+	pending *pendingCommand
+	subs    map[int]*subscription
+	nextSub int
+	closed  bool
+	err     error
+	done    chan struct{}
+}
+
+type pendingCommand struct {
+	echo   string // Command text as echoed by the modem (ATE1)
+	prefix string // Response prefix for this command (e.g., "+CSQ")
+	lines  []string
+	result chan commandResult
+}
+
+type commandResult struct {
+	resp *Response
+	err  error
+}
+
+type subscription struct {
+	prefix string
+	ch     chan string
+}
+
+// New creates a Client and starts reading from rw in a background goroutine
+// The Client expects exclusive read access. rw should be opened with a read
+// timeout so the reader goroutine can observe Close.
+func New(rw io.ReadWriter, opts ...Option) *Client {
+	c := &Client{
+		rw:      rw,
+		timeout: DefaultTimeout,
+		subs:    make(map[int]*subscription),
+		done:    make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	go c.readLoop()
+
+	return c
+}
+
+// SendCommand writes cmd terminated by CR and waits for its final result code
+// On ERROR, +CME ERROR or +CMS ERROR the returned error is an *Error and the
+// Response still contains any information lines received.
+func (c *Client) SendCommand(ctx context.Context, cmd string) (*Response, error) {
+	c.cmdMu.Lock()
+	defer c.cmdMu.Unlock()
+
+	p := &pendingCommand{
+		echo:   cmd,
+		prefix: ResponsePrefix(cmd),
+		result: make(chan commandResult, 1),
+	}
+
+	c.mu.Lock()
+	if c.closed {
+		err := c.err
+		c.mu.Unlock()
+		if err == nil {
+			err = ErrClosed
+		}
+		return nil, err
+	}
+	c.pending = p
+	c.mu.Unlock()
+
+	if _, err := c.rw.Write([]byte(cmd + "\r")); err != nil {
+		c.clearPending(p)
+		return nil, fmt.Errorf("failed to write command: %w", err)
+	}
+
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+
+	select {
+	case res := <-p.result:
+		return res.resp, res.err
+	case <-timer.C:
+		c.clearPending(p)
+		return nil, fmt.Errorf("%w: %s", ErrTimeout, cmd)
+	case <-ctx.Done():
+		c.clearPending(p)
+		return nil, ctx.Err()
+	}
+}
+
+func (c *Client) clearPending(p *pendingCommand) {
+	c.mu.Lock()
+	if c.pending == p {
+		c.pending = nil
+	}
+	c.mu.Unlock()
+}
+
+// Subscribe registers for unsolicited result codes starting with prefix (e.g., "+CREG", "RING")
+// An empty prefix receives every line not belonging to a command.
+// The returned function cancels the subscription and closes the channel.
+func (c *Client) Subscribe(prefix string) (<-chan string, func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	sub := &subscription{prefix: prefix, ch: make(chan string, urcBufferSize)}
+	if c.closed {
+		close(sub.ch)
+		return sub.ch, func() {}
+	}
+
+	id := c.nextSub
+	c.nextSub++
+	c.subs[id] = sub
+
+	var once sync.Once
+	return sub.ch, func() {
+		once.Do(func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			if _, ok := c.subs[id]; ok {
+				delete(c.subs, id)
+				close(sub.ch)
+			}
+		})
+	}
+}
+
+// Close stops the client, fails any command in flight and closes all subscriptions
+// Close does not close the underlying port.
+func (c *Client) Close() error {
+	c.shutdown(ErrClosed)
+	return nil
+}
+
+// Done returns a channel that is closed when the client stops reading
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns the error that stopped the client, if any
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+func (c *Client) shutdown(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+	c.closed = true
+	c.err = err
+
+	if c.pending != nil {
+		c.pending.result <- commandResult{err: err}
+		c.pending = nil
+	}
+	for id, sub := range c.subs {
+		close(sub.ch)
+		delete(c.subs, id)
+	}
+	close(c.done)
+}
+
+func (c *Client) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+// readLoop splits incoming data into lines and dispatches them
+// Zero-length reads (serial VTIME timeouts) are tolerated.
+func (c *Client) readLoop() {
+	buf := make([]byte, 0, 512)
+	chunk := make([]byte, 256)
+
+	for {
+		n, err := c.rw.Read(chunk)
+		if c.isClosed() {
+			return
+		}
+		if n > 0 {
+			buf = append(buf, chunk[:n]...)
+			for {
+				idx := bytes.IndexAny(buf, "\r\n")
+				if idx == -1 {
+					break
+				}
+				line := strings.TrimSpace(string(buf[:idx]))
+				buf = buf[:copy(buf, buf[idx+1:])]
+				if line != "" {
+					c.handleLine(line)
+				}
+			}
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			c.shutdown(err)
+			return
+		}
+	}
+}
+
+func (c *Client) handleLine(line string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p := c.pending
+	if p == nil {
+		c.dispatchURC(line)
+		return
+	}
+
+	if line == p.echo {
+		return
+	}
+
+	if final, err := parseFinalResult(line); final {
+		p.result <- commandResult{
+			resp: &Response{Lines: p.lines, Result: line},
+			err:  err,
+		}
+		c.pending = nil
+		return
+	}
+
+	// Lines matching a subscription but not this command's own response are URCs
+	if !hasResponsePrefix(line, p.prefix) && c.matchesSubscription(line) {
+		c.dispatchURC(line)
+		return
+	}
+
+	p.lines = append(p.lines, line)
+}
+
+func (c *Client) matchesSubscription(line string) bool {
+	for _, sub := range c.subs {
+		if sub.prefix != "" && strings.HasPrefix(line, sub.prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// dispatchURC delivers a line to all matching subscribers without blocking the reader
+func (c *Client) dispatchURC(line string) {
+	for _, sub := range c.subs {
+		if !strings.HasPrefix(line, sub.prefix) {
+			continue
+		}
+		select {
+		case sub.ch <- line:
+		default:
+			// Subscriber is not keeping up; drop rather than stall command handling
+		}
+	}
+}
+
+// ResponsePrefix derives the information response prefix from a command
+// "AT+CSQ" -> "+CSQ", "AT+CREG?" -> "+CREG", "AT+COPS=0" -> "+COPS", "ATI" -> ""
+func ResponsePrefix(cmd string) string {
+	upper := strings.ToUpper(cmd)
+	if !strings.HasPrefix(upper, "AT") || len(cmd) < 3 {
+		return ""
+	}
+	rest := cmd[2:]
+	if rest[0] != '+' && rest[0] != '$' && rest[0] != '^' && rest[0] != '#' {
+		return ""
+	}
+	end := strings.IndexAny(rest, "=?")
+	if end == -1 {
+		end = len(rest)
+	}
+	return rest[:end]
+}
+
+func hasResponsePrefix(line, prefix string) bool {
+	return prefix != "" && strings.HasPrefix(line, prefix+":")
+}
+
+// parseFinalResult reports whether line is a final result code and the error it represents
+func parseFinalResult(line string) (bool, error) {
+	switch line {
+	case "OK":
+		return true, nil
+	case "ERROR", "NO CARRIER", "BUSY", "NO ANSWER", "NO DIALTONE":
+		return true, &Error{Result: line, Code: -1}
+	}
+
+	if line == "CONNECT" || strings.HasPrefix(line, "CONNECT ") {
+		return true, nil
+	}
+
+	for _, result := range []string{"+CME ERROR", "+CMS ERROR"} {
+		rest, ok := strings.CutPrefix(line, result+":")
+		if !ok {
+			continue
+		}
+		rest = strings.TrimSpace(rest)
+		e := &Error{Result: result, Code: -1}
+		if code, err := strconv.Atoi(rest); err == nil {
+			e.Code = code
+		} else {
+			e.Text = rest
+		}
+		return true, e
+	}
+
+	return false, nil
+}
@@ -0,0 +1,247 @@
+package at
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeModem answers commands written by the client using a response table
+type fakeModem struct {
+	toClient  *io.PipeWriter
+	fromModem *io.PipeReader
+	responses map[string]string
+	echo      bool
+	written   chan string
+}
+
+func newFakeModem(responses map[string]string, echo bool) *fakeModem {
+	r, w := io.Pipe()
+	return &fakeModem{
+		toClient:  w,
+		fromModem: r,
+		responses: responses,
+		echo:      echo,
+		written:   make(chan string, 16),
+	}
+}
+
+func (m *fakeModem) Read(p []byte) (int, error) {
+	return m.fromModem.Read(p)
+}
+
+func (m *fakeModem) Write(p []byte) (int, error) {
+	cmd := strings.TrimRight(string(p), "\r")
+	m.written <- cmd
+	go func() {
+		if m.echo {
+			m.send(cmd + "\r")
+		}
+		if resp, ok := m.responses[cmd]; ok {
+			m.send(resp)
+		}
+	}()
+	return len(p), nil
+}
+
+func (m *fakeModem) send(data string) {
+	m.toClient.Write([]byte(data))
+}
+
+func TestSendCommandOK(t *testing.T) {
+	modem := newFakeModem(map[string]string{
+		"AT+CSQ": "\r\n+CSQ: 23,99\r\n\r\nOK\r\n",
+	}, true)
+	client := New(modem)
+	defer client.Close()
+
+	resp, err := client.SendCommand(context.Background(), "AT+CSQ")
+	if err != nil {
+		t.Fatalf("SendCommand failed: %v", err)
+	}
+	if resp.Result != "OK" {
+		t.Errorf("Result = %q, want OK", resp.Result)
+	}
+	if len(resp.Lines) != 1 {
+		t.Fatalf("Lines = %q, want one line (echo excluded)", resp.Lines)
+	}
+	if got := resp.Value("+CSQ"); got != "23,99" {
+		t.Errorf("Value(+CSQ) = %q, want 23,99", got)
+	}
+	if got := <-modem.written; got != "AT+CSQ" {
+		t.Errorf("written = %q, want AT+CSQ", got)
+	}
+}
+
+func TestSendCommandErrors(t *testing.T) {
+	modem := newFakeModem(map[string]string{
+		"AT+FAIL":  "\r\nERROR\r\n",
+		"AT+CPIN?": "\r\n+CME ERROR: 10\r\n",
+		"AT+CMGS":  "\r\n+CMS ERROR: invalid PDU mode\r\n",
+	}, false)
+	client := New(modem)
+	defer client.Close()
+
+	tests := []struct {
+		cmd    string
+		result string
+		code   int
+		text   string
+	}{
+		{"AT+FAIL", "ERROR", -1, ""},
+		{"AT+CPIN?", "+CME ERROR", 10, ""},
+		{"AT+CMGS", "+CMS ERROR", -1, "invalid PDU mode"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cmd, func(t *testing.T) {
+			resp, err := client.SendCommand(context.Background(), tt.cmd)
+			if !errors.Is(err, ErrCommandFailed) {
+				t.Fatalf("error = %v, want ErrCommandFailed", err)
+			}
+			var atErr *Error
+			if !errors.As(err, &atErr) {
+				t.Fatalf("error %T is not *Error", err)
+			}
+			if atErr.Result != tt.result || atErr.Code != tt.code || atErr.Text != tt.text {
+				t.Errorf("Error = %+v, want {%s %d %s}", atErr, tt.result, tt.code, tt.text)
+			}
+			if resp == nil {
+				t.Error("expected response alongside error")
+			}
+		})
+	}
+}
+
+func TestSendCommandTimeout(t *testing.T) {
+	modem := newFakeModem(map[string]string{}, false)
+	client := New(modem, WithTimeout(50*time.Millisecond))
+	defer client.Close()
+
+	_, err := client.SendCommand(context.Background(), "AT+SLOW")
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("error = %v, want ErrTimeout", err)
+	}
+}
+
+func TestSendCommandContext(t *testing.T) {
+	modem := newFakeModem(map[string]string{}, false)
+	client := New(modem)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := client.SendCommand(ctx, "AT")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestURCRouting(t *testing.T) {
+	modem := newFakeModem(map[string]string{
+		// A +CREG URC arrives while AT+CSQ is in flight
+		"AT+CSQ": "\r\n+CREG: 5\r\n+CSQ: 20,0\r\nOK\r\n",
+		// +CREG lines are the command's own response here, not URCs
+		"AT+CREG?": "\r\n+CREG: 0,1\r\nOK\r\n",
+	}, false)
+	client := New(modem)
+	defer client.Close()
+
+	creg, cancel := client.Subscribe("+CREG")
+	defer cancel()
+	ring, cancelRing := client.Subscribe("RING")
+	defer cancelRing()
+
+	resp, err := client.SendCommand(context.Background(), "AT+CSQ")
+	if err != nil {
+		t.Fatalf("SendCommand failed: %v", err)
+	}
+	if len(resp.Lines) != 1 || resp.Lines[0] != "+CSQ: 20,0" {
+		t.Errorf("Lines = %q, want [+CSQ: 20,0]", resp.Lines)
+	}
+
+	select {
+	case urc := <-creg:
+		if urc != "+CREG: 5" {
+			t.Errorf("URC = %q, want +CREG: 5", urc)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("URC not delivered")
+	}
+
+	resp, err = client.SendCommand(context.Background(), "AT+CREG?")
+	if err != nil {
+		t.Fatalf("SendCommand failed: %v", err)
+	}
+	if resp.Value("+CREG") != "0,1" {
+		t.Errorf("Value(+CREG) = %q, want 0,1", resp.Value("+CREG"))
+	}
+
+	// URC outside of any command
+	modem.send("\r\nRING\r\n")
+	select {
+	case urc := <-ring:
+		if urc != "RING" {
+			t.Errorf("URC = %q, want RING", urc)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("RING not delivered")
+	}
+
+	select {
+	case urc := <-creg:
+		t.Errorf("unexpected URC %q", urc)
+	default:
+	}
+}
+
+func TestClose(t *testing.T) {
+	modem := newFakeModem(map[string]string{}, false)
+	client := New(modem)
+
+	ch, _ := client.Subscribe("")
+	client.Close()
+
+	if _, ok := <-ch; ok {
+		t.Error("subscription channel not closed")
+	}
+	if _, err := client.SendCommand(context.Background(), "AT"); !errors.Is(err, ErrClosed) {
+		t.Errorf("SendCommand after Close = %v, want ErrClosed", err)
+	}
+}
+
+func TestReaderEOF(t *testing.T) {
+	modem := newFakeModem(map[string]string{}, false)
+	client := New(modem)
+
+	modem.toClient.Close()
+	select {
+	case <-client.Done():
+	case <-time.After(time.Second):
+		t.Fatal("client did not stop on EOF")
+	}
+	if !errors.Is(client.Err(), io.ErrUnexpectedEOF) {
+		t.Errorf("Err() = %v, want io.ErrUnexpectedEOF", client.Err())
+	}
+}
+
+func TestResponsePrefix(t *testing.T) {
+	tests := map[string]string{
+		"AT+CSQ":     "+CSQ",
+		"AT+CREG?":   "+CREG",
+		"AT+COPS=0":  "+COPS",
+		"AT+CGDCONT": "+CGDCONT",
+		"ATI":        "",
+		"AT":         "",
+		"at+csq":     "+csq",
+	}
+	for cmd, want := range tests {
+		if got := ResponsePrefix(cmd); got != want {
+			t.Errorf("ResponsePrefix(%q) = %q, want %q", cmd, got, want)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package serial
+
+import "time"
+
+// Breaker is implemented by ports that can transmit a break condition
+type Breaker interface {
+	// SendBreak holds the TX line in the spacing state for duration
+	SendBreak(duration time.Duration) error
+}
+
+// SendBreak sends a break of the given duration on p after pending output
+// has been transmitted
+// Bootloaders and some RS-485 protocols use a break to mark the start of a
+// frame or to request attention. Returns ErrNotSupported if p cannot send
+// breaks, such as a raw TCP port.
+func SendBreak(p Port, duration time.Duration) error {
+	if duration <= 0 {
+		return ErrInvalidConfig
+	}
+	if b, ok := p.(Breaker); ok {
+		return b.SendBreak(duration)
+	}
+	return ErrNotSupported
+}
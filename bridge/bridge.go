@@ -0,0 +1,551 @@
+// Package bridge pumps data between a serial port and TCP connections
+//
+// A Bridge owns the serial port: it opens it through an Opener, forwards
+// received bytes to every attached client and writes client input back to the
+// port. When the port fails it is closed and reopened after a delay, so a USB
+// adapter can be unplugged and replugged without restarting the bridge.
+//
+//	b := bridge.New(func() (serial.Port, error) {
+//	    return serial.Open("/dev/ttyUSB0", serial.WithBaudRate(9600), serial.WithReadTimeout(100*time.Millisecond))
+//	}, bridge.WithPolicy(bridge.PolicyBroadcast))
+//
+//	l, _ := net.Listen("tcp", ":5000")
+//	err := b.Serve(ctx, l)
+//
+// The port should be opened with a read timeout so the bridge can observe
+// cancellation between reads.
+//
+// For receive-only telemetry, UDPStreamer sends each frame from a
+// framing.PacketPort as one datagram, optionally prefixed with a sequence
+// number and receive timestamp.
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/allbin/go-serial"
+)
+
+// Default values, overridable with options
+const (
+	DefaultReadBufferSize = 4096
+	DefaultClientQueue    = 64 // Chunks queued per client before data is dropped
+	DefaultReconnectDelay = time.Second
+	dialTimeout           = 10 * time.Second
+)
+
+// Predefined errors for client connections
+var (
+	ErrRejected = errors.New("bridge already has a client")
+	ErrReplaced = errors.New("replaced by a new client")
+)
+
+// Policy decides which clients may attach and transmit
+type Policy int
+
+const (
+	// PolicyExclusive allows a single client; further connections are refused
+	PolicyExclusive Policy = iota
+	// PolicyBroadcast sends received data to all clients; only the
+	// longest-connected client writes to the port, input from others is discarded
+	PolicyBroadcast
+	// PolicyTakeover allows a single client; a new connection disconnects it
+	PolicyTakeover
+)
+
+// String returns the policy name as accepted by ParsePolicy
+func (p Policy) String() string {
+	switch p {
+	case PolicyExclusive:
+		return "exclusive"
+	case PolicyBroadcast:
+		return "broadcast"
+	case PolicyTakeover:
+		return "takeover"
+	default:
+		return fmt.Sprintf("Policy(%d)", int(p))
+	}
+}
+
+// ParsePolicy converts a policy name to a Policy
+func ParsePolicy(name string) (Policy, error) {
+	switch name {
+	case "exclusive":
+		return PolicyExclusive, nil
+	case "broadcast":
+		return PolicyBroadcast, nil
+	case "takeover":
+		return PolicyTakeover, nil
+	default:
+		return 0, fmt.Errorf("unknown bridge policy %q (want exclusive, broadcast or takeover)", name)
+	}
+}
+
+// EventKind identifies a bridge lifecycle event
+type EventKind int
+
+const (
+	EventPortOpened EventKind = iota
+	EventPortError
+	EventClientConnected
+	EventClientDisconnected
+	EventClientRejected
+	EventDialError
+)
+
+// String returns a short description of the event kind
+func (k EventKind) String() string {
+	switch k {
+	case EventPortOpened:
+		return "port opened"
+	case EventPortError:
+		return "port error"
+	case EventClientConnected:
+		return "client connected"
+	case EventClientDisconnected:
+		return "client disconnected"
+	case EventClientRejected:
+		return "client rejected"
+	case EventDialError:
+		return "dial error"
+	default:
+		return fmt.Sprintf("EventKind(%d)", int(k))
+	}
+}
+
+// Event reports a change in the bridge's port or clients
+type Event struct {
+	Kind EventKind
+	Addr string // Client remote address, empty for port events
+	Err  error
+}
+
+// Stats are cumulative bridge counters
+type Stats struct {
+	RXBytes    uint64 // Read from the port
+	TXBytes    uint64 // Written to the port
+	Dropped    uint64 // RX bytes discarded for clients whose queue was full, or with no client attached
+	Discarded  uint64 // Client bytes not written: not the writer, or no port open
+	Clients    int    // Currently attached
+	PortOpens  uint64 // Successful port opens, including the first
+	Rejections uint64
+}
+
+// Opener opens the serial port; it is called again to reconnect after a port error
+type Opener func() (serial.Port, error)
+
+// Option configures a Bridge
+type Option func(*Bridge)
+
+// WithPolicy sets the multiple-client policy
+func WithPolicy(policy Policy) Option {
+	return func(b *Bridge) {
+		b.policy = policy
+	}
+}
+
+// WithReadBufferSize sets the size of port reads
+func WithReadBufferSize(size int) Option {
+	return func(b *Bridge) {
+		b.readSize = size
+	}
+}
+
+// WithClientQueue sets how many received chunks are queued for each client
+// A client that falls further behind misses data instead of stalling the others.
+func WithClientQueue(chunks int) Option {
+	return func(b *Bridge) {
+		b.queue = chunks
+	}
+}
+
+// WithReconnectDelay sets the wait before reopening the port or redialing
+func WithReconnectDelay(delay time.Duration) Option {
+	return func(b *Bridge) {
+		b.reconnectDelay = delay
+	}
+}
+
+// WithMaxClients limits the number of clients under PolicyBroadcast
+// Zero, the default, allows any number.
+func WithMaxClients(n int) Option {
+	return func(b *Bridge) {
+		b.maxClients = n
+	}
+}
+
+// WithBanner sends banner to each client when it connects
+func WithBanner(banner []byte) Option {
+	return func(b *Bridge) {
+		b.banner = banner
+	}
+}
+
+// WithEventHandler registers fn to be called for port and client events
+// fn is called synchronously and must not block.
+func WithEventHandler(fn func(Event)) Option {
+	return func(b *Bridge) {
+		b.onEvent = fn
+	}
+}
+
+// Bridge connects one serial port to TCP clients
+// A Bridge runs one Serve or Dial at a time.
+type Bridge struct {
+	open           Opener
+	policy         Policy
+	readSize       int
+	queue          int
+	maxClients     int
+	banner         []byte
+	reconnectDelay time.Duration
+	onEvent        func(Event)
+
+	mu      sync.Mutex
+	port    serial.Port
+	clients []*client // In attach order; clients[0] is the writer under PolicyBroadcast
+	stats   Stats
+}
+
+// client is one attached connection
+type client struct {
+	conn     net.Conn
+	out      chan []byte
+	done     chan struct{}
+	once     sync.Once
+	replaced atomic.Bool // Disconnected by PolicyTakeover
+}
+
+// New creates a Bridge that opens its port with open
+func New(open Opener, opts ...Option) *Bridge {
+	b := &Bridge{
+		open:           open,
+		policy:         PolicyExclusive,
+		readSize:       DefaultReadBufferSize,
+		queue:          DefaultClientQueue,
+		reconnectDelay: DefaultReconnectDelay,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// Stats returns a snapshot of the bridge counters
+func (b *Bridge) Stats() Stats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats := b.stats
+	stats.Clients = len(b.clients)
+	return stats
+}
+
+// Serve accepts clients from l until ctx is cancelled or l fails
+// The listener is closed when Serve returns.
+func (b *Bridge) Serve(ctx context.Context, l net.Listener) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		b.runPort(ctx)
+	}()
+
+	go func() {
+		<-ctx.Done()
+		l.Close()
+	}()
+
+	var err error
+	for {
+		conn, aerr := l.Accept()
+		if aerr != nil {
+			if ctx.Err() == nil {
+				err = aerr
+			}
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b.handle(ctx, conn)
+		}()
+	}
+
+	cancel()
+	b.closeClients()
+	wg.Wait()
+	return err
+}
+
+// Dial connects to addr and bridges the connection, redialing whenever it drops
+// Runs until ctx is cancelled and returns ctx.Err().
+func (b *Bridge) Dial(ctx context.Context, addr string) error {
+	ctx, cancel := context.WithCancel(ctx)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		b.runPort(ctx)
+	}()
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	dialer := net.Dialer{Timeout: dialTimeout}
+	for {
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
+		if err == nil {
+			b.handle(ctx, conn)
+		} else if ctx.Err() == nil {
+			b.emit(Event{Kind: EventDialError, Addr: addr, Err: err})
+		}
+
+		if !sleep(ctx, b.reconnectDelay) {
+			return ctx.Err()
+		}
+	}
+}
+
+// handle attaches conn and pumps its traffic until either side closes
+func (b *Bridge) handle(ctx context.Context, conn net.Conn) {
+	c := &client{conn: conn, out: make(chan []byte, b.queue), done: make(chan struct{})}
+	addr := conn.RemoteAddr().String()
+
+	if !b.attach(c) {
+		conn.Close()
+		b.emit(Event{Kind: EventClientRejected, Addr: addr, Err: ErrRejected})
+		return
+	}
+	b.emit(Event{Kind: EventClientConnected, Addr: addr})
+
+	if len(b.banner) > 0 {
+		conn.Write(b.banner)
+	}
+	go b.writeClient(c)
+
+	// Stop reading when the bridge shuts down
+	stop := context.AfterFunc(ctx, c.close)
+	defer stop()
+
+	err := b.readClient(c)
+	b.detach(c)
+	c.close()
+	if err == nil && c.replaced.Load() {
+		err = ErrReplaced
+	}
+	b.emit(Event{Kind: EventClientDisconnected, Addr: addr, Err: err})
+}
+
+// attach adds c to the client list if the policy allows it
+func (b *Bridge) attach(c *client) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch {
+	case b.policy == PolicyExclusive && len(b.clients) > 0,
+		b.policy == PolicyBroadcast && b.maxClients > 0 && len(b.clients) >= b.maxClients:
+		b.stats.Rejections++
+		return false
+	case b.policy == PolicyTakeover:
+		for _, old := range b.clients {
+			old.replaced.Store(true)
+			old.close()
+		}
+		b.clients = nil
+	}
+	b.clients = append(b.clients, c)
+	return true
+}
+
+func (b *Bridge) detach(c *client) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for i, other := range b.clients {
+		if other == c {
+			b.clients = append(b.clients[:i], b.clients[i+1:]...)
+			return
+		}
+	}
+}
+
+func (b *Bridge) closeClients() {
+	b.mu.Lock()
+	clients := append([]*client(nil), b.clients...)
+	b.mu.Unlock()
+
+	for _, c := range clients {
+		c.close()
+	}
+}
+
+func (c *client) close() {
+	c.once.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+}
+
+// readClient writes client input to the port while c is the writer
+// Returns nil when the client disconnects cleanly.
+func (b *Bridge) readClient(c *client) error {
+	buf := make([]byte, b.readSize)
+	for {
+		n, err := c.conn.Read(buf)
+		if n > 0 {
+			b.writePort(c, buf[:n])
+		}
+		if err != nil {
+			select {
+			case <-c.done:
+				return nil
+			default:
+			}
+			if errors.Is(err, net.ErrClosed) || errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// writeClient sends queued port data to the client
+func (b *Bridge) writeClient(c *client) {
+	for {
+		select {
+		case data := <-c.out:
+			if _, err := c.conn.Write(data); err != nil {
+				c.close()
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// writePort writes client data to the port if c may transmit
+func (b *Bridge) writePort(c *client, data []byte) {
+	b.mu.Lock()
+	port := b.port
+	writer := len(b.clients) > 0 && b.clients[0] == c
+	if port == nil || !writer {
+		b.stats.Discarded += uint64(len(data))
+		b.mu.Unlock()
+		return
+	}
+	b.mu.Unlock()
+
+	n, err := port.Write(data)
+
+	b.mu.Lock()
+	b.stats.TXBytes += uint64(n)
+	b.stats.Discarded += uint64(len(data) - n)
+	b.mu.Unlock()
+
+	if err != nil {
+		// The port loop notices the failure on its next read and reopens
+		b.emit(Event{Kind: EventPortError, Err: fmt.Errorf("write: %w", err)})
+	}
+}
+
+// runPort keeps the port open and distributes received data until ctx is done
+func (b *Bridge) runPort(ctx context.Context) {
+	buf := make([]byte, b.readSize)
+	for {
+		port, err := b.open()
+		if err != nil {
+			b.emit(Event{Kind: EventPortError, Err: err})
+			if !sleep(ctx, b.reconnectDelay) {
+				return
+			}
+			continue
+		}
+
+		b.mu.Lock()
+		b.port = port
+		b.stats.PortOpens++
+		b.mu.Unlock()
+		b.emit(Event{Kind: EventPortOpened})
+
+		err = b.pumpPort(ctx, port, buf)
+
+		b.mu.Lock()
+		b.port = nil
+		b.mu.Unlock()
+		port.Close()
+
+		if ctx.Err() != nil {
+			return
+		}
+		b.emit(Event{Kind: EventPortError, Err: err})
+		if !sleep(ctx, b.reconnectDelay) {
+			return
+		}
+	}
+}
+
+// pumpPort reads from port until it fails or ctx is done
+func (b *Bridge) pumpPort(ctx context.Context, port serial.Port, buf []byte) error {
+	for {
+		n, err := port.ReadContext(ctx, buf)
+		if n > 0 {
+			b.broadcast(append([]byte(nil), buf[:n]...))
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// broadcast queues data for every attached client
+func (b *Bridge) broadcast(data []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.stats.RXBytes += uint64(len(data))
+	if len(b.clients) == 0 {
+		b.stats.Dropped += uint64(len(data))
+		return
+	}
+	for _, c := range b.clients {
+		select {
+		case c.out <- data:
+		default:
+			b.stats.Dropped += uint64(len(data))
+		}
+	}
+}
+
+func (b *Bridge) emit(ev Event) {
+	if b.onEvent != nil {
+		b.onEvent(ev)
+	}
+}
+
+// sleep waits for d, returning false if ctx is cancelled first
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,291 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/allbin/go-serial"
+)
+
+// ptyOpener returns the PTY master (the "device" side) and an Opener for the slave
+func ptyOpener(t *testing.T) (*os.File, Opener) {
+	t.Helper()
+
+	master, slavePath, err := serial.OpenPTY()
+	if err != nil {
+		t.Skipf("PTY not available: %v", err)
+	}
+	t.Cleanup(func() { master.Close() })
+
+	return master, func() (serial.Port, error) {
+		return serial.Open(slavePath, serial.WithReadTimeout(100*time.Millisecond))
+	}
+}
+
+// startServe runs b.Serve on a loopback listener until the test ends
+func startServe(t *testing.T, b *Bridge) string {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("TCP listener not available: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- b.Serve(ctx, l) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Serve returned %v", err)
+		}
+	})
+
+	return l.Addr().String()
+}
+
+// waitFor polls cond until it is true or the deadline passes
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if cond() {
+			return
+		}
+	}
+	t.Fatal("condition not met within 2s")
+}
+
+// readString reads exactly len(want) bytes from r with a deadline
+func readString(t *testing.T, r io.Reader, want string) {
+	t.Helper()
+
+	got := make(chan string, 1)
+	go func() {
+		buf := make([]byte, len(want))
+		n, _ := io.ReadFull(r, buf)
+		got <- string(buf[:n])
+	}()
+
+	select {
+	case s := <-got:
+		if s != want {
+			t.Errorf("read %q, want %q", s, want)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out reading %q", want)
+	}
+}
+
+func TestBroadcastPolicy(t *testing.T) {
+	master, open := ptyOpener(t)
+	b := New(open, WithPolicy(PolicyBroadcast))
+	addr := startServe(t, b)
+	waitFor(t, func() bool { return b.Stats().PortOpens == 1 })
+
+	writer, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer writer.Close()
+	waitFor(t, func() bool { return b.Stats().Clients == 1 })
+
+	watcher, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer watcher.Close()
+	waitFor(t, func() bool { return b.Stats().Clients == 2 })
+
+	// Received data goes to every client
+	master.Write([]byte("hello"))
+	readString(t, writer, "hello")
+	readString(t, watcher, "hello")
+
+	// Only the first client transmits
+	watcher.Write([]byte("ignored"))
+	waitFor(t, func() bool { return b.Stats().Discarded == 7 })
+	writer.Write([]byte("cmd"))
+	readString(t, master, "cmd")
+
+	// The watcher takes over when the writer leaves
+	writer.Close()
+	waitFor(t, func() bool { return b.Stats().Clients == 1 })
+	watcher.Write([]byte("next"))
+	readString(t, master, "next")
+
+	waitFor(t, func() bool { return b.Stats().TXBytes == 7 })
+	if stats := b.Stats(); stats.RXBytes != 5 {
+		t.Errorf("stats = %+v, want 5 RX bytes", stats)
+	}
+}
+
+func TestExclusivePolicy(t *testing.T) {
+	_, open := ptyOpener(t)
+
+	var mu sync.Mutex
+	var events []EventKind
+	b := New(open, WithEventHandler(func(ev Event) {
+		mu.Lock()
+		events = append(events, ev.Kind)
+		mu.Unlock()
+	}))
+	addr := startServe(t, b)
+
+	first, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer first.Close()
+	waitFor(t, func() bool { return b.Stats().Clients == 1 })
+
+	second, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer second.Close()
+
+	// The second connection is closed without data
+	second.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err := second.Read(make([]byte, 1)); !errors.Is(err, io.EOF) {
+		t.Errorf("rejected client read error = %v, want EOF", err)
+	}
+	if stats := b.Stats(); stats.Rejections != 1 || stats.Clients != 1 {
+		t.Errorf("stats = %+v, want 1 rejection and 1 client", stats)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []EventKind{EventPortOpened, EventClientConnected, EventClientRejected}
+	for _, kind := range want {
+		found := false
+		for _, got := range events {
+			found = found || got == kind
+		}
+		if !found {
+			t.Errorf("events %v missing %v", events, kind)
+		}
+	}
+}
+
+func TestPortReconnect(t *testing.T) {
+	_, open := ptyOpener(t)
+
+	var attempts int
+	var mu sync.Mutex
+	flaky := func() (serial.Port, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts < 3 {
+			return nil, errors.New("device not present")
+		}
+		return open()
+	}
+
+	b := New(flaky, WithReconnectDelay(10*time.Millisecond))
+	startServe(t, b)
+
+	waitFor(t, func() bool { return b.Stats().PortOpens == 1 })
+}
+
+func TestDial(t *testing.T) {
+	master, open := ptyOpener(t)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("TCP listener not available: %v", err)
+	}
+	defer l.Close()
+
+	b := New(open, WithReconnectDelay(10*time.Millisecond))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- b.Dial(ctx, l.Addr().String()) }()
+
+	// The bridge redials after the remote end drops the connection
+	for i := 0; i < 2; i++ {
+		conn, err := l.Accept()
+		if err != nil {
+			t.Fatalf("Accept failed: %v", err)
+		}
+		waitFor(t, func() bool { return b.Stats().Clients == 1 && b.Stats().PortOpens == 1 })
+
+		conn.Write([]byte("up"))
+		readString(t, master, "up")
+		conn.Close()
+		waitFor(t, func() bool { return b.Stats().Clients == 0 })
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Dial returned %v, want context.Canceled", err)
+	}
+}
+
+func TestTakeoverPolicy(t *testing.T) {
+	master, open := ptyOpener(t)
+
+	disconnected := make(chan error, 1)
+	b := New(open, WithPolicy(PolicyTakeover), WithBanner([]byte("hi\r\n")), WithEventHandler(func(ev Event) {
+		if ev.Kind == EventClientDisconnected {
+			disconnected <- ev.Err
+		}
+	}))
+	addr := startServe(t, b)
+	waitFor(t, func() bool { return b.Stats().PortOpens == 1 })
+
+	first, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer first.Close()
+	readString(t, first, "hi\r\n")
+
+	second, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer second.Close()
+	readString(t, second, "hi\r\n")
+
+	// The first client is disconnected and the second one transmits
+	select {
+	case err := <-disconnected:
+		if !errors.Is(err, ErrReplaced) {
+			t.Errorf("disconnect error = %v, want ErrReplaced", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("first client not disconnected")
+	}
+	second.Write([]byte("new"))
+	readString(t, master, "new")
+	if stats := b.Stats(); stats.Clients != 1 {
+		t.Errorf("stats = %+v, want 1 client", stats)
+	}
+}
+
+func TestMaxClients(t *testing.T) {
+	_, open := ptyOpener(t)
+	b := New(open, WithPolicy(PolicyBroadcast), WithMaxClients(1))
+	addr := startServe(t, b)
+
+	first, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer first.Close()
+	waitFor(t, func() bool { return b.Stats().Clients == 1 })
+
+	second, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer second.Close()
+	waitFor(t, func() bool { return b.Stats().Rejections == 1 })
+}
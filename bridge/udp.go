@@ -0,0 +1,191 @@
+package bridge
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/allbin/go-serial/framing"
+)
+
+// maxDatagram is the largest UDP payload over IPv4
+const maxDatagram = 65507
+
+// Predefined errors for UDP streaming
+var (
+	ErrDatagramTooLarge = errors.New("frame does not fit in a UDP datagram")
+	ErrShortDatagram    = errors.New("datagram shorter than its header")
+)
+
+// UDPOption configures a UDPStreamer
+type UDPOption func(*UDPStreamer)
+
+// WithSequence prefixes each datagram with a 32-bit big-endian sequence
+// number, starting at 0, so receivers can detect loss and reordering
+func WithSequence() UDPOption {
+	return func(s *UDPStreamer) {
+		s.sequence = true
+	}
+}
+
+// WithTimestamp prefixes each datagram with the frame's receive time as
+// 64-bit big-endian Unix nanoseconds (after the sequence number, if any)
+func WithTimestamp() UDPOption {
+	return func(s *UDPStreamer) {
+		s.timestamp = true
+	}
+}
+
+// WithUDPErrorHandler is called for frames that could not be read or sent
+// without stopping the stream (checksum errors, oversized frames, send errors)
+func WithUDPErrorHandler(fn func(error)) UDPOption {
+	return func(s *UDPStreamer) {
+		s.onError = fn
+	}
+}
+
+// UDPStreamer sends each received frame as one UDP datagram
+// Delivery is best effort: nothing is retransmitted and there is no back
+// channel to the port.
+type UDPStreamer struct {
+	conn      net.Conn
+	sequence  bool
+	timestamp bool
+	onError   func(error)
+
+	seq    uint32
+	sent   atomic.Uint64
+	failed atomic.Uint64
+}
+
+// DialUDP creates a UDPStreamer sending to addr ("host:port")
+// Broadcast and multicast destinations work as long as the host allows them.
+func DialUDP(addr string, opts ...UDPOption) (*UDPStreamer, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open UDP socket for %s: %w", addr, err)
+	}
+	return NewUDPStreamer(conn, opts...), nil
+}
+
+// NewUDPStreamer sends datagrams over an existing connection
+func NewUDPStreamer(conn net.Conn, opts ...UDPOption) *UDPStreamer {
+	s := &UDPStreamer{conn: conn}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Send transmits one frame received at at
+// Send is not safe for concurrent use.
+func (s *UDPStreamer) Send(frame []byte, at time.Time) error {
+	datagram := make([]byte, 0, s.headerSize()+len(frame))
+	if s.sequence {
+		datagram = binary.BigEndian.AppendUint32(datagram, s.seq)
+	}
+	if s.timestamp {
+		datagram = binary.BigEndian.AppendUint64(datagram, uint64(at.UnixNano()))
+	}
+	datagram = append(datagram, frame...)
+
+	// The sequence advances even for lost frames so receivers see the gap
+	s.seq++
+
+	if len(datagram) > maxDatagram {
+		s.failed.Add(1)
+		return fmt.Errorf("%w: %d bytes", ErrDatagramTooLarge, len(frame))
+	}
+	if _, err := s.conn.Write(datagram); err != nil {
+		s.failed.Add(1)
+		return err
+	}
+	s.sent.Add(1)
+	return nil
+}
+
+// Run streams frames from pp until ctx is cancelled or the port fails
+// Per-frame read and send errors are reported to the error handler and
+// do not stop the stream.
+func (s *UDPStreamer) Run(ctx context.Context, pp framing.PacketPort) error {
+	for {
+		frame, at, err := pp.ReadPacket(ctx)
+		if err != nil {
+			var csErr *framing.ChecksumError
+			if errors.As(err, &csErr) || errors.Is(err, framing.ErrFrameTooLarge) || errors.Is(err, framing.ErrInvalidFrame) {
+				s.reportError(err)
+				continue
+			}
+			return err
+		}
+
+		if err := s.Send(frame, at); err != nil {
+			s.reportError(err)
+		}
+	}
+}
+
+// Sent returns the number of datagrams sent
+func (s *UDPStreamer) Sent() uint64 {
+	return s.sent.Load()
+}
+
+// Failed returns the number of frames that could not be sent
+func (s *UDPStreamer) Failed() uint64 {
+	return s.failed.Load()
+}
+
+// Close closes the UDP socket
+func (s *UDPStreamer) Close() error {
+	return s.conn.Close()
+}
+
+func (s *UDPStreamer) headerSize() int {
+	n := 0
+	if s.sequence {
+		n += 4
+	}
+	if s.timestamp {
+		n += 8
+	}
+	return n
+}
+
+func (s *UDPStreamer) reportError(err error) {
+	if s.onError != nil {
+		s.onError(err)
+	}
+}
+
+// Datagram is a decoded UDPStreamer datagram
+type Datagram struct {
+	Sequence uint32    // Zero unless sent WithSequence
+	Time     time.Time // Zero unless sent WithTimestamp
+	Frame    []byte
+}
+
+// ParseDatagram splits a received datagram into its header fields and frame
+// sequence and timestamp must match the sender's options.
+func ParseDatagram(b []byte, sequence, timestamp bool) (Datagram, error) {
+	var d Datagram
+	if sequence {
+		if len(b) < 4 {
+			return d, ErrShortDatagram
+		}
+		d.Sequence = binary.BigEndian.Uint32(b)
+		b = b[4:]
+	}
+	if timestamp {
+		if len(b) < 8 {
+			return d, ErrShortDatagram
+		}
+		d.Time = time.Unix(0, int64(binary.BigEndian.Uint64(b)))
+		b = b[8:]
+	}
+	d.Frame = b
+	return d, nil
+}
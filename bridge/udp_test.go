@@ -0,0 +1,79 @@
+package bridge
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/allbin/go-serial/framing"
+)
+
+func TestUDPStreamer(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("UDP not available: %v", err)
+	}
+	defer pc.Close()
+
+	s, err := DialUDP(pc.LocalAddr().String(), WithSequence(), WithTimestamp())
+	if err != nil {
+		t.Fatalf("DialUDP failed: %v", err)
+	}
+	defer s.Close()
+
+	r, w := io.Pipe()
+	pp := framing.NewPacketPort(struct {
+		io.Reader
+		io.Writer
+	}{r, io.Discard}, framing.NewDelimited([]byte("\n")))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx, pp) }()
+
+	start := time.Now()
+	go w.Write([]byte("first\nsecond\n"))
+
+	buf := make([]byte, 1500)
+	for i, want := range []string{"first", "second"} {
+		pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("ReadFrom failed: %v", err)
+		}
+		d, err := ParseDatagram(buf[:n], true, true)
+		if err != nil {
+			t.Fatalf("ParseDatagram failed: %v", err)
+		}
+		if d.Sequence != uint32(i) || string(d.Frame) != want || d.Time.Before(start.Add(-time.Second)) {
+			t.Errorf("datagram %d = {%d %v %q}, want sequence %d frame %q", i, d.Sequence, d.Time, d.Frame, i, want)
+		}
+	}
+
+	cancel()
+	w.Close()
+	<-done
+	if s.Sent() != 2 {
+		t.Errorf("Sent = %d, want 2", s.Sent())
+	}
+}
+
+func TestUDPStreamerTooLarge(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+
+	s := NewUDPStreamer(client, WithSequence())
+	if err := s.Send(bytes.Repeat([]byte{0}, maxDatagram), time.Now()); !errors.Is(err, ErrDatagramTooLarge) {
+		t.Errorf("Send error = %v, want ErrDatagramTooLarge", err)
+	}
+	if s.Failed() != 1 {
+		t.Errorf("Failed = %d, want 1", s.Failed())
+	}
+	if _, err := ParseDatagram([]byte{1, 2}, true, false); !errors.Is(err, ErrShortDatagram) {
+		t.Errorf("ParseDatagram error = %v, want ErrShortDatagram", err)
+	}
+}
@@ -0,0 +1,24 @@
+package serial
+
+// BufferLevels are the bytes held in a device's kernel buffers
+// Input data piling up means the reader is not keeping up; output data that
+// does not drain usually means flow control is holding the line.
+type BufferLevels struct {
+	Input  int // Received bytes waiting to be read
+	Output int // Written bytes waiting to be transmitted
+}
+
+// BufferReporter is implemented by ports that can report kernel buffer levels
+type BufferReporter interface {
+	// BufferLevels returns the current buffer occupancy
+	BufferLevels() (BufferLevels, error)
+}
+
+// GetBufferLevels reads the kernel buffer occupancy of p
+// Returns ErrNotSupported if p has no kernel buffers, such as a network port.
+func GetBufferLevels(p Port) (BufferLevels, error) {
+	if b, ok := p.(BufferReporter); ok {
+		return b.BufferLevels()
+	}
+	return BufferLevels{}, ErrNotSupported
+}
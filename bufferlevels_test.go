@@ -0,0 +1,44 @@
+package serial
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGetBufferLevels(t *testing.T) {
+	master, slavePath, err := OpenPTY()
+	if err != nil {
+		t.Skipf("PTY not available: %v", err)
+	}
+	defer master.Close()
+
+	port, err := Open(slavePath, WithReadTimeout(100*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Open(%s) failed: %v", slavePath, err)
+	}
+
+	if _, err := master.Write([]byte("hello")); err != nil {
+		t.Fatalf("master write failed: %v", err)
+	}
+	// The PTY moves the data to the slave's buffer asynchronously
+	deadline := time.Now().Add(time.Second)
+	var levels BufferLevels
+	for time.Now().Before(deadline) {
+		if levels, err = GetBufferLevels(port); err != nil {
+			t.Fatalf("GetBufferLevels failed: %v", err)
+		}
+		if levels.Input == 5 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if levels.Input != 5 {
+		t.Errorf("Input = %d, want 5", levels.Input)
+	}
+
+	port.Close()
+	if _, err := GetBufferLevels(port); !errors.Is(err, ErrPortClosed) {
+		t.Errorf("GetBufferLevels after Close error = %v, want ErrPortClosed", err)
+	}
+}
@@ -0,0 +1,25 @@
+package serial
+
+import "sync"
+
+// scratchSize is the size of pooled buffers for reads whose data is
+// inspected and dropped, such as draining input or collecting a response
+const scratchSize = 4096
+
+// scratchPool holds *[]byte so Put does not allocate a slice header
+var scratchPool = sync.Pool{
+	New: func() any {
+		b := make([]byte, scratchSize)
+		return &b
+	},
+}
+
+// getScratch returns a scratch buffer, which must go back with putScratch
+// and must not be retained after that
+func getScratch() *[]byte {
+	return scratchPool.Get().(*[]byte)
+}
+
+func putScratch(b *[]byte) {
+	scratchPool.Put(b)
+}
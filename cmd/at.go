@@ -0,0 +1,213 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/allbin/go-serial"
+	"github.com/allbin/go-serial/at"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+)
+
+// atCmd represents the at command
+var atCmd = &cobra.Command{
+	Use:   "at <port> [command...]",
+	Short: "Send AT commands and print the responses",
+	Long: `Send AT commands to a modem one after another, waiting for the final
+result code (OK, ERROR, +CME ERROR, ...) of each, and print the information
+lines and result. Unsolicited result codes arriving in between are left
+out.
+
+Without commands on the command line, commands are read from stdin, one
+per line, with a prompt on a terminal; empty lines and lines starting with
+# are skipped, so a file of commands can be piped in.
+
+--json prints one JSON object per command instead: "ts", "command", the
+information "lines", the "value" of the line answering the command (such
+as "23,99" for AT+CSQ), the final "result", "ok" and "ms".
+
+The exit status is 0 when every command returned OK (or CONNECT), 2 when a
+command failed or timed out, and 1 on other errors, for modem health
+checks from cron. --stop-on-error skips the commands after a failure.
+
+Example usage:
+  serial at /dev/ttyUSB2 AT+CSQ AT+CREG?
+  serial at /dev/ttyUSB2 ATI AT+CGSN --json | jq -r .value
+  serial at /dev/ttyUSB2 < healthcheck.at
+  serial at /dev/ttyUSB2 AT+COPS=? --timeout 3m`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if profilePort() != "" {
+			return nil
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		// The profile's port stands in for the port argument unless the
+		// first argument names a port
+		if port := profilePort(); port != "" && (len(args) == 0 || !isPortPath(args[0])) {
+			args = append([]string{port}, args...)
+		}
+		portPath, commands := args[0], args[1:]
+
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		stopOnError, _ := cmd.Flags().GetBool("stop-on-error")
+
+		opts, _, err := portOptions(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		port, err := serial.Open(portPath, opts...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening port: %v\n", err)
+			os.Exit(1)
+		}
+		defer port.Close()
+
+		client := at.New(port, at.WithTimeout(timeout))
+		defer client.Close()
+
+		// Ctrl+C ends the command as usual, also while waiting at the prompt
+		next := atCommandSource(commands)
+		failed := false
+		for {
+			command, ok := next()
+			if !ok {
+				break
+			}
+			err := runATCommand(context.Background(), client, command, jsonOutput)
+			if errors.Is(err, at.ErrCommandFailed) || errors.Is(err, at.ErrTimeout) {
+				failed = true
+				if stopOnError {
+					break
+				}
+				continue
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if failed {
+			os.Exit(2)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(atCmd)
+
+	addPortFlags(atCmd, 100*time.Millisecond)
+	atCmd.Flags().Duration("timeout", at.DefaultTimeout, "How long to wait for the final result code of each command")
+	atCmd.Flags().Bool("json", false, "Print one JSON object per command")
+	atCmd.Flags().Bool("stop-on-error", false, "Skip the remaining commands after one fails")
+}
+
+// atCommandSource returns a function yielding the commands given, or if
+// there are none, the commands read from stdin
+func atCommandSource(commands []string) func() (string, bool) {
+	if len(commands) > 0 {
+		return func() (string, bool) {
+			if len(commands) == 0 {
+				return "", false
+			}
+			command := commands[0]
+			commands = commands[1:]
+			return command, true
+		}
+	}
+
+	stat, err := os.Stdin.Stat()
+	prompt := err == nil && stat.Mode()&os.ModeCharDevice != 0
+	scanner := bufio.NewScanner(os.Stdin)
+	return func() (string, bool) {
+		for {
+			if prompt {
+				fmt.Print("AT> ")
+			}
+			if !scanner.Scan() {
+				if prompt {
+					fmt.Println()
+				}
+				return "", false
+			}
+			line := strings.TrimSpace(scanner.Text())
+			if line != "" && !strings.HasPrefix(line, "#") {
+				return line, true
+			}
+		}
+	}
+}
+
+// atRecord is the --json output for one command
+type atRecord struct {
+	TS      time.Time `json:"ts"`
+	Command string    `json:"command"`
+	Lines   []string  `json:"lines"`
+	Value   string    `json:"value,omitempty"`
+	Result  string    `json:"result"`
+	OK      bool      `json:"ok"`
+	MS      int64     `json:"ms"`
+}
+
+// runATCommand sends command and prints its response, returning the
+// command's error
+func runATCommand(ctx context.Context, client *at.Client, command string, jsonOutput bool) error {
+	start := time.Now()
+	resp, err := client.SendCommand(ctx, command)
+	elapsed := time.Since(start)
+
+	record := atRecord{TS: start, Command: command, Lines: []string{}, MS: elapsed.Milliseconds()}
+	if resp != nil {
+		record.Lines = append(record.Lines, resp.Lines...)
+		record.Result = resp.Result
+		if prefix := at.ResponsePrefix(command); prefix != "" {
+			record.Value = resp.Value(prefix)
+		}
+	}
+	switch {
+	case err == nil:
+		record.OK = true
+	case errors.Is(err, at.ErrTimeout):
+		record.Result = "TIMEOUT"
+	case !errors.Is(err, at.ErrCommandFailed):
+		return err
+	}
+
+	if jsonOutput {
+		if encErr := json.NewEncoder(os.Stdout).Encode(record); encErr != nil {
+			return encErr
+		}
+		return err
+	}
+
+	commandStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("99")).
+		Bold(true)
+
+	resultStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("40")).
+		Bold(true)
+
+	if !record.OK {
+		resultStyle = resultStyle.Foreground(lipgloss.Color("196"))
+	}
+
+	fmt.Println(commandStyle.Render(command))
+	for _, line := range record.Lines {
+		fmt.Printf("  %s\n", line)
+	}
+	fmt.Printf("  %s  (%v)\n", resultStyle.Render(record.Result), elapsed.Round(time.Millisecond))
+	return err
+}
@@ -0,0 +1,313 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"os/signal"
+	"slices"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/allbin/go-serial"
+	"github.com/allbin/go-serial/internal/tui/components"
+	"github.com/spf13/cobra"
+)
+
+// benchmarkCmd represents the benchmark command
+var benchmarkCmd = &cobra.Command{
+	Use:   "benchmark <port>",
+	Short: "Measure throughput and round-trip latency",
+	Long: `Measure sustained throughput and round-trip latency against a loopback
+plug or an echo device, at the configured port settings.
+
+The throughput test writes blocks of --block bytes for --duration while
+counting what comes back, then reports the TX and RX rates and how close
+RX came to the line rate (the baud rate divided by the bits per character,
+such as 10 for 8N1). Bytes that never came back are reported as lost.
+
+The latency test sends --count messages of --size bytes one at a time and
+times each until it has been echoed in full, reporting min, mean, the 50th,
+90th and 99th percentiles and max. Echoes that differ from what was sent
+count as mismatches, and echoes not complete within --timeout as timeouts.
+
+With --flow-control cts, the time from CTS going active to each write that
+waited for it is reported as well; compare runs with and without
+--cts-priority to see what real-time scheduling gains.
+
+Use it to compare adapters, or to check that low-latency settings (such as
+the FTDI latency timer) have the intended effect.
+
+Example usage:
+  serial benchmark /dev/ttyUSB0
+  serial benchmark /dev/ttyUSB0 --baud 921600 --duration 10s
+  serial benchmark /dev/ttyUSB0 --test latency --count 1000 --size 1
+  serial benchmark /dev/ttyUSB0 --flow-control rtscts --block 4096
+  serial benchmark /dev/ttyUSB0 --flow-control cts --initial-rts --cts-priority 50`,
+	Args: portArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		args = withProfilePort(args, 1)
+		portPath := args[0]
+
+		test, _ := cmd.Flags().GetString("test")
+		duration, _ := cmd.Flags().GetDuration("duration")
+		block, _ := cmd.Flags().GetInt("block")
+		count, _ := cmd.Flags().GetInt("count")
+		size, _ := cmd.Flags().GetInt("size")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+
+		throughput, latency := test == "all" || test == "throughput", test == "all" || test == "latency"
+		if !throughput && !latency {
+			fmt.Fprintf(os.Stderr, "Error: unknown test: %s (valid: all, throughput, latency)\n", test)
+			os.Exit(1)
+		}
+		if block < 1 || count < 1 || size < 1 {
+			fmt.Fprintf(os.Stderr, "Error: --block, --count and --size must be positive\n")
+			os.Exit(1)
+		}
+
+		opts, config, err := portOptions(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		port, err := serial.Open(portPath, opts...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening port: %v\n", err)
+			os.Exit(1)
+		}
+		defer port.Close()
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		lineRate := float64(config.BaudRate) / float64(bitsPerChar(config))
+		fmt.Printf("Benchmark on %s at %s (line rate %s/s)\n", portPath, formatLineSettings(config), components.FormatSize(lineRate))
+
+		if throughput {
+			result, err := benchmarkThroughput(ctx, port, duration, block, timeout)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			result.print(block, lineRate)
+		}
+		if latency && ctx.Err() == nil {
+			result, err := benchmarkLatency(ctx, port, count, size, timeout)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			result.print(size)
+		}
+		if lat, err := serial.GetCTSLatency(port); err == nil && lat.Writes > 0 {
+			printCTSLatency(lat)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(benchmarkCmd)
+
+	addPortFlags(benchmarkCmd, 100*time.Millisecond)
+	benchmarkCmd.Flags().String("test", "all", "Tests to run: all, throughput, latency")
+	benchmarkCmd.Flags().Duration("duration", 5*time.Second, "How long the throughput test writes")
+	benchmarkCmd.Flags().Int("block", 256, "Bytes per write in the throughput test")
+	benchmarkCmd.Flags().Int("count", 100, "Round trips in the latency test")
+	benchmarkCmd.Flags().Int("size", 16, "Bytes per round trip in the latency test")
+	benchmarkCmd.Flags().Duration("timeout", time.Second, "Wait for each echo, and for the last throughput data")
+}
+
+// throughputResult is the outcome of the throughput test
+type throughputResult struct {
+	sent, received int64
+	txTime, rxTime time.Duration // From the first write to the last byte drained or received
+}
+
+// benchmarkThroughput writes blocks to port for duration while reading the
+// echo, then waits up to timeout of silence for the rest of it
+func benchmarkThroughput(ctx context.Context, port serial.Port, duration time.Duration, block int, timeout time.Duration) (throughputResult, error) {
+	if err := port.FlushInput(); err != nil {
+		return throughputResult{}, fmt.Errorf("flush failed: %w", err)
+	}
+
+	var result throughputResult
+	data := make([]byte, block)
+	for i := range data {
+		data[i] = byte(i)
+	}
+
+	start := time.Now()
+	deadline := start.Add(duration)
+	var sent atomic.Int64
+	written := make(chan error, 1)
+	go func() {
+		for time.Now().Before(deadline) && ctx.Err() == nil {
+			n, err := port.Write(data)
+			sent.Add(int64(n))
+			if err != nil {
+				written <- err
+				return
+			}
+		}
+		err := port.DrainOutput()
+		result.txTime = time.Since(start)
+		written <- err
+	}()
+
+	buf := make([]byte, 4096)
+	var writeErr error
+	writing := true
+	idle := time.Now()
+	for {
+		if !writing {
+			if result.received >= sent.Load() || time.Since(idle) > timeout || ctx.Err() != nil {
+				break
+			}
+		}
+		select {
+		case writeErr = <-written:
+			writing = false
+			idle = time.Now()
+		default:
+		}
+
+		n, err := port.Read(buf)
+		if n > 0 {
+			result.received += int64(n)
+			result.rxTime = time.Since(start)
+			idle = time.Now()
+		}
+		if err != nil {
+			return result, fmt.Errorf("read failed: %w", err)
+		}
+	}
+	if writing {
+		writeErr = <-written
+	}
+	result.sent = sent.Load()
+	if writeErr != nil {
+		return result, fmt.Errorf("write failed: %w", writeErr)
+	}
+	return result, nil
+}
+
+func (r throughputResult) print(block int, lineRate float64) {
+	fmt.Printf("\nThroughput (%d-byte writes):\n", block)
+	fmt.Printf("  TX  %s in %s  %s/s\n", components.FormatSize(float64(r.sent)), r.txTime.Round(time.Millisecond), components.FormatSize(rate(r.sent, r.txTime)))
+	rx := rate(r.received, r.rxTime)
+	fmt.Printf("  RX  %s in %s  %s/s (%.1f%% of line rate)\n", components.FormatSize(float64(r.received)), r.rxTime.Round(time.Millisecond), components.FormatSize(rx), 100*rx/lineRate)
+	if lost := r.sent - r.received; lost != 0 {
+		fmt.Printf("  Lost %d B (%.2f%%)\n", lost, 100*float64(lost)/float64(max(r.sent, 1)))
+	}
+}
+
+// printCTSLatency reports how quickly writes followed CTS going active
+func printCTSLatency(lat serial.CTSLatency) {
+	scheduling := "normal priority"
+	if lat.Realtime {
+		scheduling = "SCHED_FIFO"
+	}
+	fmt.Printf("\nCTS wakeup to write (%s):\n", scheduling)
+	fmt.Printf("  %d writes: min %s  mean %s  max %s\n", lat.Writes,
+		formatPeriod(lat.Min), formatPeriod(lat.Mean), formatPeriod(lat.Max))
+}
+
+// rate returns n bytes over d per second
+func rate(n int64, d time.Duration) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return float64(n) / d.Seconds()
+}
+
+// latencyResult is the outcome of the latency test
+type latencyResult struct {
+	rtts       []time.Duration
+	timeouts   int
+	mismatches int
+}
+
+// benchmarkLatency times count round trips of size random bytes each
+func benchmarkLatency(ctx context.Context, port serial.Port, count, size int, timeout time.Duration) (latencyResult, error) {
+	var result latencyResult
+	msg := make([]byte, size)
+	echo := make([]byte, 0, size)
+	buf := make([]byte, size)
+
+	for range count {
+		if ctx.Err() != nil {
+			break
+		}
+		for i := range msg {
+			msg[i] = byte(rand.IntN(256))
+		}
+		// A late echo from a timed-out round trip must not count for this one
+		if err := port.FlushInput(); err != nil {
+			return result, fmt.Errorf("flush failed: %w", err)
+		}
+
+		start := time.Now()
+		if _, err := port.Write(msg); err != nil {
+			return result, fmt.Errorf("write failed: %w", err)
+		}
+		// Plain reads return within the read timeout, so none is left
+		// pending to swallow the next echo
+		echo = echo[:0]
+		var rtt time.Duration
+		for len(echo) < size && time.Since(start) < timeout && ctx.Err() == nil {
+			n, err := port.Read(buf[:size-len(echo)])
+			rtt = time.Since(start)
+			if n > 0 {
+				echo = append(echo, buf[:n]...)
+			}
+			if err != nil {
+				return result, fmt.Errorf("read failed: %w", err)
+			}
+		}
+
+		switch {
+		case len(echo) < size:
+			if ctx.Err() == nil {
+				result.timeouts++
+			}
+		case !bytes.Equal(echo, msg):
+			result.mismatches++
+		default:
+			result.rtts = append(result.rtts, rtt)
+		}
+	}
+	return result, nil
+}
+
+func (r latencyResult) print(size int) {
+	fmt.Printf("\nRound-trip latency (%d-byte messages):\n", size)
+	if len(r.rtts) > 0 {
+		slices.Sort(r.rtts)
+		var total time.Duration
+		for _, d := range r.rtts {
+			total += d
+		}
+		fmt.Printf("  %d round trips: min %s  mean %s  p50 %s  p90 %s  p99 %s  max %s\n", len(r.rtts),
+			formatPeriod(r.rtts[0]), formatPeriod(total/time.Duration(len(r.rtts))),
+			formatPeriod(percentile(r.rtts, 50)), formatPeriod(percentile(r.rtts, 90)),
+			formatPeriod(percentile(r.rtts, 99)), formatPeriod(r.rtts[len(r.rtts)-1]))
+	} else {
+		fmt.Println("  No complete round trips")
+	}
+	if r.timeouts > 0 || r.mismatches > 0 {
+		fmt.Printf("  %d timeouts, %d mismatches\n", r.timeouts, r.mismatches)
+	}
+}
+
+// percentile returns the nearest-rank p-th percentile of sorted
+func percentile(sorted []time.Duration, p int) time.Duration {
+	i := (p*len(sorted)+99)/100 - 1
+	return sorted[max(i, 0)]
+}
@@ -0,0 +1,88 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/allbin/go-serial"
+	"github.com/spf13/cobra"
+)
+
+// defaultBreakDuration is the break length when none is given, long enough
+// for U-Boot and SiLabs bootloaders to notice at any common baud rate
+const defaultBreakDuration = 250 * time.Millisecond
+
+// breakCmd represents the break command
+var breakCmd = &cobra.Command{
+	Use:   "break <port> [duration]",
+	Short: "Transmit a break condition",
+	Long: `Hold the TX line in the spacing state for a duration (default 250ms),
+after any pending output has been sent, and release it.
+
+A break interrupts U-Boot autoboot on many boards, resets SiLabs and other
+devices into their bootloader and marks frame starts on some RS-485 buses.
+Raw tcp:// ports cannot send a break; rfc2217:// ports ask the server to.
+
+A local device is opened without changing its settings, so the baud rate
+and framing another program configured are left as they were.
+
+Examples:
+  serial break /dev/ttyUSB0
+  serial break /dev/ttyUSB0 500ms
+  serial break rfc2217://gateway:2217 1s`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if profilePort() != "" {
+			return cobra.RangeArgs(0, 2)(cmd, args)
+		}
+		return cobra.RangeArgs(1, 2)(cmd, args)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		// The profile's port stands in for the port argument unless the
+		// first argument names a port
+		if port := profilePort(); port != "" && (len(args) == 0 || !isPortPath(args[0])) {
+			args = append([]string{port}, args...)
+		}
+		if len(args) > 2 {
+			fmt.Fprintf(os.Stderr, "Error: too many arguments\n")
+			os.Exit(1)
+		}
+		portPath := args[0]
+
+		duration := defaultBreakDuration
+		if len(args) == 2 {
+			d, err := time.ParseDuration(args[1])
+			if err != nil || d <= 0 {
+				fmt.Fprintf(os.Stderr, "Error: invalid break duration %q (e.g. 250ms, 1s)\n", args[1])
+				os.Exit(1)
+			}
+			duration = d
+		}
+
+		port, err := serial.Open(portPath, serial.WithKeepSettings(), withDiagnostics())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening port: %v\n", err)
+			os.Exit(1)
+		}
+		defer port.Close()
+
+		if err := serial.SendBreak(port, duration); err != nil {
+			if errors.Is(err, serial.ErrNotSupported) {
+				fmt.Fprintf(os.Stderr, "Error: %s cannot send a break\n", portPath)
+			} else {
+				fmt.Fprintf(os.Stderr, "Error sending break: %v\n", err)
+			}
+			os.Exit(1)
+		}
+
+		fmt.Printf("Sent %v break on %s\n", duration, portPath)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(breakCmd)
+}
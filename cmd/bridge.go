@@ -0,0 +1,314 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/allbin/go-serial"
+	"github.com/allbin/go-serial/bridge"
+	"github.com/allbin/go-serial/framing"
+	"github.com/spf13/cobra"
+)
+
+// bridgeCmd represents the bridge command
+var bridgeCmd = &cobra.Command{
+	Use:   "bridge <port>",
+	Short: "Bridge a serial port to TCP clients or a UDP destination",
+	Long: `Expose a serial port over raw TCP.
+
+Data received on the port is sent to connected clients, and client data is
+written to the port. The port is reopened automatically if it fails (e.g.,
+a USB adapter is unplugged and replugged).
+
+Client policies:
+  exclusive  One client at a time; further connections are refused (default)
+  broadcast  All clients receive data; only the first connected client writes
+  takeover   One client at a time; a new connection replaces the current one
+
+With --dial the bridge connects out to a TCP server instead of listening,
+and redials whenever the connection drops.
+
+With --udp every received frame is sent as one datagram to host:port
+(receive only). Frames are split with --framing line (--delimiter) or idle
+(--idle-gap). --udp-seq prefixes a 4-byte big-endian sequence number and
+--udp-timestamp an 8-byte big-endian Unix-nanosecond receive time.
+
+--session records everything the device sent and the clients transmitted,
+plus modem signal changes, in the record/replay session format. Reopened
+ports keep appending to the same recording.
+
+--metrics exposes Prometheus metrics (bytes in/out, CTS wait histogram,
+reconnects, errors, connected clients) on http://<addr>/metrics.
+
+Example usage:
+  serial bridge /dev/ttyUSB0 --listen :5000
+  serial bridge /dev/ttyUSB0 --listen :5000 --policy broadcast --baud 9600
+  serial bridge /dev/ttyUSB0 --dial collector.local:7000 --reconnect 5s
+  serial bridge /dev/ttyUSB0 --udp 192.168.1.255:9000 --udp-seq --udp-timestamp
+  serial bridge /dev/ttyUSB0 --listen :5000 --metrics :9100
+  serial bridge /dev/ttyUSB0 --listen :5000 --session field-test.srec`,
+	Args: portArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		args = withProfilePort(args, 1)
+		portPath := args[0]
+
+		// Get flags
+		baudRate, _ := cmd.Flags().GetInt("baud")
+		flowControl, _ := cmd.Flags().GetString("flow-control")
+		initialRTS, _ := cmd.Flags().GetBool("initial-rts")
+		listenAddr, _ := cmd.Flags().GetString("listen")
+		dialAddr, _ := cmd.Flags().GetString("dial")
+		policyName, _ := cmd.Flags().GetString("policy")
+		queue, _ := cmd.Flags().GetInt("queue")
+		reconnect, _ := cmd.Flags().GetDuration("reconnect")
+		udpAddr, _ := cmd.Flags().GetString("udp")
+		udpSeq, _ := cmd.Flags().GetBool("udp-seq")
+		udpTimestamp, _ := cmd.Flags().GetBool("udp-timestamp")
+		framingMode, _ := cmd.Flags().GetString("framing")
+		delimiter, _ := cmd.Flags().GetString("delimiter")
+		idleGap, _ := cmd.Flags().GetDuration("idle-gap")
+		metricsAddr, _ := cmd.Flags().GetString("metrics")
+		sessionPath, _ := cmd.Flags().GetString("session")
+
+		modes := 0
+		for _, addr := range []string{listenAddr, dialAddr, udpAddr} {
+			if addr != "" {
+				modes++
+			}
+		}
+		if modes != 1 {
+			fmt.Fprintf(os.Stderr, "Error: specify exactly one of --listen, --dial or --udp\n")
+			os.Exit(1)
+		}
+
+		policy, err := bridge.ParsePolicy(strings.ToLower(policyName))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Configure port options; a short read timeout lets the bridge stop promptly
+		opts := []serial.Option{
+			serial.WithBaudRate(baudRate),
+			withDiagnostics(),
+		}
+
+		lineOpts, err := lineOptions(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		opts = append(opts, lineOpts...)
+
+		switch strings.ToLower(flowControl) {
+		case "cts":
+			opts = append(opts, serial.WithFlowControl(serial.FlowControlCTS))
+			if initialRTS {
+				opts = append(opts, serial.WithInitialRTS(true))
+			}
+		case "rtscts":
+			opts = append(opts, serial.WithFlowControl(serial.FlowControlRTSCTS))
+			if initialRTS {
+				opts = append(opts, serial.WithInitialRTS(true))
+			}
+		}
+
+		reg, err := startMetrics(metricsAddr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		onEvent := printBridgeEvent
+		if reg != nil {
+			opts = append(opts, serial.WithInstrumentation(reg.Instrumentation("port", portPath)))
+			countEvent := reg.BridgeEvents("port", portPath)
+			onEvent = func(ev bridge.Event) {
+				countEvent(ev)
+				printBridgeEvent(ev)
+			}
+		}
+
+		sess, err := createSession(sessionPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		open := func() (serial.Port, error) {
+			port, err := serial.Open(portPath, opts...)
+			if err != nil {
+				return nil, err
+			}
+			return sess.wrap(port), nil
+		}
+
+		if udpAddr != "" {
+			var codec framing.Codec
+			switch strings.ToLower(framingMode) {
+			case "line":
+				delim, err := strconv.Unquote(`"` + delimiter + `"`)
+				if err != nil || delim == "" {
+					fmt.Fprintf(os.Stderr, "Error: invalid delimiter %q\n", delimiter)
+					os.Exit(1)
+				}
+				codec = framing.NewDelimited([]byte(delim))
+			case "idle":
+				codec = framing.NewIdleGap(idleGap)
+			default:
+				fmt.Fprintf(os.Stderr, "Error: unknown framing %q (want line or idle)\n", framingMode)
+				os.Exit(1)
+			}
+
+			udpOpts := []bridge.UDPOption{bridge.WithUDPErrorHandler(func(err error) {
+				fmt.Fprintf(os.Stderr, "[%s] %v\n", time.Now().Format("15:04:05"), err)
+			})}
+			if udpSeq {
+				udpOpts = append(udpOpts, bridge.WithSequence())
+			}
+			if udpTimestamp {
+				udpOpts = append(udpOpts, bridge.WithTimestamp())
+			}
+
+			err := runUDPBridge(portPath, udpAddr, open, codec, udpOpts, reconnect, onEvent)
+			sess.Close()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		b := bridge.New(open,
+			bridge.WithPolicy(policy),
+			bridge.WithClientQueue(queue),
+			bridge.WithReconnectDelay(reconnect),
+			bridge.WithEventHandler(onEvent),
+		)
+
+		err = runBridge(b, portPath, listenAddr, dialAddr)
+		sess.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(bridgeCmd)
+
+	bridgeCmd.Flags().IntP("baud", "b", 115200, "Baud rate")
+	addLineFlags(bridgeCmd, 100*time.Millisecond)
+	bridgeCmd.Flags().StringP("flow-control", "f", "none", "Flow control: none, cts, rtscts")
+	bridgeCmd.Flags().Bool("initial-rts", false, "Assert RTS on port open")
+	bridgeCmd.Flags().StringP("listen", "l", "", "Listen address for clients (e.g., :5000)")
+	bridgeCmd.Flags().String("dial", "", "Connect out to host:port instead of listening")
+	bridgeCmd.Flags().StringP("policy", "p", "exclusive", "Client policy: exclusive, broadcast, takeover")
+	bridgeCmd.Flags().Int("queue", bridge.DefaultClientQueue, "Received chunks buffered per client before data is dropped")
+	bridgeCmd.Flags().Duration("reconnect", bridge.DefaultReconnectDelay, "Delay before reopening the port or redialing")
+	bridgeCmd.Flags().String("udp", "", "Send each received frame as a datagram to host:port")
+	bridgeCmd.Flags().Bool("udp-seq", false, "Prefix datagrams with a sequence number")
+	bridgeCmd.Flags().Bool("udp-timestamp", false, "Prefix datagrams with the receive time")
+	bridgeCmd.Flags().String("framing", "line", "UDP frame detection: line, idle")
+	bridgeCmd.Flags().String("session", "", "Record all RX/TX data and modem signal changes to a session file")
+	bridgeCmd.Flags().String("delimiter", `\n`, "Line framing delimiter (escapes like \\r\\n allowed)")
+	bridgeCmd.Flags().Duration("idle-gap", 20*time.Millisecond, "Silence that ends a frame with idle framing")
+	bridgeCmd.Flags().String("metrics", "", "Serve Prometheus metrics on this address (e.g., :9100)")
+}
+
+func runBridge(b *bridge.Bridge, portPath, listenAddr, dialAddr string) error {
+	// Setup signal handling for clean shutdown
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	startTime := time.Now()
+	defer func() {
+		stats := b.Stats()
+		fmt.Fprintf(os.Stderr, "\nBridge stopped after %v: %d bytes RX, %d bytes TX, %d dropped\n",
+			time.Since(startTime).Round(time.Second), stats.RXBytes, stats.TXBytes, stats.Dropped)
+	}()
+
+	if dialAddr != "" {
+		fmt.Fprintf(os.Stderr, "Bridging %s to %s\n", portPath, dialAddr)
+		fmt.Fprintf(os.Stderr, "Press Ctrl+C to stop\n\n")
+		if err := b.Dial(ctx, dialAddr); err != nil && ctx.Err() == nil {
+			return err
+		}
+		return nil
+	}
+
+	l, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Bridging %s on %s\n", portPath, l.Addr())
+	fmt.Fprintf(os.Stderr, "Press Ctrl+C to stop\n\n")
+	return b.Serve(ctx, l)
+}
+
+func runUDPBridge(portPath, udpAddr string, open bridge.Opener, codec framing.Codec, udpOpts []bridge.UDPOption, reconnect time.Duration, onEvent func(bridge.Event)) error {
+	// Setup signal handling for clean shutdown
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	streamer, err := bridge.DialUDP(udpAddr, udpOpts...)
+	if err != nil {
+		return err
+	}
+	defer streamer.Close()
+
+	startTime := time.Now()
+	defer func() {
+		fmt.Fprintf(os.Stderr, "\nStreamed %d frames (%d failed) in %v\n",
+			streamer.Sent(), streamer.Failed(), time.Since(startTime).Round(time.Second))
+	}()
+
+	fmt.Fprintf(os.Stderr, "Streaming frames from %s to udp://%s\n", portPath, udpAddr)
+	fmt.Fprintf(os.Stderr, "Press Ctrl+C to stop\n\n")
+
+	// Reopen the port after failures, like the TCP bridge
+	for {
+		port, err := open()
+		if err != nil {
+			onEvent(bridge.Event{Kind: bridge.EventPortError, Err: err})
+		} else {
+			onEvent(bridge.Event{Kind: bridge.EventPortOpened})
+			err = streamer.Run(ctx, framing.NewPacketPort(port, codec))
+			port.Close()
+			if ctx.Err() != nil {
+				return nil
+			}
+			onEvent(bridge.Event{Kind: bridge.EventPortError, Err: err})
+		}
+
+		select {
+		case <-time.After(reconnect):
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func printBridgeEvent(ev bridge.Event) {
+	timestamp := time.Now().Format("15:04:05")
+	switch {
+	case ev.Addr != "" && ev.Err != nil:
+		fmt.Fprintf(os.Stderr, "[%s] %s %s: %v\n", timestamp, ev.Kind, ev.Addr, ev.Err)
+	case ev.Addr != "":
+		fmt.Fprintf(os.Stderr, "[%s] %s %s\n", timestamp, ev.Kind, ev.Addr)
+	case ev.Err != nil:
+		fmt.Fprintf(os.Stderr, "[%s] %s: %v\n", timestamp, ev.Kind, ev.Err)
+	default:
+		fmt.Fprintf(os.Stderr, "[%s] %s\n", timestamp, ev.Kind)
+	}
+}
@@ -4,15 +4,20 @@ Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
 package cmd
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"regexp"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/allbin/go-serial"
+	"github.com/allbin/go-serial/session"
 	"github.com/spf13/cobra"
 )
 
@@ -22,19 +27,54 @@ var captureCmd = &cobra.Command{
 	Short: "Capture serial data to a file",
 	Long: `Capture incoming serial data to a file for later parsing.
 
-Reads ASCII data from the specified serial port and writes it directly to
-the output file. Runs continuously until interrupted (Ctrl+C).
+Reads data from the specified serial port and writes it to the output
+file. Runs continuously until interrupted (Ctrl+C).
+
+--format selects how data is written:
+  raw      Bytes exactly as received (default)
+  hex      Space-separated hex bytes, 16 per line
+  hexdump  Offset, hex and ASCII columns like hexdump -C, the same as
+           serial hexdump writes
+  lines    Each completed line prefixed with an ISO 8601 timestamp of its
+           first byte
 
 The output file is opened in append mode, allowing you to resume captures
 without overwriting existing data.
 
+Unattended captures can stop themselves with --max-bytes, --duration or
+--until-pattern. The exit status is 0 when the capture ends normally or the
+pattern was seen, 2 when --until-pattern was given but the capture ended
+without a match, and 1 on errors.
+
+--pcapng also writes the received data, with nanosecond timestamps, to a
+pcapng file for Wireshark (link type DLT_USER0; map it to a dissector such
+as mbrtu under Preferences > Protocols > DLT_USER).
+
+--session also records the data and every modem signal transition in the
+record/replay session format, a complete reproduction artifact for the
+session package's ReplayPort. To include transmitted data as well, record
+from the command that owns the port instead: serial bridge and serial mux
+take the same flag.
+
+--metrics exposes Prometheus metrics (bytes, timeouts, errors) on
+http://<addr>/metrics.
+
 Example usage:
   serial capture /dev/ttyUSB0 data.log
   serial capture /dev/ttyUSB0 output.txt --baud 9600
+  serial capture /dev/ttyUSB0 legacy.log --baud 9600 --databits 7 --parity even
   serial capture /dev/ttyUSB0 capture.log --console
-  serial capture /dev/ttyUSB0 capture.log --flow-control cts --initial-rts -c`,
-	Args: cobra.ExactArgs(2),
+  serial capture /dev/ttyUSB0 modbus.txt --format hexdump
+  serial capture /dev/ttyUSB0 gps.log --format lines
+  serial capture /dev/ttyUSB0 boot.log --until-pattern 'login:' --duration 2m
+  serial capture /dev/ttyUSB0 sample.bin --max-bytes 1048576
+  serial capture /dev/ttyUSB0 capture.log --flow-control cts --initial-rts -c
+  serial capture /dev/ttyUSB0 capture.log --pcapng capture.pcapng
+  serial capture /dev/ttyUSB0 capture.log --session capture.srec
+  serial capture /dev/ttyUSB0 capture.log --metrics :9100`,
+	Args: portArgs(2),
 	Run: func(cmd *cobra.Command, args []string) {
+		args = withProfilePort(args, 2)
 		portPath := args[0]
 		outputPath := args[1]
 
@@ -44,12 +84,37 @@ Example usage:
 		initialRTS, _ := cmd.Flags().GetBool("initial-rts")
 		bufferSize, _ := cmd.Flags().GetInt("buffer")
 		showConsole, _ := cmd.Flags().GetBool("console")
+		metricsAddr, _ := cmd.Flags().GetString("metrics")
+		pcapPath, _ := cmd.Flags().GetString("pcapng")
+		sessionPath, _ := cmd.Flags().GetString("session")
+		format, _ := cmd.Flags().GetString("format")
+		maxBytes, _ := cmd.Flags().GetInt64("max-bytes")
+		duration, _ := cmd.Flags().GetDuration("duration")
+		untilPattern, _ := cmd.Flags().GetString("until-pattern")
+
+		stop := captureStop{maxBytes: maxBytes, duration: duration}
+		if untilPattern != "" {
+			re, err := regexp.Compile(untilPattern)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: invalid --until-pattern: %v\n", err)
+				os.Exit(1)
+			}
+			stop.until = re
+		}
 
 		// Configure port options
 		opts := []serial.Option{
 			serial.WithBaudRate(baudRate),
+			withDiagnostics(),
 		}
 
+		lineOpts, err := lineOptions(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		opts = append(opts, lineOpts...)
+
 		switch strings.ToLower(flowControl) {
 		case "cts":
 			opts = append(opts, serial.WithFlowControl(serial.FlowControlCTS))
@@ -63,7 +128,20 @@ Example usage:
 			}
 		}
 
-		if err := runCapture(portPath, outputPath, bufferSize, showConsole, opts...); err != nil {
+		reg, err := startMetrics(metricsAddr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if reg != nil {
+			opts = append(opts, serial.WithInstrumentation(reg.Instrumentation("port", portPath)))
+		}
+
+		err = runCapture(portPath, outputPath, pcapPath, sessionPath, format, stop, bufferSize, showConsole, opts...)
+		if errors.Is(err, errPatternNotSeen) {
+			os.Exit(2)
+		}
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
@@ -74,13 +152,40 @@ func init() {
 	rootCmd.AddCommand(captureCmd)
 
 	captureCmd.Flags().IntP("baud", "b", 115200, "Baud rate")
+	addLineFlags(captureCmd, 2500*time.Millisecond)
 	captureCmd.Flags().StringP("flow-control", "f", "none", "Flow control: none, cts, rtscts")
 	captureCmd.Flags().Bool("initial-rts", false, "Assert RTS on port open")
 	captureCmd.Flags().Int("buffer", 4096, "Read buffer size")
 	captureCmd.Flags().BoolP("console", "c", false, "Display incoming data on console while capturing")
+	captureCmd.Flags().String("format", "raw", "Output format: raw, hex, hexdump, lines")
+	captureCmd.Flags().Int64("max-bytes", 0, "Stop after capturing this many bytes (0 = no limit)")
+	captureCmd.Flags().Duration("duration", 0, "Stop after this long, e.g. 10m (0 = no limit)")
+	captureCmd.Flags().String("until-pattern", "", "Stop once received data matches this regular expression")
+	captureCmd.Flags().String("pcapng", "", "Also write received data to a pcapng file")
+	captureCmd.Flags().String("session", "", "Also record data and modem signal changes to a session file")
+	captureCmd.Flags().String("metrics", "", "Serve Prometheus metrics on this address (e.g., :9100)")
+}
+
+// captureWindowSize bounds how much recent data --until-pattern can span
+const captureWindowSize = 64 * 1024
+
+// errPatternNotSeen is returned when a capture with --until-pattern stopped
+// for another reason; the command exits with status 2
+var errPatternNotSeen = errors.New("pattern not seen")
+
+// captureStop holds the conditions that end a capture besides Ctrl+C
+type captureStop struct {
+	maxBytes int64          // Stop after this many bytes (0 = no limit)
+	duration time.Duration  // Stop after this long (0 = no limit)
+	until    *regexp.Regexp // Stop once recent data matches
 }
 
-func runCapture(portPath, outputPath string, bufferSize int, showConsole bool, opts ...serial.Option) error {
+func runCapture(portPath, outputPath, pcapPath, sessionPath, format string, stop captureStop, bufferSize int, showConsole bool, opts ...serial.Option) error {
+	// Validate the format before touching the port or file
+	if _, err := newCaptureFormatter(io.Discard, format); err != nil {
+		return err
+	}
+
 	// Open serial port
 	port, err := serial.Open(portPath, opts...)
 	if err != nil {
@@ -88,6 +193,13 @@ func runCapture(portPath, outputPath string, bufferSize int, showConsole bool, o
 	}
 	defer port.Close()
 
+	sess, err := createSession(sessionPath)
+	if err != nil {
+		return err
+	}
+	defer sess.Close()
+	port = sess.wrap(port)
+
 	// Open output file in append mode
 	file, err := os.OpenFile(outputPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 	if err != nil {
@@ -95,9 +207,31 @@ func runCapture(portPath, outputPath string, bufferSize int, showConsole bool, o
 	}
 	defer file.Close()
 
+	out, _ := newCaptureFormatter(file, format)
+	defer out.close()
+
+	var pcap *session.PcapngWriter
+	if pcapPath != "" {
+		pcapFile, err := os.Create(pcapPath)
+		if err != nil {
+			return fmt.Errorf("failed to create pcapng file: %w", err)
+		}
+		defer pcapFile.Close()
+
+		pcap, err = session.NewPcapngWriter(pcapFile, session.WithInterfaceName(portPath))
+		if err != nil {
+			return fmt.Errorf("failed to write pcapng header: %w", err)
+		}
+		defer pcap.Flush()
+	}
+
 	// Setup signal handling for clean shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
+	if stop.duration > 0 {
+		ctx, cancel = context.WithTimeout(ctx, stop.duration)
+		defer cancel()
+	}
 
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
@@ -118,35 +252,153 @@ func runCapture(portPath, outputPath string, bufferSize int, showConsole bool, o
 	buffer := make([]byte, bufferSize)
 	bytesWritten := int64(0)
 	startTime := time.Now()
+	var window []byte // Recent data searched for --until-pattern
+	matched := false
 
-	for {
-		select {
-		case <-ctx.Done():
-			duration := time.Since(startTime)
-			fmt.Fprintf(os.Stderr, "\nCapture complete: %d bytes written in %v\n", bytesWritten, duration.Round(time.Millisecond))
-			return nil
-		default:
-			n, err := port.ReadContext(ctx, buffer)
-			if err != nil {
-				if ctx.Err() != nil {
-					// Context cancelled, clean shutdown
-					return nil
-				}
-				return fmt.Errorf("read error: %w", err)
+	for !matched && (stop.maxBytes == 0 || bytesWritten < stop.maxBytes) {
+		n, at, err := serial.ReadTimestamped(ctx, port, buffer)
+		if err != nil {
+			if ctx.Err() != nil {
+				// Interrupted or --duration elapsed, clean shutdown
+				break
 			}
+			return fmt.Errorf("read error: %w", err)
+		}
+		if n == 0 {
+			continue
+		}
+
+		data := buffer[:n]
+		if stop.maxBytes > 0 && bytesWritten+int64(n) > stop.maxBytes {
+			data = data[:stop.maxBytes-bytesWritten]
+		}
+		if err := out.write(at, data); err != nil {
+			return fmt.Errorf("write error: %w", err)
+		}
+		bytesWritten += int64(len(data))
+		if pcap != nil {
+			pcap.WriteEvent(at, session.Event{Kind: session.KindRX, Data: data})
+		}
+
+		// Display on console if enabled
+		if showConsole {
+			os.Stdout.Write(data)
+		}
 
-			if n > 0 {
-				written, err := file.Write(buffer[:n])
-				if err != nil {
-					return fmt.Errorf("write error: %w", err)
-				}
-				bytesWritten += int64(written)
-
-				// Display on console if enabled
-				if showConsole {
-					os.Stdout.Write(buffer[:n])
-				}
+		if stop.until != nil {
+			window = append(window, data...)
+			if len(window) > captureWindowSize {
+				window = window[len(window)-captureWindowSize:]
 			}
+			matched = stop.until.Match(window)
+		}
+	}
+
+	reason := "interrupted"
+	switch {
+	case matched:
+		reason = "pattern matched"
+	case stop.maxBytes > 0 && bytesWritten >= stop.maxBytes:
+		reason = "byte limit reached"
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		reason = "duration elapsed"
+	}
+	duration := time.Since(startTime)
+	fmt.Fprintf(os.Stderr, "\nCapture complete (%s): %d bytes written in %v\n", reason, bytesWritten, duration.Round(time.Millisecond))
+
+	if stop.until != nil && !matched {
+		return errPatternNotSeen
+	}
+	return nil
+}
+
+// captureFormatter writes received data to the capture file in one of the
+// --format layouts
+type captureFormatter struct {
+	w      io.Writer
+	format string
+	dumper *hexdumpWriter // hexdump: keeps offsets running across reads
+	col    int            // hex: bytes on the current line
+	line   []byte         // lines: partial line awaiting its newline
+	lineAt time.Time      // lines: arrival of the partial line's first byte
+}
+
+func newCaptureFormatter(w io.Writer, format string) (*captureFormatter, error) {
+	f := &captureFormatter{w: w, format: strings.ToLower(format)}
+	switch f.format {
+	case "raw", "hex", "lines":
+	case "hexdump":
+		f.dumper = &hexdumpWriter{w: w, squeeze: true}
+	default:
+		return nil, fmt.Errorf("unknown format %q (want raw, hex, hexdump or lines)", format)
+	}
+	return f, nil
+}
+
+// write formats data that arrived at at
+func (f *captureFormatter) write(at time.Time, data []byte) error {
+	switch f.format {
+	case "hex":
+		var b strings.Builder
+		for _, c := range data {
+			if f.col > 0 {
+				b.WriteByte(' ')
+			}
+			fmt.Fprintf(&b, "%02x", c)
+			if f.col++; f.col == 16 {
+				b.WriteByte('\n')
+				f.col = 0
+			}
+		}
+		_, err := io.WriteString(f.w, b.String())
+		return err
+	case "hexdump":
+		_, err := f.dumper.Write(data)
+		return err
+	case "lines":
+		for len(data) > 0 {
+			if len(f.line) == 0 {
+				f.lineAt = at
+			}
+			i := bytes.IndexByte(data, '\n')
+			if i < 0 {
+				f.line = append(f.line, data...)
+				return nil
+			}
+			f.line = append(f.line, data[:i]...)
+			data = data[i+1:]
+			if err := f.flushLine(); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		_, err := f.w.Write(data)
+		return err
+	}
+}
+
+func (f *captureFormatter) flushLine() error {
+	line := bytes.TrimSuffix(f.line, []byte("\r"))
+	f.line = f.line[:0]
+	_, err := fmt.Fprintf(f.w, "%s %s\n", f.lineAt.Format("2006-01-02T15:04:05.000000Z07:00"), line)
+	return err
+}
+
+// close writes out any partial line or hexdump row
+func (f *captureFormatter) close() error {
+	switch f.format {
+	case "hex":
+		if f.col > 0 {
+			_, err := io.WriteString(f.w, "\n")
+			return err
+		}
+	case "hexdump":
+		return f.dumper.Close()
+	case "lines":
+		if len(f.line) > 0 {
+			return f.flushLine()
 		}
 	}
+	return nil
 }
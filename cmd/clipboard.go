@@ -0,0 +1,62 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// clipboardCommands are tried in order when the desktop session they serve
+// is present; each reads the text to copy from stdin
+var clipboardCommands = []struct {
+	env  string // Variable that must be set, or "" for none
+	name string
+	args []string
+}{
+	{"WAYLAND_DISPLAY", "wl-copy", nil},
+	{"DISPLAY", "xclip", []string{"-selection", "clipboard"}},
+	{"DISPLAY", "xsel", []string{"--clipboard", "--input"}},
+}
+
+// copyToClipboard puts text on the system clipboard and returns how
+// A local clipboard tool is used when one is available; otherwise an OSC 52
+// escape sequence asks the terminal to set its clipboard, which also works
+// over SSH in terminals that support it.
+func copyToClipboard(text string) (string, error) {
+	if runtime.GOOS == "darwin" {
+		if err := pipeTo("pbcopy", nil, text); err == nil {
+			return "pbcopy", nil
+		}
+	}
+	for _, c := range clipboardCommands {
+		if os.Getenv(c.env) == "" {
+			continue
+		}
+		if _, err := exec.LookPath(c.name); err != nil {
+			continue
+		}
+		if err := pipeTo(c.name, c.args, text); err != nil {
+			logger.Debug("clipboard command failed", "command", c.name, "err", err)
+			continue
+		}
+		return c.name, nil
+	}
+
+	if _, err := os.Stdout.WriteString(ansi.SetSystemClipboard(text)); err != nil {
+		return "", fmt.Errorf("failed to write OSC 52 sequence: %w", err)
+	}
+	return "OSC 52", nil
+}
+
+func pipeTo(name string, args []string, text string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(text)
+	return cmd.Run()
+}
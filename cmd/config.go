@@ -0,0 +1,232 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/allbin/go-serial"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// Config file layout. Settings are named after command flags, so a profile
+// holds whatever the commands it is used with accept; flags a command does
+// not define are ignored. Flags given on the command line always win, then
+// the profile, then the device defaults.
+//
+//	profiles:
+//	  bench:
+//	    port: /dev/ttyUSB0     # Used when the port argument is omitted
+//	    baud: 9600
+//	    parity: even
+//	    flow-control: rtscts
+//	    macros: ~/macros.yaml
+//	devices:
+//	  - serial: FT4ABC12       # USB serial number, as shown by "serial info"
+//	    baud: 57600
+//	    no-timestamps: true
+
+var profileName string
+
+// profile holds the settings of the --profile profile, nil if none was given
+var profile map[string]any
+
+// deviceDefaults holds the per-device settings, keyed by USB serial number
+var deviceDefaults map[string]map[string]any
+
+// findConfigFile returns the config file to read: --config if given, else
+// the first of ~/.config/serial/config.yaml and ~/.serial.yaml that exists
+func findConfigFile() string {
+	if cfgFile != "" {
+		return cfgFile
+	}
+	var candidates []string
+	if dir, err := os.UserConfigDir(); err == nil {
+		candidates = append(candidates, filepath.Join(dir, "serial", "config.yaml"))
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		candidates = append(candidates, filepath.Join(home, ".serial.yaml"))
+	}
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// loadProfiles reads the selected profile and the device defaults from the
+// config file viper has read
+func loadProfiles() error {
+	var profiles map[string]map[string]any
+	if err := viper.UnmarshalKey("profiles", &profiles); err != nil {
+		return fmt.Errorf("profiles: %w", err)
+	}
+	for name, settings := range profiles {
+		if err := checkSettings(settings); err != nil {
+			return fmt.Errorf("profile %s: %w", name, err)
+		}
+	}
+
+	var devices []map[string]any
+	if err := viper.UnmarshalKey("devices", &devices); err != nil {
+		return fmt.Errorf("devices: %w", err)
+	}
+	deviceDefaults = make(map[string]map[string]any, len(devices))
+	for i, settings := range devices {
+		sn := fmt.Sprint(settings["serial"])
+		if settings["serial"] == nil || sn == "" {
+			return fmt.Errorf("device %d: missing serial", i+1)
+		}
+		delete(settings, "serial")
+		if _, ok := settings["port"]; ok {
+			return fmt.Errorf("device %s: port cannot be set per device", sn)
+		}
+		if err := checkSettings(settings); err != nil {
+			return fmt.Errorf("device %s: %w", sn, err)
+		}
+		deviceDefaults[sn] = settings
+	}
+
+	if profileName == "" {
+		return nil
+	}
+	// Viper folds keys to lower case
+	settings, ok := profiles[strings.ToLower(profileName)]
+	if !ok {
+		if viper.ConfigFileUsed() == "" {
+			return fmt.Errorf("profile %q: no config file found", profileName)
+		}
+		return fmt.Errorf("profile %q not found", profileName)
+	}
+	profile = settings
+	return nil
+}
+
+// checkSettings rejects settings that no command has a flag for, so a typo
+// is not silently ignored
+func checkSettings(settings map[string]any) error {
+	for key := range settings {
+		if key != "port" && !isCommandFlag(rootCmd, key) {
+			return fmt.Errorf("unknown setting %q", key)
+		}
+	}
+	return nil
+}
+
+func isCommandFlag(cmd *cobra.Command, name string) bool {
+	if cmd.Flags().Lookup(name) != nil {
+		return true
+	}
+	for _, c := range cmd.Commands() {
+		if isCommandFlag(c, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyConfig sets the flags of cmd that were not given on the command line
+// from the profile, then from the defaults of the device the port argument
+// names
+func applyConfig(cmd *cobra.Command, args []string) error {
+	if err := applySettings(cmd, profile); err != nil {
+		return fmt.Errorf("profile %s: %w", profileName, err)
+	}
+	if len(deviceDefaults) == 0 {
+		return nil
+	}
+	ports := append([]string{}, args...)
+	if port := profilePort(); port != "" {
+		ports = append(ports, port)
+	}
+	for _, arg := range ports {
+		info, err := serial.GetPortInfo(arg)
+		if err != nil || info.SerialNumber == "" {
+			continue
+		}
+		if settings, ok := deviceDefaults[info.SerialNumber]; ok {
+			logger.Debug("applying device defaults", "port", arg, "serial", info.SerialNumber)
+			if err := applySettings(cmd, settings); err != nil {
+				return fmt.Errorf("device %s: %w", info.SerialNumber, err)
+			}
+			break
+		}
+	}
+	return nil
+}
+
+func applySettings(cmd *cobra.Command, settings map[string]any) error {
+	for key, value := range settings {
+		flag := cmd.Flags().Lookup(key)
+		if flag == nil || flag.Changed {
+			continue
+		}
+		if err := setFlag(cmd.Flags(), key, value); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// setFlag sets a flag from a YAML value; a list sets a slice flag element
+// by element
+func setFlag(flags *pflag.FlagSet, name string, value any) error {
+	values, ok := value.([]any)
+	if !ok {
+		values = []any{value}
+	}
+	for _, v := range values {
+		s := fmt.Sprint(v)
+		if strings.HasPrefix(s, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				s = filepath.Join(home, s[2:])
+			}
+		}
+		if err := flags.Set(name, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// profilePort returns the port named by the profile, or "" if none
+func profilePort() string {
+	port, _ := profile["port"].(string)
+	return port
+}
+
+// portArgs accepts n arguments, the first of which is the port, or n-1 if
+// the profile names the port
+func portArgs(n int) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if len(args) == n-1 && profilePort() != "" {
+			return nil
+		}
+		return cobra.ExactArgs(n)(cmd, args)
+	}
+}
+
+// withProfilePort prepends the profile's port to args if it has n-1 elements
+func withProfilePort(args []string, n int) []string {
+	if len(args) == n-1 && profilePort() != "" {
+		return append([]string{profilePort()}, args...)
+	}
+	return args
+}
+
+// isPortPath reports whether arg names a device or network port rather
+// than data
+func isPortPath(arg string) bool {
+	if strings.Contains(arg, "://") {
+		return true
+	}
+	fi, err := os.Stat(arg)
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
@@ -4,17 +4,25 @@ Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
 package cmd
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/allbin/go-serial"
+	"github.com/allbin/go-serial/internal/tui/colors"
 	"github.com/allbin/go-serial/internal/tui/components"
 	"github.com/allbin/go-serial/internal/tui/keys"
 	"github.com/allbin/go-serial/internal/tui/models"
+	"github.com/allbin/go-serial/internal/tui/styles"
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
@@ -26,11 +34,12 @@ import (
 const (
 	statusBarHeight = 1 // Status bar at bottom
 	inputAreaHeight = 3 // Input field with border
+	plotHeight      = 8 // Plot title and graph, when --plot is given
 )
 
 // connectCmd represents the connect command
 var connectCmd = &cobra.Command{
-	Use:   "connect <port>",
+	Use:   "connect <port> [second-port]",
 	Short: "Connect to a serial port with bidirectional communication",
 	Long: `Connect to a serial port with a beautiful bidirectional terminal interface.
 
@@ -41,17 +50,131 @@ with real-time bidirectional communication. Features include:
 - ASCII and hex display modes
 - Connection status indicators
 - Configurable baud rate and flow control
-- CTS flow control monitoring and debugging
+- CTS flow control monitoring and debugging, with a timeline of CTS edges
+  above the TX attempts they held up (C, --timeline-span)
 - Configurable CTS timeout handling
+- F1-F12 macros with canned ASCII/hex payloads and optional repeat
+- Triggers that answer matching input automatically (--macros, T to pause)
+- Logging of everything shown to a file (--log, or toggle with L)
+- Exporting the scrollback to a file after the fact (w): raw received bytes
+  (.bin), hex lines (.hex) or the --log text format
+- Scrollback search for text or hex bytes (/, then n/N)
+- Filtering the display by regex or hex prefix, "!" to hide matches
+  (--filter, or f); hidden messages are still logged
+- Coloring of errors, NAKs, Modbus exceptions and data matching the
+  highlights rules of the config file (--no-highlights to turn off)
+- Live plot of numbers taken from the data by a regex or binary field rule
+  (--plot, p to show or hide)
+- Byte inspector for the row under the cursor in visual mode (x): hexdump,
+  the cursor byte in decimal and binary, and 16/32-bit values little and
+  big endian from the cursor (move it with left/right)
+- Copying rows selected in visual mode to the clipboard as text (y) or hex (Y)
+- Mouse wheel scrolling and drag selection of rows, copied as text on
+  release; both leave follow mode until G
+- Bracketed paste: text that spans lines or exceeds one chunk asks for
+  confirmation, then goes out in chunks (--paste-chunk) with a pause after
+  each write (--paste-delay); esc stops it
+- Raw keystroke mode (ctrl+], or --raw) that sends each key as typed,
+  control characters and escape sequences included, for device shells and
+  bootloaders; ctrl+] returns to normal mode
+- Input history per sending mode, kept across sessions (up/down, ctrl+r to
+  search; --no-history to turn off)
+- Timestamps as time of day, delta from the previous message or time since
+  a mark (cycle with d; m marks the cursor row in visual mode, else now)
+- Selectable TX line ending (--line-ending, or cycle with E) and local echo
+  of sent data (--echo, or toggle with e)
+- Two ports side by side (connect <port> <second-port>), ctrl+o to switch
+- More ports in tabs of the same terminal (":open <port> [baud]", ":close"),
+  shown in the status line; alt+left/right or alt+1-9 switch tabs
+- Bounded scrollback (--scrollback, --scrollback-bytes) that drops the oldest data first
+- Scripted send/expect sequences and auto-responses (--script), also headless
+- Break signal on ctrl+b, to wake bootloaders and SBC consoles
+- RTS/DTR toggles (R, D) and live CTS/DSR/DCD/RI states in the status bar
+- Automatic reopening of a lost port, such as an unplugged USB adapter,
+  keeping the scrollback (--reconnect, --match-serial)
+- Plain line-oriented stdin/stdout without the terminal interface (--plain),
+  for pipelines, dumb SSH sessions and services; --no-timestamps and --raw
+  apply to its output
 - Clean, responsive interface
 
 Example usage:
   serial connect /dev/ttyUSB0
   serial connect /dev/ttyUSB0 --baud 9600
   serial connect /dev/ttyUSB0 --flow-control cts --initial-rts
-  serial connect /dev/ttyUSB0 --flow-control cts --initial-rts --cts-timeout 1000`,
-	Args: cobra.ExactArgs(1),
+  serial connect /dev/ttyUSB0 --flow-control cts --initial-rts --cts-timeout 1000
+  serial connect /dev/ttyUSB0 --macros macros.yaml
+  serial connect /dev/ttyUSB0 --log bringup.log
+  serial connect /dev/ttyUSB0 --break-duration 500ms
+  serial connect /dev/ttyUSB0 --match-serial
+  serial connect /dev/ttyUSB0 --reconnect 0
+  serial connect /dev/ttyUSB0 --paste-chunk 16 --paste-delay 50ms
+  serial connect /dev/ttyUSB0 --raw
+  serial connect /dev/ttyUSB0 /dev/ttyUSB1
+  serial connect /dev/ttyUSB0 --line-ending cr --echo=false
+  serial connect /dev/ttyUSB0 --filter '!^HB'
+  serial connect /dev/ttyUSB0 --plot 'T=(-?[0-9.]+)'
+  serial connect /dev/ttyUSB0 --plot '01 03@3:s16be*0.1'
+  serial connect /dev/ttyUSB0 --scrollback 50000 --scrollback-bytes 67108864
+  serial connect /dev/ttyUSB0 --script selftest.yaml
+  serial connect /dev/ttyUSB0 --script selftest.yaml --headless --log selftest.log
+  printf 'AT\nATI\n' | serial connect /dev/ttyUSB0 --plain
+
+With a second port the two terminals open side by side, each with its own
+input; ctrl+o moves the focus between them. Both panes take timestamps from
+the same clock, so requests on one port and responses on the other line up.
+Port options apply to both ports; --script and --log need a single port.
+
+When the port is lost, for example because the adapter was unplugged, the
+status bar says so and connect tries to reopen it every --reconnect (0 to
+stay disconnected). With --match-serial it reopens the USB adapter with the
+same serial number wherever it reappears, such as /dev/ttyUSB1 instead of
+/dev/ttyUSB0. A running --script stops when the port is lost.
+
+Macro file format (F-key starts a macro; pressing it again stops a repeating one).
+Triggers answer matching input for as long as connect runs; T pauses them and
+each firing is noted in the terminal and the log:
+  macros:
+    - key: f1
+      name: Ping
+      hex: "02 06 00 03 00 00 00 99"
+    - key: f2
+      name: Status
+      ascii: "AT+STATUS\r"
+      repeat: 1s
+  triggers:
+    - name: keepalive        # Optional label for the activity log
+      on: "PING"             # Or on-hex; answered with send or send-hex
+      send: "PONG\r\n"
+    - on-hex: "10 02"
+      send-hex: "06"
+      once: true             # Fire the first time only
+
+--script runs a script in the format of "serial send --script" while the
+traffic stays visible, with two additions: loop steps and triggers that
+answer matching input for as long as the script runs. A script with only
+triggers answers until you quit. --headless runs the script without the
+terminal interface, printing traffic as log lines, and exits with status
+2 if an expect step timed out:
+  triggers:
+    - on: "RING"             # Same format as macro file triggers
+      send: "ATA\r"
+  steps:
+    - send: "AT\r"
+    - expect: "OK"
+    - loop:
+        count: 10            # 0 loops until you quit
+        steps:
+          - send-hex: "01 03 00 00 00 01"
+          - expect-hex: "01 03"
+          - delay: 500ms`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 2 {
+			return nil
+		}
+		return portArgs(1)(cmd, args)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
+		args = withProfilePort(args, 1)
 		portPath := args[0]
 
 		// Get flags
@@ -60,20 +183,40 @@ Example usage:
 		ctsTimeoutMs, _ := cmd.Flags().GetInt("cts-timeout")
 		syncWrites, _ := cmd.Flags().GetBool("sync-writes")
 		initialRTS, _ := cmd.Flags().GetBool("initial-rts")
+		macrosPath, _ := cmd.Flags().GetString("macros")
+		logPath, _ := cmd.Flags().GetString("log")
+		scriptPath, _ := cmd.Flags().GetString("script")
+		headless, _ := cmd.Flags().GetBool("headless")
+		breakDuration, _ := cmd.Flags().GetDuration("break-duration")
+		timelineSpan, _ := cmd.Flags().GetDuration("timeline-span")
+		pasteChunk, _ := cmd.Flags().GetInt("paste-chunk")
+		pasteDelay, _ := cmd.Flags().GetDuration("paste-delay")
+		raw, _ := cmd.Flags().GetBool("raw")
+		lineEnding, _ := cmd.Flags().GetString("line-ending")
+		echo, _ := cmd.Flags().GetBool("echo")
+		noHistory, _ := cmd.Flags().GetBool("no-history")
+		reconnect, _ := cmd.Flags().GetDuration("reconnect")
+		matchSerial, _ := cmd.Flags().GetBool("match-serial")
 
 		// Configure port options
 		opts := []serial.Option{
 			serial.WithBaudRate(baudRate),
 			serial.WithCTSTimeout(time.Duration(ctsTimeoutMs) * time.Millisecond),
+			withDiagnostics(),
+		}
+
+		lineOpts, err := lineOptions(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
+		opts = append(opts, lineOpts...)
 
 		// Configure write mode
 		if syncWrites {
-			fmt.Fprintf(os.Stderr, "[DEBUG] Sync writes enabled via flag\n")
 			opts = append(opts, serial.WithSyncWrite())
-		} else {
-			fmt.Fprintf(os.Stderr, "[DEBUG] Sync writes disabled (default buffered)\n")
 		}
+		logger.Debug("configured write mode", "sync_writes", syncWrites)
 
 		switch strings.ToLower(flowControl) {
 		case "cts":
@@ -88,8 +231,122 @@ Example usage:
 			}
 		}
 
+		var script *sendScript
+		if scriptPath != "" {
+			script, err = loadScript(scriptPath)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		if len(args) == 2 && (script != nil || logPath != "") {
+			fmt.Fprintf(os.Stderr, "Error: --script and --log take a single port\n")
+			os.Exit(1)
+		}
+
+		if headless {
+			if script == nil {
+				fmt.Fprintf(os.Stderr, "Error: --headless requires --script\n")
+				os.Exit(1)
+			}
+			err := runConnectHeadless(portPath, script, logPath, opts...)
+			if errors.Is(err, errNoMatch) {
+				os.Exit(2)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
 		// Start the TUI
-		if err := runConnectTUI(portPath, opts...); err != nil {
+		scrollback, err := scrollbackOptions(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if reconnect < 0 {
+			fmt.Fprintf(os.Stderr, "Error: --reconnect must not be negative\n")
+			os.Exit(1)
+		}
+		if breakDuration <= 0 {
+			fmt.Fprintf(os.Stderr, "Error: --break-duration must be positive\n")
+			os.Exit(1)
+		}
+		if timelineSpan <= 0 {
+			fmt.Fprintf(os.Stderr, "Error: --timeline-span must be positive\n")
+			os.Exit(1)
+		}
+		if pasteChunk <= 0 {
+			fmt.Fprintf(os.Stderr, "Error: --paste-chunk must be positive\n")
+			os.Exit(1)
+		}
+		if pasteDelay < 0 {
+			fmt.Fprintf(os.Stderr, "Error: --paste-delay must not be negative\n")
+			os.Exit(1)
+		}
+		lineEnding = strings.ToLower(lineEnding)
+		if _, ok := lineEndings[lineEnding]; !ok {
+			fmt.Fprintf(os.Stderr, "Error: unknown line ending %q (want none, lf, cr or crlf)\n", lineEnding)
+			os.Exit(1)
+		}
+		filter, err := filterOptions(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		plot, _ := cmd.Flags().GetString("plot")
+		if plot != "" {
+			if _, err := parsePlotRule(plot); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: invalid --plot %q: %v\n", plot, err)
+				os.Exit(1)
+			}
+		}
+		settings := connectSettings{
+			filter:      filter,
+			plot:        plot,
+			highlights:  highlightOptions(cmd),
+			lineEnding:  lineEnding,
+			noEcho:      !echo,
+			noHistory:   noHistory,
+			macrosPath:  macrosPath,
+			logPath:     logPath,
+			script:      script,
+			breakLen:    breakDuration,
+			timeline:    timelineSpan,
+			pasteChunk:  pasteChunk,
+			pasteDelay:  pasteDelay,
+			raw:         raw,
+			reconnect:   reconnect,
+			matchSerial: matchSerial,
+			scrollback:  scrollback,
+		}
+
+		if plain, _ := cmd.Flags().GetBool("plain"); plain {
+			if len(args) == 2 || script != nil {
+				fmt.Fprintf(os.Stderr, "Error: --plain takes a single port and no --script (see --headless)\n")
+				os.Exit(1)
+			}
+			noTimestamps, _ := cmd.Flags().GetBool("no-timestamps")
+			if err := runConnectPlain(portPath, settings, noTimestamps, opts...); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		// A second port opens a split view
+		if len(args) == 2 {
+			if err := runConnectSplit([2]string{args[0], args[1]}, settings, opts...); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		if err := runConnectTUI(portPath, settings, opts...); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
@@ -101,26 +358,170 @@ func init() {
 
 	// Add flags for serial configuration
 	connectCmd.Flags().IntP("baud", "b", 115200, "Baud rate (default: 115200)")
+	addLineFlags(connectCmd, 2500*time.Millisecond)
 	connectCmd.Flags().StringP("flow-control", "f", "none", "Flow control: none, cts, rtscts (default: none)")
 	connectCmd.Flags().IntP("cts-timeout", "t", 500, "CTS timeout in milliseconds (default: 500)")
 	connectCmd.Flags().Bool("sync-writes", false, "Enable synchronous writes (O_SYNC) for guaranteed transmission")
 	connectCmd.Flags().Bool("initial-rts", false, "Assert RTS on port open (required for CTS flow control)")
+	connectCmd.Flags().String("macros", "", "YAML file binding F1-F12 to ASCII/hex payloads and defining triggers")
+	connectCmd.Flags().String("log", "", "Append everything shown in the terminal to this file (toggle with L)")
+	connectCmd.Flags().String("script", "", "Run send/expect/loop steps and triggers from a YAML script")
+	connectCmd.Flags().Bool("headless", false, "Run --script without the terminal interface")
+	connectCmd.Flags().Bool("plain", false, "Send stdin lines and print traffic to stdout without the terminal interface")
+	connectCmd.Flags().Bool("no-timestamps", false, "Hide timestamps from --plain output")
+	addScrollbackFlags(connectCmd)
+	addHighlightFlag(connectCmd)
+	addFilterFlag(connectCmd)
+	connectCmd.Flags().String("plot", "", "Plot numbers from a regex capture group or a [hex-prefix]@offset:type[*scale] field")
+	connectCmd.Flags().String("line-ending", "lf", "Terminator added to ASCII input: none, lf, cr, crlf (cycle with E)")
+	connectCmd.Flags().Bool("no-history", false, "Do not load or save the input history (~/.local/share/serial/history)")
+	connectCmd.Flags().Bool("echo", true, "Show sent data in the terminal; disable for devices that echo input (toggle with e)")
+	connectCmd.Flags().Duration("break-duration", 250*time.Millisecond, "Length of the break sent with ctrl+b")
+	connectCmd.Flags().Duration("timeline-span", 5*time.Second, "Time shown across the CTS timeline (toggle with C)")
+	connectCmd.Flags().Int("paste-chunk", 64, "Bytes per write when sending pasted text")
+	connectCmd.Flags().Duration("paste-delay", 20*time.Millisecond, "Pause after each written chunk of pasted text")
+	connectCmd.Flags().Bool("raw", false, "Start in raw mode, sending each keystroke as typed (ctrl+] toggles)")
+	connectCmd.Flags().Duration("reconnect", time.Second, "Delay between attempts to reopen a lost port (0 to not reopen)")
+	connectCmd.Flags().Bool("match-serial", false, "Reopen a lost USB port by its serial number, whichever path it comes back on")
+}
+
+// connectSettings configures a connect terminal beyond its port options
+type connectSettings struct {
+	lineEnding  string                     // lineEndings key appended to ASCII input
+	noEcho      bool                       // Sent data is not shown
+	noHistory   bool                       // Input history is not loaded or saved
+	highlights  []components.HighlightRule // Rules coloring matching data
+	filter      string                     // --filter expression, if any
+	plot        string                     // --plot rule, if any
+	macrosPath  string                     // --macros file, if any
+	logPath     string                     // --log file, if any
+	script      *sendScript                // --script to run, if any
+	breakLen    time.Duration              // Length of the break sent with ctrl+b
+	timeline    time.Duration              // Time shown across the CTS timeline
+	pasteChunk  int                        // Bytes per write of pasted text
+	pasteDelay  time.Duration              // Pause after each written chunk of pasted text
+	raw         bool                       // Start in raw keystroke mode
+	reconnect   time.Duration              // Delay between attempts to reopen a lost port; 0 to not reopen
+	matchSerial bool                       // Reopen a lost port by its USB serial number
+	scrollback  scrollbackLimit
 }
 
 // connectModel represents the Bubble Tea model for the connect command
 type connectModel struct {
 	*models.SerialModel
-	terminal  *components.TerminalTable
-	statusBar *components.StatusBar
-	input     *components.Input
-	help      help.Model
-	keys      keys.ConnectKeys
-	width     int // Terminal width
-	height    int // Terminal height
+	terminal    *components.TerminalTable
+	statusBar   *components.StatusBar
+	input       *components.Input
+	macroBar    *components.MacroBar
+	triggers    []*scriptTrigger // --macros triggers, answered from Update
+	triggerOff  bool             // Triggers are paused
+	plotRule    *plotRule        // --plot rule, nil if none
+	plot        *components.Plot // Graph of the samples plotRule finds
+	inspector   *components.Inspector
+	inspecting  bool // The inspector is open; it shows in visual mode only
+	dragFrom    int  // Row where a mouse drag started, -1 if none
+	dragged     bool // The mouse moved while the button was held
+	export      *components.Prompt
+	command     *components.Prompt
+	search      *components.Search
+	filter      *components.Filter
+	macroGen    map[string]int // Invalidates pending ticks of stopped macros
+	timeline    *components.Timeline
+	timelineGen int           // Invalidates pending ticks of a hidden timeline
+	paste       *pasteJob     // Pasted text being confirmed or sent, if any
+	pasteGen    int           // Invalidates pending chunks of a stopped paste
+	pasteChunk  int           // Bytes per write of pasted text
+	pasteDelay  time.Duration // Pause after each written chunk of pasted text
+	keystrokes  chan []byte   // Raw mode keystrokes, written in order by writeKeystrokes
+	log         *terminalLog  // Nil while not logging
+	logPath     string        // File L logs to; empty for a generated name
+	breakLen    time.Duration // Length of the break sent with ctrl+b
+	breaking    bool          // A break is being transmitted
+	lineEnd     string        // lineEndings key appended to ASCII input
+	noEcho      bool          // Sent data is logged but not shown
+	noHistory   bool          // Input history is not loaded or saved
+	noSignals   bool          // Modem signals cannot be read from the port
+	reconnect   time.Duration // Delay between attempts to reopen a lost port
+	matchSerial bool          // Reopen a lost port by its USB serial number
+	help        help.Model
+	keys        keys.ConnectKeys
+	width       int // Terminal width
+	height      int // Terminal height
+}
+
+// portLostMsg reports that reading the port failed, such as after the
+// device was unplugged; the port has been closed
+type portLostMsg struct {
+	err error
+}
+
+// portReopenedMsg reports that a lost port was opened again, at path
+type portReopenedMsg struct {
+	path string
+}
+
+// scriptStatusMsg reports script progress in the terminal
+type scriptStatusMsg struct {
+	text string
+}
+
+// breakDoneMsg reports the end of a break started with ctrl+b
+type breakDoneMsg struct {
+	err error
+}
+
+// signalsMsg reports the modem signal states
+type signalsMsg struct {
+	signals serial.ModemSignals
+	at      time.Time
+	err     error // Signals cannot be read; no further updates follow
 }
 
-func runConnectTUI(portPath string, opts ...serial.Option) error {
-	fmt.Fprintf(os.Stderr, "[DEBUG] Starting connect TUI\n")
+// timelineTickMsg redraws the CTS timeline as time passes
+type timelineTickMsg struct {
+	gen int
+}
+
+// pasteChunkMsg reports that a chunk of a paste was written, or failed
+type pasteChunkMsg struct {
+	gen    int
+	status components.DataReceivedMsg
+}
+
+// keystrokeErrMsg reports a raw mode keystroke that could not be written
+type keystrokeErrMsg struct {
+	err error
+}
+
+// pasteTickMsg sends the next chunk of a paste
+type pasteTickMsg struct {
+	gen int
+}
+
+// signalToggledMsg reports the outcome of toggling RTS or DTR
+type signalToggledMsg struct {
+	name  string
+	state bool
+	err   error
+}
+
+// macroTickMsg resends a repeating macro
+type macroTickMsg struct {
+	macro *components.Macro
+	gen   int
+}
+
+// newConnectModel creates the terminal for one port; start connects it
+func newConnectModel(portPath string, settings connectSettings, opts ...serial.Option) (*connectModel, error) {
+	var macros []*components.Macro
+	var triggers []*scriptTrigger
+	if settings.macrosPath != "" {
+		var err error
+		macros, triggers, err = loadMacros(settings.macrosPath)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	// Create configuration from options to show in status bar
 	config := serial.DefaultConfig()
@@ -140,79 +541,919 @@ func runConnectTUI(portPath string, opts ...serial.Option) error {
 
 	// Create initial model with minimal dimensions - let WindowSizeMsg set proper size
 	serialModel := models.NewSerialModel(portPath)
-	m := connectModel{
+	serialModel.SetScrollbackLimit(settings.scrollback.messages, settings.scrollback.bytes)
+	m := &connectModel{
 		SerialModel: serialModel,
 		terminal:    components.NewTerminalTable(0, 0), // Will be properly sized by WindowSizeMsg
 		statusBar:   components.NewStatusBar("Serial Connect", portPath),
 		input:       components.NewInput("Type message and press Enter to send..."),
+		macroBar:    components.NewMacroBar(macros),
+		triggers:    triggers,
+		search:      components.NewSearch(),
+		filter:      components.NewFilter(),
+		inspector:   components.NewInspector(),
+		dragFrom:    -1,
+		export:      components.NewPrompt("export to: ", "file; .bin for raw received bytes, .hex for hex lines, else text"),
+		command:     components.NewPrompt(":", "open <port> [baud], close"),
+		macroGen:    make(map[string]int),
+		timeline:    components.NewTimeline(settings.timeline),
+		pasteChunk:  settings.pasteChunk,
+		pasteDelay:  settings.pasteDelay,
+		keystrokes:  make(chan []byte, 256),
+		logPath:     settings.logPath,
+		breakLen:    settings.breakLen,
+		lineEnd:     settings.lineEnding,
+		noEcho:      settings.noEcho,
+		noHistory:   settings.noHistory,
+		reconnect:   settings.reconnect,
+		matchSerial: settings.matchSerial,
 		help:        help.New(),
 		keys:        keys.NewConnectKeys(),
 	}
+	if !m.noHistory {
+		if err := loadInputHistory(m.input); err != nil {
+			logger.Warn("failed to load input history", "err", err)
+		}
+	}
+	m.terminal.SetHighlights(settings.highlights)
+	m.terminal.SetTXStatus(true)
+	m.filter.Set(settings.filter) // Checked by filterOptions
+	if settings.plot != "" {
+		m.plotRule, _ = parsePlotRule(settings.plot) // Checked by the caller
+		m.plot = components.NewPlot(settings.plot, plotHeight)
+		m.plot.SetVisible(true)
+	}
+	m.terminal.SetFilter(m.filter)
 	m.statusBar.SetConnecting()
 	m.statusBar.SetConnectionInfo(connInfo)
 
-	// Start the TUI with alt screen and input handling
-	p := tea.NewProgram(&m, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	if settings.logPath != "" {
+		log, err := openTerminalLog(settings.logPath, portPath)
+		if err != nil {
+			return nil, err
+		}
+		m.log = log
+		m.statusBar.SetLogging(true)
+	}
+	if settings.raw {
+		m.SetInputMode(models.InputModeRaw)
+	}
+	return m, nil
+}
 
+// start opens the port in the background and delivers its status, data and
+// script progress to the model through send, such as tea.Program.Send
+func (m *connectModel) start(send func(tea.Msg), script *sendScript, opts ...serial.Option) {
 	// Connect to serial port in background
-	go func() {
-		port, err := serial.Open(portPath, opts...)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "[ERROR] Failed to open port: %v\n", err)
-			p.Send(models.ConnectionStatusMsg{Connected: false, Error: err})
+	go m.run(send, script, opts...)
+	go m.writeKeystrokes(send)
+}
+
+// writeKeystrokes writes raw mode keystrokes to the port one at a time, so
+// they arrive in the order typed
+func (m *connectModel) writeKeystrokes(send func(tea.Msg)) {
+	ctx := m.GetContext()
+	for {
+		select {
+		case <-ctx.Done():
 			return
+		case data := <-m.keystrokes:
+			port := m.GetPort()
+			if port == nil {
+				continue
+			}
+			if _, err := port.WriteContext(ctx, data); err != nil && ctx.Err() == nil {
+				send(keystrokeErrMsg{err: err})
+			}
 		}
+	}
+}
 
+// sendKeystroke queues what a key sends in raw mode
+func (m *connectModel) sendKeystroke(msg tea.KeyMsg) {
+	data := keystrokeBytes(msg)
+	if len(data) == 0 {
+		return
+	}
+	if m.GetPort() == nil {
+		m.showNote("Keystroke not sent: port not connected")
+		return
+	}
+	select {
+	case m.keystrokes <- data:
+	default:
+		m.showNote("Keystroke dropped: the port is not keeping up")
+	}
+}
+
+// setRawMode enters or leaves raw keystroke mode
+func (m *connectModel) setRawMode(raw bool) {
+	if raw {
+		m.SetInputMode(models.InputModeRaw)
+		m.input.Blur()
+		m.showNote("Raw mode: keys go straight to the port; ctrl+] leaves")
+		return
+	}
+	m.SetInputMode(models.InputModeNormal)
+	m.showNote("Raw mode off")
+}
+
+// run opens the port and shows what it reads until the context ends; a
+// lost port is reopened after the --reconnect delay, keeping the scrollback
+func (m *connectModel) run(send func(tea.Msg), script *sendScript, opts ...serial.Option) {
+	ctx := m.GetContext()
+	portPath := m.GetPortPath()
+
+	port, err := serial.Open(portPath, opts...)
+	if err != nil {
+		logger.Error("failed to open port", "port", portPath, "err", err)
+		send(models.ConnectionStatusMsg{Connected: false, Error: err})
+		return
+	}
+
+	// Remember the adapter so --match-serial can find it on another path
+	var usbSerial string
+	if m.matchSerial {
+		if info, err := serial.GetPortInfo(portPath); err == nil {
+			usbSerial = info.SerialNumber
+		}
+	}
+
+	// Received data is also fed to the script, if any; a script does not
+	// survive a lost port
+	var scriptCh chan []byte
+	if script != nil {
+		scriptCh = make(chan []byte, 64)
+		go runConnectScript(ctx, send, port, script, scriptCh)
+	}
+
+	path := portPath
+	for {
 		// Store port safely
 		m.SetPort(port)
+		send(models.ConnectionStatusMsg{Connected: true, Error: nil})
+
+		connCtx, cancel := context.WithCancel(ctx)
+		go watchConnectSignals(connCtx, send, port)
+		err := m.readPort(connCtx, send, port, path, scriptCh)
+		cancel()
+
+		m.SetPort(nil)
+		port.Close()
+		if scriptCh != nil {
+			close(scriptCh)
+			scriptCh = nil
+		}
+		if ctx.Err() != nil {
+			return
+		}
 
-		p.Send(models.ConnectionStatusMsg{Connected: true, Error: nil})
+		logger.Warn("port lost", "port", path, "err", err)
+		send(portLostMsg{err: err})
+		if m.reconnect <= 0 {
+			return
+		}
+		if port, path = reopenPort(ctx, portPath, usbSerial, m.reconnect, opts...); port == nil {
+			return
+		}
+		send(portReopenedMsg{path: path})
+	}
+}
 
-		// Start reading data with context cancellation
-		go func() {
-			defer func() {
-				// Only close the port when this goroutine exits
-				if port != nil {
-					port.Close()
+// readPort sends what port reads as DataReceivedMsg, coalesced into
+// DataBatchMsg at high data rates, until ctx ends or the port is lost,
+// returning why it was lost
+func (m *connectModel) readPort(ctx context.Context, send func(tea.Msg), port serial.Port, path string, scriptCh chan<- []byte) error {
+	batch := components.NewBatcher(send, components.RenderInterval)
+	defer batch.Stop()
+
+	slab := serial.NewReadSlab(readSlabSize)
+	for {
+		n, at, err := serial.ReadTimestamped(ctx, port, slab.Next(4096))
+		if ctx.Err() != nil {
+			return ctx.Err() // Context cancelled, exit cleanly
+		}
+		if err != nil {
+			if errors.Is(err, syscall.EINTR) || errors.Is(err, syscall.EAGAIN) {
+				continue
+			}
+			return err
+		}
+		if n == 0 {
+			// A read timeout; an unplugged device also reads nothing, but
+			// its node disappears
+			if !strings.Contains(path, "://") {
+				if _, err := os.Stat(path); err != nil {
+					return err
 				}
-			}()
+			}
+			continue
+		}
 
-			buffer := make([]byte, 4096)
-			for {
+		// Send raw data with timestamp - formatting will happen in Update method
+		data := slab.Take(n)
+		batch.Send(components.DataReceivedMsg{
+			Timestamp: at,
+			Data:      data,
+		})
+		if scriptCh != nil {
+			select {
+			case scriptCh <- data:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// reopenPort tries to open portPath every delay until it succeeds or ctx
+// ends, returning nil then; with a USB serial number it opens whichever
+// port the adapter now has
+func reopenPort(ctx context.Context, portPath, usbSerial string, delay time.Duration, opts ...serial.Option) (serial.Port, string) {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ""
+		case <-time.After(delay):
+		}
+
+		path := portPath
+		if usbSerial != "" {
+			if found := findPortBySerial(usbSerial); found != "" {
+				path = found
+			}
+		}
+		port, err := serial.Open(path, opts...)
+		if err == nil {
+			return port, path
+		}
+		logger.Debug("reopen failed", "port", path, "err", err)
+	}
+}
+
+// findPortBySerial returns the port of the USB adapter with serial number
+// sn, or "" if it is not plugged in
+func findPortBySerial(sn string) string {
+	ports, err := serial.ListPorts()
+	if err != nil {
+		return ""
+	}
+	for _, path := range ports {
+		if info, err := serial.GetPortInfo(path); err == nil && info.SerialNumber == sn {
+			return path
+		}
+	}
+	return ""
+}
+
+// sendPayload writes data in the background and shows it as a pending TX
+// message; the returned command reports the final write status
+func (m *connectModel) sendPayload(port serial.Port, dataToSend, displayData []byte) tea.Cmd {
+	// Send the data with proper timeout handling and status updates
+	writeStatusCh := make(chan error, 1)
+
+	go func(port serial.Port, dataToSend []byte) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		_, err := port.WriteContext(ctx, dataToSend)
+		writeStatusCh <- err
+		close(writeStatusCh)
+	}(port, dataToSend)
+
+	// Get sequence number for this TX message
+	sequence := m.GetNextSequence()
+
+	// Capture enqueue time before creating goroutine
+	enqueuedTime := time.Now()
+
+	// Return single command for final status update
+	completion := func() tea.Msg {
+		err := <-writeStatusCh
+		writtenTime := time.Now() // Capture when write completed
+
+		if err != nil {
+			logger.Debug("write failed", "sequence", sequence, "err", err)
+		} else {
+			logger.Debug("write succeeded", "sequence", sequence, "bytes", len(dataToSend))
+		}
+
+		// Send completion status with same sequence number
+		finalStatus := components.DataReceivedMsg{
+			Timestamp:    writtenTime,
+			Data:         displayData,
+			IsTX:         true,
+			Sequence:     sequence,
+			EnqueuedTime: &enqueuedTime,
+			WrittenTime:  &writtenTime,
+		}
+		if err != nil {
+			// Check if it's a timeout error
+			if err == serial.ErrCTSTimeout || err == context.DeadlineExceeded {
+				finalStatus.Status = "TIMEOUT"
+			} else {
+				finalStatus.Status = "ERROR"
+			}
+		} else {
+			finalStatus.Status = "WRITTEN"
+		}
+		return finalStatus
+	}
+
+	// Add to display with TX prefix (initially as PENDING)
+	timestamp := enqueuedTime
+	txData := components.DataReceivedMsg{
+		Timestamp:    timestamp,
+		Data:         displayData,
+		IsTX:         true,
+		Status:       "PENDING",
+		Sequence:     sequence,
+		EnqueuedTime: &enqueuedTime,
+	}
+	// Add to both raw data store and terminal display
+	if !m.noEcho {
+		m.addMessage(txData)
+	}
+	return completion
+}
+
+// addMessage stores msg and shows it, rebuilding the table if the
+// scrollback limit evicted old messages
+func (m *connectModel) addMessage(msg components.DataReceivedMsg) {
+	m.appendMessage(msg)
+	m.terminal.Flush()
+}
+
+// appendMessage is addMessage without updating the table until the next
+// m.terminal.Flush
+func (m *connectModel) appendMessage(msg components.DataReceivedMsg) {
+	if msg.Status == "PENDING" {
+		m.timeline.AddTX(msg)
+	}
+	if m.AddRawData(msg) > 0 {
+		m.terminal.UpdateMessage(m.GetRawData())
+		m.statusBar.SetDropped(m.Dropped())
+		return
+	}
+	m.terminal.AppendMessage(msg)
+}
+
+// receive handles a message from the port or the script; the caller
+// flushes the terminal
+func (m *connectModel) receive(msg components.DataReceivedMsg) tea.Cmd {
+	// Safely handle the data message
+	defer func() {
+		if r := recover(); r != nil {
+			// If there's a panic in data handling, don't crash the whole UI
+			// Just continue running
+		}
+	}()
+
+	m.log.Write(msg)
+
+	// Only process data if we're ready (WindowSizeMsg has been received)
+	if m.IsReady() {
+		// The final status of a TX (WRITTEN, TIMEOUT or ERROR) replaces
+		// its PENDING row, found by sequence number
+		m.timeline.AddTX(msg)
+		if msg.IsTX && msg.Status != "PENDING" && msg.Status != "" && msg.Sequence > 0 {
+			if m.UpdateMessage(msg) {
+				// Message was updated, refresh terminal display
+				m.terminal.UpdateMessage(m.GetRawData())
+			}
+		} else {
+			// New message (including PENDING TX), add normally
+			m.appendMessage(msg)
+		}
+	}
+	if msg.IsTX {
+		return nil
+	}
+	if m.plotRule != nil {
+		for _, v := range m.plotRule.feed(msg.Data) {
+			m.plot.Add(v)
+		}
+	}
+	return m.answerTriggers(msg.Data)
+}
+
+// nextLineEnding returns the line ending after name in lineEndingNames
+func nextLineEnding(name string) string {
+	for i, n := range lineEndingNames {
+		if n == name {
+			return lineEndingNames[(i+1)%len(lineEndingNames)]
+		}
+	}
+	return lineEndingNames[0]
+}
+
+// yank copies the rows selected in visual mode to the clipboard, one row
+// per line as printable text or hex, and restarts the selection at the cursor
+func (m *connectModel) yank(asHex bool) {
+	rows := m.terminal.Selection()
+	if len(rows) == 0 {
+		m.showNote("Nothing to copy: select rows in visual mode (v) first")
+		return
+	}
+	format := "text"
+	if asHex {
+		format = "hex"
+	}
+	lines := make([]string, len(rows))
+	size := 0
+	for i, row := range rows {
+		if asHex {
+			lines[i] = fmt.Sprintf("% X", row.Data)
+		} else {
+			lines[i] = printableASCII(bytes.TrimRight(row.Data, "\r\n"))
+		}
+		size += len(row.Data)
+	}
+
+	via, err := copyToClipboard(strings.Join(lines, "\n"))
+	m.terminal.SetViewMode(components.ViewModeVisual)
+	if err != nil {
+		m.showNote(fmt.Sprintf("Copy failed: %v", err))
+		return
+	}
+	m.showNote(fmt.Sprintf("Copied %d row(s), %d bytes, as %s via %s", len(rows), size, format, via))
+}
+
+// toggleLog starts or stops logging the terminal to a file
+func (m *connectModel) toggleLog() {
+	var note string
+	if m.log != nil {
+		note = fmt.Sprintf("Stopped logging to %s", m.log.Path())
+		if err := m.log.Close(); err != nil {
+			note = fmt.Sprintf("Failed to close %s: %v", m.log.Path(), err)
+		}
+		m.log = nil
+	} else {
+		log, err := openTerminalLog(m.logPath, m.GetPortPath())
+		if err != nil {
+			note = err.Error()
+		} else {
+			m.log = log
+			note = fmt.Sprintf("Logging to %s", log.Path())
+		}
+	}
+	m.statusBar.SetLogging(m.log != nil)
+	m.terminal.AddMessage(components.DataReceivedMsg{
+		Timestamp: time.Now(),
+		Data:      []byte(note),
+	})
+}
+
+// runCommand carries out a : command line; the tab view opens and closes
+// the tabs asked for
+func (m *connectModel) runCommand(line string) tea.Cmd {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil
+	}
+	var msg tea.Msg
+	switch fields[0] {
+	case "open", "o":
+		if len(fields) < 2 || len(fields) > 3 {
+			m.showNote("Usage: :open <port> [baud]")
+			return nil
+		}
+		open := openTabMsg{path: fields[1]}
+		if len(fields) == 3 {
+			baud, err := strconv.Atoi(fields[2])
+			if err != nil || baud <= 0 {
+				m.showNote(fmt.Sprintf("Invalid baud rate %q", fields[2]))
+				return nil
+			}
+			open.baud = baud
+		}
+		msg = open
+	case "close", "c":
+		msg = closeTabMsg{}
+	default:
+		m.showNote(fmt.Sprintf("Unknown command %q (want open <port> [baud] or close)", fields[0]))
+		return nil
+	}
+	return func() tea.Msg { return msg }
+}
+
+// exportScrollback writes the scrollback to path and notes the outcome
+func (m *connectModel) exportScrollback(path string) {
+	msgs := m.GetRawData()
+	format, err := exportScrollback(path, m.GetPortPath(), msgs)
+	if err != nil {
+		m.showNote(fmt.Sprintf("Export failed: %v", err))
+		return
+	}
+	m.showNote(fmt.Sprintf("Exported %d messages to %s (%s)", len(msgs), path, format))
+}
+
+// sendBreak transmits a break in the background; breakDoneMsg reports
+// the outcome
+func (m *connectModel) sendBreak() tea.Cmd {
+	port := m.GetPort()
+	if port == nil || m.breaking {
+		return nil
+	}
+	m.breaking = true
+	m.statusBar.SetBreaking(true)
+	d := m.breakLen
+	return func() tea.Msg {
+		return breakDoneMsg{err: serial.SendBreak(port, d)}
+	}
+}
+
+// watchConnectSignals reports the modem signals now and after every change of an
+// input signal until ctx ends. It blocks in the kernel between changes rather
+// than polling, and reads the signals again before each wait so an edge that
+// came between two waits is shown at once instead of with the next one.
+func watchConnectSignals(ctx context.Context, send func(tea.Msg), port serial.Port) {
+	var last serial.ModemSignals
+	reported := false
+	report := func(signals serial.ModemSignals, err error) {
+		if err == nil && reported && signals == last {
+			return
+		}
+		send(signalsMsg{signals: signals, at: time.Now(), err: err})
+		last, reported = signals, true
+	}
+
+	for ctx.Err() == nil {
+		signals, err := port.GetModemSignals()
+		report(signals, err)
+		if err != nil {
+			return
+		}
+		signals, _, err = port.WaitForSignalChangeContext(ctx, serial.SignalCTS|serial.SignalDSR|serial.SignalRI|serial.SignalDCD)
+		if ctx.Err() != nil {
+			return
+		}
+		report(signals, err)
+		if err != nil {
+			return
+		}
+	}
+}
+
+// toggleSignal inverts RTS or DTR in the background
+func (m *connectModel) toggleSignal(name string) tea.Cmd {
+	port := m.GetPort()
+	if port == nil {
+		return nil
+	}
+	get, set := port.GetRTS, port.SetRTS
+	if name == "DTR" {
+		get, set = port.GetDTR, port.SetDTR
+	}
+	return func() tea.Msg {
+		state, err := get()
+		if err == nil {
+			err = set(!state)
+		}
+		return signalToggledMsg{name: name, state: !state, err: err}
+	}
+}
+
+// readSignals reads the modem signals once, such as after changing an output
+func readSignals(port serial.Port) tea.Cmd {
+	return func() tea.Msg {
+		signals, err := port.GetModemSignals()
+		return signalsMsg{signals: signals, at: time.Now(), err: err}
+	}
+}
+
+// showNote adds a line of commentary to the terminal and the log
+func (m *connectModel) showNote(text string) {
+	m.log.Note(text)
+	// Kept with the data so rebuilding the table does not drop it
+	m.addMessage(components.DataReceivedMsg{
+		Timestamp: time.Now(),
+		Data:      []byte(text),
+		IsNote:    true,
+	})
+}
+
+// layout sizes the components to the terminal, giving the table the
+// height the other sections leave
+func (m *connectModel) layout() {
+	// Total space - status bar - input area - optional bars
+	totalUIOverhead := statusBarHeight + inputAreaHeight + m.macroBar.Height()
+	if m.plot != nil {
+		totalUIOverhead += m.plot.Height()
+		m.plot.SetWidth(m.width)
+	}
+	totalUIOverhead += m.inspector.Height() + m.timeline.Height()
+	m.inspector.SetWidth(m.width)
+	m.timeline.SetWidth(m.width)
+	tableHeight := m.height - totalUIOverhead
+
+	// Ensure minimum height
+	if tableHeight < 5 {
+		tableHeight = 5
+	}
+
+	// Set component sizes
+	m.terminal.SetSize(m.width, tableHeight)
+	m.input.SetWidth(m.width)
+	m.statusBar.SetWidth(m.width)
+	m.macroBar.SetWidth(m.width)
+}
+
+// answerTriggers sends the responses of the --macros triggers that data
+// fires, noting each in the terminal and the log
+func (m *connectModel) answerTriggers(data []byte) tea.Cmd {
+	port := m.GetPort()
+	if m.triggerOff || port == nil {
+		return nil
+	}
+	var cmds []tea.Cmd
+	for _, t := range m.triggers {
+		for range t.feed(data) {
+			cmds = append(cmds, m.sendPayload(port, t.data, t.data))
+			m.showNote(t.firedText())
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// triggerStatus summarizes the --macros triggers for the status bar, e.g.
+// "⚡ 2/3 triggers"; empty if there are none
+func (m *connectModel) triggerStatus() string {
+	if len(m.triggers) == 0 {
+		return ""
+	}
+	if m.triggerOff {
+		return "⚡ triggers paused"
+	}
+	armed := 0
+	for _, t := range m.triggers {
+		if !t.Once || t.fired == 0 {
+			armed++
+		}
+	}
+	return fmt.Sprintf("⚡ %d/%d triggers", armed, len(m.triggers))
+}
+
+// triggerMacro sends a one-shot macro, or starts or stops a repeating one
+func (m *connectModel) triggerMacro(macro *components.Macro) tea.Cmd {
+	m.macroGen[macro.Key]++
+	if macro.Repeat > 0 && macro.IsRunning() {
+		macro.SetRunning(false)
+		return nil
+	}
+	macro.SetRunning(macro.Repeat > 0)
+	return m.runMacro(macro, m.macroGen[macro.Key])
+}
+
+// runMacro sends the macro's payload and schedules the next repeat
+func (m *connectModel) runMacro(macro *components.Macro, gen int) tea.Cmd {
+	port := m.GetPort()
+	if port == nil {
+		macro.SetRunning(false)
+		return nil
+	}
+	cmds := []tea.Cmd{m.sendPayload(port, macro.Data, macro.Data)}
+	if macro.IsRunning() {
+		cmds = append(cmds, tea.Tick(macro.Repeat, func(time.Time) tea.Msg {
+			return macroTickMsg{macro: macro, gen: gen}
+		}))
+	}
+	return tea.Batch(cmds...)
+}
+
+// scriptStepText describes a finished script step, indented by loop depth
+func scriptStepText(depth, i, total int, desc string, err error) string {
+	text := fmt.Sprintf("%s[%d/%d] %s", strings.Repeat("  ", depth), i+1, total, desc)
+	if err != nil {
+		return fmt.Sprintf("%s: %v", text, err)
+	}
+	return text
+}
+
+// runConnectScript runs script against port while the TUI shows the traffic
+// Writes appear as TX messages and step results as status lines.
+func runConnectScript(ctx context.Context, send func(tea.Msg), port serial.Port, script *sendScript, rxCh <-chan []byte) {
+	r := &scriptRun{
+		ctx:  ctx,
+		port: port,
+		rxCh: rxCh,
+		onSend: func(data []byte) {
+			now := time.Now()
+			send(components.DataReceivedMsg{
+				Timestamp:   now,
+				Data:        data,
+				IsTX:        true,
+				Status:      "WRITTEN",
+				WrittenTime: &now,
+			})
+		},
+		onStep: func(depth, i, total int, desc string, err error) {
+			mark := "✓"
+			if err != nil {
+				mark = "✗"
+			}
+			send(scriptStatusMsg{text: mark + " " + scriptStepText(depth, i, total, desc, err)})
+		},
+		onTrigger: func(t *scriptTrigger) {
+			send(scriptStatusMsg{text: t.firedText()})
+		},
+	}
+
+	if len(script.Steps) == 0 {
+		send(scriptStatusMsg{text: fmt.Sprintf("Script answering %d triggers", len(script.Triggers))})
+	}
+	err := r.execute(script)
+	switch {
+	case ctx.Err() != nil:
+		// Quitting
+	case err != nil:
+		send(scriptStatusMsg{text: fmt.Sprintf("Script failed: %v", err)})
+	default:
+		send(scriptStatusMsg{text: "Script completed"})
+	}
+
+	// Keep the reader from blocking on data nobody waits for
+	for range rxCh {
+	}
+}
+
+// runConnectHeadless runs script against the port without the TUI, printing
+// the traffic and step results as log lines on stdout
+func runConnectHeadless(portPath string, script *sendScript, logPath string, opts ...serial.Option) error {
+	port, err := serial.Open(portPath, opts...)
+	if err != nil {
+		return err
+	}
+	defer port.Close()
+
+	var fileLog *terminalLog
+	if logPath != "" {
+		if fileLog, err = openTerminalLog(logPath, portPath); err != nil {
+			return err
+		}
+		defer fileLog.Close()
+	}
+	out := newStreamLog(os.Stdout)
+	defer out.Close()
+
+	// The reader and the script both record traffic
+	var mu sync.Mutex
+	record := func(msg components.DataReceivedMsg) {
+		mu.Lock()
+		defer mu.Unlock()
+		out.Write(msg)
+		fileLog.Write(msg)
+	}
+	note := func(text string) {
+		mu.Lock()
+		defer mu.Unlock()
+		out.Note(text)
+		fileLog.Note(text)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	rxCh := make(chan []byte, 64)
+	go func() {
+		defer close(rxCh)
+		slab := serial.NewReadSlab(readSlabSize)
+		for {
+			n, at, err := serial.ReadTimestamped(ctx, port, slab.Next(4096))
+			if n > 0 {
+				data := slab.Take(n)
+				record(components.DataReceivedMsg{Timestamp: at, Data: data})
 				select {
-				case <-m.GetContext().Done():
-					// Context was cancelled, exit cleanly
+				case rxCh <- data:
+				case <-ctx.Done():
 					return
-				default:
-					// Try to read data from the serial port
-					n, err := port.ReadContext(m.GetContext(), buffer)
-					if err != nil {
-						// Check if it's a context cancellation
-						if m.GetContext().Err() != nil {
-							return // Context cancelled, exit cleanly
-						}
-						// For other errors, continue trying to read
-						continue
-					}
-					if n > 0 {
-						// Send raw data with timestamp - formatting will happen in Update method
-						data := make([]byte, n)
-						copy(data, buffer[:n])
-						p.Send(components.DataReceivedMsg{
-							Timestamp: time.Now(),
-							Data:      data,
-						})
-					}
 				}
 			}
-		}()
+			if err != nil {
+				if ctx.Err() == nil {
+					note(fmt.Sprintf("read failed: %v", err))
+				}
+				return
+			}
+		}
 	}()
 
-	_, err := p.Run()
+	r := &scriptRun{
+		ctx:  ctx,
+		port: port,
+		rxCh: rxCh,
+		onSend: func(data []byte) {
+			record(components.DataReceivedMsg{Timestamp: time.Now(), Data: data, IsTX: true, Status: "WRITTEN"})
+		},
+		onStep: func(depth, i, total int, desc string, err error) {
+			note(scriptStepText(depth, i, total, desc, err))
+		},
+		onTrigger: func(t *scriptTrigger) {
+			note(t.firedText())
+		},
+	}
+
+	note(fmt.Sprintf("connected to %s", portPath))
+	if err := r.execute(script); err != nil {
+		note(fmt.Sprintf("script failed: %v", err))
+		if errors.Is(err, errNoMatch) {
+			return errNoMatch
+		}
+		if errors.Is(err, context.Canceled) {
+			return nil
+		}
+		return err
+	}
+	note("script completed")
+	return nil
+}
+
+// runConnectPlain connects stdin and stdout to the port without the TUI:
+// each stdin line is sent with the line ending and traffic is printed a
+// line at a time. With raw, stdin goes out byte for byte and only the data
+// is printed. The end of stdin does not end the session, an interrupt does.
+func runConnectPlain(portPath string, settings connectSettings, noTimestamps bool, opts ...serial.Option) error {
+	port, err := serial.Open(portPath, opts...)
+	if err != nil {
+		return err
+	}
+	defer port.Close()
+
+	var fileLog *terminalLog
+	if settings.logPath != "" {
+		if fileLog, err = openTerminalLog(settings.logPath, portPath); err != nil {
+			return err
+		}
+		defer fileLog.Close()
+	}
+	out := newPlainOutput(os.Stdout, noTimestamps || settings.raw, settings.raw, settings.filter, settings.highlights)
+	defer out.Flush()
+
+	var mu sync.Mutex
+	record := func(msg components.DataReceivedMsg) {
+		out.Write(msg)
+		mu.Lock()
+		defer mu.Unlock()
+		fileLog.Write(msg)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// send writes data, echoing it unless --echo=false; a failed write is
+	// shown either way
+	send := func(data []byte) {
+		enqueued := time.Now()
+		wctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		_, err := port.WriteContext(wctx, data)
+		if ctx.Err() != nil || (err == nil && settings.noEcho) {
+			return
+		}
+		written := time.Now()
+		msg := components.DataReceivedMsg{
+			Timestamp:    written,
+			Data:         data,
+			IsTX:         true,
+			Status:       "WRITTEN",
+			EnqueuedTime: &enqueued,
+			WrittenTime:  &written,
+		}
+		if err != nil {
+			msg.Status = "ERROR"
+			fmt.Fprintf(os.Stderr, "Error: write failed: %v\n", err)
+		}
+		record(msg)
+	}
+
+	go func() {
+		in := bufio.NewReader(os.Stdin)
+		if settings.raw {
+			buffer := make([]byte, 4096)
+			for {
+				n, err := in.Read(buffer)
+				if n > 0 {
+					send(bytes.Clone(buffer[:n]))
+				}
+				if err != nil {
+					return
+				}
+			}
+		}
+		for {
+			line, err := in.ReadString('\n')
+			if line = strings.TrimRight(line, "\r\n"); line != "" || err == nil {
+				send([]byte(line + lineEndings[settings.lineEnding]))
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
 
-	// Ensure cleanup
-	m.Cancel()
-	return err
+	return readPlain(ctx, port, record)
 }
 
 func (m *connectModel) Init() tea.Cmd {
@@ -262,21 +1503,7 @@ func (m *connectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Store terminal dimensions
 		m.width = msg.Width
 		m.height = msg.Height
-
-		// Calculate available height for table
-		// Total space - status bar - input area
-		totalUIOverhead := statusBarHeight + inputAreaHeight
-		tableHeight := m.height - totalUIOverhead
-
-		// Ensure minimum height
-		if tableHeight < 5 {
-			tableHeight = 5
-		}
-
-		// Set component sizes
-		m.terminal.SetSize(m.width, tableHeight)
-		m.input.SetWidth(m.width)
-		m.statusBar.SetWidth(m.width)
+		m.layout()
 
 		if !m.IsReady() {
 			m.SetReady(true)
@@ -287,36 +1514,214 @@ func (m *connectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg.Error != nil {
 			m.SetError(msg.Error)
 			m.statusBar.SetDisconnected(msg.Error)
+			m.log.Note(fmt.Sprintf("connection failed: %v", msg.Error))
 		} else {
 			m.statusBar.SetConnected()
 			m.input.Focus()
+			m.log.Note("connected")
+		}
+
+	case portLostMsg:
+		m.SetConnected(false)
+		m.statusBar.SetLost(msg.err, m.reconnect > 0)
+		note := fmt.Sprintf("Port lost: %v", msg.err)
+		if m.reconnect > 0 {
+			note += fmt.Sprintf("; reconnecting every %s", m.reconnect)
+		}
+		m.showNote(note)
+
+	case portReopenedMsg:
+		m.statusBar.SetPortPath(msg.path)
+		if msg.path != m.GetPortPath() {
+			m.showNote(fmt.Sprintf("Port reopened as %s", msg.path))
+		} else {
+			m.showNote("Port reopened")
 		}
 
 	case components.DataReceivedMsg:
-		// Safely handle the data message
-		defer func() {
-			if r := recover(); r != nil {
-				// If there's a panic in data handling, don't crash the whole UI
-				// Just continue running
-			}
-		}()
-
-		// Only process data if we're ready (WindowSizeMsg has been received)
-		if m.IsReady() {
-			// If this is a TX completion status (WRITTEN or ERROR), update existing message
-			if msg.IsTX && (msg.Status == "WRITTEN" || msg.Status == "ERROR") && msg.Sequence > 0 {
-				if m.UpdateMessage(msg) {
-					// Message was updated, refresh terminal display
-					m.terminal.UpdateMessage(m.GetRawData())
-				}
-			} else {
-				// New message (including PENDING TX), add normally
-				m.AddRawData(msg)
-				m.terminal.AddMessage(msg)
+		cmds = append(cmds, m.receive(msg))
+		m.terminal.Flush()
+
+	case components.DataBatchMsg:
+		// Data arriving faster than RenderInterval is rendered once per batch
+		for _, msg := range msg {
+			cmds = append(cmds, m.receive(msg))
+		}
+		m.terminal.Flush()
+
+	case scriptStatusMsg:
+		m.showNote(msg.text)
+		return m, nil
+
+	case openTabMsg, closeTabMsg:
+		// Only reaches a terminal that is not in tabs
+		m.showNote("Tabs are not available in the split view")
+		return m, nil
+
+	case keystrokeErrMsg:
+		m.showNote(fmt.Sprintf("Keystroke not sent: %v", msg.err))
+		return m, nil
+
+	case signalsMsg:
+		if msg.err != nil {
+			if !m.noSignals {
+				m.noSignals = true
+				m.showNote(fmt.Sprintf("Modem signals unavailable: %v", msg.err))
 			}
+			return m, nil
+		}
+		m.statusBar.SetSignals(msg.signals)
+		m.timeline.SetCTS(msg.at, msg.signals.CTS)
+		return m, nil
+
+	case timelineTickMsg:
+		if msg.gen == m.timelineGen && m.timeline.IsVisible() {
+			return m, m.timelineTick()
 		}
+		return m, nil
+
+	case pasteChunkMsg:
+		// The write status updates the TX row like that of any other send
+		status := func() tea.Msg { return msg.status }
+		if m.paste == nil || msg.gen != m.pasteGen {
+			return m, status
+		}
+		if msg.status.Status != "WRITTEN" {
+			m.showNote(fmt.Sprintf("Paste stopped at chunk %d of %d: %s", m.paste.next, len(m.paste.chunks), strings.ToLower(msg.status.Status)))
+			m.paste = nil
+			return m, status
+		}
+		if m.paste.next == len(m.paste.chunks) {
+			m.showNote(fmt.Sprintf("Pasted %d bytes in %d chunks", m.paste.size, len(m.paste.chunks)))
+			m.paste = nil
+			return m, status
+		}
+		gen := m.pasteGen
+		return m, tea.Batch(status, tea.Tick(m.pasteDelay, func(time.Time) tea.Msg {
+			return pasteTickMsg{gen: gen}
+		}))
+
+	case pasteTickMsg:
+		if m.paste != nil && msg.gen == m.pasteGen {
+			return m, m.sendPasteChunk()
+		}
+		return m, nil
+
+	case signalToggledMsg:
+		if msg.err != nil {
+			m.showNote(fmt.Sprintf("Failed to set %s: %v", msg.name, msg.err))
+			return m, nil
+		}
+		state := "low"
+		if msg.state {
+			state = "high"
+		}
+		m.showNote(fmt.Sprintf("%s %s", msg.name, state))
+		if port := m.GetPort(); port != nil && !m.noSignals {
+			return m, readSignals(port)
+		}
+		return m, nil
+
+	case breakDoneMsg:
+		m.breaking = false
+		m.statusBar.SetBreaking(false)
+		if msg.err != nil {
+			m.showNote(fmt.Sprintf("Break failed: %v", msg.err))
+		} else {
+			m.showNote(fmt.Sprintf("Break sent (%v)", m.breakLen))
+		}
+		return m, nil
+
+	case tea.MouseMsg:
+		m.handleMouse(msg)
+		m.syncInspector()
+		return m, nil
+
+	case macroTickMsg:
+		if msg.macro.IsRunning() && msg.gen == m.macroGen[msg.macro.Key] {
+			return m, m.runMacro(msg.macro, msg.gen)
+		}
+		return m, nil
 
 	case tea.KeyMsg:
+		// Raw mode sends every key but the one that leaves it
+		if m.IsInRawMode() {
+			if key.Matches(msg, m.keys.RawMode) {
+				m.setRawMode(false)
+			} else {
+				m.sendKeystroke(msg)
+			}
+			return m, nil
+		}
+
+		// While a search query is typed, all keys go to it
+		if m.search.IsTyping() {
+			done, cmd := m.search.Update(msg)
+			if done {
+				m.terminal.SetSearch(m.search)
+			}
+			return m, cmd
+		}
+		if m.filter.IsTyping() {
+			done, cmd := m.filter.Update(msg)
+			if done {
+				m.terminal.SetFilter(m.filter)
+			}
+			return m, cmd
+		}
+
+		if m.export.IsTyping() {
+			path, done, ok, cmd := m.export.Update(msg)
+			if done && ok {
+				m.exportScrollback(path)
+			}
+			return m, cmd
+		}
+		if m.command.IsTyping() {
+			line, done, ok, cmd := m.command.Update(msg)
+			if done && ok {
+				return m, m.runCommand(line)
+			}
+			return m, cmd
+		}
+		if m.IsInInsertMode() && m.input.IsSearchingHistory() {
+			m.input.UpdateHistorySearch(msg)
+			return m, nil
+		}
+
+		// A paste waiting to be confirmed takes y or enter to send it and n
+		// or esc to drop it; esc stops one being sent
+		if m.paste != nil && !m.paste.sending {
+			switch msg.String() {
+			case "y", "Y", "enter":
+				m.paste.sending = true
+				m.pasteGen++
+				m.showNote(fmt.Sprintf("Pasting %d bytes in %d chunks, esc stops", m.paste.size, len(m.paste.chunks)))
+				return m, m.sendPasteChunk()
+			case "n", "N", "esc":
+				m.paste = nil
+				m.showNote("Paste dropped")
+			}
+			return m, nil
+		}
+		if m.paste != nil && key.Matches(msg, m.keys.Escape) {
+			m.showNote(fmt.Sprintf("Paste stopped after %d of %d bytes", m.paste.sent(), m.paste.size))
+			m.paste = nil
+			return m, nil
+		}
+		if m.IsInInsertMode() && msg.Paste && needsPasteConfirm(string(msg.Runes), m.pasteChunk) {
+			m.startPaste(string(msg.Runes))
+			return m, nil
+		}
+
+		// Break and macro keys work in both modes
+		if key.Matches(msg, m.keys.SendBreak) {
+			return m, m.sendBreak()
+		}
+		if macro := m.macroBar.Lookup(msg.String()); macro != nil {
+			return m, m.triggerMacro(macro)
+		}
+
 		// Handle mode-specific keys
 		if m.IsInInsertMode() {
 			// Insert mode - handle input and escape
@@ -336,7 +1741,7 @@ func (m *connectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 					switch m.input.GetSendingMode() {
 					case components.SendingModeASCII:
-						dataToSend = []byte(inputStr + "\n")
+						dataToSend = []byte(inputStr + lineEndings[m.lineEnd])
 						displayData = []byte(inputStr)
 					case components.SendingModeHex:
 						dataToSend, err = parseHexInput(inputStr)
@@ -355,77 +1760,20 @@ func (m *connectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						displayData = dataToSend
 					}
 
-					// Send the data with proper timeout handling and status updates
-					writeStatusCh := make(chan error, 1)
-
-					go func(port serial.Port, dataToSend []byte) {
-						ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-						defer cancel()
-
-						_, err := port.WriteContext(ctx, dataToSend)
-						writeStatusCh <- err
-						close(writeStatusCh)
-					}(port, dataToSend)
-
-					// Get sequence number for this TX message
-					sequence := m.GetNextSequence()
+					cmds = append(cmds, m.sendPayload(port, dataToSend, displayData))
 
-					// Capture enqueue time before creating goroutine
-					enqueuedTime := time.Now()
-
-					// Return single command for final status update
-					cmds = append(cmds, func() tea.Msg {
-						err := <-writeStatusCh
-						writtenTime := time.Now() // Capture when write completed
-
-						// Debug: Log any error
-						if err != nil {
-							fmt.Fprintf(os.Stderr, "[DEBUG] Write error: %v (type: %T)\n", err, err)
-						} else {
-							fmt.Fprintf(os.Stderr, "[DEBUG] Write succeeded\n")
-						}
-
-						// Send completion status with same sequence number
-						finalStatus := components.DataReceivedMsg{
-							Timestamp:    writtenTime,
-							Data:         displayData,
-							IsTX:         true,
-							Sequence:     sequence,
-							EnqueuedTime: &enqueuedTime,
-							WrittenTime:  &writtenTime,
-						}
-						if err != nil {
-							// Check if it's a timeout error
-							if err == serial.ErrCTSTimeout || err == context.DeadlineExceeded {
-								finalStatus.Status = "TIMEOUT"
-							} else {
-								finalStatus.Status = "ERROR"
-							}
-						} else {
-							finalStatus.Status = "WRITTEN"
+					// Add to history before clearing
+					if m.input.AddToHistory(inputStr) && !m.noHistory {
+						if err := appendInputHistory(m.input.GetSendingMode(), strings.TrimSpace(inputStr)); err != nil {
+							logger.Warn("failed to save input history", "err", err)
 						}
-						return finalStatus
-					})
-
-					// Add to display with TX prefix (initially as PENDING)
-					timestamp := enqueuedTime
-					txData := components.DataReceivedMsg{
-						Timestamp:    timestamp,
-						Data:         displayData,
-						IsTX:         true,
-						Status:       "PENDING",
-						Sequence:     sequence,
-						EnqueuedTime: &enqueuedTime,
 					}
-					// Add to both raw data store and terminal display
-					m.AddRawData(txData)
-					m.terminal.AddMessage(txData)
-
-					// Add to history before clearing
-					m.input.AddToHistory(inputStr)
 					m.input.SetValue("")
 				}
 				return m, tea.Batch(cmds...)
+			case key.Matches(msg, m.keys.HistorySearch):
+				m.input.StartHistorySearch()
+				return m, nil
 			case key.Matches(msg, m.keys.Up):
 				m.input.NavigateHistoryUp()
 				return m, tea.Batch(cmds...)
@@ -444,19 +1792,54 @@ func (m *connectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, tea.Quit
 
 			case key.Matches(msg, m.keys.Escape):
-				// In normal mode, escape returns to follow mode if in visual mode
+				// In normal mode, escape clears the search and returns to follow mode if in visual mode
+				if m.search.IsActive() {
+					m.search.Clear()
+					m.terminal.SetSearch(m.search)
+				}
 				if m.terminal.GetViewMode() == components.ViewModeVisual {
 					m.terminal.SetViewMode(components.ViewModeFollow)
 				}
 
+			case key.Matches(msg, m.keys.Search):
+				m.search.Start()
+				return m, nil
+
+			case key.Matches(msg, m.keys.Filter):
+				m.filter.Start()
+				return m, nil
+
+			case key.Matches(msg, m.keys.Export):
+				m.export.Start(logFileName(m.GetPortPath()))
+				return m, nil
+
+			case key.Matches(msg, m.keys.Command):
+				m.command.Start("")
+				return m, nil
+
+			case key.Matches(msg, m.keys.NextMatch):
+				m.terminal.NextMatch(true)
+
+			case key.Matches(msg, m.keys.PrevMatch):
+				m.terminal.NextMatch(false)
+
 			case key.Matches(msg, m.keys.InsertMode):
 				m.SetInputMode(models.InputModeInsert)
 				m.input.Focus()
 				return m, tea.Batch(cmds...)
 
+			case key.Matches(msg, m.keys.RawMode):
+				m.setRawMode(true)
+				return m, nil
+
 			case key.Matches(msg, m.keys.Clear):
 				m.ClearData()
 				m.terminal.Clear()
+				m.statusBar.SetDropped(0)
+				if m.plot != nil {
+					m.plot.Clear()
+				}
+				m.timeline.Clear()
 
 			case key.Matches(msg, m.keys.Help):
 				m.help.ShowAll = !m.help.ShowAll
@@ -472,9 +1855,88 @@ func (m *connectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case key.Matches(msg, m.keys.ToggleSendMode):
 				m.input.ToggleSendingMode()
 
+			case key.Matches(msg, m.keys.TimeMode):
+				m.terminal.NextTimeMode()
+
+			case key.Matches(msg, m.keys.SetMark):
+				// Mark the row under the cursor in visual mode, else now
+				at := time.Now()
+				if row, ok := m.terminal.Cursor(); ok {
+					at = row.Timestamp
+				}
+				m.terminal.SetMark(at)
+
+			case key.Matches(msg, m.keys.ToggleLog):
+				m.toggleLog()
+
+			case key.Matches(msg, m.keys.ToggleRTS):
+				return m, m.toggleSignal("RTS")
+
+			case key.Matches(msg, m.keys.ToggleDTR):
+				return m, m.toggleSignal("DTR")
+
 			case key.Matches(msg, m.keys.VisualMode):
 				m.terminal.SetViewMode(components.ViewModeVisual)
 
+			case key.Matches(msg, m.keys.ToggleEcho):
+				m.noEcho = !m.noEcho
+				if m.noEcho {
+					m.showNote("Local echo off: sent data is logged but not shown")
+				} else {
+					m.showNote("Local echo on")
+				}
+
+			case key.Matches(msg, m.keys.LineEnding):
+				m.lineEnd = nextLineEnding(m.lineEnd)
+				m.showNote(fmt.Sprintf("ASCII input ends with %s", strings.ToUpper(m.lineEnd)))
+
+			case key.Matches(msg, m.keys.ToggleTimeline):
+				m.timeline.SetVisible(!m.timeline.IsVisible())
+				m.layout()
+				if m.timeline.IsVisible() {
+					m.timelineGen++
+					return m, m.timelineTick()
+				}
+
+			case key.Matches(msg, m.keys.TogglePlot):
+				if m.plot == nil {
+					m.showNote("No plot rule given (use --plot)")
+					break
+				}
+				m.plot.SetVisible(!m.plot.IsVisible())
+				m.layout()
+
+			case key.Matches(msg, m.keys.ToggleTriggers):
+				if len(m.triggers) == 0 {
+					m.showNote("No triggers loaded (add triggers to the --macros file)")
+					break
+				}
+				m.triggerOff = !m.triggerOff
+				if m.triggerOff {
+					m.showNote("Triggers paused")
+				} else {
+					m.showNote("Triggers resumed")
+				}
+
+			case key.Matches(msg, m.keys.Inspect):
+				if m.terminal.GetViewMode() != components.ViewModeVisual {
+					m.showNote("Select a row in visual mode (v) to inspect it")
+					break
+				}
+				m.inspecting = !m.inspecting
+
+			case key.Matches(msg, m.keys.InspectLeft):
+				m.inspector.Move(-1)
+
+			case key.Matches(msg, m.keys.InspectRight):
+				m.inspector.Move(1)
+
+			case key.Matches(msg, m.keys.Yank):
+				m.yank(false)
+
+			case key.Matches(msg, m.keys.YankHex):
+				m.yank(true)
+
 			case key.Matches(msg, m.keys.GotoTop):
 				if m.terminal.GetViewMode() == components.ViewModeVisual {
 					// In visual mode, navigate to top
@@ -511,10 +1973,93 @@ func (m *connectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		_, cmd = m.terminal.Update(msg)
 		cmds = append(cmds, cmd)
 	}
+	m.syncInspector()
 
 	return m, tea.Batch(cmds...)
 }
 
+// handleMouse scrolls the table with the wheel and selects rows by dragging
+// over them, copying them as text when the button is released; both leave
+// follow mode until G
+func (m *connectModel) handleMouse(msg tea.MouseMsg) {
+	switch {
+	case msg.Button == tea.MouseButtonWheelUp:
+		m.terminal.Scroll(-3)
+	case msg.Button == tea.MouseButtonWheelDown:
+		m.terminal.Scroll(3)
+	case msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft:
+		if row := m.terminal.RowAt(msg.Y); row >= 0 {
+			m.dragFrom, m.dragged = row, false
+			m.terminal.Select(row, row)
+		}
+	case msg.Action == tea.MouseActionMotion && m.dragFrom >= 0:
+		if row := m.terminal.RowAt(msg.Y); row >= 0 {
+			m.dragged = true
+			m.terminal.Select(m.dragFrom, row)
+		}
+	case msg.Action == tea.MouseActionRelease:
+		if m.dragFrom >= 0 && m.dragged {
+			m.yank(false)
+		}
+		m.dragFrom = -1
+	}
+}
+
+// startPaste asks whether to send text pasted into the input
+func (m *connectModel) startPaste(text string) {
+	if m.paste != nil {
+		m.showNote("A paste is still being sent; esc stops it")
+		return
+	}
+	job, err := newPasteJob(text, m.input.GetSendingMode(), m.lineEnd, m.pasteChunk)
+	if err != nil {
+		m.showNote(fmt.Sprintf("Invalid paste: %v", err))
+		return
+	}
+	m.paste = job
+}
+
+// sendPasteChunk writes the next chunk of the paste; its pasteChunkMsg
+// schedules the one after
+func (m *connectModel) sendPasteChunk() tea.Cmd {
+	port := m.GetPort()
+	if port == nil {
+		m.showNote(fmt.Sprintf("Paste stopped after %d of %d bytes: port not connected", m.paste.sent(), m.paste.size))
+		m.paste = nil
+		return nil
+	}
+	chunk := m.paste.chunks[m.paste.next]
+	m.paste.next++
+	written := m.sendPayload(port, chunk, chunk)
+	gen := m.pasteGen
+	return func() tea.Msg {
+		status, _ := written().(components.DataReceivedMsg)
+		return pasteChunkMsg{gen: gen, status: status}
+	}
+}
+
+// timelineTick schedules the next redraw of the CTS timeline
+func (m *connectModel) timelineTick() tea.Cmd {
+	gen := m.timelineGen
+	return tea.Tick(100*time.Millisecond, func(time.Time) tea.Msg {
+		return timelineTickMsg{gen: gen}
+	})
+}
+
+// syncInspector points the inspector at the row under the cursor while it
+// is open in visual mode, resizing the table when it appears or goes
+func (m *connectModel) syncInspector() {
+	row, ok := m.terminal.Cursor()
+	visible := m.inspecting && ok
+	if visible {
+		m.inspector.SetMessage(row)
+	}
+	if visible != m.inspector.IsVisible() {
+		m.inspector.SetVisible(visible)
+		m.layout()
+	}
+}
+
 func (m *connectModel) View() string {
 	// Always show the UI, even if not fully ready
 	// If not ready, we'll show what we can with defaults
@@ -532,9 +2077,58 @@ func (m *connectModel) View() string {
 	inputMode := m.GetInputMode().String()
 	isInsertMode := m.IsInInsertMode()
 	input := m.input.ViewWithMode(inputMode, isInsertMode)
+	if m.search.IsTyping() {
+		input = styles.InputStyle.Copy().
+			Width(max(m.width-4, 10)).
+			BorderForeground(colors.Yellow).
+			Render(m.search.View())
+	}
+	if m.filter.IsTyping() {
+		input = styles.InputStyle.Copy().
+			Width(max(m.width-4, 10)).
+			BorderForeground(colors.Teal).
+			Render(m.filter.View())
+	}
+	if m.export.IsTyping() {
+		input = styles.InputStyle.Copy().
+			Width(max(m.width-4, 10)).
+			BorderForeground(colors.Lavender).
+			Render(m.export.View())
+	}
+	if m.command.IsTyping() {
+		input = styles.InputStyle.Copy().
+			Width(max(m.width-4, 10)).
+			BorderForeground(colors.Lavender).
+			Render(m.command.View())
+	}
+	if m.IsInRawMode() {
+		hint := fmt.Sprintf("Raw mode: keys go straight to %s; ctrl+] leaves", m.GetPortPath())
+		input = styles.InputStyle.Copy().
+			Width(max(m.width-4, 10)).
+			BorderForeground(colors.Red).
+			Render(lipgloss.NewStyle().Foreground(colors.Red).Render(hint))
+	}
+	if m.paste != nil && !m.paste.sending {
+		question := fmt.Sprintf("Paste %d bytes as %s in %d chunks of up to %d, %v apart? (y/n)",
+			m.paste.size, m.input.GetSendingMode(), len(m.paste.chunks), m.pasteChunk, m.pasteDelay)
+		input = styles.InputStyle.Copy().
+			Width(max(m.width-4, 10)).
+			BorderForeground(colors.Peach).
+			Render(lipgloss.NewStyle().Foreground(colors.Peach).Render(question))
+	}
+	m.statusBar.SetSearch(m.search.Status())
+	m.statusBar.SetFilter(m.filter.Status(m.terminal.Hidden()))
+	m.statusBar.SetTriggers(m.triggerStatus())
+	m.statusBar.SetTimeMode(m.terminal.TimeStatus())
 
 	// Comprehensive status bar with all info
 	sendingMode := m.input.GetSendingMode().String()
+	if m.input.GetSendingMode() == components.SendingModeASCII {
+		sendingMode += "+" + strings.ToUpper(m.lineEnd)
+	}
+	if m.noEcho {
+		sendingMode += " no echo"
+	}
 	timestamp := time.Now().Format("15:04:05")
 
 	// Use stored terminal width (set by WindowSizeMsg)
@@ -547,10 +2141,20 @@ func (m *connectModel) View() string {
 	statusBar := m.statusBar.ComprehensiveStatusBar(inputMode, sendingMode, viewMode, m.IsConnected(), timestamp)
 
 	// Layout without header, with comprehensive status bar at bottom
-	return lipgloss.JoinVertical(
-		lipgloss.Left,
-		content,
-		input,
-		statusBar,
-	)
+	sections := []string{content}
+	if m.plot != nil && m.plot.IsVisible() {
+		sections = append(sections, m.plot.View())
+	}
+	if m.timeline.IsVisible() {
+		sections = append(sections, m.timeline.View(time.Now()))
+	}
+	if m.inspector.IsVisible() {
+		sections = append(sections, m.inspector.View())
+	}
+	sections = append(sections, input)
+	if m.macroBar.Height() > 0 {
+		sections = append(sections, m.macroBar.View())
+	}
+	sections = append(sections, statusBar)
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
 }
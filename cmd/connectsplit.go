@@ -0,0 +1,170 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"strings"
+
+	"github.com/allbin/go-serial"
+	"github.com/allbin/go-serial/internal/tui/colors"
+	"github.com/allbin/go-serial/internal/tui/keys"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// splitModel shows two connect terminals side by side
+// Keys go to the focused pane; ctrl+o moves the focus. Both panes stamp
+// data with the same clock, so their timestamps can be compared directly.
+type splitModel struct {
+	panes  [2]*connectModel
+	focus  int // Index of the pane receiving keys
+	keys   keys.SplitKeys
+	width  int
+	height int
+}
+
+// paneMsg delivers a message to one pane of the split view
+type paneMsg struct {
+	pane int
+	msg  tea.Msg
+}
+
+func runConnectSplit(portPaths [2]string, settings connectSettings, opts ...serial.Option) error {
+	logger.Debug("starting connect split view", "ports", portPaths)
+
+	m := &splitModel{keys: keys.NewSplitKeys()}
+	for i, portPath := range portPaths {
+		pane, err := newConnectModel(portPath, settings, opts...)
+		if err != nil {
+			return err
+		}
+		m.panes[i] = pane
+	}
+
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	for i, pane := range m.panes {
+		pane.start(func(msg tea.Msg) {
+			p.Send(paneMsg{pane: i, msg: msg})
+		}, nil, opts...)
+	}
+
+	_, err := p.Run()
+
+	// Ensure cleanup
+	for _, pane := range m.panes {
+		pane.Cleanup()
+		if cerr := pane.log.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+func (m *splitModel) Init() tea.Cmd {
+	return nil
+}
+
+// update passes msg to a pane and routes the messages its command
+// produces back to the same pane
+func (m *splitModel) update(pane int, msg tea.Msg) tea.Cmd {
+	_, cmd := m.panes[pane].Update(msg)
+	return routeCmd(cmd, func(msg tea.Msg) tea.Msg {
+		return paneMsg{pane: pane, msg: msg}
+	})
+}
+
+// routeCmd wraps the messages cmd produces for one terminal, leaving
+// batches split and quitting unchanged
+func routeCmd(cmd tea.Cmd, wrap func(tea.Msg) tea.Msg) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		switch msg := cmd().(type) {
+		case nil:
+			return nil
+		case tea.QuitMsg:
+			return msg
+		case tea.BatchMsg:
+			cmds := make([]tea.Cmd, len(msg))
+			for i, c := range msg {
+				cmds[i] = routeCmd(c, wrap)
+			}
+			return tea.BatchMsg(cmds)
+		default:
+			return wrap(msg)
+		}
+	}
+}
+
+func (m *splitModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+		// One column divides the panes and one row holds each pane's title
+		left := (msg.Width - 1) / 2
+		widths := [2]int{left, msg.Width - 1 - left}
+		var cmds []tea.Cmd
+		for i := range m.panes {
+			cmds = append(cmds, m.update(i, tea.WindowSizeMsg{Width: widths[i], Height: msg.Height - 1}))
+		}
+		return m, tea.Batch(cmds...)
+
+	case paneMsg:
+		return m, m.update(msg.pane, msg.msg)
+
+	case tea.MouseMsg:
+		// The pane under the mouse gets it, relative to its table, and
+		// clicking a pane focuses it
+		pane := 0
+		if msg.X > m.panes[0].width {
+			pane = 1
+			msg.X -= m.panes[0].width + 1
+		}
+		msg.Y-- // Pane title
+		if msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft {
+			m.focus = pane
+		}
+		return m, m.update(pane, msg)
+
+	case tea.KeyMsg:
+		if key.Matches(msg, m.keys.SwitchPane) {
+			m.focus = 1 - m.focus
+			return m, nil
+		}
+	}
+	return m, m.update(m.focus, msg)
+}
+
+func (m *splitModel) View() string {
+	titleStyle := lipgloss.NewStyle().
+		Foreground(colors.Subtext0).
+		Background(colors.Surface0).
+		Padding(0, 1)
+	focusedTitleStyle := titleStyle.
+		Foreground(colors.Base).
+		Background(colors.Mauve).
+		Bold(true)
+
+	views := make([]string, 0, 3)
+	for i, pane := range m.panes {
+		style := titleStyle
+		title := pane.GetPortPath()
+		if i == m.focus {
+			style = focusedTitleStyle
+			title += "  (ctrl+o: other pane)"
+		}
+		title = style.Width(max(pane.width, 1)).MaxWidth(max(pane.width, 1)).Render(title)
+		views = append(views, lipgloss.JoinVertical(lipgloss.Left, title, pane.View()))
+
+		if i == 0 {
+			divider := strings.TrimSuffix(strings.Repeat("│\n", max(m.height, 1)), "\n")
+			views = append(views, lipgloss.NewStyle().Foreground(colors.Surface2).Render(divider))
+		}
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, views...)
+}
@@ -0,0 +1,199 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/allbin/go-serial"
+	"github.com/allbin/go-serial/internal/tui/keys"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// tabModel holds the connect terminals of one TUI, one per tab; only the
+// current tab is shown and gets keys, while all of them keep reading. The
+// first tab is the port connect was started with; ":open" adds more and
+// ":close" closes the current one.
+type tabModel struct {
+	tabs     []*connectModel
+	current  int
+	settings connectSettings // For tabs opened later: without --log and --script
+	opts     []serial.Option
+	send     func(tea.Msg)
+	size     tea.WindowSizeMsg
+	keys     keys.TabKeys
+}
+
+// tabMsg delivers a message to one tab
+type tabMsg struct {
+	tab *connectModel
+	msg tea.Msg
+}
+
+// openTabMsg asks for a tab connected to path, at baud unless that is zero
+type openTabMsg struct {
+	path string
+	baud int
+}
+
+// closeTabMsg asks to close the current tab
+type closeTabMsg struct{}
+
+func runConnectTUI(portPath string, settings connectSettings, opts ...serial.Option) error {
+	logger.Debug("starting connect TUI", "port", portPath)
+
+	first, err := newConnectModel(portPath, settings, opts...)
+	if err != nil {
+		return err
+	}
+	later := settings
+	later.logPath, later.script = "", nil
+	m := &tabModel{
+		tabs:     []*connectModel{first},
+		settings: later,
+		opts:     opts,
+		keys:     keys.NewTabKeys(),
+	}
+
+	// Start the TUI with alt screen and input handling
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	m.send = p.Send
+	first.start(m.sender(first), settings.script, opts...)
+
+	_, err = p.Run()
+
+	// Ensure cleanup
+	for _, tab := range m.tabs {
+		tab.Cleanup()
+		if cerr := tab.log.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// sender returns the function a tab's background work sends messages with
+func (m *tabModel) sender(tab *connectModel) func(tea.Msg) {
+	return func(msg tea.Msg) {
+		m.send(tabMsg{tab: tab, msg: msg})
+	}
+}
+
+func (m *tabModel) Init() tea.Cmd {
+	return nil
+}
+
+// update passes msg to a tab and routes the messages its command produces
+// back to the same tab
+func (m *tabModel) update(tab *connectModel, msg tea.Msg) tea.Cmd {
+	_, cmd := tab.Update(msg)
+	return routeCmd(cmd, func(msg tea.Msg) tea.Msg {
+		return tabMsg{tab: tab, msg: msg}
+	})
+}
+
+func (m *tabModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.size = msg
+		var cmds []tea.Cmd
+		for _, tab := range m.tabs {
+			cmds = append(cmds, m.update(tab, msg))
+		}
+		return m, tea.Batch(cmds...)
+
+	case tabMsg:
+		switch tabMsg := msg.msg.(type) {
+		case openTabMsg:
+			return m, m.open(msg.tab, tabMsg)
+		case closeTabMsg:
+			return m, m.close()
+		}
+		if m.index(msg.tab) < 0 {
+			return m, nil // Closed meanwhile
+		}
+		return m, m.update(msg.tab, msg.msg)
+
+	case tea.KeyMsg:
+		// Raw mode sends these keys to the port too
+		if len(m.tabs) > 1 && !m.tabs[m.current].IsInRawMode() {
+			switch {
+			case key.Matches(msg, m.keys.NextTab):
+				m.current = (m.current + 1) % len(m.tabs)
+				return m, nil
+			case key.Matches(msg, m.keys.PrevTab):
+				m.current = (m.current + len(m.tabs) - 1) % len(m.tabs)
+				return m, nil
+			case key.Matches(msg, m.keys.GotoTab):
+				if i := int(msg.Runes[0] - '1'); i < len(m.tabs) {
+					m.current = i
+				}
+				return m, nil
+			}
+		}
+	}
+	return m, m.update(m.tabs[m.current], msg)
+}
+
+// open adds a tab connected to msg.path and makes it current; from reports
+// a failure
+func (m *tabModel) open(from *connectModel, msg openTabMsg) tea.Cmd {
+	opts := m.opts
+	if msg.baud > 0 {
+		opts = append(opts[:len(opts):len(opts)], serial.WithBaudRate(msg.baud))
+	}
+	tab, err := newConnectModel(msg.path, m.settings, opts...)
+	if err != nil {
+		from.showNote(fmt.Sprintf("Cannot open %s: %v", msg.path, err))
+		return nil
+	}
+	m.tabs = append(m.tabs, tab)
+	m.current = len(m.tabs) - 1
+	tab.start(m.sender(tab), nil, opts...)
+	if m.size.Width > 0 {
+		return m.update(tab, m.size)
+	}
+	return nil
+}
+
+// close closes the current tab, quitting with the last one
+func (m *tabModel) close() tea.Cmd {
+	tab := m.tabs[m.current]
+	if len(m.tabs) == 1 {
+		return tea.Quit
+	}
+	tab.Cleanup()
+	if err := tab.log.Close(); err != nil {
+		logger.Warn("failed to close log", "err", err)
+	}
+	m.tabs = append(m.tabs[:m.current], m.tabs[m.current+1:]...)
+	m.current = min(m.current, len(m.tabs)-1)
+	return nil
+}
+
+// index returns the position of tab, or -1 once it is closed
+func (m *tabModel) index(tab *connectModel) int {
+	for i, t := range m.tabs {
+		if t == tab {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *tabModel) View() string {
+	current := m.tabs[m.current]
+	if len(m.tabs) == 1 {
+		current.statusBar.SetTabs(nil, 0)
+	} else {
+		names := make([]string, len(m.tabs))
+		for i, tab := range m.tabs {
+			names[i] = filepath.Base(tab.GetPortPath())
+		}
+		current.statusBar.SetTabs(names, m.current)
+	}
+	return current.View()
+}
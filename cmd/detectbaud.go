@@ -0,0 +1,271 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/allbin/go-serial"
+	"github.com/spf13/cobra"
+)
+
+// detectBaudCmd represents the detect-baud command
+var detectBaudCmd = &cobra.Command{
+	Use:   "detect-baud <port>",
+	Short: "Find the baud rate of a device by listening",
+	Long: `Listen to a device at each candidate baud rate and character format in
+turn, and report the configuration whose data looks most plausible.
+
+Each candidate is opened for --dwell and scored on what arrives: the share
+of bytes that are printable ASCII, tabs or line breaks, reduced by the
+framing and parity errors the driver counted in the meantime. A wrong rate
+shows up as non-ASCII noise and, on UARTs that keep counters, as framing
+errors. Devices that only talk when spoken to can be prodded with --probe,
+which is sent after opening each candidate.
+
+The score suits devices that send text. For binary protocols, look at the
+error columns instead: the right rate is the one without framing errors.
+
+Example usage:
+  serial detect-baud /dev/ttyUSB0
+  serial detect-baud /dev/ttyUSB0 --formats 8N1,7E1 --dwell 5s
+  serial detect-baud /dev/ttyUSB0 --rates 1200,2400,4800,9600 --probe '\r\n'`,
+	Args: portArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		args = withProfilePort(args, 1)
+		portPath := args[0]
+
+		rates, _ := cmd.Flags().GetIntSlice("rates")
+		formatNames, _ := cmd.Flags().GetStringSlice("formats")
+		dwell, _ := cmd.Flags().GetDuration("dwell")
+		probeText, _ := cmd.Flags().GetString("probe")
+
+		var formats []lineFormat
+		for _, name := range formatNames {
+			f, err := parseLineFormat(name)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: invalid --formats: %v\n", err)
+				os.Exit(1)
+			}
+			formats = append(formats, f)
+		}
+		var probe []byte
+		if probeText != "" {
+			var err error
+			if probe, err = parseDelimiter(probeText); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: invalid --probe %q: %v\n", probeText, err)
+				os.Exit(1)
+			}
+		}
+		if len(rates) == 0 || len(formats) == 0 {
+			fmt.Fprintf(os.Stderr, "Error: --rates and --formats must not be empty\n")
+			os.Exit(1)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		fmt.Printf("Listening on %s for %s at each of %d configurations\n\n", portPath, dwell, len(rates)*len(formats))
+		fmt.Printf("  %-14s %10s %7s %7s %7s %6s\n", "CONFIG", "BYTES", "ASCII", "FRAME", "PARITY", "SCORE")
+
+		var results []baudCandidate
+		for _, f := range formats {
+			for _, rate := range rates {
+				if ctx.Err() != nil {
+					break
+				}
+				c, err := probeBaud(ctx, portPath, rate, f, dwell, probe)
+				if err != nil {
+					fmt.Printf("  %-14s %v\n", fmt.Sprintf("%d %s", rate, f), err)
+					continue
+				}
+				c.print()
+				results = append(results, c)
+			}
+		}
+
+		rankCandidates(results)
+		if len(results) == 0 || results[0].received == 0 {
+			fmt.Fprintf(os.Stderr, "\nError: no data received at any rate\n")
+			os.Exit(1)
+		}
+		best := results[0]
+		fmt.Printf("\nMost likely: %s (score %.2f)\n", best.name(), best.score())
+		if best.score() < 0.5 {
+			fmt.Println("Low confidence: the data may be binary, or the rate not among the candidates")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(detectBaudCmd)
+
+	detectBaudCmd.Flags().IntSlice("rates", []int{1200, 2400, 4800, 9600, 19200, 38400, 57600, 115200, 230400, 460800, 921600}, "Candidate baud rates")
+	detectBaudCmd.Flags().StringSlice("formats", []string{"8N1"}, "Candidate character formats, such as 8N1,7E1")
+	detectBaudCmd.Flags().Duration("dwell", 2*time.Second, "How long to listen at each candidate")
+	detectBaudCmd.Flags().String("probe", "", "Send this after opening each candidate: hex such as 0x0d, or text such as \\r\\n")
+}
+
+// lineFormat is a character format such as 8N1
+type lineFormat struct {
+	dataBits int
+	parity   serial.Parity
+	stopBits int
+}
+
+// parseLineFormat reads a format of data bits, parity letter and stop bits,
+// such as 8N1 or 7E1
+func parseLineFormat(s string) (lineFormat, error) {
+	s = strings.TrimSpace(s)
+	if len(s) != 3 || s[0] < '5' || s[0] > '8' || (s[2] != '1' && s[2] != '2') {
+		return lineFormat{}, fmt.Errorf("bad format %q (want data bits, parity and stop bits, such as 8N1)", s)
+	}
+	parity, err := serial.ParseParity(s[1:2])
+	if err != nil {
+		return lineFormat{}, err
+	}
+	return lineFormat{dataBits: int(s[0] - '0'), parity: parity, stopBits: int(s[2] - '0')}, nil
+}
+
+func (f lineFormat) String() string {
+	return fmt.Sprintf("%d%s%d", f.dataBits, strings.ToUpper(f.parity.String()[:1]), f.stopBits)
+}
+
+// baudCandidate is what was received at one rate and format
+type baudCandidate struct {
+	config   serial.Config
+	received int64
+	text     int64 // Printable ASCII, tabs and line breaks
+	frame    int   // Framing errors, or -1 without driver counters
+	parity   int   // Parity errors, or -1 without driver counters
+}
+
+// probeBaud opens portPath at rate and format f, optionally sends probe,
+// and listens for dwell
+func probeBaud(ctx context.Context, portPath string, rate int, f lineFormat, dwell time.Duration, probe []byte) (baudCandidate, error) {
+	opts := []serial.Option{
+		serial.WithBaudRate(rate),
+		serial.WithDataBits(f.dataBits),
+		serial.WithParity(f.parity),
+		serial.WithStopBits(f.stopBits),
+		serial.WithReadTimeout(100 * time.Millisecond),
+		withDiagnostics(),
+	}
+	c := baudCandidate{config: serial.DefaultConfig(), frame: -1, parity: -1}
+	for _, opt := range opts {
+		if err := opt(&c.config); err != nil {
+			return c, err
+		}
+	}
+
+	port, err := serial.Open(portPath, opts...)
+	if err != nil {
+		return c, err
+	}
+	defer port.Close()
+	// Anything buffered was received at the previous candidate's settings
+	if err := port.FlushInput(); err != nil {
+		return c, fmt.Errorf("flush failed: %w", err)
+	}
+
+	before, countErr := serial.GetLineCounts(port)
+	if countErr != nil && !errors.Is(countErr, serial.ErrNotSupported) {
+		return c, countErr
+	}
+	if len(probe) > 0 {
+		if _, err := port.Write(probe); err != nil {
+			return c, fmt.Errorf("write failed: %w", err)
+		}
+	}
+
+	buf := make([]byte, 4096)
+	start := time.Now()
+	for time.Since(start) < dwell && ctx.Err() == nil {
+		n, err := port.Read(buf)
+		if n > 0 {
+			c.add(buf[:n])
+		}
+		if err != nil {
+			return c, fmt.Errorf("read failed: %w", err)
+		}
+	}
+
+	if countErr == nil {
+		after, err := serial.GetLineCounts(port)
+		if err != nil {
+			return c, err
+		}
+		c.frame = after.Frame - before.Frame
+		c.parity = after.Parity - before.Parity
+	}
+	return c, nil
+}
+
+// add counts received data
+func (c *baudCandidate) add(data []byte) {
+	for _, b := range data {
+		if (b >= 0x20 && b < 0x7f) || b == '\t' || b == '\r' || b == '\n' {
+			c.text++
+		}
+	}
+	c.received += int64(len(data))
+}
+
+// name renders the candidate's configuration, such as "9600 8N1"
+func (c baudCandidate) name() string {
+	return formatLineSettings(c.config)
+}
+
+// score rates how plausible the data is, from 0 for none or noise to 1
+// for clean text without receive errors
+func (c baudCandidate) score() float64 {
+	if c.received == 0 {
+		return 0
+	}
+	score := float64(c.text) / float64(c.received)
+	if c.frame > 0 || c.parity > 0 {
+		errs := float64(max(c.frame, 0) + max(c.parity, 0))
+		score *= max(0, 1-errs/float64(c.received))
+	}
+	return score
+}
+
+// rankCandidates sorts candidates by descending score, and those scoring
+// the same by the amount of data received
+func rankCandidates(results []baudCandidate) {
+	slices.SortStableFunc(results, func(a, b baudCandidate) int {
+		if a.score() != b.score() {
+			if a.score() > b.score() {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(b.received, a.received)
+	})
+}
+
+func (c baudCandidate) print() {
+	ascii := "-"
+	if c.received > 0 {
+		ascii = fmt.Sprintf("%.0f%%", 100*float64(c.text)/float64(c.received))
+	}
+	fmt.Printf("  %-14s %10d %7s %7s %7s %6.2f\n", c.name(), c.received, ascii, formatErrorCount(c.frame), formatErrorCount(c.parity), c.score())
+}
+
+// formatErrorCount renders an error count, or - when the driver keeps none
+func formatErrorCount(n int) string {
+	if n < 0 {
+		return "-"
+	}
+	return fmt.Sprint(n)
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"math"
+	"testing"
+
+	"github.com/allbin/go-serial"
+)
+
+func TestBaudCandidateScore(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          string
+		frame, parity int
+		want          float64
+	}{
+		{"nothing received", "", -1, -1, 0},
+		{"clean text", "Hello\r\n\tworld", -1, -1, 1},
+		{"clean text with counters", "Hello\r\n", 0, 0, 1},
+		{"half noise", "ab\x80\xff", -1, -1, 0.5},
+		{"text with framing errors", "abcdefghij", 2, 0, 0.8},
+		{"errors on both counters", "abcdefghij", 1, 4, 0.5},
+		{"more errors than bytes", "ab", 3, 0, 0},
+		{"noise with errors", "\x00\x80\xfe\xff", 2, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := baudCandidate{frame: tt.frame, parity: tt.parity}
+			c.add([]byte(tt.data))
+			if c.received != int64(len(tt.data)) {
+				t.Errorf("received = %d, want %d", c.received, len(tt.data))
+			}
+			if got := c.score(); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("score = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRankCandidates(t *testing.T) {
+	candidate := func(rate int, data string, frame int) baudCandidate {
+		c := baudCandidate{config: serial.DefaultConfig(), frame: frame, parity: -1}
+		c.config.BaudRate = rate
+		c.add([]byte(data))
+		return c
+	}
+	results := []baudCandidate{
+		candidate(4800, "\x80\xff\x80", 3),
+		candidate(9600, "OK\r\n", 0),
+		candidate(19200, "", -1),
+		candidate(115200, "OK\r\nOK\r\n", 0), // Same score, more data
+	}
+	rankCandidates(results)
+
+	var got []int
+	for _, c := range results {
+		got = append(got, c.config.BaudRate)
+	}
+	want := []int{115200, 9600, 4800, 19200}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("ranked %v, want %v", got, want)
+		}
+	}
+}
+
+func TestParseLineFormat(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"8N1", "8N1", false},
+		{" 7e1 ", "7E1", false},
+		{"5O2", "5O2", false},
+		{"8M1", "8M1", false},
+		{"9N1", "", true},
+		{"8N3", "", true},
+		{"8X1", "", true},
+		{"8N", "", true},
+	}
+	for _, tt := range tests {
+		f, err := parseLineFormat(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseLineFormat(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if err == nil && f.String() != tt.want {
+			t.Errorf("parseLineFormat(%q) = %s, want %s", tt.in, f, tt.want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/allbin/go-serial/internal/tui/components"
+)
+
+// The w key of connect and listen exports the scrollback, filtered-out
+// messages included, in a format chosen by the file extension:
+//
+//	.bin, .raw  Received bytes only, as they arrived
+//	.hex        One line of hex per message, RX and TX
+//	other       The --log format: timestamp, direction, hex and ASCII, with
+//	            notes such as "Port lost" as comments
+
+// exportFormat returns the format exportScrollback writes to path
+func exportFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".bin", ".raw":
+		return "raw"
+	case ".hex":
+		return "hex"
+	}
+	return "text"
+}
+
+// exportScrollback writes msgs to path, replacing the file, and returns the
+// format written
+func exportScrollback(path, portPath string, msgs []components.DataReceivedMsg) (string, error) {
+	format := exportFormat(path)
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+
+	w := bufio.NewWriter(f)
+	switch format {
+	case "raw":
+		for _, msg := range msgs {
+			if !msg.IsTX && !msg.IsNote {
+				w.Write(msg.Data)
+			}
+		}
+	case "hex":
+		for _, msg := range msgs {
+			if msg.Status != "PENDING" && !msg.IsNote {
+				fmt.Fprintf(w, "% X\n", msg.Data)
+			}
+		}
+	default:
+		log := newStreamLog(w)
+		log.Note(fmt.Sprintf("%s scrollback exported, %d messages", portPath, len(msgs)))
+		for _, msg := range msgs {
+			log.Write(msg)
+		}
+	}
+
+	err = w.Flush()
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return format, err
+}
@@ -0,0 +1,114 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// lineGrep passes on only the received lines that match one of the include
+// patterns, if any, and none of the exclude patterns. Data is held back
+// until its line is complete; lines are matched without their line ending.
+type lineGrep struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+	pending []byte    // Start of the line not yet complete
+	at      time.Time // When the pending line started arriving
+}
+
+// addGrepFlags adds the flags read by grepOptions
+func addGrepFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArray("grep", nil, "Keep only lines matching this regex (repeat to keep lines matching any)")
+	cmd.Flags().StringArray("grep-v", nil, "Drop lines matching this regex (repeatable)")
+}
+
+// grepOptions returns the filter of the flags from addGrepFlags, or nil if
+// neither is given
+func grepOptions(cmd *cobra.Command) (*lineGrep, error) {
+	include, _ := cmd.Flags().GetStringArray("grep")
+	exclude, _ := cmd.Flags().GetStringArray("grep-v")
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil, nil
+	}
+	g := &lineGrep{}
+	for _, expr := range include {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --grep %q: %w", expr, err)
+		}
+		g.include = append(g.include, re)
+	}
+	for _, expr := range exclude {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --grep-v %q: %w", expr, err)
+		}
+		g.exclude = append(g.exclude, re)
+	}
+	return g, nil
+}
+
+// filter takes data that arrived at at and returns the matching complete
+// lines in it, with their line endings, and when the first of them started
+// arriving. A nil filter passes data on as is.
+func (g *lineGrep) filter(at time.Time, data []byte) (time.Time, []byte) {
+	if g == nil {
+		return at, data
+	}
+	var kept []byte
+	var keptAt time.Time
+	for len(data) > 0 {
+		if len(g.pending) == 0 {
+			g.at = at
+		}
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			g.pending = append(g.pending, data...)
+			break
+		}
+		line := append(g.pending, data[:i+1]...)
+		data = data[i+1:]
+		if g.matches(line) {
+			if kept == nil {
+				keptAt = g.at
+			}
+			kept = append(kept, line...)
+		}
+		g.pending = g.pending[:0]
+	}
+	return keptAt, kept
+}
+
+// flush returns the pending line if it matches, such as when the port closes
+func (g *lineGrep) flush() (time.Time, []byte) {
+	if g == nil || len(g.pending) == 0 || !g.matches(g.pending) {
+		return time.Time{}, nil
+	}
+	line := bytes.Clone(g.pending)
+	g.pending = g.pending[:0]
+	return g.at, line
+}
+
+func (g *lineGrep) matches(line []byte) bool {
+	line = bytes.TrimRight(line, "\r\n")
+	for _, re := range g.exclude {
+		if re.Match(line) {
+			return false
+		}
+	}
+	if len(g.include) == 0 {
+		return true
+	}
+	for _, re := range g.include {
+		if re.Match(line) {
+			return true
+		}
+	}
+	return false
+}
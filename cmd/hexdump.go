@@ -0,0 +1,236 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/allbin/go-serial"
+	"github.com/spf13/cobra"
+)
+
+// hexdumpCmd represents the hexdump command
+var hexdumpCmd = &cobra.Command{
+	Use:   "hexdump <port>",
+	Short: "Stream received data as a canonical hexdump",
+	Long: `Print the bytes a port receives in the layout of hexdump -C: the offset,
+16 bytes in hex and their ASCII, one row at a time as rows fill up, so
+traffic can be piped, grepped and diffed instead of compared on screen.
+
+Like hexdump, identical consecutive rows are squeezed into a single *
+unless --no-squeeze is given, and the last partial row and the final
+offset are printed when the dump ends: on Ctrl+C, after --max-bytes or
+after --duration. The output then matches hexdump -C of the same bytes.
+
+--timestamps starts a new block of rows for every chunk the driver
+returns, headed by a # line with its arrival time, the time since the
+previous chunk and its length, to see how the data was timed.
+
+Examples:
+  serial hexdump /dev/ttyUSB0
+  serial hexdump /dev/ttyUSB0 --baud 9600 --max-bytes 256 > reply.hex
+  serial hexdump /dev/ttyUSB0 --timestamps | tee trace.txt
+  diff <(serial hexdump /dev/ttyUSB0 --duration 5s) expected.hex`,
+	Args: portArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		args = withProfilePort(args, 1)
+		timestamps, _ := cmd.Flags().GetBool("timestamps")
+		noSqueeze, _ := cmd.Flags().GetBool("no-squeeze")
+		maxBytes, _ := cmd.Flags().GetInt64("max-bytes")
+		duration, _ := cmd.Flags().GetDuration("duration")
+
+		if maxBytes < 0 || duration < 0 {
+			fmt.Fprintf(os.Stderr, "Error: --max-bytes and --duration must not be negative\n")
+			os.Exit(1)
+		}
+
+		opts, _, err := portOptions(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		port, err := serial.Open(args[0], opts...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening port: %v\n", err)
+			os.Exit(1)
+		}
+		defer port.Close()
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if duration > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, duration)
+			defer cancel()
+		}
+
+		// Unbuffered, so a pipe sees each row as soon as it is complete
+		dump := &hexdumpWriter{w: os.Stdout, squeeze: !noSqueeze && !timestamps}
+		err = runHexdump(ctx, port, dump, timestamps, maxBytes)
+		if cerr := dump.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(hexdumpCmd)
+
+	addPortFlags(hexdumpCmd, 100*time.Millisecond)
+	hexdumpCmd.Flags().BoolP("timestamps", "t", false, "Start a block with a timestamp line for every received chunk")
+	hexdumpCmd.Flags().BoolP("no-squeeze", "v", false, "Print identical consecutive rows instead of *")
+	hexdumpCmd.Flags().Int64("max-bytes", 0, "Stop after this many bytes (0 = no limit)")
+	hexdumpCmd.Flags().Duration("duration", 0, "Stop after this long (0 = no limit)")
+}
+
+// runHexdump dumps what port receives until ctx ends or maxBytes were dumped
+func runHexdump(ctx context.Context, port serial.Port, dump *hexdumpWriter, timestamps bool, maxBytes int64) error {
+	buf := make([]byte, 4096)
+	var last time.Time
+	for maxBytes == 0 || dump.offset < maxBytes {
+		n, at, err := serial.ReadTimestamped(ctx, port, buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("read error: %w", err)
+		}
+		if n == 0 {
+			continue
+		}
+
+		data := buf[:n]
+		if maxBytes > 0 && dump.offset+int64(n) > maxBytes {
+			data = data[:maxBytes-dump.offset]
+		}
+		if timestamps {
+			if err := dump.chunk(at, last, len(data)); err != nil {
+				return err
+			}
+			last = at
+		}
+		if _, err := dump.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hexdumpWriter formats a byte stream like hexdump -C, writing each row as
+// soon as it is complete; capture --format hexdump writes through it too
+type hexdumpWriter struct {
+	w       io.Writer
+	squeeze bool // Replace repeated rows with a single *
+
+	offset   int64  // Bytes written so far
+	row      []byte // Bytes of the current, incomplete row
+	rowStart int64  // Offset of the current row
+	prev     []byte // Last complete row, for squeezing
+	squeezed bool   // A * has been printed for the current repeat
+}
+
+func (d *hexdumpWriter) Write(data []byte) (int, error) {
+	for _, b := range data {
+		if len(d.row) == 0 {
+			d.rowStart = d.offset
+		}
+		d.row = append(d.row, b)
+		d.offset++
+		if len(d.row) == 16 {
+			if err := d.flushRow(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return len(data), nil
+}
+
+// chunk ends the current row and writes the header of a chunk of n bytes
+// received at at; prev is the previous chunk's arrival, zero for the first
+func (d *hexdumpWriter) chunk(at, prev time.Time, n int) error {
+	if err := d.flushRow(); err != nil {
+		return err
+	}
+	delta := ""
+	if !prev.IsZero() {
+		delta = fmt.Sprintf("  +%v", at.Sub(prev).Round(time.Microsecond))
+	}
+	_, err := fmt.Fprintf(d.w, "# %s%s  %d bytes\n", at.Format("2006-01-02T15:04:05.000000Z07:00"), delta, n)
+	return err
+}
+
+// flushRow writes the current row, which may be partial, or a * if it
+// repeats the previous complete row
+func (d *hexdumpWriter) flushRow() error {
+	if len(d.row) == 0 {
+		return nil
+	}
+	row := d.row
+	d.row = d.row[:0]
+
+	if d.squeeze && len(row) == 16 && bytes.Equal(row, d.prev) {
+		if d.squeezed {
+			return nil
+		}
+		d.squeezed = true
+		_, err := io.WriteString(d.w, "*\n")
+		return err
+	}
+	d.squeezed = false
+	if len(row) == 16 {
+		d.prev = append(d.prev[:0], row...)
+	}
+	_, err := io.WriteString(d.w, formatHexdumpRow(d.rowStart, row))
+	return err
+}
+
+// Close writes the final partial row and the end offset, as hexdump does
+// when its input ends
+func (d *hexdumpWriter) Close() error {
+	if err := d.flushRow(); err != nil {
+		return err
+	}
+	if d.offset == 0 {
+		return nil
+	}
+	_, err := fmt.Fprintf(d.w, "%08x\n", d.offset)
+	return err
+}
+
+// formatHexdumpRow renders up to 16 bytes at offset in the hexdump -C layout
+func formatHexdumpRow(offset int64, row []byte) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%08x  ", offset)
+	for i := range 16 {
+		if i < len(row) {
+			fmt.Fprintf(&b, "%02x ", row[i])
+		} else {
+			b.WriteString("   ")
+		}
+		if i == 7 {
+			b.WriteByte(' ')
+		}
+	}
+	b.WriteString(" |")
+	for _, c := range row {
+		if c < 0x20 || c > 0x7E {
+			c = '.'
+		}
+		b.WriteByte(c)
+	}
+	b.WriteString("|\n")
+	return b.String()
+}
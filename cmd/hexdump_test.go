@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHexdumpWriter(t *testing.T) {
+	data := append(bytes.Repeat([]byte("A"), 48), "hello\x00\x01"...)
+	row := "41 41 41 41 41 41 41 41  41 41 41 41 41 41 41 41  |AAAAAAAAAAAAAAAA|\n"
+	tail := "00000030  68 65 6c 6c 6f 00 01" + strings.Repeat(" ", 30) + "|hello..|\n00000037\n"
+
+	tests := []struct {
+		name    string
+		squeeze bool
+		want    string
+	}{
+		{"squeeze", true, "00000000  " + row + "*\n" + tail},
+		{"no squeeze", false, "00000000  " + row + "00000010  " + row + "00000020  " + row + tail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			d := &hexdumpWriter{w: &out, squeeze: tt.squeeze}
+			// Rows fill across writes
+			for _, chunk := range [][]byte{data[:5], data[5:40], data[40:]} {
+				if _, err := d.Write(chunk); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := d.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("dump =\n%s\nwant\n%s", out.String(), tt.want)
+			}
+		})
+	}
+
+	var out strings.Builder
+	if err := (&hexdumpWriter{w: &out}).Close(); err != nil || out.Len() != 0 {
+		t.Errorf("empty dump = %q, %v, want nothing", out.String(), err)
+	}
+}
+
+func TestHexdumpWriterChunks(t *testing.T) {
+	var out strings.Builder
+	d := &hexdumpWriter{w: &out}
+	at := time.Date(2025, 1, 2, 3, 4, 5, 6000, time.UTC)
+
+	d.chunk(at, time.Time{}, 3)
+	d.Write([]byte("abc"))
+	d.chunk(at.Add(1500*time.Microsecond), at, 1)
+	d.Write([]byte("d"))
+	d.Close()
+
+	want := "# 2025-01-02T03:04:05.000006Z  3 bytes\n" +
+		"00000000  61 62 63" + strings.Repeat(" ", 42) + "|abc|\n" +
+		"# 2025-01-02T03:04:05.001506Z  +1.5ms  1 bytes\n" +
+		"00000003  64" + strings.Repeat(" ", 48) + "|d|\n" +
+		"00000004\n"
+	if out.String() != want {
+		t.Errorf("dump =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestCaptureHexdump(t *testing.T) {
+	data := append(bytes.Repeat([]byte{0}, 40), "end"...)
+
+	var want strings.Builder
+	d := &hexdumpWriter{w: &want, squeeze: true}
+	d.Write(data)
+	d.Close()
+
+	var got strings.Builder
+	f, err := newCaptureFormatter(&got, "hexdump")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, chunk := range [][]byte{data[:20], data[20:]} {
+		if err := f.write(time.Now(), chunk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.close(); err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != want.String() {
+		t.Errorf("capture --format hexdump =\n%s\nwant the hexdump output\n%s", got.String(), want.String())
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/allbin/go-serial/internal/tui/colors"
+	"github.com/allbin/go-serial/internal/tui/components"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Highlight rules in the config file color matching data in connect and
+// listen. The first matching rule wins; configured rules are tried before
+// the built-in ones, and a rule named after a built-in rule (error, nak,
+// nak-byte, modbus-exception) replaces it.
+//
+//	highlights:
+//	  - name: heartbeat
+//	    pattern: 'ID=0x1A3'
+//	    color: teal            # Palette name, "#rrggbb" or ANSI number
+//	  - name: node-7
+//	    pattern: '^07 '        # Matched against hex such as "07 03 00 10"
+//	    hex: true
+//	    color: "#f9e2af"
+
+// highlightConfig is one highlights entry of the config file
+type highlightConfig struct {
+	Name    string `mapstructure:"name"`
+	Pattern string `mapstructure:"pattern"`
+	Hex     bool   `mapstructure:"hex"`
+	Color   string `mapstructure:"color"`
+}
+
+// highlightRules holds the configured rules followed by the built-in ones
+var highlightRules = components.BuiltinHighlights()
+
+// loadHighlights reads the highlight rules from the config file viper has
+// read
+func loadHighlights() error {
+	var entries []highlightConfig
+	if err := viper.UnmarshalKey("highlights", &entries); err != nil {
+		return fmt.Errorf("highlights: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	rules := make([]components.HighlightRule, 0, len(entries))
+	names := make(map[string]bool, len(entries))
+	for i, e := range entries {
+		name := e.Name
+		if name == "" {
+			name = fmt.Sprintf("%d", i+1)
+		}
+		if e.Pattern == "" {
+			return fmt.Errorf("highlight %s: missing pattern", name)
+		}
+		pattern, err := regexp.Compile(e.Pattern)
+		if err != nil {
+			return fmt.Errorf("highlight %s: %w", name, err)
+		}
+		color, err := parseColor(e.Color)
+		if err != nil {
+			return fmt.Errorf("highlight %s: %w", name, err)
+		}
+		rules = append(rules, components.HighlightRule{Name: name, Pattern: pattern, Hex: e.Hex, Color: color})
+		names[name] = true
+	}
+	for _, r := range components.BuiltinHighlights() {
+		if !names[r.Name] {
+			rules = append(rules, r)
+		}
+	}
+	highlightRules = rules
+	return nil
+}
+
+var colorPattern = regexp.MustCompile(`^(#[0-9a-fA-F]{6}|#[0-9a-fA-F]{3}|[0-9]{1,3})$`)
+
+// parseColor accepts a palette name, "#rrggbb" or an ANSI color number
+func parseColor(s string) (lipgloss.Color, error) {
+	if c, ok := colors.ByName(s); ok {
+		return c, nil
+	}
+	if s == "" {
+		return "", fmt.Errorf("missing color")
+	}
+	if !colorPattern.MatchString(s) {
+		return "", fmt.Errorf("unknown color %q (want a name such as red or teal, #rrggbb or an ANSI number)", s)
+	}
+	return lipgloss.Color(s), nil
+}
+
+// addHighlightFlag registers the flag that turns highlighting off
+func addHighlightFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("no-highlights", false, "Do not color data matching the built-in and configured highlight rules")
+}
+
+// highlightOptions returns the highlight rules to use given the flag from
+// addHighlightFlag
+func highlightOptions(cmd *cobra.Command) []components.HighlightRule {
+	if off, _ := cmd.Flags().GetBool("no-highlights"); off {
+		return nil
+	}
+	return highlightRules
+}
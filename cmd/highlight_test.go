@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/allbin/go-serial/internal/tui/components"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/viper"
+)
+
+func TestLoadHighlights(t *testing.T) {
+	t.Cleanup(func() {
+		viper.Set("highlights", nil)
+		highlightRules = components.BuiltinHighlights()
+	})
+
+	viper.Set("highlights", []map[string]any{
+		{"name": "heartbeat", "pattern": "ID=0x1A3", "color": "teal"},
+		{"pattern": "^07 ", "hex": true, "color": "#f9e2af"},
+		{"name": "error", "pattern": "E[0-9]+", "color": "196"},
+	})
+	if err := loadHighlights(); err != nil {
+		t.Fatalf("loadHighlights failed: %v", err)
+	}
+
+	var names []string
+	for _, r := range highlightRules {
+		names = append(names, r.Name)
+	}
+	// Configured rules come first and replace the built-in rule they name
+	if got, want := strings.Join(names, ","), "heartbeat,2,error,nak,nak-byte,modbus-exception"; got != want {
+		t.Errorf("rules = %s, want %s", got, want)
+	}
+	if !highlightRules[1].Hex || highlightRules[1].Color != lipgloss.Color("#f9e2af") {
+		t.Errorf("rule 2 = %+v, want a hex rule colored #f9e2af", highlightRules[1])
+	}
+	if !highlightRules[2].Matches([]byte("E42")) {
+		t.Error("configured error rule does not match E42")
+	}
+}
+
+func TestLoadHighlightsErrors(t *testing.T) {
+	t.Cleanup(func() {
+		viper.Set("highlights", nil)
+		highlightRules = components.BuiltinHighlights()
+	})
+
+	tests := []struct {
+		name    string
+		entry   map[string]any
+		wantErr string
+	}{
+		{"missing pattern", map[string]any{"name": "x", "color": "red"}, "highlight x: missing pattern"},
+		{"bad pattern", map[string]any{"name": "x", "pattern": "(", "color": "red"}, "highlight x: error parsing regexp"},
+		{"missing color", map[string]any{"pattern": "a"}, "highlight 1: missing color"},
+		{"unknown color", map[string]any{"pattern": "a", "color": "mauve-ish"}, `unknown color "mauve-ish"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Set("highlights", []map[string]any{tt.entry})
+			err := loadHighlights()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("loadHighlights error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
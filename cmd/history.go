@@ -0,0 +1,130 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/allbin/go-serial/internal/tui/components"
+)
+
+// The connect input history is kept in $XDG_DATA_HOME/serial/history, or
+// ~/.local/share/serial/history, one sent line per line prefixed by its
+// sending mode:
+//
+//	hex	0206000300000099
+//	ascii	AT+STATUS
+
+// historyModes maps the sending modes to their names in the history file
+var historyModes = map[string]components.SendingMode{
+	"ascii": components.SendingModeASCII,
+	"hex":   components.SendingModeHex,
+}
+
+// historyPath returns the history file, or "" if there is no home directory
+func historyPath() string {
+	dir := os.Getenv("XDG_DATA_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dir, "serial", "history")
+}
+
+// loadInputHistory fills input with the history of earlier sessions. A file
+// that has grown past twice the kept entries is rewritten with those only.
+func loadInputHistory(input *components.Input) error {
+	path := historyPath()
+	if path == "" {
+		return nil
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	entries := make(map[components.SendingMode][]string)
+	lines := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		name, entry, ok := strings.Cut(scanner.Text(), "\t")
+		mode, known := historyModes[name]
+		if !ok || !known || entry == "" {
+			continue
+		}
+		entries[mode] = append(entries[mode], entry)
+		lines++
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	for _, mode := range historyModes {
+		input.LoadHistory(mode, entries[mode])
+	}
+	if lines > 2*len(historyModes)*components.HistoryLimit {
+		return rewriteInputHistory(path, entries)
+	}
+	return nil
+}
+
+// rewriteInputHistory replaces the history file with the newest entries
+func rewriteInputHistory(path string, entries map[components.SendingMode][]string) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for name, mode := range historyModes {
+		kept := entries[mode][max(len(entries[mode])-components.HistoryLimit, 0):]
+		for _, entry := range kept {
+			fmt.Fprintf(w, "%s\t%s\n", name, entry)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// appendInputHistory adds a sent line to the history file, so sessions
+// running side by side all keep theirs
+func appendInputHistory(mode components.SendingMode, entry string) error {
+	path := historyPath()
+	if path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	name := "ascii"
+	if mode == components.SendingModeHex {
+		name = "hex"
+	}
+	_, err = fmt.Fprintf(f, "%s\t%s\n", name, entry)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/allbin/go-serial/internal/tui/components"
+)
+
+func TestInputHistory(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dir)
+	path := filepath.Join(dir, "serial", "history")
+	if historyPath() != path {
+		t.Fatalf("historyPath() = %s, want %s", historyPath(), path)
+	}
+
+	// No file yet is not an error
+	if err := loadInputHistory(components.NewInput("")); err != nil {
+		t.Fatalf("loadInputHistory without a file failed: %v", err)
+	}
+
+	for _, e := range []struct {
+		mode  components.SendingMode
+		entry string
+	}{
+		{components.SendingModeHex, "0206000300000099"},
+		{components.SendingModeASCII, "AT+STATUS"},
+		{components.SendingModeHex, "0103"},
+	} {
+		if err := appendInputHistory(e.mode, e.entry); err != nil {
+			t.Fatalf("appendInputHistory failed: %v", err)
+		}
+	}
+	// Lines in an unknown mode or without an entry are skipped
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprint(f, "binary\t00\nascii\t\nhex\n")
+	f.Close()
+
+	input := components.NewInput("")
+	if err := loadInputHistory(input); err != nil {
+		t.Fatalf("loadInputHistory failed: %v", err)
+	}
+	// The input starts in hex mode; up walks from the newest entry
+	var got []string
+	for range 3 {
+		input.NavigateHistoryUp()
+		got = append(got, input.Value())
+	}
+	if want := []string{"0103", "0206000300000099", "0206000300000099"}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("hex history = %q, want %q", got, want)
+	}
+	input.ToggleSendingMode()
+	input.NavigateHistoryUp()
+	if input.Value() != "AT+STATUS" {
+		t.Errorf("ascii history starts with %q, want AT+STATUS", input.Value())
+	}
+}
+
+func TestInputHistoryRewrite(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dir)
+	path := filepath.Join(dir, "serial", "history")
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	var b strings.Builder
+	total := 5 * components.HistoryLimit
+	for i := range total {
+		fmt.Fprintf(&b, "ascii\tline %d\n", i)
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := loadInputHistory(components.NewInput("")); err != nil {
+		t.Fatalf("loadInputHistory failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != components.HistoryLimit {
+		t.Fatalf("rewritten file has %d lines, want %d", len(lines), components.HistoryLimit)
+	}
+	if want := fmt.Sprintf("ascii\tline %d", total-1); lines[len(lines)-1] != want {
+		t.Errorf("last line = %q, want %q", lines[len(lines)-1], want)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary file left behind: %v", err)
+	}
+}
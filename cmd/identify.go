@@ -0,0 +1,398 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"regexp"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"github.com/allbin/go-serial"
+	"github.com/allbin/go-serial/modbus"
+	"github.com/allbin/go-serial/nmea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+)
+
+// identifyProbes are the probes identify knows, in the order they run:
+// the passive ones share one listening period before anything is sent
+var identifyProbes = []string{"banner", "nmea", "at", "modbus"}
+
+// identifyCmd represents the identify command
+var identifyCmd = &cobra.Command{
+	Use:   "identify <port>",
+	Short: "Probe what kind of device is attached",
+	Long: `Run a set of safe probes against a port and report what kind of device
+appears to be attached, for unlabeled equipment in the field.
+
+Probes (select with --probes, default all):
+  banner  listen for --listen without sending and show any text received
+  nmea    check the same data for NMEA 0183 sentences with valid checksums
+  at      send AT and, if answered with OK, ATI for the identification
+  modbus  send Modbus RTU Report Slave ID (function 17) to --unit
+
+Only read-only requests are sent. The probes use the port flags as given;
+if nothing answers or the data looks garbled, find the rate first with
+serial detect-baud. The exit status is 0 when a probe recognized the
+device, 2 when none did and 1 on errors.
+
+Examples:
+  serial identify /dev/ttyUSB0
+  serial identify /dev/ttyUSB0 --baud 9600 --parity even --probes modbus --unit 3
+  serial identify /dev/ttyUSB0 --baud 4800 --listen 5s --json`,
+	Args: portArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		args = withProfilePort(args, 1)
+		portPath := args[0]
+		probes, _ := cmd.Flags().GetStringSlice("probes")
+		listen, _ := cmd.Flags().GetDuration("listen")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		unit, _ := cmd.Flags().GetUint8("unit")
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+
+		for _, p := range probes {
+			if !slices.Contains(identifyProbes, p) {
+				fmt.Fprintf(os.Stderr, "Error: unknown probe %q (valid: %s)\n", p, strings.Join(identifyProbes, ", "))
+				os.Exit(1)
+			}
+		}
+		if listen <= 0 || timeout <= 0 {
+			fmt.Fprintf(os.Stderr, "Error: --listen and --timeout must be positive\n")
+			os.Exit(1)
+		}
+
+		opts, config, err := portOptions(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		port, err := serial.Open(portPath, opts...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening port: %v\n", err)
+			os.Exit(1)
+		}
+		defer port.Close()
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		id := &identifier{port: port, listen: listen, timeout: timeout, unit: unit}
+		results, err := id.run(ctx, probes)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		report := identifyReport{Port: portPath, Line: formatLineSettings(config), Probes: results}
+		// Later probes are more specific: NMEA and AT replies are text too
+		for _, r := range results {
+			if r.Found {
+				report.Device = r.Device
+			}
+		}
+		if jsonOutput {
+			if err := json.NewEncoder(os.Stdout).Encode(report); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		} else {
+			printIdentifyReport(report)
+		}
+		if report.Device == "" {
+			os.Exit(2)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(identifyCmd)
+
+	addPortFlags(identifyCmd, 100*time.Millisecond)
+	identifyCmd.Flags().StringSlice("probes", identifyProbes, "Probes to run: banner, nmea, at, modbus")
+	identifyCmd.Flags().Duration("listen", 2*time.Second, "How long the banner and nmea probes listen")
+	identifyCmd.Flags().Duration("timeout", 500*time.Millisecond, "How long the at and modbus probes wait for an answer")
+	identifyCmd.Flags().Uint8("unit", 1, "Modbus unit the modbus probe addresses")
+	identifyCmd.Flags().Bool("json", false, "Print the report as JSON")
+}
+
+// identifyResult is the outcome of one probe
+type identifyResult struct {
+	Probe  string `json:"probe"`
+	Found  bool   `json:"found"`
+	Device string `json:"device,omitempty"` // Kind of device, when found
+	Detail string `json:"detail"`
+}
+
+// identifyReport is what identify prints
+type identifyReport struct {
+	Port   string           `json:"port"`
+	Line   string           `json:"line"`
+	Device string           `json:"device,omitempty"` // From the last probe that found one
+	Probes []identifyResult `json:"probes"`
+}
+
+// identifier runs probes against one port
+type identifier struct {
+	port    serial.Port
+	listen  time.Duration
+	timeout time.Duration
+	unit    byte
+
+	heard []byte // Data received while listening, shared by banner and nmea
+}
+
+func (id *identifier) run(ctx context.Context, probes []string) ([]identifyResult, error) {
+	var results []identifyResult
+	for _, p := range identifyProbes {
+		if !slices.Contains(probes, p) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var r identifyResult
+		var err error
+		switch p {
+		case "banner":
+			if err = id.listenOnce(ctx); err == nil {
+				r = identifyBanner(id.heard)
+			}
+		case "nmea":
+			if err = id.listenOnce(ctx); err == nil {
+				r = identifyNMEA(id.heard)
+			}
+		case "at":
+			r, err = id.probeAT(ctx)
+		case "modbus":
+			r, err = id.probeModbus(ctx)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s probe: %w", p, err)
+		}
+		r.Probe = p
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// listenOnce collects what the device sends unprompted for the listen
+// period, the first time a passive probe needs it
+func (id *identifier) listenOnce(ctx context.Context) error {
+	if id.heard != nil {
+		return nil
+	}
+	id.heard = []byte{}
+	buf := make([]byte, 4096)
+	// Plain reads end within the read timeout; an abandoned ReadContext
+	// could swallow the answer to the next probe
+	deadline := time.Now().Add(id.listen)
+	for time.Now().Before(deadline) && len(id.heard) < captureWindowSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, err := id.port.Read(buf)
+		if n > 0 {
+			id.heard = append(id.heard, buf[:n]...)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// identifyBanner reports whether heard is readable text, and its first line
+func identifyBanner(heard []byte) identifyResult {
+	if len(heard) == 0 {
+		return identifyResult{Detail: "nothing received"}
+	}
+	if !isMostlyText(heard) {
+		return identifyResult{Detail: fmt.Sprintf("%d bytes of binary data (binary protocol or wrong line settings)", len(heard))}
+	}
+	for line := range strings.Lines(string(heard)) {
+		if line = strings.TrimSpace(line); line != "" {
+			return identifyResult{Found: true, Device: "text console", Detail: fmt.Sprintf("%q", truncateRunes(line, 60))}
+		}
+	}
+	return identifyResult{Detail: "only blank lines received"}
+}
+
+// isMostlyText reports whether at least 90% of data is printable ASCII or
+// common whitespace
+func isMostlyText(data []byte) bool {
+	text := 0
+	for _, b := range data {
+		if (b >= 0x20 && b < 0x7F) || b == '\r' || b == '\n' || b == '\t' {
+			text++
+		}
+	}
+	return text*10 >= len(data)*9
+}
+
+// truncateRunes shortens s to at most n runes, marking the cut
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n-1]) + "…"
+}
+
+// identifyNMEA counts the valid NMEA sentences in heard by talker and type
+func identifyNMEA(heard []byte) identifyResult {
+	valid, invalid := 0, 0
+	var kinds []string
+	for line := range strings.Lines(string(heard)) {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "$") && !strings.HasPrefix(line, "!") {
+			continue
+		}
+		s, err := nmea.ParseSentence(line)
+		if err != nil {
+			invalid++
+			continue
+		}
+		valid++
+		if kind := s.Talker + s.Type; !slices.Contains(kinds, kind) {
+			kinds = append(kinds, kind)
+		}
+	}
+	switch {
+	case valid > 0:
+		return identifyResult{Found: true, Device: "NMEA 0183 receiver (GPS/GNSS or instrument)",
+			Detail: fmt.Sprintf("%d valid sentences (%s), %d invalid", valid, strings.Join(kinds, " "), invalid)}
+	case invalid > 0:
+		return identifyResult{Detail: fmt.Sprintf("%d sentences with bad checksums (wrong line settings?)", invalid)}
+	default:
+		return identifyResult{Detail: "no sentences"}
+	}
+}
+
+// atFinalResult matches the end of an AT command response
+var atFinalResult = regexp.MustCompile(`(?m)^(OK|ERROR|\+CM[ES] ERROR:.*)\r?$`)
+
+// probeAT sends AT and, if the device answers OK, ATI
+func (id *identifier) probeAT(ctx context.Context) (identifyResult, error) {
+	resp, err := serial.Transact(ctx, id.port, []byte("AT\r"), serial.MatchRegexp(atFinalResult), 0, id.timeout)
+	if errors.Is(err, serial.ErrReadTimeout) {
+		if len(resp) > 0 {
+			return identifyResult{Detail: fmt.Sprintf("%d bytes but no OK", len(resp))}, nil
+		}
+		return identifyResult{Detail: "no response"}, nil
+	}
+	if err != nil {
+		return identifyResult{}, err
+	}
+	if !bytes.Contains(resp, []byte("OK")) {
+		return identifyResult{Found: true, Device: "AT command device", Detail: "AT answered with ERROR"}, nil
+	}
+
+	r := identifyResult{Found: true, Device: "AT command device (modem or radio module)", Detail: "AT answered OK"}
+	info, err := serial.Transact(ctx, id.port, []byte("ATI\r"), serial.MatchRegexp(atFinalResult), 0, id.timeout)
+	if err != nil && !errors.Is(err, serial.ErrReadTimeout) {
+		return identifyResult{}, err
+	}
+	var lines []string
+	for line := range strings.Lines(string(info)) {
+		line = strings.TrimSpace(line)
+		// Skip the echo and the final result
+		if line == "" || line == "ATI" || atFinalResult.MatchString(line) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) > 0 {
+		r.Detail += "; ATI: " + truncateRunes(strings.Join(lines, " / "), 60)
+	}
+	return r, nil
+}
+
+// probeModbus sends Report Slave ID to the unit
+func (id *identifier) probeModbus(ctx context.Context) (identifyResult, error) {
+	client := modbus.New(id.port, modbus.WithTimeout(id.timeout), modbus.WithRetries(0))
+	data, err := client.ReportSlaveID(ctx, id.unit)
+
+	var exc *modbus.Exception
+	switch {
+	case err == nil:
+		return identifyResult{Found: true, Device: "Modbus RTU device",
+			Detail: fmt.Sprintf("unit %d: %s", id.unit, formatSlaveID(data))}, nil
+	case errors.As(err, &exc):
+		// An exception still proves a Modbus device is listening
+		return identifyResult{Found: true, Device: "Modbus RTU device",
+			Detail: fmt.Sprintf("unit %d answered with %s", id.unit, exc.Code)}, nil
+	case errors.Is(err, serial.ErrReadTimeout):
+		return identifyResult{Detail: fmt.Sprintf("no response from unit %d", id.unit)}, nil
+	case errors.Is(err, modbus.ErrCRC), errors.Is(err, modbus.ErrInvalidResponse),
+		errors.Is(err, modbus.ErrUnexpectedUnit), errors.Is(err, modbus.ErrUnexpectedFormat):
+		return identifyResult{Detail: fmt.Sprintf("garbled response (%v)", err)}, nil
+	default:
+		return identifyResult{}, err
+	}
+}
+
+// formatSlaveID renders Report Slave ID data in its common layout: an ID
+// byte, a run indicator and text
+func formatSlaveID(data []byte) string {
+	if len(data) == 0 {
+		return "empty ID"
+	}
+	s := fmt.Sprintf("ID 0x%02X", data[0])
+	if len(data) >= 2 {
+		switch data[1] {
+		case 0x00:
+			s += ", stopped"
+		case 0xFF:
+			s += ", running"
+		default:
+			return fmt.Sprintf("ID % X", data)
+		}
+	}
+	if rest := data[min(len(data), 2):]; len(rest) > 0 {
+		if isMostlyText(rest) {
+			s += fmt.Sprintf(", %q", truncateRunes(strings.TrimSpace(string(rest)), 40))
+		} else {
+			s += fmt.Sprintf(", % X", rest)
+		}
+	}
+	return s
+}
+
+func printIdentifyReport(report identifyReport) {
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("99")).
+		Bold(true)
+
+	foundStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("40")).
+		Bold(true)
+
+	missStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("244"))
+
+	fmt.Println(titleStyle.Render(fmt.Sprintf("Identify %s (%s)", report.Port, report.Line)))
+	for _, r := range report.Probes {
+		mark := missStyle.Render("✗")
+		if r.Found {
+			mark = foundStyle.Render("✓")
+		}
+		fmt.Printf("  %s %-7s %s\n", mark, r.Probe, r.Detail)
+	}
+	fmt.Println()
+	if report.Device == "" {
+		fmt.Println("No device recognized; check the wiring and line settings (serial detect-baud)")
+		return
+	}
+	fmt.Printf("Looks like: %s\n", foundStyle.Render(report.Device))
+}
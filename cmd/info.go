@@ -23,8 +23,9 @@ Examples:
 
 For USB devices, this displays vendor/product IDs, serial numbers, interface
 numbers, and other USB-specific metadata extracted from sysfs.`,
-	Args: cobra.ExactArgs(1),
+	Args: portArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		args = withProfilePort(args, 1)
 		portPath := args[0]
 
 		info, err := serial.GetPortInfo(portPath)
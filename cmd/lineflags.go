@@ -0,0 +1,233 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/allbin/go-serial"
+	"github.com/allbin/go-serial/framing"
+	"github.com/allbin/go-serial/internal/tui/components"
+	"github.com/spf13/cobra"
+)
+
+// addLineFlags registers the data bits, parity, stop bits and read timeout
+// flags shared by the commands that exchange data over a port
+func addLineFlags(cmd *cobra.Command, readTimeout time.Duration) {
+	cmd.Flags().Int("databits", 8, "Data bits: 5, 6, 7, 8")
+	cmd.Flags().String("parity", "none", "Parity: none, odd, even, mark, space")
+	cmd.Flags().Int("stopbits", 1, "Stop bits: 1, 2")
+	cmd.Flags().Duration("read-timeout", readTimeout, "Read timeout (VTIME), in 100ms steps up to 25.5s")
+}
+
+// lineOptions returns port options for the flags registered by addLineFlags
+func lineOptions(cmd *cobra.Command) ([]serial.Option, error) {
+	dataBits, _ := cmd.Flags().GetInt("databits")
+	parityName, _ := cmd.Flags().GetString("parity")
+	stopBits, _ := cmd.Flags().GetInt("stopbits")
+	readTimeout, _ := cmd.Flags().GetDuration("read-timeout")
+
+	parity, err := serial.ParseParity(parityName)
+	if err != nil {
+		return nil, err
+	}
+	opts := []serial.Option{
+		serial.WithDataBits(dataBits),
+		serial.WithParity(parity),
+		serial.WithStopBits(stopBits),
+		serial.WithReadTimeout(readTimeout),
+	}
+
+	// Check here so a bad flag is reported by name rather than by Open
+	config := serial.DefaultConfig()
+	for i, flag := range []string{"databits", "parity", "stopbits", "read-timeout"} {
+		if err := opts[i](&config); err != nil {
+			return nil, fmt.Errorf("invalid --%s %s", flag, cmd.Flags().Lookup(flag).Value)
+		}
+	}
+	return opts, nil
+}
+
+// addPortFlags registers the baud rate and flow control flags along with
+// the line flags, for commands that need nothing else to open a port
+func addPortFlags(cmd *cobra.Command, readTimeout time.Duration) {
+	cmd.Flags().IntP("baud", "b", 115200, "Baud rate")
+	cmd.Flags().String("flow-control", "none", "Flow control: none, cts, rtscts")
+	cmd.Flags().Bool("initial-rts", false, "Assert RTS on port open (required for CTS flow control)")
+	cmd.Flags().Int("cts-priority", 0, "Run the CTS flow control monitor on its own thread at this SCHED_FIFO priority, 1-99 (0 = normal scheduling)")
+	addLineFlags(cmd, readTimeout)
+}
+
+// portOptions returns port options for the flags registered by addPortFlags,
+// with the configuration they result in
+func portOptions(cmd *cobra.Command) ([]serial.Option, serial.Config, error) {
+	baudRate, _ := cmd.Flags().GetInt("baud")
+	flowControl, _ := cmd.Flags().GetString("flow-control")
+	initialRTS, _ := cmd.Flags().GetBool("initial-rts")
+	ctsPriority, _ := cmd.Flags().GetInt("cts-priority")
+
+	opts := []serial.Option{serial.WithBaudRate(baudRate)}
+	lineOpts, err := lineOptions(cmd)
+	if err != nil {
+		return nil, serial.Config{}, err
+	}
+	opts = append(opts, lineOpts...)
+
+	switch strings.ToLower(flowControl) {
+	case "none":
+	case "cts":
+		opts = append(opts, serial.WithFlowControl(serial.FlowControlCTS))
+	case "rtscts":
+		opts = append(opts, serial.WithFlowControl(serial.FlowControlRTSCTS))
+	default:
+		return nil, serial.Config{}, fmt.Errorf("unknown flow control: %s (valid: none, cts, rtscts)", flowControl)
+	}
+	if initialRTS {
+		opts = append(opts, serial.WithInitialRTS(true))
+	}
+	if ctsPriority != 0 {
+		if ctsPriority < 1 || ctsPriority > 99 {
+			return nil, serial.Config{}, fmt.Errorf("invalid --cts-priority %d (want 1-99)", ctsPriority)
+		}
+		opts = append(opts, serial.WithCTSRealtime(ctsPriority))
+	}
+
+	config := serial.DefaultConfig()
+	for _, opt := range opts {
+		if err := opt(&config); err != nil {
+			return nil, serial.Config{}, err
+		}
+	}
+	return append(opts, withDiagnostics()), config, nil
+}
+
+// formatLineSettings renders the speed and character format of config,
+// such as "115200 8N1"
+func formatLineSettings(config serial.Config) string {
+	return fmt.Sprintf("%d %d%s%d", config.BaudRate, config.DataBits,
+		strings.ToUpper(config.Parity.String()[:1]), config.StopBits)
+}
+
+// bitsPerChar returns the bits one character of config takes on the wire:
+// start bit, data bits, parity bit and stop bits
+func bitsPerChar(config serial.Config) int {
+	bits := 1 + config.DataBits + config.StopBits
+	if config.Parity != serial.ParityNone {
+		bits++
+	}
+	return bits
+}
+
+// scrollbackLimit bounds the messages a TUI keeps in its scrollback
+type scrollbackLimit struct {
+	messages int
+	bytes    int64
+}
+
+// addScrollbackFlags registers the scrollback limit flags of the TUI commands
+func addScrollbackFlags(cmd *cobra.Command) {
+	cmd.Flags().Int("scrollback", 10000, "Messages kept in the scrollback; the oldest are dropped first (0 = no limit)")
+	cmd.Flags().Int64("scrollback-bytes", 16<<20, "Data bytes kept in the scrollback (0 = no limit)")
+}
+
+// scrollbackOptions returns the limit set by the flags from addScrollbackFlags
+func scrollbackOptions(cmd *cobra.Command) (scrollbackLimit, error) {
+	messages, _ := cmd.Flags().GetInt("scrollback")
+	bytes, _ := cmd.Flags().GetInt64("scrollback-bytes")
+	if messages < 0 {
+		return scrollbackLimit{}, fmt.Errorf("invalid --scrollback %d", messages)
+	}
+	if bytes < 0 {
+		return scrollbackLimit{}, fmt.Errorf("invalid --scrollback-bytes %d", bytes)
+	}
+	return scrollbackLimit{messages: messages, bytes: bytes}, nil
+}
+
+// addFilterFlag registers the display filter flag of the TUI commands
+func addFilterFlag(cmd *cobra.Command) {
+	cmd.Flags().String("filter", "", "Show only messages matching a regex or hex prefix; prefix with ! to hide them instead")
+}
+
+// filterOptions returns the checked expression of the flag from addFilterFlag
+func filterOptions(cmd *cobra.Command) (string, error) {
+	expr, _ := cmd.Flags().GetString("filter")
+	if err := components.NewFilter().Set(expr); err != nil {
+		return "", fmt.Errorf("invalid --filter %q: %w", expr, err)
+	}
+	return expr, nil
+}
+
+// addFrameFlags registers the flags that split received data into messages
+// other than at line breaks
+func addFrameFlags(cmd *cobra.Command) {
+	cmd.Flags().String("delimiter", "", "End messages at this delimiter instead of line breaks: hex such as 0x03, or text such as ; or \\r\\n")
+	cmd.Flags().String("frame", "line", "Message framing: line, length-prefix")
+	cmd.Flags().Int("length-bytes", 2, "Size of the length-prefix field in bytes: 1, 2, 4")
+	cmd.Flags().String("length-order", "be", "Byte order of the length-prefix field: be, le")
+}
+
+// frameOptions returns the codec of the flags from addFrameFlags, or nil to
+// split received data at line breaks
+func frameOptions(cmd *cobra.Command) (framing.Codec, error) {
+	delimiter, _ := cmd.Flags().GetString("delimiter")
+	frame, _ := cmd.Flags().GetString("frame")
+	switch strings.ToLower(frame) {
+	case "line":
+		if delimiter == "" {
+			return nil, nil
+		}
+		delim, err := parseDelimiter(delimiter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --delimiter %q: %w", delimiter, err)
+		}
+		return framing.NewDelimited(delim), nil
+	case "length-prefix":
+		if delimiter != "" {
+			return nil, fmt.Errorf("--delimiter cannot be used with --frame length-prefix")
+		}
+		size, _ := cmd.Flags().GetInt("length-bytes")
+		orderName, _ := cmd.Flags().GetString("length-order")
+		if size != 1 && size != 2 && size != 4 {
+			return nil, fmt.Errorf("invalid --length-bytes %d (want 1, 2 or 4)", size)
+		}
+		var order binary.ByteOrder
+		switch strings.ToLower(orderName) {
+		case "be":
+			order = binary.BigEndian
+		case "le":
+			order = binary.LittleEndian
+		default:
+			return nil, fmt.Errorf("invalid --length-order %q (want be or le)", orderName)
+		}
+		return framing.NewLengthPrefixed(size, order), nil
+	}
+	return nil, fmt.Errorf("unknown --frame %q (want line or length-prefix)", frame)
+}
+
+// parseDelimiter reads a delimiter given as 0x-prefixed hex, or as text with
+// Go escapes such as \r\n and \x03
+func parseDelimiter(s string) ([]byte, error) {
+	var delim []byte
+	if rest, ok := strings.CutPrefix(strings.ToLower(s), "0x"); ok {
+		var err error
+		if delim, err = hex.DecodeString(rest); err != nil {
+			return nil, err
+		}
+	} else {
+		text, err := strconv.Unquote(`"` + s + `"`)
+		if err != nil {
+			return nil, err
+		}
+		delim = []byte(text)
+	}
+	if len(delim) == 0 {
+		return nil, fmt.Errorf("empty delimiter")
+	}
+	return delim, nil
+}
@@ -4,9 +4,15 @@ Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/allbin/go-serial"
 	"github.com/charmbracelet/lipgloss"
@@ -26,25 +32,105 @@ This command scans for communication-capable serial devices including:
 - ARM/Raspberry Pi ports (ttyAMA*)
 - And other platform-specific serial devices
 
-Virtual terminals and pseudo-terminals are excluded from the listing.`,
+Virtual terminals and pseudo-terminals are excluded from the listing.
+
+--json (or -o json) prints a JSON array with the full details of each port
+for scripts: name, path, description and, for USB ports, the vendor and
+product IDs, serial number, interface, bus and device numbers, manufacturer
+and product strings. Empty USB fields are left out. No ports is an empty
+array.
+
+--filter takes comma-separated terms that a port must all match:
+  vid:pid=0403:6010  USB vendor and product ID (* for either matches any)
+  vid=0403, pid=6010 one of the IDs
+  serial=FT12        serial number containing FT12, ignoring case
+  driver=cdc_acm     kernel driver
+  type=usb           port type: usb, standard, arm or all; usb alone works too
+
+--verbose (or --usb) shows a table with the USB metadata: VID:PID, serial
+number, interface, manufacturer, product and kernel driver. --columns picks
+the table columns and their order from port, path, type, vidpid, serial, if,
+bus (bus:device number), manufacturer, product, driver and description.
+Long values are cut with an ellipsis, except in the last column.
+
+--watch keeps running after listing the ports present and prints a line as
+each port appears (+) or disappears (-), with its USB IDs and serial number,
+until Ctrl+C. Plug and unplug a dongle to see which port it maps to. With
+--json it prints one JSON object per event instead: "event" (added or
+removed), "ts" and "port" with the port's details.
+
+Example usage:
+  serial list
+  serial list --table --filter usb
+  serial list --verbose
+  serial list --filter vid:pid=0403:6010,serial=FT12
+  serial list --filter driver=cdc_acm --json
+  serial list --columns port,vidpid,serial,driver
+  serial list --watch --filter usb
+  serial list --json | jq -r '.[] | select(.serial_number == "FT12AB") | .path'`,
 	Run: func(cmd *cobra.Command, args []string) {
-		ports, err := serial.ListPorts()
+		format, err := listFormat(cmd)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error listing ports: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		columnNames := defaultListColumns
+		if verbose, _ := cmd.Flags().GetBool("verbose"); verbose {
+			columnNames = verboseListColumns
+		}
+		if usb, _ := cmd.Flags().GetBool("usb"); usb {
+			columnNames = verboseListColumns
+		}
+		if cmd.Flags().Changed("columns") {
+			columnNames, _ = cmd.Flags().GetStringSlice("columns")
+		}
+		columns, err := tableColumns(columnNames)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
 
-		if len(ports) == 0 {
-			fmt.Println("No serial ports found")
-			return
+		ports, err := serial.ListPorts()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error listing ports: %v\n", err)
+			os.Exit(1)
 		}
 
 		// Get filter flag
 		filterType, _ := cmd.Flags().GetString("filter")
-		tableFormat, _ := cmd.Flags().GetBool("table")
+		filter, err := parsePortFilter(filterType)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if watch, _ := cmd.Flags().GetBool("watch"); watch {
+			if format == "table" {
+				fmt.Fprintf(os.Stderr, "Error: --watch prints events, not a table; drop --table, --verbose and --columns\n")
+				os.Exit(1)
+			}
+			if err := watchPorts(filter, format == "json"); err != nil {
+				fmt.Fprintf(os.Stderr, "Error watching ports: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
 
 		// Filter ports if requested
-		filteredPorts := filterPorts(ports, filterType)
+		filteredPorts := filterPorts(ports, filter)
+
+		if format == "json" {
+			if err := renderJSON(filteredPorts); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		if len(ports) == 0 {
+			fmt.Println("No serial ports found")
+			return
+		}
 
 		if len(filteredPorts) == 0 {
 			if filterType != "" {
@@ -55,8 +141,8 @@ Virtual terminals and pseudo-terminals are excluded from the listing.`,
 			return
 		}
 
-		if tableFormat {
-			renderTable(filteredPorts)
+		if format == "table" {
+			renderTable(filteredPorts, columns)
 		} else {
 			renderSimple(filteredPorts)
 		}
@@ -73,13 +159,124 @@ func init() {
 	// listCmd.PersistentFlags().String("foo", "", "A help for foo")
 
 	// Add flags for filtering and table format
-	listCmd.Flags().StringP("filter", "f", "", "Filter by port type: usb, standard, arm, all")
+	listCmd.Flags().StringP("filter", "f", "", "Filter by comma-separated terms: vid:pid=0403:6010, vid=, pid=, serial=FT12, driver=cdc_acm, type=usb|standard|arm")
 	listCmd.Flags().BoolP("table", "t", false, "Display output in a styled table format")
+	listCmd.Flags().Bool("json", false, "Print the full port details as a JSON array (same as -o json)")
+	listCmd.Flags().StringP("output", "o", "text", "Output format: text, table, json")
+	listCmd.Flags().BoolP("watch", "w", false, "Keep running and print ports as they appear and disappear")
+	listCmd.Flags().BoolP("verbose", "v", false, "Table with USB metadata: VID:PID, serial, interface, manufacturer, product, driver")
+	listCmd.Flags().Bool("usb", false, "Same as --verbose")
+	listCmd.Flags().StringSlice("columns", nil, "Table columns, comma-separated: port, path, type, vidpid, serial, if, bus, manufacturer, product, driver, description")
 }
 
-// filterPorts filters the port list based on the specified filter type
-func filterPorts(ports []string, filterType string) []string {
-	if filterType == "" || filterType == "all" {
+// portEventRecord is one event of list --watch --json output
+type portEventRecord struct {
+	Event string          `json:"event"`
+	TS    time.Time       `json:"ts"`
+	Port  serial.PortInfo `json:"port"`
+}
+
+// watchPorts prints the ports passing filter as they appear and disappear
+// until interrupted, as text lines or JSON objects
+func watchPorts(filter portFilter, jsonLines bool) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	events, err := serial.WatchPorts(ctx, 0)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	for ev := range events {
+		if !filter.matches(&ev.Info) {
+			continue
+		}
+		if jsonLines {
+			if err := enc.Encode(portEventRecord{Event: ev.Kind.String(), TS: ev.Time, Port: ev.Info}); err != nil {
+				return err
+			}
+			continue
+		}
+		fmt.Println(formatPortEvent(ev))
+	}
+	return nil
+}
+
+// formatPortEvent renders a port event as a line such as
+// "[15:04:05] + /dev/ttyUSB0  USB Serial Port  0403:6001  FT12AB  FTDI FT232R"
+func formatPortEvent(ev serial.PortEvent) string {
+	mark := "+"
+	if ev.Kind == serial.PortRemoved {
+		mark = "-"
+	}
+	fields := []string{ev.Info.Path, ev.Info.Description}
+	if ev.Info.VendorID != "" {
+		fields = append(fields, ev.Info.VendorID+":"+ev.Info.ProductID)
+	}
+	if ev.Info.SerialNumber != "" {
+		fields = append(fields, ev.Info.SerialNumber)
+	}
+	if product := strings.TrimSpace(ev.Info.Manufacturer + " " + ev.Info.Product); product != "" {
+		fields = append(fields, product)
+	}
+	return fmt.Sprintf("[%s] %s %s", ev.Time.Format("15:04:05"), mark, strings.Join(fields, "  "))
+}
+
+// listFormat returns the output format chosen by --output, --table or --json
+// --verbose, --usb and --columns ask for a table unless JSON is asked for.
+func listFormat(cmd *cobra.Command) (string, error) {
+	format, _ := cmd.Flags().GetString("output")
+	switch format {
+	case "text", "table", "json":
+	default:
+		return "", fmt.Errorf("unknown output format: %s (valid: text, table, json)", format)
+	}
+
+	tableFormat, _ := cmd.Flags().GetBool("table")
+	jsonFormat, _ := cmd.Flags().GetBool("json")
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	usb, _ := cmd.Flags().GetBool("usb")
+	if (verbose || usb || cmd.Flags().Changed("columns")) && !jsonFormat && format == "text" {
+		tableFormat = true
+	}
+	explicit := cmd.Flags().Changed("output")
+	switch {
+	case tableFormat && jsonFormat:
+		return "", fmt.Errorf("--table and --json cannot be combined")
+	case tableFormat:
+		if explicit && format != "table" {
+			return "", fmt.Errorf("--table conflicts with --output %s", format)
+		}
+		return "table", nil
+	case jsonFormat:
+		if explicit && format != "json" {
+			return "", fmt.Errorf("--json conflicts with --output %s", format)
+		}
+		return "json", nil
+	}
+	return format, nil
+}
+
+// renderJSON prints the details of the ports as an indented JSON array
+// A port whose details cannot be read is listed with its name and path.
+func renderJSON(ports []string) error {
+	infos := make([]serial.PortInfo, 0, len(ports))
+	for _, port := range ports {
+		info, err := serial.GetPortInfo(port)
+		if err != nil {
+			infos = append(infos, serial.PortInfo{Name: filepath.Base(port), Path: port})
+			continue
+		}
+		infos = append(infos, *info)
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(infos)
+}
+
+// filterPorts returns the ports that pass filter
+func filterPorts(ports []string, filter portFilter) []string {
+	if len(filter) == 0 {
 		return ports
 	}
 
@@ -89,37 +286,83 @@ func filterPorts(ports []string, filterType string) []string {
 		if err != nil {
 			continue
 		}
-
-		name := strings.ToLower(info.Name)
-		switch strings.ToLower(filterType) {
-		case "usb":
-			if strings.HasPrefix(name, "ttyusb") || strings.HasPrefix(name, "ttyacm") {
-				filtered = append(filtered, port)
-			}
-		case "standard":
-			if strings.HasPrefix(name, "ttys") {
-				filtered = append(filtered, port)
-			}
-		case "arm":
-			if strings.HasPrefix(name, "ttyama") {
-				filtered = append(filtered, port)
-			}
+		if filter.matches(info) {
+			filtered = append(filtered, port)
 		}
 	}
 	return filtered
 }
 
+// listColumn is a column of the list table
+type listColumn struct {
+	title string
+	width int
+	value func(info *serial.PortInfo) string
+}
+
+// listColumns are the table columns --columns chooses from
+var listColumns = map[string]listColumn{
+	"port":         {"Port", 12, func(i *serial.PortInfo) string { return i.Name }},
+	"path":         {"Path", 16, func(i *serial.PortInfo) string { return i.Path }},
+	"type":         {"Type", 15, func(i *serial.PortInfo) string { return getPortType(i.Name) }},
+	"vidpid":       {"VID:PID", 9, formatVIDPID},
+	"serial":       {"Serial", 16, func(i *serial.PortInfo) string { return i.SerialNumber }},
+	"if":           {"IF", 4, func(i *serial.PortInfo) string { return i.InterfaceNumber }},
+	"bus":          {"Bus:Dev", 7, formatBusDevice},
+	"manufacturer": {"Manufacturer", 16, func(i *serial.PortInfo) string { return i.Manufacturer }},
+	"product":      {"Product", 24, func(i *serial.PortInfo) string { return i.Product }},
+	"driver":       {"Driver", 10, func(i *serial.PortInfo) string { return i.Driver }},
+	"description":  {"Description", 30, func(i *serial.PortInfo) string { return i.Description }},
+}
+
+// Column sets of the plain and --verbose tables
+var (
+	defaultListColumns = []string{"port", "type", "serial", "if", "description"}
+	verboseListColumns = []string{"port", "type", "vidpid", "serial", "if", "manufacturer", "product", "driver"}
+)
+
+// tableColumns returns the columns named, in order
+func tableColumns(names []string) ([]listColumn, error) {
+	columns := make([]listColumn, 0, len(names))
+	for _, name := range names {
+		col, ok := listColumns[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return nil, fmt.Errorf("unknown column: %s (valid: port, path, type, vidpid, serial, if, bus, manufacturer, product, driver, description)", name)
+		}
+		columns = append(columns, col)
+	}
+	return columns, nil
+}
+
+func formatVIDPID(i *serial.PortInfo) string {
+	if i.VendorID == "" {
+		return ""
+	}
+	return i.VendorID + ":" + i.ProductID
+}
+
+func formatBusDevice(i *serial.PortInfo) string {
+	if i.BusNumber == "" {
+		return ""
+	}
+	return i.BusNumber + ":" + i.DeviceNumber
+}
+
+// truncateCell shortens s to width runes, marking a cut with an ellipsis
+func truncateCell(s string, width int) string {
+	r := []rune(s)
+	if len(r) <= width {
+		return s
+	}
+	return string(r[:width-1]) + "…"
+}
+
 // renderTable renders the port list in a styled static table format
-func renderTable(ports []string) {
+// Empty values show as "-"; values wider than their column are cut, except
+// in the last column.
+func renderTable(ports []string, columns []listColumn) {
 	fmt.Printf("Found %d serial port(s):\n\n", len(ports))
 
-	// Define column widths
-	portWidth := 12
-	typeWidth := 15
-	serialWidth := 12
-	ifWidth := 4
-	descWidth := 30
-
 	// Create styles
 	headerStyle := lipgloss.NewStyle().
 		Bold(true).
@@ -131,46 +374,31 @@ func renderTable(ports []string) {
 	cellStyle := lipgloss.NewStyle().
 		PaddingRight(2)
 
+	row := func(cell func(col listColumn) string) string {
+		cells := make([]string, len(columns))
+		for i, col := range columns {
+			value := cell(col)
+			if value == "" {
+				value = "-"
+			}
+			if i < len(columns)-1 {
+				value = truncateCell(value, col.width)
+			}
+			cells[i] = fmt.Sprintf("%-*s", col.width, value)
+		}
+		return strings.Join(cells, " ")
+	}
+
 	// Print header
-	header := fmt.Sprintf("%-*s %-*s %-*s %-*s %-*s",
-		portWidth, "Port",
-		typeWidth, "Type",
-		serialWidth, "Serial",
-		ifWidth, "IF",
-		descWidth, "Description")
-	fmt.Println(headerStyle.Render(header))
+	fmt.Println(headerStyle.Render(row(func(col listColumn) string { return col.title })))
 
 	// Print rows
 	for _, port := range ports {
 		info, err := serial.GetPortInfo(port)
 		if err != nil {
-			row := fmt.Sprintf("%-*s %-*s %-*s %-*s %-*s",
-				portWidth, port,
-				typeWidth, "Unknown",
-				serialWidth, "-",
-				ifWidth, "-",
-				descWidth, fmt.Sprintf("Error: %v", err))
-			fmt.Println(cellStyle.Render(row))
-			continue
-		}
-
-		portType := getPortType(info.Name)
-		serialNum := info.SerialNumber
-		if serialNum == "" {
-			serialNum = "-"
-		}
-		ifNum := info.InterfaceNumber
-		if ifNum == "" {
-			ifNum = "-"
+			info = &serial.PortInfo{Name: filepath.Base(port), Path: port, Description: fmt.Sprintf("Error: %v", err)}
 		}
-
-		row := fmt.Sprintf("%-*s %-*s %-*s %-*s %-*s",
-			portWidth, info.Name,
-			typeWidth, portType,
-			serialWidth, serialNum,
-			ifWidth, ifNum,
-			descWidth, info.Description)
-		fmt.Println(cellStyle.Render(row))
+		fmt.Println(cellStyle.Render(row(func(col listColumn) string { return col.value(info) })))
 	}
 }
 
@@ -4,12 +4,20 @@ Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
 package cmd
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"os/signal"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/allbin/go-serial"
+	"github.com/allbin/go-serial/framing"
 	"github.com/allbin/go-serial/internal/tui/components"
 	"github.com/allbin/go-serial/internal/tui/keys"
 	"github.com/allbin/go-serial/internal/tui/models"
@@ -32,15 +40,49 @@ using a terminal user interface. Features include:
 - Real-time data streaming with timestamps
 - ASCII and hex display modes
 - Connection status indicators
+- Scrollback search for text or hex bytes (/, then n/N)
+- Filtering the display by regex or hex prefix, "!" to hide matches
+  (--filter, or f)
+- Coloring of errors, NAKs, Modbus exceptions and data matching the
+  highlights rules of the config file (--no-highlights to turn off)
+- Timestamps as time of day, delta from the previous line or time since a
+  mark (cycle with d, set the mark to now with m)
+- Exporting the scrollback to a file after the fact (w): raw bytes (.bin),
+  hex lines (.hex) or the connect --log text format
+- Bounded scrollback (--scrollback, --scrollback-bytes) that drops the oldest data first
+- Copying everything received to a file while it is shown (--output), in
+  the formats of serial capture (--output-format raw, hex, hexdump, lines)
+- Splitting messages at a delimiter other than line breaks, such as 0x03
+  ETX (--delimiter), or by a length prefix (--frame length-prefix with
+  --length-bytes and --length-order); --grep and --json use the same framing
+- Keeping only the lines that match a regex (--grep) or dropping those that
+  do (--grep-v), before they are shown or written to --output
+- Plain line-oriented output to stdout without the terminal interface
+  (--plain), for pipelines, dumb SSH sessions and services; honors
+  --no-timestamps, --show-indicators, --raw, --filter and --output
+- JSON lines on stdout (--json), one object per received line with its
+  timestamp, direction, hex, printable ASCII and length, for jq or log
+  shippers
+- Running totals of the bytes and lines shown, the throughput averaged over
+  --rate-window and frames lost to overflow in the status bar (reset with z)
 - Configurable baud rate and flow control
 - Clean, responsive interface
 
 Example usage:
   serial listen /dev/ttyUSB0
   serial listen /dev/ttyUSB0 --baud 9600
-  serial listen /dev/ttyUSB0 --flow-control cts --initial-rts`,
-	Args: cobra.ExactArgs(1),
+  serial listen /dev/ttyUSB0 --flow-control cts --initial-rts
+  serial listen /dev/ttyUSB0 --output boot.bin
+  serial listen /dev/ttyUSB0 --output gps.log --output-format lines
+  serial listen /dev/ttyUSB0 --plain --raw | grep -i error
+  serial listen /dev/ttyUSB0 --json | jq -r 'select(.len > 40) | .ascii'
+  serial listen /dev/ttyUSB0 --delimiter 0x03
+  serial listen /dev/ttyUSB0 --frame length-prefix --length-bytes 2 --length-order le
+  serial listen /dev/ttyUSB0 --grep '^\$GP(GGA|RMC)' --grep-v ',,,,'
+  serial listen /dev/ttyUSB0 --baud 921600 --scrollback 0 --scrollback-bytes 67108864`,
+	Args: portArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		args = withProfilePort(args, 1)
 		portPath := args[0]
 
 		// Get flags
@@ -54,8 +96,16 @@ Example usage:
 		// Configure port options
 		opts := []serial.Option{
 			serial.WithBaudRate(baudRate),
+			withDiagnostics(),
 		}
 
+		lineOpts, err := lineOptions(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		opts = append(opts, lineOpts...)
+
 		switch strings.ToLower(flowControl) {
 		case "cts":
 			opts = append(opts, serial.WithFlowControl(serial.FlowControlCTS))
@@ -69,8 +119,65 @@ Example usage:
 			}
 		}
 
-		// Start the TUI
-		if err := runListenTUI(portPath, noTimestamps, showIndicators, rawMode, opts...); err != nil {
+		scrollback, err := scrollbackOptions(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		filter, err := filterOptions(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		grep, err := grepOptions(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		codec, err := frameOptions(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		rateWindow, _ := cmd.Flags().GetDuration("rate-window")
+		if rateWindow <= 0 {
+			fmt.Fprintf(os.Stderr, "Error: --rate-window must be positive\n")
+			os.Exit(1)
+		}
+
+		plain, _ := cmd.Flags().GetBool("plain")
+		jsonLines, _ := cmd.Flags().GetBool("json")
+		if plain && jsonLines {
+			fmt.Fprintf(os.Stderr, "Error: --plain and --json cannot be combined\n")
+			os.Exit(1)
+		}
+
+		outputPath, _ := cmd.Flags().GetString("output")
+		outputFormat, _ := cmd.Flags().GetString("output-format")
+		output, err := openListenOutput(outputPath, outputFormat)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		highlights := highlightOptions(cmd)
+		switch {
+		case jsonLines:
+			err = runListenJSON(portPath, codec, grep, output, opts...)
+		case plain:
+			err = runListenPlain(portPath, noTimestamps, showIndicators, rawMode, highlights, filter, codec, grep, output, opts...)
+		default:
+			// Start the TUI
+			err = runListenTUI(portPath, noTimestamps, showIndicators, rawMode, scrollback, highlights, filter, codec, grep, output, rateWindow, opts...)
+		}
+		if cerr := output.close(); err == nil && cerr != nil {
+			err = fmt.Errorf("%s: %w", outputPath, cerr)
+		}
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
@@ -82,6 +189,7 @@ func init() {
 
 	// Add flags for serial configuration
 	listenCmd.Flags().IntP("baud", "b", 115200, "Baud rate (default: 115200)")
+	addLineFlags(listenCmd, 2500*time.Millisecond)
 	listenCmd.Flags().StringP("flow-control", "f", "none", "Flow control: none, cts, rtscts (default: none)")
 	listenCmd.Flags().Bool("initial-rts", false, "Assert RTS on port open (required for CTS flow control)")
 
@@ -89,6 +197,82 @@ func init() {
 	listenCmd.Flags().Bool("no-timestamps", false, "Hide timestamps from output")
 	listenCmd.Flags().Bool("show-indicators", false, "Show RX/TX indicators (off by default)")
 	listenCmd.Flags().Bool("raw", false, "Raw output mode: no timestamps, no indicators")
+	addScrollbackFlags(listenCmd)
+	addHighlightFlag(listenCmd)
+	addFilterFlag(listenCmd)
+	listenCmd.Flags().StringP("output", "o", "", "Also append received data to this file; --grep applies, --filter does not")
+	listenCmd.Flags().String("output-format", "raw", "Format of --output: raw, hex, hexdump, lines (as serial capture)")
+	addGrepFlags(listenCmd)
+	listenCmd.Flags().Duration("rate-window", 5*time.Second, "Period the throughput in the status bar is averaged over")
+	addFrameFlags(listenCmd)
+	listenCmd.Flags().Bool("plain", false, "Print received lines to stdout without the terminal interface, for pipes and services")
+	listenCmd.Flags().Bool("json", false, "Print one JSON object per received line to stdout: ts, dir, hex, ascii, len")
+}
+
+// listenOutput copies received data to the --output file while listen
+// shows it. The reader goroutine writes; it stops at the first error.
+type listenOutput struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+	out  *captureFormatter
+	err  error
+}
+
+// outputFailedMsg reports that writing the --output file failed
+type outputFailedMsg struct {
+	path string
+	err  error
+}
+
+// openListenOutput opens path for appending in a capture format; an empty
+// path gives a nil output that writes nothing
+func openListenOutput(path, format string) (*listenOutput, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if _, err := newCaptureFormatter(io.Discard, format); err != nil {
+		return nil, fmt.Errorf("--output-format: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open output file: %w", err)
+	}
+	out, _ := newCaptureFormatter(file, format)
+	return &listenOutput{path: path, file: file, out: out}, nil
+}
+
+// write appends data that arrived at at; it returns an error only the
+// first time writing fails
+func (o *listenOutput) write(at time.Time, data []byte) error {
+	if o == nil {
+		return nil
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.err != nil || o.file == nil {
+		return nil
+	}
+	o.err = o.out.write(at, data)
+	return o.err
+}
+
+// close writes out any partial line and closes the file
+func (o *listenOutput) close() error {
+	if o == nil {
+		return nil
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.file == nil {
+		return nil
+	}
+	err := o.out.close()
+	if cerr := o.file.Close(); err == nil {
+		err = cerr
+	}
+	o.file = nil
+	return err
 }
 
 // listenModel represents the Bubble Tea model for the listen command
@@ -96,11 +280,81 @@ type listenModel struct {
 	*models.SerialModel
 	terminal  *components.Terminal
 	statusBar *components.StatusBar
+	search    *components.Search
+	filter    *components.Filter
+	export    *components.Prompt
 	help      help.Model
-	keys      keys.TerminalKeys
+	keys      keys.ListenKeys
+	counters  *components.Counters
+	framed    bool // Every message is a frame; otherwise lines are counted by line break
+}
+
+// countersTickMsg refreshes the throughput shown while no data arrives
+// hexData logs bytes as hex, formatting them only when the record is
+// actually written
+type hexData []byte
+
+func (d hexData) LogValue() slog.Value {
+	return slog.StringValue(fmt.Sprintf("%X", []byte(d)))
 }
 
-func runListenTUI(portPath string, noTimestamps, showIndicators, rawMode bool, opts ...serial.Option) error {
+type countersTickMsg struct{}
+
+func countersTick() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return countersTickMsg{}
+	})
+}
+
+// frameDroppedMsg reports a frame the --delimiter or --frame codec rejected
+type frameDroppedMsg struct {
+	err error
+}
+
+// runListenPlain prints what the port receives to stdout until interrupted,
+// a line at a time, and copies it to output
+func runListenPlain(portPath string, noTimestamps, showIndicators, rawMode bool, highlights []components.HighlightRule, filter string, codec framing.Codec, grep *lineGrep, output *listenOutput, opts ...serial.Option) error {
+	port, err := serial.Open(portPath, opts...)
+	if err != nil {
+		return err
+	}
+	defer port.Close()
+
+	out := newPlainOutput(os.Stdout, noTimestamps || rawMode, !showIndicators || rawMode, filter, highlights)
+	defer out.Flush()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	record := func(at time.Time, data []byte) {
+		if len(data) == 0 {
+			return
+		}
+		if err := output.write(at, data); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", output.path, err)
+		}
+		out.Write(components.DataReceivedMsg{Timestamp: at, Data: data})
+	}
+	if codec != nil {
+		out.formatter.SetFramed(true)
+		return readListenFrames(ctx, port, codec, grep, func(msg components.DataReceivedMsg, frame []byte) error {
+			if err := output.write(msg.Timestamp, frame); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s: %v\n", output.path, err)
+			}
+			out.Write(msg)
+			return nil
+		}, func(err error) {
+			fmt.Fprintf(os.Stderr, "Error: dropped a frame: %v\n", err)
+		})
+	}
+	err = readPlain(ctx, port, func(msg components.DataReceivedMsg) {
+		record(grep.filter(msg.Timestamp, msg.Data))
+	})
+	record(grep.flush())
+	return err
+}
+
+func runListenTUI(portPath string, noTimestamps, showIndicators, rawMode bool, scrollback scrollbackLimit, highlights []components.HighlightRule, filter string, codec framing.Codec, grep *lineGrep, output *listenOutput, rateWindow time.Duration, opts ...serial.Option) error {
 
 	// Create configuration from options to show in status bar
 	config := serial.DefaultConfig()
@@ -119,7 +373,13 @@ func runListenTUI(portPath string, noTimestamps, showIndicators, rawMode bool, o
 
 	// Create initial model
 	serialModel := models.NewSerialModel(portPath)
+	serialModel.SetScrollbackLimit(scrollback.messages, scrollback.bytes)
 	terminal := components.NewTerminal(80, 20)
+	terminal.SetHighlights(highlights)
+	filterModel := components.NewFilter()
+	filterModel.Set(filter) // Checked by filterOptions
+	terminal.SetFilter(filterModel)
+	terminal.SetFramed(codec != nil)
 
 	// Configure formatting options
 	// Default: no indicators, show timestamps
@@ -136,10 +396,16 @@ func runListenTUI(portPath string, noTimestamps, showIndicators, rawMode bool, o
 		terminal:    terminal,
 		statusBar:   components.NewStatusBar("Serial Listen", portPath),
 		help:        help.New(),
-		keys:        keys.NewTerminalKeys(),
+		keys:        keys.NewListenKeys(),
+		search:      components.NewSearch(),
+		filter:      filterModel,
+		export:      components.NewPrompt("export to: ", "file; .bin for raw bytes, .hex for hex lines, else text"),
+		counters:    components.NewCounters(rateWindow, time.Now()),
+		framed:      codec != nil,
 	}
 	m.statusBar.SetConnecting()
 	m.statusBar.SetConnectionInfo(connInfo)
+	m.statusBar.SetLogging(output != nil)
 
 	// Start the TUI with alt screen and input handling
 	p := tea.NewProgram(&m, tea.WithAltScreen(), tea.WithMouseCellMotion())
@@ -148,7 +414,7 @@ func runListenTUI(portPath string, noTimestamps, showIndicators, rawMode bool, o
 	go func() {
 		port, err := serial.Open(portPath, opts...)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "[ERROR] Failed to open port: %v\n", err)
+			logger.Error("failed to open port", "port", portPath, "err", err)
 			p.Send(models.ConnectionStatusMsg{Connected: false, Error: err})
 			return
 		}
@@ -160,6 +426,8 @@ func runListenTUI(portPath string, noTimestamps, showIndicators, rawMode bool, o
 
 		// Start reading data with context cancellation
 		go func() {
+			batch := components.NewBatcher(p.Send, components.RenderInterval)
+			defer batch.Stop()
 			defer func() {
 				// Only close the port when this goroutine exits, don't cleanup the whole model
 				if port != nil {
@@ -167,7 +435,24 @@ func runListenTUI(portPath string, noTimestamps, showIndicators, rawMode bool, o
 				}
 			}()
 
-			buffer := make([]byte, 4096)
+			if codec != nil {
+				err := readListenFrames(m.GetContext(), port, codec, grep, func(msg components.DataReceivedMsg, frame []byte) error {
+					if err := output.write(msg.Timestamp, frame); err != nil {
+						p.Send(outputFailedMsg{path: output.path, err: err})
+					}
+					batch.Send(msg)
+					return nil
+				}, func(err error) {
+					logger.Warn("dropped a frame", "err", err)
+					p.Send(frameDroppedMsg{err: err})
+				})
+				if err != nil {
+					logger.Error("listen read failed", "err", err)
+				}
+				return
+			}
+
+			slab := serial.NewReadSlab(readSlabSize)
 			for {
 				select {
 				case <-m.GetContext().Done():
@@ -175,7 +460,7 @@ func runListenTUI(portPath string, noTimestamps, showIndicators, rawMode bool, o
 					return
 				default:
 					// Try to read data from the serial port
-					n, err := port.ReadContext(m.GetContext(), buffer)
+					n, at, err := serial.ReadTimestamped(m.GetContext(), port, slab.Next(4096))
 					if err != nil {
 						// Check if it's a context cancellation
 						if m.GetContext().Err() != nil {
@@ -185,17 +470,19 @@ func runListenTUI(portPath string, noTimestamps, showIndicators, rawMode bool, o
 						continue
 					}
 					if n > 0 {
-						// Debug: Log raw bytes received to file
-						if debugFile, err := os.OpenFile("serial_debug.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-							fmt.Fprintf(debugFile, "[SERIAL_RX] Read %d bytes: %X\n", n, buffer[:n])
-							debugFile.Close()
-						}
+						data := slab.Take(n)
+						logger.Debug("serial rx", "bytes", n, "data", hexData(data))
 
 						// Send raw data with timestamp - formatting will happen in Update method
-						data := make([]byte, n)
-						copy(data, buffer[:n])
-						p.Send(components.DataReceivedMsg{
-							Timestamp: time.Now(),
+						at, data := grep.filter(at, data)
+						if len(data) == 0 {
+							continue
+						}
+						if err := output.write(at, data); err != nil {
+							p.Send(outputFailedMsg{path: output.path, err: err})
+						}
+						batch.Send(components.DataReceivedMsg{
+							Timestamp: at,
 							Data:      data,
 						})
 					}
@@ -212,7 +499,40 @@ func runListenTUI(portPath string, noTimestamps, showIndicators, rawMode bool, o
 }
 
 func (m *listenModel) Init() tea.Cmd {
-	return nil
+	return countersTick()
+}
+
+// receive counts and stores a received message; the caller flushes the
+// terminal
+func (m *listenModel) receive(msg components.DataReceivedMsg) {
+	// Safely handle the data message
+	defer func() {
+		if r := recover(); r != nil {
+			// If there's a panic in data handling, don't crash the whole UI
+			// Just continue running
+		}
+	}()
+
+	// Ensure we're ready to display data - if window size hasn't been set yet,
+	// use reasonable defaults
+	if !m.IsReady() {
+		m.terminal.SetSize(80, 20) // Default terminal size
+		m.SetReady(true)
+	}
+
+	lines := bytes.Count(msg.Data, []byte("\n"))
+	if m.framed {
+		lines = 1
+	}
+	m.counters.Add(msg.Timestamp, len(msg.Data), lines)
+
+	// Rebuild the view if the scrollback limit evicted old messages
+	if m.AddRawData(msg) > 0 {
+		m.terminal.RefreshDisplayWithRawData(m.GetRawData())
+		m.statusBar.SetDropped(m.Dropped())
+	} else {
+		m.terminal.AppendMessage(msg)
+	}
 }
 
 func (m *listenModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -242,25 +562,58 @@ func (m *listenModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 	case components.DataReceivedMsg:
-		// Safely handle the data message
-		defer func() {
-			if r := recover(); r != nil {
-				// If there's a panic in data handling, don't crash the whole UI
-				// Just continue running
-			}
-		}()
+		m.receive(msg)
+		m.terminal.Flush()
 
-		// Ensure we're ready to display data - if window size hasn't been set yet,
-		// use reasonable defaults
-		if !m.IsReady() {
-			m.terminal.SetSize(80, 20) // Default terminal size
-			m.SetReady(true)
+	case components.DataBatchMsg:
+		// Data arriving faster than RenderInterval is rendered once per batch
+		for _, msg := range msg {
+			m.receive(msg)
 		}
+		m.terminal.Flush()
+
+	case countersTickMsg:
+		return m, countersTick()
+
+	case frameDroppedMsg:
+		m.counters.AddOverflow()
 
-		m.AddRawData(msg)
-		m.terminal.AddMessage(msg)
+	case outputFailedMsg:
+		m.statusBar.SetLogging(false)
+		m.terminal.AddFormattedMessage(fmt.Sprintf("Writing %s failed, output stopped: %v", msg.path, msg.err))
 
 	case tea.KeyMsg:
+		// While a search query is typed, all keys go to it
+		if m.search.IsTyping() {
+			done, cmd := m.search.Update(msg)
+			if done {
+				m.terminal.SetSearch(m.search)
+			}
+			return m, cmd
+		}
+		if m.filter.IsTyping() {
+			done, cmd := m.filter.Update(msg)
+			if done {
+				m.terminal.SetFilter(m.filter)
+				m.terminal.RefreshDisplayWithRawData(m.GetRawData())
+			}
+			return m, cmd
+		}
+
+		if m.export.IsTyping() {
+			path, done, ok, cmd := m.export.Update(msg)
+			if done && ok {
+				msgs := m.GetRawData()
+				format, err := exportScrollback(path, m.GetPortPath(), msgs)
+				if err != nil {
+					m.terminal.AddFormattedMessage(fmt.Sprintf("Export failed: %v", err))
+				} else {
+					m.terminal.AddFormattedMessage(fmt.Sprintf("Exported %d messages to %s (%s)", len(msgs), path, format))
+				}
+			}
+			return m, cmd
+		}
+
 		switch {
 		case key.Matches(msg, m.keys.Quit):
 			m.Cleanup()
@@ -269,6 +622,7 @@ func (m *listenModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case key.Matches(msg, m.keys.Clear):
 			m.ClearData()
 			m.terminal.Clear()
+			m.statusBar.SetDropped(0)
 
 		case key.Matches(msg, m.keys.Help):
 			m.help.ShowAll = !m.help.ShowAll
@@ -288,6 +642,36 @@ func (m *listenModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case key.Matches(msg, m.keys.ToggleIndicators):
 			m.terminal.ToggleIndicators()
 			m.terminal.RefreshDisplayWithRawData(m.GetRawData())
+
+		case key.Matches(msg, m.keys.TimeMode):
+			m.terminal.NextTimeMode()
+			m.terminal.RefreshDisplayWithRawData(m.GetRawData())
+
+		case key.Matches(msg, m.keys.SetMark):
+			m.terminal.SetMark(time.Now())
+			m.terminal.RefreshDisplayWithRawData(m.GetRawData())
+
+		case key.Matches(msg, m.keys.ResetCounters):
+			m.counters.Reset(time.Now())
+
+		case key.Matches(msg, m.keys.Search):
+			m.search.Start()
+
+		case key.Matches(msg, m.keys.Filter):
+			m.filter.Start()
+
+		case key.Matches(msg, m.keys.Export):
+			m.export.Start(logFileName(m.GetPortPath()))
+
+		case key.Matches(msg, m.keys.NextMatch):
+			m.terminal.NextMatch(true)
+
+		case key.Matches(msg, m.keys.PrevMatch):
+			m.terminal.NextMatch(false)
+
+		case key.Matches(msg, m.keys.Escape):
+			m.search.Clear()
+			m.terminal.SetSearch(m.search)
 		}
 	}
 
@@ -327,7 +711,20 @@ func (m *listenModel) View() string {
 	}
 	m.statusBar.SetWidth(terminalWidth)
 
+	m.statusBar.SetSearch(m.search.Status())
+	m.statusBar.SetFilter(m.filter.Status(m.terminal.Hidden()))
+	m.statusBar.SetTimeMode(m.terminal.TimeStatus())
+	m.statusBar.SetCounters(m.counters.Summary(time.Now()))
 	statusBar := m.statusBar.ComprehensiveStatusBar(inputMode, sendingMode, "FOLLOW", m.IsConnected(), timestamp)
+	if m.search.IsTyping() {
+		statusBar = lipgloss.NewStyle().Width(terminalWidth).Render(m.search.View())
+	}
+	if m.filter.IsTyping() {
+		statusBar = lipgloss.NewStyle().Width(terminalWidth).Render(m.filter.View())
+	}
+	if m.export.IsTyping() {
+		statusBar = lipgloss.NewStyle().Width(terminalWidth).Render(m.export.View())
+	}
 
 	// Layout without header, with comprehensive status bar at bottom
 	contentWithBorder := styles.ContentBorderStyle.Render(content)
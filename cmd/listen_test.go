@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/allbin/go-serial"
+)
+
+func TestHexDataLogValue(t *testing.T) {
+	var buf bytes.Buffer
+	serial.NewTextLogger(&buf, slog.LevelDebug).Debug("serial rx", "data", hexData{0x0a, 'A', 0xff})
+	if !strings.Contains(buf.String(), "data=0A41FF") {
+		t.Errorf("log output = %q, want data=0A41FF", buf.String())
+	}
+
+	buf.Reset()
+	serial.NewTextLogger(&buf, slog.LevelInfo).Debug("serial rx", "data", hexData{0x0a})
+	if buf.Len() != 0 {
+		t.Errorf("log output = %q, want nothing below the level", buf.String())
+	}
+}
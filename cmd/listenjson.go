@@ -0,0 +1,107 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/allbin/go-serial"
+	"github.com/allbin/go-serial/framing"
+	"github.com/allbin/go-serial/internal/tui/components"
+)
+
+// jsonRecord is one message of listen --json output
+type jsonRecord struct {
+	TS    time.Time `json:"ts"`
+	Dir   string    `json:"dir"`
+	Hex   string    `json:"hex"`
+	ASCII string    `json:"ascii"`
+	Len   int       `json:"len"`
+}
+
+// newJSONRecord describes a received message; a CR left over from a CRLF
+// line ending is not part of it
+func newJSONRecord(at time.Time, payload []byte) jsonRecord {
+	payload = bytes.TrimSuffix(payload, []byte("\r"))
+	ascii := make([]byte, len(payload))
+	for i, b := range payload {
+		if b >= 32 && b <= 126 {
+			ascii[i] = b
+		} else {
+			ascii[i] = '.'
+		}
+	}
+	return jsonRecord{
+		TS:    at,
+		Dir:   "rx",
+		Hex:   fmt.Sprintf("%X", payload),
+		ASCII: string(ascii),
+		Len:   len(payload),
+	}
+}
+
+// runListenJSON prints one JSON object per message received to stdout until
+// interrupted; messages are lines unless codec splits them otherwise
+func runListenJSON(portPath string, codec framing.Codec, grep *lineGrep, output *listenOutput, opts ...serial.Option) error {
+	if codec == nil {
+		codec = framing.NewDelimited([]byte("\n"))
+	}
+	port, err := serial.Open(portPath, opts...)
+	if err != nil {
+		return err
+	}
+	defer port.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	enc := json.NewEncoder(os.Stdout)
+	return readListenFrames(ctx, port, codec, grep, func(msg components.DataReceivedMsg, frame []byte) error {
+		if err := output.write(msg.Timestamp, frame); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", output.path, err)
+		}
+		return enc.Encode(newJSONRecord(msg.Timestamp, msg.Data))
+	}, func(err error) {
+		fmt.Fprintf(os.Stderr, "Error: dropped a frame: %v\n", err)
+	})
+}
+
+// readListenFrames passes the messages codec splits the port's data into to
+// send, with the frame each came in, until ctx is done or send fails.
+// Messages grep drops are skipped. Frames the codec rejects, such as
+// oversized ones, are reported to dropped.
+func readListenFrames(ctx context.Context, port serial.Port, codec framing.Codec, grep *lineGrep, send func(msg components.DataReceivedMsg, frame []byte) error, dropped func(error)) error {
+	pp := framing.NewPacketPort(port, codec)
+	for {
+		payload, at, err := pp.ReadPacket(ctx)
+		if errors.Is(err, framing.ErrFrameTooLarge) || errors.Is(err, framing.ErrChecksumMismatch) {
+			dropped(err)
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("read failed: %w", err)
+		}
+		if grep != nil && !grep.matches(payload) {
+			continue
+		}
+		frame, err := codec.Encode(payload)
+		if err != nil {
+			frame = payload
+		}
+		if err := send(components.DataReceivedMsg{Timestamp: at, Data: payload}, frame); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/allbin/go-serial"
+)
+
+// portFilter selects ports for list --filter; a port must match every term
+type portFilter []func(info *serial.PortInfo) bool
+
+// parsePortFilter parses comma-separated terms such as
+// "vid:pid=0403:6010,serial=FT12". The port types usb, standard and arm
+// stand alone or follow type=; all and an empty expression match any port.
+func parsePortFilter(expr string) (portFilter, error) {
+	var f portFilter
+	for _, term := range strings.Split(expr, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(term, "=")
+		if !ok {
+			key, value = "type", term
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value == "" {
+			return nil, fmt.Errorf("empty value in filter term %q", term)
+		}
+
+		switch key {
+		case "type":
+			match, err := portTypeMatcher(value)
+			if err != nil {
+				return nil, err
+			}
+			f = append(f, match)
+		case "vid:pid":
+			vid, pid, ok := strings.Cut(value, ":")
+			if !ok || vid == "" || pid == "" {
+				return nil, fmt.Errorf("vid:pid needs both IDs, such as vid:pid=0403:6010, got %q", value)
+			}
+			f = append(f, func(info *serial.PortInfo) bool {
+				return matchID(info.VendorID, vid) && matchID(info.ProductID, pid)
+			})
+		case "vid":
+			f = append(f, func(info *serial.PortInfo) bool { return matchID(info.VendorID, value) })
+		case "pid":
+			f = append(f, func(info *serial.PortInfo) bool { return matchID(info.ProductID, value) })
+		case "serial":
+			f = append(f, func(info *serial.PortInfo) bool {
+				return strings.Contains(strings.ToLower(info.SerialNumber), value)
+			})
+		case "driver":
+			f = append(f, func(info *serial.PortInfo) bool { return strings.EqualFold(info.Driver, value) })
+		default:
+			return nil, fmt.Errorf("unknown filter key: %s (valid: type, vid:pid, vid, pid, serial, driver)", key)
+		}
+	}
+	return f, nil
+}
+
+// portTypeMatcher matches ports of a type by their device name
+func portTypeMatcher(portType string) (func(info *serial.PortInfo) bool, error) {
+	var prefixes []string
+	switch portType {
+	case "all":
+		return func(*serial.PortInfo) bool { return true }, nil
+	case "usb":
+		prefixes = []string{"ttyusb", "ttyacm"}
+	case "standard":
+		prefixes = []string{"ttys"}
+	case "arm":
+		prefixes = []string{"ttyama"}
+	default:
+		return nil, fmt.Errorf("unknown port type: %s (valid: usb, standard, arm, all)", portType)
+	}
+	return func(info *serial.PortInfo) bool {
+		name := strings.ToLower(info.Name)
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// matchID compares a USB ID with a wanted one, ignoring case and leading
+// zeros; "*" matches any ID
+func matchID(id, want string) bool {
+	if want == "*" {
+		return true
+	}
+	return id != "" && strings.TrimLeft(strings.ToLower(id), "0") == strings.TrimLeft(want, "0")
+}
+
+// matches reports whether the port described by info passes every term
+func (f portFilter) matches(info *serial.PortInfo) bool {
+	for _, match := range f {
+		if !match(info) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/allbin/go-serial"
+)
+
+func TestMatchID(t *testing.T) {
+	tests := []struct {
+		id, want string
+		match    bool
+	}{
+		{"0403", "0403", true},
+		{"0403", "403", true},
+		{"1A86", "1a86", true},
+		{"1a86", "1a87", false},
+		{"", "*", true},
+		{"6010", "*", true},
+		{"", "0403", false},
+		{"0000", "0", true},
+	}
+
+	for _, tt := range tests {
+		if got := matchID(tt.id, tt.want); got != tt.match {
+			t.Errorf("matchID(%q, %q) = %v, want %v", tt.id, tt.want, got, tt.match)
+		}
+	}
+}
+
+func TestParsePortFilter(t *testing.T) {
+	ftdi := &serial.PortInfo{Name: "ttyUSB0", VendorID: "0403", ProductID: "6010", SerialNumber: "FT12AB", Driver: "ftdi_sio"}
+	ch340 := &serial.PortInfo{Name: "ttyUSB1", VendorID: "1a86", ProductID: "7523", Driver: "ch341"}
+	acm := &serial.PortInfo{Name: "ttyACM0", VendorID: "2341", ProductID: "0043", Driver: "cdc_acm"}
+	uart := &serial.PortInfo{Name: "ttyS0"}
+	pi := &serial.PortInfo{Name: "ttyAMA0"}
+	ports := []*serial.PortInfo{ftdi, ch340, acm, uart, pi}
+
+	tests := []struct {
+		expr string
+		want []*serial.PortInfo
+	}{
+		{"", ports},
+		{"all", ports},
+		{"usb", []*serial.PortInfo{ftdi, ch340, acm}},
+		{"type=standard", []*serial.PortInfo{uart}},
+		{"ARM", []*serial.PortInfo{pi}},
+		{"vid:pid=0403:6010", []*serial.PortInfo{ftdi}},
+		{"vid:pid=403:6010", []*serial.PortInfo{ftdi}},
+		{"VID:PID=1A86:7523", []*serial.PortInfo{ch340}},
+		{"vid:pid=0403:*", []*serial.PortInfo{ftdi}},
+		{"vid:pid=*:0043", []*serial.PortInfo{acm}},
+		{"vid:pid=*:*", ports},
+		{"vid=1a86", []*serial.PortInfo{ch340}},
+		{"pid=*", ports},
+		{"serial=ft12", []*serial.PortInfo{ftdi}},
+		{"driver=CDC_ACM", []*serial.PortInfo{acm}},
+		{"usb, vid=0403 ,serial=ab", []*serial.PortInfo{ftdi}},
+		{"standard,vid=0403", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			f, err := parsePortFilter(tt.expr)
+			if err != nil {
+				t.Fatalf("parsePortFilter(%q) failed: %v", tt.expr, err)
+			}
+			var got []*serial.PortInfo
+			for _, info := range ports {
+				if f.matches(info) {
+					got = append(got, info)
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("matched %d ports, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("match %d = %s, want %s", i, got[i].Name, tt.want[i].Name)
+				}
+			}
+		})
+	}
+}
+
+func TestParsePortFilterErrors(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr string
+	}{
+		{"serial=", "empty value"},
+		{"vid:pid=0403", "needs both IDs"},
+		{"vid:pid=0403:", "needs both IDs"},
+		{"vid:pid=:6010", "needs both IDs"},
+		{"bogus", "unknown port type"},
+		{"type=pci", "unknown port type"},
+		{"color=red", "unknown filter key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := parsePortFilter(tt.expr)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parsePortFilter(%q) error = %v, want one containing %q", tt.expr, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/allbin/go-serial/internal/tui/components"
+	"go.yaml.in/yaml/v3"
+)
+
+// macroFile is a connect --macros file; its triggers take the format of
+// script triggers and answer for as long as connect runs
+//
+//	macros:
+//	  - key: f1
+//	    name: Ping
+//	    hex: "02 06 00 03 00 00 00 99"
+//	  - key: f2
+//	    name: Status
+//	    ascii: "AT+STATUS\r"
+//	    repeat: 1s
+//	triggers:
+//	  - name: keepalive
+//	    on: "PING"
+//	    send: "PONG\r\n"
+type macroFile struct {
+	Macros   []macroEntry    `yaml:"macros"`
+	Triggers []scriptTrigger `yaml:"triggers"`
+}
+
+type macroEntry struct {
+	Key    string        `yaml:"key"`
+	Name   string        `yaml:"name"`
+	ASCII  *string       `yaml:"ascii"`
+	Hex    *string       `yaml:"hex"`
+	Repeat time.Duration `yaml:"repeat"`
+}
+
+// macroKeys are the keys macros may be bound to
+var macroKeys = []string{"f1", "f2", "f3", "f4", "f5", "f6", "f7", "f8", "f9", "f10", "f11", "f12"}
+
+// loadMacros parses and validates a macro file
+func loadMacros(path string) ([]*components.Macro, []*scriptTrigger, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	var file macroFile
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	seen := make(map[string]bool)
+	macros := make([]*components.Macro, 0, len(file.Macros))
+	for i, e := range file.Macros {
+		m, err := e.resolve()
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: macro %d: %w", path, i+1, err)
+		}
+		if seen[m.Key] {
+			return nil, nil, fmt.Errorf("%s: macro %d: key %s bound twice", path, i+1, m.Key)
+		}
+		seen[m.Key] = true
+		macros = append(macros, m)
+	}
+
+	triggers := make([]*scriptTrigger, len(file.Triggers))
+	for i := range file.Triggers {
+		if err := file.Triggers[i].resolve(); err != nil {
+			return nil, nil, fmt.Errorf("%s: trigger %d: %w", path, i+1, err)
+		}
+		triggers[i] = &file.Triggers[i]
+	}
+	return macros, triggers, nil
+}
+
+func (e macroEntry) resolve() (*components.Macro, error) {
+	key := strings.ToLower(e.Key)
+	valid := false
+	for _, k := range macroKeys {
+		if key == k {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return nil, fmt.Errorf("invalid key %q (want f1-f12)", e.Key)
+	}
+	if (e.ASCII == nil) == (e.Hex == nil) {
+		return nil, fmt.Errorf("want exactly one of ascii, hex")
+	}
+	if e.Repeat < 0 {
+		return nil, fmt.Errorf("invalid repeat %v", e.Repeat)
+	}
+
+	m := &components.Macro{Key: key, Name: e.Name, Repeat: e.Repeat}
+	if e.Hex != nil {
+		data, err := parseHexInput(*e.Hex)
+		if err != nil {
+			return nil, err
+		}
+		m.Data = data
+		m.IsHex = true
+	} else {
+		if *e.ASCII == "" {
+			return nil, fmt.Errorf("empty payload")
+		}
+		m.Data = []byte(*e.ASCII)
+	}
+	if m.Name == "" {
+		m.Name = formatMacroPayload(m)
+	}
+	return m, nil
+}
+
+// formatMacroPayload renders a macro's payload as the terminal shows it
+func formatMacroPayload(m *components.Macro) string {
+	if m.IsHex {
+		return fmt.Sprintf("% X", m.Data)
+	}
+	return strings.Trim(fmt.Sprintf("%q", m.Data), `"`)
+}
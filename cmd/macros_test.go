@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadMacros(t *testing.T) {
+	macros, triggers, err := loadMacros(writeScript(t, `
+macros:
+  - key: F1
+    name: Ping
+    hex: "02 06 00 03"
+  - key: f2
+    ascii: "AT+STATUS\r"
+    repeat: 1s
+triggers:
+  - name: keepalive
+    on: "PING"
+    send: "PONG\r\n"
+`))
+	if err != nil {
+		t.Fatalf("loadMacros failed: %v", err)
+	}
+	if len(macros) != 2 || len(triggers) != 1 {
+		t.Fatalf("loaded %d macros and %d triggers, want 2 and 1", len(macros), len(triggers))
+	}
+
+	ping, status := macros[0], macros[1]
+	if ping.Key != "f1" || ping.Name != "Ping" || !ping.IsHex || !bytes.Equal(ping.Data, []byte{0x02, 0x06, 0x00, 0x03}) {
+		t.Errorf("f1 macro = %+v", ping)
+	}
+	// An unnamed macro is labeled with its payload
+	if status.Name != `AT+STATUS\r` || status.IsHex || status.Repeat != time.Second {
+		t.Errorf("f2 macro = %+v", status)
+	}
+	if triggers[0].desc != "keepalive" || string(triggers[0].data) != "PONG\r\n" {
+		t.Errorf("trigger = %s sending %q", triggers[0].desc, triggers[0].data)
+	}
+}
+
+func TestLoadMacrosErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"invalid key", "macros:\n  - key: f13\n    ascii: x\n", `macro 1: invalid key "f13"`},
+		{"key bound twice", "macros:\n  - key: f1\n    ascii: x\n  - key: F1\n    ascii: y\n", "macro 2: key f1 bound twice"},
+		{"no payload", "macros:\n  - key: f1\n", "macro 1: want exactly one of ascii, hex"},
+		{"two payloads", "macros:\n  - key: f1\n    ascii: x\n    hex: \"01\"\n", "macro 1: want exactly one of ascii, hex"},
+		{"empty ascii", "macros:\n  - key: f1\n    ascii: \"\"\n", "macro 1: empty payload"},
+		{"negative repeat", "macros:\n  - key: f1\n    ascii: x\n    repeat: -1s\n", "macro 1: invalid repeat -1s"},
+		{"unknown field", "macros:\n  - key: f1\n    text: x\n", "field text not found"},
+		{"bad trigger", "triggers:\n  - on: x\n", "trigger 1: want exactly one of send, send-hex"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := loadMacros(writeScript(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("loadMacros error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
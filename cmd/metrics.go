@@ -0,0 +1,35 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/allbin/go-serial/metrics"
+)
+
+// startMetrics serves a new registry on addr's /metrics until the process exits
+// It returns nil without error when addr is empty.
+func startMetrics(addr string) (*metrics.Registry, error) {
+	if addr == "" {
+		return nil, nil
+	}
+
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for metrics: %w", err)
+	}
+
+	reg := metrics.New()
+	go func() {
+		if err := reg.Serve(context.Background(), l); err != nil {
+			logger.Error("metrics server stopped", "err", err)
+		}
+	}()
+	fmt.Fprintf(os.Stderr, "Serving metrics on http://%s/metrics\n", l.Addr())
+	return reg, nil
+}
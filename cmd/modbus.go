@@ -0,0 +1,305 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/allbin/go-serial"
+	"github.com/allbin/go-serial/modbus"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+)
+
+// modbusCmd represents the modbus command
+var modbusCmd = &cobra.Command{
+	Use:   "modbus",
+	Short: "Read and write Modbus RTU devices",
+	Long: `Read coils, discrete inputs and registers of a Modbus RTU device, or write
+its coils and holding registers, for quick field diagnostics.
+
+Modbus RTU devices commonly use 9600 or 19200 baud with even parity; set
+them with --baud and --parity. Addresses are the 0-based protocol
+addresses, so holding register 40001 is --addr 0.
+
+The exit status is 0 on success, 2 when the device does not answer or
+answers with an exception (such as illegal data address), and 1 on other
+errors.`,
+}
+
+// modbusReadCmds are the read subcommands and the function each uses
+var modbusReadCmds = []struct {
+	use, short string
+	fn         modbus.FunctionCode
+}{
+	{"read-coils", "Read coils (function 1)", modbus.ReadCoils},
+	{"read-discrete", "Read discrete inputs (function 2)", modbus.ReadDiscreteInputs},
+	{"read-holding", "Read holding registers (function 3)", modbus.ReadHoldingRegisters},
+	{"read-input", "Read input registers (function 4)", modbus.ReadInputRegisters},
+}
+
+// modbusWriteCoilCmd represents the modbus write-coil command
+var modbusWriteCoilCmd = &cobra.Command{
+	Use:   "write-coil <port>",
+	Short: "Write coils (function 5, or 15 for several)",
+	Long: `Turn coils on or off, starting at --addr. --value takes on, off, 1 or 0,
+comma-separated for consecutive coils. One value is written with Write
+Single Coil unless --multiple asks for Write Multiple Coils.
+
+Example usage:
+  serial modbus write-coil /dev/ttyUSB0 --unit 1 --addr 4 --value on
+  serial modbus write-coil /dev/ttyUSB0 --addr 0 --value on,off,on`,
+	Args: portArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		args = withProfilePort(args, 1)
+		texts, _ := cmd.Flags().GetStringSlice("value")
+		multiple, _ := cmd.Flags().GetBool("multiple")
+
+		values := make([]bool, len(texts))
+		for i, text := range texts {
+			switch strings.ToLower(text) {
+			case "on", "1", "true":
+				values[i] = true
+			case "off", "0", "false":
+			default:
+				fmt.Fprintf(os.Stderr, "Error: invalid coil value %q (want on or off)\n", text)
+				os.Exit(1)
+			}
+		}
+
+		runModbus(cmd, args[0], func(ctx context.Context, client *modbus.Client, unit byte, addr uint16) error {
+			var err error
+			if len(values) == 1 && !multiple {
+				err = client.WriteSingleCoil(ctx, unit, addr, values[0])
+			} else {
+				err = client.WriteMultipleCoils(ctx, unit, addr, values)
+			}
+			if err == nil {
+				printModbusWrite(len(values), "coil", addr, unit)
+			}
+			return err
+		})
+	},
+}
+
+// modbusWriteRegisterCmd represents the modbus write-register command
+var modbusWriteRegisterCmd = &cobra.Command{
+	Use:   "write-register <port>",
+	Short: "Write holding registers (function 6, or 16 for several)",
+	Long: `Set holding registers, starting at --addr. --value takes decimal, negative
+(as 16-bit two's complement) or 0x hex values, comma-separated for
+consecutive registers. One value is written with Write Single Register
+unless --multiple asks for Write Multiple Registers.
+
+Example usage:
+  serial modbus write-register /dev/ttyUSB0 --unit 1 --addr 10 --value 1500
+  serial modbus write-register /dev/ttyUSB0 --addr 0 --value 0x0102,-1,7`,
+	Args: portArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		args = withProfilePort(args, 1)
+		texts, _ := cmd.Flags().GetStringSlice("value")
+		multiple, _ := cmd.Flags().GetBool("multiple")
+
+		values := make([]uint16, len(texts))
+		for i, text := range texts {
+			v, err := parseRegisterValue(text)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			values[i] = v
+		}
+
+		runModbus(cmd, args[0], func(ctx context.Context, client *modbus.Client, unit byte, addr uint16) error {
+			var err error
+			if len(values) == 1 && !multiple {
+				err = client.WriteSingleRegister(ctx, unit, addr, values[0])
+			} else {
+				err = client.WriteMultipleRegisters(ctx, unit, addr, values)
+			}
+			if err == nil {
+				printModbusWrite(len(values), "register", addr, unit)
+			}
+			return err
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(modbusCmd)
+
+	for _, rc := range modbusReadCmds {
+		fn := rc.fn
+		cmd := &cobra.Command{
+			Use:   rc.use + " <port>",
+			Short: rc.short,
+			Long: rc.short + `, --count of them starting at --addr, and print them as a
+table or, with --json, a JSON object for scripts. Registers are shown in
+hex, unsigned and signed.
+
+Example usage:
+  serial modbus ` + rc.use + ` /dev/ttyUSB0 --unit 1 --addr 0 --count 10
+  serial modbus ` + rc.use + ` /dev/ttyUSB0 --baud 9600 --parity even --json`,
+			Args: portArgs(1),
+			Run: func(cmd *cobra.Command, args []string) {
+				args = withProfilePort(args, 1)
+				count, _ := cmd.Flags().GetUint16("count")
+				jsonOutput, _ := cmd.Flags().GetBool("json")
+				runModbus(cmd, args[0], func(ctx context.Context, client *modbus.Client, unit byte, addr uint16) error {
+					return readModbus(ctx, client, fn, unit, addr, count, jsonOutput)
+				})
+			},
+		}
+		addModbusFlags(cmd)
+		cmd.Flags().Uint16("count", 1, "Number of values to read")
+		cmd.Flags().Bool("json", false, "Print the values as a JSON object")
+		modbusCmd.AddCommand(cmd)
+	}
+
+	for _, cmd := range []*cobra.Command{modbusWriteCoilCmd, modbusWriteRegisterCmd} {
+		addModbusFlags(cmd)
+		cmd.Flags().StringSlice("value", nil, "Values to write, comma-separated for consecutive addresses")
+		cmd.Flags().Bool("multiple", false, "Use the write multiple function even for one value")
+		cmd.MarkFlagRequired("value")
+		modbusCmd.AddCommand(cmd)
+	}
+}
+
+// addModbusFlags registers the port and request flags of the modbus
+// subcommands
+func addModbusFlags(cmd *cobra.Command) {
+	addPortFlags(cmd, 100*time.Millisecond)
+	cmd.Flags().Uint8("unit", 1, "Unit (slave) address, 0 to broadcast a write")
+	cmd.Flags().Uint16("addr", 0, "First address (0-based)")
+	cmd.Flags().Duration("timeout", modbus.DefaultTimeout, "How long to wait for a response")
+	cmd.Flags().Int("retries", modbus.DefaultRetries, "Resends when the device does not answer")
+}
+
+// runModbus opens the port of a modbus subcommand, runs request and exits
+// with the documented status
+func runModbus(cmd *cobra.Command, portPath string, request func(ctx context.Context, client *modbus.Client, unit byte, addr uint16) error) {
+	unit, _ := cmd.Flags().GetUint8("unit")
+	addr, _ := cmd.Flags().GetUint16("addr")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	retries, _ := cmd.Flags().GetInt("retries")
+
+	opts, _, err := portOptions(cmd)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	port, err := serial.Open(portPath, opts...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening port: %v\n", err)
+		os.Exit(1)
+	}
+	defer port.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	client := modbus.New(port, modbus.WithTimeout(timeout), modbus.WithRetries(retries))
+	err = request(ctx, client, unit, addr)
+	if err == nil {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	var exc *modbus.Exception
+	if errors.As(err, &exc) || errors.Is(err, serial.ErrReadTimeout) {
+		os.Exit(2)
+	}
+	os.Exit(1)
+}
+
+// modbusResult is the --json output of the read subcommands
+type modbusResult struct {
+	Unit     byte   `json:"unit"`
+	Function string `json:"function"`
+	Address  uint16 `json:"address"`
+	Values   any    `json:"values"`
+}
+
+// readModbus reads count values with fn and prints them
+func readModbus(ctx context.Context, client *modbus.Client, fn modbus.FunctionCode, unit byte, addr, count uint16, jsonOutput bool) error {
+	var bits []bool
+	var regs []uint16
+	var err error
+	switch fn {
+	case modbus.ReadCoils:
+		bits, err = client.ReadCoils(ctx, unit, addr, count)
+	case modbus.ReadDiscreteInputs:
+		bits, err = client.ReadDiscreteInputs(ctx, unit, addr, count)
+	case modbus.ReadHoldingRegisters:
+		regs, err = client.ReadHoldingRegisters(ctx, unit, addr, count)
+	case modbus.ReadInputRegisters:
+		regs, err = client.ReadInputRegisters(ctx, unit, addr, count)
+	}
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		result := modbusResult{Unit: unit, Function: fn.String(), Address: addr, Values: regs}
+		if bits != nil {
+			result.Values = bits
+		}
+		return json.NewEncoder(os.Stdout).Encode(result)
+	}
+
+	headerStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("99"))
+
+	if bits != nil {
+		fmt.Println(headerStyle.Render(fmt.Sprintf("%-8s %s", "Address", "Value")))
+		for i, on := range bits {
+			value := "off"
+			if on {
+				value = "on"
+			}
+			fmt.Printf("%-8d %s\n", int(addr)+i, value)
+		}
+		return nil
+	}
+	fmt.Println(headerStyle.Render(fmt.Sprintf("%-8s %-6s %8s %7s", "Address", "Hex", "Unsigned", "Signed")))
+	for i, r := range regs {
+		fmt.Printf("%-8d 0x%04X %8d %7d\n", int(addr)+i, r, r, int16(r))
+	}
+	return nil
+}
+
+// parseRegisterValue reads a register value: decimal, 0x hex or a negative
+// number stored as two's complement
+func parseRegisterValue(s string) (uint16, error) {
+	v, err := strconv.ParseInt(s, 0, 32)
+	if err != nil || v < -32768 || v > 65535 {
+		return 0, fmt.Errorf("invalid register value %q (want -32768 to 65535)", s)
+	}
+	return uint16(v), nil
+}
+
+// printModbusWrite confirms a write of n values of kind at addr
+func printModbusWrite(n int, kind string, addr uint16, unit byte) {
+	successStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("40")).
+		Bold(true)
+
+	if n != 1 {
+		kind += "s"
+	}
+	target := fmt.Sprintf("unit %d", unit)
+	if unit == modbus.BroadcastUnit {
+		target = "all units (broadcast)"
+	}
+	fmt.Printf("%s Wrote %d %s at %d on %s\n", successStyle.Render("✓"), n, kind, addr, target)
+}
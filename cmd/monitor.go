@@ -5,10 +5,15 @@ package cmd
 
 import (
 	"context"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -17,10 +22,20 @@ import (
 )
 
 var (
-	monitorSignals []string
-	monitorTimeout time.Duration
+	monitorSignals  []string
+	monitorTimeout  time.Duration
+	monitorJSON     bool
+	monitorCSV      bool
+	monitorStats    bool
+	monitorOutputs  bool
+	monitorActivity bool
+	monitorRecord   string
 )
 
+// monitorPollInterval is how often monitor polls what it cannot wait on:
+// the RTS/DTR outputs and the driver's RX/TX counters
+const monitorPollInterval = 100 * time.Millisecond
+
 // monitorCmd represents the monitor command
 var monitorCmd = &cobra.Command{
 	Use:   "monitor <port>",
@@ -33,18 +48,74 @@ Examples:
   serial monitor /dev/ttyUSB0
   serial monitor /dev/ttyUSB0 --signals cts,dsr
   serial monitor /dev/ttyUSB0 --signals dcd --timeout 30s
+  serial monitor /dev/ttyUSB0 --signals cts,dsr --csv > handshake.csv
+  serial monitor /dev/ttyUSB0 --json | jq 'select(.signal == "CTS")'
+  serial monitor /dev/ttyUSB0 --signals cts --stats
+  serial monitor /dev/ttyUSB0 --signals cts --outputs --activity
+  serial monitor /dev/ttyUSB0 --outputs --record handshake.srec
+
+Available signals: cts, dsr, ri, dcd
+
+--outputs also shows the RTS and DTR lines this host drives, refreshed when
+they change, such as by serial rts or serial dtr in another terminal or by
+the driver's hardware flow control. They are recorded and counted in
+--stats like the inputs.
+
+--activity prints a tick with the bytes received and transmitted whenever
+the UART moved data, from the driver's counters, without reading the port.
+PTYs and some USB adapters keep no counters; the monitor then runs without.
+Ticks are not recorded with --json or --csv.
+
+--record writes each signal state the monitor sees to a session file, the
+format of --session on capture, bridge and mux, so signal recordings can be
+analysed or replayed next to data captures. The session holds complete
+states, so a pulse too short to see a level change is not in it; with
+--outputs, RTS/DTR changes are recorded too.
 
-Available signals: cts, dsr, ri, dcd`,
-	Args: cobra.ExactArgs(1),
+--stats prints edge statistics when the monitor stops: per signal the rising
+and falling edges, the min/mean/max time it stayed high and low between
+edges, and its duty cycle (the share of the time it was high). They go to
+stderr with --json or --csv.
+
+--json and --csv print one record per signal change instead, with the time
+in microseconds, the signal, its old and new state (HIGH or LOW) and how
+long in microseconds the old state held. The initial states come first,
+without an old state; timeouts are not recorded.`,
+	Args: portArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		args = withProfilePort(args, 1)
 		portPath := args[0]
 
-		port, err := serial.Open(portPath)
+		if monitorJSON && monitorCSV {
+			fmt.Fprintf(os.Stderr, "Error: --json and --csv cannot be combined\n")
+			os.Exit(1)
+		}
+		var records *signalRecorder
+		switch {
+		case monitorJSON:
+			records = newSignalRecorder(os.Stdout, "json")
+		case monitorCSV:
+			records = newSignalRecorder(os.Stdout, "csv")
+		}
+
+		sess, err := createSession(monitorRecord)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := sess.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing session: %v\n", err)
+			}
+		}()
+
+		opened, err := serial.Open(portPath, withDiagnostics())
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error opening port: %v\n", err)
 			os.Exit(1)
 		}
-		defer port.Close()
+		defer opened.Close()
+		port := sess.record(opened)
 
 		// Parse signal mask from flags
 		mask, err := parseSignalMask(monitorSignals)
@@ -61,12 +132,16 @@ Available signals: cts, dsr, ri, dcd`,
 		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 		go func() {
 			<-sigChan
-			fmt.Println("\nStopping monitor...")
+			if records == nil {
+				fmt.Println("\nStopping monitor...")
+			}
 			cancel()
 		}()
 
-		fmt.Printf("Monitoring signals on %s (signals: %s)\n", portPath, strings.Join(monitorSignals, ", "))
-		fmt.Println("Press Ctrl+C to stop")
+		if records == nil {
+			fmt.Printf("Monitoring signals on %s (signals: %s)\n", portPath, strings.Join(monitorSignals, ", "))
+			fmt.Println("Press Ctrl+C to stop")
+		}
 
 		// Show initial state
 		initialSignals, err := port.GetModemSignals()
@@ -74,7 +149,56 @@ Available signals: cts, dsr, ri, dcd`,
 			fmt.Fprintf(os.Stderr, "Error reading initial signals: %v\n", err)
 			os.Exit(1)
 		}
-		printSignalState("Initial", initialSignals, mask)
+		start := time.Now()
+		initial := monitoredSignals(initialSignals, mask)
+		if monitorOutputs {
+			initial = append(initial, outputSignals(initialSignals, initialSignals, true)...)
+		}
+		if records != nil {
+			records.initial(start, initial)
+		} else {
+			printSignalState("Initial", initial)
+		}
+		var stats *signalStats
+		if monitorStats {
+			stats = newSignalStats(start, initial)
+			defer func() {
+				out := os.Stdout
+				if records != nil {
+					out = os.Stderr
+				}
+				stats.print(out, time.Now())
+			}()
+		}
+
+		// Changes arrive from the wait loop and the poller
+		var mu sync.Mutex
+		report := func(title string, changed []namedSignal) {
+			mu.Lock()
+			defer mu.Unlock()
+			at := time.Now()
+			if stats != nil {
+				stats.change(at, changed)
+			}
+			if records != nil {
+				records.change(at, changed)
+				return
+			}
+			printSignalChange(title, changed)
+		}
+
+		if monitorOutputs || monitorActivity {
+			var counts *serial.LineCounts
+			if monitorActivity && records == nil {
+				c, err := serial.GetLineCounts(port)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: no RX/TX activity: %v\n", err)
+				} else {
+					counts = &c
+				}
+			}
+			go pollMonitorOutputs(ctx, port, initialSignals, counts, report, &mu)
+		}
 
 		// Monitor loop
 		for {
@@ -94,6 +218,9 @@ Available signals: cts, dsr, ri, dcd`,
 					return
 				}
 				if err == serial.ErrSignalTimeout || err == context.DeadlineExceeded {
+					if records != nil {
+						continue
+					}
 					fmt.Printf("[%s] Timeout - no signal changes\n", time.Now().Format("15:04:05"))
 					continue
 				}
@@ -101,11 +228,49 @@ Available signals: cts, dsr, ri, dcd`,
 				os.Exit(1)
 			}
 
-			printSignalChange(signals, changed)
+			report("Signal", monitoredSignals(signals, changed))
 		}
 	},
 }
 
+// pollMonitorOutputs reports RTS/DTR changes from last on, and with counts
+// prints activity ticks, until ctx is done. Ticks print under mu so they
+// don't interleave with changes.
+func pollMonitorOutputs(ctx context.Context, port serial.Port, last serial.ModemSignals, counts *serial.LineCounts, report func(string, []namedSignal), mu *sync.Mutex) {
+	ticker := time.NewTicker(monitorPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if monitorOutputs {
+			if signals, err := port.GetModemSignals(); err == nil {
+				if changed := outputSignals(signals, last, false); len(changed) > 0 {
+					report("Output", changed)
+				}
+				last = signals
+			}
+		}
+
+		if counts != nil {
+			c, err := serial.GetLineCounts(port)
+			if err != nil {
+				continue
+			}
+			rx, tx := c.RX-counts.RX, c.TX-counts.TX
+			*counts = c
+			if rx > 0 || tx > 0 {
+				mu.Lock()
+				fmt.Printf("[%s] Activity: RX +%d B, TX +%d B\n", time.Now().Format("15:04:05"), rx, tx)
+				mu.Unlock()
+			}
+		}
+	}
+}
+
 func parseSignalMask(signalNames []string) (serial.SignalMask, error) {
 	if len(signalNames) == 0 {
 		return serial.SignalCTS | serial.SignalDSR | serial.SignalRI | serial.SignalDCD, nil
@@ -129,40 +294,136 @@ func parseSignalMask(signalNames []string) (serial.SignalMask, error) {
 	return mask, nil
 }
 
-func printSignalState(prefix string, signals serial.ModemSignals, mask serial.SignalMask) {
+func printSignalState(prefix string, states []namedSignal) {
 	timestamp := time.Now().Format("15:04:05")
 	fmt.Printf("[%s] %s state:\n", timestamp, prefix)
+	printSignalLines(states)
+}
+
+func printSignalChange(kind string, changed []namedSignal) {
+	timestamp := time.Now().Format("15:04:05")
+	fmt.Printf("[%s] %s change detected:\n", timestamp, kind)
+	printSignalLines(changed)
+}
+
+func printSignalLines(states []namedSignal) {
+	for _, s := range states {
+		fmt.Printf("  %-4s %s\n", s.name+":", formatSignalState(s.high))
+	}
+	fmt.Println()
+}
+
+// signalRecord is one signal change of monitor --json and --csv output
+type signalRecord struct {
+	TS     string `json:"ts"`
+	Signal string `json:"signal"`
+	Old    string `json:"old,omitempty"`
+	New    string `json:"new"`
+	HeldUS int64  `json:"held_us,omitempty"`
+}
+
+// signalState is the last known state of a signal and when it was seen
+type signalState struct {
+	high  bool
+	since time.Time
+}
+
+// signalRecorder prints signal changes as JSON lines or CSV rows,
+// remembering each signal's state to report the old one
+type signalRecorder struct {
+	enc   *json.Encoder
+	csv   *csv.Writer
+	state map[string]signalState
+}
+
+func newSignalRecorder(w io.Writer, format string) *signalRecorder {
+	r := &signalRecorder{state: make(map[string]signalState)}
+	if format == "csv" {
+		r.csv = csv.NewWriter(w)
+	} else {
+		r.enc = json.NewEncoder(w)
+	}
+	return r
+}
+
+// namedSignal is the state of one modem signal
+type namedSignal struct {
+	name string
+	high bool
+}
+
+// monitoredSignals lists the signals of the mask with their states
+func monitoredSignals(signals serial.ModemSignals, mask serial.SignalMask) []namedSignal {
+	var out []namedSignal
 	if mask&serial.SignalCTS != 0 {
-		fmt.Printf("  CTS: %s\n", formatSignalState(signals.CTS))
+		out = append(out, namedSignal{"CTS", signals.CTS})
 	}
 	if mask&serial.SignalDSR != 0 {
-		fmt.Printf("  DSR: %s\n", formatSignalState(signals.DSR))
+		out = append(out, namedSignal{"DSR", signals.DSR})
 	}
 	if mask&serial.SignalRI != 0 {
-		fmt.Printf("  RI:  %s\n", formatSignalState(signals.RI))
+		out = append(out, namedSignal{"RI", signals.RI})
 	}
 	if mask&serial.SignalDCD != 0 {
-		fmt.Printf("  DCD: %s\n", formatSignalState(signals.DCD))
+		out = append(out, namedSignal{"DCD", signals.DCD})
 	}
-	fmt.Println()
+	return out
 }
 
-func printSignalChange(signals serial.ModemSignals, changed serial.SignalMask) {
-	timestamp := time.Now().Format("15:04:05")
-	fmt.Printf("[%s] Signal change detected:\n", timestamp)
-	if changed&serial.SignalCTS != 0 {
-		fmt.Printf("  CTS: %s\n", formatSignalState(signals.CTS))
+// outputSignals lists the RTS and DTR states that differ from last, or both
+// with all set
+func outputSignals(signals, last serial.ModemSignals, all bool) []namedSignal {
+	var out []namedSignal
+	if all || signals.RTS != last.RTS {
+		out = append(out, namedSignal{"RTS", signals.RTS})
 	}
-	if changed&serial.SignalDSR != 0 {
-		fmt.Printf("  DSR: %s\n", formatSignalState(signals.DSR))
+	if all || signals.DTR != last.DTR {
+		out = append(out, namedSignal{"DTR", signals.DTR})
 	}
-	if changed&serial.SignalRI != 0 {
-		fmt.Printf("  RI:  %s\n", formatSignalState(signals.RI))
+	return out
+}
+
+// initial records the states the monitor starts from, after the CSV header
+func (r *signalRecorder) initial(at time.Time, states []namedSignal) {
+	if r.csv != nil {
+		r.csv.Write([]string{"ts", "signal", "old", "new", "held_us"})
 	}
-	if changed&serial.SignalDCD != 0 {
-		fmt.Printf("  DCD: %s\n", formatSignalState(signals.DCD))
+	for _, s := range states {
+		r.state[s.name] = signalState{high: s.high, since: at}
+		r.write(signalRecord{TS: formatMicros(at), Signal: s.name, New: formatSignalState(s.high)})
 	}
-	fmt.Println()
+}
+
+// change records the signals in changed; a signal that pulsed and came back
+// has the same old and new state
+func (r *signalRecorder) change(at time.Time, changed []namedSignal) {
+	for _, s := range changed {
+		rec := signalRecord{TS: formatMicros(at), Signal: s.name, New: formatSignalState(s.high)}
+		if old, ok := r.state[s.name]; ok {
+			rec.Old = formatSignalState(old.high)
+			rec.HeldUS = at.Sub(old.since).Microseconds()
+		}
+		r.state[s.name] = signalState{high: s.high, since: at}
+		r.write(rec)
+	}
+}
+
+func (r *signalRecorder) write(rec signalRecord) {
+	if r.enc != nil {
+		r.enc.Encode(rec)
+		return
+	}
+	held := ""
+	if rec.Old != "" {
+		held = strconv.FormatInt(rec.HeldUS, 10)
+	}
+	r.csv.Write([]string{rec.TS, rec.Signal, rec.Old, rec.New, held})
+	r.csv.Flush()
+}
+
+// formatMicros renders a time in RFC 3339 with microseconds
+func formatMicros(t time.Time) string {
+	return t.Format("2006-01-02T15:04:05.000000Z07:00")
 }
 
 func init() {
@@ -172,4 +433,16 @@ func init() {
 		"Signals to monitor (comma-separated: cts,dsr,ri,dcd)")
 	monitorCmd.Flags().DurationVarP(&monitorTimeout, "timeout", "t", 0,
 		"Timeout for each wait operation (0 = no timeout)")
+	monitorCmd.Flags().BoolVar(&monitorJSON, "json", false,
+		"Print one JSON object per signal change, with microsecond timestamps")
+	monitorCmd.Flags().BoolVar(&monitorCSV, "csv", false,
+		"Print one CSV row per signal change, with microsecond timestamps")
+	monitorCmd.Flags().BoolVar(&monitorStats, "stats", false,
+		"Print edge counts, high/low period min/mean/max and duty cycle per signal on exit")
+	monitorCmd.Flags().BoolVar(&monitorOutputs, "outputs", false,
+		"Also show the RTS and DTR outputs, refreshed when they change")
+	monitorCmd.Flags().BoolVar(&monitorActivity, "activity", false,
+		"Print RX/TX activity ticks from the driver's byte counters")
+	monitorCmd.Flags().StringVar(&monitorRecord, "record", "",
+		"Record signal transitions to a session file")
 }
@@ -0,0 +1,137 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// periodStats summarizes the lengths of the periods a signal held one level
+type periodStats struct {
+	n        int
+	min, max time.Duration
+	total    time.Duration
+}
+
+func (p *periodStats) add(d time.Duration) {
+	if p.n == 0 || d < p.min {
+		p.min = d
+	}
+	p.max = max(p.max, d)
+	p.total += d
+	p.n++
+}
+
+// String renders min / mean / max, or "-" without a complete period
+func (p periodStats) String() string {
+	if p.n == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%s / %s / %s", formatPeriod(p.min), formatPeriod(p.total/time.Duration(p.n)), formatPeriod(p.max))
+}
+
+// edgeStats accumulates the edges of one signal. Only periods between two
+// seen edges count towards the high and low periods, as the first one began
+// before monitoring; all the time monitored counts towards the duty cycle.
+type edgeStats struct {
+	name      string
+	high      bool
+	since     time.Time // When the signal took its current level
+	edged     bool      // An edge has been seen, so since is an edge
+	rising    int
+	falling   int
+	pulses    int // Changes reported without a level change: a pulse too short to see
+	highTime  time.Duration
+	lowTime   time.Duration
+	highSpans periodStats
+	lowSpans  periodStats
+}
+
+// signalStats accumulates edge timing statistics for monitor --stats
+type signalStats struct {
+	start   time.Time
+	signals []*edgeStats
+}
+
+func newSignalStats(at time.Time, states []namedSignal) *signalStats {
+	st := &signalStats{start: at}
+	for _, s := range states {
+		st.signals = append(st.signals, &edgeStats{name: s.name, high: s.high, since: at})
+	}
+	return st
+}
+
+// change accounts the signals in changed at their new states
+func (st *signalStats) change(at time.Time, changed []namedSignal) {
+	for _, s := range changed {
+		for _, e := range st.signals {
+			if e.name == s.name {
+				e.change(at, s.high)
+			}
+		}
+	}
+}
+
+func (e *edgeStats) change(at time.Time, high bool) {
+	if high == e.high {
+		e.pulses++
+		return
+	}
+	held := at.Sub(e.since)
+	if e.high {
+		e.highTime += held
+		e.falling++
+		if e.edged {
+			e.highSpans.add(held)
+		}
+	} else {
+		e.lowTime += held
+		e.rising++
+		if e.edged {
+			e.lowSpans.add(held)
+		}
+	}
+	e.high, e.since, e.edged = high, at, true
+}
+
+// print writes a summary of the statistics up to end
+func (st *signalStats) print(w io.Writer, end time.Time) {
+	fmt.Fprintf(w, "Edge statistics over %s:\n", end.Sub(st.start).Round(time.Millisecond))
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  Signal\tEdges (rise/fall)\tHigh min / mean / max\tLow min / mean / max\tDuty")
+	for _, e := range st.signals {
+		highTime, lowTime := e.highTime, e.lowTime
+		if e.high {
+			highTime += end.Sub(e.since)
+		} else {
+			lowTime += end.Sub(e.since)
+		}
+		duty := "-"
+		if total := highTime + lowTime; total > 0 {
+			duty = fmt.Sprintf("%.1f%%", 100*float64(highTime)/float64(total))
+		}
+		fmt.Fprintf(tw, "  %s\t%d (%d/%d)\t%s\t%s\t%s\n", e.name, e.rising+e.falling, e.rising, e.falling, e.highSpans, e.lowSpans, duty)
+	}
+	tw.Flush()
+	for _, e := range st.signals {
+		if e.pulses > 0 {
+			fmt.Fprintf(w, "  %s: %d pulses too short to time\n", e.name, e.pulses)
+		}
+	}
+}
+
+// formatPeriod renders a duration with a precision suited to its size
+func formatPeriod(d time.Duration) string {
+	switch {
+	case d < time.Millisecond:
+		return d.Round(time.Microsecond).String()
+	case d < time.Second:
+		return d.Round(10 * time.Microsecond).String()
+	default:
+		return d.Round(time.Millisecond).String()
+	}
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPeriodStats(t *testing.T) {
+	var p periodStats
+	if got := p.String(); got != "-" {
+		t.Errorf("empty String() = %q, want -", got)
+	}
+	for _, d := range []time.Duration{3 * time.Millisecond, time.Millisecond, 5 * time.Millisecond} {
+		p.add(d)
+	}
+	if p.n != 3 || p.min != time.Millisecond || p.max != 5*time.Millisecond || p.total != 9*time.Millisecond {
+		t.Errorf("stats = %+v, want 3 periods of 1ms to 5ms totalling 9ms", p)
+	}
+	if got, want := p.String(), "1ms / 3ms / 5ms"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestEdgeStatsWindows(t *testing.T) {
+	start := time.Now()
+	at := func(ms float64) time.Time { return start.Add(time.Duration(ms * float64(time.Millisecond))) }
+
+	// CTS starts low; the low period before the first edge began before
+	// monitoring, so only the windows between edges are timed
+	st := newSignalStats(start, []namedSignal{{name: "CTS"}, {name: "DSR", high: true}})
+	st.change(at(10), []namedSignal{{name: "CTS", high: true}})
+	st.change(at(10.5), []namedSignal{{name: "CTS"}})
+	st.change(at(20), []namedSignal{{name: "CTS", high: true}})
+	st.change(at(20.25), []namedSignal{{name: "CTS"}})
+	st.change(at(30), []namedSignal{{name: "CTS"}}) // No level change: a pulse
+
+	cts, dsr := st.signals[0], st.signals[1]
+	if cts.rising != 2 || cts.falling != 2 || cts.pulses != 1 {
+		t.Errorf("CTS edges = %d rising, %d falling, %d pulses, want 2, 2, 1", cts.rising, cts.falling, cts.pulses)
+	}
+	if cts.highSpans.n != 2 || cts.highSpans.min != 250*time.Microsecond || cts.highSpans.max != 500*time.Microsecond {
+		t.Errorf("CTS high spans = %+v, want 250us and 500us", cts.highSpans)
+	}
+	if cts.lowSpans.n != 1 || cts.lowSpans.min != 9500*time.Microsecond {
+		t.Errorf("CTS low spans = %+v, want one of 9.5ms", cts.lowSpans)
+	}
+	if cts.highTime != 750*time.Microsecond || cts.lowTime != 19500*time.Microsecond {
+		t.Errorf("CTS high/low time = %v/%v, want 750us/19.5ms", cts.highTime, cts.lowTime)
+	}
+	if dsr.rising+dsr.falling+dsr.pulses != 0 {
+		t.Errorf("DSR saw changes meant for CTS: %+v", dsr)
+	}
+}
+
+func TestSignalStatsPrint(t *testing.T) {
+	start := time.Now()
+	st := newSignalStats(start, []namedSignal{{name: "CTS"}, {name: "DSR", high: true}})
+	st.change(start.Add(25*time.Millisecond), []namedSignal{{name: "CTS", high: true}})
+	st.change(start.Add(50*time.Millisecond), []namedSignal{{name: "CTS"}})
+	st.change(start.Add(60*time.Millisecond), []namedSignal{{name: "CTS"}})
+
+	// The period still open at the end counts towards the duty cycle
+	var buf bytes.Buffer
+	st.print(&buf, start.Add(100*time.Millisecond))
+	out := buf.String()
+
+	for _, want := range []string{
+		"Edge statistics over 100ms:",
+		"CTS     2 (1/1)",
+		"25ms / 25ms / 25ms",
+		"25.0%",
+		"DSR     0 (0/0)",
+		"100.0%",
+		"CTS: 1 pulses too short to time",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("summary missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "DSR: ") {
+		t.Errorf("summary reports pulses for DSR:\n%s", out)
+	}
+}
+
+func TestFormatPeriod(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{488*time.Microsecond + 400*time.Nanosecond, "488µs"},
+		{12*time.Millisecond + 345*time.Microsecond, "12.35ms"},
+		{2*time.Second + 345600*time.Microsecond, "2.346s"},
+	}
+
+	for _, tt := range tests {
+		if got := formatPeriod(tt.d); got != tt.want {
+			t.Errorf("formatPeriod(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/allbin/go-serial"
+	"github.com/allbin/go-serial/framing"
+	"github.com/allbin/go-serial/mqtt"
+	"github.com/spf13/cobra"
+)
+
+// mqttCmd represents the mqtt command
+var mqttCmd = &cobra.Command{
+	Use:   "mqtt <port>",
+	Short: "Publish serial frames to MQTT and write commands back",
+	Long: `Connect a serial port to an MQTT broker.
+
+Every frame received on the port is published to --topic. Messages arriving
+on --command-topic are written to the port, framed the same way.
+
+Framing:
+  line  Frames end with --delimiter (default "\n"); commands get it appended
+  idle  Frames end after --idle-gap of silence; commands are written as-is
+
+Payload encodings:
+  raw   Frame bytes unchanged
+  hex   Hex string (commands may contain spaces)
+  json  {"time": ..., "hex": ..., "text": ...}; commands use "hex" or "text"
+
+Example usage:
+  serial mqtt /dev/ttyUSB0 --broker localhost:1883 --topic site/meter/rx
+  serial mqtt /dev/ttyUSB0 --broker broker:1883 --topic gps/raw --qos 1 --retain
+  serial mqtt /dev/ttyUSB0 --broker broker:1883 --topic dev/rx --command-topic dev/tx --encoding json
+  serial mqtt /dev/ttyUSB0 --broker broker:1883 --topic modbus/rx --framing idle --idle-gap 5ms --encoding hex`,
+	Args: portArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		args = withProfilePort(args, 1)
+		portPath := args[0]
+
+		// Get flags
+		baudRate, _ := cmd.Flags().GetInt("baud")
+		broker, _ := cmd.Flags().GetString("broker")
+		topic, _ := cmd.Flags().GetString("topic")
+		commandTopic, _ := cmd.Flags().GetString("command-topic")
+		qos, _ := cmd.Flags().GetInt("qos")
+		retain, _ := cmd.Flags().GetBool("retain")
+		encodingName, _ := cmd.Flags().GetString("encoding")
+		clientID, _ := cmd.Flags().GetString("client-id")
+		username, _ := cmd.Flags().GetString("username")
+		password, _ := cmd.Flags().GetString("password")
+		framingMode, _ := cmd.Flags().GetString("framing")
+		delimiter, _ := cmd.Flags().GetString("delimiter")
+		idleGap, _ := cmd.Flags().GetDuration("idle-gap")
+
+		if topic == "" {
+			fmt.Fprintf(os.Stderr, "Error: --topic is required\n")
+			os.Exit(1)
+		}
+		if qos != 0 && qos != 1 {
+			fmt.Fprintf(os.Stderr, "Error: --qos must be 0 or 1\n")
+			os.Exit(1)
+		}
+
+		encoding, err := mqtt.ParseEncoding(encodingName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		var codec framing.Codec
+		switch strings.ToLower(framingMode) {
+		case "line":
+			delim, err := strconv.Unquote(`"` + delimiter + `"`)
+			if err != nil || delim == "" {
+				fmt.Fprintf(os.Stderr, "Error: invalid delimiter %q\n", delimiter)
+				os.Exit(1)
+			}
+			codec = framing.NewDelimited([]byte(delim))
+		case "idle":
+			codec = framing.NewIdleGap(idleGap)
+		default:
+			fmt.Fprintf(os.Stderr, "Error: unknown framing %q (want line or idle)\n", framingMode)
+			os.Exit(1)
+		}
+
+		clientOpts := []mqtt.Option{}
+		if clientID != "" {
+			clientOpts = append(clientOpts, mqtt.WithClientID(clientID))
+		}
+		if username != "" {
+			clientOpts = append(clientOpts, mqtt.WithCredentials(username, password))
+		}
+
+		gatewayOpts := []mqtt.GatewayOption{
+			mqtt.WithQoS(mqtt.QoS(qos)),
+			mqtt.WithRetain(retain),
+			mqtt.WithEncoding(encoding),
+			mqtt.WithErrorHandler(func(err error) {
+				fmt.Fprintf(os.Stderr, "[%s] %v\n", time.Now().Format("15:04:05"), err)
+			}),
+		}
+		if commandTopic != "" {
+			gatewayOpts = append(gatewayOpts, mqtt.WithCommandTopic(commandTopic))
+		}
+
+		// A short read timeout lets idle-gap framing close frames promptly
+		portOpts := []serial.Option{
+			serial.WithBaudRate(baudRate),
+			withDiagnostics(),
+		}
+
+		lineOpts, err := lineOptions(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		portOpts = append(portOpts, lineOpts...)
+
+		if err := runMQTT(portPath, broker, topic, codec, portOpts, clientOpts, gatewayOpts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mqttCmd)
+
+	mqttCmd.Flags().IntP("baud", "b", 115200, "Baud rate")
+	addLineFlags(mqttCmd, 100*time.Millisecond)
+	mqttCmd.Flags().String("broker", "localhost:1883", "MQTT broker address (host:port)")
+	mqttCmd.Flags().StringP("topic", "t", "", "Topic for received frames (required)")
+	mqttCmd.Flags().String("command-topic", "", "Topic whose messages are written to the port")
+	mqttCmd.Flags().Int("qos", 0, "MQTT QoS: 0 or 1")
+	mqttCmd.Flags().Bool("retain", false, "Publish frames with the retain flag")
+	mqttCmd.Flags().StringP("encoding", "e", "raw", "Payload encoding: raw, hex, json")
+	mqttCmd.Flags().String("client-id", "", "MQTT client ID (default: random)")
+	mqttCmd.Flags().String("username", "", "MQTT user name")
+	mqttCmd.Flags().String("password", "", "MQTT password")
+	mqttCmd.Flags().String("framing", "line", "Frame detection: line, idle")
+	mqttCmd.Flags().String("delimiter", `\n`, "Line framing delimiter (escapes like \\r\\n allowed)")
+	mqttCmd.Flags().Duration("idle-gap", 20*time.Millisecond, "Silence that ends a frame with idle framing")
+}
+
+func runMQTT(portPath, broker, topic string, codec framing.Codec, portOpts []serial.Option, clientOpts []mqtt.Option, gatewayOpts []mqtt.GatewayOption) error {
+	// Setup signal handling for clean shutdown
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	port, err := serial.Open(portPath, portOpts...)
+	if err != nil {
+		return fmt.Errorf("failed to open port: %w", err)
+	}
+	defer port.Close()
+
+	client, err := mqtt.Dial(ctx, broker, clientOpts...)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	gw := mqtt.NewGateway(client, framing.NewPacketPort(port, codec), topic, gatewayOpts...)
+
+	fmt.Fprintf(os.Stderr, "Publishing frames from %s to %s on %s\n", portPath, topic, broker)
+	fmt.Fprintf(os.Stderr, "Press Ctrl+C to stop\n\n")
+
+	err = gw.Run(ctx)
+	fmt.Fprintf(os.Stderr, "\nPublished %d frames, wrote %d commands\n", gw.Published(), gw.Commands())
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
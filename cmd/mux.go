@@ -0,0 +1,194 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/allbin/go-serial"
+	"github.com/allbin/go-serial/mux"
+	"github.com/spf13/cobra"
+)
+
+// muxCmd represents the mux command
+var muxCmd = &cobra.Command{
+	Use:   "mux <port>",
+	Short: "Share one serial port among several TCP clients",
+	Long: `Share a serial port among any number of TCP clients.
+
+Every client receives all data read from the port. The transmit policy
+decides who may write; input from other clients is discarded.
+
+Transmit policies:
+  first  The longest-connected client transmits (default)
+  claim  The first client to send data transmits until it disconnects
+  all    Every client transmits
+
+With --console the received data is also printed to stdout. Per-client
+statistics are printed every --stats interval and on exit. --session
+records everything the device sent and the clients transmitted, plus modem
+signal changes, in the record/replay session format.
+
+Example usage:
+  serial mux /dev/ttyUSB0 --listen :5000
+  serial mux /dev/ttyUSB0 --listen :5000 --policy claim --console
+  serial mux /dev/ttyUSB0 --listen 127.0.0.1:5000 --stats 10s --baud 9600
+  serial mux /dev/ttyUSB0 --listen :5000 --session field-test.srec`,
+	Args: portArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		args = withProfilePort(args, 1)
+		portPath := args[0]
+
+		// Get flags
+		baudRate, _ := cmd.Flags().GetInt("baud")
+		flowControl, _ := cmd.Flags().GetString("flow-control")
+		initialRTS, _ := cmd.Flags().GetBool("initial-rts")
+		listenAddr, _ := cmd.Flags().GetString("listen")
+		sessionPath, _ := cmd.Flags().GetString("session")
+		policyName, _ := cmd.Flags().GetString("policy")
+		queue, _ := cmd.Flags().GetInt("queue")
+		console, _ := cmd.Flags().GetBool("console")
+		statsInterval, _ := cmd.Flags().GetDuration("stats")
+
+		policy, err := mux.ParsePolicy(strings.ToLower(policyName))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Configure port options; a short read timeout lets the mux stop promptly
+		opts := []serial.Option{
+			serial.WithBaudRate(baudRate),
+			withDiagnostics(),
+		}
+
+		lineOpts, err := lineOptions(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		opts = append(opts, lineOpts...)
+
+		switch strings.ToLower(flowControl) {
+		case "cts":
+			opts = append(opts, serial.WithFlowControl(serial.FlowControlCTS))
+			if initialRTS {
+				opts = append(opts, serial.WithInitialRTS(true))
+			}
+		case "rtscts":
+			opts = append(opts, serial.WithFlowControl(serial.FlowControlRTSCTS))
+			if initialRTS {
+				opts = append(opts, serial.WithInitialRTS(true))
+			}
+		}
+
+		muxOpts := []mux.Option{mux.WithPolicy(policy), mux.WithClientQueue(queue)}
+		if err := runMux(portPath, listenAddr, sessionPath, opts, muxOpts, console, statsInterval); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(muxCmd)
+
+	muxCmd.Flags().IntP("baud", "b", 115200, "Baud rate")
+	addLineFlags(muxCmd, 100*time.Millisecond)
+	muxCmd.Flags().StringP("flow-control", "f", "none", "Flow control: none, cts, rtscts")
+	muxCmd.Flags().Bool("initial-rts", false, "Assert RTS on port open")
+	muxCmd.Flags().StringP("listen", "l", ":5000", "Listen address for clients")
+	muxCmd.Flags().StringP("policy", "p", "first", "Transmit policy: first, claim, all")
+	muxCmd.Flags().Int("queue", mux.DefaultClientQueue, "Received chunks buffered per client before data is dropped")
+	muxCmd.Flags().Bool("console", false, "Also print received data to stdout")
+	muxCmd.Flags().String("session", "", "Record all RX/TX data and modem signal changes to a session file")
+	muxCmd.Flags().Duration("stats", 0, "Print per-client statistics at this interval (0 = on exit only)")
+}
+
+func runMux(portPath, listenAddr, sessionPath string, opts []serial.Option, muxOpts []mux.Option, console bool, statsInterval time.Duration) error {
+	// Setup signal handling for clean shutdown
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	port, err := serial.Open(portPath, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to open port: %w", err)
+	}
+	defer port.Close()
+
+	sess, err := createSession(sessionPath)
+	if err != nil {
+		return err
+	}
+	defer sess.Close()
+	port = sess.wrap(port)
+
+	l, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+
+	m := mux.New(port, muxOpts...)
+	defer printMuxStats(m)
+
+	if console {
+		c, err := m.Attach("console", mux.ReadOnly())
+		if err != nil {
+			return err
+		}
+		go io.Copy(os.Stdout, c)
+	}
+
+	if statsInterval > 0 {
+		go func() {
+			ticker := time.NewTicker(statsInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					printMuxStats(m)
+				case <-m.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- m.Serve(ctx, l) }()
+
+	fmt.Fprintf(os.Stderr, "Sharing %s on %s\n", portPath, l.Addr())
+	fmt.Fprintf(os.Stderr, "Press Ctrl+C to stop\n\n")
+
+	err = m.Run(ctx)
+	if serr := <-serveErr; serr != nil {
+		return serr
+	}
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+func printMuxStats(m *mux.Mux) {
+	clients := m.Clients()
+	fmt.Fprintf(os.Stderr, "\n[%s] %d client(s)\n", time.Now().Format("15:04:05"), len(clients))
+	for _, c := range clients {
+		role := "watcher"
+		if c.Writer {
+			role = "writer"
+		}
+		fmt.Fprintf(os.Stderr, "  #%d %-21s %-7s up %-8v RX %d TX %d dropped %d denied %d\n",
+			c.ID, c.Name, role, time.Since(c.Attached).Round(time.Second), c.RXBytes, c.TXBytes, c.Dropped, c.Denied)
+	}
+}
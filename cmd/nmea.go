@@ -0,0 +1,481 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/allbin/go-serial"
+	"github.com/allbin/go-serial/internal/tui/colors"
+	"github.com/allbin/go-serial/internal/tui/styles"
+	"github.com/allbin/go-serial/nmea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+)
+
+// nmeaCmd represents the nmea command
+var nmeaCmd = &cobra.Command{
+	Use:   "nmea <port>",
+	Short: "Show live GPS status from NMEA sentences",
+	Long: `Decode the NMEA 0183 sentences of a GPS/GNSS module and show its fix
+status, satellites, position and HDOP in a small dashboard, to sanity-check
+a module without setting up gpsd. Press q or Ctrl+C to quit.
+
+The dashboard is built from RMC, GGA, GSV and VTG sentences. Sentences
+failing their checksum are counted; a growing count usually means a wrong
+baud rate. Most modules talk at 9600 baud, the default here.
+
+--raw prints each checksum-valid sentence as received instead, and --json
+one JSON object per decoded sentence with its "ts", "talker", "type" and
+decoded fields, for scripts. --talkers keeps only the sentences of the
+given talker IDs, such as GP (GPS) or GN (multi-constellation).
+
+Example usage:
+  serial nmea /dev/ttyACM0
+  serial nmea /dev/ttyUSB0 --baud 4800
+  serial nmea /dev/ttyACM0 --raw --talkers GN
+  serial nmea /dev/ttyACM0 --json | jq 'select(.type == "GGA") | .hdop'`,
+	Args: portArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		args = withProfilePort(args, 1)
+		raw, _ := cmd.Flags().GetBool("raw")
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		talkers, _ := cmd.Flags().GetStringSlice("talkers")
+
+		if raw && jsonOutput {
+			fmt.Fprintln(os.Stderr, "Error: --raw and --json cannot be combined")
+			os.Exit(1)
+		}
+
+		opts, _, err := portOptions(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		port, err := serial.Open(args[0], opts...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening port: %v\n", err)
+			os.Exit(1)
+		}
+		defer port.Close()
+
+		var readerOpts []nmea.Option
+		if len(talkers) > 0 {
+			readerOpts = append(readerOpts, nmea.WithTalkers(talkers...))
+		}
+		if raw {
+			readerOpts = append(readerOpts, nmea.WithRawSentences())
+		}
+		reader := nmea.NewReader(port, readerOpts...)
+
+		if raw || jsonOutput {
+			err = printNMEA(reader, jsonOutput)
+		} else {
+			err = runNMEADashboard(reader, args[0])
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(nmeaCmd)
+
+	addPortFlags(nmeaCmd, 100*time.Millisecond)
+	// GPS modules default to 9600 baud rather than the usual 115200
+	baud := nmeaCmd.Flags().Lookup("baud")
+	baud.Value.Set("9600")
+	baud.DefValue = "9600"
+	nmeaCmd.Flags().Bool("raw", false, "Print the checksum-valid sentences as received")
+	nmeaCmd.Flags().Bool("json", false, "Print one JSON object per decoded sentence")
+	nmeaCmd.Flags().StringSlice("talkers", nil, "Only use sentences from these talker IDs (e.g. GP,GN)")
+}
+
+// printNMEA prints the sentences of reader as text or JSON until
+// interrupted or the port fails
+func printNMEA(reader *nmea.Reader, jsonOutput bool) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	encoder := json.NewEncoder(os.Stdout)
+	for msg := range reader.Messages(ctx) {
+		if !jsonOutput {
+			fmt.Println(msg.Base().Raw)
+			continue
+		}
+		if err := encoder.Encode(nmeaRecord(time.Now(), msg)); err != nil {
+			return err
+		}
+	}
+	if err := reader.Err(); err != nil && ctx.Err() == nil {
+		return err
+	}
+	return nil
+}
+
+// nmeaRecord returns the --json output for msg
+func nmeaRecord(ts time.Time, msg nmea.Message) map[string]any {
+	s := msg.Base()
+	record := map[string]any{"ts": ts, "talker": s.Talker, "type": s.Type}
+	switch m := msg.(type) {
+	case *nmea.RMC:
+		record["time"] = m.Time
+		record["valid"] = m.Valid
+		record["lat"] = m.Latitude
+		record["lon"] = m.Longitude
+		record["speed_knots"] = m.SpeedKnots
+		record["course"] = m.Course
+		record["magnetic_variation"] = m.MagneticVariation
+		record["mode"] = m.Mode
+	case *nmea.GGA:
+		record["time_of_day"] = formatTimeOfDay(m.TimeOfDay)
+		record["lat"] = m.Latitude
+		record["lon"] = m.Longitude
+		record["fix_quality"] = m.FixQuality
+		record["fix"] = ggaFixName(m.FixQuality)
+		record["satellites"] = m.Satellites
+		record["hdop"] = m.HDOP
+		record["altitude"] = m.Altitude
+		record["geoid_separation"] = m.GeoidSeparation
+	case *nmea.GSV:
+		record["message"] = m.MessageNumber
+		record["messages"] = m.TotalMessages
+		record["in_view"] = m.SatellitesInView
+		sats := make([]map[string]int, len(m.Satellites))
+		for i, sat := range m.Satellites {
+			sats[i] = map[string]int{"prn": sat.PRN, "elevation": sat.Elevation, "azimuth": sat.Azimuth, "snr": sat.SNR}
+		}
+		record["satellites"] = sats
+	case *nmea.VTG:
+		record["true_track"] = m.TrueTrack
+		record["magnetic_track"] = m.MagneticTrack
+		record["speed_knots"] = m.SpeedKnots
+		record["speed_kph"] = m.SpeedKPH
+		record["mode"] = m.Mode
+	default:
+		record["fields"] = s.Fields
+	}
+	return record
+}
+
+// ggaFixName names a GGA fix quality indicator
+func ggaFixName(quality int) string {
+	names := []string{"no fix", "GPS", "DGPS", "PPS", "RTK fixed", "RTK float", "estimated", "manual", "simulation"}
+	if quality < 0 || quality >= len(names) {
+		return fmt.Sprintf("quality %d", quality)
+	}
+	return names[quality]
+}
+
+// hdopRating rates an HDOP value on the usual DOP scale
+func hdopRating(hdop float64) string {
+	switch {
+	case hdop <= 0:
+		return ""
+	case hdop < 1:
+		return "ideal"
+	case hdop <= 2:
+		return "excellent"
+	case hdop <= 5:
+		return "good"
+	case hdop <= 10:
+		return "moderate"
+	case hdop <= 20:
+		return "fair"
+	default:
+		return "poor"
+	}
+}
+
+// formatTimeOfDay renders a time since midnight as hh:mm:ss
+func formatTimeOfDay(d time.Duration) string {
+	return time.Time{}.Add(d).Format("15:04:05")
+}
+
+// formatCoordinate renders decimal degrees with a hemisphere letter
+func formatCoordinate(deg float64, pos, neg string) string {
+	hemisphere := pos
+	if deg < 0 {
+		hemisphere = neg
+	}
+	return fmt.Sprintf("%.6f° %s", math.Abs(deg), hemisphere)
+}
+
+// nmeaMessageMsg carries a decoded sentence to the dashboard
+type nmeaMessageMsg struct {
+	msg nmea.Message
+}
+
+// nmeaDoneMsg reports that the sentence stream ended, with its error
+type nmeaDoneMsg struct {
+	err error
+}
+
+// nmeaTickMsg refreshes the dashboard's ages and counters
+type nmeaTickMsg time.Time
+
+// nmeaModel is the dashboard of the nmea command
+type nmeaModel struct {
+	portPath string
+	reader   *nmea.Reader
+
+	gga      *nmea.GGA
+	rmc      *nmea.RMC
+	vtg      *nmea.VTG
+	inView   map[string]int                  // Satellites in view per talker
+	sats     map[string][]nmea.SatelliteInfo // Last complete GSV cycle per talker
+	partial  map[string][]nmea.SatelliteInfo // GSV cycle being collected per talker
+	counts   map[string]int                  // Sentences seen per type
+	lastData time.Time
+	started  time.Time
+	now      time.Time
+	err      error
+	done     bool
+}
+
+func nmeaTick() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+		return nmeaTickMsg(t)
+	})
+}
+
+func (m *nmeaModel) Init() tea.Cmd {
+	return nmeaTick()
+}
+
+func (m *nmeaModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c", "esc":
+			return m, tea.Quit
+		}
+	case nmeaTickMsg:
+		m.now = time.Time(msg)
+		return m, nmeaTick()
+	case nmeaDoneMsg:
+		m.done = true
+		m.err = msg.err
+	case nmeaMessageMsg:
+		m.record(msg.msg)
+	}
+	return m, nil
+}
+
+// record updates the dashboard state with msg
+func (m *nmeaModel) record(msg nmea.Message) {
+	s := msg.Base()
+	m.counts[s.Type]++
+	m.lastData = time.Now()
+	m.now = m.lastData
+
+	switch msg := msg.(type) {
+	case *nmea.GGA:
+		m.gga = msg
+	case *nmea.RMC:
+		m.rmc = msg
+	case *nmea.VTG:
+		m.vtg = msg
+	case *nmea.GSV:
+		m.inView[s.Talker] = msg.SatellitesInView
+		if msg.MessageNumber == 1 {
+			m.partial[s.Talker] = nil
+		}
+		m.partial[s.Talker] = append(m.partial[s.Talker], msg.Satellites...)
+		if msg.MessageNumber == msg.TotalMessages {
+			m.sats[s.Talker] = m.partial[s.Talker]
+			delete(m.partial, s.Talker)
+		}
+	}
+}
+
+func (m *nmeaModel) View() string {
+	labelStyle := lipgloss.NewStyle().Foreground(colors.Subtext0).Width(12)
+	goodStyle := lipgloss.NewStyle().Foreground(colors.Green).Bold(true)
+	warnStyle := lipgloss.NewStyle().Foreground(colors.Yellow).Bold(true)
+	badStyle := lipgloss.NewStyle().Foreground(colors.Red).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(colors.Overlay1)
+
+	var b strings.Builder
+	row := func(label, value string) {
+		fmt.Fprintf(&b, "  %s %s\n", labelStyle.Render(label), value)
+	}
+	unknown := dimStyle.Render("—")
+
+	b.WriteString(styles.TitleStyle.Render("NMEA "+m.portPath) + "\n\n")
+
+	// Fix status: GGA knows the fix quality, RMC only whether it is valid
+	switch {
+	case m.gga != nil && m.gga.FixQuality > 0:
+		row("Fix", goodStyle.Render(ggaFixName(m.gga.FixQuality)))
+	case m.gga != nil:
+		row("Fix", badStyle.Render("no fix"))
+	case m.rmc != nil && m.rmc.Valid:
+		row("Fix", goodStyle.Render("valid"))
+	case m.rmc != nil:
+		row("Fix", badStyle.Render("no fix"))
+	default:
+		row("Fix", unknown)
+	}
+
+	inView := 0
+	for _, n := range m.inView {
+		inView += n
+	}
+	switch {
+	case m.gga != nil:
+		row("Satellites", fmt.Sprintf("%d used, %d in view", m.gga.Satellites, inView))
+	case len(m.inView) > 0:
+		row("Satellites", fmt.Sprintf("%d in view", inView))
+	default:
+		row("Satellites", unknown)
+	}
+
+	lat, lon, hasPosition := 0.0, 0.0, false
+	if m.gga != nil && m.gga.FixQuality > 0 {
+		lat, lon, hasPosition = m.gga.Latitude, m.gga.Longitude, true
+	} else if m.rmc != nil && m.rmc.Valid {
+		lat, lon, hasPosition = m.rmc.Latitude, m.rmc.Longitude, true
+	}
+	if hasPosition {
+		row("Position", formatCoordinate(lat, "N", "S")+"  "+formatCoordinate(lon, "E", "W"))
+	} else {
+		row("Position", unknown)
+	}
+	if m.gga != nil && m.gga.FixQuality > 0 {
+		row("Altitude", fmt.Sprintf("%.1f m", m.gga.Altitude))
+	} else {
+		row("Altitude", unknown)
+	}
+
+	if m.gga != nil && m.gga.HDOP > 0 {
+		rating := hdopRating(m.gga.HDOP)
+		style := goodStyle
+		if m.gga.HDOP > 5 {
+			style = warnStyle
+		}
+		if m.gga.HDOP > 10 {
+			style = badStyle
+		}
+		row("HDOP", fmt.Sprintf("%.1f %s", m.gga.HDOP, style.Render(rating)))
+	} else {
+		row("HDOP", unknown)
+	}
+
+	switch {
+	case m.vtg != nil:
+		row("Speed", fmt.Sprintf("%.1f km/h, course %.1f°", m.vtg.SpeedKPH, m.vtg.TrueTrack))
+	case m.rmc != nil && m.rmc.Valid:
+		row("Speed", fmt.Sprintf("%.1f km/h, course %.1f°", m.rmc.SpeedKnots*1.852, m.rmc.Course))
+	default:
+		row("Speed", unknown)
+	}
+
+	switch {
+	case m.rmc != nil && !m.rmc.Time.IsZero():
+		row("UTC", m.rmc.Time.Format("2006-01-02 15:04:05"))
+	case m.gga != nil:
+		row("UTC", formatTimeOfDay(m.gga.TimeOfDay))
+	default:
+		row("UTC", unknown)
+	}
+
+	// Satellites of the last complete GSV cycles, strongest first
+	var sats []nmea.SatelliteInfo
+	for _, talkerSats := range m.sats {
+		sats = append(sats, talkerSats...)
+	}
+	if len(sats) > 0 {
+		slices.SortFunc(sats, func(a, b nmea.SatelliteInfo) int { return b.SNR - a.SNR })
+		b.WriteString("\n")
+		for _, sat := range sats {
+			bar := strings.Repeat("█", sat.SNR/5)
+			style := goodStyle
+			switch {
+			case sat.SNR == 0:
+				style = dimStyle
+			case sat.SNR < 25:
+				style = warnStyle
+			}
+			fmt.Fprintf(&b, "  PRN %3d  el %2d° az %3d°  %2d dB %s\n", sat.PRN, sat.Elevation, sat.Azimuth, sat.SNR, style.Render(bar))
+		}
+	}
+
+	b.WriteString("\n")
+	types := make([]string, 0, len(m.counts))
+	for t := range m.counts {
+		types = append(types, t)
+	}
+	slices.Sort(types)
+	counts := make([]string, len(types))
+	for i, t := range types {
+		counts[i] = fmt.Sprintf("%s %d", t, m.counts[t])
+	}
+	if len(counts) == 0 {
+		counts = append(counts, "none yet")
+	}
+	status := "Sentences: " + strings.Join(counts, ", ")
+	if invalid := m.reader.Invalid(); invalid > 0 {
+		status += ", " + badStyle.Render(fmt.Sprintf("%d invalid", invalid))
+	}
+	b.WriteString("  " + dimStyle.Render(status) + "\n")
+
+	switch {
+	case m.done && m.err != nil:
+		b.WriteString("  " + badStyle.Render("Error: "+m.err.Error()) + "\n")
+	case m.done:
+		b.WriteString("  " + badStyle.Render("Port closed") + "\n")
+	case m.lastData.IsZero() && m.now.Sub(m.started) >= 3*time.Second:
+		b.WriteString("  " + warnStyle.Render(fmt.Sprintf("No sentences for %s; check the baud rate", m.now.Sub(m.started).Round(time.Second))) + "\n")
+	case !m.lastData.IsZero() && m.now.Sub(m.lastData) >= 3*time.Second:
+		b.WriteString("  " + warnStyle.Render(fmt.Sprintf("No data for %s", m.now.Sub(m.lastData).Round(time.Second))) + "\n")
+	}
+
+	b.WriteString("\n  " + dimStyle.Render("q quit") + "\n")
+	return b.String()
+}
+
+// runNMEADashboard shows the dashboard for the sentences of reader until
+// the user quits
+func runNMEADashboard(reader *nmea.Reader, portPath string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	now := time.Now()
+	m := &nmeaModel{
+		portPath: portPath,
+		reader:   reader,
+		inView:   make(map[string]int),
+		sats:     make(map[string][]nmea.SatelliteInfo),
+		partial:  make(map[string][]nmea.SatelliteInfo),
+		counts:   make(map[string]int),
+		started:  now,
+		now:      now,
+	}
+	p := tea.NewProgram(m, tea.WithAltScreen())
+
+	go func() {
+		for msg := range reader.Messages(ctx) {
+			p.Send(nmeaMessageMsg{msg: msg})
+		}
+		if ctx.Err() == nil {
+			p.Send(nmeaDoneMsg{err: reader.Err()})
+		}
+	}()
+
+	_, err := p.Run()
+	return err
+}
@@ -0,0 +1,69 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/allbin/go-serial/internal/tui/components"
+)
+
+// pasteJob is text pasted into the connect input, sent in chunks once
+// confirmed; the next chunk goes out a pacing delay after the previous one
+// was written, so CTS flow control holds up the rest of the paste too
+type pasteJob struct {
+	chunks  [][]byte
+	size    int  // Bytes in all chunks
+	next    int  // Index of the next chunk to send
+	sending bool // Confirmed; false while the prompt asks
+}
+
+// newPasteJob prepares pasted text for sending in mode. In ASCII mode line
+// breaks become the line ending, unless that is "none"; in hex mode the text
+// is hex digits, with any whitespace and line breaks between them.
+func newPasteJob(text string, mode components.SendingMode, lineEnd string, chunkSize int) (*pasteJob, error) {
+	var data []byte
+	switch mode {
+	case components.SendingModeHex:
+		var err error
+		data, err = parseHexInput(strings.Join(strings.Fields(text), ""))
+		if err != nil {
+			return nil, err
+		}
+	default:
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+		text = strings.ReplaceAll(text, "\r", "\n")
+		if lineEnd != "none" {
+			text = strings.ReplaceAll(text, "\n", lineEndings[lineEnd])
+		}
+		data = []byte(text)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("nothing to send")
+	}
+
+	job := &pasteJob{size: len(data)}
+	for len(data) > 0 {
+		n := min(chunkSize, len(data))
+		job.chunks = append(job.chunks, data[:n])
+		data = data[n:]
+	}
+	return job, nil
+}
+
+// sent returns the bytes in the chunks sent so far
+func (j *pasteJob) sent() int {
+	n := 0
+	for _, c := range j.chunks[:j.next] {
+		n += len(c)
+	}
+	return n
+}
+
+// needsPasteConfirm reports whether pasted text is sent as a paste rather
+// than typed into the input: it spans lines or does not fit one chunk
+func needsPasteConfirm(text string, chunkSize int) bool {
+	return strings.ContainsAny(text, "\r\n") || len(text) > chunkSize
+}
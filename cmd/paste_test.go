@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/allbin/go-serial/internal/tui/components"
+)
+
+func TestNewPasteJobChunks(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		mode      components.SendingMode
+		lineEnd   string
+		chunkSize int
+		want      []string
+	}{
+		{"shorter than a chunk", "abc", components.SendingModeASCII, "none", 4, []string{"abc"}},
+		{"exactly one chunk", "abcd", components.SendingModeASCII, "none", 4, []string{"abcd"}},
+		{"exact multiple", "abcdefgh", components.SendingModeASCII, "none", 4, []string{"abcd", "efgh"}},
+		{"partial last chunk", "abcdefghi", components.SendingModeASCII, "none", 4, []string{"abcd", "efgh", "i"}},
+		{"one byte chunks", "abc", components.SendingModeASCII, "none", 1, []string{"a", "b", "c"}},
+		{"line ending counts", "ab\ncd\n", components.SendingModeASCII, "crlf", 4, []string{"ab\r\n", "cd\r\n"}},
+		{"mixed line breaks", "a\r\nb\rc\n", components.SendingModeASCII, "lf", 3, []string{"a\nb", "\nc\n"}},
+		{"line breaks kept", "a\r\nb", components.SendingModeASCII, "none", 8, []string{"a\nb"}},
+		{"hex", "01 02\n03 04", components.SendingModeHex, "crlf", 2, []string{"\x01\x02", "\x03\x04"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job, err := newPasteJob(tt.text, tt.mode, tt.lineEnd, tt.chunkSize)
+			if err != nil {
+				t.Fatalf("newPasteJob failed: %v", err)
+			}
+			if len(job.chunks) != len(tt.want) {
+				t.Fatalf("chunks = %q, want %q", job.chunks, tt.want)
+			}
+			size := 0
+			for i, want := range tt.want {
+				if !bytes.Equal(job.chunks[i], []byte(want)) {
+					t.Errorf("chunk %d = %q, want %q", i, job.chunks[i], want)
+				}
+				size += len(want)
+			}
+			if job.size != size {
+				t.Errorf("size = %d, want %d", job.size, size)
+			}
+		})
+	}
+}
+
+func TestNewPasteJobEmpty(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		mode components.SendingMode
+	}{
+		{"empty", "", components.SendingModeASCII},
+		{"empty hex", "", components.SendingModeHex},
+		{"hex whitespace only", " \n\t", components.SendingModeHex},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if job, err := newPasteJob(tt.text, tt.mode, "none", 4); err == nil {
+				t.Errorf("newPasteJob = %d chunks, want an error", len(job.chunks))
+			}
+		})
+	}
+}
+
+func TestPasteJobSent(t *testing.T) {
+	job, err := newPasteJob("abcdefghij", components.SendingModeASCII, "none", 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for next, want := range []int{0, 4, 8, 10} {
+		job.next = next
+		if got := job.sent(); got != want {
+			t.Errorf("sent after %d chunks = %d, want %d", next, got, want)
+		}
+	}
+}
+
+func TestNeedsPasteConfirm(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"abcd", false},
+		{"abcde", true},
+		{"ab\n", true},
+		{"ab\r", true},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := needsPasteConfirm(tt.text, 4); got != tt.want {
+			t.Errorf("needsPasteConfirm(%q, 4) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
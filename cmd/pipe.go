@@ -0,0 +1,165 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/allbin/go-serial"
+	"github.com/spf13/cobra"
+)
+
+// pipeCmd represents the pipe command
+var pipeCmd = &cobra.Command{
+	Use:   "pipe <port>",
+	Short: "Connect a port to stdin and stdout",
+	Long: `Copy stdin to the port and everything the port receives to stdout, byte
+for byte, with no TUI, prompts, line ending translation or timestamps, so
+the port can be driven by expect, socat, shell redirection or any other
+program. Diagnostics go to stderr only.
+
+All port flags apply, including flow control. When stdin ends, the pending
+output is drained and received data is still copied for --linger (0 exits
+right away). --idle-timeout ends the pipe once nothing has been received
+for that long, for scripts that read a response of unknown length.
+
+Examples:
+  printf 'AT\r' | serial pipe /dev/ttyUSB2 --linger 2s
+  serial pipe /dev/ttyUSB0 --baud 9600 < commands.bin > responses.bin
+  socat TCP-LISTEN:7000,reuseaddr EXEC:'serial pipe /dev/ttyUSB0'
+  expect -c 'spawn serial pipe /dev/ttyUSB0; send "\r"; expect "login:"'`,
+	Args: portArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		args = withProfilePort(args, 1)
+		linger, _ := cmd.Flags().GetDuration("linger")
+		idleTimeout, _ := cmd.Flags().GetDuration("idle-timeout")
+
+		if linger < 0 || idleTimeout < 0 {
+			fmt.Fprintf(os.Stderr, "Error: --linger and --idle-timeout must not be negative\n")
+			os.Exit(1)
+		}
+
+		opts, _, err := portOptions(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		port, err := serial.Open(args[0], opts...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening port: %v\n", err)
+			os.Exit(1)
+		}
+		defer port.Close()
+
+		if err := runPipe(port, os.Stdin, os.Stdout, linger, idleTimeout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pipeCmd)
+
+	addPortFlags(pipeCmd, 100*time.Millisecond)
+	pipeCmd.Flags().Duration("linger", 500*time.Millisecond, "How long to keep copying received data after stdin ends")
+	pipeCmd.Flags().Duration("idle-timeout", 0, "End once nothing has been received for this long (0 = never)")
+}
+
+// runPipe copies in to port and port to out until in ends and the linger
+// time has passed, nothing arrives for idleTimeout, a signal or an error
+func runPipe(port serial.Port, in io.Reader, out io.Writer, linger, idleTimeout time.Duration) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer stop()
+
+	received := make(chan error, 1)
+	activity := make(chan struct{}, 1)
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			n, err := port.ReadContext(ctx, buf)
+			if n > 0 {
+				if _, werr := out.Write(buf[:n]); werr != nil {
+					received <- werr
+					return
+				}
+				select {
+				case activity <- struct{}{}:
+				default:
+				}
+			}
+			if err != nil {
+				received <- err
+				return
+			}
+		}
+	}()
+
+	sent := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			n, err := in.Read(buf)
+			if n > 0 {
+				if _, werr := port.WriteContext(ctx, buf[:n]); werr != nil {
+					sent <- fmt.Errorf("write failed: %w", werr)
+					return
+				}
+			}
+			if errors.Is(err, io.EOF) {
+				sent <- port.DrainOutput()
+				return
+			}
+			if err != nil {
+				sent <- err
+				return
+			}
+		}
+	}()
+
+	// A nil channel never fires, leaving the timer off
+	var idle <-chan time.Time
+	var idleTimer *time.Timer
+	if idleTimeout > 0 {
+		idleTimer = time.NewTimer(idleTimeout)
+		defer idleTimer.Stop()
+		idle = idleTimer.C
+	}
+	var lingerDone <-chan time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-received:
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("read failed: %w", err)
+		case err := <-sent:
+			if err != nil {
+				return err
+			}
+			if linger == 0 {
+				return nil
+			}
+			lingerDone = time.After(linger)
+		case <-lingerDone:
+			return nil
+		case <-activity:
+			if idleTimer != nil {
+				idleTimer.Reset(idleTimeout)
+			}
+		case <-idle:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,83 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/allbin/go-serial"
+	"github.com/allbin/go-serial/internal/tui/components"
+)
+
+// plainOutput prints traffic a line at a time for --plain, formatted like
+// the terminal of the TUI; colors only reach a terminal, so pipes, dumb
+// terminals and the journal get plain text
+type plainOutput struct {
+	mu        sync.Mutex
+	w         *bufio.Writer
+	formatter *components.DataFormatter
+}
+
+// newPlainOutput prints to w; filter is an expression checked by
+// filterOptions
+func newPlainOutput(w io.Writer, noTimestamps, noIndicators bool, filter string, highlights []components.HighlightRule) *plainOutput {
+	formatter := components.NewDataFormatter(false, true)
+	formatter.SetFormatOptions(noTimestamps, noIndicators)
+	formatter.SetHighlights(highlights)
+	f := components.NewFilter()
+	f.Set(filter)
+	formatter.SetFilter(f)
+	return &plainOutput{w: bufio.NewWriter(w), formatter: formatter}
+}
+
+// Write prints the complete lines of msg; RX data up to the next line break
+// is held back until it arrives or Flush
+func (o *plainOutput) Write(msg components.DataReceivedMsg) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.print(o.formatter.FormatMessage(msg))
+}
+
+// Flush prints the RX data still waiting for a line break
+func (o *plainOutput) Flush() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.print(o.formatter.FlushBuffer(time.Now()))
+}
+
+func (o *plainOutput) print(lines []string) {
+	for _, line := range lines {
+		fmt.Fprintln(o.w, line)
+	}
+	o.w.Flush()
+}
+
+// readSlabSize is the block size of the ReadSlab each read pump receives
+// into; received chunks are kept by their consumers, so reads go straight
+// into memory that can be handed over instead of being copied out of a
+// reused buffer
+const readSlabSize = 64 * 1024
+
+// readPlain passes what port receives to record until ctx is done, which
+// it does not report as an error
+func readPlain(ctx context.Context, port serial.Port, record func(components.DataReceivedMsg)) error {
+	slab := serial.NewReadSlab(readSlabSize)
+	for {
+		n, at, err := serial.ReadTimestamped(ctx, port, slab.Next(4096))
+		if n > 0 {
+			record(components.DataReceivedMsg{Timestamp: at, Data: slab.Take(n)})
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("read failed: %w", err)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// plotLineLimit bounds the partial line a regex plot rule buffers
+const plotLineLimit = 4096
+
+// plotRule extracts numeric samples from received data for connect --plot
+// A rule is either a regular expression, whose first capture group (or
+// whole match) is parsed as a number on each received line, or a binary
+// field in the form [hex-prefix]@offset:type[*scale], read from each
+// received frame that starts with the prefix:
+//
+//	'T=(-?[0-9.]+)'        Temperature from "T=23.4 RH=41" lines
+//	'01 03@3:s16be*0.1'    Signed register from Modbus read responses
+type plotRule struct {
+	desc string
+
+	re   *regexp.Regexp // Text rule
+	line []byte         // Received text not yet ended by a newline
+
+	prefix []byte // Binary rule: frames must start with these bytes
+	offset int
+	field  plotField
+	scale  float64
+}
+
+// plotField decodes one binary field type
+type plotField struct {
+	size   int
+	decode func(b []byte) float64
+}
+
+var plotFields = map[string]plotField{
+	"u8":    {1, func(b []byte) float64 { return float64(b[0]) }},
+	"s8":    {1, func(b []byte) float64 { return float64(int8(b[0])) }},
+	"u16be": {2, func(b []byte) float64 { return float64(binary.BigEndian.Uint16(b)) }},
+	"u16le": {2, func(b []byte) float64 { return float64(binary.LittleEndian.Uint16(b)) }},
+	"s16be": {2, func(b []byte) float64 { return float64(int16(binary.BigEndian.Uint16(b))) }},
+	"s16le": {2, func(b []byte) float64 { return float64(int16(binary.LittleEndian.Uint16(b))) }},
+	"u32be": {4, func(b []byte) float64 { return float64(binary.BigEndian.Uint32(b)) }},
+	"u32le": {4, func(b []byte) float64 { return float64(binary.LittleEndian.Uint32(b)) }},
+	"s32be": {4, func(b []byte) float64 { return float64(int32(binary.BigEndian.Uint32(b))) }},
+	"s32le": {4, func(b []byte) float64 { return float64(int32(binary.LittleEndian.Uint32(b))) }},
+	"f32be": {4, func(b []byte) float64 { return float64(math.Float32frombits(binary.BigEndian.Uint32(b))) }},
+	"f32le": {4, func(b []byte) float64 { return float64(math.Float32frombits(binary.LittleEndian.Uint32(b))) }},
+}
+
+var plotFieldPattern = regexp.MustCompile(`^([0-9a-fA-F ]*)@(\d+):([a-z0-9]+)(?:\*([-+0-9.eE]+))?$`)
+
+// parsePlotRule parses a --plot rule
+func parsePlotRule(s string) (*plotRule, error) {
+	if m := plotFieldPattern.FindStringSubmatch(s); m != nil {
+		r := &plotRule{desc: s, scale: 1}
+		if p := strings.ReplaceAll(m[1], " ", ""); p != "" {
+			prefix, err := parseHexInput(p)
+			if err != nil {
+				return nil, fmt.Errorf("prefix: %w", err)
+			}
+			r.prefix = prefix
+		}
+		r.offset, _ = strconv.Atoi(m[2])
+		field, ok := plotFields[m[3]]
+		if !ok {
+			return nil, fmt.Errorf("unknown field type %q (want u8, s8, u16be, s16le, f32be, ...)", m[3])
+		}
+		r.field = field
+		if m[4] != "" {
+			scale, err := strconv.ParseFloat(m[4], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid scale %q", m[4])
+			}
+			r.scale = scale
+		}
+		return r, nil
+	}
+
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return nil, err
+	}
+	if re.NumSubexp() > 1 {
+		return nil, fmt.Errorf("want at most one capture group, got %d", re.NumSubexp())
+	}
+	return &plotRule{desc: s, re: re}, nil
+}
+
+// feed returns the samples found in received data
+func (r *plotRule) feed(data []byte) []float64 {
+	if r.re == nil {
+		if !bytes.HasPrefix(data, r.prefix) || len(data) < r.offset+r.field.size {
+			return nil
+		}
+		return []float64{r.field.decode(data[r.offset:r.offset+r.field.size]) * r.scale}
+	}
+
+	r.line = append(r.line, data...)
+	var samples []float64
+	for {
+		end := bytes.IndexByte(r.line, '\n')
+		if end < 0 {
+			break
+		}
+		for _, m := range r.re.FindAllSubmatch(r.line[:end], -1) {
+			text := m[len(m)-1]
+			if v, err := strconv.ParseFloat(strings.TrimSpace(string(text)), 64); err == nil {
+				samples = append(samples, v)
+			}
+		}
+		r.line = r.line[end+1:]
+	}
+	if over := len(r.line) - plotLineLimit; over > 0 {
+		r.line = r.line[over:]
+	}
+	return samples
+}
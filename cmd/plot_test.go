@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParsePlotRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    string
+		wantErr string // Substring of the error, or empty for success
+	}{
+		{"regexp", `T=(-?[0-9.]+)`, ""},
+		{"whole match", `[0-9]+`, ""},
+		{"field", "01 03@3:s16be*0.1", ""},
+		{"field without prefix", "@0:u8", ""},
+		{"two groups", `(a)(b)`, "want at most one capture group, got 2"},
+		{"bad regexp", `(`, "error parsing regexp"},
+		{"unknown field", "01@1:u24be", `unknown field type "u24be"`},
+		{"bad scale", "@0:u8*1e", `invalid scale "1e"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parsePlotRule(tt.rule)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("parsePlotRule(%q) failed: %v", tt.rule, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parsePlotRule(%q) error = %v, want one containing %q", tt.rule, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPlotRuleFeed(t *testing.T) {
+	tests := []struct {
+		name   string
+		rule   string
+		frames []string
+		want   []float64
+	}{
+		{"line split across reads", `T=(-?[0-9.]+)`, []string{"T=23", ".4 RH=41\nT=-1", "\n"}, []float64{23.4, -1}},
+		{"several per line", `[0-9]+`, []string{"1 2 3\n"}, []float64{1, 2, 3}},
+		{"unterminated line", `[0-9]+`, []string{"42"}, nil},
+		{"s16be scaled", "01 03@3:s16be*0.1", []string{"\x01\x03\x02\xff\x38"}, []float64{-20}},
+		{"wrong prefix", "01 03@3:u16be", []string{"\x01\x04\x02\x00\x2a"}, nil},
+		{"short frame", "01 03@3:u16be", []string{"\x01\x03\x02\x00"}, nil},
+		{"u8", "@0:u8", []string{"\xff"}, []float64{255}},
+		{"u32le", "@0:u32le", []string{"\x01\x00\x00\x00"}, []float64{1}},
+		{"f32be", "@0:f32be", []string{"\x3f\xc0\x00\x00"}, []float64{1.5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := parsePlotRule(tt.rule)
+			if err != nil {
+				t.Fatalf("parsePlotRule(%q) failed: %v", tt.rule, err)
+			}
+			var got []float64
+			for _, f := range tt.frames {
+				for _, v := range r.feed([]byte(f)) {
+					got = append(got, math.Round(v*1000)/1000)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("samples = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlotRuleLineLimit(t *testing.T) {
+	r, err := parsePlotRule(`[0-9]+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.feed([]byte(strings.Repeat("x", 2*plotLineLimit)))
+	if len(r.line) != plotLineLimit {
+		t.Errorf("buffered %d bytes, want %d", len(r.line), plotLineLimit)
+	}
+}
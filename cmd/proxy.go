@@ -0,0 +1,344 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/allbin/go-serial"
+	"github.com/allbin/go-serial/internal/tui/components"
+	"github.com/spf13/cobra"
+	"golang.org/x/sys/unix"
+)
+
+// proxyCmd represents the proxy command
+var proxyCmd = &cobra.Command{
+	Use:   "proxy <port>",
+	Short: "Put a logging, rewriting PTY in front of a port",
+	Long: `Open a port and serve it on a new PTY, so that an application pointed at
+the PTY talks to the device through this command. Every chunk passing in
+either direction is printed, labeled app (application to device) or device
+(device to application), with microsecond timestamps.
+
+--pty=PATH also creates a symlink with a stable name to the PTY, removed on
+exit, for applications configured with a fixed device name. The line
+settings of the real port come from the flags; what the application sets
+on the PTY has no effect.
+
+--rewrite replaces bytes on the way through: [tx:|rx:]FROM=TO, where tx
+rewrites what the application sends, rx what the device sends, and no
+prefix both. FROM and TO are hex such as 0x0102, or text with Go escapes
+such as \r\n (write = as \x3d); an empty TO deletes FROM. The rules apply in
+the order given, and matches spanning two reads are found: bytes that may
+start a match are held until the rest arrives or the line goes quiet for
+100ms.
+
+--output and --pcapng record the traffic as forwarded, after rewriting, in
+the formats of serial sniff; --quiet stops printing it.
+
+Example usage:
+  serial proxy /dev/ttyUSB0
+  serial proxy /dev/ttyUSB0 --baud 9600 --pty=/tmp/ttyMETER
+  serial proxy /dev/ttyUSB0 --output tool.log --pcapng tool.pcapng --quiet
+  serial proxy /dev/ttyUSB0 --rewrite 'rx:FW 1.2=FW 2.0' --rewrite tx:0x0d0a=0x0d`,
+	Args: portArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		args = withProfilePort(args, 1)
+		portPath := args[0]
+
+		linkPath, _ := cmd.Flags().GetString("pty")
+		ruleTexts, _ := cmd.Flags().GetStringArray("rewrite")
+		outputPath, _ := cmd.Flags().GetString("output")
+		pcapPath, _ := cmd.Flags().GetString("pcapng")
+		quiet, _ := cmd.Flags().GetBool("quiet")
+		if linkPath == proxyNoLink {
+			linkPath = ""
+		}
+
+		var rules []rewriteRule
+		for _, text := range ruleTexts {
+			rule, err := parseRewriteRule(text)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: invalid --rewrite %q: %v\n", text, err)
+				os.Exit(1)
+			}
+			rules = append(rules, rule)
+		}
+
+		opts, config, err := portOptions(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		err = runProxy(portPath, config, linkPath, rules, outputPath, pcapPath, quiet, opts...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// proxyNoLink is the value of a bare --pty, which creates no symlink
+const proxyNoLink = "auto"
+
+// proxyQuiet is how long the line must be quiet before bytes held for a
+// possible rewrite match are passed on
+const proxyQuiet = 100 * time.Millisecond
+
+func init() {
+	rootCmd.AddCommand(proxyCmd)
+
+	addPortFlags(proxyCmd, proxyQuiet)
+	proxyCmd.Flags().String("pty", proxyNoLink, "Serve the port on a new PTY; --pty=PATH also symlinks PATH to it")
+	proxyCmd.Flags().Lookup("pty").NoOptDefVal = proxyNoLink
+	proxyCmd.Flags().StringArray("rewrite", nil, "Replace bytes in transit: [tx:|rx:]FROM=TO (repeatable)")
+	proxyCmd.Flags().StringP("output", "o", "", "Also append the traffic to this file, one line per chunk")
+	proxyCmd.Flags().String("pcapng", "", "Also write the traffic to a pcapng file")
+	proxyCmd.Flags().BoolP("quiet", "q", false, "Do not print the traffic")
+}
+
+func runProxy(portPath string, config serial.Config, linkPath string, rules []rewriteRule, outputPath, pcapPath string, quiet bool, opts ...serial.Option) error {
+	port, err := serial.Open(portPath, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to open port: %w", err)
+	}
+	defer port.Close()
+
+	master, ptyPath, err := serial.OpenPTY()
+	if err != nil {
+		return err
+	}
+	if master, err = nonblocking(master); err != nil {
+		return err
+	}
+	defer master.Close()
+	// Keep the slave open so master reads do not fail with EIO while the
+	// application has it closed
+	holder, err := os.OpenFile(ptyPath, os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", ptyPath, err)
+	}
+	defer holder.Close()
+
+	shown := ptyPath
+	if linkPath != "" {
+		if err := os.Symlink(ptyPath, linkPath); err != nil {
+			return fmt.Errorf("failed to create link: %w", err)
+		}
+		defer os.Remove(linkPath)
+		shown = fmt.Sprintf("%s -> %s", linkPath, ptyPath)
+	}
+
+	labels := [2]string{"app", "device"}
+	rec, err := openLinkRecorder(outputPath, pcapPath, [2]string{ptyPath, portPath}, labels)
+	if err != nil {
+		return err
+	}
+	defer rec.close()
+
+	var out *plainOutput
+	if !quiet {
+		out = newPlainOutput(os.Stdout, false, false, "", nil)
+		out.formatter.SetFramed(true)
+		out.formatter.SetMicroseconds(true)
+	}
+	record := func(msg components.DataReceivedMsg) {
+		rec.write(msg)
+		if out != nil {
+			out.Write(msg)
+		}
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	fmt.Fprintf(os.Stderr, "Proxying %s at %s on %s\n", portPath, formatLineSettings(config), shown)
+	fmt.Fprintf(os.Stderr, "Press Ctrl+C to stop\n\n")
+
+	tx, rx := newRewriter(rules, true), newRewriter(rules, false)
+	errs := make(chan error, 2)
+	go func() {
+		errs <- proxyFromApp(ctx, master, port, tx, func(at time.Time, data []byte) {
+			record(components.DataReceivedMsg{Timestamp: at, Data: data, IsTX: true, Source: labels[0]})
+		})
+		cancel()
+	}()
+	go func() {
+		errs <- proxyFromDevice(ctx, port, master, rx, func(at time.Time, data []byte) {
+			record(components.DataReceivedMsg{Timestamp: at, Data: data, Source: labels[1]})
+		})
+		cancel()
+	}()
+	err = <-errs
+	if err2 := <-errs; err == nil {
+		err = err2
+	}
+
+	if len(rules) > 0 {
+		fmt.Fprintf(os.Stderr, "\nRewrites: %d app, %d device\n", tx.hits, rx.hits)
+	}
+	return err
+}
+
+// nonblocking returns f reopened in non-blocking mode, so that read
+// deadlines work on it, and closes f
+// OpenPTY leaves the master blocking, as calling Fd does.
+func nonblocking(f *os.File) (*os.File, error) {
+	defer f.Close()
+	fd, err := unix.Dup(int(f.Fd()))
+	if err != nil {
+		return nil, err
+	}
+	if err := unix.SetNonblock(fd, true); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+	return os.NewFile(uintptr(fd), f.Name()), nil
+}
+
+// proxyFromApp forwards what the application writes to the PTY to port
+func proxyFromApp(ctx context.Context, master *os.File, port serial.Port, rw *rewriter, record func(time.Time, []byte)) error {
+	buf := make([]byte, 4096)
+	for ctx.Err() == nil {
+		master.SetReadDeadline(time.Now().Add(proxyQuiet))
+		n, err := master.Read(buf)
+		at := time.Now()
+		data := rw.apply(buf[:n])
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			data, err = append(data, rw.flush()...), nil
+		}
+		if len(data) > 0 {
+			if _, werr := port.Write(data); werr != nil {
+				return fmt.Errorf("write to port failed: %w", werr)
+			}
+			record(at, data)
+		}
+		if err != nil {
+			return fmt.Errorf("read from pty failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// proxyFromDevice forwards what port receives to the application
+func proxyFromDevice(ctx context.Context, port serial.Port, master *os.File, rw *rewriter, record func(time.Time, []byte)) error {
+	buf := make([]byte, 4096)
+	for ctx.Err() == nil {
+		// Reads return empty once the line has been quiet for the read timeout
+		n, err := port.Read(buf)
+		at := time.Now()
+		var data []byte
+		switch {
+		case n > 0:
+			data = rw.apply(buf[:n])
+		case err == nil:
+			data = rw.flush()
+		}
+		if len(data) > 0 {
+			if _, werr := master.Write(data); werr != nil {
+				return fmt.Errorf("write to pty failed: %w", werr)
+			}
+			record(at, data)
+		}
+		if err != nil {
+			return fmt.Errorf("read from port failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// rewriteRule replaces from with to in one or both directions
+type rewriteRule struct {
+	tx, rx   bool // Applies to data from the application, from the device
+	from, to []byte
+}
+
+// parseRewriteRule reads a rule of the form [tx:|rx:]FROM=TO
+func parseRewriteRule(s string) (rewriteRule, error) {
+	rule := rewriteRule{tx: true, rx: true}
+	if rest, ok := strings.CutPrefix(s, "tx:"); ok {
+		s, rule.rx = rest, false
+	} else if rest, ok := strings.CutPrefix(s, "rx:"); ok {
+		s, rule.tx = rest, false
+	}
+	from, to, ok := strings.Cut(s, "=")
+	if !ok {
+		return rule, fmt.Errorf("want FROM=TO")
+	}
+	var err error
+	if rule.from, err = parseDelimiter(from); err != nil {
+		return rule, fmt.Errorf("FROM: %w", err)
+	}
+	if to != "" {
+		if rule.to, err = parseDelimiter(to); err != nil {
+			return rule, fmt.Errorf("TO: %w", err)
+		}
+	}
+	return rule, nil
+}
+
+// rewriter applies the rules of one direction to a stream, across reads
+type rewriter struct {
+	rules   []rewriteRule
+	pending []byte // Tail that may start a match, awaiting more data
+	hits    int
+}
+
+func newRewriter(rules []rewriteRule, tx bool) *rewriter {
+	w := &rewriter{}
+	for _, rule := range rules {
+		if (tx && rule.tx) || (!tx && rule.rx) {
+			w.rules = append(w.rules, rule)
+		}
+	}
+	return w
+}
+
+// apply returns data with the rules applied, holding back a tail that may
+// be the start of a match until the next apply or flush
+func (w *rewriter) apply(data []byte) []byte {
+	if len(w.rules) == 0 {
+		return bytes.Clone(data)
+	}
+	buf := append(w.pending, data...)
+	w.pending = nil
+	var out []byte
+	i := 0
+scan:
+	for i < len(buf) {
+		for _, rule := range w.rules {
+			if bytes.HasPrefix(buf[i:], rule.from) {
+				out = append(out, rule.to...)
+				i += len(rule.from)
+				w.hits++
+				continue scan
+			}
+		}
+		for _, rule := range w.rules {
+			if bytes.HasPrefix(rule.from, buf[i:]) {
+				w.pending = bytes.Clone(buf[i:])
+				break scan
+			}
+		}
+		out = append(out, buf[i])
+		i++
+	}
+	return out
+}
+
+// flush returns the held back bytes, which the quiet line leaves unmatched
+func (w *rewriter) flush() []byte {
+	data := w.pending
+	w.pending = nil
+	return data
+}
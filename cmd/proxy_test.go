@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseRewriteRule(t *testing.T) {
+	tests := []struct {
+		in       string
+		tx, rx   bool
+		from, to string
+		wantErr  string // Substring of the error, or empty for success
+	}{
+		{in: "FW 1.2=FW 2.0", tx: true, rx: true, from: "FW 1.2", to: "FW 2.0"},
+		{in: "tx:0x0d0a=0x0d", tx: true, from: "\r\n", to: "\r"},
+		{in: `rx:\r\n=\n`, rx: true, from: "\r\n", to: "\n"},
+		{in: "rx:ping=", rx: true, from: "ping"},
+		{in: `a\x3db=c`, tx: true, rx: true, from: "a=b", to: "c"},
+		{in: "nothing", wantErr: "want FROM=TO"},
+		{in: "=x", wantErr: "FROM: empty delimiter"},
+		{in: "0xzz=x", wantErr: "FROM:"},
+		{in: `a=\q`, wantErr: "TO:"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			rule, err := parseRewriteRule(tt.in)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseRewriteRule failed: %v", err)
+			}
+			if rule.tx != tt.tx || rule.rx != tt.rx || string(rule.from) != tt.from || string(rule.to) != tt.to {
+				t.Errorf("rule = %+v, want tx %v rx %v %q=%q", rule, tt.tx, tt.rx, tt.from, tt.to)
+			}
+		})
+	}
+}
+
+func TestRewriter(t *testing.T) {
+	mustRules := func(texts ...string) []rewriteRule {
+		var rules []rewriteRule
+		for _, text := range texts {
+			rule, err := parseRewriteRule(text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rules = append(rules, rule)
+		}
+		return rules
+	}
+
+	tests := []struct {
+		name     string
+		rules    []rewriteRule
+		tx       bool
+		reads    []string
+		want     string // Output of all reads followed by a flush
+		wantHits int
+	}{
+		{"no rules", nil, true, []string{"abc", "def"}, "abcdef", 0},
+		{"in one read", mustRules("FW 1.2=FW 2.0"), false, []string{"ver FW 1.2 ok"}, "ver FW 2.0 ok", 1},
+		{"across reads", mustRules("FW 1.2=FW 2.0"), false, []string{"ver FW", " 1.", "2 ok"}, "ver FW 2.0 ok", 1},
+		{"deletion", mustRules("ping="), true, []string{"pingpong ping"}, "pong ", 2},
+		{"rules in order", mustRules("ab=x", "a=y"), true, []string{"aab"}, "yx", 2},
+		{"partial match flushed", mustRules(`\r\n=\n`), true, []string{"end\r"}, "end\r", 0},
+		{"other direction only", mustRules("rx:a=b"), true, []string{"aaa"}, "aaa", 0},
+		{"this direction only", mustRules("rx:a=b", "tx:a=c"), false, []string{"aa"}, "bb", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rw := newRewriter(tt.rules, tt.tx)
+			var out []byte
+			for _, read := range tt.reads {
+				out = append(out, rw.apply([]byte(read))...)
+			}
+			out = append(out, rw.flush()...)
+			if !bytes.Equal(out, []byte(tt.want)) {
+				t.Errorf("output = %q, want %q", out, tt.want)
+			}
+			if rw.hits != tt.wantHits {
+				t.Errorf("hits = %d, want %d", rw.hits, tt.wantHits)
+			}
+		})
+	}
+}
+
+func TestRewriterHoldsPossibleMatch(t *testing.T) {
+	rule, err := parseRewriteRule(`\r\n=\n`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rw := newRewriter([]rewriteRule{rule}, true)
+	if got := rw.apply([]byte("line\r")); string(got) != "line" {
+		t.Errorf("apply = %q, want the \\r held back", got)
+	}
+	if got := rw.apply([]byte("\nnext")); string(got) != "\nnext" {
+		t.Errorf("apply = %q, want the completed match rewritten", got)
+	}
+	if got := rw.flush(); len(got) != 0 {
+		t.Errorf("flush = %q, want nothing held", got)
+	}
+}
@@ -0,0 +1,61 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// keySequences are the bytes a VT100/xterm sends for keys without a
+// character of their own
+var keySequences = map[tea.KeyType]string{
+	tea.KeyUp:        "\x1b[A",
+	tea.KeyDown:      "\x1b[B",
+	tea.KeyRight:     "\x1b[C",
+	tea.KeyLeft:      "\x1b[D",
+	tea.KeyHome:      "\x1b[H",
+	tea.KeyEnd:       "\x1b[F",
+	tea.KeyPgUp:      "\x1b[5~",
+	tea.KeyPgDown:    "\x1b[6~",
+	tea.KeyInsert:    "\x1b[2~",
+	tea.KeyDelete:    "\x1b[3~",
+	tea.KeyShiftTab:  "\x1b[Z",
+	tea.KeyCtrlUp:    "\x1b[1;5A",
+	tea.KeyCtrlDown:  "\x1b[1;5B",
+	tea.KeyCtrlRight: "\x1b[1;5C",
+	tea.KeyCtrlLeft:  "\x1b[1;5D",
+	tea.KeyF1:        "\x1bOP",
+	tea.KeyF2:        "\x1bOQ",
+	tea.KeyF3:        "\x1bOR",
+	tea.KeyF4:        "\x1bOS",
+	tea.KeyF5:        "\x1b[15~",
+	tea.KeyF6:        "\x1b[17~",
+	tea.KeyF7:        "\x1b[18~",
+	tea.KeyF8:        "\x1b[19~",
+	tea.KeyF9:        "\x1b[20~",
+	tea.KeyF10:       "\x1b[21~",
+	tea.KeyF11:       "\x1b[23~",
+	tea.KeyF12:       "\x1b[24~",
+}
+
+// keystrokeBytes returns what a terminal sends for a key in raw mode: the
+// character typed, the control code of ctrl keys (enter is CR, backspace
+// DEL), or an escape sequence; alt prefixes ESC. Keys without one give nil.
+func keystrokeBytes(msg tea.KeyMsg) []byte {
+	var data []byte
+	switch {
+	case msg.Type == tea.KeyRunes:
+		data = []byte(string(msg.Runes))
+	case msg.Type == tea.KeySpace:
+		data = []byte(" ")
+	case msg.Type >= 0 && msg.Type <= 127:
+		data = []byte{byte(msg.Type)}
+	default:
+		data = []byte(keySequences[msg.Type])
+	}
+	if len(data) > 0 && msg.Alt {
+		data = append([]byte{0x1b}, data...)
+	}
+	return data
+}
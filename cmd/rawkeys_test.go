@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestKeystrokeBytes(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  tea.KeyMsg
+		want []byte
+	}{
+		{"rune", tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")}, []byte("a")},
+		{"multibyte rune", tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("é")}, []byte("é")},
+		{"space", tea.KeyMsg{Type: tea.KeySpace}, []byte(" ")},
+		{"ctrl-a", tea.KeyMsg{Type: tea.KeyCtrlA}, []byte{0x01}},
+		{"ctrl-c", tea.KeyMsg{Type: tea.KeyCtrlC}, []byte{0x03}},
+		{"ctrl-z", tea.KeyMsg{Type: tea.KeyCtrlZ}, []byte{0x1a}},
+		{"ctrl-@", tea.KeyMsg{Type: tea.KeyCtrlAt}, []byte{0x00}},
+		{"enter", tea.KeyMsg{Type: tea.KeyEnter}, []byte{'\r'}},
+		{"tab", tea.KeyMsg{Type: tea.KeyTab}, []byte{'\t'}},
+		{"backspace", tea.KeyMsg{Type: tea.KeyBackspace}, []byte{0x7f}},
+		{"escape", tea.KeyMsg{Type: tea.KeyEsc}, []byte{0x1b}},
+		{"up", tea.KeyMsg{Type: tea.KeyUp}, []byte("\x1b[A")},
+		{"down", tea.KeyMsg{Type: tea.KeyDown}, []byte("\x1b[B")},
+		{"right", tea.KeyMsg{Type: tea.KeyRight}, []byte("\x1b[C")},
+		{"left", tea.KeyMsg{Type: tea.KeyLeft}, []byte("\x1b[D")},
+		{"ctrl-left", tea.KeyMsg{Type: tea.KeyCtrlLeft}, []byte("\x1b[1;5D")},
+		{"delete", tea.KeyMsg{Type: tea.KeyDelete}, []byte("\x1b[3~")},
+		{"f1", tea.KeyMsg{Type: tea.KeyF1}, []byte("\x1bOP")},
+		{"f12", tea.KeyMsg{Type: tea.KeyF12}, []byte("\x1b[24~")},
+		{"alt-x", tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x"), Alt: true}, []byte("\x1bx")},
+		{"alt-ctrl-a", tea.KeyMsg{Type: tea.KeyCtrlA, Alt: true}, []byte{0x1b, 0x01}},
+		{"alt-up", tea.KeyMsg{Type: tea.KeyUp, Alt: true}, []byte("\x1b\x1b[A")},
+		{"alt-space", tea.KeyMsg{Type: tea.KeySpace, Alt: true}, []byte("\x1b ")},
+		{"no sequence", tea.KeyMsg{Type: tea.KeyShiftUp}, nil},
+		{"alt without sequence", tea.KeyMsg{Type: tea.KeyShiftUp, Alt: true}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := keystrokeBytes(tt.msg); !bytes.Equal(got, tt.want) {
+				t.Errorf("keystrokeBytes(%v) = %q, want %q", tt.msg, got, tt.want)
+			}
+		})
+	}
+}
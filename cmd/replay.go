@@ -0,0 +1,191 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/allbin/go-serial"
+	"github.com/allbin/go-serial/session"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+)
+
+// replayCmd represents the replay command
+var replayCmd = &cobra.Command{
+	Use:   "replay <session-file> <port>",
+	Short: "Play back a recorded session to a port",
+	Long: `Re-send the TX traffic of a session recording (such as bridge --session
+between an application and a device) to a port with the original timing,
+to regression-test a device against captured traffic.
+
+--speed scales the timing (2 plays twice as fast) and --max-gap shortens
+long idle stretches. --direction rx plays the recorded device side
+instead, so the port stands in for the device towards a host.
+
+--session records the run, the replayed data and everything the device
+answers, for comparison with the original recording; answers arriving
+within --wait after the last chunk are included.
+
+Examples:
+  serial replay field-bug.srec /dev/ttyUSB0
+  serial replay provisioning.srec /dev/ttyUSB0 --speed 4 --max-gap 1s
+  serial replay field-bug.srec /dev/ttyUSB0 --session rerun.srec --wait 3s
+  serial replay device.srec /dev/ttyUSB1 --direction rx`,
+	Args: portArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		// The session file comes first, so the profile's port goes last
+		if len(args) == 1 {
+			args = append(args, profilePort())
+		}
+		sessionPath, portPath := args[0], args[1]
+
+		speed, _ := cmd.Flags().GetFloat64("speed")
+		maxGap, _ := cmd.Flags().GetDuration("max-gap")
+		direction, _ := cmd.Flags().GetString("direction")
+		recordPath, _ := cmd.Flags().GetString("session")
+		wait, _ := cmd.Flags().GetDuration("wait")
+
+		if speed <= 0 {
+			fmt.Fprintf(os.Stderr, "Error: --speed must be positive\n")
+			os.Exit(1)
+		}
+		replayOpts := []session.ReplayOption{session.WithSpeed(speed), session.WithMaxGap(maxGap)}
+		switch direction {
+		case "tx":
+			replayOpts = append(replayOpts, session.WithDirection(session.KindTX))
+		case "rx":
+			replayOpts = append(replayOpts, session.WithDirection(session.KindRX))
+		default:
+			fmt.Fprintf(os.Stderr, "Error: invalid --direction %q (want tx or rx)\n", direction)
+			os.Exit(1)
+		}
+
+		f, err := os.Open(sessionPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		reader, err := session.NewReader(f)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", sessionPath, err)
+			os.Exit(1)
+		}
+
+		opts, _, err := portOptions(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		port, err := serial.Open(portPath, opts...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening port: %v\n", err)
+			os.Exit(1)
+		}
+
+		sess, err := createSession(recordPath)
+		if err != nil {
+			port.Close()
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		err = runReplay(reader, sess.wrap(port), wait, replayOpts...)
+		port.Close()
+		if cerr := sess.Close(); err == nil && cerr != nil {
+			err = fmt.Errorf("failed to write session: %w", cerr)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(replayCmd)
+
+	addPortFlags(replayCmd, 100*time.Millisecond)
+	replayCmd.Flags().Float64("speed", 1, "Playback speed factor (2 = twice as fast)")
+	replayCmd.Flags().Duration("max-gap", 0, "Cap idle gaps between chunks (0 = keep them)")
+	replayCmd.Flags().String("direction", "tx", "Side of the recording to send: tx (application) or rx (device)")
+	replayCmd.Flags().String("session", "", "Record the replay and the device's answers to a session file")
+	replayCmd.Flags().Duration("wait", time.Second, "How long to keep reading answers after the last chunk")
+}
+
+// replayCounter counts the chunks and bytes written through it
+type replayCounter struct {
+	port   serial.Port
+	chunks int
+	bytes  int
+}
+
+func (c *replayCounter) Write(data []byte) (int, error) {
+	n, err := c.port.Write(data)
+	c.chunks++
+	c.bytes += n
+	return n, err
+}
+
+// runReplay plays reader to port while reading the port's answers, so a
+// recording port records them
+func runReplay(reader *session.Reader, port serial.Port, wait time.Duration, opts ...session.ReplayOption) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	readCtx, stopReading := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		buf := make([]byte, 4096)
+		for readCtx.Err() == nil {
+			if _, err := port.ReadContext(readCtx, buf); err != nil {
+				if readCtx.Err() == nil {
+					logger.Debug("replay read stopped", "err", err)
+				}
+				return
+			}
+		}
+	}()
+
+	counter := &replayCounter{port: port}
+	start := time.Now()
+	err := session.Replay(ctx, reader, counter, opts...)
+	elapsed := time.Since(start)
+	if err == nil {
+		err = port.DrainOutput()
+	}
+	if err == nil {
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+		}
+	}
+	stopReading()
+	wg.Wait()
+
+	if errors.Is(err, context.Canceled) {
+		return fmt.Errorf("replay interrupted after %d bytes", counter.bytes)
+	}
+	if err != nil {
+		return err
+	}
+
+	successStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("40")).
+		Bold(true)
+
+	fmt.Printf("%s Replayed %d bytes in %d chunks over %v\n", successStyle.Render("✓"),
+		counter.bytes, counter.chunks, elapsed.Round(time.Millisecond))
+	return nil
+}
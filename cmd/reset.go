@@ -32,7 +32,7 @@ Examples:
   sudo serial reset --serial NC7ILXW1    # Reset by serial number`,
 	Args: func(cmd *cobra.Command, args []string) error {
 		serialFlag, _ := cmd.Flags().GetString("serial")
-		if serialFlag == "" && len(args) != 1 {
+		if serialFlag == "" && len(withProfilePort(args, 1)) != 1 {
 			return errors.New("requires either a port path argument or --serial flag")
 		}
 		if serialFlag != "" && len(args) > 0 {
@@ -57,7 +57,7 @@ Examples:
 			err = serial.ResetUSBDeviceBySerial(serialFlag)
 		} else {
 			// Reset by port path
-			portPath := args[0]
+			portPath := withProfilePort(args, 1)[0]
 			fmt.Printf("Resetting USB device: %s\n", portPath)
 			err = serial.ResetUSBDevice(portPath)
 		}
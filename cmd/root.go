@@ -2,13 +2,27 @@ package cmd
 
 import (
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 
+	"github.com/allbin/go-serial"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
-var cfgFile string
+var (
+	cfgFile  string
+	logLevel string
+	logFile  string
+	traceTo  string
+)
+
+// logger receives diagnostics from commands and the ports they open
+var logger serial.Logger = serial.DiscardLogger
+
+// traceOutput receives the ioctl trace of opened ports (--trace), if set
+var traceOutput io.Writer
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
@@ -23,6 +37,9 @@ to quickly create a Cobra application.`,
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	// Run: func(cmd *cobra.Command, args []string) { },
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return applyConfig(cmd, args)
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -35,13 +52,17 @@ func Execute() {
 }
 
 func init() {
-	cobra.OnInitialize(initConfig)
+	cobra.OnInitialize(initLogging, initConfig)
 
 	// Here you will define your flags and configuration settings.
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
 
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.serial.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.config/serial/config.yaml or $HOME/.serial.yaml)")
+	rootCmd.PersistentFlags().StringVarP(&profileName, "profile", "P", "", "Apply settings from a named config file profile")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "warn", "Diagnostic log level: debug, info, warn, error")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "Write diagnostics to this file instead of stderr")
+	rootCmd.PersistentFlags().StringVar(&traceTo, "trace", "", "Trace termios/modem ioctls to this file (- for stderr)")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -50,24 +71,74 @@ func init() {
 
 // initConfig reads in config file and ENV variables if set.
 func initConfig() {
-	if cfgFile != "" {
-		// Use config file from the flag.
-		viper.SetConfigFile(cfgFile)
-	} else {
-		// Find home directory.
-		home, err := os.UserHomeDir()
-		cobra.CheckErr(err)
-
-		// Search config in home directory with name ".serial" (without extension).
-		viper.AddConfigPath(home)
+	viper.AutomaticEnv() // read in environment variables that match
+
+	if path := findConfigFile(); path != "" {
+		viper.SetConfigFile(path)
 		viper.SetConfigType("yaml")
-		viper.SetConfigName(".serial")
+		if err := viper.ReadInConfig(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		logger.Debug("using config file", "path", viper.ConfigFileUsed())
 	}
 
-	viper.AutomaticEnv() // read in environment variables that match
+	err := loadProfiles()
+	if err == nil {
+		err = loadHighlights()
+	}
+	if err != nil {
+		if path := viper.ConfigFileUsed(); path != "" {
+			err = fmt.Errorf("%s: %w", path, err)
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// initLogging sets up the diagnostic logger from --log-level and --log-file
+func initLogging() {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(logLevel)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid --log-level %q (want debug, info, warn or error)\n", logLevel)
+		os.Exit(1)
+	}
+
+	out := os.Stderr
+	if logFile != "" {
+		f, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		out = f
+	}
+	logger = serial.NewTextLogger(out, level)
+
+	switch traceTo {
+	case "":
+	case "-":
+		traceOutput = os.Stderr
+	default:
+		f, err := os.OpenFile(traceTo, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		traceOutput = f
+	}
+}
 
-	// If a config file is found, read it in.
-	if err := viper.ReadInConfig(); err == nil {
-		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
+// withDiagnostics applies the global --log-level, --log-file and --trace
+// settings to a port
+func withDiagnostics() serial.Option {
+	return func(c *serial.Config) error {
+		if err := serial.WithLogger(logger)(c); err != nil {
+			return err
+		}
+		if traceOutput != nil {
+			return serial.WithTrace(traceOutput)(c)
+		}
+		return nil
 	}
 }
@@ -29,8 +29,9 @@ Examples:
   serial rts /dev/ttyUSB0 off
 
 Valid states: high, low, on, off, true, false, 1, 0`,
-	Args: cobra.ExactArgs(2),
+	Args: portArgs(2),
 	Run: func(cmd *cobra.Command, args []string) {
+		args = withProfilePort(args, 2)
 		portPath := args[0]
 		stateArg := args[1]
 
@@ -40,7 +41,7 @@ Valid states: high, low, on, off, true, false, 1, 0`,
 			os.Exit(1)
 		}
 
-		port, err := serial.Open(portPath)
+		port, err := serial.Open(portPath, withDiagnostics())
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error opening port: %v\n", err)
 			os.Exit(1)
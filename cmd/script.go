@@ -0,0 +1,533 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/signal"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/allbin/go-serial"
+	"github.com/charmbracelet/lipgloss"
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	// defaultScriptTimeout is how long expect steps wait unless the script says otherwise
+	defaultScriptTimeout = 2 * time.Second
+
+	// triggerWindowSize bounds the data a trigger searches for its pattern
+	triggerWindowSize = 4096
+)
+
+// sendScript is a --script file for send and connect: steps run in order
+// over one port open while triggers answer matching input in the background
+//
+//	timeout: 2s            # Default for expect steps
+//	triggers:
+//	  - on: "RING"
+//	    send: "ATA\r"
+//	  - name: login         # Shown when the trigger fires
+//	    on: "login: $"
+//	    send: "root\n"
+//	    once: true          # Fire the first time only
+//	steps:
+//	  - set-dtr: false
+//	  - delay: 100ms
+//	  - set-dtr: true
+//	  - break: 250ms
+//	  - send: "AT\r"
+//	  - expect: "OK\r\n"
+//	    timeout: 5s
+//	  - loop:
+//	      count: 3
+//	      steps:
+//	        - send-hex: "01 03 00 00 00 01"
+//	        - expect-hex: "01 03"
+type sendScript struct {
+	Timeout  time.Duration   `yaml:"timeout"`
+	Triggers []scriptTrigger `yaml:"triggers"`
+	Steps    []scriptStep    `yaml:"steps"`
+}
+
+// scriptStep holds exactly one action
+type scriptStep struct {
+	Send      *string        `yaml:"send"`
+	SendHex   *string        `yaml:"send-hex"`
+	Expect    *string        `yaml:"expect"`
+	ExpectHex *string        `yaml:"expect-hex"`
+	Delay     *time.Duration `yaml:"delay"`
+	Break     *time.Duration `yaml:"break"`
+	SetRTS    *bool          `yaml:"set-rts"`
+	SetDTR    *bool          `yaml:"set-dtr"`
+	Loop      *scriptLoop    `yaml:"loop"`
+	Timeout   time.Duration  `yaml:"timeout"` // Expect steps only
+
+	data  []byte                 // Resolved send payload
+	match func(rx []byte) int    // Resolved expectation: end of match in rx, or -1
+	desc  string                 // Step as shown in progress output
+	run   func(*scriptRun) error // Set by resolve
+}
+
+// scriptLoop repeats its steps count times, or until interrupted if count is 0
+type scriptLoop struct {
+	Count int          `yaml:"count"`
+	Steps []scriptStep `yaml:"steps"`
+}
+
+// scriptTrigger sends a response whenever received data matches a pattern,
+// or only the first time if Once is set
+type scriptTrigger struct {
+	Name    string  `yaml:"name"`
+	On      *string `yaml:"on"`
+	OnHex   *string `yaml:"on-hex"`
+	Send    *string `yaml:"send"`
+	SendHex *string `yaml:"send-hex"`
+	Once    bool    `yaml:"once"`
+
+	match  func(rx []byte) int // Resolved pattern: end of match in rx, or -1
+	data   []byte              // Resolved response
+	desc   string
+	window []byte // Received data not yet matched
+	fired  int    // Times the trigger has matched
+}
+
+// scriptRun is the state shared by the steps of one script run
+// Received data arrives on rxCh from a reader owned by the caller, so the
+// script can share the port with a live view of the traffic.
+type scriptRun struct {
+	ctx      context.Context
+	port     serial.Port
+	rxCh     <-chan []byte
+	rx       []byte // Received data not yet consumed by an expect step
+	triggers []*scriptTrigger
+
+	onSend    func(data []byte)                                 // Called before each write, if set
+	onStep    func(depth, i, total int, desc string, err error) // Called after each step
+	onTrigger func(t *scriptTrigger)                            // Called after a trigger's response is written, if set
+}
+
+// loadScript parses and validates a script file
+func loadScript(path string) (*sendScript, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var script sendScript
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(&script); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(script.Steps) == 0 && len(script.Triggers) == 0 {
+		return nil, fmt.Errorf("%s: no steps or triggers", path)
+	}
+	if script.Timeout <= 0 {
+		script.Timeout = defaultScriptTimeout
+	}
+
+	if err := resolveSteps(script.Steps, script.Timeout); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for i := range script.Triggers {
+		if err := script.Triggers[i].resolve(); err != nil {
+			return nil, fmt.Errorf("%s: trigger %d: %w", path, i+1, err)
+		}
+	}
+	return &script, nil
+}
+
+func resolveSteps(steps []scriptStep, defaultTimeout time.Duration) error {
+	for i := range steps {
+		if err := steps[i].resolve(defaultTimeout); err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// resolve checks that the step has one action and prepares it to run
+func (s *scriptStep) resolve(defaultTimeout time.Duration) error {
+	actions := 0
+	for _, set := range []bool{s.Send != nil, s.SendHex != nil, s.Expect != nil, s.ExpectHex != nil,
+		s.Delay != nil, s.Break != nil, s.SetRTS != nil, s.SetDTR != nil, s.Loop != nil} {
+		if set {
+			actions++
+		}
+	}
+	if actions != 1 {
+		return fmt.Errorf("want exactly one of send, send-hex, expect, expect-hex, delay, break, set-rts, set-dtr, loop; got %d", actions)
+	}
+	if s.Timeout <= 0 {
+		s.Timeout = defaultTimeout
+	}
+
+	switch {
+	case s.Send != nil:
+		s.data = []byte(*s.Send)
+		s.desc = fmt.Sprintf("send %q", *s.Send)
+		s.run = s.runSend
+	case s.SendHex != nil:
+		data, err := parseHexString(*s.SendHex)
+		if err != nil {
+			return err
+		}
+		s.data = []byte(data)
+		s.desc = fmt.Sprintf("send % X", s.data)
+		s.run = s.runSend
+	case s.Expect != nil:
+		match, err := regexpMatcher(*s.Expect)
+		if err != nil {
+			return err
+		}
+		s.match = match
+		s.desc = fmt.Sprintf("expect %q", *s.Expect)
+		s.run = s.runExpect
+	case s.ExpectHex != nil:
+		match, err := hexMatcher(*s.ExpectHex)
+		if err != nil {
+			return err
+		}
+		s.match = match
+		s.desc = fmt.Sprintf("expect %s", strings.ToUpper(*s.ExpectHex))
+		s.run = s.runExpect
+	case s.Delay != nil:
+		s.desc = fmt.Sprintf("delay %v", *s.Delay)
+		s.run = func(r *scriptRun) error { return r.wait(*s.Delay, nil) }
+	case s.Break != nil:
+		s.desc = fmt.Sprintf("break %v", *s.Break)
+		s.run = func(r *scriptRun) error { return serial.SendBreak(r.port, *s.Break) }
+	case s.SetRTS != nil:
+		s.desc = fmt.Sprintf("set-rts %v", *s.SetRTS)
+		s.run = func(r *scriptRun) error { return r.port.SetRTS(*s.SetRTS) }
+	case s.SetDTR != nil:
+		s.desc = fmt.Sprintf("set-dtr %v", *s.SetDTR)
+		s.run = func(r *scriptRun) error { return r.port.SetDTR(*s.SetDTR) }
+	case s.Loop != nil:
+		if s.Loop.Count < 0 {
+			return fmt.Errorf("invalid loop count %d", s.Loop.Count)
+		}
+		if len(s.Loop.Steps) == 0 {
+			return fmt.Errorf("loop has no steps")
+		}
+		if err := resolveSteps(s.Loop.Steps, defaultTimeout); err != nil {
+			return fmt.Errorf("loop: %w", err)
+		}
+		if s.Loop.Count == 0 {
+			s.desc = "loop until interrupted"
+		} else {
+			s.desc = fmt.Sprintf("loop %d times", s.Loop.Count)
+		}
+		s.run = s.runLoop
+	}
+	return nil
+}
+
+// resolve checks that the trigger has one pattern and one response
+func (t *scriptTrigger) resolve() error {
+	if (t.On == nil) == (t.OnHex == nil) {
+		return fmt.Errorf("want exactly one of on, on-hex")
+	}
+	if (t.Send == nil) == (t.SendHex == nil) {
+		return fmt.Errorf("want exactly one of send, send-hex")
+	}
+
+	var err error
+	var on string
+	if t.On != nil {
+		t.match, err = regexpMatcher(*t.On)
+		on = fmt.Sprintf("%q", *t.On)
+	} else {
+		t.match, err = hexMatcher(*t.OnHex)
+		on = strings.ToUpper(*t.OnHex)
+	}
+	if err != nil {
+		return err
+	}
+
+	if t.Send != nil {
+		t.data = []byte(*t.Send)
+		t.desc = fmt.Sprintf("on %s send %q", on, *t.Send)
+	} else {
+		data, err := parseHexString(*t.SendHex)
+		if err != nil {
+			return err
+		}
+		t.data = []byte(data)
+		t.desc = fmt.Sprintf("on %s send % X", on, t.data)
+	}
+	if t.Name != "" {
+		t.desc = t.Name
+	}
+	return nil
+}
+
+// regexpMatcher returns a matcher for the end of the first match of pattern
+func regexpMatcher(pattern string) (func(rx []byte) int, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return func(rx []byte) int {
+		if loc := re.FindIndex(rx); loc != nil {
+			return loc[1]
+		}
+		return -1
+	}, nil
+}
+
+// hexMatcher returns a matcher for the end of the first occurrence of the hex bytes
+func hexMatcher(hexStr string) (func(rx []byte) int, error) {
+	data, err := parseHexString(hexStr)
+	if err != nil {
+		return nil, err
+	}
+	want := []byte(data)
+	return func(rx []byte) int {
+		if i := bytes.Index(rx, want); i >= 0 {
+			return i + len(want)
+		}
+		return -1
+	}, nil
+}
+
+func (s *scriptStep) runSend(r *scriptRun) error {
+	return r.write(s.data)
+}
+
+// runExpect waits until the data received since the last expect step matches
+// Data up to the end of the match is consumed; anything after it is kept for
+// the next expect step.
+func (s *scriptStep) runExpect(r *scriptRun) error {
+	matched := func() bool {
+		if end := s.match(r.rx); end >= 0 {
+			r.rx = r.rx[end:]
+			return true
+		}
+		return false
+	}
+	err := r.wait(s.Timeout, matched)
+	if errors.Is(err, errNoMatch) {
+		return fmt.Errorf("%w within %v, received %q", errNoMatch, s.Timeout, r.rx)
+	}
+	return err
+}
+
+func (s *scriptStep) runLoop(r *scriptRun) error {
+	for i := 0; s.Loop.Count == 0 || i < s.Loop.Count; i++ {
+		if err := r.runSteps(s.Loop.Steps, 1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// write reports data to onSend and sends it; reporting first keeps fast
+// replies from being shown ahead of the data they answer
+func (r *scriptRun) write(data []byte) error {
+	if r.onSend != nil {
+		r.onSend(data)
+	}
+	_, err := r.port.WriteContext(r.ctx, data)
+	return err
+}
+
+// wait takes in received data for up to d, returning early once done reports
+// true; it returns errNoMatch if done never did, and nil after d if done is nil
+func (r *scriptRun) wait(d time.Duration, done func() bool) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	for {
+		if done != nil && done() {
+			return nil
+		}
+		select {
+		case data, ok := <-r.rxCh:
+			if !ok {
+				return serial.ErrPortClosed
+			}
+			if err := r.receive(data); err != nil {
+				return err
+			}
+		case <-timer.C:
+			if done == nil {
+				return nil
+			}
+			return errNoMatch
+		case <-r.ctx.Done():
+			return r.ctx.Err()
+		}
+	}
+}
+
+// receive buffers data for expect steps and answers any triggers it completes
+func (r *scriptRun) receive(data []byte) error {
+	r.rx = append(r.rx, data...)
+	for _, t := range r.triggers {
+		for range t.feed(data) {
+			if err := r.write(t.data); err != nil {
+				return fmt.Errorf("trigger %s: %w", t.desc, err)
+			}
+			if r.onTrigger != nil {
+				r.onTrigger(t)
+			}
+		}
+	}
+	return nil
+}
+
+// feed adds received data to the trigger's window and returns how many
+// times the trigger fires on it
+func (t *scriptTrigger) feed(data []byte) int {
+	if t.Once && t.fired > 0 {
+		return 0
+	}
+	t.window = append(t.window, data...)
+	n := 0
+	for !t.Once || n == 0 {
+		end := t.match(t.window)
+		if end < 0 {
+			break
+		}
+		t.window = t.window[end:]
+		n++
+	}
+	if over := len(t.window) - triggerWindowSize; over > 0 {
+		t.window = t.window[over:]
+	}
+	t.fired += n
+	return n
+}
+
+// firedText describes a trigger that has just fired
+func (t *scriptTrigger) firedText() string {
+	text := fmt.Sprintf("Trigger %s fired (%d)", t.desc, t.fired)
+	if t.Once {
+		text += ", now disarmed"
+	}
+	return text
+}
+
+// runSteps runs steps in order, stopping at the first failure
+func (r *scriptRun) runSteps(steps []scriptStep, depth int) error {
+	for i := range steps {
+		step := &steps[i]
+		err := step.run(r)
+		if r.onStep != nil && (step.Loop == nil || err == nil) {
+			r.onStep(depth, i, len(steps), step.desc, err)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// execute runs the script's steps, or only its triggers until the context
+// ends if it has no steps
+func (r *scriptRun) execute(script *sendScript) error {
+	for i := range script.Triggers {
+		t := script.Triggers[i]
+		r.triggers = append(r.triggers, &t)
+	}
+	if len(script.Steps) == 0 {
+		err := r.wait(time.Duration(math.MaxInt64), nil)
+		if errors.Is(err, context.Canceled) {
+			return nil
+		}
+		return err
+	}
+	return r.runSteps(script.Steps, 0)
+}
+
+// readInto sends data read from port to a channel until ctx ends or the
+// port fails; the channel is closed on return
+func readInto(ctx context.Context, port serial.Port) <-chan []byte {
+	ch := make(chan []byte, 64)
+	go func() {
+		defer close(ch)
+		slab := serial.NewReadSlab(readSlabSize)
+		for {
+			n, err := port.ReadContext(ctx, slab.Next(4096))
+			if n > 0 {
+				select {
+				case ch <- slab.Take(n):
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// runScript opens the port and runs the script's steps, stopping at the first failure
+func runScript(portPath string, script *sendScript, opts ...serial.Option) error {
+	infoStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("99")).
+		Bold(true)
+
+	successStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("40")).
+		Bold(true)
+
+	errorStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("196")).
+		Bold(true)
+
+	fmt.Printf("%s Opening %s...\n", infoStyle.Render("⚡"), portPath)
+
+	port, err := serial.Open(portPath, opts...)
+	if err != nil {
+		return fmt.Errorf("%s %v", errorStyle.Render("✗"), err)
+	}
+	defer port.Close()
+
+	fmt.Printf("%s Connected successfully\n", successStyle.Render("✓"))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	r := &scriptRun{
+		ctx:  ctx,
+		port: port,
+		rxCh: readInto(ctx, port),
+		onStep: func(depth, i, total int, desc string, err error) {
+			indent := strings.Repeat("  ", depth)
+			if err != nil {
+				fmt.Printf("%s%s [%d/%d] %s: %v\n", indent, errorStyle.Render("✗"), i+1, total, desc, err)
+				return
+			}
+			fmt.Printf("%s%s [%d/%d] %s\n", indent, successStyle.Render("✓"), i+1, total, desc)
+		},
+		onTrigger: func(t *scriptTrigger) {
+			fmt.Println(t.firedText())
+		},
+	}
+	if len(script.Steps) == 0 {
+		fmt.Printf("%s Answering %d triggers until Ctrl+C\n", infoStyle.Render("⚡"), len(script.Triggers))
+	}
+	if err := r.execute(script); err != nil {
+		if errors.Is(err, errNoMatch) {
+			return errNoMatch
+		}
+		return fmt.Errorf("script failed: %w", err)
+	}
+
+	fmt.Printf("%s Script completed\n", successStyle.Render("✓"))
+	return nil
+}
@@ -0,0 +1,365 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/allbin/go-serial"
+)
+
+// scriptPort records what a script writes and the lines it sets; any other
+// Port method panics on the nil embedded Port
+type scriptPort struct {
+	serial.Port
+	written  [][]byte
+	rts, dtr bool
+}
+
+func (p *scriptPort) WriteContext(ctx context.Context, data []byte) (int, error) {
+	p.written = append(p.written, append([]byte(nil), data...))
+	return len(data), nil
+}
+
+func (p *scriptPort) SetRTS(rts bool) error {
+	p.rts = rts
+	return nil
+}
+
+func (p *scriptPort) SetDTR(dtr bool) error {
+	p.dtr = dtr
+	return nil
+}
+
+func writeScript(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "script.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadScript(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string // Substring of the error, or empty for success
+	}{
+		{"steps", "steps:\n  - send: \"AT\\r\"\n  - expect: \"OK\"\n", ""},
+		{"triggers only", "triggers:\n  - on: RING\n    send: \"ATA\\r\"\n", ""},
+		{"empty", "timeout: 1s\n", "no steps or triggers"},
+		{"unknown field", "steps:\n  - sned: AT\n", "field sned not found"},
+		{"no action", "steps:\n  - timeout: 1s\n", "step 1: want exactly one of"},
+		{"two actions", "steps:\n  - send: AT\n    expect: OK\n", "step 1: want exactly one of"},
+		{"bad send-hex", "steps:\n  - send-hex: \"0\"\n", "step 1: hex string must have even length"},
+		{"bad expect", "steps:\n  - send: AT\n  - expect: \"(\"\n", "step 2: error parsing regexp"},
+		{"empty loop", "steps:\n  - loop:\n      count: 2\n", "step 1: loop has no steps"},
+		{"negative loop count", "steps:\n  - loop:\n      count: -1\n      steps:\n        - send: AT\n", "invalid loop count -1"},
+		{"bad loop step", "steps:\n  - loop:\n      steps:\n        - delay: 1s\n          break: 1s\n", "step 1: loop: step 1: want exactly one of"},
+		{"trigger without pattern", "triggers:\n  - send: ATA\n", "trigger 1: want exactly one of on, on-hex"},
+		{"trigger with two patterns", "triggers:\n  - on: RING\n    on-hex: \"52\"\n    send: ATA\n", "trigger 1: want exactly one of on, on-hex"},
+		{"trigger without response", "triggers:\n  - on: RING\n", "trigger 1: want exactly one of send, send-hex"},
+		{"bad trigger hex", "triggers:\n  - on-hex: \"zz\"\n    send: ATA\n", "trigger 1: invalid hex byte"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadScript(writeScript(t, tt.content))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("loadScript failed: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("loadScript error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadScriptTimeouts(t *testing.T) {
+	script, err := loadScript(writeScript(t, `
+steps:
+  - expect: "a"
+  - expect: "b"
+    timeout: 5s
+  - loop:
+      steps:
+        - expect: "c"
+`))
+	if err != nil {
+		t.Fatalf("loadScript failed: %v", err)
+	}
+	if script.Timeout != defaultScriptTimeout {
+		t.Errorf("script timeout = %v, want %v", script.Timeout, defaultScriptTimeout)
+	}
+	for i, want := range []time.Duration{defaultScriptTimeout, 5 * time.Second} {
+		if got := script.Steps[i].Timeout; got != want {
+			t.Errorf("step %d timeout = %v, want %v", i+1, got, want)
+		}
+	}
+	if got := script.Steps[2].Loop.Steps[0].Timeout; got != defaultScriptTimeout {
+		t.Errorf("loop step timeout = %v, want %v", got, defaultScriptTimeout)
+	}
+
+	script, err = loadScript(writeScript(t, "timeout: 300ms\nsteps:\n  - expect: a\n"))
+	if err != nil {
+		t.Fatalf("loadScript failed: %v", err)
+	}
+	if got := script.Steps[0].Timeout; got != 300*time.Millisecond {
+		t.Errorf("step timeout = %v, want the script's 300ms", got)
+	}
+}
+
+func TestResolveSteps(t *testing.T) {
+	str := func(s string) *string { return &s }
+	dur := func(d time.Duration) *time.Duration { return &d }
+	yes := true
+
+	tests := []struct {
+		name     string
+		step     scriptStep
+		wantDesc string
+		wantData []byte
+	}{
+		{"send", scriptStep{Send: str("AT\r")}, `send "AT\r"`, []byte("AT\r")},
+		{"send-hex", scriptStep{SendHex: str("0x01 0x03")}, "send 01 03", []byte{0x01, 0x03}},
+		{"expect", scriptStep{Expect: str("OK\r\n")}, `expect "OK\r\n"`, nil},
+		{"expect-hex", scriptStep{ExpectHex: str("01 ab")}, "expect 01 AB", nil},
+		{"delay", scriptStep{Delay: dur(100 * time.Millisecond)}, "delay 100ms", nil},
+		{"break", scriptStep{Break: dur(250 * time.Millisecond)}, "break 250ms", nil},
+		{"set-rts", scriptStep{SetRTS: &yes}, "set-rts true", nil},
+		{"set-dtr", scriptStep{SetDTR: &yes}, "set-dtr true", nil},
+		{"loop", scriptStep{Loop: &scriptLoop{Count: 3, Steps: []scriptStep{{Send: str("x")}}}}, "loop 3 times", nil},
+		{"endless loop", scriptStep{Loop: &scriptLoop{Steps: []scriptStep{{Send: str("x")}}}}, "loop until interrupted", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps := []scriptStep{tt.step}
+			if err := resolveSteps(steps, time.Second); err != nil {
+				t.Fatalf("resolveSteps failed: %v", err)
+			}
+			if steps[0].desc != tt.wantDesc {
+				t.Errorf("desc = %q, want %q", steps[0].desc, tt.wantDesc)
+			}
+			if !bytes.Equal(steps[0].data, tt.wantData) {
+				t.Errorf("data = % X, want % X", steps[0].data, tt.wantData)
+			}
+			if steps[0].run == nil {
+				t.Error("run not set")
+			}
+		})
+	}
+}
+
+func TestMatchers(t *testing.T) {
+	re, err := regexpMatcher(`OK\r?\n`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hex, err := hexMatcher("01 03")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		match func([]byte) int
+		rx    string
+		want  int
+	}{
+		{"regexp match", re, "AT\r\nOK\r\nrest", 8},
+		{"regexp no match", re, "AT\r\nERROR\r\n", -1},
+		{"hex match", hex, "\x00\x01\x03\x02", 3},
+		{"hex no match", hex, "\x01\x02\x03", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.match([]byte(tt.rx)); got != tt.want {
+				t.Errorf("match(%q) = %d, want %d", tt.rx, got, tt.want)
+			}
+		})
+	}
+}
+
+// newTestRun returns a scriptRun fed from the returned channel
+func newTestRun(t *testing.T) (*scriptRun, *scriptPort, chan []byte) {
+	t.Helper()
+	port := &scriptPort{}
+	rxCh := make(chan []byte, 16)
+	return &scriptRun{ctx: context.Background(), port: port, rxCh: rxCh}, port, rxCh
+}
+
+func resolvedStep(t *testing.T, step scriptStep, timeout time.Duration) *scriptStep {
+	t.Helper()
+	steps := []scriptStep{step}
+	if err := resolveSteps(steps, timeout); err != nil {
+		t.Fatalf("resolveSteps failed: %v", err)
+	}
+	return &steps[0]
+}
+
+func TestRunExpect(t *testing.T) {
+	pattern := "OK"
+	step := resolvedStep(t, scriptStep{Expect: &pattern}, time.Second)
+
+	r, _, rxCh := newTestRun(t)
+	rxCh <- []byte("AT\r\nO")
+	rxCh <- []byte("K\r\nnext")
+	if err := step.run(r); err != nil {
+		t.Fatalf("expect failed: %v", err)
+	}
+	if string(r.rx) != "\r\nnext" {
+		t.Errorf("rx after match = %q, want the data after OK", r.rx)
+	}
+
+	// The remainder satisfies the next expect step without new data
+	r.rx = []byte("OK")
+	if err := step.run(r); err != nil {
+		t.Fatalf("expect on buffered data failed: %v", err)
+	}
+}
+
+func TestRunExpectTimeout(t *testing.T) {
+	pattern := "OK"
+	step := resolvedStep(t, scriptStep{Expect: &pattern}, 50*time.Millisecond)
+
+	r, _, rxCh := newTestRun(t)
+	rxCh <- []byte("ERROR")
+	err := step.run(r)
+	if !errors.Is(err, errNoMatch) {
+		t.Fatalf("expect error = %v, want errNoMatch", err)
+	}
+	if !strings.Contains(err.Error(), `received "ERROR"`) {
+		t.Errorf("expect error = %q, want the received data", err)
+	}
+
+	close(rxCh)
+	if err := step.run(r); err != serial.ErrPortClosed {
+		t.Errorf("expect on closed channel error = %v, want ErrPortClosed", err)
+	}
+}
+
+func TestTriggerFeed(t *testing.T) {
+	on, send := "RING", "ATA\r"
+	trigger := scriptTrigger{On: &on, Send: &send}
+	if err := trigger.resolve(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A match split across reads fires once, repeats fire again
+	if n := trigger.feed([]byte("RI")); n != 0 {
+		t.Errorf("feed(RI) fired %d times, want 0", n)
+	}
+	if n := trigger.feed([]byte("NG\r\nRING\r\nRING")); n != 3 {
+		t.Errorf("feed fired %d times, want 3", n)
+	}
+	if trigger.fired != 3 {
+		t.Errorf("fired = %d, want 3", trigger.fired)
+	}
+
+	// The window keeps only the most recent data
+	trigger.feed(bytes.Repeat([]byte("x"), 2*triggerWindowSize))
+	if len(trigger.window) != triggerWindowSize {
+		t.Errorf("window holds %d bytes, want %d", len(trigger.window), triggerWindowSize)
+	}
+
+	once := scriptTrigger{Name: "answer", On: &on, Send: &send, Once: true}
+	if err := once.resolve(); err != nil {
+		t.Fatal(err)
+	}
+	if n := once.feed([]byte("RING RING")); n != 1 {
+		t.Errorf("once trigger fired %d times, want 1", n)
+	}
+	if n := once.feed([]byte("RING")); n != 0 {
+		t.Errorf("disarmed trigger fired %d times, want 0", n)
+	}
+	if got, want := once.firedText(), "Trigger answer fired (1), now disarmed"; got != want {
+		t.Errorf("firedText() = %q, want %q", got, want)
+	}
+}
+
+func TestExecute(t *testing.T) {
+	script, err := loadScript(writeScript(t, `
+timeout: 1s
+triggers:
+  - on-hex: "05"
+    send-hex: "06"
+steps:
+  - set-rts: true
+  - loop:
+      count: 2
+      steps:
+        - send: "AT\r"
+        - expect: "OK"
+`))
+	if err != nil {
+		t.Fatalf("loadScript failed: %v", err)
+	}
+
+	r, port, rxCh := newTestRun(t)
+	var triggered []string
+	r.onTrigger = func(t *scriptTrigger) { triggered = append(triggered, t.firedText()) }
+	var steps []string
+	r.onStep = func(depth, i, total int, desc string, err error) {
+		steps = append(steps, strings.Repeat(" ", depth)+desc)
+	}
+
+	rxCh <- []byte("\x05OK")
+	rxCh <- []byte("OK")
+	if err := r.execute(script); err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+
+	if !port.rts {
+		t.Error("RTS not set")
+	}
+	var written []string
+	for _, w := range port.written {
+		written = append(written, string(w))
+	}
+	if got, want := strings.Join(written, "|"), "AT\r|\x06|AT\r"; got != want {
+		t.Errorf("written = %q, want %q", got, want)
+	}
+	if len(triggered) != 1 || triggered[0] != "Trigger on 05 send 06 fired (1)" {
+		t.Errorf("triggered = %q, want one firing", triggered)
+	}
+	wantSteps := []string{"set-rts true", ` send "AT\r"`, ` expect "OK"`, ` send "AT\r"`, ` expect "OK"`, "loop 2 times"}
+	if strings.Join(steps, ",") != strings.Join(wantSteps, ",") {
+		t.Errorf("steps = %q, want %q", steps, wantSteps)
+	}
+
+	// execute works on copies, so the script's triggers are unchanged
+	if script.Triggers[0].fired != 0 {
+		t.Errorf("script trigger fired = %d, want 0", script.Triggers[0].fired)
+	}
+}
+
+func TestExecuteTriggersOnly(t *testing.T) {
+	script, err := loadScript(writeScript(t, "triggers:\n  - on: ping\n    send: pong\n"))
+	if err != nil {
+		t.Fatalf("loadScript failed: %v", err)
+	}
+
+	r, port, rxCh := newTestRun(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	r.ctx = ctx
+	r.onTrigger = func(*scriptTrigger) { cancel() }
+
+	rxCh <- []byte("ping")
+	if err := r.execute(script); err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+	if len(port.written) != 1 || string(port.written[0]) != "pong" {
+		t.Errorf("written = %q, want pong", port.written)
+	}
+}
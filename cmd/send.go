@@ -5,11 +5,16 @@ package cmd
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
+	"regexp"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/allbin/go-serial"
@@ -27,58 +32,124 @@ This command sends data to the specified serial port. Data can be provided as:
 - Command line argument: send "Hello World" /dev/ttyUSB0
 - From stdin (pipe): echo "test data" | serial send /dev/ttyUSB0
 - Interactive mode: serial send /dev/ttyUSB0 (prompts for input)
+- File: serial send --file firmware.bin /dev/ttyUSB0 (--file - for stdin)
+
+--line-ending terminates text data (argument, stdin or interactive) with
+LF, CR or CRLF. Line breaks inside multi-line stdin data are converted to
+the same terminator. Trailing newlines are trimmed from stdin before the
+terminator is added unless --no-trim is given. Hex data is never modified.
+
+--repeat sends the same data several times over one open port, --interval
+apart, so polling or stress-testing a device does not reopen the port (and
+re-toggle DTR) for every message. --repeat 0 repeats until Ctrl+C.
+
+--expect waits after each write for a response matching a regular
+expression, or containing the bytes given as hex:<hex>, and prints it. The
+command exits with status 2 when no matching response arrives within
+--response-timeout and 1 on other errors, so it can serve as a scripted
+health check.
+
+--script runs ordered steps from a YAML file over one port open, for
+provisioning flows such as entering a bootloader, sending configuration
+and verifying the reply:
+
+  timeout: 2s              # Default wait for expect steps
+  steps:
+    - set-dtr: false       # Also set-rts
+    - delay: 100ms
+    - set-dtr: true
+    - break: 250ms
+    - send: "AT\r"         # Sent verbatim; use YAML escapes for CR/LF
+    - expect: "OK\r\n"     # Regular expression; timeout: overrides the default
+    - send-hex: "01 03 00 00 00 01"
+    - expect-hex: "01 03"
+    - loop:                # count: 0 loops until Ctrl+C
+        count: 3
+        steps:
+          - send: "AT+CSQ\r"
+          - expect: "OK"
+
+An expect step matches data received since the previous expect step. A
+triggers list (see "serial connect --help") answers matching input while
+the steps run. The script stops at the first failing step; the exit
+status is 2 if an expect step timed out and 1 for other failures.
+
+--file sends bytes exactly as stored, with no hex, newline or trimming
+applied. It writes --chunk-size bytes at a time, pausing --chunk-delay
+between chunks for devices without flow control, and shows a progress bar
+on terminals. --timeout applies to each chunk.
 
 Features include:
 - Multiple input methods (argument, stdin, interactive)
 - Configurable baud rate and flow control
-- Automatic line endings (--newline flag)
+- Selectable line endings (--line-ending none|lf|cr|crlf, --newline for lf)
 - Hex input support (--hex flag)
 - Connection status feedback with styled output
 
 Example usage:
   serial send "Hello World" /dev/ttyUSB0
   serial send "AT+GMR" /dev/ttyUSB0 --newline
+  serial send "ATZ" /dev/ttyUSB0 --line-ending cr
+  cat commands.txt | serial send /dev/ttyUSB0 --line-ending crlf
   echo "test" | serial send /dev/ttyUSB0
-  serial send /dev/ttyUSB0  # Interactive mode`,
-	Args: cobra.MinimumNArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		var data string
-		var portPath string
-
-		// Parse arguments: either "send data port" or "send port"
-		if len(args) == 1 {
-			portPath = args[0]
-			// Check if we have stdin data
-			stat, err := os.Stdin.Stat()
-			if err != nil || (stat.Mode()&os.ModeCharDevice) != 0 {
-				// No pipe input, use interactive mode
-				data = promptForData()
-			} else {
-				// Read from stdin
-				stdinData, err := io.ReadAll(os.Stdin)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error reading from stdin: %v\n", err)
-					os.Exit(1)
-				}
-				data = strings.TrimRight(string(stdinData), "\r\n")
-			}
-		} else {
-			data = args[0]
-			portPath = args[1]
+  serial send /dev/ttyUSB0  # Interactive mode
+  serial send "?" /dev/ttyUSB0 --repeat 0 --interval 500ms  # Poll until Ctrl+C
+  serial send "AT" /dev/ttyUSB0 --newline --expect 'OK\r\n' --response-timeout 1s
+  serial send 010300000001840A /dev/ttyUSB0 --hex --expect hex:0103
+  serial send --script provision.yaml /dev/ttyUSB0
+  serial send --file config.bin /dev/ttyUSB0 --chunk-size 64 --chunk-delay 20ms
+  cat image.bin | serial send --file - /dev/ttyUSB0`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if profilePort() != "" {
+			return nil
 		}
-
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
 		// Get flags
 		baudRate, _ := cmd.Flags().GetInt("baud")
 		flowControl, _ := cmd.Flags().GetString("flow-control")
 		addNewline, _ := cmd.Flags().GetBool("newline")
+		lineEndingName, _ := cmd.Flags().GetString("line-ending")
+		noTrim, _ := cmd.Flags().GetBool("no-trim")
 		hexMode, _ := cmd.Flags().GetBool("hex")
 		timeout, _ := cmd.Flags().GetDuration("timeout")
 		initialRTS, _ := cmd.Flags().GetBool("initial-rts")
+		filePath, _ := cmd.Flags().GetString("file")
+		chunkSize, _ := cmd.Flags().GetInt("chunk-size")
+		chunkDelay, _ := cmd.Flags().GetDuration("chunk-delay")
+		scriptPath, _ := cmd.Flags().GetString("script")
+		repeat, _ := cmd.Flags().GetInt("repeat")
+		interval, _ := cmd.Flags().GetDuration("interval")
+		expectPattern, _ := cmd.Flags().GetString("expect")
+		responseTimeout, _ := cmd.Flags().GetDuration("response-timeout")
+
+		// The profile's port stands in for the port argument; a lone argument
+		// is then the data unless it names a port
+		if port := profilePort(); port != "" {
+			lone := len(args) == 1 && scriptPath == "" && filePath == "" && !isPortPath(args[0])
+			if len(args) == 0 || lone {
+				args = append(args, port)
+			}
+		}
+
+		if repeat < 0 {
+			fmt.Fprintf(os.Stderr, "Error: --repeat must not be negative\n")
+			os.Exit(1)
+		}
 
 		// Configure port options
 		opts := []serial.Option{
 			serial.WithBaudRate(baudRate),
+			withDiagnostics(),
+		}
+
+		lineOpts, err := lineOptions(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
+		opts = append(opts, lineOpts...)
 
 		switch strings.ToLower(flowControl) {
 		case "cts":
@@ -93,6 +164,96 @@ Example usage:
 			}
 		}
 
+		expect := sendExpect{timeout: responseTimeout, hex: hexMode}
+		if expectPattern != "" {
+			expect.match, err = parseExpect(expectPattern)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		// Poll often enough for response timeouts to be honoured
+		if (expectPattern != "" || scriptPath != "") && !cmd.Flags().Changed("read-timeout") {
+			opts = append(opts, serial.WithReadTimeout(100*time.Millisecond))
+		}
+
+		// Run a scripted sequence
+		if scriptPath != "" {
+			if len(args) != 1 {
+				fmt.Fprintf(os.Stderr, "Error: --script takes the port as the only argument\n")
+				os.Exit(1)
+			}
+			script, err := loadScript(scriptPath)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			err = runScript(args[0], script, opts...)
+			if errors.Is(err, errNoMatch) {
+				os.Exit(2)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		// Stream a file unmodified
+		if filePath != "" {
+			if len(args) != 1 {
+				fmt.Fprintf(os.Stderr, "Error: --file takes the port as the only argument\n")
+				os.Exit(1)
+			}
+			if chunkSize < 1 {
+				fmt.Fprintf(os.Stderr, "Error: --chunk-size must be positive\n")
+				os.Exit(1)
+			}
+			if err := sendFile(args[0], filePath, chunkSize, chunkDelay, timeout, opts...); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		// --newline is shorthand for --line-ending lf
+		if addNewline && !cmd.Flags().Changed("line-ending") {
+			lineEndingName = "lf"
+		}
+		lineEnding, ok := lineEndings[strings.ToLower(lineEndingName)]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Error: unknown line ending %q (want none, lf, cr or crlf)\n", lineEndingName)
+			os.Exit(1)
+		}
+
+		var data string
+		var portPath string
+
+		// Parse arguments: either "send data port" or "send port"
+		if len(args) == 1 {
+			portPath = args[0]
+			// Check if we have stdin data
+			stat, err := os.Stdin.Stat()
+			if err != nil || (stat.Mode()&os.ModeCharDevice) != 0 {
+				// No pipe input, use interactive mode
+				data = promptForData()
+			} else {
+				// Read from stdin
+				stdinData, err := io.ReadAll(os.Stdin)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error reading from stdin: %v\n", err)
+					os.Exit(1)
+				}
+				data = string(stdinData)
+				if !noTrim {
+					data = strings.TrimRight(data, "\r\n")
+				}
+			}
+		} else {
+			data = args[0]
+			portPath = args[1]
+		}
+
 		// Process data based on flags
 		if hexMode {
 			processedData, err := parseHexString(data)
@@ -103,12 +264,16 @@ Example usage:
 			data = processedData
 		}
 
-		if addNewline && !hexMode {
-			data += "\n"
+		if !hexMode {
+			data = applyLineEnding(data, lineEnding)
 		}
 
 		// Send the data
-		if err := sendData(portPath, data, timeout, opts...); err != nil {
+		err = sendData(portPath, data, timeout, repeat, interval, expect, opts...)
+		if errors.Is(err, errNoMatch) {
+			os.Exit(2)
+		}
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
@@ -120,11 +285,43 @@ func init() {
 
 	// Add flags for serial configuration and send options
 	sendCmd.Flags().IntP("baud", "b", 115200, "Baud rate (default: 115200)")
+	addLineFlags(sendCmd, 2500*time.Millisecond)
 	sendCmd.Flags().StringP("flow-control", "f", "none", "Flow control: none, cts, rtscts (default: none)")
-	sendCmd.Flags().BoolP("newline", "n", false, "Add newline character to the end of data")
+	sendCmd.Flags().BoolP("newline", "n", false, "Add newline character to the end of data (same as --line-ending lf)")
+	sendCmd.Flags().String("line-ending", "none", "Line terminator for text data: none, lf, cr, crlf")
+	sendCmd.Flags().Bool("no-trim", false, "Keep trailing newlines and whitespace from stdin")
 	sendCmd.Flags().BoolP("hex", "x", false, "Interpret data as hexadecimal (e.g., '48656c6c6f' for 'Hello')")
 	sendCmd.Flags().DurationP("timeout", "t", 5*time.Second, "Timeout for sending data (default: 5s)")
 	sendCmd.Flags().Bool("initial-rts", false, "Assert RTS on port open (required for CTS flow control)")
+	sendCmd.Flags().Int("repeat", 1, "Send the data this many times over one connection (0 = until Ctrl+C)")
+	sendCmd.Flags().Duration("interval", time.Second, "Pause between repeated sends")
+	sendCmd.Flags().String("expect", "", "Wait for a response matching this regular expression (or hex:0D0A for bytes)")
+	sendCmd.Flags().Duration("response-timeout", 2*time.Second, "How long to wait for the --expect response")
+	sendCmd.Flags().String("script", "", "Run the send/expect/delay/signal/break steps in a YAML script")
+	sendCmd.Flags().String("file", "", "Send the contents of a file unmodified (- for binary stdin)")
+	sendCmd.Flags().Int("chunk-size", 4096, "Bytes written at a time with --file")
+	sendCmd.Flags().Duration("chunk-delay", 0, "Pause between chunks with --file (e.g., 10ms)")
+}
+
+// lineEndings maps --line-ending names to terminators
+var lineEndings = map[string]string{
+	"none": "",
+	"lf":   "\n",
+	"cr":   "\r",
+	"crlf": "\r\n",
+}
+
+// lineEndingNames lists the lineEndings keys in the order connect cycles them
+var lineEndingNames = []string{"none", "lf", "cr", "crlf"}
+
+// applyLineEnding terminates each line of data with ending, converting the
+// LF or CRLF breaks within it
+func applyLineEnding(data, ending string) string {
+	if ending == "" {
+		return data
+	}
+	lines := strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n")
+	return strings.Join(lines, ending) + ending
 }
 
 func promptForData() string {
@@ -165,7 +362,47 @@ func parseHexString(hexStr string) (string, error) {
 	return result.String(), nil
 }
 
-func sendData(portPath, data string, timeout time.Duration, opts ...serial.Option) error {
+// errNoMatch is returned when --expect saw no matching response; the command
+// exits with status 2
+var errNoMatch = errors.New("no matching response")
+
+// sendExpect is the response each send waits for with --expect
+type sendExpect struct {
+	match   serial.Matcher // nil = do not wait for a response
+	timeout time.Duration
+	hex     bool // Print responses as hex
+}
+
+// parseExpect builds the --expect matcher: a regular expression, or "hex:"
+// followed by bytes the response must contain
+func parseExpect(expect string) (serial.Matcher, error) {
+	if h, ok := strings.CutPrefix(expect, "hex:"); ok {
+		want, err := parseHexString(h)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --expect hex: %v", err)
+		}
+		return func(resp []byte) bool {
+			return bytes.Contains(resp, []byte(want))
+		}, nil
+	}
+	re, err := regexp.Compile(expect)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --expect pattern: %v", err)
+	}
+	return serial.MatchRegexp(re), nil
+}
+
+func (e sendExpect) format(resp []byte) string {
+	if e.hex {
+		return fmt.Sprintf("% X", resp)
+	}
+	return fmt.Sprintf("%q", resp)
+}
+
+// sendData writes data repeat times (0 = until interrupted), interval apart,
+// over a single port open, waiting for a response after each write if
+// expect is set
+func sendData(portPath, data string, timeout time.Duration, repeat int, interval time.Duration, expect sendExpect, opts ...serial.Option) error {
 	// Styled output
 	infoStyle := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("99")).
@@ -191,19 +428,58 @@ func sendData(portPath, data string, timeout time.Duration, opts ...serial.Optio
 
 	fmt.Printf("%s Connected successfully\n", successStyle.Render("✓"))
 
-	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
+	// Stop repeating on Ctrl+C
+	sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	for i := 1; repeat == 0 || i <= repeat; i++ {
+		if i > 1 {
+			select {
+			case <-time.After(interval):
+			case <-sigCtx.Done():
+				fmt.Printf("%s Stopped after %d sends\n", infoStyle.Render("⏹"), i-1)
+				return nil
+			}
+		}
+
+		// Create context with timeout
+		ctx, cancel := context.WithTimeout(sigCtx, timeout+expect.timeout)
 
-	// Send data
-	fmt.Printf("%s Sending %d bytes...\n", infoStyle.Render("📤"), len(data))
+		// Send data
+		if i == 1 {
+			fmt.Printf("%s Sending %d bytes...\n", infoStyle.Render("📤"), len(data))
+		}
 
-	n, err := port.WriteContext(ctx, []byte(data))
-	if err != nil {
-		return fmt.Errorf("%s failed to send data: %v", errorStyle.Render("✗"), err)
-	}
+		var n int
+		var resp []byte
+		if expect.match != nil {
+			resp, err = serial.Transact(ctx, port, []byte(data), expect.match, 0, expect.timeout)
+			n = len(data)
+		} else {
+			n, err = port.WriteContext(ctx, []byte(data))
+		}
+		cancel()
+		if errors.Is(err, serial.ErrReadTimeout) {
+			fmt.Printf("%s No matching response within %v, received %d bytes: %s\n",
+				errorStyle.Render("✗"), expect.timeout, len(resp), expect.format(resp))
+			return errNoMatch
+		}
+		if err != nil {
+			return fmt.Errorf("%s failed to send data: %v", errorStyle.Render("✗"), err)
+		}
 
-	fmt.Printf("%s Successfully sent %d bytes\n", successStyle.Render("✓"), n)
+		switch {
+		case repeat == 1:
+			fmt.Printf("%s Successfully sent %d bytes\n", successStyle.Render("✓"), n)
+		case repeat == 0:
+			fmt.Printf("%s [%d] Sent %d bytes\n", successStyle.Render("✓"), i, n)
+		default:
+			fmt.Printf("%s [%d/%d] Sent %d bytes\n", successStyle.Render("✓"), i, repeat, n)
+		}
+		if expect.match != nil {
+			fmt.Printf("%s Response: %s\n", successStyle.Render("📥"), expect.format(resp))
+		}
+	}
 
 	// Show data preview (first 50 chars)
 	preview := data
@@ -222,3 +498,131 @@ func sendData(portPath, data string, timeout time.Duration, opts ...serial.Optio
 
 	return nil
 }
+
+// sendFile streams a file, or stdin for "-", to the port unmodified
+func sendFile(portPath, filePath string, chunkSize int, chunkDelay, timeout time.Duration, opts ...serial.Option) error {
+	infoStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("99")).
+		Bold(true)
+
+	successStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("40")).
+		Bold(true)
+
+	errorStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("196")).
+		Bold(true)
+
+	var r io.Reader = os.Stdin
+	total := int64(-1) // Unknown for pipes
+	if filePath != "-" {
+		f, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if stat, err := f.Stat(); err == nil && stat.Mode().IsRegular() {
+			total = stat.Size()
+		}
+		r = f
+	}
+
+	fmt.Printf("%s Opening %s...\n", infoStyle.Render("⚡"), portPath)
+
+	port, err := serial.Open(portPath, opts...)
+	if err != nil {
+		return fmt.Errorf("%s %v", errorStyle.Render("✗"), err)
+	}
+	defer port.Close()
+
+	fmt.Printf("%s Connected successfully\n", successStyle.Render("✓"))
+
+	progress := newSendProgress(total)
+	buf := make([]byte, chunkSize)
+	var sent int64
+	for {
+		n, rerr := io.ReadFull(r, buf)
+		if n > 0 {
+			if sent > 0 && chunkDelay > 0 {
+				time.Sleep(chunkDelay)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			written, err := port.WriteContext(ctx, buf[:n])
+			cancel()
+			sent += int64(written)
+			progress.update(sent)
+			if err != nil {
+				progress.finish()
+				return fmt.Errorf("%s failed after %d bytes: %v", errorStyle.Render("✗"), sent, err)
+			}
+		}
+		if rerr == io.EOF || rerr == io.ErrUnexpectedEOF {
+			break
+		}
+		if rerr != nil {
+			progress.finish()
+			return fmt.Errorf("%s read error after %d bytes: %v", errorStyle.Render("✗"), sent, rerr)
+		}
+	}
+
+	// Wait until the device has the last chunk before reporting success
+	if err := port.DrainOutput(); err != nil {
+		progress.finish()
+		return fmt.Errorf("%s failed to drain output: %v", errorStyle.Render("✗"), err)
+	}
+	progress.finish()
+
+	fmt.Printf("%s Successfully sent %d bytes in %v\n", successStyle.Render("✓"), sent, time.Since(progress.start).Round(time.Millisecond))
+	return nil
+}
+
+// sendProgress draws a progress bar for --file on a terminal
+type sendProgress struct {
+	icon  string
+	total int64 // -1 when the size is unknown
+	start time.Time
+	drawn time.Time
+	tty   bool
+	sent  int64
+}
+
+func newSendProgress(total int64) *sendProgress {
+	stat, err := os.Stdout.Stat()
+	return &sendProgress{
+		icon:  "📤",
+		total: total,
+		start: time.Now(),
+		tty:   err == nil && stat.Mode()&os.ModeCharDevice != 0,
+	}
+}
+
+// update redraws the bar at most ten times a second
+func (p *sendProgress) update(sent int64) {
+	p.sent = sent
+	if !p.tty || time.Since(p.drawn) < 100*time.Millisecond {
+		return
+	}
+	p.drawn = time.Now()
+	fmt.Printf("\r%s", p.render())
+}
+
+// finish draws the final state and ends the progress line
+func (p *sendProgress) finish() {
+	if p.tty {
+		fmt.Printf("\r%s\n", p.render())
+	}
+}
+
+func (p *sendProgress) render() string {
+	barStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("99"))
+
+	rate := float64(p.sent) / 1024 / time.Since(p.start).Seconds()
+	if p.total <= 0 {
+		return fmt.Sprintf("%s %d bytes  %.1f KB/s", p.icon, p.sent, rate)
+	}
+
+	const width = 30
+	filled := int(p.sent * width / p.total)
+	bar := barStyle.Render(strings.Repeat("█", filled)) + strings.Repeat("░", width-filled)
+	return fmt.Sprintf("%s %s %3d%%  %d/%d bytes  %.1f KB/s", p.icon, bar, p.sent*100/p.total, p.sent, p.total, rate)
+}
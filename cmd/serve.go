@@ -0,0 +1,139 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/allbin/go-serial"
+	"github.com/allbin/go-serial/bridge"
+	"github.com/allbin/go-serial/metrics"
+	"github.com/allbin/go-serial/ser2net"
+	"github.com/spf13/cobra"
+)
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve serial ports over TCP from a ser2net configuration",
+	Long: `Serve every connection in a ser2net-style YAML configuration.
+
+Each connection listens on its accepter's TCP port and bridges clients to
+its serial device. Ports are reopened automatically when they fail.
+
+Supported ser2net settings:
+  accepter   tcp,<port> or tcp,<host>,<port> (telnet/RFC 2217 is not supported)
+  connector  serialdev,<device>,<baud><parity><databits><stopbits>,[-]rtscts,[-]local
+  enable     on/off
+  options    banner, kickolduser, max-connections
+
+Additional options:
+  initial-rts      Assert RTS on open
+  usb-reset        Reset the USB device after repeated open failures (needs root)
+  reconnect-delay  Wait before reopening a failed port (e.g. 2s)
+
+--metrics exposes Prometheus metrics for every connection, labelled with
+the connection name, on http://<addr>/metrics.
+
+Example usage:
+  serial serve --config /etc/ser2net.yaml
+  serial serve --config ser2net.yaml --check
+  serial serve --config /etc/ser2net.yaml --metrics :9100`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get flags
+		configPath, _ := cmd.Flags().GetString("config")
+		check, _ := cmd.Flags().GetBool("check")
+		metricsAddr, _ := cmd.Flags().GetString("metrics")
+
+		f, err := os.Open(configPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		conns, err := ser2net.Parse(f)
+		f.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		printConnections(conns)
+		if check {
+			return
+		}
+
+		reg, err := startMetrics(metricsAddr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if err := runServe(conns, reg); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringP("config", "c", "/etc/ser2net.yaml", "ser2net YAML configuration file")
+	serveCmd.Flags().Bool("check", false, "Validate the configuration and exit")
+	serveCmd.Flags().String("metrics", "", "Serve Prometheus metrics on this address (e.g., :9100)")
+}
+
+func printConnections(conns []ser2net.Connection) {
+	for _, c := range conns {
+		state := ""
+		if !c.Enabled {
+			state = " (disabled)"
+		}
+		fmt.Fprintf(os.Stderr, "%-12s %-16s %s %s%s\n", c.Name, c.Addr, c.Device, c.SerialParams(), state)
+		if len(c.Ignored) > 0 {
+			fmt.Fprintf(os.Stderr, "%-12s warning: ignoring connector options %s\n", "", strings.Join(c.Ignored, ", "))
+		}
+	}
+	fmt.Fprintln(os.Stderr)
+}
+
+func runServe(conns []ser2net.Connection, reg *metrics.Registry) error {
+	// Setup signal handling for clean shutdown
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	portOptions := func(name string) []serial.Option {
+		opts := []serial.Option{withDiagnostics()}
+		if reg != nil {
+			opts = append(opts, serial.WithInstrumentation(reg.Instrumentation("connection", name)))
+		}
+		return opts
+	}
+
+	fmt.Fprintf(os.Stderr, "Press Ctrl+C to stop\n\n")
+	return ser2net.Serve(ctx, conns, ser2net.WithPortOptions(portOptions), ser2net.WithEventHandler(func(name string, ev bridge.Event) {
+		if reg != nil {
+			reg.BridgeEvents("connection", name)(ev)
+		}
+
+		timestamp := time.Now().Format("15:04:05")
+		switch {
+		case ev.Addr != "" && ev.Err != nil:
+			fmt.Fprintf(os.Stderr, "[%s] %s: %s %s: %v\n", timestamp, name, ev.Kind, ev.Addr, ev.Err)
+		case ev.Addr != "":
+			fmt.Fprintf(os.Stderr, "[%s] %s: %s %s\n", timestamp, name, ev.Kind, ev.Addr)
+		case ev.Err != nil:
+			fmt.Fprintf(os.Stderr, "[%s] %s: %s: %v\n", timestamp, name, ev.Kind, ev.Err)
+		default:
+			fmt.Fprintf(os.Stderr, "[%s] %s: %s\n", timestamp, name, ev.Kind)
+		}
+	}))
+}
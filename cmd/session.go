@@ -0,0 +1,87 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/allbin/go-serial"
+	"github.com/allbin/go-serial/session"
+)
+
+// sessionFile records port traffic and modem signal transitions for --session
+// A nil *sessionFile records nothing.
+type sessionFile struct {
+	f   *os.File
+	rec *session.Recorder
+}
+
+// createSession creates a session recording at path, or returns nil if path is empty
+func createSession(path string) (*sessionFile, error) {
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session file: %w", err)
+	}
+	rec, err := session.NewRecorder(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to write session header: %w", err)
+	}
+	return &sessionFile{f: f, rec: rec}, nil
+}
+
+// wrap returns port with its traffic recorded to the session
+// The modem inputs are watched until the port is closed, so signal
+// transitions are recorded even when nothing else queries them.
+func (s *sessionFile) wrap(port serial.Port) serial.Port {
+	if s == nil {
+		return port
+	}
+	recorded := s.record(port)
+	go watchSignals(recorded)
+	return recorded
+}
+
+// record returns port with its traffic and the signal states queried
+// through it recorded to the session, for callers that watch the signals
+// themselves
+func (s *sessionFile) record(port serial.Port) serial.Port {
+	if s == nil {
+		return port
+	}
+	return session.Record(port, s.rec)
+}
+
+// Close flushes the recording and closes the file
+func (s *sessionFile) Close() error {
+	if s == nil {
+		return nil
+	}
+	err := s.rec.Flush()
+	if cerr := s.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// watchSignals queries the modem state, then waits for input line changes
+// until the port fails; the recording port records each state it sees
+func watchSignals(port serial.Port) {
+	if _, err := port.GetModemSignals(); err != nil {
+		logger.Debug("modem signals not recorded", "err", err)
+		return
+	}
+	mask := serial.SignalCTS | serial.SignalDSR | serial.SignalRI | serial.SignalDCD
+	for {
+		if _, _, err := port.WaitForSignalChangeContext(context.Background(), mask); err != nil {
+			logger.Debug("stopped recording modem signals", "err", err)
+			return
+		}
+	}
+}
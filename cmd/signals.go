@@ -30,11 +30,12 @@ Signal meanings:
   DCD - Data Carrier Detect (input)
   RTS - Request To Send (output)
   DTR - Data Terminal Ready (output)`,
-	Args: cobra.ExactArgs(1),
+	Args: portArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		args = withProfilePort(args, 1)
 		portPath := args[0]
 
-		port, err := serial.Open(portPath)
+		port, err := serial.Open(portPath, withDiagnostics())
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error opening port: %v\n", err)
 			os.Exit(1)
@@ -0,0 +1,351 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/allbin/go-serial"
+	"github.com/allbin/go-serial/internal/tui/components"
+	"github.com/allbin/go-serial/internal/tui/keys"
+	"github.com/allbin/go-serial/internal/tui/models"
+	"github.com/allbin/go-serial/session"
+	"github.com/charmbracelet/bubbles/help"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+// sniffCmd represents the sniff command
+var sniffCmd = &cobra.Command{
+	Use:   "sniff <port-a> <port-b>",
+	Short: "Monitor both directions of a link through a tap",
+	Long: `Monitor both directions of a serial link passively, through a Y-cable or
+tap that feeds each direction to the RX line of its own adapter.
+
+<port-a> receives what side A sends and <port-b> what side B sends. Both
+streams are merged in the order they arrive, each chunk labeled with its
+side and stamped to the microsecond by the same clock, so the timing of
+requests and responses can be read off directly. Nothing is ever written
+to either port.
+
+The labels default to the port names; --labels names the sides instead,
+such as --labels host,meter. Side A is shown as ↗ and side B as ↙.
+
+The TUI offers the keys of serial listen: search (/), filter (f), time
+modes (d, m), export (w) and counters (z). --plain prints to stdout
+instead, and --output also appends every chunk to a file in the connect
+--log format, with the side in place of RX/TX. --pcapng also writes a
+pcapng file for Wireshark, side A as outbound and side B as inbound
+packets (link type DLT_USER0).
+
+Example usage:
+  serial sniff /dev/ttyUSB0 /dev/ttyUSB1
+  serial sniff /dev/ttyUSB0 /dev/ttyUSB1 --baud 9600 --labels master,slave
+  serial sniff /dev/ttyUSB0 /dev/ttyUSB1 --output link.log --pcapng link.pcapng
+  serial sniff /dev/ttyUSB0 /dev/ttyUSB1 --plain --no-timestamps`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		portPaths := [2]string{args[0], args[1]}
+
+		baudRate, _ := cmd.Flags().GetInt("baud")
+		labelList, _ := cmd.Flags().GetStringSlice("labels")
+		outputPath, _ := cmd.Flags().GetString("output")
+		pcapPath, _ := cmd.Flags().GetString("pcapng")
+		plain, _ := cmd.Flags().GetBool("plain")
+		noTimestamps, _ := cmd.Flags().GetBool("no-timestamps")
+
+		labels := [2]string{filepath.Base(portPaths[0]), filepath.Base(portPaths[1])}
+		switch len(labelList) {
+		case 0:
+		case 2:
+			labels = [2]string{labelList[0], labelList[1]}
+		default:
+			fmt.Fprintf(os.Stderr, "Error: --labels takes two names, for side A and side B\n")
+			os.Exit(1)
+		}
+
+		opts := []serial.Option{
+			serial.WithBaudRate(baudRate),
+			withDiagnostics(),
+		}
+		lineOpts, err := lineOptions(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		opts = append(opts, lineOpts...)
+
+		scrollback, err := scrollbackOptions(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		filter, err := filterOptions(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		rec, err := openLinkRecorder(outputPath, pcapPath, portPaths, labels)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if plain {
+			err = runSniffPlain(portPaths, labels, noTimestamps, filter, rec, opts...)
+		} else {
+			err = runSniffTUI(portPaths, labels, noTimestamps, scrollback, highlightOptions(cmd), filter, rec, opts...)
+		}
+		if cerr := rec.close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(sniffCmd)
+
+	sniffCmd.Flags().IntP("baud", "b", 115200, "Baud rate of the link")
+	addLineFlags(sniffCmd, 100*time.Millisecond)
+	sniffCmd.Flags().StringSlice("labels", nil, "Names of side A and side B, such as host,device (default: the port names)")
+	sniffCmd.Flags().StringP("output", "o", "", "Also append both directions to this file, one line per chunk")
+	sniffCmd.Flags().String("pcapng", "", "Also write both directions to a pcapng file")
+	sniffCmd.Flags().Bool("plain", false, "Print to stdout without the terminal interface")
+	sniffCmd.Flags().Bool("no-timestamps", false, "Hide timestamps from output")
+	addScrollbackFlags(sniffCmd)
+	addHighlightFlag(sniffCmd)
+	addFilterFlag(sniffCmd)
+}
+
+// linkRecorder writes both directions of a link to the --output and
+// --pcapng files of sniff and proxy; the goroutine of each direction calls
+// it. A nil *linkRecorder records nothing.
+type linkRecorder struct {
+	mu       sync.Mutex
+	log      *terminalLog
+	pcapFile *os.File
+	pcap     *session.PcapngWriter
+}
+
+// openLinkRecorder opens the files named by outputPath and pcapPath;
+// with neither it returns nil
+func openLinkRecorder(outputPath, pcapPath string, portPaths, labels [2]string) (*linkRecorder, error) {
+	if outputPath == "" && pcapPath == "" {
+		return nil, nil
+	}
+	r := &linkRecorder{}
+	link := fmt.Sprintf("%s (%s) / %s (%s)", labels[0], portPaths[0], labels[1], portPaths[1])
+	if outputPath != "" {
+		log, err := openTerminalLog(outputPath, link)
+		if err != nil {
+			return nil, err
+		}
+		r.log = log
+	}
+	if pcapPath != "" {
+		f, err := os.Create(pcapPath)
+		if err != nil {
+			r.log.Close()
+			return nil, fmt.Errorf("failed to create pcapng file: %w", err)
+		}
+		r.pcapFile = f
+		r.pcap, err = session.NewPcapngWriter(f, session.WithInterfaceName(link))
+		if err != nil {
+			r.close()
+			return nil, fmt.Errorf("failed to write pcapng header: %w", err)
+		}
+	}
+	return r, nil
+}
+
+// write records a chunk sent by side A (msg.IsTX) or side B
+func (r *linkRecorder) write(msg components.DataReceivedMsg) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.log.Write(msg)
+	if r.pcap != nil {
+		kind := session.KindRX
+		if msg.IsTX {
+			kind = session.KindTX
+		}
+		r.pcap.WriteEvent(msg.Timestamp, session.Event{Kind: kind, Data: msg.Data})
+	}
+}
+
+func (r *linkRecorder) close() error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	err := r.log.Close()
+	if r.pcap != nil {
+		if perr := r.pcap.Flush(); err == nil {
+			err = perr
+		}
+	}
+	if r.pcapFile != nil {
+		if cerr := r.pcapFile.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// openSniffPorts opens the ports of both sides; close them with
+// closeSniffPorts
+func openSniffPorts(portPaths [2]string, opts ...serial.Option) ([2]serial.Port, error) {
+	var ports [2]serial.Port
+	for i, portPath := range portPaths {
+		port, err := serial.Open(portPath, opts...)
+		if err != nil {
+			closeSniffPorts(ports)
+			return ports, fmt.Errorf("failed to open %s: %w", portPath, err)
+		}
+		ports[i] = port
+	}
+	return ports, nil
+}
+
+func closeSniffPorts(ports [2]serial.Port) {
+	for _, port := range ports {
+		if port != nil {
+			port.Close()
+		}
+	}
+}
+
+// readSniff passes what each port receives to record, labeled with its
+// side, until ctx is done or a read fails
+func readSniff(ctx context.Context, ports [2]serial.Port, portPaths, labels [2]string, record func(components.DataReceivedMsg)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errs := make(chan error, 2)
+	for i, port := range ports {
+		go func() {
+			err := readPlain(ctx, port, func(msg components.DataReceivedMsg) {
+				msg.Source = labels[i]
+				msg.IsTX = i == 0
+				record(msg)
+			})
+			if err != nil {
+				err = fmt.Errorf("%s: %w", portPaths[i], err)
+			}
+			cancel()
+			errs <- err
+		}()
+	}
+	err := <-errs
+	if err2 := <-errs; err == nil {
+		err = err2
+	}
+	return err
+}
+
+// runSniffPlain prints the merged streams to stdout until interrupted
+func runSniffPlain(portPaths, labels [2]string, noTimestamps bool, filter string, rec *linkRecorder, opts ...serial.Option) error {
+	out := newPlainOutput(os.Stdout, noTimestamps, false, filter, nil)
+	out.formatter.SetFramed(true)
+	out.formatter.SetMicroseconds(true)
+	defer out.Flush()
+
+	ports, err := openSniffPorts(portPaths, opts...)
+	if err != nil {
+		return err
+	}
+	defer closeSniffPorts(ports)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return readSniff(ctx, ports, portPaths, labels, func(msg components.DataReceivedMsg) {
+		rec.write(msg)
+		out.Write(msg)
+	})
+}
+
+// runSniffTUI shows the merged streams in the terminal interface of listen
+func runSniffTUI(portPaths, labels [2]string, noTimestamps bool, scrollback scrollbackLimit, highlights []components.HighlightRule, filter string, rec *linkRecorder, opts ...serial.Option) error {
+	config := serial.DefaultConfig()
+	for _, opt := range opts {
+		opt(&config)
+	}
+	link := portPaths[0] + " ⇄ " + portPaths[1]
+
+	serialModel := models.NewSerialModel(link)
+	serialModel.SetScrollbackLimit(scrollback.messages, scrollback.bytes)
+	terminal := components.NewTerminal(80, 20)
+	terminal.SetHighlights(highlights)
+	filterModel := components.NewFilter()
+	filterModel.Set(filter) // Checked by filterOptions
+	terminal.SetFilter(filterModel)
+	terminal.SetFramed(true)
+	terminal.SetMicroseconds(true)
+	terminal.SetFormatOptions(noTimestamps, false)
+
+	m := listenModel{
+		SerialModel: serialModel,
+		terminal:    terminal,
+		statusBar:   components.NewStatusBar("Serial Sniff", link),
+		help:        help.New(),
+		keys:        keys.NewListenKeys(),
+		search:      components.NewSearch(),
+		filter:      filterModel,
+		export:      components.NewPrompt("export to: ", "file; .bin for raw bytes, .hex for hex lines, else text"),
+		counters:    components.NewCounters(5*time.Second, time.Now()),
+		framed:      true,
+	}
+	m.statusBar.SetConnecting()
+	m.statusBar.SetConnectionInfo(&components.ConnectionInfo{
+		BaudRate: config.BaudRate,
+		DataBits: config.DataBits,
+		StopBits: config.StopBits,
+		Parity:   config.Parity,
+	})
+	m.statusBar.SetLogging(rec != nil)
+
+	p := tea.NewProgram(&m, tea.WithAltScreen(), tea.WithMouseCellMotion())
+
+	go func() {
+		ports, err := openSniffPorts(portPaths, opts...)
+		if err != nil {
+			logger.Error("failed to open port", "err", err)
+			p.Send(models.ConnectionStatusMsg{Connected: false, Error: err})
+			return
+		}
+		defer closeSniffPorts(ports)
+		p.Send(models.ConnectionStatusMsg{Connected: true})
+
+		batch := components.NewBatcher(p.Send, components.RenderInterval)
+		err = readSniff(m.GetContext(), ports, portPaths, labels, func(msg components.DataReceivedMsg) {
+			rec.write(msg)
+			batch.Send(msg)
+		})
+		batch.Stop()
+		if err != nil {
+			logger.Error("sniff read failed", "err", err)
+			p.Send(models.ConnectionStatusMsg{Connected: false, Error: err})
+		}
+	}()
+
+	_, err := p.Run()
+
+	m.Cancel()
+	return err
+}
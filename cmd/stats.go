@@ -0,0 +1,259 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/allbin/go-serial"
+	"github.com/allbin/go-serial/internal/tui/colors"
+	"github.com/allbin/go-serial/internal/tui/styles"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+)
+
+// statsCmd represents the stats command
+var statsCmd = &cobra.Command{
+	Use:   "stats <port>",
+	Short: "Show live UART counters, buffer levels and line settings",
+	Long: `Show the driver's line counters (TIOCGICOUNT), the bytes waiting in the
+kernel's receive and transmit buffers, and the current termios settings of a
+port, refreshed every --interval, so line problems can be spotted while
+another program uses the port.
+
+The port is opened without changing its settings. Counters are shown as
+the driver's totals, the change since stats started and the change over
+the last interval; error counters (framing, overrun, parity, break, buffer
+overrun) that grew in the last interval are highlighted. r restarts the
+"since start" column, q or Ctrl+C quits.
+
+Counters come from the UART driver, so PTYs and some USB adapters have
+none; the buffers and settings are still shown. --once prints a single
+snapshot without the live view.
+
+Examples:
+  serial stats /dev/ttyS0
+  serial stats /dev/ttyUSB0 --interval 250ms
+  serial stats /dev/ttyUSB0 --once`,
+	Args: portArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		args = withProfilePort(args, 1)
+		portPath := args[0]
+		interval, _ := cmd.Flags().GetDuration("interval")
+		once, _ := cmd.Flags().GetBool("once")
+
+		if interval <= 0 {
+			fmt.Fprintf(os.Stderr, "Error: --interval must be positive\n")
+			os.Exit(1)
+		}
+
+		port, err := serial.Open(portPath, serial.WithKeepSettings(), withDiagnostics())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening port: %v\n", err)
+			os.Exit(1)
+		}
+		defer port.Close()
+
+		first := takeStatsSnapshot(port)
+		m := &statsModel{port: port, portPath: portPath, interval: interval, start: first, prev: first, cur: first}
+		if once {
+			fmt.Print(m.render(false))
+			return
+		}
+		if _, err := tea.NewProgram(m, tea.WithAltScreen()).Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+
+	statsCmd.Flags().Duration("interval", time.Second, "How often to refresh")
+	statsCmd.Flags().Bool("once", false, "Print one snapshot and exit")
+}
+
+// statsSnapshot is one reading of a port's driver state
+type statsSnapshot struct {
+	at          time.Time
+	settings    serial.LineSettings
+	settingsErr error
+	levels      serial.BufferLevels
+	levelsErr   error
+	counts      serial.LineCounts
+	countsErr   error
+}
+
+func takeStatsSnapshot(port serial.Port) statsSnapshot {
+	s := statsSnapshot{at: time.Now()}
+	s.settings, s.settingsErr = serial.GetLineSettings(port)
+	s.levels, s.levelsErr = serial.GetBufferLevels(port)
+	s.counts, s.countsErr = serial.GetLineCounts(port)
+	return s
+}
+
+// statsCounter names a LineCounts field, whether it counts errors and
+// whether its last interval is shown as a rate
+type statsCounter struct {
+	name  string
+	isErr bool
+	rate  bool
+	get   func(serial.LineCounts) int
+}
+
+var statsCounters = []statsCounter{
+	{"RX bytes", false, true, func(c serial.LineCounts) int { return c.RX }},
+	{"TX bytes", false, true, func(c serial.LineCounts) int { return c.TX }},
+	{"Framing", true, false, func(c serial.LineCounts) int { return c.Frame }},
+	{"Overrun", true, false, func(c serial.LineCounts) int { return c.Overrun }},
+	{"Parity", true, false, func(c serial.LineCounts) int { return c.Parity }},
+	{"Break", true, false, func(c serial.LineCounts) int { return c.Break }},
+	{"Buf overrun", true, false, func(c serial.LineCounts) int { return c.BufferOverrun }},
+	{"CTS edges", false, false, func(c serial.LineCounts) int { return c.CTS }},
+	{"DSR edges", false, false, func(c serial.LineCounts) int { return c.DSR }},
+	{"RI edges", false, false, func(c serial.LineCounts) int { return c.RI }},
+	{"DCD edges", false, false, func(c serial.LineCounts) int { return c.DCD }},
+}
+
+// statsTickMsg asks the stats view for a new snapshot
+type statsTickMsg time.Time
+
+// statsModel is the live view of the stats command
+type statsModel struct {
+	port     serial.Port
+	portPath string
+	interval time.Duration
+
+	start statsSnapshot // Baseline of the "since start" column
+	prev  statsSnapshot
+	cur   statsSnapshot
+}
+
+func (m *statsModel) tick() tea.Cmd {
+	return tea.Tick(m.interval, func(t time.Time) tea.Msg {
+		return statsTickMsg(t)
+	})
+}
+
+func (m *statsModel) Init() tea.Cmd {
+	return m.tick()
+}
+
+func (m *statsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c", "esc":
+			return m, tea.Quit
+		case "r":
+			m.start = m.cur
+		}
+	case statsTickMsg:
+		m.prev, m.cur = m.cur, takeStatsSnapshot(m.port)
+		return m, m.tick()
+	}
+	return m, nil
+}
+
+func (m *statsModel) View() string {
+	return m.render(true)
+}
+
+// render draws the current snapshot; live adds the interval columns and
+// key help
+func (m *statsModel) render(live bool) string {
+	labelStyle := lipgloss.NewStyle().Foreground(colors.Subtext0).Width(12)
+	headerStyle := lipgloss.NewStyle().Foreground(colors.Mauve).Bold(true)
+	errStyle := lipgloss.NewStyle().Foreground(colors.Red).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(colors.Overlay1)
+
+	var b strings.Builder
+	row := func(label, value string) {
+		fmt.Fprintf(&b, "  %s %s\n", labelStyle.Render(label), value)
+	}
+	cur := m.cur
+
+	b.WriteString(styles.TitleStyle.Render("Stats "+m.portPath) + "\n\n")
+
+	if cur.settingsErr != nil {
+		row("Line", errStyle.Render(cur.settingsErr.Error()))
+	} else {
+		s := cur.settings
+		line := fmt.Sprintf("%s %d%s%d", formatStatsBaud(s.BaudRate), s.DataBits,
+			strings.ToUpper(s.Parity.String()[:1]), s.StopBits)
+		row("Line", line)
+		row("Flow", formatStatsFlow(s))
+		row("Read", fmt.Sprintf("VMIN %d, VTIME %v", s.MinChars, s.ReadTimeout))
+		row("Flags", dimStyle.Render(fmt.Sprintf("cflag=%#x iflag=%#x oflag=%#x lflag=%#x", s.Cflag, s.Iflag, s.Oflag, s.Lflag)))
+	}
+
+	if cur.levelsErr != nil {
+		row("Buffers", errStyle.Render(cur.levelsErr.Error()))
+	} else {
+		row("Buffers", fmt.Sprintf("RX %d bytes waiting, TX %d bytes queued", cur.levels.Input, cur.levels.Output))
+	}
+
+	b.WriteString("\n")
+	switch {
+	case errors.Is(cur.countsErr, serial.ErrNotSupported):
+		b.WriteString("  " + dimStyle.Render("No line counters: the driver does not keep them") + "\n")
+	case cur.countsErr != nil:
+		b.WriteString("  " + errStyle.Render("Counters: "+cur.countsErr.Error()) + "\n")
+	case live:
+		elapsed := cur.at.Sub(m.prev.at).Seconds()
+		b.WriteString(headerStyle.Render(fmt.Sprintf("  %-12s %12s %12s %13s", "Counter", "Total", "Since start", "Last interval")) + "\n")
+		for _, c := range statsCounters {
+			total := c.get(cur.counts)
+			delta := total - c.get(m.prev.counts)
+			last := fmt.Sprintf("%13d", delta)
+			if c.rate && elapsed > 0 {
+				last = fmt.Sprintf("%13s", fmt.Sprintf("%.0f/s", float64(delta)/elapsed))
+			}
+			line := fmt.Sprintf("  %-12s %12d %12d %s", c.name, total, total-c.get(m.start.counts), last)
+			if c.isErr && delta > 0 {
+				line = errStyle.Render(line + "  ◀")
+			}
+			b.WriteString(line + "\n")
+		}
+	default:
+		b.WriteString(headerStyle.Render(fmt.Sprintf("  %-12s %12s", "Counter", "Total")) + "\n")
+		for _, c := range statsCounters {
+			fmt.Fprintf(&b, "  %-12s %12d\n", c.name, c.get(cur.counts))
+		}
+	}
+
+	if live {
+		b.WriteString("\n  " + dimStyle.Render(fmt.Sprintf("every %v · r reset · q quit", m.interval)) + "\n")
+	}
+	return b.String()
+}
+
+// formatStatsBaud renders a baud rate, which the driver may not know
+func formatStatsBaud(rate int) string {
+	if rate == 0 {
+		return "unknown baud"
+	}
+	return fmt.Sprint(rate)
+}
+
+// formatStatsFlow names the flow control the settings enable
+func formatStatsFlow(s serial.LineSettings) string {
+	var modes []string
+	if s.RTSCTS {
+		modes = append(modes, "RTS/CTS")
+	}
+	if s.XONXOFF {
+		modes = append(modes, "XON/XOFF")
+	}
+	if len(modes) == 0 {
+		return "none"
+	}
+	return strings.Join(modes, ", ")
+}
@@ -0,0 +1,282 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/allbin/go-serial"
+	"github.com/spf13/cobra"
+	"golang.org/x/sys/unix"
+)
+
+// termCmd represents the term command
+var termCmd = &cobra.Command{
+	Use:   "term <port>",
+	Short: "Minimal raw terminal, like picocom",
+	Long: `Connect the terminal straight to a port, like picocom or screen: every key
+goes to the port as typed and everything received goes to the terminal
+unchanged, with no alternate screen, status bar or line editing. Use it
+for SBC and router consoles, where the device draws its own screen or the
+TUI of serial connect gets in the way.
+
+Ctrl-C, Ctrl-Z and the like go to the device too. Commands are typed after
+the escape key, Ctrl-A by default (--escape picks another letter):
+  Ctrl-A q, Ctrl-A x  Quit
+  Ctrl-A b            Send a break
+  Ctrl-A t            Toggle DTR
+  Ctrl-A g            Toggle RTS
+  Ctrl-A Ctrl-A       Send Ctrl-A itself
+  Ctrl-A h            Show these commands
+
+Example usage:
+  serial term /dev/ttyUSB0
+  serial term /dev/ttyUSB0 --baud 1500000
+  serial term /dev/ttyUSB0 --escape t`,
+	Args: portArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		args = withProfilePort(args, 1)
+		portPath := args[0]
+
+		escapeName, _ := cmd.Flags().GetString("escape")
+		breakDuration, _ := cmd.Flags().GetDuration("break")
+		escape, err := parseEscapeKey(escapeName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		opts, config, err := portOptions(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		port, err := serial.Open(portPath, opts...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening port: %v\n", err)
+			os.Exit(1)
+		}
+		defer port.Close()
+
+		fmt.Printf("Terminal on %s at %s; escape is Ctrl-%c, Ctrl-%c h for help\n",
+			portPath, formatLineSettings(config), escape+'A'-1, escape+'A'-1)
+
+		if err := runTerm(port, escape, breakDuration); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(termCmd)
+
+	addPortFlags(termCmd, 100*time.Millisecond)
+	termCmd.Flags().String("escape", "a", "Escape key, a letter used with Ctrl")
+	termCmd.Flags().Duration("break", 250*time.Millisecond, "Duration of the break sent with the escape key and b")
+}
+
+// parseEscapeKey returns the control character of a letter, such as 0x01
+// for a
+func parseEscapeKey(name string) (byte, error) {
+	if len(name) != 1 || !('a' <= name[0]|0x20 && name[0]|0x20 <= 'z') {
+		return 0, fmt.Errorf("invalid --escape %q (want a letter, used with Ctrl)", name)
+	}
+	return name[0] | 0x20 - 'a' + 1, nil
+}
+
+// runTerm passes keys to port and received data to stdout until the quit
+// command, a signal or a port error
+func runTerm(port serial.Port, escape byte, breakDuration time.Duration) error {
+	restore, err := rawStdin()
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer stop()
+
+	portErr := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 4096)
+		for ctx.Err() == nil {
+			n, err := port.Read(buf)
+			if n > 0 {
+				os.Stdout.Write(buf[:n])
+			}
+			if err != nil {
+				portErr <- err
+				return
+			}
+		}
+	}()
+
+	keys := make(chan []byte)
+	go func() {
+		buf := make([]byte, 256)
+		for {
+			n, err := os.Stdin.Read(buf)
+			if err != nil {
+				close(keys)
+				return
+			}
+			keys <- append([]byte(nil), buf[:n]...)
+		}
+	}()
+
+	t := &termState{port: port, escape: escape, breakDuration: breakDuration}
+	for {
+		select {
+		case <-ctx.Done():
+			t.note("Terminated")
+			return nil
+		case err := <-portErr:
+			t.note("Port failed")
+			return fmt.Errorf("read failed: %w", err)
+		case data, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			if quit, err := t.keys(data); quit || err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// termState interprets keys for the port and the escape commands
+type termState struct {
+	port          serial.Port
+	escape        byte
+	breakDuration time.Duration
+	escaped       bool // The escape key was the last key
+}
+
+// keys handles typed bytes, returning true on the quit command
+func (t *termState) keys(data []byte) (bool, error) {
+	var out []byte
+	for _, b := range data {
+		if !t.escaped {
+			if b == t.escape {
+				t.escaped = true
+			} else {
+				out = append(out, b)
+			}
+			continue
+		}
+		t.escaped = false
+		if b == t.escape {
+			out = append(out, b)
+			continue
+		}
+		// Commands act after the keys typed before them have been sent
+		if err := t.send(out); err != nil {
+			return false, err
+		}
+		out = out[:0]
+		if t.command(b) {
+			t.note("Quit")
+			return true, nil
+		}
+	}
+	return false, t.send(out)
+}
+
+func (t *termState) send(data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if _, err := t.port.Write(data); err != nil {
+		return fmt.Errorf("write failed: %w", err)
+	}
+	return nil
+}
+
+// command runs the escape command c, returning true for quit
+func (t *termState) command(c byte) bool {
+	key := fmt.Sprintf("Ctrl-%c", t.escape+'A'-1)
+	if 1 <= c && c <= 26 {
+		c += 'a' - 1 // Commands work with Ctrl held as well, as Ctrl-A Ctrl-X
+	}
+	switch c | 0x20 {
+	case 'q', 'x':
+		return true
+	case 'b':
+		if err := serial.SendBreak(t.port, t.breakDuration); err != nil {
+			t.note(fmt.Sprintf("Break failed: %v", err))
+		} else {
+			t.note(fmt.Sprintf("Sent %s break", t.breakDuration))
+		}
+	case 't':
+		t.toggle("DTR", t.port.GetDTR, t.port.SetDTR)
+	case 'g':
+		t.toggle("RTS", t.port.GetRTS, t.port.SetRTS)
+	case 'h', '?':
+		t.note(strings.Join([]string{
+			"Commands, typed after " + key + ":",
+			"  q, x    Quit",
+			"  b       Send a break",
+			"  t       Toggle DTR",
+			"  g       Toggle RTS",
+			"  " + key + "  Send " + key + " itself",
+		}, "\r\n"))
+	default:
+		t.note(fmt.Sprintf("Unknown command; %s h for help", key))
+	}
+	return false
+}
+
+// toggle inverts a modem output and reports its new state
+func (t *termState) toggle(name string, get func() (bool, error), set func(bool) error) {
+	state, err := get()
+	if err == nil {
+		err = set(!state)
+	}
+	if err != nil {
+		t.note(fmt.Sprintf("%s failed: %v", name, err))
+		return
+	}
+	level := "low"
+	if !state {
+		level = "high"
+	}
+	t.note(fmt.Sprintf("%s %s", name, level))
+}
+
+// note prints a message of the terminal itself between received data;
+// stdout is raw, so lines end in \r\n
+func (t *termState) note(text string) {
+	fmt.Fprintf(os.Stdout, "\r\n*** %s ***\r\n", text)
+}
+
+// rawStdin puts the terminal on stdin into raw mode, as cfmakeraw does, and
+// returns a function that restores it
+func rawStdin() (func(), error) {
+	fd := int(os.Stdin.Fd())
+	old, err := unix.IoctlGetTermios(fd, unix.TCGETS)
+	if err != nil {
+		return nil, fmt.Errorf("stdin is not a terminal: %w", err)
+	}
+	raw := *old
+	raw.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	raw.Oflag &^= unix.OPOST
+	raw.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	raw.Cflag &^= unix.CSIZE | unix.PARENB
+	raw.Cflag |= unix.CS8
+	raw.Cc[unix.VMIN] = 1
+	raw.Cc[unix.VTIME] = 0
+	if err := unix.IoctlSetTermios(fd, unix.TCSETS, &raw); err != nil {
+		return nil, fmt.Errorf("failed to set raw mode: %w", err)
+	}
+	return func() {
+		unix.IoctlSetTermios(fd, unix.TCSETS, old)
+	}, nil
+}
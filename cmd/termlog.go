@@ -0,0 +1,124 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/allbin/go-serial/internal/tui/components"
+)
+
+// terminalLog appends the messages shown in a TUI terminal to a file
+// One line per message: timestamp, direction (or source), hex bytes and
+// printable ASCII.
+// A nil *terminalLog logs nothing.
+type terminalLog struct {
+	path string
+	c    io.Closer // Nil when writing to a stream the log does not own
+	w    *bufio.Writer
+}
+
+// openTerminalLog opens path for appending, or a timestamped file named
+// after the port in the working directory if path is empty
+func openTerminalLog(path, portPath string) (*terminalLog, error) {
+	if path == "" {
+		path = logFileName(portPath)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+	l := &terminalLog{path: path, c: f, w: bufio.NewWriter(f)}
+	fmt.Fprintf(l.w, "# %s log started %s\n", portPath, time.Now().Format(time.RFC3339))
+	return l, nil
+}
+
+// logFileName returns a timestamped file name for logging portPath, such as
+// serial-ttyUSB0-20250102-150405.log
+func logFileName(portPath string) string {
+	return fmt.Sprintf("serial-%s-%s.log", filepath.Base(portPath), time.Now().Format("20060102-150405"))
+}
+
+// newStreamLog logs to w, such as stdout, which Close leaves open
+func newStreamLog(w io.Writer) *terminalLog {
+	return &terminalLog{w: bufio.NewWriter(w)}
+}
+
+// Write logs msg; pending TX messages are skipped and logged once their
+// final status arrives, and notes are logged as commentary
+func (l *terminalLog) Write(msg components.DataReceivedMsg) {
+	if l == nil || msg.Status == "PENDING" {
+		return
+	}
+	if msg.IsNote {
+		fmt.Fprintf(l.w, "# %s %s\n", msg.Timestamp.Format("2006-01-02T15:04:05.000000Z07:00"), msg.Data)
+		l.w.Flush()
+		return
+	}
+	dir := "RX"
+	if msg.IsTX {
+		dir = "TX"
+	}
+	status := ""
+	if msg.Source != "" {
+		dir = msg.Source
+	} else if msg.IsTX && msg.Status != "WRITTEN" {
+		status = " [" + msg.Status + "]"
+	}
+	fmt.Fprintf(l.w, "%s %s %-47s | %s%s\n",
+		msg.Timestamp.Format("2006-01-02T15:04:05.000000Z07:00"), dir,
+		fmt.Sprintf("% X", msg.Data), printableASCII(msg.Data), status)
+	l.w.Flush()
+}
+
+// Note logs a line of commentary, such as a status change
+func (l *terminalLog) Note(text string) {
+	if l == nil {
+		return
+	}
+	fmt.Fprintf(l.w, "# %s %s\n", time.Now().Format("2006-01-02T15:04:05.000000Z07:00"), text)
+	l.w.Flush()
+}
+
+// Path returns the file being written
+func (l *terminalLog) Path() string {
+	if l == nil {
+		return ""
+	}
+	return l.path
+}
+
+func (l *terminalLog) Close() error {
+	if l == nil {
+		return nil
+	}
+	if l.c == nil {
+		return l.w.Flush()
+	}
+	fmt.Fprintf(l.w, "# log stopped %s\n", time.Now().Format(time.RFC3339))
+	err := l.w.Flush()
+	if cerr := l.c.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// printableASCII replaces non-printable bytes with '.'
+func printableASCII(data []byte) string {
+	var b strings.Builder
+	for _, c := range data {
+		if c >= 32 && c <= 126 {
+			b.WriteByte(c)
+		} else {
+			b.WriteByte('.')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,103 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/allbin/go-serial"
+	"github.com/spf13/cobra"
+)
+
+// virtualPairCmd represents the virtual-pair command
+var virtualPairCmd = &cobra.Command{
+	Use:   "virtual-pair",
+	Short: "Create two linked PTYs acting as a null modem",
+	Long: `Create a pair of pseudo-terminals wired together like a null-modem cable.
+
+Bytes written to one device can be read from the other. Open either end with
+any program (including other serial commands) to test without hardware.
+
+--baud limits throughput to what a real UART at that rate (8N1) could carry.
+--cts-open and --cts-closed simulate a device that periodically drops CTS:
+data flows during the open window and is held until the next one.
+
+--link-a and --link-b create symlinks with stable names to the devices.
+They are removed on exit.
+
+Example usage:
+  serial virtual-pair
+  serial virtual-pair --baud 9600
+  serial virtual-pair --link-a /tmp/ttyV0 --link-b /tmp/ttyV1
+  serial virtual-pair --baud 115200 --cts-open 50ms --cts-closed 950ms`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get flags
+		baudRate, _ := cmd.Flags().GetInt("baud")
+		ctsOpen, _ := cmd.Flags().GetDuration("cts-open")
+		ctsClosed, _ := cmd.Flags().GetDuration("cts-closed")
+		linkA, _ := cmd.Flags().GetString("link-a")
+		linkB, _ := cmd.Flags().GetString("link-b")
+
+		opts := []serial.VirtualPairOption{serial.WithPairBaudRate(baudRate)}
+		if ctsClosed > 0 {
+			opts = append(opts, serial.WithPairCTSWindow(ctsOpen, ctsClosed))
+		}
+
+		if err := runVirtualPair(opts, linkA, linkB); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(virtualPairCmd)
+
+	virtualPairCmd.Flags().IntP("baud", "b", 0, "Simulated baud rate (0 = unlimited)")
+	virtualPairCmd.Flags().Duration("cts-open", 0, "Time CTS is asserted in each simulated CTS cycle")
+	virtualPairCmd.Flags().Duration("cts-closed", 0, "Time CTS is deasserted in each simulated CTS cycle")
+	virtualPairCmd.Flags().String("link-a", "", "Symlink to create for the first device")
+	virtualPairCmd.Flags().String("link-b", "", "Symlink to create for the second device")
+}
+
+func runVirtualPair(opts []serial.VirtualPairOption, linkA, linkB string) error {
+	// Setup signal handling for clean shutdown
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	pair, err := serial.OpenVirtualPair(opts...)
+	if err != nil {
+		return err
+	}
+	defer pair.Close()
+
+	pathA, pathB := pair.PathA, pair.PathB
+	for _, link := range []struct{ name, target string }{{linkA, pair.PathA}, {linkB, pair.PathB}} {
+		if link.name == "" {
+			continue
+		}
+		if err := os.Symlink(link.target, link.name); err != nil {
+			return fmt.Errorf("failed to create link: %w", err)
+		}
+		defer os.Remove(link.name)
+	}
+	if linkA != "" {
+		pathA = fmt.Sprintf("%s -> %s", linkA, pair.PathA)
+	}
+	if linkB != "" {
+		pathB = fmt.Sprintf("%s -> %s", linkB, pair.PathB)
+	}
+
+	fmt.Printf("A: %s\n", pathA)
+	fmt.Printf("B: %s\n", pathB)
+	fmt.Fprintf(os.Stderr, "Press Ctrl+C to stop\n")
+
+	<-ctx.Done()
+	return nil
+}
@@ -0,0 +1,206 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/allbin/go-serial"
+	"github.com/spf13/cobra"
+)
+
+// errWaitTimedOut is returned when --timeout passes before the device or
+// pattern appeared; the command exits with status 2
+var errWaitTimedOut = errors.New("timed out")
+
+// waitCmd represents the wait command
+var waitCmd = &cobra.Command{
+	Use:   "wait",
+	Short: "Block until a device appears or sends a pattern",
+	Long: `Wait until a port exists and, with --pattern, until data received from it
+matches a regular expression, then print the port's path and exit. Meant
+for provisioning scripts, Makefiles and systemd ExecStartPre= lines that
+must not start before a device is plugged in and ready.
+
+The device is named by --port (a device path or network URL, defaulting to
+the profile's port) or by --serial, the USB serial number of its adapter,
+which finds it whichever ttyUSB number it enumerates as. Enumeration is
+checked every --poll; network ports count as present once they accept a
+connection. If the device disappears while waiting for the pattern, the
+wait starts over when it returns.
+
+The exit status is 0 once the device (and pattern) appeared, 2 when
+--timeout passed first and 1 on errors.
+
+Examples:
+  serial wait --port /dev/ttyUSB0 --timeout 30s
+  serial wait --serial FT123 --pattern "READY"
+  serial wait --serial FT123 --pattern 'login:' --baud 9600 --timeout 2m
+  PORT=$(serial wait --serial FT123) && serial send "AT\r" "$PORT"`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		portPath, _ := cmd.Flags().GetString("port")
+		usbSerial, _ := cmd.Flags().GetString("serial")
+		pattern, _ := cmd.Flags().GetString("pattern")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		poll, _ := cmd.Flags().GetDuration("poll")
+
+		if portPath == "" && usbSerial == "" {
+			portPath = profilePort()
+		}
+		if portPath == "" && usbSerial == "" {
+			fmt.Fprintf(os.Stderr, "Error: --port or --serial is required\n")
+			os.Exit(1)
+		}
+		if portPath != "" && usbSerial != "" {
+			fmt.Fprintf(os.Stderr, "Error: --port and --serial cannot be used together\n")
+			os.Exit(1)
+		}
+		if timeout < 0 || poll <= 0 {
+			fmt.Fprintf(os.Stderr, "Error: --timeout must not be negative and --poll must be positive\n")
+			os.Exit(1)
+		}
+
+		var re *regexp.Regexp
+		if pattern != "" {
+			var err error
+			if re, err = regexp.Compile(pattern); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: invalid --pattern: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		opts, _, err := portOptions(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		opts = append(opts, withDiagnostics())
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		path, err := waitForDevice(ctx, portPath, usbSerial, re, poll, opts...)
+		if errors.Is(err, errWaitTimedOut) {
+			what := "appear"
+			if re != nil {
+				what = fmt.Sprintf("send %q", pattern)
+			}
+			fmt.Fprintf(os.Stderr, "Error: %s did not %s within %v\n", waitTarget(portPath, usbSerial), what, timeout)
+			os.Exit(2)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(path)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(waitCmd)
+
+	addPortFlags(waitCmd, 100*time.Millisecond)
+	waitCmd.Flags().String("port", "", "Port to wait for (default: the profile's port)")
+	waitCmd.Flags().String("serial", "", "Wait for the port of the USB adapter with this serial number")
+	waitCmd.Flags().String("pattern", "", "Also wait until received data matches this regular expression")
+	waitCmd.Flags().Duration("timeout", 0, "Give up with exit status 2 after this long (0 = wait forever)")
+	waitCmd.Flags().Duration("poll", 250*time.Millisecond, "How often to check whether the device is present")
+}
+
+// waitTarget describes the awaited device for messages
+func waitTarget(portPath, usbSerial string) string {
+	if usbSerial != "" {
+		return "device with serial " + usbSerial
+	}
+	return portPath
+}
+
+// waitForDevice polls until the device named by portPath or usbSerial is
+// present and, if re is set, has sent data matching it, and returns its
+// path. A cancelled ctx returns errWaitTimedOut if its deadline passed.
+func waitForDevice(ctx context.Context, portPath, usbSerial string, re *regexp.Regexp, poll time.Duration, opts ...serial.Option) (string, error) {
+	for first := true; ; first = false {
+		if !first {
+			select {
+			case <-ctx.Done():
+				if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					return "", errWaitTimedOut
+				}
+				return "", ctx.Err()
+			case <-time.After(poll):
+			}
+		}
+
+		path := portPath
+		if usbSerial != "" {
+			if path = findPortBySerial(usbSerial); path == "" {
+				continue
+			}
+		}
+		// Network ports are checked by connecting, below
+		if !strings.Contains(path, "://") {
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+			if re == nil {
+				return path, nil
+			}
+		}
+
+		port, err := serial.Open(path, opts...)
+		if err != nil {
+			// udev may still be setting up permissions
+			logger.Debug("wait open failed", "port", path, "err", err)
+			continue
+		}
+		if re == nil {
+			port.Close()
+			return path, nil
+		}
+		matched, err := waitForPattern(ctx, port, re)
+		port.Close()
+		if matched {
+			return path, nil
+		}
+		if err != nil && ctx.Err() == nil {
+			logger.Debug("wait read failed", "port", path, "err", err)
+		}
+	}
+}
+
+// waitForPattern reads port until recent data matches re, reporting
+// whether it did before a read failed
+func waitForPattern(ctx context.Context, port serial.Port, re *regexp.Regexp) (bool, error) {
+	buf := make([]byte, 4096)
+	var window []byte
+	for {
+		n, err := port.ReadContext(ctx, buf)
+		if n > 0 {
+			window = append(window, buf[:n]...)
+			if len(window) > captureWindowSize {
+				window = window[len(window)-captureWindowSize:]
+			}
+			if re.Match(window) {
+				return true, nil
+			}
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+}
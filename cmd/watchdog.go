@@ -0,0 +1,514 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/allbin/go-serial"
+	"github.com/spf13/cobra"
+)
+
+// watchdogPollInterval is how often the watchdog checks for a stall
+const watchdogPollInterval = 250 * time.Millisecond
+
+// watchdogCmd represents the watchdog command
+var watchdogCmd = &cobra.Command{
+	Use:   "watchdog <port>",
+	Short: "Detect a hung device and recover it automatically",
+	Long: `Watch a port for stall conditions and run recovery actions when one
+occurs, for unattended devices that occasionally hang.
+
+Stall conditions (at least one is required):
+  --rx-timeout D     nothing received for D
+  --cts-low D        CTS deasserted for D
+  --cts-timeouts N   N probe writes in a row failing with a CTS timeout
+A port that fails or disappears is a stall too.
+
+--probe sends a keepalive (text with Go escapes such as \r\n, or 0x hex)
+every --probe-interval, so a device that only answers has something to
+answer; with --flow-control cts, writes blocked for --cts-timeout count
+towards --cts-timeouts.
+
+Recovery actions run in the order of --action (repeatable):
+  dtr        drop DTR for --dtr-pulse, then raise it again
+  usb-reset  reset the USB device (needs usbreset and root), by --serial
+             or the adapter's own serial number, and wait for it to return
+  hook       run --hook with sh -c; SERIAL_PORT, SERIAL_WATCHDOG_REASON and
+             SERIAL_WATCHDOG_RECOVERY are set in its environment
+Afterwards the port is reopened if needed and stalls are ignored for
+--holdoff, giving the device time to boot.
+
+Every stall, action and result is logged as a structured record on stdout
+(logfmt, or JSON lines with --json). The watchdog normally reads and
+discards the port's data; --counters instead watches the driver's RX
+counter and leaves the port's data and settings to the program using it.
+After --max-recoveries recoveries the watchdog gives up with exit status 2.
+
+Examples:
+  serial watchdog /dev/ttyUSB0 --rx-timeout 30s --action dtr
+  serial watchdog /dev/ttyS1 --counters --rx-timeout 1m --action hook --hook 'systemctl restart meter'
+  serial watchdog /dev/ttyUSB0 --flow-control cts --initial-rts --probe 'AT\r' --probe-interval 10s \
+      --cts-timeouts 3 --action dtr --action usb-reset --json`,
+	Args: portArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		args = withProfilePort(args, 1)
+		settings, err := watchdogOptions(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		opts := []serial.Option{serial.WithKeepSettings(), withDiagnostics()}
+		if !settings.counters {
+			var err error
+			if opts, _, err = portOptions(cmd); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		ctsTimeout, _ := cmd.Flags().GetDuration("cts-timeout")
+		opts = append(opts, serial.WithCTSTimeout(ctsTimeout))
+
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		var handler slog.Handler = slog.NewTextHandler(os.Stdout, nil)
+		if jsonOutput {
+			handler = slog.NewJSONHandler(os.Stdout, nil)
+		}
+
+		w := &watchdog{
+			watchdogSettings: settings,
+			portPath:         args[0],
+			opts:             opts,
+			log:              slog.New(handler).With("port", args[0]),
+		}
+		if w.usbSerial == "" && slices.Contains(w.actions, "usb-reset") {
+			if info, err := serial.GetPortInfo(w.portPath); err == nil {
+				w.usbSerial = info.SerialNumber
+			}
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		err = w.run(ctx)
+		if errors.Is(err, errWatchdogGaveUp) {
+			os.Exit(2)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(watchdogCmd)
+
+	addPortFlags(watchdogCmd, 100*time.Millisecond)
+	watchdogCmd.Flags().Duration("rx-timeout", 0, "Stall when nothing is received for this long")
+	watchdogCmd.Flags().Duration("cts-low", 0, "Stall when CTS stays deasserted this long")
+	watchdogCmd.Flags().Int("cts-timeouts", 0, "Stall after this many probe writes in a row time out on CTS")
+	watchdogCmd.Flags().String("probe", "", "Keepalive to send every --probe-interval (Go escapes or 0x hex)")
+	watchdogCmd.Flags().Duration("probe-interval", 10*time.Second, "How often to send --probe")
+	watchdogCmd.Flags().Duration("cts-timeout", 5*time.Second, "How long a probe write waits for CTS")
+	watchdogCmd.Flags().StringArray("action", nil, "Recovery action, in order: dtr, usb-reset, hook (repeatable)")
+	watchdogCmd.Flags().Duration("dtr-pulse", 500*time.Millisecond, "How long the dtr action holds DTR low")
+	watchdogCmd.Flags().String("serial", "", "USB serial number for usb-reset (default: the port's adapter)")
+	watchdogCmd.Flags().String("hook", "", "Shell command the hook action runs")
+	watchdogCmd.Flags().Duration("hook-timeout", 30*time.Second, "How long the hook may run")
+	watchdogCmd.Flags().Duration("holdoff", 30*time.Second, "Ignore stalls this long after a recovery")
+	watchdogCmd.Flags().Duration("reopen-timeout", time.Minute, "How long to wait for the port to return after a recovery")
+	watchdogCmd.Flags().Int("max-recoveries", 0, "Give up with exit status 2 after this many recoveries (0 = never)")
+	watchdogCmd.Flags().Bool("counters", false, "Watch the driver's RX counter instead of reading the port")
+	watchdogCmd.Flags().Bool("json", false, "Log JSON lines instead of logfmt")
+}
+
+// errWatchdogGaveUp reports that --max-recoveries was reached
+var errWatchdogGaveUp = errors.New("watchdog gave up")
+
+// watchdogSettings are the stall conditions and recovery actions of a
+// watchdog
+type watchdogSettings struct {
+	rxTimeout     time.Duration
+	ctsLow        time.Duration
+	ctsTimeouts   int
+	probe         []byte
+	probeInterval time.Duration
+	actions       []string
+	dtrPulse      time.Duration
+	usbSerial     string
+	hook          string
+	hookTimeout   time.Duration
+	holdoff       time.Duration
+	reopenTimeout time.Duration
+	maxRecoveries int
+	counters      bool
+}
+
+// watchdogOptions reads and checks the watchdog flags
+func watchdogOptions(cmd *cobra.Command) (watchdogSettings, error) {
+	var s watchdogSettings
+	f := cmd.Flags()
+	s.rxTimeout, _ = f.GetDuration("rx-timeout")
+	s.ctsLow, _ = f.GetDuration("cts-low")
+	s.ctsTimeouts, _ = f.GetInt("cts-timeouts")
+	s.probeInterval, _ = f.GetDuration("probe-interval")
+	s.actions, _ = f.GetStringArray("action")
+	s.dtrPulse, _ = f.GetDuration("dtr-pulse")
+	s.usbSerial, _ = f.GetString("serial")
+	s.hook, _ = f.GetString("hook")
+	s.hookTimeout, _ = f.GetDuration("hook-timeout")
+	s.holdoff, _ = f.GetDuration("holdoff")
+	s.reopenTimeout, _ = f.GetDuration("reopen-timeout")
+	s.maxRecoveries, _ = f.GetInt("max-recoveries")
+	s.counters, _ = f.GetBool("counters")
+
+	if probe, _ := f.GetString("probe"); probe != "" {
+		var err error
+		if s.probe, err = parseDelimiter(probe); err != nil {
+			return s, fmt.Errorf("invalid --probe: %v", err)
+		}
+		if s.probeInterval <= 0 {
+			return s, fmt.Errorf("--probe-interval must be positive")
+		}
+	}
+	if s.rxTimeout <= 0 && s.ctsLow <= 0 && s.ctsTimeouts <= 0 {
+		return s, fmt.Errorf("no stall condition: set --rx-timeout, --cts-low or --cts-timeouts")
+	}
+	if s.ctsTimeouts > 0 && s.probe == nil {
+		return s, fmt.Errorf("--cts-timeouts needs --probe to write")
+	}
+	if len(s.actions) == 0 {
+		return s, fmt.Errorf("no recovery action: set --action dtr, usb-reset or hook")
+	}
+	for _, action := range s.actions {
+		switch action {
+		case "dtr", "usb-reset":
+		case "hook":
+			if s.hook == "" {
+				return s, fmt.Errorf("--action hook needs --hook")
+			}
+		default:
+			return s, fmt.Errorf("unknown --action %q (valid: dtr, usb-reset, hook)", action)
+		}
+	}
+	if slices.Contains(s.actions, "usb-reset") && !serial.IsUSBResetAvailable() {
+		return s, fmt.Errorf("--action usb-reset needs the usbreset utility (usbutils package)")
+	}
+	return s, nil
+}
+
+// watchdog watches one port and recovers it when it stalls
+type watchdog struct {
+	watchdogSettings
+	portPath string
+	opts     []serial.Option
+	log      *slog.Logger
+
+	port     serial.Port
+	ioCancel context.CancelFunc // Stops the reader and prober of port
+	ioDone   sync.WaitGroup
+
+	mu          sync.Mutex
+	lastRX      time.Time
+	ctsTimedOut int   // Consecutive probe writes that timed out on CTS
+	ioErr       error // Error that stopped the reader or prober
+}
+
+// run watches the port until ctx is done or the watchdog gives up
+func (w *watchdog) run(ctx context.Context) error {
+	if err := w.open(ctx, false); err != nil {
+		return err
+	}
+	defer w.close()
+	w.log.Info("watchdog started", "conditions", w.conditions(), "actions", strings.Join(w.actions, ","))
+
+	ticker := time.NewTicker(watchdogPollInterval)
+	defer ticker.Stop()
+
+	var lastCount int
+	var ctsLowSince time.Time
+	holdUntil := time.Now()
+	recoveries := 0
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.log.Info("watchdog stopped", "recoveries", recoveries)
+			return nil
+		case <-ticker.C:
+		}
+		now := time.Now()
+
+		reason := w.check(now, &lastCount, &ctsLowSince)
+		if reason == "" || now.Before(holdUntil) {
+			continue
+		}
+
+		recoveries++
+		w.log.Warn("stall detected", "reason", reason, "recovery", recoveries)
+		w.recover(ctx, reason, recoveries)
+		if ctx.Err() != nil {
+			continue
+		}
+		if w.maxRecoveries > 0 && recoveries >= w.maxRecoveries {
+			w.log.Error("giving up", "recoveries", recoveries)
+			return errWatchdogGaveUp
+		}
+
+		holdUntil = time.Now().Add(w.holdoff)
+		ctsLowSince = time.Time{}
+		lastCount = -1
+		w.mu.Lock()
+		w.lastRX = time.Now()
+		w.ctsTimedOut = 0
+		w.mu.Unlock()
+	}
+}
+
+// conditions describes the enabled stall conditions for the start record
+func (w *watchdog) conditions() string {
+	var c []string
+	if w.rxTimeout > 0 {
+		c = append(c, "rx-timeout="+w.rxTimeout.String())
+	}
+	if w.ctsLow > 0 {
+		c = append(c, "cts-low="+w.ctsLow.String())
+	}
+	if w.ctsTimeouts > 0 {
+		c = append(c, "cts-timeouts="+strconv.Itoa(w.ctsTimeouts))
+	}
+	return strings.Join(c, ",")
+}
+
+// check returns why the port is stalled, or "" if it is not
+func (w *watchdog) check(now time.Time, lastCount *int, ctsLowSince *time.Time) string {
+	if w.port == nil {
+		return "port unavailable"
+	}
+
+	w.mu.Lock()
+	ioErr, ctsTimedOut := w.ioErr, w.ctsTimedOut
+	if w.counters {
+		if counts, err := serial.GetLineCounts(w.port); err == nil {
+			if counts.RX != *lastCount {
+				w.lastRX = now
+			}
+			*lastCount = counts.RX
+		}
+	}
+	lastRX := w.lastRX
+	w.mu.Unlock()
+
+	if ioErr != nil {
+		return fmt.Sprintf("port error: %v", ioErr)
+	}
+	if w.rxTimeout > 0 && now.Sub(lastRX) >= w.rxTimeout {
+		return fmt.Sprintf("nothing received for %v", now.Sub(lastRX).Round(time.Second))
+	}
+	if w.ctsLow > 0 {
+		signals, err := w.port.GetModemSignals()
+		if err != nil {
+			return fmt.Sprintf("port error: %v", err)
+		}
+		if signals.CTS {
+			*ctsLowSince = time.Time{}
+		} else if ctsLowSince.IsZero() {
+			*ctsLowSince = now
+		}
+		if !ctsLowSince.IsZero() && now.Sub(*ctsLowSince) >= w.ctsLow {
+			return fmt.Sprintf("CTS low for %v", now.Sub(*ctsLowSince).Round(time.Second))
+		}
+	}
+	if w.ctsTimeouts > 0 && ctsTimedOut >= w.ctsTimeouts {
+		return fmt.Sprintf("%d probe writes in a row timed out on CTS", ctsTimedOut)
+	}
+	return ""
+}
+
+// recover runs the recovery actions in order and makes sure the port is
+// open again afterwards
+func (w *watchdog) recover(ctx context.Context, reason string, recovery int) {
+	for _, action := range w.actions {
+		start := time.Now()
+		err := w.runAction(ctx, action, reason, recovery)
+		attrs := []any{"action", action, "recovery", recovery, "ms", time.Since(start).Milliseconds()}
+		if err != nil {
+			w.log.Error("recovery action failed", append(attrs, "err", err)...)
+			continue
+		}
+		w.log.Info("recovery action done", attrs...)
+	}
+
+	w.mu.Lock()
+	ioErr := w.ioErr
+	w.mu.Unlock()
+	if w.port != nil && ioErr == nil {
+		return
+	}
+
+	// The port failed or was reset; reopen it for the next round
+	w.close()
+	reopenCtx, cancel := context.WithTimeout(ctx, w.reopenTimeout)
+	defer cancel()
+	if err := w.open(reopenCtx, true); err != nil {
+		w.log.Error("port not back", "recovery", recovery, "err", err)
+		return
+	}
+	w.log.Info("port reopened", "recovery", recovery, "path", w.portPath)
+}
+
+// runAction performs one recovery action
+func (w *watchdog) runAction(ctx context.Context, action, reason string, recovery int) error {
+	switch action {
+	case "dtr":
+		if w.port == nil {
+			return errors.New("port unavailable")
+		}
+		if err := w.port.SetDTR(false); err != nil {
+			return err
+		}
+		select {
+		case <-time.After(w.dtrPulse):
+		case <-ctx.Done():
+		}
+		return w.port.SetDTR(true)
+
+	case "usb-reset":
+		w.close()
+		if w.usbSerial != "" {
+			return serial.ResetUSBDeviceBySerial(w.usbSerial)
+		}
+		return serial.ResetUSBDeviceContext(ctx, w.portPath, withDiagnostics())
+
+	case "hook":
+		hookCtx, cancel := context.WithTimeout(ctx, w.hookTimeout)
+		defer cancel()
+		hook := exec.CommandContext(hookCtx, "sh", "-c", w.hook)
+		hook.Env = append(os.Environ(),
+			"SERIAL_PORT="+w.portPath,
+			"SERIAL_WATCHDOG_REASON="+reason,
+			"SERIAL_WATCHDOG_RECOVERY="+strconv.Itoa(recovery))
+		out, err := hook.CombinedOutput()
+		if output := strings.TrimSpace(string(out)); output != "" {
+			w.log.Info("hook output", "recovery", recovery, "output", output)
+		}
+		return err
+	}
+	return fmt.Errorf("unknown action %q", action)
+}
+
+// open opens the port and starts its reader and prober; with retry it
+// keeps trying until ctx is done, following a USB adapter to a new path
+func (w *watchdog) open(ctx context.Context, retry bool) error {
+	port, err := serial.Open(w.portPath, w.opts...)
+	if err != nil && retry {
+		var path string
+		if port, path = reopenPort(ctx, w.portPath, w.usbSerial, time.Second, w.opts...); port != nil {
+			w.portPath, err = path, nil
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	w.port = port
+	w.mu.Lock()
+	w.lastRX = time.Now()
+	w.ctsTimedOut = 0
+	w.ioErr = nil
+	w.mu.Unlock()
+
+	ioCtx, cancel := context.WithCancel(context.Background())
+	w.ioCancel = cancel
+	if !w.counters {
+		w.ioDone.Add(1)
+		go w.read(ioCtx, port)
+	}
+	if w.probe != nil {
+		w.ioDone.Add(1)
+		go w.sendProbes(ioCtx, port)
+	}
+	return nil
+}
+
+// close stops the reader and prober and closes the port
+func (w *watchdog) close() {
+	if w.port == nil {
+		return
+	}
+	w.ioCancel()
+	w.ioDone.Wait()
+	w.port.Close()
+	w.port = nil
+}
+
+// read discards received data, noting when it arrives
+func (w *watchdog) read(ctx context.Context, port serial.Port) {
+	defer w.ioDone.Done()
+	buf := make([]byte, 4096)
+	for ctx.Err() == nil {
+		n, err := port.ReadContext(ctx, buf)
+		if err != nil {
+			if ctx.Err() == nil {
+				w.setIOErr(err)
+			}
+			return
+		}
+		if n > 0 {
+			w.mu.Lock()
+			w.lastRX = time.Now()
+			w.mu.Unlock()
+		}
+	}
+}
+
+// sendProbes writes the keepalive every probe interval, counting writes
+// that time out on CTS
+func (w *watchdog) sendProbes(ctx context.Context, port serial.Port) {
+	defer w.ioDone.Done()
+	ticker := time.NewTicker(w.probeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		_, err := port.WriteContext(ctx, w.probe)
+		w.mu.Lock()
+		if errors.Is(err, serial.ErrCTSTimeout) {
+			w.ctsTimedOut++
+			w.log.Debug("probe timed out on CTS", "consecutive", w.ctsTimedOut)
+		} else if err == nil {
+			w.ctsTimedOut = 0
+		}
+		w.mu.Unlock()
+		if err != nil && !errors.Is(err, serial.ErrCTSTimeout) && ctx.Err() == nil {
+			w.setIOErr(err)
+			return
+		}
+	}
+}
+
+func (w *watchdog) setIOErr(err error) {
+	w.mu.Lock()
+	if w.ioErr == nil {
+		w.ioErr = err
+	}
+	w.mu.Unlock()
+}
@@ -0,0 +1,298 @@
+/*
+Copyright © 2025 Mathias Djärv <mathias.djarv@allbinary.se>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/allbin/go-serial"
+	"github.com/allbin/go-serial/xmodem"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+)
+
+// xmodemCmd represents the xmodem command
+var xmodemCmd = &cobra.Command{
+	Use:   "xmodem",
+	Short: "Transfer files with XMODEM or YMODEM",
+	Long: `Send or receive files with XMODEM, XMODEM-1K or YMODEM, as the lrzsz tools
+sx, rx, sb and rb do, to load firmware through bootloaders and modems.
+
+The exit status is 0 when the transfer completed, 2 when it failed (the
+other side canceled, stopped answering or a block could not get through
+in --retries tries), and 1 on other errors, such as a port that does not
+open, for provisioning scripts.`,
+}
+
+// xmodemSendCmd represents the xmodem send command
+var xmodemSendCmd = &cobra.Command{
+	Use:   "send <file> <port>",
+	Short: "Send a file with XMODEM or YMODEM",
+	Long: `Send a file to a receiver that is waiting for it, such as a bootloader
+told to receive. The receiver picks the check: 'C' requests CRC-16, NAK the
+8-bit checksum of original XMODEM. --1k sends 1024-byte blocks in CRC mode.
+The last block is padded with SUB (0x1A).
+
+--ymodem sends the file as a YMODEM batch, with its name, size and
+modification time, in 1024-byte blocks.
+
+Example usage:
+  serial xmodem send firmware.bin /dev/ttyUSB0
+  serial xmodem send firmware.bin /dev/ttyUSB0 --1k --baud 921600
+  serial xmodem send firmware.bin /dev/ttyUSB0 --ymodem`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		filePath, portPath := args[0], args[1]
+		ymodem, _ := cmd.Flags().GetBool("ymodem")
+		oneK, _ := cmd.Flags().GetBool("1k")
+
+		f, err := os.Open(filePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		stat, err := f.Stat()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		port, opts := openXModemPort(cmd, portPath)
+		defer port.Close()
+		if oneK {
+			opts = append(opts, xmodem.With1K())
+		}
+
+		progress := newSendProgress(stat.Size())
+		opts = append(opts, xmodem.WithProgress(func(p xmodem.Progress) {
+			progress.update(p.Bytes)
+		}))
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		fmt.Printf("Sending %s (%d bytes); start the receiver now\n", filePath, stat.Size())
+		if ymodem {
+			file := xmodem.File{
+				Header: xmodem.Header{Name: filepath.Base(filePath), Size: stat.Size(), ModTime: stat.ModTime()},
+				Data:   f,
+			}
+			err = xmodem.SendBatch(ctx, port, []xmodem.File{file}, opts...)
+		} else {
+			err = xmodem.Send(ctx, port, f, opts...)
+		}
+		progress.finish()
+		xmodemExit(err, progress.sent, progress.start)
+	},
+}
+
+// xmodemReceiveCmd represents the xmodem receive command
+var xmodemReceiveCmd = &cobra.Command{
+	Use:   "receive <file> <port>",
+	Short: "Receive a file with XMODEM or YMODEM",
+	Long: `Receive a file from a sender, asking it to start every 3 seconds until it
+does. CRC-16 is requested, falling back to the 8-bit checksum after three
+unanswered requests; --checksum requests the checksum from the start, for
+senders that only know original XMODEM. 128- and 1024-byte blocks are both
+accepted.
+
+XMODEM sends no file size, so the file keeps the SUB (0x1A) padding of the
+last block unless --trim removes it, which must not be used for data that
+may end in 0x1A itself.
+
+With --ymodem, <file> is the directory to write the received files to,
+under their own names and with their exact sizes.
+
+Example usage:
+  serial xmodem receive dump.bin /dev/ttyUSB0
+  serial xmodem receive dump.bin /dev/ttyUSB0 --trim --checksum
+  serial xmodem receive ./incoming /dev/ttyUSB0 --ymodem`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		filePath, portPath := args[0], args[1]
+		ymodem, _ := cmd.Flags().GetBool("ymodem")
+		checksum, _ := cmd.Flags().GetBool("checksum")
+		trim, _ := cmd.Flags().GetBool("trim")
+
+		if ymodem {
+			if stat, err := os.Stat(filePath); err != nil || !stat.IsDir() {
+				fmt.Fprintf(os.Stderr, "Error: %s is not a directory\n", filePath)
+				os.Exit(1)
+			}
+		}
+
+		port, opts := openXModemPort(cmd, portPath)
+		defer port.Close()
+		if checksum {
+			opts = append(opts, xmodem.WithChecksum())
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		fmt.Println("Waiting for the sender...")
+		if ymodem {
+			received, err := receiveYModem(ctx, port, filePath, opts)
+			xmodemExit(err, received, time.Time{})
+			return
+		}
+
+		f, err := os.Create(filePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		progress := newSendProgress(-1)
+		progress.icon = "📥"
+		opts = append(opts, xmodem.WithProgress(func(p xmodem.Progress) {
+			progress.update(p.Bytes)
+		}))
+		n, err := xmodem.Receive(ctx, port, f, opts...)
+		progress.finish()
+		if err == nil && trim {
+			n, err = trimPadding(f, n)
+		}
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		xmodemExit(err, n, progress.start)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(xmodemCmd)
+	xmodemCmd.AddCommand(xmodemSendCmd, xmodemReceiveCmd)
+
+	for _, cmd := range []*cobra.Command{xmodemSendCmd, xmodemReceiveCmd} {
+		addPortFlags(cmd, 100*time.Millisecond)
+		cmd.Flags().Bool("ymodem", false, "Use YMODEM, which sends file names and sizes")
+		cmd.Flags().Int("retries", xmodem.DefaultRetries, "Tries per block before giving up")
+		cmd.Flags().Duration("timeout", xmodem.DefaultTimeout, "How long to wait for a block or its acknowledgement")
+		cmd.Flags().Duration("start-timeout", xmodem.DefaultStartTimeout, "How long to wait for the other side to start")
+	}
+	xmodemSendCmd.Flags().Bool("1k", false, "Send 1024-byte blocks (XMODEM-1K) when the receiver uses CRC")
+	xmodemReceiveCmd.Flags().Bool("checksum", false, "Request the 8-bit checksum instead of CRC-16")
+	xmodemReceiveCmd.Flags().Bool("trim", false, "Remove the SUB padding from the end of an XMODEM file")
+}
+
+// openXModemPort opens the port of an xmodem command, exiting on errors,
+// and returns the transfer options of the shared flags
+func openXModemPort(cmd *cobra.Command, portPath string) (serial.Port, []xmodem.Option) {
+	retries, _ := cmd.Flags().GetInt("retries")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	startTimeout, _ := cmd.Flags().GetDuration("start-timeout")
+
+	serialOpts, config, err := portOptions(cmd)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	port, err := serial.Open(portPath, serialOpts...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening port: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Opened %s at %s\n", portPath, formatLineSettings(config))
+
+	return port, []xmodem.Option{
+		xmodem.WithRetries(retries),
+		xmodem.WithTimeout(timeout),
+		xmodem.WithStartTimeout(startTimeout),
+	}
+}
+
+// receiveYModem receives a YMODEM batch into dir, showing the progress of
+// each file, and returns the number of bytes received
+func receiveYModem(ctx context.Context, port serial.Port, dir string, opts []xmodem.Option) (int64, error) {
+	var progress *sendProgress
+	var total int64
+	opts = append(opts, xmodem.WithProgress(func(p xmodem.Progress) {
+		progress.update(p.Bytes)
+	}))
+	create := func(h xmodem.Header) (io.WriteCloser, error) {
+		// Names come from the other side, so they may not leave dir
+		name := filepath.Base(h.Name)
+		if name == "." || name == ".." || name == string(filepath.Separator) {
+			return nil, fmt.Errorf("invalid file name %q", h.Name)
+		}
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		fmt.Printf("Receiving %s (%d bytes)\n", name, h.Size)
+		progress = newSendProgress(h.Size)
+		progress.icon = "📥"
+		return &ymodemFile{File: f, header: h, progress: progress, total: &total}, nil
+	}
+	_, err := xmodem.ReceiveBatch(ctx, port, create, opts...)
+	if err != nil && progress != nil {
+		progress.finish()
+	}
+	return total, err
+}
+
+// ymodemFile finishes the progress bar and sets the modification time of
+// a received file when it is closed
+type ymodemFile struct {
+	*os.File
+	header   xmodem.Header
+	progress *sendProgress
+	total    *int64
+}
+
+func (f *ymodemFile) Close() error {
+	f.progress.finish()
+	*f.total += f.progress.sent
+	if err := f.File.Close(); err != nil {
+		return err
+	}
+	if !f.header.ModTime.IsZero() {
+		return os.Chtimes(f.Name(), f.header.ModTime, f.header.ModTime)
+	}
+	return nil
+}
+
+// trimPadding removes trailing SUB bytes, the padding of the last block,
+// from the n bytes written to f and returns the new size
+func trimPadding(f *os.File, n int64) (int64, error) {
+	tail := make([]byte, min(n, 1024))
+	if _, err := f.ReadAt(tail, n-int64(len(tail))); err != nil {
+		return n, err
+	}
+	for len(tail) > 0 && tail[len(tail)-1] == xmodem.SUB {
+		tail = tail[:len(tail)-1]
+		n--
+	}
+	return n, f.Truncate(n)
+}
+
+// xmodemExit reports the result of a transfer and exits with status 2 if
+// it failed
+func xmodemExit(err error, bytes int64, start time.Time) {
+	successStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("40")).
+		Bold(true)
+
+	errorStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("196")).
+		Bold(true)
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s Transfer failed after %d bytes: %v\n", errorStyle.Render("✗"), bytes, err)
+		os.Exit(2)
+	}
+	if start.IsZero() {
+		fmt.Printf("%s Transferred %d bytes\n", successStyle.Render("✓"), bytes)
+		return
+	}
+	fmt.Printf("%s Transferred %d bytes in %v\n", successStyle.Render("✓"), bytes, time.Since(start).Round(time.Millisecond))
+}
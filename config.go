@@ -1,6 +1,9 @@
 package serial
 
-import "time"
+import (
+	"io"
+	"time"
+)
 
 // WriteMode represents the write synchronization mode
 type WriteMode int
@@ -12,16 +15,23 @@ const (
 
 // Config holds the configuration for a serial port
 type Config struct {
-	BaudRate    int
-	DataBits    int
-	StopBits    int
-	Parity      Parity
-	FlowControl FlowControl
-	CTSTimeout  time.Duration
-	ReadTimeout time.Duration // VTIME setting (max 25.5 seconds, rounded to deciseconds)
-	WriteMode   WriteMode     // Controls write synchronization behavior
-	InitialRTS  *bool         // Initial RTS state (nil = hardware default)
-	InitialDTR  *bool         // Initial DTR state (nil = hardware default)
+	BaudRate        int
+	DataBits        int
+	StopBits        int
+	Parity          Parity
+	FlowControl     FlowControl
+	CTSTimeout      time.Duration
+	CTSPriority     int             // SCHED_FIFO priority of the CTS monitor thread (0 = normal scheduling)
+	ReadTimeout     time.Duration   // VTIME setting (max 25.5 seconds, rounded to deciseconds)
+	WriteMode       WriteMode       // Controls write synchronization behavior
+	InitialRTS      *bool           // Initial RTS state (nil = hardware default)
+	InitialDTR      *bool           // Initial DTR state (nil = hardware default)
+	Logger          Logger          // Diagnostics (default DiscardLogger)
+	Instrumentation Instrumentation // Tracing spans and counters (nil = disabled)
+	Tap             io.Writer       // Mirror of all RX/TX traffic (nil = disabled)
+	TapFormat       TapFormat       // How Tap output is formatted
+	Trace           io.Writer       // Ioctl trace output (nil = disabled)
+	KeepSettings    bool            // Leave the device's termios as found
 }
 
 // Option is a functional option for configuring a serial port
@@ -38,6 +48,7 @@ func DefaultConfig() Config {
 		CTSTimeout:  60 * time.Second,        // Neocortec reference default (matches NcConstants.DefaultCtsTimeOutMs)
 		ReadTimeout: 2500 * time.Millisecond, // 2.5 seconds - match reference 250ms * 10
 		WriteMode:   WriteModeBuffered,
+		Logger:      DiscardLogger,
 	}
 }
 
@@ -148,3 +159,25 @@ func WithInitialDTR(state bool) Option {
 		return nil
 	}
 }
+
+// WithKeepSettings opens a local device without configuring its line
+// The speed, character format, flow control and timeouts stay as another
+// program or stty left them, so the port can be observed without
+// disturbing it. The line options are then ignored.
+func WithKeepSettings() Option {
+	return func(c *Config) error {
+		c.KeepSettings = true
+		return nil
+	}
+}
+
+// WithLogger routes the port's diagnostic messages to logger
+func WithLogger(logger Logger) Option {
+	return func(c *Config) error {
+		if logger == nil {
+			return ErrInvalidConfig
+		}
+		c.Logger = logger
+		return nil
+	}
+}
@@ -43,6 +43,13 @@
 //	        info.Path, info.Description, info.VendorID, info.ProductID, info.SerialNumber)
 //	}
 //
+// WatchPorts reports ports as they are plugged in and unplugged:
+//
+//	events, err := serial.WatchPorts(ctx, 0)
+//	for ev := range events {
+//	    fmt.Println(ev.Kind, ev.Info.Path, ev.Info.SerialNumber)
+//	}
+//
 // # Hardware Flow Control
 //
 // Monitor and control modem signals (CTS, DSR, DCD, RI, RTS, DTR):
@@ -56,12 +63,44 @@
 //	err = port.SetRTS(true)
 //	err = port.SetDTR(false)
 //
+//	// Reset an Arduino-style board through its DTR auto-reset circuit
+//	err = serial.PulseDTR(port, 100*time.Millisecond)
+//
 //	// Wait for signal changes (event-driven)
 //	signals, changed, err := port.WaitForSignalChange(
 //	    serial.SignalDSR|serial.SignalDCD,
 //	    5*time.Second,
 //	)
 //
+// With FlowControlCTS, writes wait for CTS on a monitor goroutine and go out
+// the moment it activates. For CTS windows of a few milliseconds or less,
+// WithCTSRealtime gives the monitor its own thread at SCHED_FIFO priority,
+// and GetCTSLatency reports how long writes took after CTS woke it.
+//
+// # Network Ports
+//
+// Open accepts "rfc2217://host:port" (Telnet COM-PORT-OPTION) and
+// "tcp://host:port" (raw socket) devices in place of a device path:
+//
+//	port, err := serial.Open("rfc2217://192.168.1.50:2217", serial.WithBaudRate(9600))
+//
+// # Request/Response Transactions
+//
+// Write a request and wait for a matching reply, retrying on timeout:
+//
+//	resp, err := serial.Transact(ctx, port, []byte("READ?\r\n"),
+//	    serial.MatchSuffix([]byte("\r\n")), 2, 500*time.Millisecond)
+//
+// # Virtual Null-Modem Pairs
+//
+// OpenVirtualPair creates two linked PTYs for testing without hardware,
+// optionally throttled to a baud rate:
+//
+//	pair, err := serial.OpenVirtualPair(serial.WithPairBaudRate(9600))
+//	defer pair.Close()
+//	device, _ := serial.Open(pair.PathA)
+//	app, _ := serial.Open(pair.PathB)
+//
 // # USB Device Management (Linux)
 //
 // Reset hung USB devices programmatically:
@@ -74,6 +113,54 @@
 //
 // Requires usbreset utility from usbutils package and root/sudo permissions.
 //
+// # Break Signals
+//
+// SendBreak holds the TX line low after pending output drains, on serial
+// ports and RFC 2217 connections:
+//
+//	err := serial.SendBreak(port, 250*time.Millisecond)
+//
+// # Line Counters
+//
+// GetLineCounts reads the counters a UART driver keeps of modem line
+// transitions, bytes transferred and receive errors; PTYs and network ports
+// return ErrNotSupported:
+//
+//	counts, err := serial.GetLineCounts(port)
+//
+// # Traffic Tap
+//
+// WithTrafficTap mirrors all RX/TX bytes to a writer, as raw bytes, hex
+// lines or hexdump blocks:
+//
+//	port, err := serial.Open("/dev/ttyUSB0", serial.WithTrafficTap(os.Stderr, serial.TapHex))
+//
+// # Ioctl Trace
+//
+// WithTrace logs every termios and modem-control ioctl, including CTS waits,
+// with monotonic timestamps, for debugging flow-control issues:
+//
+//	port, err := serial.Open("/dev/ttyUSB0", serial.WithTrace(os.Stderr))
+//
+// # Logging
+//
+// Diagnostics go to a Logger, which *slog.Logger satisfies; the default
+// discards them:
+//
+//	port, err := serial.Open("/dev/ttyUSB0", serial.WithLogger(slog.Default()))
+//
+// # Tracing and Metrics
+//
+// WithInstrumentation reports spans (serial.open, serial.write,
+// serial.cts_wait, serial.usb_reset) and counters (bytes, timeouts, errors)
+// to an Instrumentation, typically a small OpenTelemetry adapter. Spans from
+// WriteContext are children of the span in the caller's context:
+//
+//	port, err := serial.Open("/dev/ttyUSB0", serial.WithInstrumentation(inst))
+//	n, err := port.WriteContext(requestCtx, frame)
+//
+// The metrics subpackage turns these hooks into Prometheus metrics.
+//
 // # Context Support
 //
 // All I/O operations support context for timeout and cancellation control:
@@ -84,6 +171,44 @@
 //	n, err := port.WriteContext(ctx, data)
 //	n, err = port.ReadContext(ctx, buffer)
 //
+// ReadTimestamped also returns when the data arrived, stamped as the read
+// syscall returns so scheduler delays do not skew latency measurements:
+//
+//	n, at, err := serial.ReadTimestamped(ctx, port, buffer)
+//
+// DrainInputContext discards input until the line goes quiet, ctx ends or
+// more than a byte limit was discarded; DrainInput applies
+// DefaultDrainTimeout and DefaultDrainLimit, so a device that never stops
+// sending cannot hold it indefinitely:
+//
+//	n, err := port.DrainInputContext(ctx, 4096)
+//
+// # Buffer Ownership
+//
+// Reads fill the caller's buffer and keep no reference to it once they
+// return, so a single buffer can be reused for every read; the read path
+// itself does not allocate. Reads whose context has no Done channel run on
+// the caller's goroutine, others on one reader goroutine per port. A read
+// abandoned by a cancelled context may still write to its buffer until
+// the read timeout expires.
+//
+// Write does not retain data after it returns, but a WriteContext call
+// whose context ended may still be writing it. A traffic tap's writer is
+// passed the caller's buffers and must not keep them.
+//
+// Code that hands received chunks to other goroutines can read into a
+// ReadSlab, which carves buffers out of large blocks, instead of copying
+// each chunk out of a reused buffer:
+//
+//	slab := serial.NewReadSlab(64 * 1024)
+//	n, err := port.ReadContext(ctx, slab.Next(4096))
+//	chunks <- slab.Take(n) // The receiver owns the chunk
+//
+// Port.Writev writes several buffers as one write, so framed protocols can
+// send a header, payload and checksum without copying them into one frame:
+//
+//	n, err := port.Writev([][]byte{header, payload, crc})
+//
 // # Error Handling
 //
 // The library provides specific error types for robust error handling:
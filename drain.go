@@ -0,0 +1,46 @@
+package serial
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// Bounds of DrainInput, which takes no context
+const (
+	DefaultDrainLimit   = 64 * 1024       // Bytes discarded before giving up
+	DefaultDrainTimeout = 2 * time.Second // Time spent before giving up
+)
+
+// drainQuiet is how long input must stay silent for draining to finish
+// It exceeds the 16 ms latency timer with which USB adapters hold back
+// data in transit.
+const drainQuiet = 50 * time.Millisecond
+
+// drainWithDefaults runs drain within DefaultDrainTimeout and
+// DefaultDrainLimit, reporting either bound as ErrDrainIncomplete
+func drainWithDefaults(drain func(context.Context, int) (int, error)) error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultDrainTimeout)
+	defer cancel()
+
+	_, err := drain(ctx, DefaultDrainLimit)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrDrainIncomplete
+	}
+	return err
+}
+
+// pollInput waits up to timeout for input on fd
+func pollInput(fd int, timeout time.Duration) (bool, error) {
+	fds := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLIN}}
+	ts := unix.NsecToTimespec(int64(timeout))
+	for {
+		n, err := unix.Ppoll(fds, &ts, nil)
+		if err == unix.EINTR {
+			continue
+		}
+		return n > 0, err
+	}
+}
@@ -0,0 +1,126 @@
+package serial
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestDrainInputIdle(t *testing.T) {
+	// A long read timeout used to hold every drain for its full length
+	port, _ := openPTYPort(t, WithReadTimeout(2500*time.Millisecond))
+
+	start := time.Now()
+	if err := port.DrainInput(); err != nil {
+		t.Fatalf("DrainInput failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("DrainInput on an idle port took %s", elapsed)
+	}
+}
+
+func TestDrainInputContext(t *testing.T) {
+	port, send := openPTYPort(t, WithReadTimeout(100*time.Millisecond))
+
+	// Data in transit after the flush is discarded too
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		send([]byte("late data"))
+	}()
+	n, err := port.DrainInputContext(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("DrainInputContext failed: %v", err)
+	}
+	if n != len("late data") {
+		t.Errorf("DrainInputContext discarded %d bytes, want %d", n, len("late data"))
+	}
+
+	// A device that never goes quiet is given up on
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case <-time.After(5 * time.Millisecond):
+				send([]byte("streaming"))
+			}
+		}
+	}()
+
+	if _, err := port.DrainInputContext(context.Background(), 100); !errors.Is(err, ErrDrainIncomplete) {
+		t.Errorf("DrainInputContext with limit error = %v, want ErrDrainIncomplete", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := port.DrainInputContext(ctx, 1<<20); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("DrainInputContext with deadline error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 400*time.Millisecond {
+		t.Errorf("DrainInputContext overran its deadline: %s", elapsed)
+	}
+}
+
+func TestDrainOutput(t *testing.T) {
+	master, slavePath, err := OpenPTY()
+	if err != nil {
+		t.Skipf("PTY not available: %v", err)
+	}
+	defer master.Close()
+
+	port, err := Open(slavePath, WithReadTimeout(100*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Open(%s) failed: %v", slavePath, err)
+	}
+	defer port.Close()
+
+	data := bytes.Repeat([]byte("0123456789abcdef"), 64)
+	if _, err := port.Write(data); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := port.DrainOutput(); err != nil {
+		t.Fatalf("DrainOutput failed: %v", err)
+	}
+	levels, err := GetBufferLevels(port)
+	if err != nil {
+		t.Fatalf("GetBufferLevels failed: %v", err)
+	}
+	if levels.Output != 0 {
+		t.Errorf("%d bytes still queued for output after DrainOutput", levels.Output)
+	}
+
+	got := make([]byte, len(data))
+	if _, err := io.ReadFull(master, got); err != nil {
+		t.Fatalf("master read failed: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Error("master did not receive the data written before DrainOutput")
+	}
+
+	port.Close()
+	if err := port.DrainOutput(); err != ErrPortClosed {
+		t.Errorf("DrainOutput after Close error = %v, want ErrPortClosed", err)
+	}
+}
+
+func TestIgnoringEINTR(t *testing.T) {
+	calls := 0
+	err := ignoringEINTR(func() error {
+		calls++
+		if calls < 3 {
+			return unix.EINTR
+		}
+		return unix.EIO
+	})
+	if err != unix.EIO || calls != 3 {
+		t.Errorf("ignoringEINTR = %v after %d calls, want EIO after 3", err, calls)
+	}
+}
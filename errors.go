@@ -13,6 +13,8 @@ var (
 	ErrPortClosed       = errors.New("serial port is closed")
 	ErrWriteTimeout     = errors.New("write operation timed out")
 	ErrReadTimeout      = errors.New("read operation timed out")
+	ErrNotSupported     = errors.New("operation not supported by this port")
+	ErrDrainIncomplete  = errors.New("input did not go quiet while draining")
 
 	// Signal monitoring errors
 	ErrSignalTimeout     = errors.New("timeout waiting for signal change")
@@ -0,0 +1,528 @@
+package firmata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	serial "github.com/allbin/go-serial"
+)
+
+// Predefined errors for Firmata communication
+var (
+	ErrClosed       = errors.New("firmata client is closed")
+	ErrTimeout      = errors.New("firmata request timed out")
+	ErrInvalidPin   = errors.New("invalid firmata pin")
+	ErrInvalidValue = errors.New("invalid firmata value")
+)
+
+// Defaults used by Open and New
+const (
+	DefaultBaudRate = 57600 // StandardFirmata default
+	DefaultTimeout  = 5 * time.Second
+)
+
+// resetPulse is the DTR low time used to reboot the board in Open
+const resetPulse = 100 * time.Millisecond
+
+// maxSysexSize bounds buffered sysex messages so a lost END_SYSEX cannot grow memory
+const maxSysexSize = 4096
+
+// sysexBufferSize is the per-subscriber channel capacity; messages are dropped when a subscriber falls behind
+const sysexBufferSize = 16
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithTimeout sets the default timeout for requests awaiting a reply
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithBaudRate sets the baud rate used by Open
+func WithBaudRate(rate int) Option {
+	return func(c *Client) {
+		c.baudRate = rate
+	}
+}
+
+// Client controls a board running Firmata
+type Client struct {
+	rw       io.ReadWriter
+	closer   io.Closer // Set when Open created the port
+	timeout  time.Duration
+	baudRate int
+
+	writeMu sync.Mutex
+
+	mu         sync.Mutex
+	digitalOut [16]uint8 // Output state per 8-pin port, for DIGITAL_MESSAGE
+	digitalIn  [128]bool
+	analog     [16]int
+	version    [2]int
+	firmware   *Firmware
+	subs       map[int]*subscription
+	nextSub    int
+	closed     bool
+	err        error
+	done       chan struct{}
+}
+
+type subscription struct {
+	cmd byte
+	ch  chan []byte
+}
+
+// Open opens device, resets the board through DTR and waits for Firmata to report its firmware
+// The returned client owns the port and closes it on Close.
+func Open(ctx context.Context, device string, opts ...Option) (*Client, error) {
+	cfg := &Client{baudRate: DefaultBaudRate}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	port, err := serial.Open(device,
+		serial.WithBaudRate(cfg.baudRate),
+		serial.WithReadTimeout(100*time.Millisecond),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := serial.PulseDTR(port, resetPulse); err != nil {
+		port.Close()
+		return nil, err
+	}
+
+	c := New(port, opts...)
+	c.closer = port
+
+	// The bootloader ignores input for a moment after reset; keep asking
+	for {
+		reqCtx, cancel := context.WithTimeout(ctx, time.Second)
+		_, err := c.QueryFirmware(reqCtx)
+		cancel()
+		if err == nil {
+			return c, nil
+		}
+		if ctx.Err() != nil || errors.Is(err, ErrClosed) || c.isClosed() {
+			c.Close()
+			return nil, fmt.Errorf("firmata handshake on %s failed: %w", device, err)
+		}
+	}
+}
+
+// New creates a Client on an already open port and starts reading in a background goroutine
+// rw should be opened with a read timeout so the reader goroutine can observe Close.
+func New(rw io.ReadWriter, opts ...Option) *Client {
+	c := &Client{
+		rw:       rw,
+		timeout:  DefaultTimeout,
+		baudRate: DefaultBaudRate,
+		subs:     make(map[int]*subscription),
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	go c.readLoop()
+
+	return c
+}
+
+// SetPinMode configures the function of a pin
+func (c *Client) SetPinMode(pin int, mode PinMode) error {
+	if pin < 0 || pin > 127 {
+		return fmt.Errorf("%w: %d", ErrInvalidPin, pin)
+	}
+	return c.write([]byte{cmdSetPinMode, byte(pin), byte(mode)})
+}
+
+// DigitalWrite sets a digital output pin high or low
+func (c *Client) DigitalWrite(pin int, value bool) error {
+	if pin < 0 || pin >= 8*len(c.digitalOut) {
+		return fmt.Errorf("%w: %d", ErrInvalidPin, pin)
+	}
+
+	c.mu.Lock()
+	port := pin / 8
+	if value {
+		c.digitalOut[port] |= 1 << (pin % 8)
+	} else {
+		c.digitalOut[port] &^= 1 << (pin % 8)
+	}
+	mask := c.digitalOut[port]
+	c.mu.Unlock()
+
+	return c.write([]byte{cmdDigitalMessage | byte(port), mask & 0x7F, mask >> 7})
+}
+
+// AnalogWrite sets a PWM or servo output value
+// Pins above 15 and values above 14 bits use the extended analog sysex message.
+func (c *Client) AnalogWrite(pin int, value int) error {
+	if pin < 0 || pin > 127 {
+		return fmt.Errorf("%w: %d", ErrInvalidPin, pin)
+	}
+	if value < 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidValue, value)
+	}
+
+	if pin < 16 && value < 1<<14 {
+		return c.write([]byte{cmdAnalogMessage | byte(pin), byte(value & 0x7F), byte(value >> 7 & 0x7F)})
+	}
+
+	data := []byte{byte(pin)}
+	for v := value; ; v >>= 7 {
+		data = append(data, byte(v&0x7F))
+		if v < 0x80 {
+			break
+		}
+	}
+	return c.SendSysex(SysexExtendedAnalog, data)
+}
+
+// ReportDigital enables or disables change reports for the 8-pin port containing pin
+func (c *Client) ReportDigital(pin int, enable bool) error {
+	if pin < 0 || pin >= 8*len(c.digitalOut) {
+		return fmt.Errorf("%w: %d", ErrInvalidPin, pin)
+	}
+	return c.write([]byte{cmdReportDigital | byte(pin/8), boolByte(enable)})
+}
+
+// ReportAnalog enables or disables periodic reports for analog input channel (A0 = 0)
+func (c *Client) ReportAnalog(channel int, enable bool) error {
+	if channel < 0 || channel >= len(c.analog) {
+		return fmt.Errorf("%w: analog channel %d", ErrInvalidPin, channel)
+	}
+	return c.write([]byte{cmdReportAnalog | byte(channel), boolByte(enable)})
+}
+
+// SetSamplingInterval sets how often the board reports analog inputs
+func (c *Client) SetSamplingInterval(interval time.Duration) error {
+	ms := int(interval / time.Millisecond)
+	if ms <= 0 || ms >= 1<<14 {
+		return fmt.Errorf("%w: sampling interval %v", ErrInvalidValue, interval)
+	}
+	return c.SendSysex(SysexSamplingInterval, []byte{byte(ms & 0x7F), byte(ms >> 7)})
+}
+
+// DigitalRead returns the last reported state of a digital input pin
+// Enable reporting with ReportDigital first.
+func (c *Client) DigitalRead(pin int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if pin < 0 || pin >= len(c.digitalIn) {
+		return false
+	}
+	return c.digitalIn[pin]
+}
+
+// AnalogRead returns the last reported value of an analog input channel
+// Enable reporting with ReportAnalog first.
+func (c *Client) AnalogRead(channel int) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if channel < 0 || channel >= len(c.analog) {
+		return 0
+	}
+	return c.analog[channel]
+}
+
+// Version returns the protocol version reported by the board (0.0 until reported)
+func (c *Client) Version() (major, minor int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.version[0], c.version[1]
+}
+
+// Firmware returns the last firmware report, or nil if none was received
+func (c *Client) Firmware() *Firmware {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.firmware
+}
+
+// QueryFirmware asks the board for its firmware name and version
+func (c *Client) QueryFirmware(ctx context.Context) (*Firmware, error) {
+	data, err := c.SysexRequest(ctx, SysexReportFirmware, nil, SysexReportFirmware)
+	if err != nil {
+		return nil, err
+	}
+	fw, err := parseFirmware(data)
+	if err != nil {
+		return nil, err
+	}
+	return fw, nil
+}
+
+// Reset sends SYSTEM_RESET, returning the firmware to its power-on state
+func (c *Client) Reset() error {
+	return c.write([]byte{cmdSystemReset})
+}
+
+// SendSysex sends a sysex message; data bytes must be 7-bit
+func (c *Client) SendSysex(cmd byte, data []byte) error {
+	if cmd > 0x7F {
+		return fmt.Errorf("%w: sysex command 0x%02X", ErrInvalidValue, cmd)
+	}
+	msg := make([]byte, 0, len(data)+3)
+	msg = append(msg, cmdStartSysex, cmd)
+	for _, b := range data {
+		if b > 0x7F {
+			return fmt.Errorf("%w: sysex data byte 0x%02X is not 7-bit (use Encode7Bit)", ErrInvalidValue, b)
+		}
+		msg = append(msg, b)
+	}
+	return c.write(append(msg, cmdEndSysex))
+}
+
+// SysexRequest sends a sysex message and waits for a reply with command reply
+// The returned data excludes the command byte.
+func (c *Client) SysexRequest(ctx context.Context, cmd byte, data []byte, reply byte) ([]byte, error) {
+	ch, cancel := c.SubscribeSysex(reply)
+	defer cancel()
+
+	if err := c.SendSysex(cmd, data); err != nil {
+		return nil, err
+	}
+
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+
+	select {
+	case resp, ok := <-ch:
+		if !ok {
+			return nil, c.closedErr()
+		}
+		return resp, nil
+	case <-timer.C:
+		return nil, fmt.Errorf("%w: sysex 0x%02X", ErrTimeout, cmd)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// SubscribeSysex receives sysex messages with the given command (data excludes the command byte)
+// The returned function cancels the subscription and closes the channel.
+func (c *Client) SubscribeSysex(cmd byte) (<-chan []byte, func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	sub := &subscription{cmd: cmd, ch: make(chan []byte, sysexBufferSize)}
+	if c.closed {
+		close(sub.ch)
+		return sub.ch, func() {}
+	}
+
+	id := c.nextSub
+	c.nextSub++
+	c.subs[id] = sub
+
+	var once sync.Once
+	return sub.ch, func() {
+		once.Do(func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			if _, ok := c.subs[id]; ok {
+				delete(c.subs, id)
+				close(sub.ch)
+			}
+		})
+	}
+}
+
+// Close stops the client and closes the port if it was opened by Open
+func (c *Client) Close() error {
+	c.shutdown(ErrClosed)
+	if c.closer != nil {
+		return c.closer.Close()
+	}
+	return nil
+}
+
+// Done returns a channel that is closed when the client stops reading
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns the error that stopped the client, if any
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+func (c *Client) write(msg []byte) error {
+	if c.isClosed() {
+		return c.closedErr()
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if _, err := c.rw.Write(msg); err != nil {
+		return fmt.Errorf("failed to write firmata message: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) closedErr() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	return ErrClosed
+}
+
+func (c *Client) shutdown(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+	c.closed = true
+	c.err = err
+
+	for id, sub := range c.subs {
+		close(sub.ch)
+		delete(c.subs, id)
+	}
+	close(c.done)
+}
+
+func (c *Client) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+// readLoop parses the MIDI-style Firmata stream
+// Zero-length reads (serial VTIME timeouts) are tolerated.
+func (c *Client) readLoop() {
+	chunk := make([]byte, 256)
+	var (
+		msg     []byte
+		cmd     byte
+		need    int
+		inSysex bool
+	)
+
+	for {
+		n, err := c.rw.Read(chunk)
+		if c.isClosed() {
+			return
+		}
+		n = max(n, 0) // A failed read may report -1
+
+		for _, b := range chunk[:n] {
+			switch {
+			case inSysex && b == cmdEndSysex:
+				c.handleSysex(msg)
+				inSysex = false
+				msg = msg[:0]
+			case inSysex:
+				if len(msg) < maxSysexSize {
+					msg = append(msg, b)
+				}
+			case b == cmdStartSysex:
+				inSysex = true
+				cmd = 0
+				msg = msg[:0]
+			case b&0x80 != 0:
+				cmd = b
+				need = messageLength(b)
+				msg = msg[:0]
+				if need == 0 {
+					cmd = 0
+				}
+			case cmd != 0:
+				msg = append(msg, b)
+				if len(msg) == need {
+					c.handleMessage(cmd, msg)
+					cmd = 0
+					msg = msg[:0]
+				}
+			}
+		}
+
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			c.shutdown(err)
+			return
+		}
+	}
+}
+
+func (c *Client) handleMessage(cmd byte, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	value := int(data[0]) | int(data[1])<<7
+	switch {
+	case cmd&0xF0 == cmdDigitalMessage:
+		port := int(cmd & 0x0F)
+		for i := 0; i < 8; i++ {
+			c.digitalIn[port*8+i] = value&(1<<i) != 0
+		}
+	case cmd&0xF0 == cmdAnalogMessage:
+		c.analog[cmd&0x0F] = value
+	case cmd == cmdReportVersion:
+		c.version = [2]int{int(data[0]), int(data[1])}
+	}
+}
+
+func (c *Client) handleSysex(msg []byte) {
+	if len(msg) == 0 {
+		return
+	}
+	cmd := msg[0]
+	data := append([]byte(nil), msg[1:]...)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cmd == SysexReportFirmware {
+		if fw, err := parseFirmware(data); err == nil {
+			c.firmware = fw
+		}
+	}
+
+	for _, sub := range c.subs {
+		if sub.cmd != cmd {
+			continue
+		}
+		select {
+		case sub.ch <- data:
+		default:
+			// Subscriber is not keeping up; drop rather than stall the reader
+		}
+	}
+}
+
+// parseFirmware decodes a REPORT_FIRMWARE payload: major, minor, name as 7-bit pairs
+func parseFirmware(data []byte) (*Firmware, error) {
+	if len(data) < 2 {
+		return nil, fmt.Errorf("%w: firmware report of %d bytes", ErrInvalidValue, len(data))
+	}
+	return &Firmware{
+		Major: int(data[0]),
+		Minor: int(data[1]),
+		Name:  string(Decode7Bit(data[2:])),
+	}, nil
+}
+
+func boolByte(b bool) byte {
+	if b {
+		return 1
+	}
+	return 0
+}
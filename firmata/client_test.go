@@ -0,0 +1,216 @@
+package firmata
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// fakeBoard records messages written by the client and lets tests inject board output
+type fakeBoard struct {
+	toClient  *io.PipeWriter
+	fromBoard *io.PipeReader
+	written   chan []byte
+	replies   map[string][]byte // Written message -> reply
+}
+
+func newFakeBoard(replies map[string][]byte) *fakeBoard {
+	r, w := io.Pipe()
+	return &fakeBoard{
+		toClient:  w,
+		fromBoard: r,
+		written:   make(chan []byte, 16),
+		replies:   replies,
+	}
+}
+
+func (b *fakeBoard) Read(p []byte) (int, error) {
+	return b.fromBoard.Read(p)
+}
+
+func (b *fakeBoard) Write(p []byte) (int, error) {
+	msg := append([]byte(nil), p...)
+	b.written <- msg
+	if reply, ok := b.replies[string(msg)]; ok {
+		go b.toClient.Write(reply)
+	}
+	return len(p), nil
+}
+
+// waitFor polls cond until it is true or the deadline passes
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if cond() {
+			return
+		}
+	}
+	t.Fatal("condition not met within 1s")
+}
+
+func TestOutputMessages(t *testing.T) {
+	board := newFakeBoard(nil)
+	client := New(board)
+	defer client.Close()
+
+	tests := []struct {
+		name string
+		send func() error
+		want []byte
+	}{
+		{"pin mode", func() error { return client.SetPinMode(13, ModeOutput) }, []byte{0xF4, 13, 0x01}},
+		{"digital high", func() error { return client.DigitalWrite(13, true) }, []byte{0x91, 0x20, 0x00}},
+		{"digital same port", func() error { return client.DigitalWrite(15, true) }, []byte{0x91, 0x20, 0x01}},
+		{"digital low", func() error { return client.DigitalWrite(13, false) }, []byte{0x91, 0x00, 0x01}},
+		{"analog", func() error { return client.AnalogWrite(3, 200) }, []byte{0xE3, 0x48, 0x01}},
+		{"extended analog", func() error { return client.AnalogWrite(20, 1000) }, []byte{0xF0, 0x6F, 20, 0x68, 0x07, 0xF7}},
+		{"report analog", func() error { return client.ReportAnalog(2, true) }, []byte{0xC2, 0x01}},
+		{"report digital", func() error { return client.ReportDigital(9, true) }, []byte{0xD1, 0x01}},
+		{"sampling interval", func() error { return client.SetSamplingInterval(200 * time.Millisecond) }, []byte{0xF0, 0x7A, 0x48, 0x01, 0xF7}},
+		{"reset", client.Reset, []byte{0xFF}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.send(); err != nil {
+				t.Fatalf("send failed: %v", err)
+			}
+			if got := <-board.written; !bytes.Equal(got, tt.want) {
+				t.Errorf("wrote % X, want % X", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvalidArguments(t *testing.T) {
+	client := New(newFakeBoard(nil))
+	defer client.Close()
+
+	if err := client.SetPinMode(200, ModeInput); !errors.Is(err, ErrInvalidPin) {
+		t.Errorf("SetPinMode error = %v, want ErrInvalidPin", err)
+	}
+	if err := client.DigitalWrite(-1, true); !errors.Is(err, ErrInvalidPin) {
+		t.Errorf("DigitalWrite error = %v, want ErrInvalidPin", err)
+	}
+	if err := client.ReportAnalog(16, true); !errors.Is(err, ErrInvalidPin) {
+		t.Errorf("ReportAnalog error = %v, want ErrInvalidPin", err)
+	}
+	if err := client.SendSysex(0x71, []byte{0x80}); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("SendSysex error = %v, want ErrInvalidValue", err)
+	}
+}
+
+func TestInputReports(t *testing.T) {
+	board := newFakeBoard(nil)
+	client := New(board)
+	defer client.Close()
+
+	go board.toClient.Write([]byte{
+		0xF9, 0x02, 0x05, // Version 2.5
+		0x91, 0x05, 0x00, // Port 1: pins 8 and 10 high
+		0xE2, 0x7F, 0x07, // A2 = 1023
+		0x42, // Stray data byte without command
+	})
+
+	waitFor(t, func() bool { return client.AnalogRead(2) == 1023 })
+	if !client.DigitalRead(8) || client.DigitalRead(9) || !client.DigitalRead(10) {
+		t.Errorf("digital pins 8-10 = %v %v %v, want true false true",
+			client.DigitalRead(8), client.DigitalRead(9), client.DigitalRead(10))
+	}
+	if major, minor := client.Version(); major != 2 || minor != 5 {
+		t.Errorf("Version = %d.%d, want 2.5", major, minor)
+	}
+}
+
+func TestQueryFirmware(t *testing.T) {
+	reply := append([]byte{0xF0, 0x79, 0x02, 0x05}, Encode7Bit([]byte("StandardFirmata.ino"))...)
+	reply = append(reply, 0xF7)
+	board := newFakeBoard(map[string][]byte{
+		string([]byte{0xF0, 0x79, 0xF7}): reply,
+	})
+	client := New(board)
+	defer client.Close()
+
+	fw, err := client.QueryFirmware(context.Background())
+	if err != nil {
+		t.Fatalf("QueryFirmware failed: %v", err)
+	}
+	if fw.Major != 2 || fw.Minor != 5 || fw.Name != "StandardFirmata.ino" {
+		t.Errorf("firmware = %+v", fw)
+	}
+	if got := client.Firmware(); got == nil || got.Name != fw.Name {
+		t.Errorf("Firmware() = %+v, want cached report", got)
+	}
+}
+
+func TestSysexSubscribeAndTimeout(t *testing.T) {
+	board := newFakeBoard(nil)
+	client := New(board, WithTimeout(20*time.Millisecond))
+
+	ch, cancel := client.SubscribeSysex(SysexStringData)
+	defer cancel()
+
+	msg := append([]byte{0xF0, 0x71}, Encode7Bit([]byte("hi"))...)
+	go board.toClient.Write(append(msg, 0xF7))
+
+	select {
+	case data := <-ch:
+		if got := string(Decode7Bit(data)); got != "hi" {
+			t.Errorf("string data = %q, want hi", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no sysex message received")
+	}
+
+	if _, err := client.SysexRequest(context.Background(), SysexCapabilityQuery, nil, SysexCapabilityResponse); !errors.Is(err, ErrTimeout) {
+		t.Errorf("SysexRequest error = %v, want ErrTimeout", err)
+	}
+
+	client.Close()
+	if err := client.DigitalWrite(1, true); !errors.Is(err, ErrClosed) {
+		t.Errorf("write after Close error = %v, want ErrClosed", err)
+	}
+}
+
+// failingBoard fails its first read once released, reporting -1 bytes like
+// a serial port whose read system call failed
+type failingBoard struct {
+	*fakeBoard
+	release chan struct{}
+}
+
+var errBoardRead = errors.New("read failed")
+
+func (b *failingBoard) Read(p []byte) (int, error) {
+	<-b.release
+	return -1, errBoardRead
+}
+
+func TestReadErrorStopsClient(t *testing.T) {
+	board := &failingBoard{fakeBoard: newFakeBoard(nil), release: make(chan struct{})}
+	client := New(board, WithTimeout(time.Second))
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := client.SysexRequest(context.Background(), SysexCapabilityQuery, nil, SysexCapabilityResponse)
+		errCh <- err
+	}()
+	<-board.written // The request is waiting for its reply
+	close(board.release)
+
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, errBoardRead) {
+			t.Errorf("pending SysexRequest error = %v, want the read error", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("pending SysexRequest not woken by the read error")
+	}
+	<-client.Done()
+	if !errors.Is(client.Err(), errBoardRead) {
+		t.Errorf("Err() = %v, want the read error", client.Err())
+	}
+}
@@ -0,0 +1,141 @@
+// Package firmata implements a Firmata protocol client for microcontrollers
+// running StandardFirmata (Arduino and compatibles).
+//
+// The client configures pin modes, writes digital and analog (PWM) outputs,
+// enables reporting of inputs, and exchanges sysex messages:
+//
+//	client, err := firmata.Open(ctx, "/dev/ttyACM0")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer client.Close()
+//
+//	client.SetPinMode(13, firmata.ModeOutput)
+//	client.DigitalWrite(13, true)
+//
+//	client.ReportAnalog(0, true)
+//	value := client.AnalogRead(0)
+package firmata
+
+import "fmt"
+
+// Message commands (Firmata protocol 2.x)
+const (
+	cmdDigitalMessage     = 0x90 // 0x90 | port, LSB, MSB
+	cmdAnalogMessage      = 0xE0 // 0xE0 | pin, LSB, MSB
+	cmdReportAnalog       = 0xC0 // 0xC0 | pin, enable
+	cmdReportDigital      = 0xD0 // 0xD0 | port, enable
+	cmdStartSysex         = 0xF0
+	cmdSetPinMode         = 0xF4 // pin, mode
+	cmdSetDigitalPinValue = 0xF5 // pin, value
+	cmdEndSysex           = 0xF7
+	cmdReportVersion      = 0xF9 // major, minor
+	cmdSystemReset        = 0xFF
+)
+
+// Sysex commands
+const (
+	SysexAnalogMappingQuery    byte = 0x69
+	SysexAnalogMappingResponse byte = 0x6A
+	SysexCapabilityQuery       byte = 0x6B
+	SysexCapabilityResponse    byte = 0x6C
+	SysexPinStateQuery         byte = 0x6D
+	SysexPinStateResponse      byte = 0x6E
+	SysexExtendedAnalog        byte = 0x6F
+	SysexStringData            byte = 0x71
+	SysexReportFirmware        byte = 0x79
+	SysexSamplingInterval      byte = 0x7A
+)
+
+// PinMode is the function assigned to a pin
+type PinMode byte
+
+const (
+	ModeInput   PinMode = 0x00
+	ModeOutput  PinMode = 0x01
+	ModeAnalog  PinMode = 0x02
+	ModePWM     PinMode = 0x03
+	ModeServo   PinMode = 0x04
+	ModeShift   PinMode = 0x05
+	ModeI2C     PinMode = 0x06
+	ModeOneWire PinMode = 0x07
+	ModeStepper PinMode = 0x08
+	ModeEncoder PinMode = 0x09
+	ModeSerial  PinMode = 0x0A
+	ModePullup  PinMode = 0x0B
+)
+
+func (m PinMode) String() string {
+	switch m {
+	case ModeInput:
+		return "INPUT"
+	case ModeOutput:
+		return "OUTPUT"
+	case ModeAnalog:
+		return "ANALOG"
+	case ModePWM:
+		return "PWM"
+	case ModeServo:
+		return "SERVO"
+	case ModeShift:
+		return "SHIFT"
+	case ModeI2C:
+		return "I2C"
+	case ModeOneWire:
+		return "ONEWIRE"
+	case ModeStepper:
+		return "STEPPER"
+	case ModeEncoder:
+		return "ENCODER"
+	case ModeSerial:
+		return "SERIAL"
+	case ModePullup:
+		return "PULLUP"
+	default:
+		return fmt.Sprintf("PinMode(0x%02X)", byte(m))
+	}
+}
+
+// Firmware identifies the sketch running on the board
+type Firmware struct {
+	Major int
+	Minor int
+	Name  string // Sketch file name, e.g. "StandardFirmata.ino"
+}
+
+func (f Firmware) String() string {
+	return fmt.Sprintf("%s %d.%d", f.Name, f.Major, f.Minor)
+}
+
+// Encode7Bit splits each byte into two 7-bit bytes (LSB first) for sysex payloads
+func Encode7Bit(data []byte) []byte {
+	out := make([]byte, 0, 2*len(data))
+	for _, b := range data {
+		out = append(out, b&0x7F, b>>7)
+	}
+	return out
+}
+
+// Decode7Bit joins pairs of 7-bit bytes (LSB first) produced by Encode7Bit
+// A trailing unpaired byte is ignored.
+func Decode7Bit(data []byte) []byte {
+	out := make([]byte, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		out = append(out, data[i]&0x7F|data[i+1]<<7)
+	}
+	return out
+}
+
+// messageLength returns the number of data bytes following a command byte
+func messageLength(cmd byte) int {
+	switch {
+	case cmd&0xF0 == cmdDigitalMessage, cmd&0xF0 == cmdAnalogMessage:
+		return 2
+	case cmd&0xF0 == cmdReportAnalog, cmd&0xF0 == cmdReportDigital:
+		return 1
+	case cmd == cmdSetPinMode, cmd == cmdSetDigitalPinValue, cmd == cmdReportVersion:
+		return 2
+	default:
+		return 0
+	}
+}
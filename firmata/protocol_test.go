@@ -0,0 +1,52 @@
+package firmata
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncode7Bit(t *testing.T) {
+	data := []byte{0x00, 0x7F, 0x80, 0xFF, 'A'}
+	encoded := Encode7Bit(data)
+
+	want := []byte{0x00, 0x00, 0x7F, 0x00, 0x00, 0x01, 0x7F, 0x01, 0x41, 0x00}
+	if !bytes.Equal(encoded, want) {
+		t.Errorf("Encode7Bit = % X, want % X", encoded, want)
+	}
+	for _, b := range encoded {
+		if b > 0x7F {
+			t.Fatalf("encoded byte 0x%02X is not 7-bit", b)
+		}
+	}
+	if got := Decode7Bit(encoded); !bytes.Equal(got, data) {
+		t.Errorf("Decode7Bit = % X, want % X", got, data)
+	}
+	if got := Decode7Bit([]byte{0x41, 0x00, 0x42}); !bytes.Equal(got, []byte{'A'}) {
+		t.Errorf("Decode7Bit with odd length = % X, want 41", got)
+	}
+}
+
+func TestMessageLength(t *testing.T) {
+	tests := []struct {
+		cmd  byte
+		want int
+	}{
+		{0x90, 2}, {0x9F, 2}, {0xE5, 2}, {0xC0, 1}, {0xD3, 1},
+		{0xF4, 2}, {0xF5, 2}, {0xF9, 2}, {0xFF, 0}, {0xF7, 0},
+	}
+
+	for _, tt := range tests {
+		if got := messageLength(tt.cmd); got != tt.want {
+			t.Errorf("messageLength(0x%02X) = %d, want %d", tt.cmd, got, tt.want)
+		}
+	}
+}
+
+func TestPinModeString(t *testing.T) {
+	if got := ModePWM.String(); got != "PWM" {
+		t.Errorf("ModePWM.String() = %q", got)
+	}
+	if got := PinMode(0x7E).String(); got != "PinMode(0x7E)" {
+		t.Errorf("unknown String() = %q", got)
+	}
+}
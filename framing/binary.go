@@ -0,0 +1,334 @@
+package framing
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Marshal encodes a struct into its binary wire layout
+//
+// Fields are encoded in declaration order without padding. Supported field
+// types are bool, fixed-size integers, float32/64, arrays, nested structs,
+// and []byte/string. Multi-byte values are big-endian unless tagged otherwise.
+// The `serial` struct tag controls the layout:
+//
+//	type Reading struct {
+//	    ID     uint16                        // big-endian (default)
+//	    Temp   int16   `serial:"le"`         // little-endian
+//	    Name   string  `serial:"size=8"`     // fixed 8 bytes, zero padded
+//	    Flags  [2]byte
+//	    cached int                           // unexported fields are skipped
+//	    Debug  bool    `serial:"-"`          // skipped
+//	    Data   []byte                        // last field only: remaining bytes
+//	}
+//
+// Byte order tags ("le", "be") on a nested struct or array apply to its elements.
+func Marshal(v any) ([]byte, error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: Marshal of %s, want struct", ErrStructLayout, rv.Kind())
+	}
+	return appendStruct(nil, rv, binary.BigEndian)
+}
+
+// Unmarshal decodes data into the struct pointed to by v using the Marshal layout
+// Returns ErrInvalidFrame if data is shorter or longer than the layout.
+func Unmarshal(data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: Unmarshal requires a non-nil struct pointer", ErrStructLayout)
+	}
+
+	rest, err := decodeStruct(data, rv.Elem(), binary.BigEndian)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return fmt.Errorf("%w: %d unexpected trailing bytes", ErrInvalidFrame, len(rest))
+	}
+	return nil
+}
+
+// WriteStruct marshals v and writes it as one packet
+func WriteStruct(pp PacketPort, v any) error {
+	payload, err := Marshal(v)
+	if err != nil {
+		return err
+	}
+	return pp.WritePacket(payload)
+}
+
+// ReadStruct reads the next packet into v and returns its receive time
+func ReadStruct(ctx context.Context, pp PacketPort, v any) (time.Time, error) {
+	payload, ts, err := pp.ReadPacket(ctx)
+	if err != nil {
+		return ts, err
+	}
+	return ts, Unmarshal(payload, v)
+}
+
+// byteOrder reads and appends multi-byte values (binary.BigEndian, binary.LittleEndian)
+type byteOrder interface {
+	binary.ByteOrder
+	binary.AppendByteOrder
+}
+
+// fieldTag is the parsed `serial` struct tag
+type fieldTag struct {
+	skip  bool
+	order byteOrder
+	size  int // Fixed size for []byte/string, 0 if unset
+}
+
+func parseTag(tag string, order byteOrder) (fieldTag, error) {
+	ft := fieldTag{order: order}
+	if tag == "" {
+		return ft, nil
+	}
+	for _, opt := range strings.Split(tag, ",") {
+		switch opt = strings.TrimSpace(opt); {
+		case opt == "-":
+			ft.skip = true
+		case opt == "le":
+			ft.order = binary.LittleEndian
+		case opt == "be":
+			ft.order = binary.BigEndian
+		case strings.HasPrefix(opt, "size="):
+			n, err := strconv.Atoi(strings.TrimPrefix(opt, "size="))
+			if err != nil || n <= 0 {
+				return ft, fmt.Errorf("%w: invalid size in tag %q", ErrStructLayout, tag)
+			}
+			ft.size = n
+		default:
+			return ft, fmt.Errorf("%w: unknown tag option %q", ErrStructLayout, opt)
+		}
+	}
+	return ft, nil
+}
+
+// structFields yields the encodable fields of a struct with their parsed tags
+func structFields(rv reflect.Value, order byteOrder, fn func(f reflect.Value, name string, tag fieldTag, last bool) error) error {
+	rt := rv.Type()
+
+	var fields []int
+	for i := 0; i < rt.NumField(); i++ {
+		if rt.Field(i).IsExported() {
+			fields = append(fields, i)
+		}
+	}
+
+	for n, i := range fields {
+		sf := rt.Field(i)
+		tag, err := parseTag(sf.Tag.Get("serial"), order)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", sf.Name, err)
+		}
+		if tag.skip {
+			continue
+		}
+		if err := fn(rv.Field(i), sf.Name, tag, n == len(fields)-1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func appendStruct(dst []byte, rv reflect.Value, order byteOrder) ([]byte, error) {
+	err := structFields(rv, order, func(f reflect.Value, name string, tag fieldTag, last bool) error {
+		var err error
+		dst, err = appendValue(dst, f, tag, last)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", name, err)
+		}
+		return nil
+	})
+	return dst, err
+}
+
+func appendValue(dst []byte, v reflect.Value, tag fieldTag, last bool) ([]byte, error) {
+	order := tag.order
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return append(dst, 1), nil
+		}
+		return append(dst, 0), nil
+	case reflect.Int8:
+		return append(dst, byte(v.Int())), nil
+	case reflect.Uint8:
+		return append(dst, byte(v.Uint())), nil
+	case reflect.Int16:
+		return order.AppendUint16(dst, uint16(v.Int())), nil
+	case reflect.Uint16:
+		return order.AppendUint16(dst, uint16(v.Uint())), nil
+	case reflect.Int32:
+		return order.AppendUint32(dst, uint32(v.Int())), nil
+	case reflect.Uint32:
+		return order.AppendUint32(dst, uint32(v.Uint())), nil
+	case reflect.Int64:
+		return order.AppendUint64(dst, uint64(v.Int())), nil
+	case reflect.Uint64:
+		return order.AppendUint64(dst, v.Uint()), nil
+	case reflect.Float32:
+		return order.AppendUint32(dst, math.Float32bits(float32(v.Float()))), nil
+	case reflect.Float64:
+		return order.AppendUint64(dst, math.Float64bits(v.Float())), nil
+	case reflect.Array:
+		var err error
+		for i := 0; i < v.Len(); i++ {
+			if dst, err = appendValue(dst, v.Index(i), fieldTag{order: order}, false); err != nil {
+				return nil, err
+			}
+		}
+		return dst, nil
+	case reflect.Struct:
+		return appendStruct(dst, v, order)
+	case reflect.String, reflect.Slice:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 {
+			return nil, fmt.Errorf("%w: unsupported slice type %s", ErrStructLayout, v.Type())
+		}
+		var data []byte
+		if v.Kind() == reflect.String {
+			data = []byte(v.String())
+		} else {
+			data = v.Bytes()
+		}
+		if tag.size == 0 {
+			if !last {
+				return nil, fmt.Errorf("%w: variable-length %s must be the last field or have a size tag", ErrStructLayout, v.Kind())
+			}
+			return append(dst, data...), nil
+		}
+		if len(data) > tag.size {
+			return nil, fmt.Errorf("%w: %d bytes exceed size=%d", ErrStructLayout, len(data), tag.size)
+		}
+		dst = append(dst, data...)
+		return append(dst, make([]byte, tag.size-len(data))...), nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported type %s", ErrStructLayout, v.Type())
+	}
+}
+
+func decodeStruct(data []byte, rv reflect.Value, order byteOrder) ([]byte, error) {
+	err := structFields(rv, order, func(f reflect.Value, name string, tag fieldTag, last bool) error {
+		var err error
+		data, err = decodeValue(data, f, tag, last)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", name, err)
+		}
+		return nil
+	})
+	return data, err
+}
+
+func decodeValue(data []byte, v reflect.Value, tag fieldTag, last bool) ([]byte, error) {
+	order := tag.order
+
+	need := func(n int) error {
+		if len(data) < n {
+			return fmt.Errorf("%w: need %d bytes, have %d", ErrInvalidFrame, n, len(data))
+		}
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool, reflect.Int8, reflect.Uint8:
+		if err := need(1); err != nil {
+			return nil, err
+		}
+		switch v.Kind() {
+		case reflect.Bool:
+			v.SetBool(data[0] != 0)
+		case reflect.Int8:
+			v.SetInt(int64(int8(data[0])))
+		default:
+			v.SetUint(uint64(data[0]))
+		}
+		return data[1:], nil
+	case reflect.Int16, reflect.Uint16:
+		if err := need(2); err != nil {
+			return nil, err
+		}
+		u := order.Uint16(data)
+		if v.Kind() == reflect.Int16 {
+			v.SetInt(int64(int16(u)))
+		} else {
+			v.SetUint(uint64(u))
+		}
+		return data[2:], nil
+	case reflect.Int32, reflect.Uint32, reflect.Float32:
+		if err := need(4); err != nil {
+			return nil, err
+		}
+		u := order.Uint32(data)
+		switch v.Kind() {
+		case reflect.Int32:
+			v.SetInt(int64(int32(u)))
+		case reflect.Uint32:
+			v.SetUint(uint64(u))
+		default:
+			v.SetFloat(float64(math.Float32frombits(u)))
+		}
+		return data[4:], nil
+	case reflect.Int64, reflect.Uint64, reflect.Float64:
+		if err := need(8); err != nil {
+			return nil, err
+		}
+		u := order.Uint64(data)
+		switch v.Kind() {
+		case reflect.Int64:
+			v.SetInt(int64(u))
+		case reflect.Uint64:
+			v.SetUint(u)
+		default:
+			v.SetFloat(math.Float64frombits(u))
+		}
+		return data[8:], nil
+	case reflect.Array:
+		var err error
+		for i := 0; i < v.Len(); i++ {
+			if data, err = decodeValue(data, v.Index(i), fieldTag{order: order}, false); err != nil {
+				return nil, err
+			}
+		}
+		return data, nil
+	case reflect.Struct:
+		return decodeStruct(data, v, order)
+	case reflect.String, reflect.Slice:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 {
+			return nil, fmt.Errorf("%w: unsupported slice type %s", ErrStructLayout, v.Type())
+		}
+		n := tag.size
+		if n == 0 {
+			if !last {
+				return nil, fmt.Errorf("%w: variable-length %s must be the last field or have a size tag", ErrStructLayout, v.Kind())
+			}
+			n = len(data)
+		}
+		if err := need(n); err != nil {
+			return nil, err
+		}
+		field := data[:n]
+		if v.Kind() == reflect.String {
+			if tag.size > 0 {
+				// Fixed-size strings are zero padded
+				if i := strings.IndexByte(string(field), 0); i != -1 {
+					field = field[:i]
+				}
+			}
+			v.SetString(string(field))
+		} else {
+			v.SetBytes(append([]byte(nil), field...))
+		}
+		return data[n:], nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported type %s", ErrStructLayout, v.Type())
+	}
+}
@@ -0,0 +1,136 @@
+package framing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+type sensorHeader struct {
+	Version uint8
+	Flags   [2]byte
+}
+
+type sensorReading struct {
+	Header  sensorHeader
+	ID      uint16
+	Temp    int16   `serial:"le"`
+	Ratio   float32 `serial:"be"`
+	Name    string  `serial:"size=6"`
+	Active  bool
+	cached  int
+	Debug   bool `serial:"-"`
+	Samples []byte
+}
+
+func TestMarshalLayout(t *testing.T) {
+	r := sensorReading{
+		Header:  sensorHeader{Version: 2, Flags: [2]byte{0xA0, 0x0B}},
+		ID:      0x1234,
+		Temp:    -2,
+		Ratio:   1.5,
+		Name:    "probe",
+		Active:  true,
+		cached:  99,
+		Debug:   true,
+		Samples: []byte{0x01, 0x02},
+	}
+
+	got, err := Marshal(&r)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	want := []byte{
+		0x02, 0xA0, 0x0B, // Header
+		0x12, 0x34, // ID big-endian
+		0xFE, 0xFF, // Temp little-endian
+		0x3F, 0xC0, 0x00, 0x00, // Ratio
+		'p', 'r', 'o', 'b', 'e', 0x00, // Name padded
+		0x01,       // Active
+		0x01, 0x02, // Samples
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Marshal =\n% X\nwant\n% X", got, want)
+	}
+
+	var back sensorReading
+	if err := Unmarshal(got, &back); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	r.cached, r.Debug = 0, false
+	if back.Header != r.Header || back.ID != r.ID || back.Temp != r.Temp || back.Ratio != r.Ratio ||
+		back.Name != r.Name || back.Active != r.Active || !bytes.Equal(back.Samples, r.Samples) {
+		t.Errorf("Unmarshal = %+v, want %+v", back, r)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	type fixed struct {
+		A uint32
+		B uint8
+	}
+
+	var v fixed
+	if err := Unmarshal([]byte{0x00, 0x01}, &v); !errors.Is(err, ErrInvalidFrame) {
+		t.Errorf("short data error = %v, want ErrInvalidFrame", err)
+	}
+	if err := Unmarshal([]byte{0, 0, 0, 1, 2, 3}, &v); !errors.Is(err, ErrInvalidFrame) {
+		t.Errorf("trailing data error = %v, want ErrInvalidFrame", err)
+	}
+	if err := Unmarshal([]byte{0, 0, 0, 1, 2}, v); !errors.Is(err, ErrStructLayout) {
+		t.Errorf("non-pointer error = %v, want ErrStructLayout", err)
+	}
+}
+
+func TestMarshalLayoutErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		v    any
+	}{
+		{"not a struct", 42},
+		{"variable field not last", struct {
+			Data []byte
+			N    uint8
+		}{}},
+		{"unsupported type", struct{ M map[string]int }{}},
+		{"int without size", struct{ N int }{}},
+		{"bad tag", struct {
+			N uint16 `serial:"middle"`
+		}{}},
+		{"string too long", struct {
+			S string `serial:"size=2"`
+		}{S: "abc"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Marshal(tt.v); !errors.Is(err, ErrStructLayout) {
+				t.Errorf("Marshal error = %v, want ErrStructLayout", err)
+			}
+		})
+	}
+}
+
+func TestStructOverPacketPort(t *testing.T) {
+	type ping struct {
+		Seq   uint16 `serial:"le"`
+		Value int32
+	}
+
+	lb := newLoopback()
+	pp := NewPacketPort(lb, WithChecksum(NewHDLC().WithFCS(nil), CRC16Modbus))
+	go WriteStruct(pp, ping{Seq: 7, Value: -100})
+
+	var got ping
+	ts, err := ReadStruct(context.Background(), pp, &got)
+	if err != nil {
+		t.Fatalf("ReadStruct failed: %v", err)
+	}
+	if got.Seq != 7 || got.Value != -100 {
+		t.Errorf("got %+v, want {Seq:7 Value:-100}", got)
+	}
+	if ts.IsZero() {
+		t.Error("timestamp should be set")
+	}
+}
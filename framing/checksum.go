@@ -0,0 +1,259 @@
+package framing
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// Checksum computes an integrity check value appended to frame payloads
+type Checksum interface {
+	// Name returns a short identifier (e.g., "crc16-modbus")
+	Name() string
+	// Size returns the number of checksum bytes on the wire
+	Size() int
+	// Append appends the checksum of data to dst in wire byte order
+	Append(dst, data []byte) []byte
+}
+
+// ChecksumError reports a frame whose checksum did not verify
+// errors.Is(err, ErrChecksumMismatch) reports true for ChecksumError values.
+type ChecksumError struct {
+	Checksum string // Checksum algorithm name
+	Frame    []byte // Offending frame payload including the received checksum
+	Expected []byte // Checksum computed over the payload
+	Actual   []byte // Checksum received in the frame
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("%s checksum mismatch: expected % X, got % X (frame % X)", e.Checksum, e.Expected, e.Actual, e.Frame)
+}
+
+// Unwrap allows errors.Is(err, ErrChecksumMismatch)
+func (e *ChecksumError) Unwrap() error {
+	return ErrChecksumMismatch
+}
+
+// checksum adapts a function to the Checksum interface
+type checksum struct {
+	name   string
+	size   int
+	append func(dst, data []byte) []byte
+}
+
+func (c checksum) Name() string                   { return c.name }
+func (c checksum) Size() int                      { return c.size }
+func (c checksum) Append(dst, data []byte) []byte { return c.append(dst, data) }
+
+// Predefined checksum strategies
+var (
+	// CRC8 is CRC-8/SMBUS (poly 0x07, init 0x00), one byte
+	CRC8 Checksum = checksum{"crc8", 1, func(dst, data []byte) []byte {
+		return append(dst, CRC8Sum(data))
+	}}
+
+	// CRC16CCITT is CRC-16/CCITT-FALSE (poly 0x1021, init 0xFFFF), big-endian
+	CRC16CCITT Checksum = checksum{"crc16-ccitt", 2, func(dst, data []byte) []byte {
+		return binary.BigEndian.AppendUint16(dst, CRC16CCITTSum(data))
+	}}
+
+	// CRC16XModem is CRC-16/XMODEM (poly 0x1021, init 0x0000), big-endian
+	CRC16XModem Checksum = checksum{"crc16-xmodem", 2, func(dst, data []byte) []byte {
+		return binary.BigEndian.AppendUint16(dst, CRC16XModemSum(data))
+	}}
+
+	// CRC16Modbus is CRC-16/MODBUS (poly 0xA001 reflected, init 0xFFFF), little-endian as on the Modbus RTU wire
+	CRC16Modbus Checksum = checksum{"crc16-modbus", 2, func(dst, data []byte) []byte {
+		return binary.LittleEndian.AppendUint16(dst, CRC16ModbusSum(data))
+	}}
+
+	// CRC16X25 is CRC-16/X-25 (poly 0x8408 reflected, init 0xFFFF, inverted), little-endian
+	// This is the 16-bit frame check sequence used by HDLC and PPP.
+	CRC16X25 Checksum = checksum{"crc16-x25", 2, func(dst, data []byte) []byte {
+		return binary.LittleEndian.AppendUint16(dst, CRC16X25Sum(data))
+	}}
+
+	// CRC32 is CRC-32/IEEE (as used by Ethernet and zlib), little-endian
+	CRC32 Checksum = checksum{"crc32", 4, func(dst, data []byte) []byte {
+		return binary.LittleEndian.AppendUint32(dst, crc32.ChecksumIEEE(data))
+	}}
+
+	// XOR8 is the XOR of all payload bytes, one byte (NMEA-style longitudinal parity)
+	XOR8 Checksum = checksum{"xor8", 1, func(dst, data []byte) []byte {
+		return append(dst, XORSum(data))
+	}}
+
+	// Fletcher16 is the Fletcher-16 checksum (modulo 255), transmitted as sum1 then sum2
+	Fletcher16 Checksum = checksum{"fletcher16", 2, func(dst, data []byte) []byte {
+		sum := Fletcher16Sum(data)
+		return append(dst, byte(sum), byte(sum>>8))
+	}}
+)
+
+// CRC8Sum computes CRC-8/SMBUS over data
+func CRC8Sum(data []byte) uint8 {
+	var crc uint8
+	for _, b := range data {
+		crc ^= b
+		for i := 0; i < 8; i++ {
+			if crc&0x80 != 0 {
+				crc = crc<<1 ^ 0x07
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// CRC16CCITTSum computes CRC-16/CCITT-FALSE over data
+func CRC16CCITTSum(data []byte) uint16 {
+	return crc16CCITT(0xFFFF, data)
+}
+
+// CRC16XModemSum computes CRC-16/XMODEM over data
+func CRC16XModemSum(data []byte) uint16 {
+	return crc16CCITT(0, data)
+}
+
+// crc16CCITT computes the non-reflected CCITT polynomial with the given initial value
+func crc16CCITT(crc uint16, data []byte) uint16 {
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// CRC16ModbusSum computes CRC-16/MODBUS over data
+func CRC16ModbusSum(data []byte) uint16 {
+	crc := uint16(0xFFFF)
+	for _, b := range data {
+		crc ^= uint16(b)
+		for i := 0; i < 8; i++ {
+			if crc&1 != 0 {
+				crc = crc>>1 ^ 0xA001
+			} else {
+				crc >>= 1
+			}
+		}
+	}
+	return crc
+}
+
+// CRC16X25Sum computes CRC-16/X-25 over data
+func CRC16X25Sum(data []byte) uint16 {
+	crc := uint16(0xFFFF)
+	for _, b := range data {
+		crc ^= uint16(b)
+		for i := 0; i < 8; i++ {
+			if crc&1 != 0 {
+				crc = crc>>1 ^ 0x8408
+			} else {
+				crc >>= 1
+			}
+		}
+	}
+	return ^crc
+}
+
+// XORSum computes the XOR of all bytes in data
+func XORSum(data []byte) uint8 {
+	var sum uint8
+	for _, b := range data {
+		sum ^= b
+	}
+	return sum
+}
+
+// Fletcher16Sum computes Fletcher-16 over data, returning sum2<<8 | sum1
+func Fletcher16Sum(data []byte) uint16 {
+	var sum1, sum2 uint16
+	for _, b := range data {
+		sum1 = (sum1 + uint16(b)) % 255
+		sum2 = (sum2 + sum1) % 255
+	}
+	return sum2<<8 | sum1
+}
+
+// VerifyChecksum splits a frame into payload and trailing checksum and verifies it
+// Returns a *ChecksumError if the checksum does not match.
+func VerifyChecksum(cs Checksum, frame []byte) ([]byte, error) {
+	size := cs.Size()
+	if len(frame) < size {
+		return nil, fmt.Errorf("%w: %d bytes is shorter than %s checksum", ErrInvalidFrame, len(frame), cs.Name())
+	}
+
+	payload := frame[:len(frame)-size]
+	actual := frame[len(frame)-size:]
+	expected := cs.Append(nil, payload)
+	if !bytes.Equal(expected, actual) {
+		return nil, &ChecksumError{
+			Checksum: cs.Name(),
+			Frame:    frame,
+			Expected: expected,
+			Actual:   append([]byte(nil), actual...),
+		}
+	}
+	return payload, nil
+}
+
+// checksummed wraps a codec so that encoders append and decoders verify a checksum
+type checksummed struct {
+	codec    Codec
+	checksum Checksum
+}
+
+// WithChecksum returns a codec that appends cs to each payload before framing it
+// with codec, and verifies and strips it after decoding.
+func WithChecksum(codec Codec, cs Checksum) Codec {
+	return &checksummed{codec: codec, checksum: cs}
+}
+
+func (c *checksummed) Encode(payload []byte) ([]byte, error) {
+	withSum := make([]byte, 0, len(payload)+c.checksum.Size())
+	withSum = append(withSum, payload...)
+	withSum = c.checksum.Append(withSum, payload)
+	return c.codec.Encode(withSum)
+}
+
+// EncodeVector returns the frame of the wrapped codec with the checksum as
+// a separate buffer; if the wrapped codec cannot frame separate buffers,
+// the frame is a single buffer from Encode
+func (c *checksummed) EncodeVector(payload []byte) ([][]byte, error) {
+	pe, ok := c.codec.(partsEncoder)
+	if !ok {
+		frame, err := c.Encode(payload)
+		if err != nil {
+			return nil, err
+		}
+		return [][]byte{frame}, nil
+	}
+	return pe.encodeParts([][]byte{payload, c.checksum.Append(nil, payload)})
+}
+
+func (c *checksummed) NewDecoder(r io.Reader) Decoder {
+	return &checksummedDecoder{inner: c.codec.NewDecoder(r), checksum: c.checksum}
+}
+
+type checksummedDecoder struct {
+	inner    Decoder
+	checksum Checksum
+}
+
+func (d *checksummedDecoder) Decode(ctx context.Context) ([]byte, error) {
+	frame, err := d.inner.Decode(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return VerifyChecksum(d.checksum, frame)
+}
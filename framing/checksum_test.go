@@ -0,0 +1,146 @@
+package framing
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+var checkInput = []byte("123456789")
+
+func TestChecksumCheckValues(t *testing.T) {
+	tests := []struct {
+		name string
+		got  uint32
+		want uint32
+	}{
+		{"CRC8", uint32(CRC8Sum(checkInput)), 0xF4},
+		{"CRC16CCITT", uint32(CRC16CCITTSum(checkInput)), 0x29B1},
+		{"CRC16XModem", uint32(CRC16XModemSum(checkInput)), 0x31C3},
+		{"CRC16Modbus", uint32(CRC16ModbusSum(checkInput)), 0x4B37},
+		{"CRC16X25", uint32(CRC16X25Sum(checkInput)), 0x906E},
+		{"XOR", uint32(XORSum(checkInput)), 0x31},
+		{"Fletcher16 abcde", uint32(Fletcher16Sum([]byte("abcde"))), 0xC8F0},
+		{"Fletcher16 abcdef", uint32(Fletcher16Sum([]byte("abcdef"))), 0x2057},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %#x, want %#x", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChecksumWireFormat(t *testing.T) {
+	tests := []struct {
+		cs   Checksum
+		want []byte
+	}{
+		{CRC8, []byte{0xF4}},
+		{CRC16CCITT, []byte{0x29, 0xB1}},
+		{CRC16XModem, []byte{0x31, 0xC3}},
+		{CRC16Modbus, []byte{0x37, 0x4B}},
+		{CRC16X25, []byte{0x6E, 0x90}},
+		{CRC32, []byte{0x26, 0x39, 0xF4, 0xCB}},
+		{XOR8, []byte{0x31}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cs.Name(), func(t *testing.T) {
+			got := tt.cs.Append(nil, checkInput)
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("Append = % X, want % X", got, tt.want)
+			}
+			if len(got) != tt.cs.Size() {
+				t.Errorf("Size() = %d, appended %d bytes", tt.cs.Size(), len(got))
+			}
+		})
+	}
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	frame := CRC16Modbus.Append([]byte{0x01, 0x03, 0x00, 0x00, 0x00, 0x0A}, []byte{0x01, 0x03, 0x00, 0x00, 0x00, 0x0A})
+	// Well-known Modbus request: 01 03 00 00 00 0A C5 CD
+	if !bytes.Equal(frame[6:], []byte{0xC5, 0xCD}) {
+		t.Fatalf("Modbus CRC = % X, want C5 CD", frame[6:])
+	}
+
+	payload, err := VerifyChecksum(CRC16Modbus, frame)
+	if err != nil {
+		t.Fatalf("VerifyChecksum failed: %v", err)
+	}
+	if len(payload) != 6 {
+		t.Errorf("payload length = %d, want 6", len(payload))
+	}
+
+	frame[6] ^= 0xFF
+	_, err = VerifyChecksum(CRC16Modbus, frame)
+	if !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("error = %v, want ErrChecksumMismatch", err)
+	}
+	var csErr *ChecksumError
+	if !errors.As(err, &csErr) {
+		t.Fatalf("error %T is not *ChecksumError", err)
+	}
+	if !bytes.Equal(csErr.Frame, frame) {
+		t.Errorf("Frame = % X, want % X", csErr.Frame, frame)
+	}
+
+	if _, err := VerifyChecksum(CRC32, []byte{0x01}); !errors.Is(err, ErrInvalidFrame) {
+		t.Errorf("short frame error = %v, want ErrInvalidFrame", err)
+	}
+}
+
+func TestWithChecksumRoundTrip(t *testing.T) {
+	codec := WithChecksum(NewDelimited([]byte{'\n'}), XOR8)
+
+	good, err := codec.Encode([]byte("HELLO"))
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if !bytes.Equal(good, []byte("HELLO\x42\n")) {
+		t.Fatalf("Encode = %q", good)
+	}
+
+	stream := append([]byte("HELLO\x00\n"), good...)
+	dec := codec.NewDecoder(bytes.NewReader(stream))
+
+	_, err = dec.Decode(context.Background())
+	var csErr *ChecksumError
+	if !errors.As(err, &csErr) {
+		t.Fatalf("first Decode error = %v, want *ChecksumError", err)
+	}
+
+	payload, err := dec.Decode(context.Background())
+	if err != nil {
+		t.Fatalf("second Decode failed: %v", err)
+	}
+	if string(payload) != "HELLO" {
+		t.Errorf("payload = %q, want HELLO", payload)
+	}
+}
+
+func TestWithChecksumEncodeVector(t *testing.T) {
+	codecs := []Codec{
+		WithChecksum(NewDelimited([]byte{'\n'}), CRC16Modbus),
+		WithChecksum(NewLengthPrefixed(2, binary.BigEndian), CRC32),
+		WithChecksum(NewHDLC(), CRC16CCITT),
+	}
+	for _, codec := range codecs {
+		frame, err := codec.Encode([]byte("HELLO"))
+		if err != nil {
+			t.Fatalf("Encode failed: %v", err)
+		}
+		bufs, err := codec.(VectorCodec).EncodeVector([]byte("HELLO"))
+		if err != nil {
+			t.Fatalf("EncodeVector failed: %v", err)
+		}
+		if got := bytes.Join(bufs, nil); !bytes.Equal(got, frame) {
+			t.Errorf("EncodeVector = % X, want % X as from Encode", got, frame)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package framing
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+)
+
+// Delimited frames messages by terminating each one with a fixed byte sequence
+// Suitable for line-based ASCII protocols ("\r\n") or ETX-terminated frames (0x03).
+// Binary payloads that may contain the delimiter need an escaping codec such as HDLC.
+type Delimited struct {
+	delimiter    []byte
+	maxFrameSize int
+}
+
+// NewDelimited creates a codec that terminates frames with delimiter
+func NewDelimited(delimiter []byte) *Delimited {
+	d := make([]byte, len(delimiter))
+	copy(d, delimiter)
+	return &Delimited{
+		delimiter:    d,
+		maxFrameSize: DefaultMaxFrameSize,
+	}
+}
+
+// WithMaxFrameSize sets the largest payload the decoder will buffer
+func (d *Delimited) WithMaxFrameSize(size int) *Delimited {
+	d.maxFrameSize = size
+	return d
+}
+
+// Encode appends the delimiter to payload
+func (d *Delimited) Encode(payload []byte) ([]byte, error) {
+	if len(d.delimiter) == 0 {
+		return nil, fmt.Errorf("%w: empty delimiter", ErrInvalidFrame)
+	}
+	if bytes.Contains(payload, d.delimiter) {
+		return nil, fmt.Errorf("%w: payload contains delimiter", ErrInvalidFrame)
+	}
+	frame := make([]byte, 0, len(payload)+len(d.delimiter))
+	frame = append(frame, payload...)
+	return append(frame, d.delimiter...), nil
+}
+
+// EncodeVector returns payload and the delimiter as separate buffers
+func (d *Delimited) EncodeVector(payload []byte) ([][]byte, error) {
+	return d.encodeParts([][]byte{payload})
+}
+
+func (d *Delimited) encodeParts(parts [][]byte) ([][]byte, error) {
+	if len(d.delimiter) == 0 {
+		return nil, fmt.Errorf("%w: empty delimiter", ErrInvalidFrame)
+	}
+	if partsContain(parts, d.delimiter) {
+		return nil, fmt.Errorf("%w: payload contains delimiter", ErrInvalidFrame)
+	}
+	return append(parts[:len(parts):len(parts)], d.delimiter), nil
+}
+
+// partsContain reports whether the concatenation of parts contains sep,
+// including occurrences spanning parts
+func partsContain(parts [][]byte, sep []byte) bool {
+	k := len(sep) - 1 // Bytes of one part that can start a spanning sep
+	var tail []byte
+	for _, part := range parts {
+		if bytes.Contains(part, sep) {
+			return true
+		}
+		if k == 0 {
+			continue
+		}
+		edge := append(append([]byte(nil), tail...), part[:min(len(part), k)]...)
+		if bytes.Contains(edge, sep) {
+			return true
+		}
+		if len(part) >= k {
+			tail = part[len(part)-k:]
+		} else {
+			tail = edge[max(0, len(edge)-k):]
+		}
+	}
+	return false
+}
+
+// NewDecoder returns a decoder that splits r on the delimiter
+func (d *Delimited) NewDecoder(r io.Reader) Decoder {
+	return &delimitedDecoder{codec: d, stream: newStreamBuffer(r)}
+}
+
+type delimitedDecoder struct {
+	codec  *Delimited
+	stream *streamBuffer
+	skip   bool // Discarding an oversized frame until the next delimiter
+}
+
+func (d *delimitedDecoder) Decode(ctx context.Context) ([]byte, error) {
+	delim := d.codec.delimiter
+	if len(delim) == 0 {
+		return nil, fmt.Errorf("%w: empty delimiter", ErrInvalidFrame)
+	}
+
+	for {
+		if idx := bytes.Index(d.stream.buf, delim); idx != -1 {
+			if d.skip {
+				d.skip = false
+				d.stream.consume(idx + len(delim))
+				continue
+			}
+			payload := d.stream.take(idx)
+			d.stream.consume(len(delim))
+			return payload, nil
+		}
+
+		if len(d.stream.buf) > d.codec.maxFrameSize+len(delim) {
+			// Keep a possible partial delimiter at the tail
+			d.stream.consume(len(d.stream.buf) - (len(delim) - 1))
+			if !d.skip {
+				d.skip = true
+				return nil, ErrFrameTooLarge
+			}
+		}
+
+		if err := d.stream.fill(ctx); err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package framing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+// slowReader returns one byte per read with empty reads in between,
+// mimicking a serial port with VTIME timeouts
+type slowReader struct {
+	data  []byte
+	empty bool
+}
+
+func (r *slowReader) Read(p []byte) (int, error) {
+	r.empty = !r.empty
+	if r.empty {
+		return 0, nil
+	}
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+	p[0] = r.data[0]
+	r.data = r.data[1:]
+	return 1, nil
+}
+
+func TestDelimitedEncode(t *testing.T) {
+	codec := NewDelimited([]byte{0x03})
+
+	frame, err := codec.Encode([]byte{0x02, 'A', 'B'})
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if !bytes.Equal(frame, []byte{0x02, 'A', 'B', 0x03}) {
+		t.Errorf("Encode = % X", frame)
+	}
+
+	if _, err := codec.Encode([]byte{0x03}); !errors.Is(err, ErrInvalidFrame) {
+		t.Errorf("payload with delimiter error = %v, want ErrInvalidFrame", err)
+	}
+}
+
+func TestDelimitedDecode(t *testing.T) {
+	codec := NewDelimited([]byte("\r\n"))
+	dec := codec.NewDecoder(&slowReader{data: []byte("first\r\n\r\nthird\r\npartial")})
+
+	want := []string{"first", "", "third"}
+	for _, w := range want {
+		payload, err := dec.Decode(context.Background())
+		if err != nil {
+			t.Fatalf("Decode failed: %v", err)
+		}
+		if string(payload) != w {
+			t.Errorf("payload = %q, want %q", payload, w)
+		}
+	}
+
+	if _, err := dec.Decode(context.Background()); err != io.EOF {
+		t.Errorf("Decode at end = %v, want io.EOF", err)
+	}
+}
+
+func TestDelimitedFrameTooLarge(t *testing.T) {
+	codec := NewDelimited([]byte{'\n'}).WithMaxFrameSize(8)
+	stream := append(bytes.Repeat([]byte{'x'}, 600), []byte("\nok\n")...)
+	dec := codec.NewDecoder(bytes.NewReader(stream))
+
+	if _, err := dec.Decode(context.Background()); !errors.Is(err, ErrFrameTooLarge) {
+		t.Fatalf("Decode error = %v, want ErrFrameTooLarge", err)
+	}
+
+	payload, err := dec.Decode(context.Background())
+	if err != nil {
+		t.Fatalf("Decode after oversize failed: %v", err)
+	}
+	if string(payload) != "ok" {
+		t.Errorf("payload = %q, want ok", payload)
+	}
+}
+
+func TestDelimitedDecodeCancel(t *testing.T) {
+	codec := NewDelimited([]byte{'\n'})
+	dec := codec.NewDecoder(&slowReader{data: []byte("no delimiter")})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := dec.Decode(ctx); err != context.Canceled {
+		t.Errorf("Decode error = %v, want context.Canceled", err)
+	}
+}
+
+func TestDelimitedEncodeVector(t *testing.T) {
+	codec := NewDelimited([]byte("\r\n"))
+
+	bufs, err := codec.EncodeVector([]byte("AB"))
+	if err != nil {
+		t.Fatalf("EncodeVector failed: %v", err)
+	}
+	if got := bytes.Join(bufs, nil); string(got) != "AB\r\n" {
+		t.Errorf("EncodeVector = %q, want AB\\r\\n", got)
+	}
+
+	// A delimiter split across parts is still found
+	for _, parts := range [][][]byte{
+		{[]byte("A\r"), []byte("\nB")},
+		{[]byte("A\r"), nil, []byte("\n")},
+		{[]byte("\r\n")},
+	} {
+		if _, err := codec.encodeParts(parts); !errors.Is(err, ErrInvalidFrame) {
+			t.Errorf("encodeParts(%q) error = %v, want ErrInvalidFrame", parts, err)
+		}
+	}
+	parts := [][]byte{[]byte("A\r"), []byte("B\n")}
+	if _, err := codec.encodeParts(parts); err != nil {
+		t.Errorf("encodeParts(%q) failed: %v", parts, err)
+	}
+}
@@ -0,0 +1,124 @@
+// Package framing splits serial byte streams into messages and wraps outgoing
+// messages into frames.
+//
+// A Codec describes one framing scheme. Encode produces a complete frame from a
+// payload; NewDecoder returns a Decoder that extracts payloads from a stream
+// such as a serial.Port:
+//
+//	codec := framing.WithChecksum(framing.NewDelimited([]byte("\r\n")), framing.XOR8)
+//
+//	frame, _ := codec.Encode([]byte("PING"))
+//	port.Write(frame)
+//
+//	dec := codec.NewDecoder(port)
+//	for {
+//	    payload, err := dec.Decode(ctx)
+//	    var csErr *framing.ChecksumError
+//	    if errors.As(err, &csErr) {
+//	        log.Printf("dropping corrupt frame % X", csErr.Frame)
+//	        continue
+//	    }
+//	    ...
+//	}
+//
+// Decoders tolerate zero-length reads, so a port opened with a read timeout
+// (VTIME) lets Decode observe context cancellation between reads.
+//
+// Codecs that do not escape the payload also implement VectorCodec, which
+// returns the frame as buffers for serial.Port's Writev, saving the copy of
+// the payload into the frame:
+//
+//	bufs, _ := codec.(framing.VectorCodec).EncodeVector(payload)
+//	port.Writev(bufs)
+package framing
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// Predefined errors for frame decoding
+var (
+	ErrFrameTooLarge    = errors.New("frame exceeds maximum size")
+	ErrChecksumMismatch = errors.New("frame checksum mismatch")
+	ErrInvalidFrame     = errors.New("invalid frame")
+	ErrStructLayout     = errors.New("invalid struct layout")
+)
+
+// DefaultMaxFrameSize bounds decoder buffers unless a codec specifies otherwise
+const DefaultMaxFrameSize = 4096
+
+// Codec converts between message payloads and on-the-wire frames
+type Codec interface {
+	// Encode wraps a payload into a complete frame ready to be written
+	Encode(payload []byte) ([]byte, error)
+	// NewDecoder returns a Decoder that extracts payloads from r
+	NewDecoder(r io.Reader) Decoder
+}
+
+// VectorCodec is implemented by codecs that frame a payload without
+// transforming it, so a frame can be written as separate buffers with
+// serial.Port's Writev instead of being copied into one
+// PacketPort uses it when its port can write vectors.
+type VectorCodec interface {
+	Codec
+	// EncodeVector frames payload like Encode, returning the frame as
+	// buffers to be written in order; where the framing allows, payload is
+	// one of them rather than a copy
+	EncodeVector(payload []byte) ([][]byte, error)
+}
+
+// partsEncoder frames the concatenation of parts without joining them, for
+// codecs wrapped by WithChecksum
+type partsEncoder interface {
+	encodeParts(parts [][]byte) ([][]byte, error)
+}
+
+// Decoder extracts successive payloads from a byte stream
+type Decoder interface {
+	// Decode blocks until the next complete frame is available and returns its payload
+	// Per-frame errors (e.g., *ChecksumError) leave the decoder usable; call Decode again.
+	Decode(ctx context.Context) ([]byte, error)
+}
+
+// streamBuffer accumulates bytes from a reader for decoders
+type streamBuffer struct {
+	r     io.Reader
+	buf   []byte
+	chunk []byte
+}
+
+func newStreamBuffer(r io.Reader) *streamBuffer {
+	return &streamBuffer{
+		r:     r,
+		buf:   make([]byte, 0, 512),
+		chunk: make([]byte, 512),
+	}
+}
+
+// fill performs one read and appends the result to the buffer
+// Zero-length reads return nil so callers can re-check their framing state.
+func (s *streamBuffer) fill(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	n, err := s.r.Read(s.chunk)
+	if n > 0 {
+		s.buf = append(s.buf, s.chunk[:n]...)
+	}
+	return err
+}
+
+// consume removes the first n bytes from the buffer
+func (s *streamBuffer) consume(n int) {
+	s.buf = s.buf[:copy(s.buf, s.buf[n:])]
+}
+
+// take returns a copy of the first n bytes and removes them from the buffer
+func (s *streamBuffer) take(n int) []byte {
+	out := make([]byte, n)
+	copy(out, s.buf[:n])
+	s.consume(n)
+	return out
+}
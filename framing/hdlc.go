@@ -0,0 +1,159 @@
+package framing
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+)
+
+// HDLC framing bytes
+const (
+	HDLCFlag   = 0x7E // Opens and closes every frame
+	HDLCEscape = 0x7D // Precedes an escaped byte
+	hdlcXOR    = 0x20 // Escaped bytes are transmitted XORed with this value
+)
+
+// HDLC frames messages the way HDLC asynchronous framing and PPP do (RFC 1662):
+// each frame is enclosed in 0x7E flags, 0x7E and 0x7D inside the frame are sent
+// as 0x7D followed by the byte XOR 0x20, and a frame check sequence is appended
+// before escaping.
+//
+// The default FCS is CRC16X25 (the 16-bit PPP FCS). Decoders discard bytes
+// before the first flag, skip empty frames between back-to-back flags, and
+// report ErrInvalidFrame for frames aborted with 0x7D 0x7E.
+type HDLC struct {
+	fcs           Checksum
+	escapeControl bool
+	maxFrameSize  int
+}
+
+// NewHDLC creates an HDLC codec with a CRC16X25 frame check sequence
+func NewHDLC() *HDLC {
+	return &HDLC{
+		fcs:          CRC16X25,
+		maxFrameSize: DefaultMaxFrameSize,
+	}
+}
+
+// WithFCS sets the frame check sequence; nil disables it
+func (h *HDLC) WithFCS(cs Checksum) *HDLC {
+	h.fcs = cs
+	return h
+}
+
+// WithControlEscaping also escapes control characters 0x00-0x1F when encoding
+// This matches PPP's default async control character map and keeps XON/XOFF
+// out of the byte stream. Decoders always accept escaped bytes.
+func (h *HDLC) WithControlEscaping() *HDLC {
+	h.escapeControl = true
+	return h
+}
+
+// WithMaxFrameSize sets the largest payload (excluding FCS) the codec accepts
+func (h *HDLC) WithMaxFrameSize(size int) *HDLC {
+	h.maxFrameSize = size
+	return h
+}
+
+func (h *HDLC) fcsSize() int {
+	if h.fcs == nil {
+		return 0
+	}
+	return h.fcs.Size()
+}
+
+func (h *HDLC) needsEscape(b byte) bool {
+	return b == HDLCFlag || b == HDLCEscape || (h.escapeControl && b < 0x20)
+}
+
+// Encode appends the FCS to payload, escapes it and wraps it in flags
+func (h *HDLC) Encode(payload []byte) ([]byte, error) {
+	if len(payload) > h.maxFrameSize {
+		return nil, ErrFrameTooLarge
+	}
+
+	body := payload
+	if h.fcs != nil {
+		body = h.fcs.Append(append(make([]byte, 0, len(payload)+h.fcs.Size()), payload...), payload)
+	}
+
+	frame := make([]byte, 0, len(body)+len(body)/8+2)
+	frame = append(frame, HDLCFlag)
+	for _, b := range body {
+		if h.needsEscape(b) {
+			frame = append(frame, HDLCEscape, b^hdlcXOR)
+		} else {
+			frame = append(frame, b)
+		}
+	}
+	return append(frame, HDLCFlag), nil
+}
+
+// NewDecoder returns a decoder that extracts flag-delimited frames from r
+func (h *HDLC) NewDecoder(r io.Reader) Decoder {
+	return &hdlcDecoder{codec: h, stream: newStreamBuffer(r)}
+}
+
+type hdlcDecoder struct {
+	codec  *HDLC
+	stream *streamBuffer
+	synced bool // An opening flag has been seen
+}
+
+func (d *hdlcDecoder) Decode(ctx context.Context) ([]byte, error) {
+	// Every payload byte may be escaped, doubling its size on the wire
+	maxRaw := 2 * (d.codec.maxFrameSize + d.codec.fcsSize())
+
+	for {
+		if !d.synced {
+			if idx := bytes.IndexByte(d.stream.buf, HDLCFlag); idx != -1 {
+				d.stream.consume(idx + 1)
+				d.synced = true
+				continue
+			}
+			d.stream.consume(len(d.stream.buf))
+		} else if idx := bytes.IndexByte(d.stream.buf, HDLCFlag); idx != -1 {
+			raw := d.stream.take(idx)
+			// The closing flag also opens the next frame, so stay synced
+			d.stream.consume(1)
+			if len(raw) == 0 {
+				continue
+			}
+			return d.codec.unframe(raw)
+		} else if len(d.stream.buf) > maxRaw {
+			d.stream.consume(len(d.stream.buf))
+			d.synced = false
+			return nil, ErrFrameTooLarge
+		}
+
+		if err := d.stream.fill(ctx); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// unframe removes escaping from the bytes between two flags and verifies the FCS
+func (h *HDLC) unframe(raw []byte) ([]byte, error) {
+	body := raw[:0]
+	for i := 0; i < len(raw); i++ {
+		b := raw[i]
+		if b == HDLCEscape {
+			i++
+			if i == len(raw) {
+				// 0x7D immediately before a flag aborts the frame
+				return nil, fmt.Errorf("%w: aborted frame", ErrInvalidFrame)
+			}
+			b = raw[i] ^ hdlcXOR
+		}
+		body = append(body, b)
+	}
+
+	if len(body) > h.maxFrameSize+h.fcsSize() {
+		return nil, ErrFrameTooLarge
+	}
+	if h.fcs == nil {
+		return body, nil
+	}
+	return VerifyChecksum(h.fcs, body)
+}
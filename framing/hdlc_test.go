@@ -0,0 +1,134 @@
+package framing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestHDLCEncode(t *testing.T) {
+	tests := []struct {
+		name    string
+		codec   *HDLC
+		payload []byte
+		want    []byte
+	}{
+		{
+			name:    "plain with FCS",
+			codec:   NewHDLC(),
+			payload: checkInput,
+			want:    append(append([]byte{0x7E}, checkInput...), 0x6E, 0x90, 0x7E),
+		},
+		{
+			name:    "flag and escape bytes",
+			codec:   NewHDLC().WithFCS(nil),
+			payload: []byte{0x01, 0x7E, 0x02, 0x7D, 0x03},
+			want:    []byte{0x7E, 0x01, 0x7D, 0x5E, 0x02, 0x7D, 0x5D, 0x03, 0x7E},
+		},
+		{
+			name:    "control escaping",
+			codec:   NewHDLC().WithFCS(nil).WithControlEscaping(),
+			payload: []byte{0x11, 0x41, 0x13},
+			want:    []byte{0x7E, 0x7D, 0x31, 0x41, 0x7D, 0x33, 0x7E},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.codec.Encode(tt.payload)
+			if err != nil {
+				t.Fatalf("Encode failed: %v", err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("Encode = % X, want % X", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHDLCRoundTrip(t *testing.T) {
+	codec := NewHDLC().WithControlEscaping()
+	payloads := [][]byte{
+		{0x7E, 0x7D, 0x7E, 0x7D},
+		{0x00, 0x11, 0x13, 0x20, 0xFF},
+		bytes.Repeat([]byte{0x7E}, 64),
+	}
+
+	var stream []byte
+	for _, p := range payloads {
+		frame, err := codec.Encode(p)
+		if err != nil {
+			t.Fatalf("Encode failed: %v", err)
+		}
+		stream = append(stream, frame...)
+	}
+
+	dec := codec.NewDecoder(&slowReader{data: stream})
+	for _, want := range payloads {
+		got, err := dec.Decode(context.Background())
+		if err != nil {
+			t.Fatalf("Decode failed: %v", err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("Decode = % X, want % X", got, want)
+		}
+	}
+	if _, err := dec.Decode(context.Background()); err != io.EOF {
+		t.Errorf("Decode at end = %v, want io.EOF", err)
+	}
+}
+
+func TestHDLCDecodeResync(t *testing.T) {
+	codec := NewHDLC()
+	good, _ := codec.Encode([]byte("ok"))
+	bad, _ := codec.Encode([]byte("corrupt"))
+	bad[2] ^= 0x01
+
+	var stream []byte
+	stream = append(stream, "line noise"...)  // Before the first flag
+	stream = append(stream, 0x7E, 0x7E, 0x7E) // Idle flags
+	stream = append(stream, 'x', 0x7D, 0x7E)  // Aborted frame
+	stream = append(stream, bad...)           // FCS error
+	stream = append(stream, good...)          // Valid frame
+	dec := codec.NewDecoder(bytes.NewReader(stream))
+
+	if _, err := dec.Decode(context.Background()); !errors.Is(err, ErrInvalidFrame) {
+		t.Errorf("aborted frame error = %v, want ErrInvalidFrame", err)
+	}
+	var csErr *ChecksumError
+	if _, err := dec.Decode(context.Background()); !errors.As(err, &csErr) {
+		t.Errorf("corrupt frame error = %v, want *ChecksumError", err)
+	}
+	payload, err := dec.Decode(context.Background())
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if string(payload) != "ok" {
+		t.Errorf("payload = %q, want ok", payload)
+	}
+}
+
+func TestHDLCFrameTooLarge(t *testing.T) {
+	codec := NewHDLC().WithMaxFrameSize(4)
+	if _, err := codec.Encode([]byte("too long")); !errors.Is(err, ErrFrameTooLarge) {
+		t.Errorf("Encode error = %v, want ErrFrameTooLarge", err)
+	}
+
+	good, _ := codec.Encode([]byte("ok"))
+	stream := append([]byte{0x7E}, bytes.Repeat([]byte{'x'}, 64)...)
+	stream = append(stream, good...)
+	dec := codec.NewDecoder(bytes.NewReader(stream))
+
+	if _, err := dec.Decode(context.Background()); !errors.Is(err, ErrFrameTooLarge) {
+		t.Fatalf("Decode error = %v, want ErrFrameTooLarge", err)
+	}
+	payload, err := dec.Decode(context.Background())
+	if err != nil {
+		t.Fatalf("Decode after oversize failed: %v", err)
+	}
+	if string(payload) != "ok" {
+		t.Errorf("payload = %q, want ok", payload)
+	}
+}
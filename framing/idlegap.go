@@ -0,0 +1,176 @@
+package framing
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Common serial character sizes in bits, including start, parity and stop bits
+const (
+	BitsPerChar8N1 = 10
+	BitsPerChar8E1 = 11
+	BitsPerChar8N2 = 11
+)
+
+// CharacterTime returns the time one character occupies on the wire
+func CharacterTime(baudRate, bitsPerChar int) time.Duration {
+	if baudRate <= 0 {
+		return 0
+	}
+	return time.Duration(bitsPerChar) * time.Second / time.Duration(baudRate)
+}
+
+// IdleGap frames messages by the silence between them
+// Many ad-hoc binary protocols (and Modbus RTU, with its 3.5 character
+// inter-frame gap) have no delimiter: a frame ends when the line goes quiet.
+//
+// The decoder timestamps every read, with the arrival times of a reader that
+// implements serial.TimestampedReader and the time each Read returned
+// otherwise. A frame is complete when a read times out after the gap has
+// elapsed, or when new data arrives after a gap. Open the port with a read
+// timeout no longer than the latency you can accept; with
+// VTIME-based timeouts a frame is delivered at the next read timeout.
+//
+// Encode returns the payload unchanged. Senders are responsible for keeping
+// the line quiet for at least the gap between frames.
+type IdleGap struct {
+	gap          time.Duration
+	charTime     time.Duration // Used to estimate when the first byte of a chunk arrived
+	maxFrameSize int
+	now          func() time.Time
+}
+
+// NewIdleGap creates a codec that ends frames after gap of silence
+func NewIdleGap(gap time.Duration) *IdleGap {
+	return &IdleGap{
+		gap:          gap,
+		maxFrameSize: DefaultMaxFrameSize,
+		now:          time.Now,
+	}
+}
+
+// NewIdleGapChars creates a codec whose gap is a number of character times at baudRate
+// For Modbus RTU at 9600 8E1: NewIdleGapChars(9600, BitsPerChar8E1, 3.5).
+// The character time also refines gap detection: a chunk of n bytes is assumed
+// to have started n character times before the read returned.
+func NewIdleGapChars(baudRate, bitsPerChar int, chars float64) *IdleGap {
+	charTime := CharacterTime(baudRate, bitsPerChar)
+	c := NewIdleGap(time.Duration(chars * float64(charTime)))
+	c.charTime = charTime
+	return c
+}
+
+// WithMaxFrameSize sets the largest frame the decoder will buffer
+func (c *IdleGap) WithMaxFrameSize(size int) *IdleGap {
+	c.maxFrameSize = size
+	return c
+}
+
+// Gap returns the configured inter-frame silence
+func (c *IdleGap) Gap() time.Duration {
+	return c.gap
+}
+
+// Encode returns a copy of payload; framing is carried by timing alone
+func (c *IdleGap) Encode(payload []byte) ([]byte, error) {
+	if len(payload) > c.maxFrameSize {
+		return nil, ErrFrameTooLarge
+	}
+	return append([]byte(nil), payload...), nil
+}
+
+// NewDecoder returns a decoder that splits r on idle gaps
+func (c *IdleGap) NewDecoder(r io.Reader) Decoder {
+	d := &idleGapDecoder{codec: c, r: r, chunk: make([]byte, 512)}
+	d.tr, _ = r.(timestampedReader)
+	return d
+}
+
+type idleGapDecoder struct {
+	codec    *IdleGap
+	r        io.Reader
+	tr       timestampedReader // r, if it reports arrival times
+	chunk    []byte
+	buf      []byte
+	last     time.Time // When the most recent data was read
+	oversize bool      // Discarding an oversized frame until the next gap
+	err      error     // Read error, returned once the buffered frame is delivered
+}
+
+func (d *idleGapDecoder) Decode(ctx context.Context) ([]byte, error) {
+	for {
+		if d.err != nil {
+			if d.pending() {
+				// The stream ended; whatever was buffered is the last frame
+				return d.finish()
+			}
+			return nil, d.err
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		n, now, err := d.read()
+		d.err = err
+
+		if n == 0 {
+			if d.pending() && now.Sub(d.last) >= d.codec.gap {
+				return d.finish()
+			}
+			continue
+		}
+
+		// Estimate when the first byte of this chunk arrived
+		start := now.Add(-time.Duration(n) * d.codec.charTime)
+		if d.pending() && start.Sub(d.last) >= d.codec.gap {
+			frame, ferr := d.finish()
+			d.append(d.chunk[:n], now)
+			return frame, ferr
+		}
+		d.append(d.chunk[:n], now)
+	}
+}
+
+// read reads the next chunk and returns when it arrived
+func (d *idleGapDecoder) read() (int, time.Time, error) {
+	if d.tr != nil {
+		// Without a Done channel the read blocks like Read would
+		n, at, err := d.tr.ReadTimestamped(context.Background(), d.chunk)
+		if !at.IsZero() {
+			return n, at, err
+		}
+		return n, d.codec.now(), err
+	}
+	n, err := d.r.Read(d.chunk)
+	return n, d.codec.now(), err
+}
+
+// pending reports whether a frame (possibly oversized) is in progress
+func (d *idleGapDecoder) pending() bool {
+	return len(d.buf) > 0 || d.oversize
+}
+
+// append buffers data, switching to discard mode when the frame grows too large
+func (d *idleGapDecoder) append(data []byte, now time.Time) {
+	d.last = now
+	if d.oversize {
+		return
+	}
+	d.buf = append(d.buf, data...)
+	if len(d.buf) > d.codec.maxFrameSize {
+		d.buf = d.buf[:0]
+		d.oversize = true
+	}
+}
+
+// finish returns the buffered frame, or ErrFrameTooLarge if it was discarded
+func (d *idleGapDecoder) finish() ([]byte, error) {
+	if d.oversize {
+		d.oversize = false
+		return nil, ErrFrameTooLarge
+	}
+	frame := append([]byte(nil), d.buf...)
+	d.buf = d.buf[:0]
+	return frame, nil
+}
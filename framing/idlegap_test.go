@@ -0,0 +1,218 @@
+package framing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// timedRead is one scripted read: data returned at a point in time
+type timedRead struct {
+	at   time.Duration
+	data string
+}
+
+// scriptedStream replays reads and reports their times through clock
+type scriptedStream struct {
+	reads []timedRead
+	base  time.Time
+	now   time.Time
+}
+
+func (s *scriptedStream) Read(p []byte) (int, error) {
+	if len(s.reads) == 0 {
+		return 0, io.EOF
+	}
+	r := s.reads[0]
+	s.reads = s.reads[1:]
+	s.now = s.base.Add(r.at)
+	return copy(p, r.data), nil
+}
+
+func (s *scriptedStream) clock() time.Time {
+	return s.now
+}
+
+func decodeScript(t *testing.T, codec *IdleGap, reads []timedRead) []string {
+	t.Helper()
+
+	stream := &scriptedStream{reads: reads, base: time.Now()}
+	codec.now = stream.clock
+	dec := codec.NewDecoder(stream)
+
+	var frames []string
+	for {
+		frame, err := dec.Decode(context.Background())
+		if err == io.EOF {
+			return frames
+		}
+		if err != nil {
+			frames = append(frames, "error: "+err.Error())
+			continue
+		}
+		frames = append(frames, string(frame))
+	}
+}
+
+func TestCharacterTime(t *testing.T) {
+	tests := []struct {
+		baud, bits int
+		want       time.Duration
+	}{
+		{9600, BitsPerChar8N1, 1041666 * time.Nanosecond},
+		{9600, BitsPerChar8E1, 1145833 * time.Nanosecond},
+		{115200, BitsPerChar8N1, 86805 * time.Nanosecond},
+		{0, BitsPerChar8N1, 0},
+	}
+
+	for _, tt := range tests {
+		if got := CharacterTime(tt.baud, tt.bits); got != tt.want {
+			t.Errorf("CharacterTime(%d, %d) = %v, want %v", tt.baud, tt.bits, got, tt.want)
+		}
+	}
+
+	// Modbus RTU at 9600 8E1: 3.5 characters ~ 4.01ms
+	if gap := NewIdleGapChars(9600, BitsPerChar8E1, 3.5).Gap(); gap < 4*time.Millisecond || gap > 4100*time.Microsecond {
+		t.Errorf("3.5 char gap at 9600 8E1 = %v, want ~4.01ms", gap)
+	}
+}
+
+func TestIdleGapDecode(t *testing.T) {
+	frames := decodeScript(t, NewIdleGap(10*time.Millisecond), []timedRead{
+		{0, "ab"},
+		{2 * time.Millisecond, "c"},
+		{5 * time.Millisecond, ""},  // Timeout before the gap: keep buffering
+		{15 * time.Millisecond, ""}, // Timeout after the gap: frame complete
+		{20 * time.Millisecond, "de"},
+		{40 * time.Millisecond, "f"}, // Data after a gap: previous frame complete
+		{45 * time.Millisecond, "g"},
+	})
+
+	want := []string{"abc", "de", "fg"}
+	if len(frames) != len(want) {
+		t.Fatalf("frames = %q, want %q", frames, want)
+	}
+	for i := range want {
+		if frames[i] != want[i] {
+			t.Errorf("frame %d = %q, want %q", i, frames[i], want[i])
+		}
+	}
+}
+
+func TestIdleGapCharacterTimeCompensation(t *testing.T) {
+	// 9600 8N1: ~1.04ms per character, gap 3.5 chars ~3.6ms
+	codec := NewIdleGapChars(9600, BitsPerChar8N1, 3.5)
+
+	// The second read returns 4ms after the first, but carries 4 characters
+	// that were on the wire for ~4.2ms: there was no silence between them.
+	frames := decodeScript(t, codec, []timedRead{
+		{0, "\x01\x03"},
+		{4 * time.Millisecond, "\x00\x00"},
+		{8200 * time.Microsecond, "\x00\x0A"},
+		{20 * time.Millisecond, ""},
+	})
+
+	if len(frames) != 1 || frames[0] != "\x01\x03\x00\x00\x00\x0A" {
+		t.Errorf("frames = %q, want one 6-byte frame", frames)
+	}
+}
+
+func TestIdleGapFrameTooLarge(t *testing.T) {
+	codec := NewIdleGap(10 * time.Millisecond).WithMaxFrameSize(4)
+
+	frames := decodeScript(t, codec, []timedRead{
+		{0, "abc"},
+		{1 * time.Millisecond, "def"},
+		{30 * time.Millisecond, "ok"},
+	})
+
+	want := []string{"error: " + ErrFrameTooLarge.Error(), "ok"}
+	if len(frames) != 2 || frames[0] != want[0] || frames[1] != want[1] {
+		t.Errorf("frames = %q, want %q", frames, want)
+	}
+
+	if _, err := codec.Encode(bytes.Repeat([]byte{'x'}, 5)); !errors.Is(err, ErrFrameTooLarge) {
+		t.Errorf("Encode error = %v, want ErrFrameTooLarge", err)
+	}
+}
+
+// stampedStream is a scriptedStream that reports each read's scripted time
+// as its arrival time
+type stampedStream struct {
+	*scriptedStream
+}
+
+func (s stampedStream) ReadTimestamped(ctx context.Context, p []byte) (int, time.Time, error) {
+	n, err := s.Read(p)
+	return n, s.now, err
+}
+
+func TestIdleGapArrivalTimes(t *testing.T) {
+	// Both reads return late and together; only their arrival times show
+	// the gap between them
+	reads := []timedRead{
+		{0, "ab"},
+		{30 * time.Millisecond, "cd"},
+	}
+	base := time.Now()
+	late := func() time.Time { return base.Add(time.Second) }
+
+	decodeAll := func(dec Decoder) []string {
+		var frames []string
+		for {
+			frame, err := dec.Decode(context.Background())
+			if err != nil {
+				return frames
+			}
+			frames = append(frames, string(frame))
+		}
+	}
+
+	codec := NewIdleGap(10 * time.Millisecond)
+	codec.now = late
+	frames := decodeAll(codec.NewDecoder(stampedStream{&scriptedStream{reads: reads, base: base}}))
+	if len(frames) != 2 || frames[0] != "ab" || frames[1] != "cd" {
+		t.Errorf("frames = %q, want [\"ab\" \"cd\"] split by arrival time", frames)
+	}
+
+	// A PacketPort passes the arrival times through to the decoder
+	pp := NewPacketPort(struct {
+		stampedStream
+		io.Writer
+	}{stampedStream{&scriptedStream{reads: reads, base: base}}, io.Discard}, codec)
+	payload, at, err := pp.ReadPacket(context.Background())
+	if err != nil || string(payload) != "ab" || !at.Equal(base) {
+		t.Errorf("ReadPacket = %q, %v, %v, want \"ab\" stamped at the first arrival", payload, at, err)
+	}
+
+	// Without arrival times every read is stamped when it returned
+	frames = decodeAll(codec.NewDecoder(&scriptedStream{reads: reads, base: base}))
+	if len(frames) != 1 || frames[0] != "abcd" {
+		t.Errorf("frames = %q, want one frame without arrival times", frames)
+	}
+}
+
+func TestIdleGapRealTime(t *testing.T) {
+	lb := newLoopback()
+	pp := NewPacketPort(lb, NewIdleGap(20*time.Millisecond))
+
+	go func() {
+		lb.Write([]byte("first"))
+		time.Sleep(60 * time.Millisecond)
+		lb.Write([]byte("second"))
+		lb.Close()
+	}()
+
+	for _, want := range []string{"first", "second"} {
+		payload, _, err := pp.ReadPacket(context.Background())
+		if err != nil {
+			t.Fatalf("ReadPacket failed: %v", err)
+		}
+		if string(payload) != want {
+			t.Errorf("payload = %q, want %q", payload, want)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package framing
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// LengthPrefixed frames messages by preceding each with its payload length
+// The prefix is 1, 2 or 4 bytes in the given byte order and counts only the
+// payload after it. A prefix over the maximum frame size usually means the
+// decoder lost sync; it skips one byte and tries again from the next.
+type LengthPrefixed struct {
+	size         int
+	order        binary.ByteOrder
+	maxFrameSize int
+}
+
+// NewLengthPrefixed creates a codec with a size-byte length prefix in order
+func NewLengthPrefixed(size int, order binary.ByteOrder) *LengthPrefixed {
+	return &LengthPrefixed{
+		size:         size,
+		order:        order,
+		maxFrameSize: DefaultMaxFrameSize,
+	}
+}
+
+// WithMaxFrameSize sets the largest payload the codec accepts
+func (l *LengthPrefixed) WithMaxFrameSize(size int) *LengthPrefixed {
+	l.maxFrameSize = size
+	return l
+}
+
+// maxLength returns the largest length the prefix can hold, or an error for
+// an unsupported prefix size
+func (l *LengthPrefixed) maxLength() (uint64, error) {
+	switch l.size {
+	case 1, 2, 4:
+		return 1<<(8*l.size) - 1, nil
+	}
+	return 0, fmt.Errorf("%w: length prefix of %d bytes", ErrInvalidFrame, l.size)
+}
+
+// Encode prepends the length of payload
+func (l *LengthPrefixed) Encode(payload []byte) ([]byte, error) {
+	prefix, err := l.prefix(len(payload))
+	if err != nil {
+		return nil, err
+	}
+	frame := make([]byte, 0, len(prefix)+len(payload))
+	frame = append(frame, prefix...)
+	return append(frame, payload...), nil
+}
+
+// EncodeVector returns the length prefix and payload as separate buffers
+func (l *LengthPrefixed) EncodeVector(payload []byte) ([][]byte, error) {
+	return l.encodeParts([][]byte{payload})
+}
+
+func (l *LengthPrefixed) encodeParts(parts [][]byte) ([][]byte, error) {
+	length := 0
+	for _, part := range parts {
+		length += len(part)
+	}
+	prefix, err := l.prefix(length)
+	if err != nil {
+		return nil, err
+	}
+	return append([][]byte{prefix}, parts...), nil
+}
+
+// prefix returns the length prefix of a payload of length bytes
+func (l *LengthPrefixed) prefix(length int) ([]byte, error) {
+	limit, err := l.maxLength()
+	if err != nil {
+		return nil, err
+	}
+	if length > l.maxFrameSize || uint64(length) > limit {
+		return nil, ErrFrameTooLarge
+	}
+	prefix := make([]byte, l.size)
+	switch l.size {
+	case 1:
+		prefix[0] = byte(length)
+	case 2:
+		l.order.PutUint16(prefix, uint16(length))
+	case 4:
+		l.order.PutUint32(prefix, uint32(length))
+	}
+	return prefix, nil
+}
+
+// NewDecoder returns a decoder that reads length-prefixed frames from r
+func (l *LengthPrefixed) NewDecoder(r io.Reader) Decoder {
+	return &lengthPrefixedDecoder{codec: l, stream: newStreamBuffer(r)}
+}
+
+type lengthPrefixedDecoder struct {
+	codec  *LengthPrefixed
+	stream *streamBuffer
+}
+
+func (d *lengthPrefixedDecoder) Decode(ctx context.Context) ([]byte, error) {
+	if _, err := d.codec.maxLength(); err != nil {
+		return nil, err
+	}
+	size := d.codec.size
+
+	for {
+		if len(d.stream.buf) >= size {
+			var n int
+			switch size {
+			case 1:
+				n = int(d.stream.buf[0])
+			case 2:
+				n = int(d.codec.order.Uint16(d.stream.buf))
+			case 4:
+				n = int(d.codec.order.Uint32(d.stream.buf))
+			}
+			if n > d.codec.maxFrameSize {
+				d.stream.consume(1)
+				return nil, ErrFrameTooLarge
+			}
+			if len(d.stream.buf) >= size+n {
+				d.stream.consume(size)
+				return d.stream.take(n), nil
+			}
+		}
+
+		if err := d.stream.fill(ctx); err != nil {
+			return nil, err
+		}
+	}
+}
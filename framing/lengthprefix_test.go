@@ -0,0 +1,80 @@
+package framing
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestLengthPrefixedEncode(t *testing.T) {
+	tests := []struct {
+		size  int
+		order binary.ByteOrder
+		want  []byte
+	}{
+		{1, binary.BigEndian, []byte{0x02, 'h', 'i'}},
+		{2, binary.BigEndian, []byte{0x00, 0x02, 'h', 'i'}},
+		{2, binary.LittleEndian, []byte{0x02, 0x00, 'h', 'i'}},
+		{4, binary.LittleEndian, []byte{0x02, 0x00, 0x00, 0x00, 'h', 'i'}},
+	}
+	for _, tt := range tests {
+		frame, err := NewLengthPrefixed(tt.size, tt.order).Encode([]byte("hi"))
+		if err != nil {
+			t.Fatalf("Encode with %d-byte prefix failed: %v", tt.size, err)
+		}
+		if !bytes.Equal(frame, tt.want) {
+			t.Errorf("Encode with %d-byte prefix = % X, want % X", tt.size, frame, tt.want)
+		}
+	}
+
+	if _, err := NewLengthPrefixed(1, binary.BigEndian).Encode(make([]byte, 256)); !errors.Is(err, ErrFrameTooLarge) {
+		t.Errorf("256 bytes with 1-byte prefix error = %v, want ErrFrameTooLarge", err)
+	}
+	if _, err := NewLengthPrefixed(3, binary.BigEndian).Encode([]byte("hi")); !errors.Is(err, ErrInvalidFrame) {
+		t.Errorf("3-byte prefix error = %v, want ErrInvalidFrame", err)
+	}
+}
+
+func TestLengthPrefixedDecode(t *testing.T) {
+	codec := NewLengthPrefixed(2, binary.BigEndian)
+	stream := []byte{0x00, 0x03, 0x01, 0x02, 0x03, 0x00, 0x00, 0x00, 0x01, 0x0A, 0x00, 0x05, 'p'}
+	dec := codec.NewDecoder(&slowReader{data: stream})
+
+	want := [][]byte{{0x01, 0x02, 0x03}, {}, {0x0A}}
+	for _, w := range want {
+		payload, err := dec.Decode(context.Background())
+		if err != nil {
+			t.Fatalf("Decode failed: %v", err)
+		}
+		if !bytes.Equal(payload, w) {
+			t.Errorf("payload = % X, want % X", payload, w)
+		}
+	}
+
+	if _, err := dec.Decode(context.Background()); err != io.EOF {
+		t.Errorf("Decode of partial frame = %v, want io.EOF", err)
+	}
+}
+
+func TestLengthPrefixedFrameTooLarge(t *testing.T) {
+	codec := NewLengthPrefixed(2, binary.LittleEndian).WithMaxFrameSize(8)
+	stream := []byte{0xFF, 0xFF, 0x02, 0x00, 'o', 'k'}
+	dec := codec.NewDecoder(bytes.NewReader(stream))
+
+	for range 2 {
+		if _, err := dec.Decode(context.Background()); !errors.Is(err, ErrFrameTooLarge) {
+			t.Fatalf("Decode error = %v, want ErrFrameTooLarge", err)
+		}
+	}
+
+	payload, err := dec.Decode(context.Background())
+	if err != nil {
+		t.Fatalf("Decode after resync failed: %v", err)
+	}
+	if string(payload) != "ok" {
+		t.Errorf("payload = %q, want ok", payload)
+	}
+}
@@ -0,0 +1,156 @@
+package framing
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// PacketPort exchanges whole messages instead of bytes
+// It is the shared message pump for protocol layers built on a serial port.
+type PacketPort interface {
+	// ReadPacket blocks until the next frame is decoded and returns its payload
+	// together with its receive time (the arrival of the first read that
+	// delivered data after the previous frame). Per-frame errors such as
+	// *ChecksumError leave the port usable; call ReadPacket again.
+	ReadPacket(ctx context.Context) ([]byte, time.Time, error)
+	// WritePacket encodes payload and writes the complete frame with a single
+	// Write, or a single Writev for a VectorCodec on a port that has one
+	WritePacket(payload []byte) error
+	// Close closes the underlying port if it implements io.Closer
+	Close() error
+}
+
+// NewPacketPort combines a port and a codec into a PacketPort
+// Writes go through rw.Write unchanged, so a serial.Port opened with
+// FlowControlCTS gates each frame on CTS as a unit. Concurrent WritePacket
+// calls are serialized so frames never interleave. Frames are stamped with
+// the arrival times of a port that implements serial.TimestampedReader, and
+// with the time each Read returned otherwise.
+func NewPacketPort(rw io.ReadWriter, codec Codec) PacketPort {
+	sr := &stampedReader{r: rw}
+	sr.tr, _ = rw.(timestampedReader)
+	return &packetPort{
+		rw:     rw,
+		codec:  codec,
+		stamps: sr,
+		dec:    codec.NewDecoder(sr),
+	}
+}
+
+type packetPort struct {
+	rw     io.ReadWriter
+	codec  Codec
+	stamps *stampedReader
+	dec    Decoder
+
+	readMu  sync.Mutex
+	writeMu sync.Mutex
+}
+
+func (p *packetPort) ReadPacket(ctx context.Context) ([]byte, time.Time, error) {
+	p.readMu.Lock()
+	defer p.readMu.Unlock()
+
+	payload, err := p.dec.Decode(ctx)
+	ts := p.stamps.frameStart()
+	if err != nil {
+		return nil, ts, err
+	}
+	return payload, ts, nil
+}
+
+// vectorWriter matches the Writev method of serial.Port, which PacketPort
+// does not require of its io.ReadWriter
+type vectorWriter interface {
+	Writev(bufs [][]byte) (int, error)
+}
+
+func (p *packetPort) WritePacket(payload []byte) error {
+	if vc, ok := p.codec.(VectorCodec); ok {
+		if vw, ok := p.rw.(vectorWriter); ok {
+			bufs, err := vc.EncodeVector(payload)
+			if err != nil {
+				return err
+			}
+
+			p.writeMu.Lock()
+			defer p.writeMu.Unlock()
+
+			_, err = vw.Writev(bufs)
+			return err
+		}
+	}
+
+	frame, err := p.codec.Encode(payload)
+	if err != nil {
+		return err
+	}
+
+	p.writeMu.Lock()
+	defer p.writeMu.Unlock()
+
+	_, err = p.rw.Write(frame)
+	return err
+}
+
+func (p *packetPort) Close() error {
+	if c, ok := p.rw.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// timestampedReader matches serial.TimestampedReader
+type timestampedReader interface {
+	ReadTimestamped(ctx context.Context, buf []byte) (int, time.Time, error)
+}
+
+// stampedReader records when data arrives so decoded frames can be timestamped
+type stampedReader struct {
+	r     io.Reader
+	tr    timestampedReader // r, if it reports arrival times
+	first time.Time         // First non-empty read since the last frame was returned
+	last  time.Time         // Most recent non-empty read
+}
+
+func (s *stampedReader) Read(p []byte) (int, error) {
+	// Without a Done channel the read blocks like Read would
+	n, _, err := s.ReadTimestamped(context.Background(), p)
+	return n, err
+}
+
+// ReadTimestamped passes arrival times on to decoders that use them, such
+// as IdleGap's
+func (s *stampedReader) ReadTimestamped(ctx context.Context, p []byte) (int, time.Time, error) {
+	var n int
+	var at time.Time
+	var err error
+	if s.tr != nil {
+		n, at, err = s.tr.ReadTimestamped(ctx, p)
+	} else {
+		n, err = s.r.Read(p)
+		at = time.Now()
+	}
+	if n > 0 {
+		s.last = at
+		if s.first.IsZero() {
+			s.first = at
+		}
+	}
+	return n, at, err
+}
+
+// frameStart returns the arrival time of the frame just decoded and resets tracking
+// A frame decoded entirely from data buffered by an earlier read is stamped
+// with the time of that read; a frame that started in the tail of the previous
+// frame's last read is stamped with the next read.
+func (s *stampedReader) frameStart() time.Time {
+	ts := s.first
+	if ts.IsZero() {
+		ts = s.last
+	}
+	s.first = time.Time{}
+	return ts
+}
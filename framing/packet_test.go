@@ -0,0 +1,199 @@
+package framing
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+// loopback feeds everything written to it back to its reader
+type loopback struct {
+	*io.PipeReader
+	*io.PipeWriter
+	closed bool
+}
+
+func newLoopback() *loopback {
+	r, w := io.Pipe()
+	return &loopback{PipeReader: r, PipeWriter: w}
+}
+
+func (l *loopback) Close() error {
+	l.closed = true
+	return l.PipeWriter.Close()
+}
+
+func TestPacketPortRoundTrip(t *testing.T) {
+	lb := newLoopback()
+	pp := NewPacketPort(lb, NewHDLC())
+
+	payloads := [][]byte{[]byte("first"), {0x7E, 0x7D}, []byte("third")}
+	go func() {
+		for _, p := range payloads {
+			if err := pp.WritePacket(p); err != nil {
+				t.Errorf("WritePacket failed: %v", err)
+			}
+		}
+	}()
+
+	var prev time.Time
+	for _, want := range payloads {
+		got, ts, err := pp.ReadPacket(context.Background())
+		if err != nil {
+			t.Fatalf("ReadPacket failed: %v", err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("payload = % X, want % X", got, want)
+		}
+		if ts.IsZero() || ts.Before(prev) {
+			t.Errorf("timestamp %v is zero or before previous %v", ts, prev)
+		}
+		prev = ts
+	}
+
+	if err := pp.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if !lb.closed {
+		t.Error("Close did not close the underlying port")
+	}
+	if _, _, err := pp.ReadPacket(context.Background()); err != io.EOF {
+		t.Errorf("ReadPacket after Close = %v, want io.EOF", err)
+	}
+}
+
+func TestPacketPortTimestamp(t *testing.T) {
+	lb := newLoopback()
+	pp := NewPacketPort(lb, NewDelimited([]byte{'\n'}))
+
+	before := time.Now()
+	go func() {
+		lb.Write([]byte("part"))
+		time.Sleep(20 * time.Millisecond)
+		lb.Write([]byte("ial\n"))
+	}()
+
+	payload, ts, err := pp.ReadPacket(context.Background())
+	if err != nil {
+		t.Fatalf("ReadPacket failed: %v", err)
+	}
+	if string(payload) != "partial" {
+		t.Errorf("payload = %q, want partial", payload)
+	}
+	// Stamped with the first chunk, not the one that completed the frame
+	if d := ts.Sub(before); d < 0 || d >= 20*time.Millisecond {
+		t.Errorf("timestamp %v after start, want first chunk arrival", d)
+	}
+}
+
+// stampedLoopback is a loopback whose reads report a fixed arrival time,
+// like a serial.Port stamping data when its reader received it
+type stampedLoopback struct {
+	*loopback
+	at time.Time
+}
+
+func (l *stampedLoopback) ReadTimestamped(ctx context.Context, buf []byte) (int, time.Time, error) {
+	n, err := l.Read(buf)
+	return n, l.at, err
+}
+
+func TestPacketPortPortTimestamps(t *testing.T) {
+	at := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	lb := &stampedLoopback{loopback: newLoopback(), at: at}
+	pp := NewPacketPort(lb, NewDelimited([]byte{'\n'}))
+
+	go lb.Write([]byte("frame\n"))
+	_, ts, err := pp.ReadPacket(context.Background())
+	if err != nil {
+		t.Fatalf("ReadPacket failed: %v", err)
+	}
+	if !ts.Equal(at) {
+		t.Errorf("timestamp = %v, want the port's arrival time %v", ts, at)
+	}
+}
+
+// recordingWriter captures each Write call separately
+type recordingWriter struct {
+	mu     sync.Mutex
+	writes [][]byte
+}
+
+func (w *recordingWriter) Read([]byte) (int, error) { return 0, io.EOF }
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writes = append(w.writes, append([]byte(nil), p...))
+	return len(p), nil
+}
+
+func TestPacketPortWritesWholeFrames(t *testing.T) {
+	w := &recordingWriter{}
+	pp := NewPacketPort(w, NewDelimited([]byte{0x03}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pp.WritePacket([]byte("payload"))
+		}()
+	}
+	wg.Wait()
+
+	if len(w.writes) != 8 {
+		t.Fatalf("got %d writes, want 8", len(w.writes))
+	}
+	for _, frame := range w.writes {
+		if string(frame) != "payload\x03" {
+			t.Errorf("frame = %q, want one complete frame per write", frame)
+		}
+	}
+
+	if err := pp.WritePacket([]byte{0x03}); !errors.Is(err, ErrInvalidFrame) {
+		t.Errorf("WritePacket error = %v, want ErrInvalidFrame", err)
+	}
+}
+
+// vectorRecorder records the buffers of each Writev
+type vectorRecorder struct {
+	recordingWriter
+	vectors [][][]byte
+}
+
+func (w *vectorRecorder) Writev(bufs [][]byte) (int, error) {
+	w.vectors = append(w.vectors, bufs)
+	return len(bytes.Join(bufs, nil)), nil
+}
+
+func TestPacketPortWritev(t *testing.T) {
+	w := &vectorRecorder{}
+	payload := []byte("payload")
+	pp := NewPacketPort(w, WithChecksum(NewLengthPrefixed(1, binary.BigEndian), XOR8))
+
+	if err := pp.WritePacket(payload); err != nil {
+		t.Fatalf("WritePacket failed: %v", err)
+	}
+	if len(w.writes) != 0 || len(w.vectors) != 1 {
+		t.Fatalf("got %d writes and %d vectors, want one vector", len(w.writes), len(w.vectors))
+	}
+	bufs := w.vectors[0]
+	if len(bufs) != 3 || &bufs[1][0] != &payload[0] {
+		t.Errorf("vector = % X, want prefix, the payload itself and checksum", bufs)
+	}
+
+	// Codecs that transform the payload still write whole frames
+	pp = NewPacketPort(w, NewHDLC())
+	if err := pp.WritePacket(payload); err != nil {
+		t.Fatalf("WritePacket failed: %v", err)
+	}
+	if len(w.writes) != 1 || len(w.vectors) != 1 {
+		t.Errorf("got %d writes and %d vectors, want one write", len(w.writes), len(w.vectors))
+	}
+}
@@ -0,0 +1,116 @@
+package serial
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// PortEventKind tells whether a port appeared or disappeared
+type PortEventKind int
+
+const (
+	PortAdded PortEventKind = iota
+	PortRemoved
+)
+
+func (k PortEventKind) String() string {
+	if k == PortRemoved {
+		return "removed"
+	}
+	return "added"
+}
+
+// PortEvent reports a serial port appearing or disappearing
+type PortEvent struct {
+	Kind PortEventKind
+	Time time.Time
+	// Info describes the port; for a removed port it is what was read when
+	// it appeared, as the device is gone
+	Info PortInfo
+}
+
+// WatchPorts reports serial ports appearing and disappearing until ctx is
+// done, then closes the channel
+// The ports present when watching starts are reported as added first, so
+// every port is seen exactly once. ListPorts is polled every interval, or
+// every 500ms if interval is not positive; a port unplugged and replugged
+// within one interval may go unnoticed.
+func WatchPorts(ctx context.Context, interval time.Duration) (<-chan PortEvent, error) {
+	if interval <= 0 {
+		interval = 500 * time.Millisecond
+	}
+	ports, err := ListPorts()
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan PortEvent)
+	go func() {
+		defer close(events)
+		known := make(map[string]PortInfo)
+		send := func(ev PortEvent) bool {
+			select {
+			case events <- ev:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			added, removed := diffPorts(known, ports)
+			now := time.Now()
+			for _, path := range removed {
+				info := known[path]
+				delete(known, path)
+				if !send(PortEvent{Kind: PortRemoved, Time: now, Info: info}) {
+					return
+				}
+			}
+			for _, path := range added {
+				info := PortInfo{Name: filepath.Base(path), Path: path}
+				if i, err := GetPortInfo(path); err == nil {
+					info = *i
+				}
+				known[path] = info
+				if !send(PortEvent{Kind: PortAdded, Time: now, Info: info}) {
+					return
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			// A failed scan leaves the known ports as they are
+			if current, err := ListPorts(); err == nil {
+				ports = current
+			}
+		}
+	}()
+	return events, nil
+}
+
+// diffPorts returns the ports not in known, in the order of ports, and the
+// known ports no longer in ports, sorted
+func diffPorts(known map[string]PortInfo, ports []string) (added, removed []string) {
+	present := make(map[string]bool, len(ports))
+	for _, path := range ports {
+		present[path] = true
+		if _, ok := known[path]; !ok {
+			added = append(added, path)
+		}
+	}
+	for path := range known {
+		if !present[path] {
+			removed = append(removed, path)
+		}
+	}
+	sort.Strings(removed)
+	return added, removed
+}
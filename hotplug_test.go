@@ -0,0 +1,61 @@
+package serial
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDiffPorts(t *testing.T) {
+	known := map[string]PortInfo{
+		"/dev/ttyUSB0": {Name: "ttyUSB0"},
+		"/dev/ttyUSB1": {Name: "ttyUSB1"},
+		"/dev/ttyS0":   {Name: "ttyS0"},
+	}
+	added, removed := diffPorts(known, []string{"/dev/ttyACM0", "/dev/ttyS0", "/dev/ttyUSB2"})
+	if want := []string{"/dev/ttyACM0", "/dev/ttyUSB2"}; !slices.Equal(added, want) {
+		t.Errorf("added = %v, want %v", added, want)
+	}
+	if want := []string{"/dev/ttyUSB0", "/dev/ttyUSB1"}; !slices.Equal(removed, want) {
+		t.Errorf("removed = %v, want %v", removed, want)
+	}
+
+	if added, removed := diffPorts(known, []string{"/dev/ttyS0", "/dev/ttyUSB0", "/dev/ttyUSB1"}); added != nil || removed != nil {
+		t.Errorf("unchanged ports diff = %v, %v, want none", added, removed)
+	}
+}
+
+func TestWatchPorts(t *testing.T) {
+	ports, err := ListPorts()
+	if err != nil {
+		t.Skipf("ListPorts failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := WatchPorts(ctx, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("WatchPorts failed: %v", err)
+	}
+
+	// The ports present at the start come first, as added
+	for range ports {
+		ev := <-events
+		if ev.Kind != PortAdded || ev.Info.Path == "" {
+			t.Errorf("initial event = %v %q, want added port", ev.Kind, ev.Info.Path)
+		}
+	}
+
+	cancel()
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case _, ok := <-events:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("events not closed after cancel")
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package serial
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+)
+
+// Span names passed to Instrumentation.StartSpan
+const (
+	SpanOpen     = "serial.open"
+	SpanWrite    = "serial.write"
+	SpanCTSWait  = "serial.cts_wait" // Child of serial.write while a CTS-gated write is queued
+	SpanUSBReset = "serial.usb_reset"
+)
+
+// Counter names passed to Instrumentation.Add
+const (
+	CounterBytesRead    = "serial.bytes_read"
+	CounterBytesWritten = "serial.bytes_written"
+	CounterTimeouts     = "serial.timeouts" // CTS, read and context deadline expiries
+	CounterErrors       = "serial.errors"   // Failed operations other than timeouts
+)
+
+// EndSpanFunc finishes a span, recording err (nil on success)
+type EndSpanFunc func(err error)
+
+// Instrumentation receives tracing spans and counter increments
+// It is shaped so an OpenTelemetry adapter is a thin wrapper: StartSpan maps
+// to trace.Tracer.Start and span.End, Add to an Int64Counter. Attributes use
+// slog.Attr to avoid a dependency on any telemetry SDK. Spans started from
+// WriteContext inherit the caller's context, so serial stalls appear inside
+// upstream request traces.
+type Instrumentation interface {
+	StartSpan(ctx context.Context, name string, attrs ...slog.Attr) (context.Context, EndSpanFunc)
+	Add(ctx context.Context, counter string, n int64, attrs ...slog.Attr)
+}
+
+// WithInstrumentation enables tracing spans and counters for the port
+func WithInstrumentation(inst Instrumentation) Option {
+	return func(c *Config) error {
+		if inst == nil {
+			return ErrInvalidConfig
+		}
+		c.Instrumentation = inst
+		return nil
+	}
+}
+
+// startSpan starts a span if instrumentation is enabled
+func (c *Config) startSpan(ctx context.Context, name string, attrs ...slog.Attr) (context.Context, EndSpanFunc) {
+	return startSpan(c.Instrumentation, ctx, name, attrs...)
+}
+
+func startSpan(inst Instrumentation, ctx context.Context, name string, attrs ...slog.Attr) (context.Context, EndSpanFunc) {
+	if inst == nil {
+		return ctx, func(error) {}
+	}
+	return inst.StartSpan(ctx, name, attrs...)
+}
+
+// record counts the bytes moved by an operation and classifies its error
+func (c *Config) record(ctx context.Context, counter string, n int, err error) {
+	if c.Instrumentation == nil {
+		return
+	}
+	if n > 0 {
+		c.Instrumentation.Add(ctx, counter, int64(n))
+	}
+	countError(c.Instrumentation, ctx, err)
+}
+
+// countError increments the timeout or error counter for err
+func countError(inst Instrumentation, ctx context.Context, err error) {
+	switch {
+	case inst == nil || err == nil:
+	case isTimeout(err):
+		inst.Add(ctx, CounterTimeouts, 1)
+	case errors.Is(err, context.Canceled):
+		// Cancellation is the caller's decision, not a port problem
+	default:
+		inst.Add(ctx, CounterErrors, 1)
+	}
+}
+
+func isTimeout(err error) bool {
+	return errors.Is(err, ErrCTSTimeout) || errors.Is(err, ErrReadTimeout) ||
+		errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded)
+}
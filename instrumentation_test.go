@@ -0,0 +1,148 @@
+package serial
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+)
+
+type spanKey struct{}
+
+type recordedSpan struct {
+	name   string
+	parent string
+	err    error
+	ended  bool
+}
+
+// recorder is an Instrumentation that keeps everything it receives
+type recorder struct {
+	mu       sync.Mutex
+	spans    []*recordedSpan
+	counters map[string]int64
+}
+
+func (r *recorder) StartSpan(ctx context.Context, name string, attrs ...slog.Attr) (context.Context, EndSpanFunc) {
+	parent, _ := ctx.Value(spanKey{}).(string)
+	span := &recordedSpan{name: name, parent: parent}
+
+	r.mu.Lock()
+	r.spans = append(r.spans, span)
+	r.mu.Unlock()
+
+	return context.WithValue(ctx, spanKey{}, name), func(err error) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		span.err, span.ended = err, true
+	}
+}
+
+func (r *recorder) Add(ctx context.Context, counter string, n int64, attrs ...slog.Attr) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.counters == nil {
+		r.counters = make(map[string]int64)
+	}
+	r.counters[counter] += n
+}
+
+func (r *recorder) find(name string) *recordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, span := range r.spans {
+		if span.name == name {
+			return span
+		}
+	}
+	return nil
+}
+
+func (r *recorder) count(counter string) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.counters[counter]
+}
+
+func TestWithInstrumentation(t *testing.T) {
+	config := DefaultConfig()
+	if config.Instrumentation != nil {
+		t.Errorf("default Instrumentation = %v, want nil", config.Instrumentation)
+	}
+	if err := WithInstrumentation(nil)(&config); err == nil {
+		t.Error("WithInstrumentation(nil) succeeded, want error")
+	}
+
+	master, slavePath, err := OpenPTY()
+	if err != nil {
+		t.Skipf("PTY not available: %v", err)
+	}
+	defer master.Close()
+
+	rec := &recorder{}
+	port, err := Open(slavePath, WithReadTimeout(100*time.Millisecond), WithInstrumentation(rec))
+	if err != nil {
+		t.Fatalf("Open(%s) failed: %v", slavePath, err)
+	}
+	defer port.Close()
+
+	if span := rec.find(SpanOpen); span == nil || !span.ended || span.err != nil {
+		t.Errorf("open span = %+v, want ended without error", span)
+	}
+
+	// The write span is a child of the caller's span
+	ctx := context.WithValue(context.Background(), spanKey{}, "request")
+	if _, err := port.WriteContext(ctx, []byte("hello")); err != nil {
+		t.Fatalf("WriteContext failed: %v", err)
+	}
+	span := rec.find(SpanWrite)
+	if span == nil || !span.ended {
+		t.Fatalf("write span = %+v, want ended", span)
+	}
+	if span.parent != "request" {
+		t.Errorf("write span parent = %q, want %q", span.parent, "request")
+	}
+	if got := rec.count(CounterBytesWritten); got != 5 {
+		t.Errorf("%s = %d, want 5", CounterBytesWritten, got)
+	}
+
+	master.Write([]byte("abc"))
+	buf := make([]byte, 16)
+	if _, err := port.Read(buf); err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if got := rec.count(CounterBytesRead); got != 3 {
+		t.Errorf("%s = %d, want 3", CounterBytesRead, got)
+	}
+
+	// An expired context is a timeout, not an error
+	expired, cancel := context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+	port.WriteContext(expired, []byte("x"))
+	if got := rec.count(CounterTimeouts); got != 1 {
+		t.Errorf("%s = %d, want 1", CounterTimeouts, got)
+	}
+	if got := rec.count(CounterErrors); got != 0 {
+		t.Errorf("%s = %d, want 0", CounterErrors, got)
+	}
+
+	port.Close()
+	port.Write([]byte("x"))
+	if got := rec.count(CounterErrors); got != 1 {
+		t.Errorf("%s after write to closed port = %d, want 1", CounterErrors, got)
+	}
+}
+
+func TestOpenInstrumentationError(t *testing.T) {
+	rec := &recorder{}
+	if _, err := Open("/dev/does-not-exist", WithInstrumentation(rec)); err == nil {
+		t.Fatal("Open succeeded, want error")
+	}
+	if span := rec.find(SpanOpen); span == nil || span.err == nil {
+		t.Errorf("open span = %+v, want error recorded", span)
+	}
+	if got := rec.count(CounterErrors); got != 1 {
+		t.Errorf("%s = %d, want 1", CounterErrors, got)
+	}
+}
@@ -1,6 +1,10 @@
 package colors
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
 
 // Catppuccin Mocha color palette
 var (
@@ -34,3 +38,32 @@ var (
 	Flamingo  = lipgloss.Color("#f2cdcd") // Light pink
 	Rosewater = lipgloss.Color("#f5e0dc") // Lightest pink
 )
+
+// byName maps lower-case palette names to colors, for user configuration
+var byName = map[string]lipgloss.Color{
+	"text":      Text,
+	"subtext":   Subtext0,
+	"overlay":   Overlay0,
+	"lavender":  Lavender,
+	"blue":      Blue,
+	"sapphire":  Sapphire,
+	"sky":       Sky,
+	"teal":      Teal,
+	"green":     Green,
+	"yellow":    Yellow,
+	"peach":     Peach,
+	"orange":    Peach,
+	"maroon":    Maroon,
+	"red":       Red,
+	"mauve":     Mauve,
+	"purple":    Mauve,
+	"pink":      Pink,
+	"flamingo":  Flamingo,
+	"rosewater": Rosewater,
+}
+
+// ByName returns the palette color called name, ignoring case
+func ByName(name string) (lipgloss.Color, bool) {
+	c, ok := byName[strings.ToLower(name)]
+	return c, ok
+}
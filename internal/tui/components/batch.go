@@ -0,0 +1,77 @@
+package components
+
+import (
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// RenderInterval is how often a Batcher delivers data at most, so a view
+// renders at most about 20 times per second however fast data arrives
+const RenderInterval = 50 * time.Millisecond
+
+// DataBatchMsg carries messages coalesced by a Batcher, oldest first
+type DataBatchMsg []DataReceivedMsg
+
+// Batcher coalesces the DataReceivedMsgs of a read loop
+// The first message after a quiet interval is sent at once, so echoes and
+// slow traffic are not delayed; messages arriving within the interval after
+// it are held and sent together as one DataBatchMsg when it ends. Each
+// message sent costs an Update and a View, which at high baud rates used to
+// mean a full render for every chunk the driver returned.
+type Batcher struct {
+	send     func(tea.Msg)
+	interval time.Duration
+
+	mu      sync.Mutex
+	pending DataBatchMsg
+	last    time.Time   // When data was last sent
+	timer   *time.Timer // Sends pending when the interval ends, nil if idle
+}
+
+// NewBatcher returns a Batcher passing messages to send, such as
+// tea.Program.Send, at most once per interval
+func NewBatcher(send func(tea.Msg), interval time.Duration) *Batcher {
+	return &Batcher{send: send, interval: interval}
+}
+
+// Send sends msg, or holds it for the next batch
+func (b *Batcher) Send(msg DataReceivedMsg) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.timer == nil && time.Since(b.last) >= b.interval {
+		b.last = time.Now()
+		b.send(msg)
+		return
+	}
+	b.pending = append(b.pending, msg)
+	if b.timer == nil {
+		b.timer = time.AfterFunc(b.interval-time.Since(b.last), b.flush)
+	}
+}
+
+func (b *Batcher) flush() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.timer = nil
+	if len(b.pending) == 0 {
+		return
+	}
+	b.last = time.Now()
+	batch := b.pending
+	b.pending = nil
+	b.send(batch)
+}
+
+// Stop sends held messages at once, such as when the read loop ends
+func (b *Batcher) Stop() {
+	b.mu.Lock()
+	timer := b.timer
+	b.mu.Unlock()
+	if timer != nil && timer.Stop() {
+		b.flush()
+	}
+}
@@ -0,0 +1,51 @@
+package components
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestBatcher(t *testing.T) {
+	var mu sync.Mutex
+	var sent []tea.Msg
+	b := NewBatcher(func(msg tea.Msg) {
+		mu.Lock()
+		sent = append(sent, msg)
+		mu.Unlock()
+	}, 50*time.Millisecond)
+	received := func() []tea.Msg {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]tea.Msg(nil), sent...)
+	}
+
+	// The first message goes out at once, the next ones wait for the interval
+	b.Send(DataReceivedMsg{Data: []byte("a")})
+	b.Send(DataReceivedMsg{Data: []byte("b")})
+	b.Send(DataReceivedMsg{Data: []byte("c")})
+	if got := received(); len(got) != 1 {
+		t.Fatalf("sent %d messages at once, want 1", len(got))
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	got := received()
+	if len(got) != 2 {
+		t.Fatalf("sent %d messages after the interval, want 2", len(got))
+	}
+	batch, ok := got[1].(DataBatchMsg)
+	if !ok || len(batch) != 2 || string(batch[0].Data) != "b" || string(batch[1].Data) != "c" {
+		t.Fatalf("second message = %#v, want a batch of b and c", got[1])
+	}
+
+	// Stop sends held messages without waiting
+	time.Sleep(60 * time.Millisecond)
+	b.Send(DataReceivedMsg{Data: []byte("d")})
+	b.Send(DataReceivedMsg{Data: []byte("e")})
+	b.Stop()
+	if got := received(); len(got) != 4 {
+		t.Errorf("sent %d messages after Stop, want 4", len(got))
+	}
+}
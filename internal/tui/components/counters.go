@@ -0,0 +1,96 @@
+package components
+
+import (
+	"fmt"
+	"time"
+)
+
+// counterSample is the data of one message, kept while it is within the
+// rate window
+type counterSample struct {
+	at    time.Time
+	bytes int
+}
+
+// Counters totals received bytes and lines since a start or reset, with the
+// throughput over a sliding window, for watching long soak tests
+type Counters struct {
+	bytes    int64
+	lines    int64
+	overflow int // Messages lost before display, such as oversized frames
+	since    time.Time
+	window   time.Duration
+	samples  []counterSample // Oldest first, within window of the newest
+}
+
+func NewCounters(window time.Duration, now time.Time) *Counters {
+	return &Counters{window: window, since: now}
+}
+
+// Add counts a message of n bytes holding lines complete lines
+func (c *Counters) Add(at time.Time, n, lines int) {
+	c.bytes += int64(n)
+	c.lines += int64(lines)
+	c.samples = append(c.samples, counterSample{at: at, bytes: n})
+	c.prune(at)
+}
+
+// AddOverflow counts a message that was lost before it could be shown
+func (c *Counters) AddOverflow() {
+	c.overflow++
+}
+
+// Reset starts counting again from now
+func (c *Counters) Reset(now time.Time) {
+	*c = Counters{window: c.window, since: now}
+}
+
+func (c *Counters) prune(now time.Time) {
+	start := now.Add(-c.window)
+	i := 0
+	for i < len(c.samples) && c.samples[i].at.Before(start) {
+		i++
+	}
+	c.samples = c.samples[i:]
+}
+
+// Rate returns the bytes per second received over the window up to now,
+// or since the start if that is shorter
+func (c *Counters) Rate(now time.Time) float64 {
+	c.prune(now)
+	span := min(c.window, now.Sub(c.since))
+	if span <= 0 {
+		return 0
+	}
+	total := 0
+	for _, s := range c.samples {
+		total += s.bytes
+	}
+	return float64(total) / span.Seconds()
+}
+
+// Summary renders the counters for the status bar, such as
+// "RX 1.2 MiB 8412 lines 960 B/s 3m20s"
+func (c *Counters) Summary(now time.Time) string {
+	s := fmt.Sprintf("RX %s %d lines %s/s %s", FormatSize(float64(c.bytes)), c.lines,
+		FormatSize(c.Rate(now)), now.Sub(c.since).Truncate(time.Second))
+	if c.overflow > 0 {
+		s += fmt.Sprintf(" %d overflow", c.overflow)
+	}
+	return s
+}
+
+// FormatSize renders a byte count with a binary unit, such as "12.5 KiB"
+func FormatSize(n float64) string {
+	if n < 1024 {
+		return fmt.Sprintf("%.0f B", n)
+	}
+	units := []string{"KiB", "MiB", "GiB", "TiB"}
+	for i, unit := range units {
+		n /= 1024
+		if n < 1024 || i == len(units)-1 {
+			return fmt.Sprintf("%.1f %s", n, unit)
+		}
+	}
+	return ""
+}
@@ -13,10 +13,12 @@ type DataReceivedMsg struct {
 	Timestamp    time.Time
 	Data         []byte
 	IsTX         bool
-	Status       string     // For TX messages: "PENDING", "WRITTEN", "ERROR", empty for RX
+	Status       string     // For TX messages: "PENDING", "WRITTEN", "TIMEOUT", "ERROR", empty for RX
 	Sequence     int64      // Unique sequence number for updating messages in place
 	EnqueuedTime *time.Time // When message was queued for sending (TX only)
 	WrittenTime  *time.Time // When message was actually written (TX only)
+	IsNote       bool       // Commentary, such as a status change, rather than port data
+	Source       string     // Side of a tapped link that sent the data, shown instead of RX/TX
 }
 
 type DisplayMode struct {
@@ -33,6 +35,14 @@ type DataFormatter struct {
 	mode       DisplayMode
 	options    FormatOptions
 	lineBuffer []byte // Buffer for accumulating partial lines in ASCII mode
+	framed     bool   // RX messages are whole frames, shown without line buffering
+	highlights []HighlightRule
+	filter     *Filter // Nil or inactive when not filtering
+	hidden     int     // Chunks or lines the filter has hidden
+	timeMode   TimeMode
+	mark       time.Time // Start of TimeSinceMark times
+	prev       time.Time // Time of the previous message shown, for TimeDelta
+	micro      bool      // Times of day to the microsecond
 }
 
 func NewDataFormatter(showHex, showASCII bool) *DataFormatter {
@@ -59,9 +69,31 @@ func (df *DataFormatter) SetFormatOptions(noTimestamps, noIndicators bool) {
 	df.options.NoIndicators = noIndicators
 }
 
+// SetFramed shows each RX message on a line of its own, for messages already
+// split into frames, instead of buffering data up to line breaks
+func (df *DataFormatter) SetFramed(framed bool) {
+	df.framed = framed
+}
+
+// SetHighlights sets the rules that color matching data; the first matching
+// rule wins
+func (df *DataFormatter) SetHighlights(rules []HighlightRule) {
+	df.highlights = rules
+}
+
+// SetFilter hides the chunks or lines f does not show; Hidden counts them
+func (df *DataFormatter) SetFilter(f *Filter) {
+	df.filter = f
+}
+
+// Hidden returns the number of chunks or lines the filter has hidden
+func (df *DataFormatter) Hidden() int {
+	return df.hidden
+}
+
 func (df *DataFormatter) FormatMessage(msg DataReceivedMsg) []string {
-	// For TX messages or HEX-only mode, show each chunk immediately (original behavior)
-	if msg.IsTX || (df.mode.ShowHex && !df.mode.ShowASCII) {
+	// For TX messages, frames or HEX-only mode, show each chunk immediately (original behavior)
+	if msg.IsTX || df.framed || (df.mode.ShowHex && !df.mode.ShowASCII) {
 		line := df.formatSingleChunk(msg, msg.Data)
 		if line != "" {
 			return []string{line}
@@ -84,12 +116,17 @@ func (df *DataFormatter) FormatMessage(msg DataReceivedMsg) []string {
 
 // formatSingleChunk formats a single data chunk without line buffering
 func (df *DataFormatter) formatSingleChunk(msg DataReceivedMsg, data []byte) string {
+	if !df.filter.Shows(data) {
+		df.hidden++
+		return ""
+	}
+
 	var parts []string
 
 	// Add timestamp if enabled
 	var timestampStyled string
 	if !df.options.NoTimestamps {
-		timestamp := msg.Timestamp.Format("15:04:05.000")
+		timestamp := df.formatTime(msg.Timestamp)
 		timestampStyled = lipgloss.NewStyle().
 			Foreground(colors.Subtext0).
 			Render(fmt.Sprintf("[%s]", timestamp))
@@ -101,18 +138,26 @@ func (df *DataFormatter) formatSingleChunk(msg DataReceivedMsg, data []byte) str
 		indicator = df.getIndicator(msg)
 	}
 
+	// Data matching a highlight rule is shown bold in the rule's color
+	rule, highlighted := matchHighlight(df.highlights, data)
+	highlightStyle := lipgloss.NewStyle().Foreground(rule.Color).Bold(true)
+
 	// Format data with visual styling (no prefixes, just colors)
 	if df.mode.ShowHex {
 		hexStr := fmt.Sprintf("% X", data)
-		hexPart := lipgloss.NewStyle().
-			Foreground(colors.Peach).
-			Render(hexStr)
-		parts = append(parts, hexPart)
+		hexStyle := lipgloss.NewStyle().Foreground(colors.Peach)
+		if highlighted {
+			hexStyle = highlightStyle
+		}
+		parts = append(parts, hexStyle.Render(hexStr))
 	}
 
 	if df.mode.ShowASCII {
 		asciiStr := df.bytesToASCII(data)
 		// ASCII in default color (no styling needed)
+		if highlighted {
+			asciiStr = highlightStyle.Render(asciiStr)
+		}
 		parts = append(parts, asciiStr)
 	}
 
@@ -193,8 +238,32 @@ func (df *DataFormatter) formatBufferedLines(msg DataReceivedMsg) []string {
 	return lines
 }
 
+// txLatency renders the time a TX message took from being queued to its
+// final status, such as " +1.3ms", or "" if it is not known
+func txLatency(msg DataReceivedMsg) string {
+	if msg.EnqueuedTime == nil || msg.WrittenTime == nil {
+		return ""
+	}
+	d := msg.WrittenTime.Sub(*msg.EnqueuedTime)
+	switch {
+	case d < time.Millisecond:
+		return fmt.Sprintf(" +%dµs", d.Microseconds())
+	case d < 10*time.Millisecond:
+		return fmt.Sprintf(" +%.1fms", float64(d)/float64(time.Millisecond))
+	default:
+		return fmt.Sprintf(" +%dms", d.Milliseconds())
+	}
+}
+
 // getIndicator creates the styled TX/RX indicator
 func (df *DataFormatter) getIndicator(msg DataReceivedMsg) string {
+	if msg.Source != "" {
+		// Tapped traffic: the arrow and color tell the directions apart
+		if msg.IsTX {
+			return lipgloss.NewStyle().Foreground(colors.Peach).Bold(true).Render("↗ " + msg.Source)
+		}
+		return lipgloss.NewStyle().Foreground(colors.Sky).Bold(true).Render("↙ " + msg.Source)
+	}
 	if msg.IsTX {
 		// TX with up-right arrow and status-based coloring
 		var txColor lipgloss.Color
@@ -206,19 +275,13 @@ func (df *DataFormatter) getIndicator(msg DataReceivedMsg) string {
 			statusText = "TX [ENQUEUED]"
 		case "WRITTEN":
 			txColor = colors.Green
-			statusText = "TX [SENT"
-			// Show timing delta if we have both enqueued and written times
-			if msg.EnqueuedTime != nil && msg.WrittenTime != nil {
-				delta := msg.WrittenTime.Sub(*msg.EnqueuedTime)
-				statusText += fmt.Sprintf(" +%dms", delta.Milliseconds())
-			}
-			statusText += "]"
+			statusText = "TX [SENT" + txLatency(msg) + "]"
 		case "TIMEOUT":
 			txColor = colors.Peach // Orange/peach for timeout
-			statusText = "TX [TIMEOUT - MAY STILL SEND]"
+			statusText = "TX [TIMEOUT" + txLatency(msg) + " - MAY STILL SEND]"
 		case "ERROR":
 			txColor = colors.Red
-			statusText = "TX [ERROR]"
+			statusText = "TX [ERROR" + txLatency(msg) + "]"
 		default:
 			txColor = colors.Peach
 			statusText = "TX"
@@ -291,6 +354,12 @@ func (df *DataFormatter) ClearBuffer() {
 	df.lineBuffer = df.lineBuffer[:0]
 }
 
+// ResetHidden restarts the count of hidden chunks or lines, such as before
+// reformatting all messages
+func (df *DataFormatter) ResetHidden() {
+	df.hidden = 0
+}
+
 func (df *DataFormatter) ToggleHex() {
 	df.mode.ShowHex = !df.mode.ShowHex
 }
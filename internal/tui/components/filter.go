@@ -0,0 +1,134 @@
+package components
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/allbin/go-serial/internal/tui/colors"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Filter hides messages that do not match an expression from the display
+// An expression that parses as hex, such as "01 03", matches data starting
+// with those bytes; anything else is a regular expression matched against
+// the printable rendering of the data. A leading "!" inverts the filter, so
+// "!^HB" hides heartbeat lines instead of showing only them.
+type Filter struct {
+	input  textinput.Model
+	typing bool           // Expression is being edited
+	expr   string         // Committed expression; empty when not filtering
+	invert bool           // Hide matching messages rather than the others
+	prefix []byte         // Expression as bytes if it parses as hex
+	re     *regexp.Regexp // Expression as a regexp otherwise
+	err    error          // Why the expression being edited was rejected
+}
+
+func NewFilter() *Filter {
+	ti := textinput.New()
+	ti.Prompt = "filter: "
+	ti.Placeholder = "regex or hex prefix, ! to hide matches"
+	ti.CharLimit = 128
+	return &Filter{input: ti}
+}
+
+// Start begins editing the expression, starting from the current one
+func (f *Filter) Start() {
+	f.typing = true
+	f.err = nil
+	f.input.SetValue(f.expr)
+	f.input.CursorEnd()
+	f.input.Focus()
+}
+
+// IsTyping reports whether the expression is being edited
+func (f *Filter) IsTyping() bool {
+	return f.typing
+}
+
+// IsActive reports whether a committed expression is hiding messages
+func (f *Filter) IsActive() bool {
+	return f != nil && f.expr != ""
+}
+
+// Update handles keys while typing; it returns true when the expression was
+// committed with enter or editing was abandoned with esc, which keeps the
+// previous expression. An invalid expression is reported and stays open.
+func (f *Filter) Update(msg tea.KeyMsg) (bool, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		if err := f.Set(f.input.Value()); err != nil {
+			f.err = err
+			return false, nil
+		}
+		f.typing = false
+		f.input.Blur()
+		return true, nil
+	case tea.KeyEsc:
+		f.typing = false
+		f.input.Blur()
+		return true, nil
+	}
+	f.err = nil
+	var cmd tea.Cmd
+	f.input, cmd = f.input.Update(msg)
+	return false, cmd
+}
+
+// Set commits expr, or clears the filter if expr is empty
+func (f *Filter) Set(expr string) error {
+	expr = strings.TrimSpace(expr)
+	body, invert := strings.CutPrefix(expr, "!")
+	body = strings.TrimSpace(body)
+
+	var prefix []byte
+	var re *regexp.Regexp
+	if b, err := hex.DecodeString(strings.ReplaceAll(body, " ", "")); err == nil && len(b) > 0 {
+		prefix = b
+	} else if body != "" {
+		re, err = regexp.Compile(body)
+		if err != nil {
+			return err
+		}
+	}
+	if body == "" {
+		expr = ""
+	}
+	f.expr, f.invert, f.prefix, f.re = expr, invert, prefix, re
+	return nil
+}
+
+// Shows reports whether data is displayed under the filter
+func (f *Filter) Shows(data []byte) bool {
+	if f == nil || f.expr == "" {
+		return true
+	}
+	var match bool
+	if f.prefix != nil {
+		match = bytes.HasPrefix(data, f.prefix)
+	} else {
+		match = f.re.MatchString(printable(data))
+	}
+	return match != f.invert
+}
+
+// Status summarizes the committed filter, e.g. "filter !^HB (812 hidden)"
+func (f *Filter) Status(hidden int) string {
+	if f.expr == "" {
+		return ""
+	}
+	return fmt.Sprintf("filter %s (%d hidden)", f.expr, hidden)
+}
+
+// View renders the expression being typed
+func (f *Filter) View() string {
+	view := lipgloss.NewStyle().Foreground(colors.Teal).Render(f.input.View())
+	if f.err != nil {
+		view += lipgloss.NewStyle().Foreground(colors.Red).Render("  " + f.err.Error())
+	}
+	return view
+}
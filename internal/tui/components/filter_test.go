@@ -0,0 +1,79 @@
+package components
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestFilterShows(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		data string
+		want bool
+	}{
+		{"no filter", "", "anything", true},
+		{"regex match", "^HB", "HB 42", true},
+		{"regex miss", "^HB", "DATA 42", false},
+		{"inverted match", "!^HB", "HB 42", false},
+		{"inverted miss", "! ^HB", "DATA 42", true},
+		{"regex on printable", `\.\.OK`, "\x00\x01OK", true},
+		{"hex prefix", "01 03", "\x01\x03\x02", true},
+		{"hex prefix elsewhere", "0103", "\x00\x01\x03", false},
+		{"inverted hex prefix", "!01", "\x01\x03", false},
+		{"bang alone clears", "!", "anything", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewFilter()
+			if err := f.Set(tt.expr); err != nil {
+				t.Fatalf("Set(%q) failed: %v", tt.expr, err)
+			}
+			if got := f.Shows([]byte(tt.data)); got != tt.want {
+				t.Errorf("Shows(%q) under %q = %v, want %v", tt.data, tt.expr, got, tt.want)
+			}
+		})
+	}
+
+	var f *Filter
+	if !f.Shows([]byte("x")) || f.IsActive() {
+		t.Error("nil filter hides data")
+	}
+}
+
+func TestFilterEditing(t *testing.T) {
+	f := NewFilter()
+	if err := f.Set("^HB"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := f.Status(3), "filter ^HB (3 hidden)"; got != want {
+		t.Errorf("Status = %q, want %q", got, want)
+	}
+
+	// An invalid expression stays open and keeps the committed one
+	f.Start()
+	f.input.SetValue("(")
+	if done, _ := f.Update(tea.KeyMsg{Type: tea.KeyEnter}); done || f.err == nil {
+		t.Errorf("enter on invalid expression: done = %v, err = %v", done, f.err)
+	}
+	if !f.Shows([]byte("HB")) || f.Shows([]byte("DATA")) {
+		t.Error("invalid expression replaced the committed filter")
+	}
+
+	// Esc abandons the edit
+	if done, _ := f.Update(tea.KeyMsg{Type: tea.KeyEsc}); !done || f.IsTyping() {
+		t.Errorf("esc: done = %v, typing = %v", done, f.IsTyping())
+	}
+
+	// Enter on an empty expression clears the filter
+	f.Start()
+	f.input.SetValue("")
+	if done, _ := f.Update(tea.KeyMsg{Type: tea.KeyEnter}); !done || f.IsActive() {
+		t.Errorf("enter on empty expression: done = %v, active = %v", done, f.IsActive())
+	}
+	if f.Status(0) != "" {
+		t.Errorf("Status without a filter = %q, want empty", f.Status(0))
+	}
+}
@@ -0,0 +1,70 @@
+package components
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/allbin/go-serial/internal/tui/colors"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// HighlightRule colors data that Pattern matches
+// Text rules match the printable rendering of the data, with non-printable
+// bytes shown as '.'; hex rules match the upper-case, space-separated hex
+// rendering, such as "01 83 02 C0 F1".
+type HighlightRule struct {
+	Name    string
+	Pattern *regexp.Regexp
+	Hex     bool
+	Color   lipgloss.Color
+}
+
+// Matches reports whether the rule matches data
+func (r HighlightRule) Matches(data []byte) bool {
+	if r.Hex {
+		return r.Pattern.MatchString(fmt.Sprintf("% X", data))
+	}
+	return r.Pattern.MatchString(printable(data))
+}
+
+// BuiltinHighlights returns the rules used unless configuration replaces
+// them by name
+func BuiltinHighlights() []HighlightRule {
+	return []HighlightRule{
+		{
+			Name:    "error",
+			Pattern: regexp.MustCompile(`(?i)\b(error|fail(ed|ure)?|fatal)\b`),
+			Color:   colors.Red,
+		},
+		{
+			Name:    "nak",
+			Pattern: regexp.MustCompile(`\bNAK\b`),
+			Color:   colors.Peach,
+		},
+		{
+			// A lone ASCII NAK byte, as sent by XMODEM and many framed protocols
+			Name:    "nak-byte",
+			Pattern: regexp.MustCompile(`^15$`),
+			Hex:     true,
+			Color:   colors.Peach,
+		},
+		{
+			// Modbus RTU exception response: address, function code with the
+			// high bit set, exception code other than 05 (ACKNOWLEDGE), CRC
+			Name:    "modbus-exception",
+			Pattern: regexp.MustCompile(`^[0-9A-F]{2} (8[1-9A-F]|9[0-9A-F]|A[0-9AB]) 0[1-46-9AB] [0-9A-F]{2} [0-9A-F]{2}$`),
+			Hex:     true,
+			Color:   colors.Maroon,
+		},
+	}
+}
+
+// matchHighlight returns the first of rules that matches data
+func matchHighlight(rules []HighlightRule, data []byte) (HighlightRule, bool) {
+	for _, r := range rules {
+		if r.Matches(data) {
+			return r, true
+		}
+	}
+	return HighlightRule{}, false
+}
@@ -0,0 +1,42 @@
+package components
+
+import "testing"
+
+func TestBuiltinHighlights(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string // Name of the matching rule, or empty for none
+	}{
+		{"error", []byte("ERROR: bad checksum\r\n"), "error"},
+		{"failed", []byte("init failed"), "error"},
+		{"word inside another", []byte("terrors"), ""},
+		{"nak text", []byte("got NAK"), "nak"},
+		{"nak byte", []byte{0x15}, "nak-byte"},
+		{"nak byte in data", []byte{0x15, 0x01}, ""},
+		{"modbus exception", []byte{0x01, 0x83, 0x02, 0xC0, 0xF1}, "modbus-exception"},
+		{"modbus acknowledge", []byte{0x01, 0x83, 0x05, 0xC0, 0xF1}, ""},
+		{"modbus response", []byte{0x01, 0x03, 0x02, 0x00, 0x2A}, ""},
+		{"plain data", []byte("OK\r\n"), ""},
+	}
+
+	rules := BuiltinHighlights()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, ok := matchHighlight(rules, tt.data)
+			if !ok {
+				r.Name = ""
+			}
+			if r.Name != tt.want {
+				t.Errorf("matchHighlight(% X) = %q, want %q", tt.data, r.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestHighlightRuleMatchesPrintable(t *testing.T) {
+	r := HighlightRule{Pattern: BuiltinHighlights()[0].Pattern}
+	if !r.Matches([]byte("\x00\x01error\xff")) {
+		t.Error("text rule did not match around non-printable bytes")
+	}
+}
@@ -29,13 +29,21 @@ func (s SendingMode) String() string {
 	}
 }
 
+// HistoryLimit is the number of entries kept per sending mode
+const HistoryLimit = 1000
+
 type Input struct {
 	textInput     textinput.Model
 	sendingMode   SendingMode
-	history       []string
+	history       [2][]string // Sent input per sending mode, oldest first
 	historyIndex  int
 	currentInput  string // Store current input when navigating history
 	terminalWidth int    // Store terminal width for consistent sizing
+
+	// Reverse incremental history search, started with ctrl+r
+	searching   bool
+	searchQuery string
+	searchMatch int // Index of the matching history entry, -1 if none
 }
 
 func NewInput(placeholder string) *Input {
@@ -51,7 +59,6 @@ func NewInput(placeholder string) *Input {
 	return &Input{
 		textInput:    ti,
 		sendingMode:  SendingModeHex,
-		history:      make([]string, 0),
 		historyIndex: -1,
 		currentInput: "",
 	}
@@ -85,6 +92,9 @@ func (i *Input) SetValue(value string) {
 }
 
 func (i *Input) ToggleSendingMode() {
+	// Each mode has its own history
+	i.historyIndex = -1
+	i.currentInput = ""
 	switch i.sendingMode {
 	case SendingModeASCII:
 		i.sendingMode = SendingModeHex
@@ -136,7 +146,20 @@ func (i *Input) ViewWithMode(inputMode string, isInsertMode bool) string {
 
 	styledPrompt := promptStyle.Render(promptSymbol)
 
-	if isInsertMode {
+	if isInsertMode && i.searching {
+		match := ""
+		if i.searchMatch >= 0 {
+			match = i.history[i.sendingMode][i.searchMatch]
+		}
+		label := "(reverse-i-search)"
+		if i.searchMatch < 0 && i.searchQuery != "" {
+			label = "(failed reverse-i-search)"
+		}
+		search := lipgloss.NewStyle().
+			Foreground(colors.Overlay0).
+			Render(fmt.Sprintf("%s`%s': ", label, i.searchQuery))
+		inputContent = lipgloss.JoinHorizontal(lipgloss.Left, styledPrompt, " ", search, match)
+	} else if isInsertMode {
 		// Insert mode: show input field (Tab hint moved to status bar)
 		inputField := i.textInput.View()
 		inputContent = lipgloss.JoinHorizontal(lipgloss.Left, styledPrompt, " ", inputField)
@@ -170,56 +193,70 @@ func (i *Input) ViewWithMode(inputMode string, isInsertMode bool) string {
 	return inputStyle.Render(inputContent)
 }
 
-// AddToHistory adds a command to the history if it's not empty or a duplicate
-func (i *Input) AddToHistory(command string) {
+// AddToHistory adds a command to the history of the current sending mode
+// if it's not empty or a duplicate, and reports whether it was added
+func (i *Input) AddToHistory(command string) bool {
+	// Reset history index
+	i.historyIndex = -1
+	i.currentInput = ""
+
 	command = strings.TrimSpace(command)
 	if command == "" {
-		return
+		return false
 	}
 
 	// Don't add if it's the same as the last command
-	if len(i.history) > 0 && i.history[len(i.history)-1] == command {
-		return
+	history := i.history[i.sendingMode]
+	if len(history) > 0 && history[len(history)-1] == command {
+		return false
 	}
 
-	i.history = append(i.history, command)
-
-	// Keep only last 100 commands
-	if len(i.history) > 100 {
-		i.history = i.history[1:]
+	history = append(history, command)
+	if over := len(history) - HistoryLimit; over > 0 {
+		history = history[over:]
 	}
+	i.history[i.sendingMode] = history
+	return true
+}
 
-	// Reset history index
+// LoadHistory replaces the history of a sending mode, oldest entry first,
+// such as with the history of earlier sessions
+func (i *Input) LoadHistory(mode SendingMode, entries []string) {
+	if over := len(entries) - HistoryLimit; over > 0 {
+		entries = entries[over:]
+	}
+	i.history[mode] = append([]string(nil), entries...)
 	i.historyIndex = -1
-	i.currentInput = ""
 }
 
 // NavigateHistoryUp moves up in command history
 func (i *Input) NavigateHistoryUp() {
-	if len(i.history) == 0 {
+	history := i.history[i.sendingMode]
+	if len(history) == 0 {
 		return
 	}
 
 	// First time navigating: save current input
 	if i.historyIndex == -1 {
 		i.currentInput = i.textInput.Value()
-		i.historyIndex = len(i.history) - 1
+		i.historyIndex = len(history) - 1
 	} else if i.historyIndex > 0 {
 		i.historyIndex--
 	}
 
-	i.textInput.SetValue(i.history[i.historyIndex])
+	i.textInput.SetValue(history[i.historyIndex])
 }
 
 // NavigateHistoryDown moves down in command history
 func (i *Input) NavigateHistoryDown() {
-	if len(i.history) == 0 || i.historyIndex == -1 {
+	history := i.history[i.sendingMode]
+	if len(history) == 0 || i.historyIndex == -1 {
 		return
 	}
 
-	if i.historyIndex < len(i.history)-1 {
+	if i.historyIndex < len(history)-1 {
 		i.historyIndex++
-		i.textInput.SetValue(i.history[i.historyIndex])
+		i.textInput.SetValue(history[i.historyIndex])
 	} else {
 		// Back to current input
 		i.historyIndex = -1
@@ -227,3 +264,57 @@ func (i *Input) NavigateHistoryDown() {
 		i.currentInput = ""
 	}
 }
+
+// StartHistorySearch begins a reverse incremental search of the history of
+// the current sending mode, like ctrl+r in a shell
+func (i *Input) StartHistorySearch() {
+	i.searching = true
+	i.searchQuery = ""
+	i.searchMatch = -1
+}
+
+// IsSearchingHistory reports whether a history search is in progress
+func (i *Input) IsSearchingHistory() bool {
+	return i.searching
+}
+
+// UpdateHistorySearch handles keys during a history search: typing narrows
+// the search, ctrl+r finds the next older match, enter puts the match in
+// the input for editing and esc or ctrl+g abandons the search
+func (i *Input) UpdateHistorySearch(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyCtrlR:
+		if i.searchMatch > 0 {
+			i.findHistory(i.searchMatch - 1)
+		}
+	case tea.KeyEnter:
+		if i.searchMatch >= 0 {
+			i.textInput.SetValue(i.history[i.sendingMode][i.searchMatch])
+			i.textInput.CursorEnd()
+		}
+		i.searching = false
+	case tea.KeyEsc, tea.KeyCtrlG:
+		i.searching = false
+	case tea.KeyBackspace:
+		if q := []rune(i.searchQuery); len(q) > 0 {
+			i.searchQuery = string(q[:len(q)-1])
+			i.findHistory(len(i.history[i.sendingMode]) - 1)
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		i.searchQuery += string(msg.Runes)
+		i.findHistory(len(i.history[i.sendingMode]) - 1)
+	}
+}
+
+// findHistory sets searchMatch to the newest entry at or before from that
+// contains the query, or -1
+func (i *Input) findHistory(from int) {
+	history := i.history[i.sendingMode]
+	for j := min(from, len(history)-1); j >= 0; j-- {
+		if strings.Contains(history[j], i.searchQuery) {
+			i.searchMatch = j
+			return
+		}
+	}
+	i.searchMatch = -1
+}
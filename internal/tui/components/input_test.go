@@ -0,0 +1,68 @@
+package components
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestInputHistory(t *testing.T) {
+	in := NewInput("")
+	for _, c := range []string{"01", "01", " ", "02"} {
+		in.AddToHistory(c)
+	}
+	if got := in.history[SendingModeHex]; len(got) != 2 {
+		t.Fatalf("history = %q, want duplicates and blanks skipped", got)
+	}
+
+	in.SetValue("draft")
+	in.NavigateHistoryUp()
+	in.NavigateHistoryUp()
+	if in.Value() != "01" {
+		t.Errorf("two up = %q, want 01", in.Value())
+	}
+	in.NavigateHistoryDown()
+	in.NavigateHistoryDown()
+	if in.Value() != "draft" {
+		t.Errorf("back down = %q, want the draft", in.Value())
+	}
+
+	entries := make([]string, HistoryLimit+5)
+	for i := range entries {
+		entries[i] = string(rune('a' + i%26))
+	}
+	in.LoadHistory(SendingModeASCII, entries)
+	if got := len(in.history[SendingModeASCII]); got != HistoryLimit {
+		t.Errorf("LoadHistory kept %d entries, want %d", got, HistoryLimit)
+	}
+}
+
+func TestInputHistorySearch(t *testing.T) {
+	in := NewInput("")
+	in.LoadHistory(SendingModeHex, []string{"0103 0000", "0206", "0103 0010"})
+	in.SetValue("")
+
+	in.StartHistorySearch()
+	in.UpdateHistorySearch(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("0103")})
+	if in.searchMatch != 2 {
+		t.Errorf("match = %d, want the newest entry 2", in.searchMatch)
+	}
+	in.UpdateHistorySearch(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if in.searchMatch != 0 {
+		t.Errorf("ctrl+r match = %d, want the older entry 0", in.searchMatch)
+	}
+	in.UpdateHistorySearch(tea.KeyMsg{Type: tea.KeyEnter})
+	if in.IsSearchingHistory() || in.Value() != "0103 0000" {
+		t.Errorf("after enter: searching = %v, value = %q", in.IsSearchingHistory(), in.Value())
+	}
+
+	in.StartHistorySearch()
+	in.UpdateHistorySearch(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("ff")})
+	if in.searchMatch != -1 {
+		t.Errorf("match for a missing query = %d, want -1", in.searchMatch)
+	}
+	in.UpdateHistorySearch(tea.KeyMsg{Type: tea.KeyEsc})
+	if in.IsSearchingHistory() || in.Value() != "0103 0000" {
+		t.Errorf("after esc: searching = %v, value = %q", in.IsSearchingHistory(), in.Value())
+	}
+}
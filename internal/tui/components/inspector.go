@@ -0,0 +1,147 @@
+package components
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/allbin/go-serial/internal/tui/colors"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// inspectorDumpRows is the number of hexdump lines the inspector shows; a
+// longer message scrolls to keep the cursor byte in view
+const inspectorDumpRows = 6
+
+// InspectorHeight is the number of lines Inspector.View renders
+const InspectorHeight = 1 + inspectorDumpRows + 3
+
+// Inspector details the bytes of one message: an offset-addressed hexdump
+// with a byte cursor, the cursor byte in decimal and binary, and the
+// integers and floats that start at the cursor
+type Inspector struct {
+	msg     DataReceivedMsg
+	cursor  int // Offset of the byte under the cursor
+	width   int
+	visible bool
+}
+
+func NewInspector() *Inspector {
+	return &Inspector{width: 80}
+}
+
+// SetMessage inspects msg; the cursor returns to the first byte when msg is
+// another message than before
+func (in *Inspector) SetMessage(msg DataReceivedMsg) {
+	if !msg.Timestamp.Equal(in.msg.Timestamp) || msg.Sequence != in.msg.Sequence || len(msg.Data) != len(in.msg.Data) {
+		in.cursor = 0
+	}
+	in.msg = msg
+}
+
+// Move moves the byte cursor by delta bytes, stopping at either end
+func (in *Inspector) Move(delta int) {
+	in.cursor = min(max(in.cursor+delta, 0), max(len(in.msg.Data)-1, 0))
+}
+
+func (in *Inspector) SetWidth(width int) {
+	in.width = width
+}
+
+// SetVisible shows or hides the inspector
+func (in *Inspector) SetVisible(visible bool) {
+	in.visible = visible
+}
+
+// IsVisible reports whether the inspector is shown
+func (in *Inspector) IsVisible() bool {
+	return in.visible
+}
+
+// Height returns the number of lines View renders: zero while hidden
+func (in *Inspector) Height() int {
+	if !in.visible {
+		return 0
+	}
+	return InspectorHeight
+}
+
+func (in *Inspector) View() string {
+	if !in.visible {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().Foreground(colors.Mauve).Bold(true)
+	infoStyle := lipgloss.NewStyle().Foreground(colors.Subtext0)
+	offsetStyle := lipgloss.NewStyle().Foreground(colors.Overlay0)
+	cursorStyle := lipgloss.NewStyle().Reverse(true).Bold(true)
+	labelStyle := lipgloss.NewStyle().Foreground(colors.Sky)
+
+	data := in.msg.Data
+	dir := "RX"
+	if in.msg.IsTX {
+		dir = "TX"
+	}
+	lines := []string{titleStyle.Render("Inspector") + infoStyle.Render(fmt.Sprintf("  %s %s  %d bytes  offset %d (0x%04X)  ←/→ move",
+		dir, in.msg.Timestamp.Format("15:04:05.000"), len(data), in.cursor, in.cursor))}
+
+	// Hexdump: 16 bytes per line, or 8 on narrow terminals
+	perLine := 16
+	if in.width < 80 {
+		perLine = 8
+	}
+	first := 0
+	if row := in.cursor / perLine; row >= inspectorDumpRows {
+		first = row - inspectorDumpRows + 1
+	}
+	for r := first; r < first+inspectorDumpRows; r++ {
+		start := r * perLine
+		if start >= len(data) {
+			lines = append(lines, "")
+			continue
+		}
+		var hex, ascii strings.Builder
+		for i := start; i < start+perLine; i++ {
+			if i >= len(data) {
+				hex.WriteString("   ")
+				continue
+			}
+			b, c := fmt.Sprintf("%02X", data[i]), printable(data[i:i+1])
+			if i == in.cursor {
+				b, c = cursorStyle.Render(b), cursorStyle.Render(c)
+			}
+			hex.WriteString(b + " ")
+			ascii.WriteString(c)
+		}
+		lines = append(lines, offsetStyle.Render(fmt.Sprintf("%04X  ", start))+hex.String()+" "+ascii.String())
+	}
+
+	if len(data) == 0 {
+		return strings.Join(append(lines, "", "", ""), "\n")
+	}
+
+	b := data[in.cursor]
+	rest := data[in.cursor:]
+	lines = append(lines,
+		labelStyle.Render("byte        ")+fmt.Sprintf("0x%02X  dec %d  int8 %d  bin %08b", b, b, int8(b), b),
+		labelStyle.Render("u16/s16     ")+decodeAt(rest, 2, func(b []byte, o binary.ByteOrder) string {
+			v := o.Uint16(b)
+			return fmt.Sprintf("%d / %d", v, int16(v))
+		}),
+		labelStyle.Render("u32/s32/f32 ")+decodeAt(rest, 4, func(b []byte, o binary.ByteOrder) string {
+			v := o.Uint32(b)
+			return fmt.Sprintf("%d / %d / %g", v, int32(v), math.Float32frombits(v))
+		}),
+	)
+	return strings.Join(lines, "\n")
+}
+
+// decodeAt renders the size bytes at the start of data both little and big
+// endian, or notes that the message ends too soon
+func decodeAt(data []byte, size int, decode func([]byte, binary.ByteOrder) string) string {
+	if len(data) < size {
+		return fmt.Sprintf("needs %d bytes from the cursor", size)
+	}
+	return fmt.Sprintf("le %s   be %s", decode(data[:size], binary.LittleEndian), decode(data[:size], binary.BigEndian))
+}
@@ -0,0 +1,84 @@
+package components
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInspectorCursor(t *testing.T) {
+	in := NewInspector()
+	msg := DataReceivedMsg{Data: []byte{1, 2, 3, 4}, Timestamp: time.Now(), Sequence: 1}
+	in.SetMessage(msg)
+
+	for _, tt := range []struct{ delta, want int }{{2, 2}, {10, 3}, {-1, 2}, {-10, 0}} {
+		in.Move(tt.delta)
+		if in.cursor != tt.want {
+			t.Errorf("Move(%d): cursor = %d, want %d", tt.delta, in.cursor, tt.want)
+		}
+	}
+
+	// The same message keeps the cursor, another one resets it
+	in.Move(2)
+	in.SetMessage(msg)
+	if in.cursor != 2 {
+		t.Errorf("cursor after setting the same message = %d, want 2", in.cursor)
+	}
+	msg.Sequence++
+	in.SetMessage(msg)
+	if in.cursor != 0 {
+		t.Errorf("cursor after another message = %d, want 0", in.cursor)
+	}
+
+	in.SetMessage(DataReceivedMsg{})
+	in.Move(1)
+	if in.cursor != 0 {
+		t.Errorf("cursor in an empty message = %d, want 0", in.cursor)
+	}
+}
+
+func TestInspectorView(t *testing.T) {
+	in := NewInspector()
+	if in.View() != "" || in.Height() != 0 {
+		t.Error("hidden inspector renders")
+	}
+	in.SetVisible(true)
+
+	data := make([]byte, 200)
+	data[160], data[161], data[162], data[163] = 0xFF, 0xFE, 0x00, 0x00
+	in.SetMessage(DataReceivedMsg{Data: data, IsTX: true})
+	in.Move(160)
+
+	view := in.View()
+	lines := strings.Split(view, "\n")
+	if len(lines) != in.Height() {
+		t.Fatalf("rendered %d lines, want %d", len(lines), in.Height())
+	}
+	if !strings.Contains(lines[0], "TX") || !strings.Contains(lines[0], "200 bytes  offset 160 (0x00A0)") {
+		t.Errorf("header = %q", lines[0])
+	}
+	// Row 10 holds the cursor, so the dump scrolls to end with it
+	if !strings.HasPrefix(lines[1], "0050  ") || !strings.HasPrefix(lines[6], "00A0  ") {
+		t.Errorf("dump rows start %q and %q, want 0050 and 00A0", lines[1][:6], lines[6][:6])
+	}
+	for _, want := range []string{
+		"0xFF  dec 255  int8 -1  bin 11111111",
+		"le 65279 / -257   be 65534 / -2",
+		"le 65279 / 65279 / ",
+	} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view lacks %q:\n%s", want, view)
+		}
+	}
+
+	// Decoding stops where the message ends
+	in.Move(100)
+	if view := in.View(); !strings.Contains(view, "needs 2 bytes from the cursor") || !strings.Contains(view, "needs 4 bytes from the cursor") {
+		t.Errorf("view at the last byte:\n%s", view)
+	}
+
+	in.SetMessage(DataReceivedMsg{})
+	if lines := strings.Split(in.View(), "\n"); len(lines) != InspectorHeight {
+		t.Errorf("empty message rendered %d lines, want %d", len(lines), InspectorHeight)
+	}
+}
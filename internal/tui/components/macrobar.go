@@ -0,0 +1,97 @@
+package components
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/allbin/go-serial/internal/tui/colors"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Macro is a canned payload bound to a function key
+type Macro struct {
+	Key     string        // Key as reported by tea.KeyMsg, e.g. "f1"
+	Name    string        // Label shown in the macro bar
+	Data    []byte        // Payload sent as-is
+	IsHex   bool          // Payload was given as hex
+	Repeat  time.Duration // Resend interval; zero sends once
+	running bool
+}
+
+// MacroBar shows the loaded macros and which repeating macros are running
+type MacroBar struct {
+	macros []*Macro
+	width  int
+}
+
+func NewMacroBar(macros []*Macro) *MacroBar {
+	return &MacroBar{macros: macros}
+}
+
+func (mb *MacroBar) SetWidth(width int) {
+	mb.width = width
+}
+
+// Height returns the number of lines View renders: one if any macros are loaded
+func (mb *MacroBar) Height() int {
+	if len(mb.macros) == 0 {
+		return 0
+	}
+	return 1
+}
+
+// Lookup returns the macro bound to key, or nil
+func (mb *MacroBar) Lookup(key string) *Macro {
+	for _, m := range mb.macros {
+		if m.Key == key {
+			return m
+		}
+	}
+	return nil
+}
+
+// IsRunning reports whether a repeating macro is currently active
+func (m *Macro) IsRunning() bool {
+	return m.running
+}
+
+// SetRunning starts or stops a repeating macro's indicator
+func (m *Macro) SetRunning(running bool) {
+	m.running = running
+}
+
+func (mb *MacroBar) View() string {
+	if len(mb.macros) == 0 {
+		return ""
+	}
+
+	keyStyle := lipgloss.NewStyle().
+		Foreground(colors.Base).
+		Background(colors.Lavender).
+		Bold(true).
+		Padding(0, 1)
+	runningKeyStyle := keyStyle.
+		Background(colors.Green)
+	nameStyle := lipgloss.NewStyle().
+		Foreground(colors.Subtext0).
+		Padding(0, 1)
+
+	var items []string
+	for _, m := range mb.macros {
+		style := keyStyle
+		if m.running {
+			style = runningKeyStyle
+		}
+		name := m.Name
+		if m.Repeat > 0 {
+			name = fmt.Sprintf("%s ⟳%v", name, m.Repeat)
+		}
+		items = append(items, style.Render(m.Key), nameStyle.Render(name))
+	}
+
+	bar := lipgloss.JoinHorizontal(lipgloss.Left, items...)
+	if mb.width > 0 {
+		bar = lipgloss.NewStyle().MaxWidth(mb.width).Render(bar)
+	}
+	return bar
+}
@@ -0,0 +1,34 @@
+package components
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMacroBar(t *testing.T) {
+	if mb := NewMacroBar(nil); mb.Height() != 0 || mb.View() != "" {
+		t.Error("macro bar without macros renders")
+	}
+
+	ping := &Macro{Key: "f1", Name: "Ping"}
+	status := &Macro{Key: "f2", Name: "Status", Repeat: time.Second}
+	mb := NewMacroBar([]*Macro{ping, status})
+	if mb.Height() != 1 {
+		t.Errorf("Height = %d, want 1", mb.Height())
+	}
+	if mb.Lookup("f2") != status || mb.Lookup("f3") != nil {
+		t.Error("Lookup returned the wrong macro")
+	}
+
+	status.SetRunning(true)
+	if !status.IsRunning() || ping.IsRunning() {
+		t.Error("SetRunning did not mark only the started macro")
+	}
+	view := mb.View()
+	for _, want := range []string{"f1", "Ping", "f2", "Status ⟳1s"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view %q lacks %q", view, want)
+		}
+	}
+}
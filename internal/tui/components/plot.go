@@ -0,0 +1,137 @@
+package components
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/allbin/go-serial/internal/tui/colors"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// plotCapacity bounds the samples a Plot keeps; only the newest that fit
+// the width are drawn
+const plotCapacity = 4096
+
+// plotLevels are the partial cells of a column, in eighths
+var plotLevels = []rune(" ▁▂▃▄▅▆▇█")
+
+// Plot is a scrolling bar graph of numeric samples, newest on the right
+// The vertical scale follows the minimum and maximum of the samples shown.
+type Plot struct {
+	title   string
+	values  []float64
+	total   int // Samples added, including those no longer kept
+	width   int
+	height  int // Lines rendered, including the title line
+	visible bool
+}
+
+func NewPlot(title string, height int) *Plot {
+	return &Plot{title: title, height: height, width: 80}
+}
+
+// Add appends a sample
+func (p *Plot) Add(v float64) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return
+	}
+	p.values = append(p.values, v)
+	p.total++
+	if over := len(p.values) - plotCapacity; over > 0 {
+		p.values = append(p.values[:0], p.values[over:]...)
+	}
+}
+
+// Clear removes all samples
+func (p *Plot) Clear() {
+	p.values = p.values[:0]
+	p.total = 0
+}
+
+func (p *Plot) SetWidth(width int) {
+	p.width = width
+}
+
+// SetVisible shows or hides the plot
+func (p *Plot) SetVisible(visible bool) {
+	p.visible = visible
+}
+
+// IsVisible reports whether the plot is shown
+func (p *Plot) IsVisible() bool {
+	return p.visible
+}
+
+// Height returns the number of lines View renders: zero while hidden
+func (p *Plot) Height() int {
+	if !p.visible {
+		return 0
+	}
+	return p.height
+}
+
+func (p *Plot) View() string {
+	if !p.visible {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().Foreground(colors.Mauve).Bold(true)
+	infoStyle := lipgloss.NewStyle().Foreground(colors.Subtext0)
+	axisStyle := lipgloss.NewStyle().Foreground(colors.Overlay0)
+	barStyle := lipgloss.NewStyle().Foreground(colors.Teal)
+
+	rows := max(p.height-1, 1)
+	const axisWidth = 10 // Scale labels and the axis line
+	cols := max(p.width-axisWidth-1, 1)
+	shown := p.values[max(len(p.values)-cols, 0):]
+
+	if len(shown) == 0 {
+		header := titleStyle.Render("▁▃▅ "+p.title) + infoStyle.Render("  waiting for samples")
+		return header + strings.Repeat("\n", rows)
+	}
+
+	lo, hi := shown[0], shown[0]
+	for _, v := range shown {
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+	last := shown[len(shown)-1]
+	header := titleStyle.Render("▁▃▅ "+p.title) +
+		infoStyle.Render(fmt.Sprintf("  last %s  min %s  max %s  %d samples",
+			formatSample(last), formatSample(lo), formatSample(hi), p.total))
+
+	// Height of each column in eighths of a cell
+	levels := make([]int, len(shown))
+	for i, v := range shown {
+		if hi == lo {
+			levels[i] = rows * 4
+			continue
+		}
+		levels[i] = int(math.Round((v - lo) / (hi - lo) * float64(rows*8)))
+		levels[i] = max(levels[i], 1) // Keep the minimum visible
+	}
+
+	lines := []string{header}
+	for r := 0; r < rows; r++ {
+		label := ""
+		switch r {
+		case 0:
+			label = formatSample(hi)
+		case rows - 1:
+			label = formatSample(lo)
+		}
+		var bars strings.Builder
+		floor := (rows - 1 - r) * 8
+		for _, level := range levels {
+			bars.WriteRune(plotLevels[min(max(level-floor, 0), 8)])
+		}
+		lines = append(lines, axisStyle.Render(fmt.Sprintf("%*s │", axisWidth-2, label))+barStyle.Render(bars.String()))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatSample renders a sample compactly, e.g. 23.4, 1200 or 1.5e+06
+func formatSample(v float64) string {
+	return fmt.Sprintf("%.4g", v)
+}
@@ -0,0 +1,67 @@
+package components
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestPlotAdd(t *testing.T) {
+	p := NewPlot("temp", 4)
+	p.Add(math.NaN())
+	p.Add(math.Inf(1))
+	if p.total != 0 {
+		t.Errorf("total after NaN and Inf = %d, want 0", p.total)
+	}
+
+	for i := 0; i < plotCapacity+10; i++ {
+		p.Add(float64(i))
+	}
+	if len(p.values) != plotCapacity || p.values[0] != 10 {
+		t.Errorf("kept %d samples from %v, want %d from 10", len(p.values), p.values[0], plotCapacity)
+	}
+	if p.total != plotCapacity+10 {
+		t.Errorf("total = %d, want %d", p.total, plotCapacity+10)
+	}
+
+	p.Clear()
+	if len(p.values) != 0 || p.total != 0 {
+		t.Errorf("Clear left %d samples, total %d", len(p.values), p.total)
+	}
+}
+
+func TestPlotView(t *testing.T) {
+	p := NewPlot("temp", 4)
+	p.SetWidth(20)
+	if p.View() != "" || p.Height() != 0 {
+		t.Error("hidden plot renders")
+	}
+
+	p.SetVisible(true)
+	if got := p.View(); strings.Count(got, "\n") != 3 || !strings.Contains(got, "waiting for samples") {
+		t.Errorf("empty plot = %q, want a waiting header over 3 lines", got)
+	}
+
+	for _, v := range []float64{1, 2, 3} {
+		p.Add(v)
+	}
+	lines := strings.Split(p.View(), "\n")
+	if len(lines) != p.Height() {
+		t.Fatalf("rendered %d lines, want %d", len(lines), p.Height())
+	}
+	if !strings.Contains(lines[0], "last 3  min 1  max 3  3 samples") {
+		t.Errorf("header = %q", lines[0])
+	}
+	// The maximum fills the top row, the minimum keeps a sliver on the bottom
+	if !strings.HasSuffix(lines[1], "█") || !strings.HasSuffix(lines[3], "▁██") {
+		t.Errorf("bars = %q", lines[1:])
+	}
+}
+
+func TestFormatSample(t *testing.T) {
+	for v, want := range map[float64]string{23.4: "23.4", 1200: "1200", 1.5e6: "1.5e+06", -0.125: "-0.125"} {
+		if got := formatSample(v); got != want {
+			t.Errorf("formatSample(%v) = %q, want %q", v, got, want)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package components
+
+import (
+	"github.com/allbin/go-serial/internal/tui/colors"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Prompt asks for one line of text, such as a file name
+type Prompt struct {
+	input  textinput.Model
+	typing bool // Text is being edited
+}
+
+func NewPrompt(prompt, placeholder string) *Prompt {
+	ti := textinput.New()
+	ti.Prompt = prompt
+	ti.Placeholder = placeholder
+	ti.CharLimit = 256
+	return &Prompt{input: ti}
+}
+
+// Start begins editing, starting from value
+func (p *Prompt) Start(value string) {
+	p.typing = true
+	p.input.SetValue(value)
+	p.input.CursorEnd()
+	p.input.Focus()
+}
+
+// IsTyping reports whether the text is being edited
+func (p *Prompt) IsTyping() bool {
+	return p.typing
+}
+
+// Update handles keys while typing; it returns done when editing ended and
+// ok with the text if it was entered with enter rather than abandoned with
+// esc
+func (p *Prompt) Update(msg tea.KeyMsg) (value string, done, ok bool, cmd tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		p.typing = false
+		p.input.Blur()
+		return p.input.Value(), true, p.input.Value() != "", nil
+	case tea.KeyEsc:
+		p.typing = false
+		p.input.Blur()
+		return "", true, false, nil
+	}
+	p.input, cmd = p.input.Update(msg)
+	return "", false, false, cmd
+}
+
+// View renders the text being typed
+func (p *Prompt) View() string {
+	return lipgloss.NewStyle().Foreground(colors.Lavender).Render(p.input.View())
+}
@@ -0,0 +1,166 @@
+package components
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/allbin/go-serial/internal/tui/colors"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Search is a vim-style "/" search over terminal scrollback
+// A query matches data whose ASCII rendering contains it (ignoring case) or,
+// if the query is valid hex such as "15" or "10 02", data containing those
+// bytes.
+type Search struct {
+	input   textinput.Model
+	typing  bool   // Query is being edited
+	query   string // Committed query; empty when no search is active
+	pattern []byte // Query as bytes if it parses as hex
+	current int    // Index of the current match, -1 if none
+	total   int    // Number of matches
+}
+
+func NewSearch() *Search {
+	ti := textinput.New()
+	ti.Prompt = "/"
+	ti.Placeholder = "text or hex bytes"
+	ti.CharLimit = 128
+	return &Search{input: ti, current: -1}
+}
+
+// Start begins editing a new query
+func (s *Search) Start() {
+	s.typing = true
+	s.input.SetValue("")
+	s.input.Focus()
+}
+
+// IsTyping reports whether the query is being edited
+func (s *Search) IsTyping() bool {
+	return s.typing
+}
+
+// IsActive reports whether a committed query is highlighting matches
+func (s *Search) IsActive() bool {
+	return s.query != ""
+}
+
+// Update handles keys while typing; it returns true when the query was
+// committed with enter or cleared with esc
+func (s *Search) Update(msg tea.KeyMsg) (bool, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		s.typing = false
+		s.input.Blur()
+		s.setQuery(s.input.Value())
+		return true, nil
+	case tea.KeyEsc:
+		s.typing = false
+		s.input.Blur()
+		s.setQuery("")
+		return true, nil
+	}
+	var cmd tea.Cmd
+	s.input, cmd = s.input.Update(msg)
+	return false, cmd
+}
+
+// Clear removes the committed query
+func (s *Search) Clear() {
+	s.setQuery("")
+}
+
+func (s *Search) setQuery(query string) {
+	s.query = strings.TrimSpace(query)
+	s.pattern = nil
+	s.current = -1
+	s.total = 0
+	if b, err := hex.DecodeString(strings.ReplaceAll(s.query, " ", "")); err == nil && len(b) > 0 {
+		s.pattern = b
+	}
+}
+
+// Matches reports whether data contains the query
+func (s *Search) Matches(data []byte) bool {
+	if s.query == "" {
+		return false
+	}
+	if s.pattern != nil && bytes.Contains(data, s.pattern) {
+		return true
+	}
+	return s.MatchesText(printable(data))
+}
+
+// MatchesText reports whether an already rendered line contains the query,
+// as text or as space-separated hex
+func (s *Search) MatchesText(line string) bool {
+	if s.query == "" {
+		return false
+	}
+	line = strings.ToLower(line)
+	if strings.Contains(line, strings.ToLower(s.query)) {
+		return true
+	}
+	return s.pattern != nil && strings.Contains(line, strings.ToLower(fmt.Sprintf("% X", s.pattern)))
+}
+
+// SetPosition records the current match and the number of matches for View
+func (s *Search) SetPosition(current, total int) {
+	s.current = current
+	s.total = total
+}
+
+// stepMatch moves pos through n matches in the given direction, wrapping
+// around; from no match (-1) it starts at the first or last match
+func stepMatch(pos, n int, forward bool) int {
+	if n == 0 {
+		return -1
+	}
+	if pos < 0 {
+		if forward {
+			return 0
+		}
+		return n - 1
+	}
+	if forward {
+		return (pos + 1) % n
+	}
+	return (pos - 1 + n) % n
+}
+
+// Status summarizes the committed search, e.g. "/NAK 3/17"
+func (s *Search) Status() string {
+	if s.query == "" {
+		return ""
+	}
+	if s.total == 0 {
+		return fmt.Sprintf("/%s no matches", s.query)
+	}
+	if s.current < 0 {
+		return fmt.Sprintf("/%s %d matches", s.query, s.total)
+	}
+	return fmt.Sprintf("/%s %d/%d", s.query, s.current+1, s.total)
+}
+
+// View renders the query being typed
+func (s *Search) View() string {
+	return lipgloss.NewStyle().Foreground(colors.Yellow).Render(s.input.View())
+}
+
+// printable replaces non-printable bytes with '.'
+func printable(data []byte) string {
+	var b strings.Builder
+	for _, c := range data {
+		if c >= 32 && c <= 126 {
+			b.WriteByte(c)
+		} else {
+			b.WriteByte('.')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,93 @@
+package components
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSearchMatches(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		data  string
+		want  bool
+	}{
+		{"no query", "", "NAK", false},
+		{"text", "nak", "got NAK\r\n", true},
+		{"text miss", "ack", "got NAK", false},
+		{"hex bytes", "10 02", "\x00\x10\x02\x03", true},
+		{"hex bytes miss", "10 02", "\x10\x03", false},
+		{"hex-looking text", "beef", "dead beef", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSearch()
+			s.setQuery(tt.query)
+			if got := s.Matches([]byte(tt.data)); got != tt.want {
+				t.Errorf("Matches(%q) for %q = %v, want %v", tt.data, tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchMatchesText(t *testing.T) {
+	s := NewSearch()
+	s.setQuery("1002")
+	if !s.MatchesText("RX 00 10 02 03") {
+		t.Error("hex query does not match a rendered hex line")
+	}
+	if s.MatchesText("RX 10 03 02") {
+		t.Error("hex query matches a line without its bytes")
+	}
+}
+
+func TestStepMatch(t *testing.T) {
+	tests := []struct {
+		pos, n  int
+		forward bool
+		want    int
+	}{
+		{-1, 0, true, -1},
+		{-1, 5, true, 0},
+		{-1, 5, false, 4},
+		{3, 5, true, 4},
+		{4, 5, true, 0},
+		{0, 5, false, 4},
+		{2, 5, false, 1},
+	}
+	for _, tt := range tests {
+		if got := stepMatch(tt.pos, tt.n, tt.forward); got != tt.want {
+			t.Errorf("stepMatch(%d, %d, %v) = %d, want %d", tt.pos, tt.n, tt.forward, got, tt.want)
+		}
+	}
+}
+
+func TestSearchStatus(t *testing.T) {
+	s := NewSearch()
+	s.Start()
+	s.input.SetValue(" NAK ")
+	if done, _ := s.Update(tea.KeyMsg{Type: tea.KeyEnter}); !done || s.IsTyping() || !s.IsActive() {
+		t.Fatalf("enter: done = %v, typing = %v, active = %v", done, s.IsTyping(), s.IsActive())
+	}
+
+	for _, tt := range []struct {
+		current, total int
+		want           string
+	}{
+		{-1, 0, "/NAK no matches"},
+		{-1, 17, "/NAK 17 matches"},
+		{2, 17, "/NAK 3/17"},
+	} {
+		s.SetPosition(tt.current, tt.total)
+		if got := s.Status(); got != tt.want {
+			t.Errorf("Status at %d/%d = %q, want %q", tt.current, tt.total, got, tt.want)
+		}
+	}
+
+	s.Start()
+	if done, _ := s.Update(tea.KeyMsg{Type: tea.KeyEsc}); !done || s.IsActive() || s.Status() != "" {
+		t.Errorf("esc: done = %v, active = %v, status = %q", done, s.IsActive(), s.Status())
+	}
+}
@@ -2,6 +2,7 @@ package components
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/allbin/go-serial"
 	"github.com/allbin/go-serial/internal/tui/colors"
@@ -25,6 +26,18 @@ type StatusBar struct {
 	err            error
 	width          int
 	connectionInfo *ConnectionInfo
+	logging        bool                 // Terminal contents are being logged to a file
+	search         string               // Search summary, empty when not searching
+	breaking       bool                 // A break is being transmitted
+	signals        *serial.ModemSignals // Live modem signals, nil if unknown
+	dropped        int                  // Messages evicted from the scrollback
+	filter         string               // Filter summary, empty when not filtering
+	triggers       string               // Trigger summary, empty when there are none
+	lost           string               // Why the port was lost, empty while connected
+	timeMode       string               // Relative time mode summary, empty for time of day
+	tabs           []string             // Tab names, nil with a single tab
+	tab            int                  // Index of the tab this bar belongs to
+	counters       string               // Traffic counters summary, empty when not counting
 }
 
 func NewStatusBar(title, portPath string) *StatusBar {
@@ -48,6 +61,62 @@ func (sb *StatusBar) SetConnectionInfo(info *ConnectionInfo) {
 	sb.connectionInfo = info
 }
 
+// SetLogging shows or hides the log-to-file indicator
+func (sb *StatusBar) SetLogging(logging bool) {
+	sb.logging = logging
+}
+
+// SetSearch shows a search summary such as "/NAK 3/17"; empty hides it
+func (sb *StatusBar) SetSearch(summary string) {
+	sb.search = summary
+}
+
+// SetBreaking shows or hides the break-in-progress indicator
+func (sb *StatusBar) SetBreaking(breaking bool) {
+	sb.breaking = breaking
+}
+
+// SetSignals shows the modem signal states
+func (sb *StatusBar) SetSignals(signals serial.ModemSignals) {
+	sb.signals = &signals
+}
+
+// SetDropped shows how many messages the scrollback limit has evicted;
+// zero hides the indicator
+func (sb *StatusBar) SetDropped(dropped int) {
+	sb.dropped = dropped
+}
+
+// SetFilter shows a filter summary such as "filter !^HB (812 hidden)";
+// empty hides it
+func (sb *StatusBar) SetFilter(summary string) {
+	sb.filter = summary
+}
+
+// SetTriggers shows a trigger summary such as "⚡ 2/3 triggers"; empty
+// hides it
+func (sb *StatusBar) SetTriggers(summary string) {
+	sb.triggers = summary
+}
+
+// SetTimeMode shows a summary of the relative time mode, such as "Δ prev";
+// empty hides it
+func (sb *StatusBar) SetTimeMode(summary string) {
+	sb.timeMode = summary
+}
+
+// SetCounters shows a traffic counters summary such as "RX 1.2 MiB 8412
+// lines 960 B/s"; empty hides it
+func (sb *StatusBar) SetCounters(summary string) {
+	sb.counters = summary
+}
+
+// SetTabs shows a tab bar with names, current highlighted; nil hides it
+func (sb *StatusBar) SetTabs(names []string, current int) {
+	sb.tabs = names
+	sb.tab = current
+}
+
 func (sb *StatusBar) SetConnecting() {
 	sb.status = "Connecting..."
 	sb.err = nil
@@ -56,6 +125,29 @@ func (sb *StatusBar) SetConnecting() {
 func (sb *StatusBar) SetConnected() {
 	sb.status = "Connected - listening for data..."
 	sb.err = nil
+	sb.lost = ""
+}
+
+// SetLost marks the port as lost, such as by unplugging the device; with
+// reconnecting it is being reopened
+func (sb *StatusBar) SetLost(err error, reconnecting bool) {
+	sb.err = nil
+	sb.lost = "PORT LOST"
+	if err != nil {
+		sb.lost += ": " + err.Error()
+	}
+	if reconnecting {
+		sb.status = "Reconnecting..."
+		sb.lost += ", reconnecting"
+	} else {
+		sb.status = "Disconnected"
+	}
+}
+
+// SetPortPath updates the port shown, such as after it was reopened under
+// another name
+func (sb *StatusBar) SetPortPath(portPath string) {
+	sb.portPath = portPath
 }
 
 func (sb *StatusBar) SetDisconnected(err error) {
@@ -137,6 +229,13 @@ func (sb *StatusBar) ComprehensiveStatusBar(inputMode, sendingMode, viewMode str
 			Bold(true).
 			Padding(0, 1)
 		modeText = "INSERT"
+	} else if inputMode == "RAW" {
+		modeStyle = lipgloss.NewStyle().
+			Foreground(colors.Base).
+			Background(colors.Red).
+			Bold(true).
+			Padding(0, 1)
+		modeText = "RAW"
 	} else {
 		// Show view mode for normal mode
 		if viewMode == "VISUAL" {
@@ -174,7 +273,7 @@ func (sb *StatusBar) ComprehensiveStatusBar(inputMode, sendingMode, viewMode str
 	} else if connected {
 		connStyle = lipgloss.NewStyle().Foreground(colors.Green)
 		connIndicator = "●"
-	} else if sb.status == "Connecting..." {
+	} else if sb.status == "Connecting..." || sb.status == "Reconnecting..." {
 		connStyle = lipgloss.NewStyle().Foreground(colors.Yellow)
 		connIndicator = "○"
 	} else {
@@ -183,6 +282,63 @@ func (sb *StatusBar) ComprehensiveStatusBar(inputMode, sendingMode, viewMode str
 	}
 
 	connectionIndicator := connStyle.Render(connIndicator)
+	if sb.logging {
+		logStyle := lipgloss.NewStyle().
+			Foreground(colors.Red).
+			Bold(true).
+			PaddingLeft(1)
+		connectionIndicator = lipgloss.JoinHorizontal(lipgloss.Left, connectionIndicator, logStyle.Render("⏺ LOG"))
+	}
+	if sb.lost != "" {
+		lostStyle := lipgloss.NewStyle().
+			Foreground(colors.Red).
+			Bold(true).
+			PaddingLeft(1)
+		connectionIndicator = lipgloss.JoinHorizontal(lipgloss.Left, connectionIndicator, lostStyle.Render(sb.lost))
+	}
+	if sb.breaking {
+		breakStyle := lipgloss.NewStyle().
+			Foreground(colors.Peach).
+			Bold(true).
+			PaddingLeft(1)
+		connectionIndicator = lipgloss.JoinHorizontal(lipgloss.Left, connectionIndicator, breakStyle.Render("BREAK"))
+	}
+	if sb.dropped > 0 {
+		droppedStyle := lipgloss.NewStyle().
+			Foreground(colors.Peach).
+			PaddingLeft(1)
+		connectionIndicator = lipgloss.JoinHorizontal(lipgloss.Left, connectionIndicator, droppedStyle.Render(fmt.Sprintf("⚠ %d dropped", sb.dropped)))
+	}
+	if sb.counters != "" {
+		countersStyle := lipgloss.NewStyle().
+			Foreground(colors.Lavender).
+			PaddingLeft(1)
+		connectionIndicator = lipgloss.JoinHorizontal(lipgloss.Left, connectionIndicator, countersStyle.Render(sb.counters))
+	}
+	if sb.timeMode != "" {
+		timeStyle := lipgloss.NewStyle().
+			Foreground(colors.Sky).
+			PaddingLeft(1)
+		connectionIndicator = lipgloss.JoinHorizontal(lipgloss.Left, connectionIndicator, timeStyle.Render("⏱ "+sb.timeMode))
+	}
+	if sb.triggers != "" {
+		triggerStyle := lipgloss.NewStyle().
+			Foreground(colors.Mauve).
+			PaddingLeft(1)
+		connectionIndicator = lipgloss.JoinHorizontal(lipgloss.Left, connectionIndicator, triggerStyle.Render(sb.triggers))
+	}
+	if sb.filter != "" {
+		filterStyle := lipgloss.NewStyle().
+			Foreground(colors.Teal).
+			PaddingLeft(1)
+		connectionIndicator = lipgloss.JoinHorizontal(lipgloss.Left, connectionIndicator, filterStyle.Render(sb.filter))
+	}
+	if sb.search != "" {
+		searchStyle := lipgloss.NewStyle().
+			Foreground(colors.Yellow).
+			PaddingLeft(1)
+		connectionIndicator = lipgloss.JoinHorizontal(lipgloss.Left, connectionIndicator, searchStyle.Render(sb.search))
+	}
 
 	// Section 4: Connection info (like file type with icon)
 	var connInfo string
@@ -230,9 +386,15 @@ func (sb *StatusBar) ComprehensiveStatusBar(inputMode, sendingMode, viewMode str
 	} else {
 		leftSide = lipgloss.JoinHorizontal(lipgloss.Left, mode, port, connectionIndicator, divider)
 	}
+	if sb.tabs != nil {
+		leftSide = lipgloss.JoinHorizontal(lipgloss.Left, sb.tabsView(), leftSide)
+	}
 
 	// Build right side with divider
 	rightSide := lipgloss.JoinHorizontal(lipgloss.Left, connectionDetails, divider, time)
+	if sb.signals != nil {
+		rightSide = lipgloss.JoinHorizontal(lipgloss.Left, sb.signalsView(), divider, rightSide)
+	}
 
 	// Calculate spacer and handle width overflow
 	leftWidth := lipgloss.Width(leftSide)
@@ -261,6 +423,46 @@ func (sb *StatusBar) ComprehensiveStatusBar(inputMode, sendingMode, viewMode str
 	return statusBarStyle.Render(content)
 }
 
+// tabsView lists the tabs by number and name, the current one highlighted
+func (sb *StatusBar) tabsView() string {
+	tabStyle := lipgloss.NewStyle().
+		Foreground(colors.Subtext0).
+		Background(colors.Surface1).
+		Padding(0, 1)
+	currentStyle := tabStyle.
+		Foreground(colors.Base).
+		Background(colors.Mauve).
+		Bold(true)
+
+	tabs := make([]string, len(sb.tabs))
+	for i, name := range sb.tabs {
+		style := tabStyle
+		if i == sb.tab {
+			style = currentStyle
+		}
+		tabs[i] = style.Render(fmt.Sprintf("%d %s", i+1, name))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Left, tabs...)
+}
+
+// signalsView lights asserted modem signals: the RTS and DTR outputs, then
+// the CTS, DSR, DCD and RI inputs
+func (sb *StatusBar) signalsView() string {
+	onStyle := lipgloss.NewStyle().Foreground(colors.Green).Bold(true)
+	offStyle := lipgloss.NewStyle().Foreground(colors.Overlay0)
+	signal := func(name string, on bool) string {
+		if on {
+			return onStyle.Render(name)
+		}
+		return offStyle.Render(name)
+	}
+
+	s := sb.signals
+	outputs := strings.Join([]string{signal("RTS", s.RTS), signal("DTR", s.DTR)}, " ")
+	inputs := strings.Join([]string{signal("CTS", s.CTS), signal("DSR", s.DSR), signal("DCD", s.DCD), signal("RI", s.RI)}, " ")
+	return lipgloss.NewStyle().Padding(0, 1).Render(outputs + offStyle.Render(" › ") + inputs)
+}
+
 // compactStatusBar creates a minimal status bar for narrow terminals
 func (sb *StatusBar) compactStatusBar(inputMode, viewMode string, connected bool, timestamp string, terminalWidth int) string {
 	// Mode indicator
@@ -273,6 +475,13 @@ func (sb *StatusBar) compactStatusBar(inputMode, viewMode string, connected bool
 			Bold(true).
 			Padding(0, 1)
 		modeText = "INSERT"
+	} else if inputMode == "RAW" {
+		modeStyle = lipgloss.NewStyle().
+			Foreground(colors.Base).
+			Background(colors.Red).
+			Bold(true).
+			Padding(0, 1)
+		modeText = "RAW"
 	} else {
 		if viewMode == "VISUAL" {
 			modeStyle = lipgloss.NewStyle().
@@ -318,6 +527,14 @@ func (sb *StatusBar) compactStatusBar(inputMode, viewMode string, connected bool
 
 	// Build minimal status bar
 	content := lipgloss.JoinHorizontal(lipgloss.Left, mode, port, connection)
+	if sb.tabs != nil {
+		tabStyle := lipgloss.NewStyle().
+			Foreground(colors.Base).
+			Background(colors.Mauve).
+			Bold(true).
+			Padding(0, 1)
+		content = lipgloss.JoinHorizontal(lipgloss.Left, tabStyle.Render(fmt.Sprintf("%d/%d", sb.tab+1, len(sb.tabs))), content)
+	}
 
 	// Apply background and ensure it fills the width
 	statusBarStyle := lipgloss.NewStyle().
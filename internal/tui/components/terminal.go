@@ -2,15 +2,27 @@ package components
 
 import (
 	"strings"
+	"time"
 
+	"github.com/allbin/go-serial/internal/tui/colors"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 )
 
 type Terminal struct {
 	viewport  viewport.Model
 	formatter *DataFormatter
 	data      []string
+	search    *Search // Nil or inactive when not searching
+	matches   []int   // Indices into data of lines matching search
+	matchPos  int     // Index into matches of the current match, -1 if none
+	following bool    // Keep the newest line in view
+
+	content strings.Builder // data joined by newlines, up to line joined
+	joined  int
+	dirty   bool // Lines were appended since the last render
 }
 
 func NewTerminal(width, height int) *Terminal {
@@ -19,6 +31,8 @@ func NewTerminal(width, height int) *Terminal {
 		viewport:  vp,
 		formatter: NewDataFormatter(true, true), // Default: show both hex and ASCII
 		data:      make([]string, 0),
+		matchPos:  -1,
+		following: true,
 	}
 }
 
@@ -32,6 +46,13 @@ func (t *Terminal) GetViewport() viewport.Model {
 }
 
 func (t *Terminal) AddMessage(msg DataReceivedMsg) {
+	t.AppendMessage(msg)
+	t.Flush()
+}
+
+// AppendMessage adds the lines of msg without rendering them; call Flush
+// after a batch of messages, so fast data is rendered once per batch
+func (t *Terminal) AppendMessage(msg DataReceivedMsg) {
 	formattedLines := t.formatter.FormatMessage(msg)
 	if len(formattedLines) == 0 {
 		// No complete lines yet, still buffering
@@ -39,42 +60,126 @@ func (t *Terminal) AddMessage(msg DataReceivedMsg) {
 	}
 
 	t.data = append(t.data, formattedLines...)
+	t.dirty = true
+}
+
+// Flush renders the lines appended since the last render
+func (t *Terminal) Flush() {
+	if t.dirty {
+		t.render()
+	}
+}
 
-	// Set content and ensure viewport scrolls to show the latest message
-	content := strings.Join(t.data, "\n")
-	t.viewport.SetContent(content)
+// setData replaces all lines, such as after reformatting
+func (t *Terminal) setData(lines []string) {
+	t.data = lines
+	t.content.Reset()
+	t.joined = 0
+}
 
-	// Force viewport to bottom to show the latest message
-	// Even if content is shorter than viewport height
-	if len(t.data) > 0 {
+// render sets the viewport content, marking search matches, and scrolls to
+// the latest line unless a search match is being viewed
+func (t *Terminal) render() {
+	t.dirty = false
+	t.matches = t.matches[:0]
+	if t.search == nil || !t.search.IsActive() {
+		// Only lines added since the last render are joined
+		for ; t.joined < len(t.data); t.joined++ {
+			if t.joined > 0 {
+				t.content.WriteByte('\n')
+			}
+			t.content.WriteString(t.data[t.joined])
+		}
+		t.viewport.SetContent(t.content.String())
+	} else {
+		matchStyle := lipgloss.NewStyle().Foreground(colors.Yellow)
+		currentStyle := lipgloss.NewStyle().Foreground(colors.Base).Background(colors.Yellow)
+		lines := make([]string, len(t.data))
+		for i, line := range t.data {
+			gutter := "  "
+			if t.search.MatchesText(ansi.Strip(line)) {
+				t.matches = append(t.matches, i)
+				gutter = matchStyle.Render("▌ ")
+				if len(t.matches)-1 == t.matchPos {
+					gutter = currentStyle.Render("▶") + " "
+				}
+			}
+			lines[i] = gutter + line
+		}
+		t.viewport.SetContent(strings.Join(lines, "\n"))
+
+		if t.matchPos >= len(t.matches) {
+			t.matchPos = len(t.matches) - 1
+		}
+		t.search.SetPosition(t.matchPos, len(t.matches))
+	}
+
+	if t.following {
 		t.viewport.GotoBottom()
 	}
 }
 
+// SetSearch marks lines matching s and jumps to the most recent match
+func (t *Terminal) SetSearch(s *Search) {
+	t.search = s
+	t.matchPos = -1
+	t.following = true
+	t.render()
+	if s.IsActive() {
+		t.NextMatch(false)
+	}
+}
+
+// NextMatch scrolls to the next (or previous) matching line, wrapping around;
+// the view stays there until the search is cleared
+func (t *Terminal) NextMatch(forward bool) {
+	if t.search == nil || !t.search.IsActive() {
+		return
+	}
+	t.matchPos = stepMatch(t.matchPos, len(t.matches), forward)
+	if t.matchPos >= 0 {
+		t.following = false
+	}
+	t.render()
+	if t.matchPos >= 0 {
+		line := t.matches[t.matchPos] - t.viewport.Height/2
+		if line < 0 {
+			line = 0
+		}
+		t.viewport.SetYOffset(line)
+	}
+}
+
 func (t *Terminal) UpdateMessage(rawData []DataReceivedMsg) {
 	// Refresh the entire display with updated raw data
 	// This ensures proper ordering and formatting
-	t.data = t.formatter.FormatMessages(rawData)
-	t.viewport.SetContent(strings.Join(t.data, "\n"))
-	t.viewport.GotoBottom()
+	t.setData(t.formatter.FormatMessages(rawData))
+	t.render()
 }
 
 func (t *Terminal) AddFormattedMessage(msg string) {
 	t.data = append(t.data, msg)
-	t.viewport.SetContent(strings.Join(t.data, "\n"))
-	t.viewport.GotoBottom()
+	t.render()
 }
 
+// RefreshDisplayWithRawData reformats the display from rawData, such as
+// after a display mode change or scrollback eviction
 func (t *Terminal) RefreshDisplayWithRawData(rawData []DataReceivedMsg) {
-	t.data = t.formatter.FormatMessages(rawData)
-	t.viewport.SetContent(strings.Join(t.data, "\n"))
-	t.viewport.GotoBottom()
+	t.formatter.ClearBuffer()
+	t.formatter.ResetHidden()
+	t.formatter.ResetDelta()
+	t.setData(t.formatter.FormatMessages(rawData))
+	t.render()
 }
 
 func (t *Terminal) Clear() {
-	t.data = make([]string, 0)
-	t.viewport.SetContent("")
+	t.setData(make([]string, 0))
+	t.matchPos = -1
+	t.following = true
+	t.render()
 	t.formatter.ClearBuffer()
+	t.formatter.ResetHidden()
+	t.formatter.ResetDelta()
 }
 
 func (t *Terminal) ToggleHex() {
@@ -97,10 +202,53 @@ func (t *Terminal) SetFormatOptions(noTimestamps, noIndicators bool) {
 	t.formatter.SetFormatOptions(noTimestamps, noIndicators)
 }
 
+// SetFramed shows each RX message as a line, for messages already split
+// into frames
+func (t *Terminal) SetFramed(framed bool) {
+	t.formatter.SetFramed(framed)
+}
+
+// SetMicroseconds shows times of day to the microsecond
+func (t *Terminal) SetMicroseconds(micro bool) {
+	t.formatter.SetMicroseconds(micro)
+}
+
+// SetHighlights sets the rules that color matching lines
+func (t *Terminal) SetHighlights(rules []HighlightRule) {
+	t.formatter.SetHighlights(rules)
+}
+
+// SetFilter hides the lines f does not show; call RefreshDisplayWithRawData
+// to apply it to the lines already shown
+func (t *Terminal) SetFilter(f *Filter) {
+	t.formatter.SetFilter(f)
+}
+
+// Hidden returns the number of lines the filter has hidden
+func (t *Terminal) Hidden() int {
+	return t.formatter.Hidden()
+}
+
 func (t *Terminal) ToggleTimestamps() {
 	t.formatter.options.NoTimestamps = !t.formatter.options.NoTimestamps
 }
 
+// NextTimeMode cycles timestamps through time of day, time since the
+// previous line and time since the mark
+func (t *Terminal) NextTimeMode() TimeMode {
+	return t.formatter.NextTimeMode()
+}
+
+// SetMark shows timestamps relative to at
+func (t *Terminal) SetMark(at time.Time) {
+	t.formatter.SetMark(at)
+}
+
+// TimeStatus summarizes a relative time mode; see DataFormatter.TimeStatus
+func (t *Terminal) TimeStatus() string {
+	return t.formatter.TimeStatus()
+}
+
 func (t *Terminal) ToggleIndicators() {
 	t.formatter.options.NoIndicators = !t.formatter.options.NoIndicators
 }
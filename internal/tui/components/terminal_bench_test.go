@@ -0,0 +1,73 @@
+package components
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// benchChunks returns n chunks of the size a USB adapter returns at high
+// baud rates, each ending a line
+func benchChunks(n int) []DataReceivedMsg {
+	msgs := make([]DataReceivedMsg, n)
+	at := time.Now()
+	for i := range msgs {
+		msgs[i] = DataReceivedMsg{
+			Timestamp: at.Add(time.Duration(i) * time.Millisecond),
+			Data:      fmt.Appendf(nil, "$GPGGA,%06d,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*47\r\n", i),
+		}
+	}
+	return msgs
+}
+
+// BenchmarkTerminalAppend measures adding chunks to a terminal holding a
+// full scrollback, rendering after every chunk versus once per batch as a
+// Batcher delivers them at high data rates
+func BenchmarkTerminalAppend(b *testing.B) {
+	for _, batch := range []int{1, 64} {
+		b.Run(fmt.Sprintf("batch=%d", batch), func(b *testing.B) {
+			term := NewTerminal(120, 40)
+			for _, msg := range benchChunks(5000) {
+				term.AppendMessage(msg)
+			}
+			term.Flush()
+			chunks := benchChunks(batch)
+
+			b.ResetTimer()
+			for range b.N {
+				for _, msg := range chunks {
+					term.AppendMessage(msg)
+				}
+				term.Flush()
+				_ = term.View()
+			}
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*batch), "ns/chunk")
+		})
+	}
+}
+
+// BenchmarkTerminalTableAppend is BenchmarkTerminalAppend for the table
+// view of connect
+func BenchmarkTerminalTableAppend(b *testing.B) {
+	for _, batch := range []int{1, 64} {
+		b.Run(fmt.Sprintf("batch=%d", batch), func(b *testing.B) {
+			table := NewTerminalTable(120, 40)
+			table.SetSize(120, 40) // Pages the rows as connect does
+			for _, msg := range benchChunks(5000) {
+				table.AppendMessage(msg)
+			}
+			table.Flush()
+			chunks := benchChunks(batch)
+
+			b.ResetTimer()
+			for range b.N {
+				for _, msg := range chunks {
+					table.AppendMessage(msg)
+				}
+				table.Flush()
+				_ = table.View()
+			}
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*batch), "ns/chunk")
+		})
+	}
+}
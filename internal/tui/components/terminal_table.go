@@ -3,8 +3,10 @@ package components
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/allbin/go-serial/internal/tui/colors"
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/evertras/bubble-table/table"
@@ -22,6 +24,16 @@ type TerminalTable struct {
 	formatter *DataFormatter
 	viewMode  ViewMode
 	rawData   []DataReceivedMsg
+	shown     []DataReceivedMsg // rawData that passes the filter, one per row
+	rows      []table.Row       // Rendered rows of shown
+	dirty     bool              // Rows were appended since the table was last updated
+	filter    *Filter           // Nil or inactive when not filtering
+	search    *Search           // Nil or inactive when not searching
+	matches   []int             // Indices into shown of rows matching search
+	matchPos  int               // Index into matches of the current match, -1 if none
+	anchor    int               // Row where the visual selection starts, -1 for the cursor row only
+	txStatus  bool              // The direction column also shows TX status and latency
+	width     int
 }
 
 func NewTerminalTable(width, height int) *TerminalTable {
@@ -52,13 +64,16 @@ func NewTerminalTable(width, height int) *TerminalTable {
 	}
 
 	t := table.New(columns).
-		Border(table.Border{})
+		Border(table.Border{}).
+		WithKeyMap(navigationKeys())
 
 	tt := &TerminalTable{
 		table:     t,
 		formatter: NewDataFormatter(true, true), // Default: show both hex and ASCII
 		viewMode:  ViewModeFollow,               // Start in follow mode
 		rawData:   make([]DataReceivedMsg, 0),
+		matchPos:  -1,
+		anchor:    -1,
 	}
 
 	// Set initial column widths based on actual width
@@ -67,7 +82,41 @@ func NewTerminalTable(width, height int) *TerminalTable {
 	return tt
 }
 
+// navigationKeys moves the cursor in visual mode; the other table controls
+// are left unbound so they do not shadow the terminal's keys
+func navigationKeys() table.KeyMap {
+	return table.KeyMap{
+		RowDown: key.NewBinding(
+			key.WithKeys("down", "j"),
+		),
+		RowUp: key.NewBinding(
+			key.WithKeys("up", "k"),
+		),
+		PageDown: key.NewBinding(
+			key.WithKeys("pgdown", "ctrl+f"),
+		),
+		PageUp: key.NewBinding(
+			key.WithKeys("pgup", "ctrl+u"),
+		),
+		PageFirst: key.NewBinding(
+			key.WithKeys("home", "g"),
+		),
+		PageLast: key.NewBinding(
+			key.WithKeys("end"),
+		),
+	}
+}
+
+// SetTXStatus widens the direction column to show the status of TX rows
+// and the time from queueing to the wire, such as "↗ +1.3ms"
+func (tt *TerminalTable) SetTXStatus(show bool) {
+	tt.txStatus = show
+	tt.updateColumnsForDisplayMode(tt.width)
+	tt.refreshTable()
+}
+
 func (tt *TerminalTable) SetSize(width, height int) {
+	tt.width = width
 	// Update columns first, then table dimensions
 	tt.updateColumnsForDisplayMode(width)
 	tt.table = tt.table.WithTargetWidth(width).WithMaxTotalWidth(width).WithMinimumHeight(height).WithPageSize(height - 2) // Reserve space for header
@@ -94,6 +143,9 @@ func (tt *TerminalTable) updateColumnsForDisplayMode(width int) {
 	// Fixed column widths - keep these stable
 	timeWidth := 14 // Increased for "15:04:05.000" format
 	dirWidth := 3   // Just enough for the arrow
+	if tt.txStatus {
+		dirWidth = 12 // Arrow and "+12.3ms" or "TIMEOUT"
+	}
 	bytesWidth := 6 // Enough for "Bytes" header and reasonable counts
 
 	// Calculate remaining width for data columns
@@ -172,22 +224,144 @@ func (tt *TerminalTable) updateColumnsForDisplayMode(width int) {
 }
 
 func (tt *TerminalTable) AddMessage(msg DataReceivedMsg) {
+	tt.AppendMessage(msg)
+	tt.Flush()
+}
+
+// AppendMessage adds a row for msg without updating the table; call Flush
+// after a batch of messages, so fast data is rendered once per batch
+// Only the new row is formatted, not the whole scrollback.
+func (tt *TerminalTable) AppendMessage(msg DataReceivedMsg) {
 	tt.rawData = append(tt.rawData, msg)
-	tt.refreshTable()
+	switch {
+	case !tt.filter.IsActive():
+		tt.shown = tt.rawData
+	case tt.filter.Shows(msg.Data):
+		tt.shown = append(tt.shown, msg)
+	default:
+		return
+	}
+	tt.rows = append(tt.rows, tt.row(len(tt.shown)-1, msg))
+	tt.dirty = true
 }
 
+// Flush updates the table with the rows appended since the last update
+func (tt *TerminalTable) Flush() {
+	if tt.dirty {
+		tt.apply()
+	}
+}
+
+// UpdateMessage replaces the rows with a copy of rawData, so rows added
+// later with AddMessage do not write into the caller's slice
 func (tt *TerminalTable) UpdateMessage(rawData []DataReceivedMsg) {
-	tt.rawData = rawData
+	tt.rawData = append([]DataReceivedMsg(nil), rawData...)
 	tt.refreshTable()
 }
 
 func (tt *TerminalTable) refreshTable() {
-	rows := make([]table.Row, 0, len(tt.rawData))
-	for _, msg := range tt.rawData {
-		row := tt.formatMessageAsRow(msg)
-		rows = append(rows, row)
+	tt.matches = tt.matches[:0]
+
+	tt.shown = tt.rawData
+	if tt.filter.IsActive() {
+		tt.shown = make([]DataReceivedMsg, 0, len(tt.rawData))
+		for _, msg := range tt.rawData {
+			if tt.filter.Shows(msg.Data) {
+				tt.shown = append(tt.shown, msg)
+			}
+		}
+	}
+
+	tt.formatter.ResetDelta()
+	tt.rows = make([]table.Row, 0, len(tt.shown))
+	for i, msg := range tt.shown {
+		tt.rows = append(tt.rows, tt.row(i, msg))
+	}
+	tt.apply()
+}
+
+// row formats shown message i, styled for the selection and search matches
+func (tt *TerminalTable) row(i int, msg DataReceivedMsg) table.Row {
+	row := tt.formatMessageAsRow(msg)
+	if tt.anchor >= 0 {
+		if first, last := tt.selectionRange(); i >= first && i <= last {
+			row = row.WithStyle(row.Style.Background(colors.Surface0))
+		}
+	}
+	if tt.search != nil && tt.search.IsActive() && tt.search.Matches(msg.Data) {
+		tt.matches = append(tt.matches, i)
+		style := row.Style.Background(colors.Surface1)
+		if len(tt.matches)-1 == tt.matchPos {
+			style = style.Background(colors.Surface2).Bold(true)
+		}
+		row = row.WithStyle(style)
+	}
+	return row
+}
+
+// apply hands the rows to the table, keeping the newest in view when
+// following
+func (tt *TerminalTable) apply() {
+	tt.dirty = false
+	tt.table = tt.table.WithRows(tt.rows)
+	if tt.viewMode == ViewModeFollow {
+		// Keep the page with the newest row in view
+		tt.table = tt.table.WithHighlightedRow(len(tt.rows) - 1)
+	}
+
+	if tt.matchPos >= len(tt.matches) {
+		tt.matchPos = len(tt.matches) - 1
+	}
+	if tt.search != nil {
+		tt.search.SetPosition(tt.matchPos, len(tt.matches))
+	}
+}
+
+// SetFilter hides the messages f does not show; in visual mode the cursor
+// and selection restart at the newest row, as the rows have changed
+func (tt *TerminalTable) SetFilter(f *Filter) {
+	tt.filter = f
+	tt.anchor = -1
+	tt.matchPos = -1
+	tt.refreshTable()
+	if tt.viewMode == ViewModeVisual {
+		tt.table = tt.table.WithHighlightedRow(len(tt.shown) - 1)
+		tt.anchor = tt.table.GetHighlightedRowIndex()
+		tt.refreshTable()
+	}
+}
+
+// Hidden returns the number of messages the filter hides
+func (tt *TerminalTable) Hidden() int {
+	return len(tt.rawData) - len(tt.shown)
+}
+
+// SetSearch highlights rows matching s and jumps to the most recent match
+func (tt *TerminalTable) SetSearch(s *Search) {
+	tt.search = s
+	tt.matchPos = -1
+	tt.refreshTable()
+	if s.IsActive() {
+		tt.NextMatch(false)
+	}
+}
+
+// NextMatch moves to the next (or previous) matching row, wrapping around,
+// and switches to visual mode so new data does not move the view
+func (tt *TerminalTable) NextMatch(forward bool) {
+	if tt.search == nil || !tt.search.IsActive() {
+		return
+	}
+	tt.matchPos = stepMatch(tt.matchPos, len(tt.matches), forward)
+	if tt.matchPos >= 0 && tt.viewMode != ViewModeVisual {
+		tt.viewMode = ViewModeVisual
+		tt.table = tt.table.Focused(true)
+		tt.anchor = -1
+	}
+	tt.refreshTable()
+	if tt.matchPos >= 0 {
+		tt.table = tt.table.WithHighlightedRow(tt.matches[tt.matchPos])
 	}
-	tt.table = tt.table.WithRows(rows)
 }
 
 func (tt *TerminalTable) formatMessageAsRow(msg DataReceivedMsg) table.Row {
@@ -202,15 +376,28 @@ func (tt *TerminalTable) formatMessageAsRow(msg DataReceivedMsg) table.Row {
 	)
 
 	// Format timestamp
-	timestamp := msg.Timestamp.Format("15:04:05.000")
+	timestamp := tt.formatter.formatTime(msg.Timestamp)
 
 	// Format direction with arrows
 	var direction string
 	if msg.IsTX {
 		direction = "↗"
+		if tt.txStatus {
+			switch msg.Status {
+			case "PENDING":
+				direction += " …"
+			case "WRITTEN":
+				direction += txLatency(msg)
+			case "TIMEOUT", "ERROR":
+				direction += " " + msg.Status
+			}
+		}
 	} else {
 		direction = "↙"
 	}
+	if msg.Source != "" {
+		direction += " " + msg.Source
+	}
 
 	// Format bytes count
 	bytesStr := fmt.Sprintf("%d", len(msg.Data))
@@ -308,25 +495,47 @@ func (tt *TerminalTable) formatMessageAsRow(msg DataReceivedMsg) table.Row {
 		row = row.WithStyle(rxStyle)
 	}
 
+	// Highlight rules override the RX and sent TX colors, leaving pending,
+	// timed out and failed TX rows in their status colors
+	if !msg.IsTX || msg.Status == "WRITTEN" || msg.Status == "" {
+		if rule, ok := matchHighlight(tt.formatter.highlights, msg.Data); ok {
+			row = row.WithStyle(lipgloss.NewStyle().Foreground(rule.Color).Bold(true))
+		}
+	}
+
 	return row
 }
 
 func (tt *TerminalTable) Clear() {
 	tt.rawData = make([]DataReceivedMsg, 0)
+	tt.shown = tt.rawData
+	tt.rows = nil
+	tt.dirty = false
+	tt.matches = tt.matches[:0]
+	tt.matchPos = -1
+	if tt.search != nil {
+		tt.search.SetPosition(-1, 0)
+	}
 	tt.table = tt.table.WithRows([]table.Row{})
 }
 
 func (tt *TerminalTable) ToggleHex() {
 	tt.formatter.ToggleHex()
 	// Update table structure to reflect new display mode
-	tt.updateColumnsForDisplayMode(80) // Use default width since evertras doesn't expose current width
+	tt.updateColumnsForDisplayMode(tt.width)
 	tt.refreshTable()
 }
 
 func (tt *TerminalTable) ToggleASCII() {
 	tt.formatter.ToggleASCII()
 	// Update table structure to reflect new display mode
-	tt.updateColumnsForDisplayMode(80) // Use default width since evertras doesn't expose current width
+	tt.updateColumnsForDisplayMode(tt.width)
+	tt.refreshTable()
+}
+
+// SetHighlights sets the rules that color matching rows
+func (tt *TerminalTable) SetHighlights(rules []HighlightRule) {
+	tt.formatter.SetHighlights(rules)
 	tt.refreshTable()
 }
 
@@ -338,14 +547,104 @@ func (tt *TerminalTable) GetViewMode() ViewMode {
 	return tt.viewMode
 }
 
+// SetViewMode switches between following new data and visual mode, where
+// the cursor starts on the newest row and anchors a selection there
+// Setting visual mode again while in it restarts the selection at the cursor.
 func (tt *TerminalTable) SetViewMode(mode ViewMode) {
+	if mode == ViewModeVisual && tt.viewMode != ViewModeVisual {
+		tt.table = tt.table.WithHighlightedRow(len(tt.shown) - 1)
+	}
 	tt.viewMode = mode
-	// Note: evertras table focus handling might be different
-	// We'll handle this via the Update method instead
+	tt.table = tt.table.Focused(mode == ViewModeVisual)
+	tt.anchor = -1
+	if mode == ViewModeVisual {
+		tt.anchor = tt.table.GetHighlightedRowIndex()
+	}
+	tt.refreshTable()
+}
+
+// selectionRange returns the first and last selected rows: those between
+// the anchor and the cursor, or the cursor row alone without an anchor
+func (tt *TerminalTable) selectionRange() (int, int) {
+	cursor := tt.table.GetHighlightedRowIndex()
+	if tt.anchor < 0 || tt.anchor >= len(tt.shown) {
+		return cursor, cursor
+	}
+	return min(tt.anchor, cursor), max(tt.anchor, cursor)
+}
+
+// Selection returns the messages selected in visual mode, oldest first
+func (tt *TerminalTable) Selection() []DataReceivedMsg {
+	if tt.viewMode != ViewModeVisual || len(tt.shown) == 0 {
+		return nil
+	}
+	first, last := tt.selectionRange()
+	return tt.shown[first : last+1]
+}
+
+// Scroll moves the cursor by delta rows, switching to visual mode so new
+// data no longer moves the view; the selection restarts at the cursor
+func (tt *TerminalTable) Scroll(delta int) {
+	if tt.viewMode != ViewModeVisual {
+		tt.SetViewMode(ViewModeVisual)
+	}
+	tt.table = tt.table.WithHighlightedRow(tt.table.GetHighlightedRowIndex() + delta)
+	tt.anchor = tt.table.GetHighlightedRowIndex()
+	tt.refreshTable()
+}
+
+// RowAt returns the row shown on line y of the view, counting the header
+// as line 0, or -1 if no row is shown there
+func (tt *TerminalTable) RowAt(y int) int {
+	start, end := tt.table.VisibleIndices()
+	row := start + y - 1
+	if y < 1 || row > end {
+		return -1
+	}
+	return row
+}
+
+// Select switches to visual mode with the rows from anchor to cursor
+// selected, such as while dragging the mouse
+func (tt *TerminalTable) Select(anchor, cursor int) {
+	if tt.viewMode != ViewModeVisual {
+		tt.SetViewMode(ViewModeVisual)
+	}
+	tt.table = tt.table.WithHighlightedRow(cursor)
+	tt.anchor = anchor
+	tt.refreshTable()
+}
+
+// Cursor returns the message under the cursor in visual mode
+func (tt *TerminalTable) Cursor() (DataReceivedMsg, bool) {
+	cursor := tt.table.GetHighlightedRowIndex()
+	if tt.viewMode != ViewModeVisual || cursor < 0 || cursor >= len(tt.shown) {
+		return DataReceivedMsg{}, false
+	}
+	return tt.shown[cursor], true
+}
+
+// NextTimeMode cycles the Time column through time of day, time since the
+// previous row and time since the mark
+func (tt *TerminalTable) NextTimeMode() TimeMode {
+	mode := tt.formatter.NextTimeMode()
+	tt.refreshTable()
+	return mode
+}
+
+// SetMark shows times relative to t
+func (tt *TerminalTable) SetMark(t time.Time) {
+	tt.formatter.SetMark(t)
+	tt.refreshTable()
+}
+
+// TimeStatus summarizes a relative time mode; see DataFormatter.TimeStatus
+func (tt *TerminalTable) TimeStatus() string {
+	return tt.formatter.TimeStatus()
 }
 
 func (tt *TerminalTable) RefreshDisplayWithRawData(rawData []DataReceivedMsg) {
-	tt.rawData = rawData
+	tt.rawData = append([]DataReceivedMsg(nil), rawData...)
 	tt.refreshTable()
 }
 
@@ -358,7 +657,11 @@ func (tt *TerminalTable) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	// Only allow table navigation in visual mode
 	if tt.viewMode == ViewModeVisual {
+		cursor := tt.table.GetHighlightedRowIndex()
 		tt.table, cmd = tt.table.Update(msg)
+		if tt.anchor >= 0 && tt.table.GetHighlightedRowIndex() != cursor {
+			tt.refreshTable() // Restyle the selection
+		}
 	}
 
 	return tt, cmd
@@ -0,0 +1,236 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/allbin/go-serial/internal/tui/colors"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// TimelineHeight is the number of lines Timeline.View renders
+const TimelineHeight = 4
+
+// timelineLabelWidth is the width of the row labels, "CTS " and "TX  "
+const timelineLabelWidth = 4
+
+// ctsEdge is a change of CTS
+type ctsEdge struct {
+	at   time.Time
+	high bool // CTS asserted: the device is ready to receive
+}
+
+// txSpan is one TX from the time it was queued until it was written, timed
+// out or failed; done is zero while it is pending
+type txSpan struct {
+	seq    int64
+	queued time.Time
+	done   time.Time
+	status string
+}
+
+// Timeline shows CTS edges above the TX attempts of the same period, so a
+// write stalled by flow control lines up with the CTS deassertion behind it
+// The newest time is on the right; TX rows show ━ while a write waits and
+// ● when it was written, or ✗ when it timed out or failed.
+type Timeline struct {
+	span    time.Duration // Time shown across the width
+	edges   []ctsEdge     // Oldest first; the one before the span gives the state at its start
+	tx      []txSpan      // Oldest first
+	width   int
+	visible bool
+}
+
+func NewTimeline(span time.Duration) *Timeline {
+	return &Timeline{span: span, width: 80}
+}
+
+// SetCTS records the CTS state at a time; only changes are kept
+func (tl *Timeline) SetCTS(at time.Time, high bool) {
+	if n := len(tl.edges); n > 0 && tl.edges[n-1].high == high {
+		return
+	}
+	tl.edges = append(tl.edges, ctsEdge{at: at, high: high})
+	tl.prune(at)
+}
+
+// AddTX records a TX message: a PENDING one starts a span and the final
+// status of the same sequence ends it
+func (tl *Timeline) AddTX(msg DataReceivedMsg) {
+	if !msg.IsTX || msg.EnqueuedTime == nil {
+		return
+	}
+	if msg.Status != "PENDING" {
+		for i := len(tl.tx) - 1; i >= 0; i-- {
+			if tl.tx[i].seq == msg.Sequence {
+				tl.tx[i].done, tl.tx[i].status = msg.Timestamp, msg.Status
+				return
+			}
+		}
+	}
+	span := txSpan{seq: msg.Sequence, queued: *msg.EnqueuedTime, status: msg.Status}
+	if msg.Status != "PENDING" {
+		span.done = msg.Timestamp
+	}
+	tl.tx = append(tl.tx, span)
+	tl.prune(msg.Timestamp)
+}
+
+// prune drops what has scrolled out of view, keeping the last CTS edge
+// before the span for the state at its start
+func (tl *Timeline) prune(now time.Time) {
+	start := now.Add(-tl.span)
+	for len(tl.edges) > 1 && tl.edges[1].at.Before(start) {
+		tl.edges = tl.edges[1:]
+	}
+	for len(tl.tx) > 0 && !tl.tx[0].done.IsZero() && tl.tx[0].done.Before(start) {
+		tl.tx = tl.tx[1:]
+	}
+}
+
+// Clear forgets the TX attempts; the CTS state is kept
+func (tl *Timeline) Clear() {
+	tl.tx = nil
+	if n := len(tl.edges); n > 1 {
+		tl.edges = tl.edges[n-1:]
+	}
+}
+
+func (tl *Timeline) SetWidth(width int) {
+	tl.width = width
+}
+
+// SetVisible shows or hides the timeline
+func (tl *Timeline) SetVisible(visible bool) {
+	tl.visible = visible
+}
+
+// IsVisible reports whether the timeline is shown
+func (tl *Timeline) IsVisible() bool {
+	return tl.visible
+}
+
+// Height returns the number of lines View renders: zero while hidden
+func (tl *Timeline) Height() int {
+	if !tl.visible {
+		return 0
+	}
+	return TimelineHeight
+}
+
+// View renders the span up to now
+func (tl *Timeline) View(now time.Time) string {
+	if !tl.visible {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().Foreground(colors.Mauve).Bold(true)
+	infoStyle := lipgloss.NewStyle().Foreground(colors.Subtext0)
+	labelStyle := lipgloss.NewStyle().Foreground(colors.Overlay0)
+	highStyle := lipgloss.NewStyle().Foreground(colors.Green)
+	lowStyle := lipgloss.NewStyle().Foreground(colors.Red)
+	edgeStyle := lipgloss.NewStyle().Foreground(colors.Text)
+	waitStyle := lipgloss.NewStyle().Foreground(colors.Yellow)
+	writtenStyle := lipgloss.NewStyle().Foreground(colors.Blue)
+	failedStyle := lipgloss.NewStyle().Foreground(colors.Peach)
+
+	cols := max(tl.width-timelineLabelWidth, 10)
+	start := now.Add(-tl.span)
+	step := tl.span / time.Duration(cols)
+	column := func(t time.Time) int {
+		return int(t.Sub(start) / step)
+	}
+
+	// CTS: ▔ asserted, ▁ deasserted, │ where it changed
+	cts := make([]string, cols)
+	state, known := false, false
+	next := 0
+	for next < len(tl.edges) && tl.edges[next].at.Before(start) {
+		state, known = tl.edges[next].high, true
+		next++
+	}
+	for c := 0; c < cols; c++ {
+		end := start.Add(step * time.Duration(c+1))
+		changed := false
+		for next < len(tl.edges) && tl.edges[next].at.Before(end) {
+			changed = changed || known
+			state, known = tl.edges[next].high, true
+			next++
+		}
+		switch {
+		case changed:
+			cts[c] = edgeStyle.Render("│")
+		case !known:
+			cts[c] = " "
+		case state:
+			cts[c] = highStyle.Render("▔")
+		default:
+			cts[c] = lowStyle.Render("▁")
+		}
+	}
+
+	// TX: ━ waiting from queueing until written (●) or failed (✗)
+	tx := make([]string, cols)
+	for c := range tx {
+		tx[c] = " "
+	}
+	var longest time.Duration
+	pending := 0
+	for _, s := range tl.tx {
+		end := s.done
+		if end.IsZero() {
+			end = now
+			pending++
+		}
+		longest = max(longest, end.Sub(s.queued))
+		first, last := max(column(s.queued), 0), min(column(end), cols-1)
+		if last < 0 || first >= cols {
+			continue
+		}
+		for c := first; c < last; c++ {
+			tx[c] = waitStyle.Render("━")
+		}
+		switch s.status {
+		case "PENDING":
+			tx[last] = waitStyle.Render("━")
+		case "WRITTEN":
+			tx[last] = writtenStyle.Render("●")
+		default:
+			tx[last] = failedStyle.Render("✗")
+		}
+	}
+
+	info := fmt.Sprintf("  last %s  %d TX", tl.span, len(tl.tx))
+	if pending > 0 {
+		info += fmt.Sprintf(", %d waiting", pending)
+	}
+	if longest >= time.Millisecond {
+		info += fmt.Sprintf(", longest %s", longest.Round(time.Millisecond))
+	} else if longest > 0 {
+		info += fmt.Sprintf(", longest %s", longest.Round(time.Microsecond))
+	}
+	if len(tl.edges) > 0 {
+		if tl.edges[len(tl.edges)-1].high {
+			info += "  CTS asserted"
+		} else {
+			info += "  CTS deasserted"
+		}
+	}
+
+	// Axis: the start, middle and end of the span
+	axis := []rune(strings.Repeat(" ", cols))
+	left, mid := fmt.Sprintf("-%s", tl.span), fmt.Sprintf("-%s", tl.span/2)
+	copy(axis, []rune(left))
+	if m := cols/2 - len(mid)/2; m > len(left) {
+		copy(axis[m:], []rune(mid))
+	}
+	copy(axis[cols-3:], []rune("now"))
+
+	return strings.Join([]string{
+		titleStyle.Render("CTS timeline") + infoStyle.Render(info),
+		labelStyle.Render("CTS ") + strings.Join(cts, ""),
+		labelStyle.Render("TX  ") + strings.Join(tx, ""),
+		labelStyle.Render(strings.Repeat(" ", timelineLabelWidth) + string(axis)),
+	}, "\n")
+}
@@ -0,0 +1,121 @@
+package components
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeMode selects how message times are shown
+type TimeMode int
+
+const (
+	TimeAbsolute  TimeMode = iota // Time of day, 15:04:05.000
+	TimeDelta                     // Time since the previous message shown
+	TimeSinceMark                 // Time since the mark set with SetMark
+)
+
+func (m TimeMode) String() string {
+	switch m {
+	case TimeDelta:
+		return "Δ prev"
+	case TimeSinceMark:
+		return "Δ mark"
+	default:
+		return "time"
+	}
+}
+
+// formatTime renders the time of a message under the formatter's time mode
+// In TimeDelta mode each call measures from the previous one, so messages
+// must be formatted in order after ResetDelta.
+func (df *DataFormatter) formatTime(t time.Time) string {
+	switch df.timeMode {
+	case TimeDelta:
+		prev := df.prev
+		df.prev = t
+		if prev.IsZero() {
+			return df.formatTimeOfDay(t)
+		}
+		return formatDelta(t.Sub(prev))
+	case TimeSinceMark:
+		return formatDelta(t.Sub(df.mark))
+	}
+	return df.formatTimeOfDay(t)
+}
+
+func (df *DataFormatter) formatTimeOfDay(t time.Time) string {
+	if df.micro {
+		return t.Format("15:04:05.000000")
+	}
+	return t.Format("15:04:05.000")
+}
+
+// SetMicroseconds shows times of day to the microsecond rather than the
+// millisecond
+func (df *DataFormatter) SetMicroseconds(micro bool) {
+	df.micro = micro
+}
+
+// SetTimeMode selects how message times are shown; TimeSinceMark needs a
+// mark set with SetMark
+func (df *DataFormatter) SetTimeMode(mode TimeMode) {
+	if mode == TimeSinceMark && df.mark.IsZero() {
+		mode = TimeAbsolute
+	}
+	df.timeMode = mode
+}
+
+func (df *DataFormatter) GetTimeMode() TimeMode {
+	return df.timeMode
+}
+
+// NextTimeMode cycles through the time modes, skipping TimeSinceMark while
+// no mark is set
+func (df *DataFormatter) NextTimeMode() TimeMode {
+	df.SetTimeMode((df.timeMode + 1) % 3)
+	return df.timeMode
+}
+
+// SetMark sets the time TimeSinceMark measures from and selects that mode
+func (df *DataFormatter) SetMark(t time.Time) {
+	df.mark = t
+	df.timeMode = TimeSinceMark
+}
+
+// TimeStatus summarizes a relative time mode for the status bar, e.g.
+// "Δ mark 12:01:02.345"; it is empty for absolute times
+func (df *DataFormatter) TimeStatus() string {
+	switch df.timeMode {
+	case TimeDelta:
+		return df.timeMode.String()
+	case TimeSinceMark:
+		return fmt.Sprintf("%s %s", df.timeMode, df.mark.Format("15:04:05.000"))
+	}
+	return ""
+}
+
+// ResetDelta forgets the previous message, such as before formatting all
+// messages again
+func (df *DataFormatter) ResetDelta() {
+	df.prev = time.Time{}
+}
+
+// formatDelta renders a time difference compactly with its sign, such as
+// +850µs, +12.345ms, +1.204s or -2m03.500s
+func formatDelta(d time.Duration) string {
+	sign := "+"
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	switch {
+	case d < time.Millisecond:
+		return fmt.Sprintf("%s%dµs", sign, d.Microseconds())
+	case d < time.Second:
+		return fmt.Sprintf("%s%.3fms", sign, float64(d)/float64(time.Millisecond))
+	case d < time.Minute:
+		return fmt.Sprintf("%s%.3fs", sign, d.Seconds())
+	case d < time.Hour:
+		return fmt.Sprintf("%s%dm%06.3fs", sign, int(d/time.Minute), (d % time.Minute).Seconds())
+	}
+	return fmt.Sprintf("%s%dh%02dm%02ds", sign, int(d/time.Hour), int(d%time.Hour/time.Minute), int(d%time.Minute/time.Second))
+}
@@ -0,0 +1,93 @@
+package components
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatDelta(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{850 * time.Microsecond, "+850µs"},
+		{12345 * time.Microsecond, "+12.345ms"},
+		{1204 * time.Millisecond, "+1.204s"},
+		{-(2*time.Minute + 3500*time.Millisecond), "-2m03.500s"},
+		{3*time.Hour + 4*time.Minute + 5*time.Second, "+3h04m05s"},
+		{0, "+0µs"},
+	}
+	for _, tt := range tests {
+		if got := formatDelta(tt.d); got != tt.want {
+			t.Errorf("formatDelta(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestFormatTimeModes(t *testing.T) {
+	base := time.Date(2025, 1, 2, 12, 1, 2, 345678000, time.Local)
+	df := NewDataFormatter(true, true)
+
+	if got := df.formatTime(base); got != "12:01:02.345" {
+		t.Errorf("absolute time = %q", got)
+	}
+	df.SetMicroseconds(true)
+	if got := df.formatTime(base); got != "12:01:02.345678" {
+		t.Errorf("absolute time in microseconds = %q", got)
+	}
+	if df.TimeStatus() != "" {
+		t.Errorf("TimeStatus in absolute mode = %q, want empty", df.TimeStatus())
+	}
+
+	// Each delta measures from the previous message; the first shows its time of day
+	df.SetTimeMode(TimeDelta)
+	for _, tt := range []struct {
+		at   time.Duration
+		want string
+	}{
+		{0, "12:01:02.345678"},
+		{5 * time.Millisecond, "+5.000ms"},
+		{5*time.Millisecond + 300*time.Microsecond, "+300µs"},
+	} {
+		if got := df.formatTime(base.Add(tt.at)); got != tt.want {
+			t.Errorf("delta at %v = %q, want %q", tt.at, got, tt.want)
+		}
+	}
+	df.ResetDelta()
+	if got := df.formatTime(base); got != "12:01:02.345678" {
+		t.Errorf("delta after ResetDelta = %q, want the time of day", got)
+	}
+
+	df.SetMark(base)
+	if df.GetTimeMode() != TimeSinceMark || df.TimeStatus() != "Δ mark 12:01:02.345" {
+		t.Errorf("after SetMark: mode %v, status %q", df.GetTimeMode(), df.TimeStatus())
+	}
+	if got := df.formatTime(base.Add(-1500 * time.Millisecond)); got != "-1.500s" {
+		t.Errorf("time since mark = %q, want -1.500s", got)
+	}
+}
+
+func TestNextTimeMode(t *testing.T) {
+	df := NewDataFormatter(true, true)
+
+	// Without a mark the cycle skips TimeSinceMark
+	if got := df.NextTimeMode(); got != TimeDelta {
+		t.Errorf("NextTimeMode = %v, want %v", got, TimeDelta)
+	}
+	if got := df.NextTimeMode(); got != TimeAbsolute {
+		t.Errorf("NextTimeMode without a mark = %v, want %v", got, TimeAbsolute)
+	}
+	df.SetTimeMode(TimeSinceMark)
+	if df.GetTimeMode() != TimeAbsolute {
+		t.Errorf("SetTimeMode(TimeSinceMark) without a mark selected %v", df.GetTimeMode())
+	}
+
+	df.SetMark(time.Now())
+	df.SetTimeMode(TimeDelta)
+	if got := df.NextTimeMode(); got != TimeSinceMark {
+		t.Errorf("NextTimeMode with a mark = %v, want %v", got, TimeSinceMark)
+	}
+	if got := df.NextTimeMode(); got != TimeAbsolute {
+		t.Errorf("NextTimeMode = %v, want %v", got, TimeAbsolute)
+	}
+}
@@ -39,6 +39,13 @@ type TerminalKeys struct {
 	ToggleASCII      key.Binding
 	ToggleTimestamps key.Binding
 	ToggleIndicators key.Binding
+	TimeMode         key.Binding
+	SetMark          key.Binding
+	Search           key.Binding
+	NextMatch        key.Binding
+	PrevMatch        key.Binding
+	Filter           key.Binding
+	Export           key.Binding
 }
 
 func NewTerminalKeys() TerminalKeys {
@@ -64,6 +71,34 @@ func NewTerminalKeys() TerminalKeys {
 			key.WithKeys("r"),
 			key.WithHelp("r", "toggle RX/TX indicators"),
 		),
+		TimeMode: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "cycle time/delta/since mark"),
+		),
+		SetMark: key.NewBinding(
+			key.WithKeys("m"),
+			key.WithHelp("m", "set time mark"),
+		),
+		Search: key.NewBinding(
+			key.WithKeys("/"),
+			key.WithHelp("/", "search text or hex"),
+		),
+		NextMatch: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "next match"),
+		),
+		PrevMatch: key.NewBinding(
+			key.WithKeys("N"),
+			key.WithHelp("N", "previous match"),
+		),
+		Filter: key.NewBinding(
+			key.WithKeys("f"),
+			key.WithHelp("f", "filter messages"),
+		),
+		Export: key.NewBinding(
+			key.WithKeys("w"),
+			key.WithHelp("w", "export scrollback to file"),
+		),
 	}
 }
 
@@ -75,6 +110,8 @@ func (k TerminalKeys) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.InsertMode, k.Escape, k.Clear},
 		{k.ToggleHex, k.ToggleASCII, k.ToggleTimestamps, k.ToggleIndicators},
-		{k.Help, k.Quit},
+		{k.TimeMode, k.SetMark},
+		{k.Search, k.NextMatch, k.PrevMatch, k.Filter},
+		{k.Export, k.Help, k.Quit},
 	}
 }
@@ -8,11 +8,28 @@ type ConnectKeys struct {
 	Enter          key.Binding
 	Send           key.Binding
 	ToggleSendMode key.Binding
+	HistorySearch  key.Binding
+	RawMode        key.Binding
+	Command        key.Binding
 	Up             key.Binding
 	Down           key.Binding
 	VisualMode     key.Binding
 	GotoTop        key.Binding
 	GotoBottom     key.Binding
+	ToggleLog      key.Binding
+	SendBreak      key.Binding
+	ToggleRTS      key.Binding
+	ToggleDTR      key.Binding
+	Yank           key.Binding
+	YankHex        key.Binding
+	ToggleEcho     key.Binding
+	LineEnding     key.Binding
+	ToggleTriggers key.Binding
+	TogglePlot     key.Binding
+	ToggleTimeline key.Binding
+	Inspect        key.Binding
+	InspectLeft    key.Binding
+	InspectRight   key.Binding
 }
 
 func NewConnectKeys() ConnectKeys {
@@ -30,6 +47,10 @@ func NewConnectKeys() ConnectKeys {
 			key.WithKeys("tab"),
 			key.WithHelp("tab", "toggle send mode"),
 		),
+		HistorySearch: key.NewBinding(
+			key.WithKeys("ctrl+r"),
+			key.WithHelp("ctrl+r", "search input history"),
+		),
 		Up: key.NewBinding(
 			key.WithKeys("up", "k"),
 			key.WithHelp("↑/k", "up"),
@@ -50,6 +71,70 @@ func NewConnectKeys() ConnectKeys {
 			key.WithKeys("G"),
 			key.WithHelp("G", "goto bottom"),
 		),
+		ToggleLog: key.NewBinding(
+			key.WithKeys("L"),
+			key.WithHelp("L", "toggle log to file"),
+		),
+		SendBreak: key.NewBinding(
+			key.WithKeys("ctrl+b"),
+			key.WithHelp("ctrl+b", "send break"),
+		),
+		ToggleRTS: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "toggle RTS"),
+		),
+		ToggleDTR: key.NewBinding(
+			key.WithKeys("D"),
+			key.WithHelp("D", "toggle DTR"),
+		),
+		Yank: key.NewBinding(
+			key.WithKeys("y"),
+			key.WithHelp("y", "copy selection as text"),
+		),
+		YankHex: key.NewBinding(
+			key.WithKeys("Y"),
+			key.WithHelp("Y", "copy selection as hex"),
+		),
+		ToggleEcho: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "toggle local echo"),
+		),
+		LineEnding: key.NewBinding(
+			key.WithKeys("E"),
+			key.WithHelp("E", "cycle line ending"),
+		),
+		ToggleTriggers: key.NewBinding(
+			key.WithKeys("T"),
+			key.WithHelp("T", "pause/resume triggers"),
+		),
+		TogglePlot: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "show/hide plot"),
+		),
+		ToggleTimeline: key.NewBinding(
+			key.WithKeys("C"),
+			key.WithHelp("C", "show/hide CTS timeline"),
+		),
+		Command: key.NewBinding(
+			key.WithKeys(":"),
+			key.WithHelp(":", "command (open <port> [baud], close)"),
+		),
+		RawMode: key.NewBinding(
+			key.WithKeys("ctrl+]"),
+			key.WithHelp("ctrl+]", "raw keystroke mode on/off"),
+		),
+		Inspect: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "inspect row bytes (visual mode)"),
+		),
+		InspectLeft: key.NewBinding(
+			key.WithKeys("left"),
+			key.WithHelp("←", "previous byte"),
+		),
+		InspectRight: key.NewBinding(
+			key.WithKeys("right"),
+			key.WithHelp("→", "next byte"),
+		),
 	}
 }
 
@@ -59,9 +144,53 @@ func (k ConnectKeys) ShortHelp() []key.Binding {
 
 func (k ConnectKeys) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
-		{k.InsertMode, k.VisualMode, k.Escape, k.Clear},
+		{k.InsertMode, k.VisualMode, k.RawMode, k.Escape, k.Clear},
 		{k.ToggleHex, k.ToggleASCII, k.ToggleTimestamps, k.ToggleIndicators},
+		{k.TimeMode, k.SetMark},
 		{k.GotoTop, k.GotoBottom, k.Up, k.Down},
-		{k.Enter, k.Help, k.Quit},
+		{k.Yank, k.YankHex, k.Inspect, k.InspectLeft, k.InspectRight},
+		{k.Search, k.NextMatch, k.PrevMatch, k.Filter},
+		{k.Enter, k.HistorySearch, k.SendBreak, k.ToggleRTS, k.ToggleDTR},
+		{k.ToggleEcho, k.LineEnding, k.ToggleTriggers, k.TogglePlot, k.ToggleTimeline, k.ToggleLog, k.Export},
+		{k.Command, k.Help, k.Quit},
+	}
+}
+
+// TabKeys are handled by the tab view before the current tab, while there
+// is more than one
+type TabKeys struct {
+	NextTab key.Binding
+	PrevTab key.Binding
+	GotoTab key.Binding
+}
+
+func NewTabKeys() TabKeys {
+	return TabKeys{
+		NextTab: key.NewBinding(
+			key.WithKeys("alt+right", "ctrl+pgdown"),
+			key.WithHelp("alt+→", "next tab"),
+		),
+		PrevTab: key.NewBinding(
+			key.WithKeys("alt+left", "ctrl+pgup"),
+			key.WithHelp("alt+←", "previous tab"),
+		),
+		GotoTab: key.NewBinding(
+			key.WithKeys("alt+1", "alt+2", "alt+3", "alt+4", "alt+5", "alt+6", "alt+7", "alt+8", "alt+9"),
+			key.WithHelp("alt+1-9", "go to tab"),
+		),
+	}
+}
+
+// SplitKeys are handled by the two-port split view before the focused pane
+type SplitKeys struct {
+	SwitchPane key.Binding
+}
+
+func NewSplitKeys() SplitKeys {
+	return SplitKeys{
+		SwitchPane: key.NewBinding(
+			key.WithKeys("ctrl+o"),
+			key.WithHelp("ctrl+o", "switch pane"),
+		),
 	}
 }
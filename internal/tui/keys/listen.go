@@ -0,0 +1,33 @@
+package keys
+
+import "github.com/charmbracelet/bubbles/key"
+
+// ListenKeys includes terminal keys plus the listen traffic counters
+type ListenKeys struct {
+	TerminalKeys
+	ResetCounters key.Binding
+}
+
+func NewListenKeys() ListenKeys {
+	return ListenKeys{
+		TerminalKeys: NewTerminalKeys(),
+		ResetCounters: key.NewBinding(
+			key.WithKeys("z"),
+			key.WithHelp("z", "reset counters"),
+		),
+	}
+}
+
+func (k ListenKeys) ShortHelp() []key.Binding {
+	return k.TerminalKeys.ShortHelp()
+}
+
+func (k ListenKeys) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.InsertMode, k.Escape, k.Clear},
+		{k.ToggleHex, k.ToggleASCII, k.ToggleTimestamps, k.ToggleIndicators},
+		{k.TimeMode, k.SetMark, k.ResetCounters},
+		{k.Search, k.NextMatch, k.PrevMatch, k.Filter},
+		{k.Export, k.Help, k.Quit},
+	}
+}
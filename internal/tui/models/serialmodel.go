@@ -14,6 +14,7 @@ type InputMode int
 const (
 	InputModeNormal InputMode = iota
 	InputModeInsert
+	InputModeRaw // Keys go straight to the port
 )
 
 func (m InputMode) String() string {
@@ -22,6 +23,8 @@ func (m InputMode) String() string {
 		return "NORMAL"
 	case InputModeInsert:
 		return "INSERT"
+	case InputModeRaw:
+		return "RAW"
 	default:
 		return "NORMAL"
 	}
@@ -44,6 +47,12 @@ type SerialModel struct {
 	ready     bool
 	sequence  int64 // Counter for message sequence numbers
 
+	// Scrollback limits; zero means unlimited
+	maxMessages int
+	maxBytes    int64
+	rawBytes    int64 // Data bytes held in rawData
+	dropped     int   // Messages evicted since the last clear
+
 	// Input mode (vim-like)
 	inputMode InputMode
 
@@ -113,13 +122,47 @@ func (m *SerialModel) GetRawData() []components.DataReceivedMsg {
 	return m.rawData
 }
 
-func (m *SerialModel) AddRawData(msg components.DataReceivedMsg) {
+// SetScrollbackLimit bounds the messages kept by count and by data bytes;
+// zero leaves a dimension unlimited
+func (m *SerialModel) SetScrollbackLimit(messages int, bytes int64) {
+	m.maxMessages = messages
+	m.maxBytes = bytes
+}
+
+// AddRawData stores msg and returns the number of old messages evicted to
+// stay within the scrollback limit
+// Eviction trims to 90% of the limit, so views rebuilt after an eviction
+// are not rebuilt for every message once the scrollback is full.
+func (m *SerialModel) AddRawData(msg components.DataReceivedMsg) int {
 	// Assign sequence number if not already set
 	if msg.Sequence == 0 {
 		m.sequence++
 		msg.Sequence = m.sequence
 	}
 	m.rawData = append(m.rawData, msg)
+	m.rawBytes += int64(len(msg.Data))
+
+	if (m.maxMessages <= 0 || len(m.rawData) <= m.maxMessages) && (m.maxBytes <= 0 || m.rawBytes <= m.maxBytes) {
+		return 0
+	}
+	keepMessages := m.maxMessages - m.maxMessages/10
+	keepBytes := m.maxBytes - m.maxBytes/10
+	n := 0
+	for n < len(m.rawData)-1 &&
+		(keepMessages > 0 && len(m.rawData)-n > keepMessages || keepBytes > 0 && m.rawBytes > keepBytes) {
+		m.rawBytes -= int64(len(m.rawData[n].Data))
+		n++
+	}
+	clear(m.rawData[:n]) // Release the evicted data
+	m.rawData = m.rawData[n:]
+	m.dropped += n
+	return n
+}
+
+// Dropped returns the number of messages evicted from the scrollback since
+// it was last cleared
+func (m *SerialModel) Dropped() int {
+	return m.dropped
 }
 
 func (m *SerialModel) UpdateMessage(msg components.DataReceivedMsg) bool {
@@ -141,6 +184,8 @@ func (m *SerialModel) GetNextSequence() int64 {
 func (m *SerialModel) ClearData() {
 	m.rawData = make([]components.DataReceivedMsg, 0)
 	m.sequence = 0
+	m.rawBytes = 0
+	m.dropped = 0
 }
 
 func (m *SerialModel) GetFormattedData() []string {
@@ -185,6 +230,12 @@ func (m *SerialModel) IsInInsertMode() bool {
 	return m.inputMode == InputModeInsert
 }
 
+func (m *SerialModel) IsInRawMode() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.inputMode == InputModeRaw
+}
+
 func (m *SerialModel) GetContext() context.Context {
 	return m.ctx
 }
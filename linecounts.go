@@ -0,0 +1,31 @@
+package serial
+
+// LineCounts are the counters a UART driver keeps for a device: modem line
+// transitions, bytes transferred and receive errors
+// They count from when the driver bound the device, not from when the port
+// was opened, so compare two readings rather than using one on its own.
+type LineCounts struct {
+	CTS, DSR, RI, DCD int // Modem line transitions
+	RX, TX            int // Bytes received and transmitted by the UART
+	Frame             int // Characters with framing errors
+	Overrun           int // Characters lost to UART FIFO overruns
+	Parity            int // Characters with parity errors
+	Break             int // Break conditions received
+	BufferOverrun     int // Characters lost to a full tty buffer
+}
+
+// LineCounter is implemented by ports that can report driver line counters
+type LineCounter interface {
+	// LineCounts returns the driver's current counters
+	LineCounts() (LineCounts, error)
+}
+
+// GetLineCounts reads the driver counters of p
+// Returns ErrNotSupported if p has none, such as a network port or a PTY,
+// or a USB adapter whose driver does not keep them.
+func GetLineCounts(p Port) (LineCounts, error) {
+	if c, ok := p.(LineCounter); ok {
+		return c.LineCounts()
+	}
+	return LineCounts{}, ErrNotSupported
+}
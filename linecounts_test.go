@@ -0,0 +1,30 @@
+package serial
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGetLineCountsNotSupported(t *testing.T) {
+	master, slavePath, err := OpenPTY()
+	if err != nil {
+		t.Skipf("PTY not available: %v", err)
+	}
+	defer master.Close()
+
+	port, err := Open(slavePath, WithReadTimeout(100*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Open(%s) failed: %v", slavePath, err)
+	}
+
+	// PTYs have no UART, so the driver keeps no counters
+	if _, err := GetLineCounts(port); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("GetLineCounts on PTY error = %v, want ErrNotSupported", err)
+	}
+
+	port.Close()
+	if _, err := GetLineCounts(port); !errors.Is(err, ErrPortClosed) {
+		t.Errorf("GetLineCounts after Close error = %v, want ErrPortClosed", err)
+	}
+}
@@ -0,0 +1,89 @@
+package serial
+
+import (
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// LineSettings is the line configuration a device's driver currently has,
+// read back from its termios
+// Unlike the Config a port was opened with, it reflects changes made since
+// by other programs or stty.
+type LineSettings struct {
+	BaudRate    int           // 0 if the speed is not one Open accepts
+	DataBits    int           // 5 to 8
+	Parity      Parity        // Including mark and space (CMSPAR)
+	StopBits    int           // 1 or 2
+	RTSCTS      bool          // Hardware flow control (CRTSCTS)
+	XONXOFF     bool          // Software flow control (IXON or IXOFF)
+	MinChars    int           // VMIN: bytes a read waits for
+	ReadTimeout time.Duration // VTIME: how long a read waits for data
+
+	// Raw termios flags, for what the fields above do not cover
+	Cflag, Iflag, Oflag, Lflag uint32
+}
+
+// LineSettingsReader is implemented by ports that can read back their
+// driver's line configuration
+type LineSettingsReader interface {
+	// LineSettings returns the driver's current line configuration
+	LineSettings() (LineSettings, error)
+}
+
+// GetLineSettings reads the driver's line configuration of p
+// Returns ErrNotSupported if p has no termios, such as a network port.
+func GetLineSettings(p Port) (LineSettings, error) {
+	if r, ok := p.(LineSettingsReader); ok {
+		return r.LineSettings()
+	}
+	return LineSettings{}, ErrNotSupported
+}
+
+// decodeTermios converts termios flags to LineSettings
+func decodeTermios(t *unix.Termios) LineSettings {
+	s := LineSettings{
+		BaudRate:    baudRateFromCode(t.Cflag & unix.CBAUD),
+		DataBits:    8,
+		Parity:      ParityNone,
+		StopBits:    1,
+		RTSCTS:      t.Cflag&unix.CRTSCTS != 0,
+		XONXOFF:     t.Iflag&(unix.IXON|unix.IXOFF) != 0,
+		MinChars:    int(t.Cc[unix.VMIN]),
+		ReadTimeout: time.Duration(t.Cc[unix.VTIME]) * 100 * time.Millisecond,
+		Cflag:       t.Cflag,
+		Iflag:       t.Iflag,
+		Oflag:       t.Oflag,
+		Lflag:       t.Lflag,
+	}
+	if t.Cflag&unix.CBAUD == unix.BOTHER {
+		s.BaudRate = int(t.Ospeed)
+	}
+
+	switch t.Cflag & unix.CSIZE {
+	case unix.CS5:
+		s.DataBits = 5
+	case unix.CS6:
+		s.DataBits = 6
+	case unix.CS7:
+		s.DataBits = 7
+	}
+	if t.Cflag&unix.CSTOPB != 0 {
+		s.StopBits = 2
+	}
+
+	if t.Cflag&unix.PARENB != 0 {
+		odd := t.Cflag&unix.PARODD != 0
+		switch {
+		case t.Cflag&unix.CMSPAR != 0 && odd:
+			s.Parity = ParityMark
+		case t.Cflag&unix.CMSPAR != 0:
+			s.Parity = ParitySpace
+		case odd:
+			s.Parity = ParityOdd
+		default:
+			s.Parity = ParityEven
+		}
+	}
+	return s
+}
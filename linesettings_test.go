@@ -0,0 +1,104 @@
+package serial
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestGetLineSettings(t *testing.T) {
+	master, slavePath, err := OpenPTY()
+	if err != nil {
+		t.Skipf("PTY not available: %v", err)
+	}
+	defer master.Close()
+
+	// PTYs force 8 data bits without parity, so only speed, stop bits and
+	// timeouts can be checked here; decodeTermios covers the rest
+	port, err := Open(slavePath, WithBaudRate(9600), WithStopBits(2), WithReadTimeout(300*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Open(%s) failed: %v", slavePath, err)
+	}
+	defer port.Close()
+
+	got, err := GetLineSettings(port)
+	if err != nil {
+		t.Fatalf("GetLineSettings failed: %v", err)
+	}
+	if got.BaudRate != 9600 || got.StopBits != 2 {
+		t.Errorf("settings = %d baud %d stop bits, want 9600 and 2", got.BaudRate, got.StopBits)
+	}
+	if got.ReadTimeout != 300*time.Millisecond || got.MinChars != 0 {
+		t.Errorf("VTIME/VMIN = %v/%d, want 300ms/0", got.ReadTimeout, got.MinChars)
+	}
+}
+
+func TestKeepSettings(t *testing.T) {
+	master, slavePath, err := OpenPTY()
+	if err != nil {
+		t.Skipf("PTY not available: %v", err)
+	}
+	defer master.Close()
+
+	first, err := Open(slavePath, WithBaudRate(19200))
+	if err != nil {
+		t.Fatalf("Open(%s) failed: %v", slavePath, err)
+	}
+	defer first.Close()
+
+	// A second open with defaults but WithKeepSettings must not reset the speed
+	second, err := Open(slavePath, WithKeepSettings())
+	if err != nil {
+		t.Fatalf("Open(%s) with WithKeepSettings failed: %v", slavePath, err)
+	}
+	defer second.Close()
+
+	got, err := GetLineSettings(second)
+	if err != nil {
+		t.Fatalf("GetLineSettings failed: %v", err)
+	}
+	if got.BaudRate != 19200 {
+		t.Errorf("BaudRate = %d, want 19200 left by the first open", got.BaudRate)
+	}
+}
+
+func TestDecodeTermios(t *testing.T) {
+	tests := []struct {
+		name   string
+		cflag  uint32
+		parity Parity
+		bits   int
+	}{
+		{"odd", unix.CS8 | unix.PARENB | unix.PARODD, ParityOdd, 8},
+		{"mark", unix.CS8 | unix.PARENB | unix.PARODD | unix.CMSPAR, ParityMark, 8},
+		{"space", unix.CS7 | unix.PARENB | unix.CMSPAR, ParitySpace, 7},
+		{"none", unix.CS5, ParityNone, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := decodeTermios(&unix.Termios{Cflag: tt.cflag | unix.B115200})
+			if got.Parity != tt.parity || got.DataBits != tt.bits || got.BaudRate != 115200 {
+				t.Errorf("decodeTermios = %s %d bits %d baud, want %s %d bits 115200",
+					got.Parity, got.DataBits, got.BaudRate, tt.parity, tt.bits)
+			}
+		})
+	}
+
+	other := decodeTermios(&unix.Termios{Cflag: unix.CS8 | unix.BOTHER, Ospeed: 250000})
+	if other.BaudRate != 250000 {
+		t.Errorf("BOTHER BaudRate = %d, want 250000", other.BaudRate)
+	}
+}
+
+func TestParityCflag(t *testing.T) {
+	// PTYs clear the parity bits, so check what configurePort would set by
+	// decoding it like GetLineSettings does on a real UART
+	for _, parity := range []Parity{ParityNone, ParityOdd, ParityEven, ParityMark, ParitySpace} {
+		got := decodeTermios(&unix.Termios{Cflag: unix.CS8 | unix.B9600 | parityCflag(parity)})
+		if got.Parity != parity {
+			t.Errorf("parityCflag(%s) decodes as %s", parity, got.Parity)
+		}
+	}
+}
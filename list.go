@@ -95,21 +95,22 @@ func isCharacterDevice(path string) bool {
 
 // GetPortInfo returns detailed information about a serial port
 type PortInfo struct {
-	Name        string // Device name (e.g., "ttyACM0")
-	Path        string // Full device path (e.g., "/dev/ttyACM0")
-	Description string // Human-readable description
+	Name        string `json:"name"`        // Device name (e.g., "ttyACM0")
+	Path        string `json:"path"`        // Full device path (e.g., "/dev/ttyACM0")
+	Description string `json:"description"` // Human-readable description
 
 	// USB Device Information (Linux-specific, empty on other platforms)
-	VendorID        string // USB Vendor ID (hex, e.g., "1a86")
-	ProductID       string // USB Product ID (hex, e.g., "55d2")
-	SerialNumber    string // USB Serial Number (e.g., "5481031032")
-	InterfaceNumber string // USB Interface Number (hex, e.g., "02")
-	BusNumber       string // USB Bus Number (decimal, e.g., "001")
-	DeviceNumber    string // USB Device Number (decimal, e.g., "003")
+	VendorID        string `json:"vendor_id,omitempty"`        // USB Vendor ID (hex, e.g., "1a86")
+	ProductID       string `json:"product_id,omitempty"`       // USB Product ID (hex, e.g., "55d2")
+	SerialNumber    string `json:"serial_number,omitempty"`    // USB Serial Number (e.g., "5481031032")
+	InterfaceNumber string `json:"interface_number,omitempty"` // USB Interface Number (hex, e.g., "02")
+	BusNumber       string `json:"bus_number,omitempty"`       // USB Bus Number (decimal, e.g., "001")
+	DeviceNumber    string `json:"device_number,omitempty"`    // USB Device Number (decimal, e.g., "003")
 
 	// Additional metadata
-	Manufacturer string // USB Manufacturer string (if available)
-	Product      string // USB Product string (if available)
+	Manufacturer string `json:"manufacturer,omitempty"` // USB Manufacturer string (if available)
+	Product      string `json:"product,omitempty"`      // USB Product string (if available)
+	Driver       string `json:"driver,omitempty"`       // Kernel driver (e.g., "ftdi_sio", "cdc_acm")
 }
 
 // GetPortInfo returns detailed information about a specific port
@@ -126,6 +127,7 @@ func GetPortInfo(portPath string) (*PortInfo, error) {
 		Name:        name,
 		Path:        portPath,
 		Description: getPortDescription(name),
+		Driver:      readDriver(filepath.Join("/sys/class/tty", name, "device")),
 	}
 
 	// Try to get USB device information if it's a USB device
@@ -203,6 +205,29 @@ func enrichUSBInfo(info *PortInfo) {
 	info.DeviceNumber = readSysfsFile(filepath.Join(usbDevicePath, "devnum"))
 }
 
+// readDriver returns the name of the kernel driver bound to the sysfs device
+// at devicePath, or "" if none is
+// Newer kernels put platform UARTs behind serial-base port and controller
+// devices; the driver of interest is the one of the device above them.
+func readDriver(devicePath string) string {
+	// Resolve the device symlink, so that parents are sysfs parents
+	devicePath, err := filepath.EvalSymlinks(devicePath)
+	if err != nil {
+		return ""
+	}
+	for range 3 {
+		link, err := os.Readlink(filepath.Join(devicePath, "driver"))
+		if err != nil {
+			return ""
+		}
+		if filepath.Base(filepath.Dir(filepath.Dir(link))) != "serial-base" {
+			return filepath.Base(link)
+		}
+		devicePath = filepath.Dir(devicePath)
+	}
+	return ""
+}
+
 // readSysfsFile reads a single-line sysfs file and returns trimmed content
 // Returns empty string on any error (graceful degradation)
 func readSysfsFile(path string) string {
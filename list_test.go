@@ -1,6 +1,7 @@
 package serial
 
 import (
+	"encoding/json"
 	"os"
 	"regexp"
 	"strings"
@@ -111,6 +112,20 @@ func TestGetPortInfo(t *testing.T) {
 	}
 }
 
+func TestPortInfoJSON(t *testing.T) {
+	info := PortInfo{Name: "ttyUSB0", Path: "/dev/ttyUSB0", Description: "USB Serial Port", VendorID: "0403", ProductID: "6001"}
+	data, err := json.Marshal(info)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	// USB fields are omitted when empty, so non-USB ports stay compact
+	want := `{"name":"ttyUSB0","path":"/dev/ttyUSB0","description":"USB Serial Port","vendor_id":"0403","product_id":"6001"}`
+	if string(data) != want {
+		t.Errorf("Marshal = %s, want %s", data, want)
+	}
+}
+
 // TestPortFiltering tests that we correctly filter different types of devices
 func TestPortFiltering(t *testing.T) {
 	// Create test device files
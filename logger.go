@@ -0,0 +1,34 @@
+package serial
+
+import (
+	"io"
+	"log/slog"
+)
+
+// Logger receives the library's diagnostic messages
+// Arguments after msg are alternating key/value pairs, as in log/slog.
+// *slog.Logger satisfies Logger, so any slog handler can be plugged in:
+//
+//	port, err := serial.Open("/dev/ttyUSB0", serial.WithLogger(slog.Default()))
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// DiscardLogger drops all messages; it is the default Logger
+var DiscardLogger Logger = discardLogger{}
+
+type discardLogger struct{}
+
+func (discardLogger) Debug(string, ...any) {}
+func (discardLogger) Info(string, ...any)  {}
+func (discardLogger) Warn(string, ...any)  {}
+func (discardLogger) Error(string, ...any) {}
+
+// NewTextLogger returns a slog-backed Logger writing text records at level
+// and above to w
+func NewTextLogger(w io.Writer, level slog.Level) Logger {
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: level}))
+}
@@ -0,0 +1,46 @@
+package serial
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithLogger(t *testing.T) {
+	config := DefaultConfig()
+	if config.Logger != DiscardLogger {
+		t.Errorf("default Logger = %v, want DiscardLogger", config.Logger)
+	}
+	if err := WithLogger(nil)(&config); err == nil {
+		t.Error("WithLogger(nil) succeeded, want error")
+	}
+
+	master, slavePath, err := OpenPTY()
+	if err != nil {
+		t.Skipf("PTY not available: %v", err)
+	}
+	defer master.Close()
+
+	var buf bytes.Buffer
+	port, err := Open(slavePath, WithReadTimeout(100*time.Millisecond), WithLogger(NewTextLogger(&buf, slog.LevelDebug)))
+	if err != nil {
+		t.Fatalf("Open(%s) failed: %v", slavePath, err)
+	}
+	port.Close()
+
+	logged := buf.String()
+	for _, want := range []string{"port opened", "device=" + slavePath, "port closed"} {
+		if !strings.Contains(logged, want) {
+			t.Errorf("log output missing %q:\n%s", want, logged)
+		}
+	}
+
+	// Records below the level are dropped
+	buf.Reset()
+	NewTextLogger(&buf, slog.LevelWarn).Info("hidden")
+	if buf.Len() != 0 {
+		t.Errorf("info record logged at warn level: %q", buf.String())
+	}
+}
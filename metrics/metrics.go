@@ -0,0 +1,317 @@
+// Package metrics exposes port and bridge activity in the Prometheus text format
+//
+// A Registry collects counters, gauges and histograms and serves them on
+// /metrics without any Prometheus client dependency. Ports report through
+// the serial.Instrumentation returned by Registry.Instrumentation, bridges
+// through Registry.BridgeEvents:
+//
+//	reg := metrics.New()
+//	port, err := serial.Open("/dev/ttyUSB0",
+//		serial.WithInstrumentation(reg.Instrumentation("port", "/dev/ttyUSB0")))
+//
+//	l, _ := net.Listen("tcp", ":9100")
+//	go reg.Serve(ctx, l)
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are histogram upper bounds in seconds, from 1ms to the
+// default 60s CTS timeout
+var DefaultBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// Metric types
+const (
+	typeCounter   = "counter"
+	typeGauge     = "gauge"
+	typeHistogram = "histogram"
+)
+
+// Option configures a Registry
+type Option func(*Registry)
+
+// WithBuckets sets the histogram upper bounds (default DefaultBuckets)
+func WithBuckets(buckets ...float64) Option {
+	return func(r *Registry) {
+		r.buckets = slices.Sorted(slices.Values(buckets))
+	}
+}
+
+// Registry holds metric values for exposition
+// Metric names and label pairs are used as given; callers should follow
+// Prometheus naming (snake_case, counters ending in _total).
+type Registry struct {
+	buckets []float64
+
+	mu       sync.Mutex
+	families map[string]*family
+}
+
+// family is all series of one metric name
+type family struct {
+	typ    string
+	help   string
+	series map[string]*series // By rendered label set
+}
+
+type series struct {
+	labels string // Rendered without braces, e.g. port="/dev/ttyUSB0"
+	value  float64
+
+	// Histograms only
+	counts []uint64 // Per bucket, not cumulative
+	count  uint64
+}
+
+// New creates an empty Registry
+func New(opts ...Option) *Registry {
+	r := &Registry{
+		buckets:  DefaultBuckets,
+		families: make(map[string]*family),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Describe sets the help text shown for name
+func (r *Registry) Describe(name, help string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if f, ok := r.families[name]; ok {
+		f.help = help
+		return
+	}
+	r.families[name] = &family{help: help, series: make(map[string]*series)}
+}
+
+// Add increases counter name by v
+// labels are key/value pairs, e.g. Add("x_total", 1, "port", "/dev/ttyS0").
+func (r *Registry) Add(name string, v float64, labels ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if s := r.series(name, typeCounter, labels); s != nil {
+		s.value += v
+	}
+}
+
+// AddGauge changes gauge name by delta
+func (r *Registry) AddGauge(name string, delta float64, labels ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if s := r.series(name, typeGauge, labels); s != nil {
+		s.value += delta
+	}
+}
+
+// SetGauge sets gauge name to v
+func (r *Registry) SetGauge(name string, v float64, labels ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if s := r.series(name, typeGauge, labels); s != nil {
+		s.value = v
+	}
+}
+
+// Observe records v in histogram name
+func (r *Registry) Observe(name string, v float64, labels ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := r.series(name, typeHistogram, labels)
+	if s == nil {
+		return
+	}
+	if s.counts == nil {
+		s.counts = make([]uint64, len(r.buckets))
+	}
+	if i, _ := slices.BinarySearch(r.buckets, v); i < len(r.buckets) {
+		s.counts[i]++
+	}
+	s.count++
+	s.value += v
+}
+
+// Value returns the current value of a counter or gauge, or a histogram's sum
+func (r *Registry) Value(name string, labels ...string) float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if f, ok := r.families[name]; ok {
+		if s, ok := f.series[renderLabels(labels)]; ok {
+			return s.value
+		}
+	}
+	return 0
+}
+
+// series returns the series for name and labels, creating it as needed (mu held)
+// It returns nil if name is already registered with a different type.
+func (r *Registry) series(name, typ string, labels []string) *series {
+	f, ok := r.families[name]
+	if !ok {
+		f = &family{series: make(map[string]*series)}
+		r.families[name] = f
+	}
+	if f.typ == "" {
+		f.typ = typ
+	} else if f.typ != typ {
+		return nil
+	}
+
+	key := renderLabels(labels)
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labels: key}
+		f.series[key] = s
+	}
+	return s
+}
+
+// WriteTo writes every metric in the Prometheus text exposition format
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+	for _, name := range slices.Sorted(maps.Keys(r.families)) {
+		f := r.families[name]
+		if f.typ == "" {
+			continue // Described but never recorded
+		}
+		if f.help != "" {
+			fmt.Fprintf(cw, "# HELP %s %s\n", name, escapeHelp(f.help))
+		}
+		fmt.Fprintf(cw, "# TYPE %s %s\n", name, f.typ)
+
+		for _, key := range slices.Sorted(maps.Keys(f.series)) {
+			s := f.series[key]
+			if f.typ != typeHistogram {
+				fmt.Fprintf(cw, "%s%s %s\n", name, braces(s.labels), formatFloat(s.value))
+				continue
+			}
+
+			var cumulative uint64
+			for i, bound := range r.buckets {
+				if s.counts != nil {
+					cumulative += s.counts[i]
+				}
+				fmt.Fprintf(cw, "%s_bucket%s %d\n", name, braces(joinLabels(s.labels, `le="`+formatFloat(bound)+`"`)), cumulative)
+			}
+			fmt.Fprintf(cw, "%s_bucket%s %d\n", name, braces(joinLabels(s.labels, `le="+Inf"`)), s.count)
+			fmt.Fprintf(cw, "%s_sum%s %s\n", name, braces(s.labels), formatFloat(s.value))
+			fmt.Fprintf(cw, "%s_count%s %d\n", name, braces(s.labels), s.count)
+		}
+	}
+	if err := cw.w.Flush(); err != nil {
+		return cw.n, err
+	}
+	return cw.n, cw.err
+}
+
+// ServeHTTP writes the metrics as a Prometheus scrape response
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	r.WriteTo(w)
+}
+
+// Serve answers HTTP requests for /metrics on l until ctx is cancelled
+// The listener is closed when Serve returns.
+func (r *Registry) Serve(ctx context.Context, l net.Listener) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", r)
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	stop := context.AfterFunc(ctx, func() { srv.Close() })
+	defer stop()
+
+	if err := srv.Serve(l); err != nil && ctx.Err() == nil {
+		return err
+	}
+	return nil
+}
+
+// renderLabels formats key/value pairs as Prometheus labels, sorted by key
+func renderLabels(pairs []string) string {
+	if len(pairs) == 0 {
+		return ""
+	}
+	if len(pairs)%2 != 0 {
+		pairs = append(pairs, "")
+	}
+
+	labels := make([]string, 0, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		labels = append(labels, pairs[i]+`="`+escapeLabel(pairs[i+1])+`"`)
+	}
+	slices.Sort(labels)
+	return strings.Join(labels, ",")
+}
+
+func joinLabels(labels, extra string) string {
+	if labels == "" {
+		return extra
+	}
+	return labels + "," + extra
+}
+
+func braces(labels string) string {
+	if labels == "" {
+		return ""
+	}
+	return "{" + labels + "}"
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+// countingWriter tracks bytes written and keeps the first error
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
@@ -0,0 +1,158 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/allbin/go-serial"
+	"github.com/allbin/go-serial/bridge"
+)
+
+func exposition(t *testing.T, r *Registry) string {
+	t.Helper()
+	var b strings.Builder
+	if _, err := r.WriteTo(&b); err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+	return b.String()
+}
+
+func TestWriteTo(t *testing.T) {
+	r := New(WithBuckets(1, 0.1))
+	r.Describe("requests_total", "Requests handled.")
+	r.Add("requests_total", 2, "port", "b")
+	r.Add("requests_total", 1, "port", "a")
+	r.SetGauge("temperature", 21.5)
+	r.Observe("latency_seconds", 0.05, "port", `x"y`)
+	r.Observe("latency_seconds", 0.5, "port", `x"y`)
+	r.Observe("latency_seconds", 5, "port", `x"y`)
+
+	want := `# TYPE latency_seconds histogram
+latency_seconds_bucket{port="x\"y",le="0.1"} 1
+latency_seconds_bucket{port="x\"y",le="1"} 2
+latency_seconds_bucket{port="x\"y",le="+Inf"} 3
+latency_seconds_sum{port="x\"y"} 5.55
+latency_seconds_count{port="x\"y"} 3
+# HELP requests_total Requests handled.
+# TYPE requests_total counter
+requests_total{port="a"} 1
+requests_total{port="b"} 2
+# TYPE temperature gauge
+temperature 21.5
+`
+	if got := exposition(t, r); got != want {
+		t.Errorf("exposition =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestTypeMismatchIgnored(t *testing.T) {
+	r := New()
+	r.Add("x", 1)
+	r.SetGauge("x", 5)
+	if got := r.Value("x"); got != 1 {
+		t.Errorf("Value = %v, want 1", got)
+	}
+}
+
+func TestInstrumentation(t *testing.T) {
+	r := New()
+	inst := r.Instrumentation("port", "/dev/ttyS0")
+
+	_, end := inst.StartSpan(context.Background(), serial.SpanCTSWait)
+	end(nil)
+	inst.Add(context.Background(), serial.CounterBytesWritten, 42)
+	inst.Add(context.Background(), serial.CounterTimeouts, 1)
+
+	tests := []struct {
+		name string
+		want float64
+	}{
+		{BytesWrittenTotal, 42},
+		{TimeoutsTotal, 1},
+	}
+	for _, tt := range tests {
+		if got := r.Value(tt.name, "port", "/dev/ttyS0"); got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	out := exposition(t, r)
+	for _, want := range []string{
+		`serial_cts_wait_seconds_count{port="/dev/ttyS0"} 1`,
+		"# HELP serial_bytes_written_total Bytes written to the serial port.",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("exposition missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestBridgeEvents(t *testing.T) {
+	r := New()
+	handle := r.BridgeEvents("port", "gps")
+
+	for _, ev := range []bridge.Event{
+		{Kind: bridge.EventPortOpened},
+		{Kind: bridge.EventClientConnected, Addr: "a"},
+		{Kind: bridge.EventClientConnected, Addr: "b"},
+		{Kind: bridge.EventClientDisconnected, Addr: "a"},
+		{Kind: bridge.EventPortError, Err: errors.New("gone")},
+		{Kind: bridge.EventPortOpened},
+		{Kind: bridge.EventClientRejected, Addr: "c"},
+	} {
+		handle(ev)
+	}
+
+	tests := []struct {
+		name string
+		want float64
+	}{
+		{PortOpensTotal, 2},
+		{ReconnectsTotal, 1},
+		{PortErrorsTotal, 1},
+		{ClientsConnected, 1},
+		{ClientRejectionsTotal, 1},
+	}
+	for _, tt := range tests {
+		if got := r.Value(tt.name, "port", "gps"); got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestServe(t *testing.T) {
+	r := New()
+	r.Add("hits_total", 3)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- r.Serve(ctx, l) }()
+
+	resp, err := http.Get("http://" + l.Addr().String() + "/metrics")
+	if err != nil {
+		t.Fatalf("GET /metrics failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("Content-Type = %q, want text/plain", ct)
+	}
+	if !strings.Contains(string(body), "hits_total 3\n") {
+		t.Errorf("body = %q, want hits_total 3", body)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Serve returned %v, want nil", err)
+	}
+}
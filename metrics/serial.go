@@ -0,0 +1,121 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/allbin/go-serial"
+	"github.com/allbin/go-serial/bridge"
+)
+
+// Metric names recorded by Instrumentation and BridgeEvents
+const (
+	BytesReadTotal        = "serial_bytes_read_total"
+	BytesWrittenTotal     = "serial_bytes_written_total"
+	TimeoutsTotal         = "serial_timeouts_total"
+	ErrorsTotal           = "serial_errors_total"
+	OpenSeconds           = "serial_open_seconds"
+	WriteSeconds          = "serial_write_seconds"
+	CTSWaitSeconds        = "serial_cts_wait_seconds"
+	USBResetSeconds       = "serial_usb_reset_seconds"
+	PortOpensTotal        = "serial_bridge_port_opens_total"
+	ReconnectsTotal       = "serial_bridge_reconnects_total"
+	PortErrorsTotal       = "serial_bridge_port_errors_total"
+	ClientsConnected      = "serial_bridge_clients"
+	ClientRejectionsTotal = "serial_bridge_client_rejections_total"
+	DialErrorsTotal       = "serial_bridge_dial_errors_total"
+)
+
+var descriptions = map[string]string{
+	BytesReadTotal:        "Bytes read from the serial port.",
+	BytesWrittenTotal:     "Bytes written to the serial port.",
+	TimeoutsTotal:         "CTS, read and deadline timeouts.",
+	ErrorsTotal:           "Failed port operations other than timeouts.",
+	OpenSeconds:           "Time to open and configure the port.",
+	WriteSeconds:          "Write duration, including CTS waits.",
+	CTSWaitSeconds:        "Time CTS flow-controlled writes spent queued.",
+	USBResetSeconds:       "USB reset duration, including re-enumeration.",
+	PortOpensTotal:        "Successful port opens by the bridge, including the first.",
+	ReconnectsTotal:       "Port reopens after a failure.",
+	PortErrorsTotal:       "Port open, read and write failures seen by the bridge.",
+	ClientsConnected:      "Currently connected bridge clients.",
+	ClientRejectionsTotal: "Clients turned away by the bridge policy.",
+	DialErrorsTotal:       "Failed outbound connection attempts.",
+}
+
+// describe registers help texts for the well-known metric names
+func (r *Registry) describe() {
+	for name, help := range descriptions {
+		r.Describe(name, help)
+	}
+}
+
+// Instrumentation returns a serial.Instrumentation that records into r
+// Span durations become histograms named after the span (serial.cts_wait
+// becomes serial_cts_wait_seconds) and counters gain a _total suffix.
+// labels are added to every series, e.g. Instrumentation("port", "/dev/ttyUSB0").
+func (r *Registry) Instrumentation(labels ...string) serial.Instrumentation {
+	r.describe()
+	return &instrumentation{reg: r, labels: labels}
+}
+
+type instrumentation struct {
+	reg    *Registry
+	labels []string
+}
+
+func (i *instrumentation) StartSpan(ctx context.Context, name string, attrs ...slog.Attr) (context.Context, serial.EndSpanFunc) {
+	start := time.Now()
+	return ctx, func(error) {
+		i.reg.Observe(metricName(name)+"_seconds", time.Since(start).Seconds(), i.labels...)
+	}
+}
+
+func (i *instrumentation) Add(ctx context.Context, counter string, n int64, attrs ...slog.Attr) {
+	i.reg.Add(metricName(counter)+"_total", float64(n), i.labels...)
+}
+
+func metricName(name string) string {
+	return strings.ReplaceAll(name, ".", "_")
+}
+
+// BridgeEvents returns a bridge event handler that counts port opens,
+// reconnects, errors and clients
+// labels are added to every series; chain it with other handlers as needed.
+func (r *Registry) BridgeEvents(labels ...string) func(bridge.Event) {
+	r.describe()
+	return func(ev bridge.Event) {
+		switch ev.Kind {
+		case bridge.EventPortOpened:
+			r.countOpen(labels)
+		case bridge.EventPortError:
+			r.Add(PortErrorsTotal, 1, labels...)
+		case bridge.EventClientConnected:
+			r.AddGauge(ClientsConnected, 1, labels...)
+		case bridge.EventClientDisconnected:
+			r.AddGauge(ClientsConnected, -1, labels...)
+		case bridge.EventClientRejected:
+			r.Add(ClientRejectionsTotal, 1, labels...)
+		case bridge.EventDialError:
+			r.Add(DialErrorsTotal, 1, labels...)
+		}
+	}
+}
+
+// countOpen counts a port open, and a reconnect for every open after the first
+func (r *Registry) countOpen(labels []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	opens := r.series(PortOpensTotal, typeCounter, labels)
+	reconnects := r.series(ReconnectsTotal, typeCounter, labels)
+	if opens == nil || reconnects == nil {
+		return
+	}
+	if opens.value > 0 {
+		reconnects.value++
+	}
+	opens.value++
+}
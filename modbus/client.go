@@ -0,0 +1,252 @@
+package modbus
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/allbin/go-serial"
+)
+
+// Default timing values, overridable with options
+const (
+	DefaultTimeout = time.Second // Wait for a complete response
+	DefaultRetries = 2           // Resends after a request goes unanswered
+)
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithTimeout sets how long to wait for a complete response
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithRetries sets how often an unanswered request is resent
+func WithRetries(retries int) Option {
+	return func(c *Client) {
+		c.retries = retries
+	}
+}
+
+// WithTurnaround sets how long to keep the line quiet after a broadcast,
+// which devices process without answering
+func WithTurnaround(delay time.Duration) Option {
+	return func(c *Client) {
+		c.turnaround = delay
+	}
+}
+
+// Client sends Modbus RTU requests over a port, one at a time
+type Client struct {
+	port       serial.Port
+	timeout    time.Duration
+	retries    int
+	turnaround time.Duration
+
+	mu sync.Mutex // Serializes requests on the bus
+}
+
+// New creates a Client for port
+// port should have a read timeout well below the response timeout, so that
+// responses are collected promptly.
+func New(port serial.Port, opts ...Option) *Client {
+	c := &Client{
+		port:       port,
+		timeout:    DefaultTimeout,
+		retries:    DefaultRetries,
+		turnaround: 100 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// ReadCoils reads count coils starting at addr
+func (c *Client) ReadCoils(ctx context.Context, unit byte, addr, count uint16) ([]bool, error) {
+	return c.readBits(ctx, unit, ReadCoils, addr, count)
+}
+
+// ReadDiscreteInputs reads count discrete inputs starting at addr
+func (c *Client) ReadDiscreteInputs(ctx context.Context, unit byte, addr, count uint16) ([]bool, error) {
+	return c.readBits(ctx, unit, ReadDiscreteInputs, addr, count)
+}
+
+// ReadHoldingRegisters reads count holding registers starting at addr
+func (c *Client) ReadHoldingRegisters(ctx context.Context, unit byte, addr, count uint16) ([]uint16, error) {
+	return c.readRegisters(ctx, unit, ReadHoldingRegisters, addr, count)
+}
+
+// ReadInputRegisters reads count input registers starting at addr
+func (c *Client) ReadInputRegisters(ctx context.Context, unit byte, addr, count uint16) ([]uint16, error) {
+	return c.readRegisters(ctx, unit, ReadInputRegisters, addr, count)
+}
+
+// WriteSingleCoil turns the coil at addr on or off
+func (c *Client) WriteSingleCoil(ctx context.Context, unit byte, addr uint16, on bool) error {
+	value := uint16(0x0000)
+	if on {
+		value = 0xFF00
+	}
+	_, err := c.Do(ctx, unit, WriteSingleCoil, addressAndValue(addr, value))
+	return err
+}
+
+// WriteSingleRegister sets the holding register at addr
+func (c *Client) WriteSingleRegister(ctx context.Context, unit byte, addr, value uint16) error {
+	_, err := c.Do(ctx, unit, WriteSingleRegister, addressAndValue(addr, value))
+	return err
+}
+
+// WriteMultipleCoils sets consecutive coils starting at addr
+func (c *Client) WriteMultipleCoils(ctx context.Context, unit byte, addr uint16, values []bool) error {
+	if len(values) < 1 || len(values) > MaxWriteBits {
+		return fmt.Errorf("%w: %d coils (1-%d)", ErrInvalidQuantity, len(values), MaxWriteBits)
+	}
+	packed := packBits(values)
+	data := addressAndValue(addr, uint16(len(values)))
+	data = append(data, byte(len(packed)))
+	_, err := c.Do(ctx, unit, WriteMultipleCoils, append(data, packed...))
+	return err
+}
+
+// WriteMultipleRegisters sets consecutive holding registers starting at addr
+func (c *Client) WriteMultipleRegisters(ctx context.Context, unit byte, addr uint16, values []uint16) error {
+	if len(values) < 1 || len(values) > MaxWriteRegisters {
+		return fmt.Errorf("%w: %d registers (1-%d)", ErrInvalidQuantity, len(values), MaxWriteRegisters)
+	}
+	data := addressAndValue(addr, uint16(len(values)))
+	data = append(data, byte(2*len(values)))
+	for _, v := range values {
+		data = binary.BigEndian.AppendUint16(data, v)
+	}
+	_, err := c.Do(ctx, unit, WriteMultipleRegisters, data)
+	return err
+}
+
+// ReportSlaveID returns the identification data of unit
+// Its content is device-specific: usually an ID byte, a run indicator
+// (0x00 stopped, 0xFF running) and further data such as a description.
+func (c *Client) ReportSlaveID(ctx context.Context, unit byte) ([]byte, error) {
+	if unit == BroadcastUnit {
+		return nil, ErrBroadcastRead
+	}
+	data, err := c.Do(ctx, unit, ReportSlaveID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 1 || len(data) != 1+int(data[0]) {
+		return nil, fmt.Errorf("%w: %d data bytes for a byte count of %d", ErrUnexpectedFormat, len(data)-1, data[0])
+	}
+	return data[1:], nil
+}
+
+// Do sends a request with function fn and data to unit and returns the
+// data of the response
+// An exception response is returned as an *Exception. Requests to
+// BroadcastUnit return no data once the turnaround delay has passed.
+func (c *Client) Do(ctx context.Context, unit byte, fn FunctionCode, data []byte) ([]byte, error) {
+	request := EncodeRTU(unit, append([]byte{byte(fn)}, data...))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if unit == BroadcastUnit {
+		if _, err := c.port.WriteContext(ctx, request); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", fn, err)
+		}
+		if err := c.port.DrainOutput(); err != nil {
+			return nil, fmt.Errorf("failed to drain %s: %w", fn, err)
+		}
+		select {
+		case <-time.After(c.turnaround):
+			return nil, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	match := func(resp []byte) bool {
+		n := responseLength(resp)
+		return n > 0 && len(resp) >= n
+	}
+	frame, err := serial.Transact(ctx, c.port, request, match, c.retries, c.timeout)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	return parseResponse(frame[:responseLength(frame)], unit, fn)
+}
+
+// parseResponse checks a response frame against its request and returns
+// its data
+func parseResponse(frame []byte, unit byte, fn FunctionCode) ([]byte, error) {
+	gotUnit, pdu, err := DecodeRTU(frame)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	if gotUnit != unit {
+		return nil, fmt.Errorf("%w: %d, want %d", ErrUnexpectedUnit, gotUnit, unit)
+	}
+	switch pdu[0] {
+	case byte(fn):
+		return pdu[1:], nil
+	case byte(fn) | exceptionFlag:
+		return nil, &Exception{Function: fn, Code: ExceptionCode(pdu[1])}
+	default:
+		return nil, fmt.Errorf("%w: function 0x%02X, want %s", ErrUnexpectedFormat, pdu[0], fn)
+	}
+}
+
+func (c *Client) readBits(ctx context.Context, unit byte, fn FunctionCode, addr, count uint16) ([]bool, error) {
+	if err := checkRead(unit, count, MaxReadBits); err != nil {
+		return nil, err
+	}
+	data, err := c.Do(ctx, unit, fn, addressAndValue(addr, count))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 1 || int(data[0]) != (int(count)+7)/8 || len(data) != 1+int(data[0]) {
+		return nil, fmt.Errorf("%w: %d data bytes for %d bits", ErrUnexpectedFormat, len(data)-1, count)
+	}
+	return unpackBits(data[1:], int(count)), nil
+}
+
+func (c *Client) readRegisters(ctx context.Context, unit byte, fn FunctionCode, addr, count uint16) ([]uint16, error) {
+	if err := checkRead(unit, count, MaxReadRegisters); err != nil {
+		return nil, err
+	}
+	data, err := c.Do(ctx, unit, fn, addressAndValue(addr, count))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 1 || int(data[0]) != 2*int(count) || len(data) != 1+int(data[0]) {
+		return nil, fmt.Errorf("%w: %d data bytes for %d registers", ErrUnexpectedFormat, len(data)-1, count)
+	}
+	regs := make([]uint16, count)
+	for i := range regs {
+		regs[i] = binary.BigEndian.Uint16(data[1+2*i:])
+	}
+	return regs, nil
+}
+
+func checkRead(unit byte, count uint16, max int) error {
+	if unit == BroadcastUnit {
+		return ErrBroadcastRead
+	}
+	if count < 1 || int(count) > max {
+		return fmt.Errorf("%w: %d (1-%d)", ErrInvalidQuantity, count, max)
+	}
+	return nil
+}
+
+// addressAndValue encodes the address and value (or quantity) that start
+// most requests
+func addressAndValue(addr, value uint16) []byte {
+	b := binary.BigEndian.AppendUint16(make([]byte, 0, 6), addr)
+	return binary.BigEndian.AppendUint16(b, value)
+}
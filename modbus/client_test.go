@@ -0,0 +1,212 @@
+package modbus
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"os"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/allbin/go-serial"
+)
+
+// fakeDevice serves requests written to the PTY master with a register and
+// coil map, as a Modbus RTU device on unit 1 would
+type fakeDevice struct {
+	master   *os.File
+	requests chan []byte
+
+	mu        sync.Mutex // Guards the maps against tests seeding them
+	registers [16]uint16
+	coils     [16]bool
+}
+
+func newClient(t *testing.T, opts ...Option) (*Client, *fakeDevice) {
+	t.Helper()
+	master, slavePath, err := serial.OpenPTY()
+	if err != nil {
+		t.Skipf("PTY not available: %v", err)
+	}
+	t.Cleanup(func() { master.Close() })
+
+	port, err := serial.Open(slavePath, serial.WithReadTimeout(100*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Open(%s) failed: %v", slavePath, err)
+	}
+	t.Cleanup(func() { port.Close() })
+
+	dev := &fakeDevice{master: master, requests: make(chan []byte, 16)}
+	go dev.serve()
+	opts = append([]Option{WithTimeout(300 * time.Millisecond), WithRetries(0), WithTurnaround(10 * time.Millisecond)}, opts...)
+	return New(port, opts...), dev
+}
+
+func (d *fakeDevice) serve() {
+	buf := make([]byte, 256)
+	for {
+		n, err := d.master.Read(buf)
+		if err != nil {
+			return
+		}
+		req := slices.Clone(buf[:n])
+		d.requests <- req
+		unit, pdu, err := DecodeRTU(req)
+		if err != nil || unit != 1 {
+			continue
+		}
+		d.mu.Lock()
+		resp := d.handle(pdu)
+		d.mu.Unlock()
+		d.master.Write(EncodeRTU(unit, resp))
+	}
+}
+
+func (d *fakeDevice) handle(pdu []byte) []byte {
+	fn := FunctionCode(pdu[0])
+	if fn == ReportSlaveID {
+		id := append([]byte{0x2A, 0xFF}, "METER"...)
+		return append([]byte{pdu[0], byte(len(id))}, id...)
+	}
+	addr := int(binary.BigEndian.Uint16(pdu[1:]))
+	value := binary.BigEndian.Uint16(pdu[3:])
+	exception := []byte{byte(fn) | exceptionFlag, byte(IllegalDataAddress)}
+
+	switch fn {
+	case ReadHoldingRegisters:
+		if addr+int(value) > len(d.registers) {
+			return exception
+		}
+		resp := []byte{pdu[0], byte(2 * value)}
+		for _, r := range d.registers[addr : addr+int(value)] {
+			resp = binary.BigEndian.AppendUint16(resp, r)
+		}
+		return resp
+	case ReadCoils:
+		return append([]byte{pdu[0], byte((value + 7) / 8)}, packBits(d.coils[addr:addr+int(value)])...)
+	case WriteSingleRegister:
+		d.registers[addr] = value
+		return pdu
+	case WriteSingleCoil:
+		d.coils[addr] = value == 0xFF00
+		return pdu
+	case WriteMultipleRegisters:
+		for i := range int(value) {
+			d.registers[addr+i] = binary.BigEndian.Uint16(pdu[6+2*i:])
+		}
+		return pdu[:5]
+	default:
+		return []byte{byte(fn) | exceptionFlag, byte(IllegalFunction)}
+	}
+}
+
+func TestReadWriteRegisters(t *testing.T) {
+	client, dev := newClient(t)
+	dev.mu.Lock()
+	dev.registers[2] = 0x1234
+	dev.mu.Unlock()
+	ctx := context.Background()
+
+	if err := client.WriteSingleRegister(ctx, 1, 3, 0xBEEF); err != nil {
+		t.Fatalf("WriteSingleRegister failed: %v", err)
+	}
+	if err := client.WriteMultipleRegisters(ctx, 1, 4, []uint16{1, 2}); err != nil {
+		t.Fatalf("WriteMultipleRegisters failed: %v", err)
+	}
+	regs, err := client.ReadHoldingRegisters(ctx, 1, 2, 4)
+	if err != nil {
+		t.Fatalf("ReadHoldingRegisters failed: %v", err)
+	}
+	if want := []uint16{0x1234, 0xBEEF, 1, 2}; !slices.Equal(regs, want) {
+		t.Errorf("registers = %04X, want %04X", regs, want)
+	}
+}
+
+func TestReadWriteCoils(t *testing.T) {
+	client, _ := newClient(t)
+	ctx := context.Background()
+
+	if err := client.WriteSingleCoil(ctx, 1, 9, true); err != nil {
+		t.Fatalf("WriteSingleCoil failed: %v", err)
+	}
+	coils, err := client.ReadCoils(ctx, 1, 8, 3)
+	if err != nil {
+		t.Fatalf("ReadCoils failed: %v", err)
+	}
+	if want := []bool{false, true, false}; !slices.Equal(coils, want) {
+		t.Errorf("coils = %v, want %v", coils, want)
+	}
+}
+
+func TestReportSlaveID(t *testing.T) {
+	client, _ := newClient(t)
+
+	id, err := client.ReportSlaveID(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("ReportSlaveID failed: %v", err)
+	}
+	if want := append([]byte{0x2A, 0xFF}, "METER"...); !slices.Equal(id, want) {
+		t.Errorf("ID = % X, want % X", id, want)
+	}
+}
+
+func TestException(t *testing.T) {
+	client, _ := newClient(t)
+
+	_, err := client.ReadHoldingRegisters(context.Background(), 1, 10, 10)
+	var exc *Exception
+	if !errors.As(err, &exc) {
+		t.Fatalf("got %v, want an *Exception", err)
+	}
+	if exc.Function != ReadHoldingRegisters || exc.Code != IllegalDataAddress {
+		t.Errorf("exception = %+v", exc)
+	}
+}
+
+func TestNoResponse(t *testing.T) {
+	client, _ := newClient(t)
+
+	start := time.Now()
+	_, err := client.ReadHoldingRegisters(context.Background(), 2, 0, 1)
+	if !errors.Is(err, serial.ErrReadTimeout) {
+		t.Errorf("got %v, want ErrReadTimeout", err)
+	}
+	if elapsed := time.Since(start); elapsed < 300*time.Millisecond {
+		t.Errorf("returned after %v, before the timeout", elapsed)
+	}
+}
+
+func TestBroadcast(t *testing.T) {
+	client, dev := newClient(t)
+	ctx := context.Background()
+
+	if err := client.WriteSingleRegister(ctx, BroadcastUnit, 0, 5); err != nil {
+		t.Fatalf("broadcast write failed: %v", err)
+	}
+	select {
+	case req := <-dev.requests:
+		if req[0] != BroadcastUnit {
+			t.Errorf("request to unit %d", req[0])
+		}
+	case <-time.After(time.Second):
+		t.Fatal("broadcast not sent")
+	}
+
+	if _, err := client.ReadHoldingRegisters(ctx, BroadcastUnit, 0, 1); !errors.Is(err, ErrBroadcastRead) {
+		t.Errorf("broadcast read: got %v, want ErrBroadcastRead", err)
+	}
+}
+
+func TestInvalidQuantity(t *testing.T) {
+	client, _ := newClient(t)
+	ctx := context.Background()
+
+	if _, err := client.ReadHoldingRegisters(ctx, 1, 0, MaxReadRegisters+1); !errors.Is(err, ErrInvalidQuantity) {
+		t.Errorf("read: got %v, want ErrInvalidQuantity", err)
+	}
+	if err := client.WriteMultipleRegisters(ctx, 1, 0, nil); !errors.Is(err, ErrInvalidQuantity) {
+		t.Errorf("write: got %v, want ErrInvalidQuantity", err)
+	}
+}
@@ -0,0 +1,212 @@
+// Package modbus implements a Modbus RTU master (client) over a serial port.
+//
+// A Modbus RTU frame is the unit (slave) address, a protocol data unit of
+// function code and data, and a CRC-16/MODBUS sent low byte first:
+//
+//	+------+----------+----------------+-----------+
+//	| unit | function | data (0-252)   | CRC (2)   |
+//	+------+----------+----------------+-----------+
+//
+// Frames are delimited by line silence, so the client sends one request
+// and reads until the response has the length its function code implies.
+//
+//	port, _ := serial.Open("/dev/ttyUSB0",
+//	    serial.WithBaudRate(9600),
+//	    serial.WithParity(serial.ParityEven),
+//	    serial.WithReadTimeout(50*time.Millisecond),
+//	)
+//	client := modbus.New(port)
+//
+//	regs, err := client.ReadHoldingRegisters(ctx, 1, 0, 10)
+//	var exc *modbus.Exception
+//	if errors.As(err, &exc) && exc.Code == modbus.IllegalDataAddress {
+//	    // The device has no such registers
+//	}
+package modbus
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/allbin/go-serial/framing"
+)
+
+// FunctionCode identifies a Modbus request
+type FunctionCode byte
+
+// Public function codes for bit and register access
+const (
+	ReadCoils              FunctionCode = 0x01
+	ReadDiscreteInputs     FunctionCode = 0x02
+	ReadHoldingRegisters   FunctionCode = 0x03
+	ReadInputRegisters     FunctionCode = 0x04
+	WriteSingleCoil        FunctionCode = 0x05
+	WriteSingleRegister    FunctionCode = 0x06
+	WriteMultipleCoils     FunctionCode = 0x0F
+	WriteMultipleRegisters FunctionCode = 0x10
+)
+
+// ReportSlaveID asks a serial line device for its identification
+const ReportSlaveID FunctionCode = 0x11
+
+// exceptionFlag is set in the function code of an exception response
+const exceptionFlag = 0x80
+
+func (f FunctionCode) String() string {
+	switch f {
+	case ReadCoils:
+		return "ReadCoils"
+	case ReadDiscreteInputs:
+		return "ReadDiscreteInputs"
+	case ReadHoldingRegisters:
+		return "ReadHoldingRegisters"
+	case ReadInputRegisters:
+		return "ReadInputRegisters"
+	case WriteSingleCoil:
+		return "WriteSingleCoil"
+	case WriteSingleRegister:
+		return "WriteSingleRegister"
+	case WriteMultipleCoils:
+		return "WriteMultipleCoils"
+	case WriteMultipleRegisters:
+		return "WriteMultipleRegisters"
+	case ReportSlaveID:
+		return "ReportSlaveID"
+	default:
+		return fmt.Sprintf("FunctionCode(0x%02X)", byte(f))
+	}
+}
+
+// ExceptionCode is the reason a device gives for rejecting a request
+type ExceptionCode byte
+
+// Exception codes of the Modbus application protocol
+const (
+	IllegalFunction                    ExceptionCode = 0x01
+	IllegalDataAddress                 ExceptionCode = 0x02
+	IllegalDataValue                   ExceptionCode = 0x03
+	ServerDeviceFailure                ExceptionCode = 0x04
+	Acknowledge                        ExceptionCode = 0x05
+	ServerDeviceBusy                   ExceptionCode = 0x06
+	MemoryParityError                  ExceptionCode = 0x08
+	GatewayPathUnavailable             ExceptionCode = 0x0A
+	GatewayTargetDeviceFailedToRespond ExceptionCode = 0x0B
+)
+
+func (c ExceptionCode) String() string {
+	switch c {
+	case IllegalFunction:
+		return "illegal function"
+	case IllegalDataAddress:
+		return "illegal data address"
+	case IllegalDataValue:
+		return "illegal data value"
+	case ServerDeviceFailure:
+		return "server device failure"
+	case Acknowledge:
+		return "acknowledge"
+	case ServerDeviceBusy:
+		return "server device busy"
+	case MemoryParityError:
+		return "memory parity error"
+	case GatewayPathUnavailable:
+		return "gateway path unavailable"
+	case GatewayTargetDeviceFailedToRespond:
+		return "gateway target device failed to respond"
+	default:
+		return fmt.Sprintf("exception 0x%02X", byte(c))
+	}
+}
+
+// Exception is the error for an exception response from a device
+type Exception struct {
+	Function FunctionCode
+	Code     ExceptionCode
+}
+
+func (e *Exception) Error() string {
+	return fmt.Sprintf("modbus exception %d (%s) for %s", e.Code, e.Code, e.Function)
+}
+
+// Predefined errors for Modbus communication
+var (
+	ErrCRC              = errors.New("modbus CRC mismatch")
+	ErrInvalidResponse  = errors.New("invalid modbus response")
+	ErrInvalidQuantity  = errors.New("modbus quantity out of range")
+	ErrBroadcastRead    = errors.New("modbus reads cannot be broadcast")
+	ErrUnexpectedUnit   = errors.New("modbus response from another unit")
+	ErrUnexpectedFormat = errors.New("modbus response does not match request")
+)
+
+// Quantity limits per request, set by the 256-byte RTU frame
+const (
+	MaxReadBits       = 2000
+	MaxReadRegisters  = 125
+	MaxWriteBits      = 1968
+	MaxWriteRegisters = 123
+)
+
+// BroadcastUnit addresses every device; broadcast writes get no response
+const BroadcastUnit = 0
+
+// EncodeRTU returns the RTU frame carrying pdu to unit
+func EncodeRTU(unit byte, pdu []byte) []byte {
+	frame := make([]byte, 0, len(pdu)+3)
+	frame = append(frame, unit)
+	frame = append(frame, pdu...)
+	return framing.CRC16Modbus.Append(frame, frame)
+}
+
+// DecodeRTU checks the CRC of an RTU frame and returns its unit and pdu
+func DecodeRTU(frame []byte) (byte, []byte, error) {
+	if len(frame) < 4 {
+		return 0, nil, fmt.Errorf("%w: frame of %d bytes", ErrInvalidResponse, len(frame))
+	}
+	body := frame[:len(frame)-2]
+	if binary.LittleEndian.Uint16(frame[len(body):]) != framing.CRC16ModbusSum(body) {
+		return 0, nil, ErrCRC
+	}
+	return body[0], body[1:], nil
+}
+
+// responseLength returns the length of the RTU response frame starting with
+// b, or 0 if more bytes are needed to tell
+func responseLength(b []byte) int {
+	if len(b) < 2 {
+		return 0
+	}
+	if b[1]&exceptionFlag != 0 {
+		return 5
+	}
+	switch FunctionCode(b[1]) {
+	case ReadCoils, ReadDiscreteInputs, ReadHoldingRegisters, ReadInputRegisters, ReportSlaveID:
+		if len(b) < 3 {
+			return 0
+		}
+		return 3 + int(b[2]) + 2
+	default:
+		// Writes echo the address and value or quantity
+		return 8
+	}
+}
+
+// packBits packs bools into bytes, the first in the lowest bit
+func packBits(bits []bool) []byte {
+	b := make([]byte, (len(bits)+7)/8)
+	for i, on := range bits {
+		if on {
+			b[i/8] |= 1 << (i % 8)
+		}
+	}
+	return b
+}
+
+// unpackBits returns the first n bits of b
+func unpackBits(b []byte, n int) []bool {
+	bits := make([]bool, n)
+	for i := range bits {
+		bits[i] = b[i/8]&(1<<(i%8)) != 0
+	}
+	return bits
+}
@@ -0,0 +1,82 @@
+package modbus
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestEncodeRTU(t *testing.T) {
+	// Read 10 holding registers from 0 on unit 1, a common reference frame
+	got := EncodeRTU(1, []byte{0x03, 0x00, 0x00, 0x00, 0x0A})
+	want := []byte{0x01, 0x03, 0x00, 0x00, 0x00, 0x0A, 0xC5, 0xCD}
+	if !bytes.Equal(got, want) {
+		t.Errorf("EncodeRTU = % X, want % X", got, want)
+	}
+}
+
+func TestDecodeRTU(t *testing.T) {
+	frame := EncodeRTU(7, []byte{0x06, 0x00, 0x01, 0x00, 0x03})
+	unit, pdu, err := DecodeRTU(frame)
+	if err != nil {
+		t.Fatalf("DecodeRTU failed: %v", err)
+	}
+	if unit != 7 || !bytes.Equal(pdu, []byte{0x06, 0x00, 0x01, 0x00, 0x03}) {
+		t.Errorf("DecodeRTU = %d, % X", unit, pdu)
+	}
+
+	frame[3] ^= 0x01
+	if _, _, err := DecodeRTU(frame); !errors.Is(err, ErrCRC) {
+		t.Errorf("corrupted frame: got %v, want ErrCRC", err)
+	}
+	if _, _, err := DecodeRTU([]byte{0x01, 0x02}); !errors.Is(err, ErrInvalidResponse) {
+		t.Errorf("short frame: got %v, want ErrInvalidResponse", err)
+	}
+}
+
+func TestResponseLength(t *testing.T) {
+	tests := []struct {
+		name  string
+		frame []byte
+		want  int
+	}{
+		{"too short", []byte{0x01}, 0},
+		{"read without count", []byte{0x01, 0x03}, 0},
+		{"read registers", []byte{0x01, 0x03, 0x04}, 9},
+		{"read coils", []byte{0x01, 0x01, 0x01}, 6},
+		{"report slave ID", []byte{0x01, 0x11, 0x07}, 12},
+		{"exception", []byte{0x01, 0x83}, 5},
+		{"write single", []byte{0x01, 0x06}, 8},
+		{"write multiple", []byte{0x01, 0x10}, 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := responseLength(tt.frame); got != tt.want {
+				t.Errorf("responseLength(% X) = %d, want %d", tt.frame, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPackBits(t *testing.T) {
+	bits := []bool{true, false, true, true, false, false, true, false, true}
+	packed := packBits(bits)
+	if !bytes.Equal(packed, []byte{0x4D, 0x01}) {
+		t.Errorf("packBits = % X, want 4D 01", packed)
+	}
+	got := unpackBits(packed, len(bits))
+	for i := range bits {
+		if got[i] != bits[i] {
+			t.Fatalf("unpackBits = %v, want %v", got, bits)
+		}
+	}
+}
+
+func TestExceptionError(t *testing.T) {
+	var err error = &Exception{Function: ReadHoldingRegisters, Code: IllegalDataAddress}
+	want := "modbus exception 2 (illegal data address) for ReadHoldingRegisters"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
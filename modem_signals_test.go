@@ -2,6 +2,7 @@ package serial
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -268,3 +269,43 @@ func TestWaitForSignalChangeContextCancellation(t *testing.T) {
 			err, ErrPortClosed, context.Canceled)
 	}
 }
+
+// dtrRecorder records DTR transitions; other Port methods are not implemented
+type dtrRecorder struct {
+	Port
+	states  []bool
+	flushed bool
+}
+
+func (d *dtrRecorder) SetDTR(state bool) error {
+	d.states = append(d.states, state)
+	return nil
+}
+
+func (d *dtrRecorder) FlushInput() error {
+	d.flushed = true
+	return nil
+}
+
+// TestPulseDTR tests the DTR reset sequence
+func TestPulseDTR(t *testing.T) {
+	d := &dtrRecorder{}
+	start := time.Now()
+
+	if err := PulseDTR(d, 20*time.Millisecond); err != nil {
+		t.Fatalf("PulseDTR() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("PulseDTR() returned after %v, want >= 20ms", elapsed)
+	}
+	if len(d.states) != 2 || d.states[0] || !d.states[1] {
+		t.Errorf("DTR transitions = %v, want [false true]", d.states)
+	}
+	if !d.flushed {
+		t.Error("PulseDTR() should flush stale input")
+	}
+
+	if err := PulseDTR(&port{closed: true}, 0); !errors.Is(err, ErrPortClosed) {
+		t.Errorf("PulseDTR() on closed port error = %v, want %v", err, ErrPortClosed)
+	}
+}
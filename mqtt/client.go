@@ -0,0 +1,446 @@
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Predefined errors for MQTT communication
+var (
+	ErrClosed              = errors.New("MQTT client is closed")
+	ErrTimeout             = errors.New("MQTT operation timed out")
+	ErrConnectionRefused   = errors.New("MQTT connection refused")
+	ErrSubscriptionRefused = errors.New("MQTT subscription refused")
+	ErrMalformedPacket     = errors.New("malformed MQTT packet")
+	ErrInvalidQoS          = errors.New("unsupported MQTT QoS")
+)
+
+// Default timing values, overridable with options
+const (
+	DefaultKeepAlive = 60 * time.Second
+	DefaultTimeout   = 10 * time.Second // Connect, write and acknowledgement timeout
+)
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithClientID sets the MQTT client identifier (default: random "go-serial-" ID)
+func WithClientID(id string) Option {
+	return func(c *Client) {
+		c.clientID = id
+	}
+}
+
+// WithCredentials sets the user name and password sent on connect
+func WithCredentials(username, password string) Option {
+	return func(c *Client) {
+		c.username = username
+		c.password = password
+	}
+}
+
+// WithKeepAlive sets the keep-alive interval; zero disables pings
+func WithKeepAlive(interval time.Duration) Option {
+	return func(c *Client) {
+		c.keepAlive = interval
+	}
+}
+
+// WithTimeout sets how long to wait for the broker to connect or acknowledge
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// subscription routes messages matching filter to handler
+type subscription struct {
+	filter  string
+	handler func(Message)
+}
+
+// Client is a minimal MQTT 3.1.1 client
+// Subscription handlers run on the client's read goroutine and should return
+// promptly; QoS 1 messages are acknowledged after the handler returns.
+type Client struct {
+	conn      net.Conn
+	clientID  string
+	username  string
+	password  string
+	keepAlive time.Duration
+	timeout   time.Duration
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	nextID  uint16
+	pending map[uint16]chan packet // PUBACK/SUBACK waiters by packet ID
+	subs    []subscription
+	closed  bool
+	err     error
+	done    chan struct{}
+}
+
+// Dial connects to the broker at addr ("host:1883")
+func Dial(ctx context.Context, addr string, opts ...Option) (*Client, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to broker %s: %w", addr, err)
+	}
+
+	c, err := NewClient(ctx, conn, opts...)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// NewClient performs the MQTT handshake over an established connection
+// Use it for TLS or other custom transports; Dial covers plain TCP.
+func NewClient(ctx context.Context, conn net.Conn, opts ...Option) (*Client, error) {
+	c := &Client{
+		conn:      conn,
+		keepAlive: DefaultKeepAlive,
+		timeout:   DefaultTimeout,
+		pending:   make(map[uint16]chan packet),
+		done:      make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.clientID == "" {
+		c.clientID = randomClientID()
+	}
+
+	// Bound the handshake by the timeout and ctx
+	conn.SetDeadline(time.Now().Add(c.timeout))
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Unix(1, 0))
+	})
+	defer stop()
+
+	connect := encodeConnect(connectOptions{
+		clientID:  c.clientID,
+		username:  c.username,
+		password:  c.password,
+		keepAlive: uint16(c.keepAlive / time.Second),
+	})
+	if _, err := conn.Write(connect); err != nil {
+		return nil, c.handshakeErr(ctx, err)
+	}
+
+	r := bufio.NewReader(conn)
+	p, err := readPacket(r)
+	if err != nil {
+		return nil, c.handshakeErr(ctx, err)
+	}
+	if p.kind != packetConnAck || len(p.body) < 2 {
+		return nil, fmt.Errorf("%w: expected CONNACK, got packet type %d", ErrMalformedPacket, p.kind)
+	}
+	if code := p.body[1]; code != 0 {
+		return nil, &ConnectError{Code: code}
+	}
+
+	if !stop() {
+		return nil, ctx.Err()
+	}
+	conn.SetDeadline(time.Time{})
+
+	go c.readLoop(r)
+	if c.keepAlive > 0 {
+		go c.pingLoop()
+	}
+
+	return c, nil
+}
+
+// handshakeErr reports ctx cancellation and deadline expiry clearly
+func (c *Client) handshakeErr(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return fmt.Errorf("%w: no CONNACK from broker", ErrTimeout)
+	}
+	return fmt.Errorf("MQTT handshake failed: %w", err)
+}
+
+func randomClientID() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return "go-serial-" + hex.EncodeToString(b)
+}
+
+// Publish sends payload to topic
+// With AtLeastOnce it returns once the broker has acknowledged the message.
+func (c *Client) Publish(ctx context.Context, topic string, payload []byte, qos QoS, retain bool) error {
+	msg := Message{Topic: topic, Payload: payload, QoS: qos, Retain: retain}
+	switch qos {
+	case AtMostOnce:
+		return c.write(encodePublish(msg, 0))
+	case AtLeastOnce:
+		_, err := c.request(ctx, func(id uint16) []byte { return encodePublish(msg, id) })
+		return err
+	default:
+		return ErrInvalidQoS
+	}
+}
+
+// Subscribe registers handler for messages matching filter and subscribes at qos
+// Filters may use the + and # wildcards.
+func (c *Client) Subscribe(ctx context.Context, filter string, qos QoS, handler func(Message)) error {
+	if qos > AtLeastOnce {
+		return ErrInvalidQoS
+	}
+
+	// Register first so retained messages sent right after SUBACK are not missed
+	c.mu.Lock()
+	c.subs = append(c.subs, subscription{filter: filter, handler: handler})
+	c.mu.Unlock()
+
+	p, err := c.request(ctx, func(id uint16) []byte { return encodeSubscribe(id, filter, qos) })
+	if err == nil && (len(p.body) < 3 || p.body[2] == 0x80) {
+		err = fmt.Errorf("%w: %s", ErrSubscriptionRefused, filter)
+	}
+	if err != nil {
+		c.mu.Lock()
+		for i := range c.subs {
+			if c.subs[i].filter == filter {
+				c.subs = append(c.subs[:i], c.subs[i+1:]...)
+				break
+			}
+		}
+		c.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Close sends DISCONNECT and closes the connection
+func (c *Client) Close() error {
+	if !c.isClosed() {
+		c.write(encodePacket(packetDisconnect, 0, nil))
+	}
+	c.shutdown(ErrClosed)
+	return nil
+}
+
+// Done returns a channel that is closed when the connection ends
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns the error that ended the connection, if any
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// request sends a packet with a fresh packet ID and waits for its acknowledgement
+func (c *Client) request(ctx context.Context, build func(id uint16) []byte) (packet, error) {
+	reply := make(chan packet, 1)
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return packet{}, c.closedErr()
+	}
+	id := c.allocID()
+	c.pending[id] = reply
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	if err := c.write(build(id)); err != nil {
+		return packet{}, err
+	}
+
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+
+	select {
+	case p := <-reply:
+		return p, nil
+	case <-timer.C:
+		return packet{}, ErrTimeout
+	case <-ctx.Done():
+		return packet{}, ctx.Err()
+	case <-c.done:
+		return packet{}, c.closedErr()
+	}
+}
+
+// allocID returns an unused non-zero packet ID (mu held)
+func (c *Client) allocID() uint16 {
+	for {
+		c.nextID++
+		if c.nextID == 0 {
+			continue
+		}
+		if _, busy := c.pending[c.nextID]; !busy {
+			return c.nextID
+		}
+	}
+}
+
+// write sends one complete packet
+func (c *Client) write(data []byte) error {
+	if c.isClosed() {
+		return c.closedErr()
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	c.conn.SetWriteDeadline(time.Now().Add(c.timeout))
+	if _, err := c.conn.Write(data); err != nil {
+		c.shutdown(err)
+		return err
+	}
+	return nil
+}
+
+func (c *Client) closedErr() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	return ErrClosed
+}
+
+func (c *Client) shutdown(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+	c.closed = true
+	c.err = err
+	close(c.done)
+	c.conn.Close()
+}
+
+func (c *Client) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+// readLoop dispatches packets from the broker until the connection fails
+func (c *Client) readLoop(r *bufio.Reader) {
+	for {
+		if c.keepAlive > 0 {
+			// The broker answers pings, so silence for 1.5 intervals means it is gone
+			c.conn.SetReadDeadline(time.Now().Add(c.keepAlive * 3 / 2))
+		}
+
+		p, err := readPacket(r)
+		if c.isClosed() {
+			return
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			c.shutdown(err)
+			return
+		}
+
+		switch p.kind {
+		case packetPublish:
+			msg, id, err := decodePublish(p)
+			if err != nil {
+				c.shutdown(err)
+				return
+			}
+			c.dispatch(msg)
+			if msg.QoS == AtLeastOnce {
+				c.write(encodeAck(packetPubAck, id))
+			}
+		case packetPubAck, packetSubAck:
+			id, err := packetID(p)
+			if err != nil {
+				c.shutdown(err)
+				return
+			}
+			c.mu.Lock()
+			reply, ok := c.pending[id]
+			c.mu.Unlock()
+			if ok {
+				select {
+				case reply <- p:
+				default: // Duplicate acknowledgement
+				}
+			}
+		}
+	}
+}
+
+// dispatch delivers msg to every matching subscription handler
+func (c *Client) dispatch(msg Message) {
+	c.mu.Lock()
+	var handlers []func(Message)
+	for _, sub := range c.subs {
+		if matchTopic(sub.filter, msg.Topic) {
+			handlers = append(handlers, sub.handler)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler(msg)
+	}
+}
+
+// pingLoop keeps the connection alive while it is idle
+func (c *Client) pingLoop() {
+	ticker := time.NewTicker(c.keepAlive / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.write(encodePacket(packetPingReq, 0, nil))
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// matchTopic reports whether topic matches a subscription filter
+func matchTopic(filter, topic string) bool {
+	fparts := strings.Split(filter, "/")
+	tparts := strings.Split(topic, "/")
+
+	for i, part := range fparts {
+		if part == "#" {
+			return true
+		}
+		if i >= len(tparts) {
+			return false
+		}
+		if part != "+" && part != tparts[i] {
+			return false
+		}
+	}
+	return len(fparts) == len(tparts)
+}
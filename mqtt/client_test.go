@@ -0,0 +1,182 @@
+package mqtt
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeBroker answers the client's handshake, publishes and subscriptions
+type fakeBroker struct {
+	conn     net.Conn
+	received chan packet
+	connAck  byte // CONNACK return code
+}
+
+func newFakeBroker(t *testing.T, connAck byte) (*fakeBroker, net.Conn) {
+	t.Helper()
+
+	server, client := net.Pipe()
+	b := &fakeBroker{conn: server, received: make(chan packet, 16), connAck: connAck}
+	t.Cleanup(func() { server.Close() })
+
+	go b.run()
+	return b, client
+}
+
+func (b *fakeBroker) run() {
+	r := bufio.NewReader(b.conn)
+	for {
+		p, err := readPacket(r)
+		if err != nil {
+			return
+		}
+		switch p.kind {
+		case packetConnect:
+			b.conn.Write(encodePacket(packetConnAck, 0, []byte{0, b.connAck}))
+		case packetPublish:
+			if _, id, _ := decodePublish(p); id != 0 {
+				b.conn.Write(encodeAck(packetPubAck, id))
+			}
+		case packetSubscribe:
+			// Grant the requested QoS, refuse filters starting with "denied"
+			id, _ := packetID(p)
+			filter, rest, _ := readString(p.body[2:])
+			code := rest[0]
+			if strings.HasPrefix(filter, "denied") {
+				code = 0x80
+			}
+			b.conn.Write(encodePacket(packetSubAck, 0, []byte{byte(id >> 8), byte(id), code}))
+		}
+		b.received <- p
+	}
+}
+
+// next returns the next packet of kind received from the client
+func (b *fakeBroker) next(t *testing.T, kind byte) packet {
+	t.Helper()
+	for {
+		select {
+		case p := <-b.received:
+			if p.kind == kind {
+				return p
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no packet of type %d received", kind)
+		}
+	}
+}
+
+func TestPacketRoundTrip(t *testing.T) {
+	payload := bytes.Repeat([]byte{0xAB}, 200) // Two-byte remaining length
+	msg := Message{Topic: "a/b", Payload: payload, QoS: AtLeastOnce, Retain: true}
+
+	p, err := readPacket(bufio.NewReader(bytes.NewReader(encodePublish(msg, 42))))
+	if err != nil {
+		t.Fatalf("readPacket failed: %v", err)
+	}
+	got, id, err := decodePublish(p)
+	if err != nil {
+		t.Fatalf("decodePublish failed: %v", err)
+	}
+	if id != 42 || got.Topic != "a/b" || !got.Retain || got.QoS != AtLeastOnce || !bytes.Equal(got.Payload, payload) {
+		t.Errorf("decoded %+v id %d, want original message with id 42", got, id)
+	}
+
+	if _, err := readPacket(bufio.NewReader(bytes.NewReader([]byte{0x30, 0xFF, 0xFF, 0xFF, 0xFF}))); !errors.Is(err, ErrMalformedPacket) {
+		t.Errorf("oversized remaining length error = %v, want ErrMalformedPacket", err)
+	}
+}
+
+func TestMatchTopic(t *testing.T) {
+	tests := []struct {
+		filter, topic string
+		want          bool
+	}{
+		{"a/b", "a/b", true},
+		{"a/b", "a/c", false},
+		{"a/+", "a/b", true},
+		{"a/+", "a/b/c", false},
+		{"a/#", "a/b/c", true},
+		{"a/#", "a", true},
+		{"+/b", "a/b", true},
+		{"a/b/c", "a/b", false},
+	}
+
+	for _, tt := range tests {
+		if got := matchTopic(tt.filter, tt.topic); got != tt.want {
+			t.Errorf("matchTopic(%q, %q) = %v, want %v", tt.filter, tt.topic, got, tt.want)
+		}
+	}
+}
+
+func TestConnectRefused(t *testing.T) {
+	_, conn := newFakeBroker(t, 5)
+
+	_, err := NewClient(context.Background(), conn)
+	var connErr *ConnectError
+	if !errors.As(err, &connErr) || connErr.Code != 5 || !errors.Is(err, ErrConnectionRefused) {
+		t.Errorf("NewClient error = %v, want ConnectError code 5", err)
+	}
+}
+
+func TestPublishAndSubscribe(t *testing.T) {
+	broker, conn := newFakeBroker(t, 0)
+
+	client, err := NewClient(context.Background(), conn, WithClientID("test"), WithCredentials("user", "secret"))
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Close()
+
+	connect := broker.next(t, packetConnect)
+	if !bytes.Contains(connect.body, []byte("test")) || !bytes.Contains(connect.body, []byte("secret")) {
+		t.Errorf("CONNECT body % X missing client ID or password", connect.body)
+	}
+
+	// QoS 1 publish waits for PUBACK
+	if err := client.Publish(context.Background(), "dev/rx", []byte("hi"), AtLeastOnce, false); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	msg, _, _ := decodePublish(broker.next(t, packetPublish))
+	if msg.Topic != "dev/rx" || string(msg.Payload) != "hi" {
+		t.Errorf("broker received %+v", msg)
+	}
+
+	if err := client.Publish(context.Background(), "dev/rx", nil, 2, false); !errors.Is(err, ErrInvalidQoS) {
+		t.Errorf("QoS 2 publish error = %v, want ErrInvalidQoS", err)
+	}
+
+	received := make(chan Message, 1)
+	if err := client.Subscribe(context.Background(), "dev/+/cmd", AtLeastOnce, func(m Message) { received <- m }); err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	if err := client.Subscribe(context.Background(), "denied/#", AtMostOnce, func(Message) {}); !errors.Is(err, ErrSubscriptionRefused) {
+		t.Errorf("refused Subscribe error = %v, want ErrSubscriptionRefused", err)
+	}
+
+	// Messages matching the filter reach the handler and QoS 1 is acknowledged
+	broker.conn.Write(encodePublish(Message{Topic: "dev/7/cmd", Payload: []byte("go"), QoS: AtLeastOnce}, 9))
+	select {
+	case m := <-received:
+		if string(m.Payload) != "go" {
+			t.Errorf("handler got %q, want go", m.Payload)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("handler not called")
+	}
+	if id, _ := packetID(broker.next(t, packetPubAck)); id != 9 {
+		t.Errorf("PUBACK id = %d, want 9", id)
+	}
+
+	client.Close()
+	broker.next(t, packetDisconnect)
+	if err := client.Publish(context.Background(), "x", nil, AtMostOnce, false); !errors.Is(err, ErrClosed) {
+		t.Errorf("Publish after Close error = %v, want ErrClosed", err)
+	}
+}
@@ -0,0 +1,252 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/allbin/go-serial/framing"
+)
+
+// Encoding selects how frames are represented in MQTT payloads
+type Encoding int
+
+const (
+	EncodingRaw  Encoding = iota // Frame bytes as-is
+	EncodingHex                  // Lowercase hex string
+	EncodingJSON                 // {"time": ..., "hex": ..., "text": ...}
+)
+
+// String returns the encoding name as accepted by ParseEncoding
+func (e Encoding) String() string {
+	switch e {
+	case EncodingRaw:
+		return "raw"
+	case EncodingHex:
+		return "hex"
+	case EncodingJSON:
+		return "json"
+	default:
+		return fmt.Sprintf("Encoding(%d)", int(e))
+	}
+}
+
+// ParseEncoding converts an encoding name to an Encoding
+func ParseEncoding(name string) (Encoding, error) {
+	switch strings.ToLower(name) {
+	case "raw":
+		return EncodingRaw, nil
+	case "hex":
+		return EncodingHex, nil
+	case "json":
+		return EncodingJSON, nil
+	default:
+		return 0, fmt.Errorf("unknown MQTT payload encoding %q (want raw, hex or json)", name)
+	}
+}
+
+// JSONFrame is the payload format of EncodingJSON
+// Published frames carry Time, Hex and, when the frame is valid UTF-8, Text.
+// Commands set either Hex or Text; Hex takes precedence.
+type JSONFrame struct {
+	Time time.Time `json:"time,omitzero"`
+	Hex  string    `json:"hex,omitempty"`
+	Text string    `json:"text,omitempty"`
+}
+
+// ErrInvalidCommand is reported for command messages that cannot be decoded
+var ErrInvalidCommand = errors.New("invalid MQTT command payload")
+
+// GatewayOption configures a Gateway
+type GatewayOption func(*Gateway)
+
+// WithCommandTopic writes messages received on topic to the port
+func WithCommandTopic(topic string) GatewayOption {
+	return func(g *Gateway) {
+		g.commandTopic = topic
+	}
+}
+
+// WithQoS sets the QoS for publishing frames and subscribing to commands
+func WithQoS(qos QoS) GatewayOption {
+	return func(g *Gateway) {
+		g.qos = qos
+	}
+}
+
+// WithRetain publishes frames with the retain flag, so new subscribers see the latest one
+func WithRetain(retain bool) GatewayOption {
+	return func(g *Gateway) {
+		g.retain = retain
+	}
+}
+
+// WithEncoding sets the payload encoding for frames and commands
+func WithEncoding(enc Encoding) GatewayOption {
+	return func(g *Gateway) {
+		g.encoding = enc
+	}
+}
+
+// WithErrorHandler is called for per-message errors that do not stop the gateway
+// (undecodable commands, port write failures, frame checksum errors).
+func WithErrorHandler(fn func(error)) GatewayOption {
+	return func(g *Gateway) {
+		g.onError = fn
+	}
+}
+
+// Gateway publishes frames from a PacketPort and writes commands back to it
+type Gateway struct {
+	client       *Client
+	port         framing.PacketPort
+	topic        string
+	commandTopic string
+	qos          QoS
+	retain       bool
+	encoding     Encoding
+	onError      func(error)
+
+	published atomic.Uint64
+	commands  atomic.Uint64
+}
+
+// NewGateway creates a Gateway publishing frames from port to topic
+func NewGateway(client *Client, port framing.PacketPort, topic string, opts ...GatewayOption) *Gateway {
+	g := &Gateway{
+		client: client,
+		port:   port,
+		topic:  topic,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// Run pumps frames and commands until ctx is cancelled, the port fails or the
+// broker connection is lost
+func (g *Gateway) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if g.commandTopic != "" {
+		if err := g.client.Subscribe(ctx, g.commandTopic, g.qos, g.handleCommand); err != nil {
+			return fmt.Errorf("failed to subscribe to %s: %w", g.commandTopic, err)
+		}
+	}
+
+	// Stop reading the port when the broker connection ends
+	go func() {
+		select {
+		case <-g.client.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for {
+		frame, at, err := g.port.ReadPacket(ctx)
+		if err != nil {
+			var csErr *framing.ChecksumError
+			if errors.As(err, &csErr) || errors.Is(err, framing.ErrFrameTooLarge) || errors.Is(err, framing.ErrInvalidFrame) {
+				g.reportError(err)
+				continue
+			}
+			select {
+			case <-g.client.Done():
+				return g.client.Err()
+			default:
+			}
+			return err
+		}
+
+		payload, err := g.encode(frame, at)
+		if err != nil {
+			return err
+		}
+		if err := g.client.Publish(ctx, g.topic, payload, g.qos, g.retain); err != nil {
+			return fmt.Errorf("failed to publish frame: %w", err)
+		}
+		g.published.Add(1)
+	}
+}
+
+// Published returns the number of frames published
+func (g *Gateway) Published() uint64 {
+	return g.published.Load()
+}
+
+// Commands returns the number of commands written to the port
+func (g *Gateway) Commands() uint64 {
+	return g.commands.Load()
+}
+
+// encode converts a received frame to an MQTT payload
+func (g *Gateway) encode(frame []byte, at time.Time) ([]byte, error) {
+	switch g.encoding {
+	case EncodingHex:
+		return []byte(hex.EncodeToString(frame)), nil
+	case EncodingJSON:
+		jf := JSONFrame{Time: at, Hex: hex.EncodeToString(frame)}
+		if utf8.Valid(frame) {
+			jf.Text = string(frame)
+		}
+		return json.Marshal(jf)
+	default:
+		return frame, nil
+	}
+}
+
+// decode converts a command payload to the bytes written to the port
+func (g *Gateway) decode(payload []byte) ([]byte, error) {
+	switch g.encoding {
+	case EncodingHex:
+		data, err := hex.DecodeString(strings.Join(strings.Fields(string(payload)), ""))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCommand, err)
+		}
+		return data, nil
+	case EncodingJSON:
+		var jf JSONFrame
+		if err := json.Unmarshal(payload, &jf); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCommand, err)
+		}
+		if jf.Hex != "" {
+			data, err := hex.DecodeString(jf.Hex)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidCommand, err)
+			}
+			return data, nil
+		}
+		return []byte(jf.Text), nil
+	default:
+		return payload, nil
+	}
+}
+
+// handleCommand writes a command message to the port
+func (g *Gateway) handleCommand(msg Message) {
+	data, err := g.decode(msg.Payload)
+	if err != nil {
+		g.reportError(err)
+		return
+	}
+	if err := g.port.WritePacket(data); err != nil {
+		g.reportError(fmt.Errorf("failed to write command: %w", err))
+		return
+	}
+	g.commands.Add(1)
+}
+
+func (g *Gateway) reportError(err error) {
+	if g.onError != nil {
+		g.onError(err)
+	}
+}
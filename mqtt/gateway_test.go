@@ -0,0 +1,132 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/allbin/go-serial/framing"
+)
+
+// fakeDevice is the serial side: tests inject received bytes and observe writes
+type fakeDevice struct {
+	rx      *io.PipeReader
+	inject  *io.PipeWriter
+	written chan []byte
+}
+
+func newFakeDevice() *fakeDevice {
+	r, w := io.Pipe()
+	return &fakeDevice{rx: r, inject: w, written: make(chan []byte, 8)}
+}
+
+func (d *fakeDevice) Read(p []byte) (int, error) {
+	return d.rx.Read(p)
+}
+
+func (d *fakeDevice) Write(p []byte) (int, error) {
+	d.written <- append([]byte(nil), p...)
+	return len(p), nil
+}
+
+func TestEncodings(t *testing.T) {
+	at := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		enc     Encoding
+		frame   string
+		want    string
+		command string
+		cmdData string
+	}{
+		{EncodingRaw, "a\x00b", "a\x00b", "x\x01", "x\x01"},
+		{EncodingHex, "\x01\xAB", "01ab", "01 ab ff", "\x01\xAB\xFF"},
+		{EncodingJSON, "ok", `{"time":"2025-01-02T03:04:05Z","hex":"6f6b","text":"ok"}`, `{"text":"AT"}`, "AT"},
+		{EncodingJSON, "\xFF", `{"time":"2025-01-02T03:04:05Z","hex":"ff"}`, `{"hex":"0a0b","text":"ignored"}`, "\x0A\x0B"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.enc.String(), func(t *testing.T) {
+			g := NewGateway(nil, nil, "t", WithEncoding(tt.enc))
+
+			payload, err := g.encode([]byte(tt.frame), at)
+			if err != nil || string(payload) != tt.want {
+				t.Errorf("encode = %q, %v, want %q", payload, err, tt.want)
+			}
+
+			data, err := g.decode([]byte(tt.command))
+			if err != nil || string(data) != tt.cmdData {
+				t.Errorf("decode = %q, %v, want %q", data, err, tt.cmdData)
+			}
+		})
+	}
+
+	if _, err := NewGateway(nil, nil, "t", WithEncoding(EncodingHex)).decode([]byte("zz")); !errors.Is(err, ErrInvalidCommand) {
+		t.Errorf("invalid hex error = %v, want ErrInvalidCommand", err)
+	}
+	if enc, err := ParseEncoding("JSON"); err != nil || enc != EncodingJSON {
+		t.Errorf("ParseEncoding(JSON) = %v, %v", enc, err)
+	}
+}
+
+func TestGatewayRun(t *testing.T) {
+	broker, conn := newFakeBroker(t, 0)
+	client, err := NewClient(context.Background(), conn)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Close()
+
+	device := newFakeDevice()
+	pp := framing.NewPacketPort(device, framing.NewDelimited([]byte("\n")))
+	gw := NewGateway(client, pp, "meter/rx",
+		WithCommandTopic("meter/tx"),
+		WithQoS(AtLeastOnce),
+		WithEncoding(EncodingJSON),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- gw.Run(ctx) }()
+
+	broker.next(t, packetSubscribe)
+
+	// Received frames are published
+	go device.inject.Write([]byte("temp=21\n"))
+	msg, _, _ := decodePublish(broker.next(t, packetPublish))
+	var jf JSONFrame
+	if err := json.Unmarshal(msg.Payload, &jf); err != nil {
+		t.Fatalf("published payload %q is not JSON: %v", msg.Payload, err)
+	}
+	if msg.Topic != "meter/rx" || jf.Text != "temp=21" || jf.Time.IsZero() {
+		t.Errorf("published %s %+v", msg.Topic, jf)
+	}
+
+	// Commands are written to the port as frames
+	broker.conn.Write(encodePublish(Message{Topic: "meter/tx", Payload: []byte(`{"text":"READ"}`)}, 0))
+	select {
+	case got := <-device.written:
+		if string(got) != "READ\n" {
+			t.Errorf("port received %q, want READ\\n", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("command not written to port")
+	}
+
+	// The publish counter advances once the broker's PUBACK is processed
+	for deadline := time.Now().Add(time.Second); gw.Published() == 0 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	if gw.Published() != 1 || gw.Commands() != 1 {
+		t.Errorf("Published = %d, Commands = %d, want 1 and 1", gw.Published(), gw.Commands())
+	}
+
+	cancel()
+	device.inject.Close()
+	if err := <-done; !errors.Is(err, context.Canceled) && !errors.Is(err, io.EOF) {
+		t.Errorf("Run returned %v, want context.Canceled", err)
+	}
+}
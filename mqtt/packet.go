@@ -0,0 +1,238 @@
+// Package mqtt connects a serial port to an MQTT broker
+//
+// A Gateway publishes every frame received on a framing.PacketPort to a topic
+// and writes messages arriving on a command topic back to the port:
+//
+//	client, err := mqtt.Dial(ctx, "broker.local:1883", mqtt.WithClientID("meter-17"))
+//	pp := framing.NewPacketPort(port, framing.NewDelimited([]byte("\r\n")))
+//
+//	gw := mqtt.NewGateway(client, pp, "site/meter-17/rx",
+//	    mqtt.WithCommandTopic("site/meter-17/tx"),
+//	    mqtt.WithQoS(mqtt.AtLeastOnce),
+//	    mqtt.WithEncoding(mqtt.EncodingJSON),
+//	)
+//	err = gw.Run(ctx)
+//
+// The package includes a small MQTT 3.1.1 client without external
+// dependencies. It supports QoS 0 and 1, which covers telemetry and command
+// delivery; QoS 2 is not implemented.
+package mqtt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Control packet types (MQTT 3.1.1 section 2.2.1)
+const (
+	packetConnect     = 1
+	packetConnAck     = 2
+	packetPublish     = 3
+	packetPubAck      = 4
+	packetSubscribe   = 8
+	packetSubAck      = 9
+	packetPingReq     = 12
+	packetPingResp    = 13
+	packetDisconnect  = 14
+	maxRemainingBytes = 4 // Remaining length is at most four varint bytes
+)
+
+// QoS is the MQTT delivery guarantee
+type QoS byte
+
+const (
+	AtMostOnce  QoS = 0 // Fire and forget
+	AtLeastOnce QoS = 1 // Acknowledged by the broker, may be duplicated
+)
+
+// packet is a decoded control packet
+type packet struct {
+	kind  byte
+	flags byte
+	body  []byte
+}
+
+// readPacket reads one control packet
+func readPacket(r *bufio.Reader) (packet, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return packet{}, err
+	}
+
+	var length, shift int
+	for i := 0; ; i++ {
+		if i == maxRemainingBytes {
+			return packet{}, fmt.Errorf("%w: remaining length too long", ErrMalformedPacket)
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			return packet{}, err
+		}
+		length |= int(b&0x7F) << shift
+		shift += 7
+		if b&0x80 == 0 {
+			break
+		}
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return packet{}, err
+	}
+	return packet{kind: header >> 4, flags: header & 0x0F, body: body}, nil
+}
+
+// encodePacket builds a control packet from its parts
+func encodePacket(kind, flags byte, body []byte) []byte {
+	out := []byte{kind<<4 | flags}
+	length := len(body)
+	for {
+		b := byte(length & 0x7F)
+		length >>= 7
+		if length > 0 {
+			b |= 0x80
+		}
+		out = append(out, b)
+		if length == 0 {
+			break
+		}
+	}
+	return append(out, body...)
+}
+
+// appendString appends a length-prefixed UTF-8 string
+func appendString(dst []byte, s string) []byte {
+	dst = binary.BigEndian.AppendUint16(dst, uint16(len(s)))
+	return append(dst, s...)
+}
+
+// readString splits a length-prefixed string from the front of data
+func readString(data []byte) (string, []byte, error) {
+	if len(data) < 2 {
+		return "", nil, ErrMalformedPacket
+	}
+	n := int(binary.BigEndian.Uint16(data))
+	if len(data) < 2+n {
+		return "", nil, ErrMalformedPacket
+	}
+	return string(data[2 : 2+n]), data[2+n:], nil
+}
+
+// connectOptions are the fields of a CONNECT packet
+type connectOptions struct {
+	clientID  string
+	username  string
+	password  string
+	keepAlive uint16 // Seconds
+}
+
+func encodeConnect(o connectOptions) []byte {
+	flags := byte(0x02) // Clean session
+	if o.username != "" {
+		flags |= 0x80
+	}
+	if o.password != "" {
+		flags |= 0x40
+	}
+
+	body := appendString(nil, "MQTT")
+	body = append(body, 4, flags) // Protocol level 4 = 3.1.1
+	body = binary.BigEndian.AppendUint16(body, o.keepAlive)
+	body = appendString(body, o.clientID)
+	if o.username != "" {
+		body = appendString(body, o.username)
+	}
+	if o.password != "" {
+		body = appendString(body, o.password)
+	}
+	return encodePacket(packetConnect, 0, body)
+}
+
+// Message is an application message received from the broker
+type Message struct {
+	Topic   string
+	Payload []byte
+	QoS     QoS
+	Retain  bool
+}
+
+func encodePublish(msg Message, id uint16) []byte {
+	flags := byte(msg.QoS) << 1
+	if msg.Retain {
+		flags |= 0x01
+	}
+	body := appendString(nil, msg.Topic)
+	if msg.QoS > AtMostOnce {
+		body = binary.BigEndian.AppendUint16(body, id)
+	}
+	body = append(body, msg.Payload...)
+	return encodePacket(packetPublish, flags, body)
+}
+
+// decodePublish parses a PUBLISH packet; id is zero for QoS 0
+func decodePublish(p packet) (Message, uint16, error) {
+	topic, rest, err := readString(p.body)
+	if err != nil {
+		return Message{}, 0, err
+	}
+	msg := Message{Topic: topic, QoS: QoS(p.flags>>1) & 0x03, Retain: p.flags&0x01 != 0}
+
+	var id uint16
+	if msg.QoS > AtMostOnce {
+		if len(rest) < 2 {
+			return Message{}, 0, ErrMalformedPacket
+		}
+		id = binary.BigEndian.Uint16(rest)
+		rest = rest[2:]
+	}
+	msg.Payload = rest
+	return msg, id, nil
+}
+
+func encodeSubscribe(id uint16, filter string, qos QoS) []byte {
+	body := binary.BigEndian.AppendUint16(nil, id)
+	body = appendString(body, filter)
+	body = append(body, byte(qos))
+	return encodePacket(packetSubscribe, 0x02, body)
+}
+
+// encodeAck builds PUBACK and other packets that carry only a packet ID
+func encodeAck(kind byte, id uint16) []byte {
+	return encodePacket(kind, 0, binary.BigEndian.AppendUint16(nil, id))
+}
+
+// packetID returns the packet identifier at the start of an ack body
+func packetID(p packet) (uint16, error) {
+	if len(p.body) < 2 {
+		return 0, ErrMalformedPacket
+	}
+	return binary.BigEndian.Uint16(p.body), nil
+}
+
+// connectReturnCodes describes CONNACK refusals
+var connectReturnCodes = map[byte]string{
+	1: "unacceptable protocol version",
+	2: "identifier rejected",
+	3: "server unavailable",
+	4: "bad user name or password",
+	5: "not authorized",
+}
+
+// ConnectError is returned when the broker refuses the connection
+type ConnectError struct {
+	Code byte
+}
+
+func (e *ConnectError) Error() string {
+	if reason, ok := connectReturnCodes[e.Code]; ok {
+		return fmt.Sprintf("broker refused connection: %s", reason)
+	}
+	return fmt.Sprintf("broker refused connection: code %d", e.Code)
+}
+
+// Unwrap allows errors.Is(err, ErrConnectionRefused)
+func (e *ConnectError) Unwrap() error {
+	return ErrConnectionRefused
+}
@@ -0,0 +1,488 @@
+// Package mux shares one serial port among several clients
+//
+// Every attached client receives all data read from the port. A transmit
+// policy decides which clients may write, so a debugging session can have
+// one writer and any number of watchers:
+//
+//	m := mux.New(port, mux.WithPolicy(mux.PolicyClaim))
+//	go m.Run(ctx)
+//
+//	logger, _ := m.Attach("logger", mux.ReadOnly())
+//	go io.Copy(logFile, logger)
+//
+//	l, _ := net.Listen("tcp", ":5000")
+//	go m.Serve(ctx, l) // Network clients attach too
+//
+// Clients are independent: one that stops reading misses data once its queue
+// fills instead of stalling the port or the other clients.
+package mux
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/allbin/go-serial"
+)
+
+// Predefined errors for multiplexing
+var (
+	ErrNotWriter = errors.New("client may not transmit")
+	ErrClosed    = errors.New("mux is closed")
+)
+
+// Default values, overridable with options
+const (
+	DefaultReadBufferSize = 4096
+	DefaultClientQueue    = 64 // Chunks queued per client before data is dropped
+)
+
+// Policy decides which clients may write to the port
+type Policy int
+
+const (
+	// PolicyFirst lets only the longest-attached writable client transmit
+	PolicyFirst Policy = iota
+	// PolicyClaim gives the transmit right to the first client that writes;
+	// it keeps it until it detaches or calls Release
+	PolicyClaim
+	// PolicyAll lets every writable client transmit; writes are not interleaved
+	PolicyAll
+)
+
+// String returns the policy name as accepted by ParsePolicy
+func (p Policy) String() string {
+	switch p {
+	case PolicyFirst:
+		return "first"
+	case PolicyClaim:
+		return "claim"
+	case PolicyAll:
+		return "all"
+	default:
+		return fmt.Sprintf("Policy(%d)", int(p))
+	}
+}
+
+// ParsePolicy converts a policy name to a Policy
+func ParsePolicy(name string) (Policy, error) {
+	switch name {
+	case "first":
+		return PolicyFirst, nil
+	case "claim":
+		return PolicyClaim, nil
+	case "all":
+		return PolicyAll, nil
+	default:
+		return 0, fmt.Errorf("unknown mux policy %q (want first, claim or all)", name)
+	}
+}
+
+// ClientStats are one client's cumulative counters
+type ClientStats struct {
+	ID       int
+	Name     string
+	Attached time.Time
+	ReadOnly bool
+	Writer   bool   // Currently holds the transmit right
+	RXBytes  uint64 // Port data queued for the client
+	TXBytes  uint64 // Client data written to the port
+	Dropped  uint64 // Port data lost because the client's queue was full
+	Denied   uint64 // Client data refused by the transmit policy
+}
+
+// Option configures a Mux
+type Option func(*Mux)
+
+// WithPolicy sets the transmit policy (default PolicyFirst)
+func WithPolicy(policy Policy) Option {
+	return func(m *Mux) {
+		m.policy = policy
+	}
+}
+
+// WithReadBufferSize sets the size of port reads
+func WithReadBufferSize(size int) Option {
+	return func(m *Mux) {
+		m.readSize = size
+	}
+}
+
+// WithClientQueue sets how many received chunks are queued for each client
+func WithClientQueue(chunks int) Option {
+	return func(m *Mux) {
+		m.queue = chunks
+	}
+}
+
+// ClientOption configures an attached client
+type ClientOption func(*Client)
+
+// ReadOnly attaches a watcher that never transmits
+func ReadOnly() ClientOption {
+	return func(c *Client) {
+		c.readOnly = true
+	}
+}
+
+// Mux distributes one port's data to attached clients
+type Mux struct {
+	port     serial.Port
+	policy   Policy
+	readSize int
+	queue    int
+
+	writeMu sync.Mutex // Keeps writes from different clients whole
+
+	mu      sync.Mutex
+	clients []*Client // In attach order
+	claimed *Client   // Writer under PolicyClaim
+	nextID  int
+	closed  bool
+	err     error
+	done    chan struct{}
+}
+
+// New creates a Mux for port
+// The port should be opened with a read timeout so Run can observe
+// cancellation between reads.
+func New(port serial.Port, opts ...Option) *Mux {
+	m := &Mux{
+		port:     port,
+		policy:   PolicyFirst,
+		readSize: DefaultReadBufferSize,
+		queue:    DefaultClientQueue,
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Run reads from the port and broadcasts to clients until ctx is cancelled
+// or the port fails. All clients are detached when Run returns; the port is
+// left open for the caller to close.
+func (m *Mux) Run(ctx context.Context) error {
+	// Clients share each chunk, read straight into memory they may keep
+	slab := serial.NewReadSlab(16 * m.readSize)
+	for {
+		n, err := m.port.ReadContext(ctx, slab.Next(m.readSize))
+		if n > 0 {
+			m.broadcast(slab.Take(n))
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				err = ctx.Err()
+			}
+			m.shutdown(err)
+			return err
+		}
+	}
+}
+
+// Done returns a channel that is closed when Run returns
+func (m *Mux) Done() <-chan struct{} {
+	return m.done
+}
+
+// Err returns the error that stopped Run, if any
+func (m *Mux) Err() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.err
+}
+
+// Attach adds a client named name (for statistics)
+func (m *Mux) Attach(name string, opts ...ClientOption) (*Client, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return nil, ErrClosed
+	}
+
+	m.nextID++
+	c := &Client{
+		mux:  m,
+		out:  make(chan []byte, m.queue),
+		done: make(chan struct{}),
+		stats: ClientStats{
+			ID:       m.nextID,
+			Name:     name,
+			Attached: time.Now(),
+		},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.stats.ReadOnly = c.readOnly
+	m.clients = append(m.clients, c)
+	return c, nil
+}
+
+// Clients returns statistics for the attached clients in attach order
+// After Run returns it reports the clients that were attached when it stopped.
+func (m *Mux) Clients() []ClientStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := make([]ClientStats, len(m.clients))
+	for i, c := range m.clients {
+		stats[i] = c.stats
+		stats[i].Writer = m.isWriter(c)
+	}
+	return stats
+}
+
+// Serve attaches each connection accepted from l as a client until ctx is
+// cancelled or l fails. Input from connections that may not transmit is
+// discarded and counted as denied. The listener is closed when Serve returns.
+func (m *Mux) Serve(ctx context.Context, l net.Listener, opts ...ClientOption) error {
+	ctx, cancel := context.WithCancel(ctx)
+
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-m.done:
+		}
+		l.Close()
+	}()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil || m.isClosed() {
+				return nil
+			}
+			return err
+		}
+
+		c, err := m.Attach(conn.RemoteAddr().String(), opts...)
+		if err != nil {
+			conn.Close()
+			return nil
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			serveConn(ctx, c, conn)
+		}()
+	}
+}
+
+// serveConn pumps data between conn and c until either side closes
+func serveConn(ctx context.Context, c *Client, conn net.Conn) {
+	stop := context.AfterFunc(ctx, func() { c.Close() })
+	defer stop()
+
+	// Port data to the connection; closing conn ends the input loop below
+	go func() {
+		io.Copy(conn, c)
+		conn.Close()
+	}()
+
+	buf := make([]byte, DefaultReadBufferSize)
+	for {
+		n, err := conn.Read(buf)
+		if n > 0 {
+			if _, werr := c.Write(buf[:n]); werr != nil && !errors.Is(werr, ErrNotWriter) {
+				break
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	c.Close()
+	conn.Close()
+}
+
+// isWriter reports whether c may transmit now (mu held)
+func (m *Mux) isWriter(c *Client) bool {
+	if c.readOnly {
+		return false
+	}
+	switch m.policy {
+	case PolicyAll:
+		return true
+	case PolicyClaim:
+		return m.claimed == c
+	default:
+		for _, other := range m.clients {
+			if !other.readOnly {
+				return other == c
+			}
+		}
+		return false
+	}
+}
+
+// broadcast queues data for every attached client
+func (m *Mux) broadcast(data []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, c := range m.clients {
+		select {
+		case c.out <- data:
+			c.stats.RXBytes += uint64(len(data))
+		default:
+			c.stats.Dropped += uint64(len(data))
+		}
+	}
+}
+
+func (m *Mux) detach(c *Client) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return // Keep the final client list for Clients
+	}
+
+	for i, other := range m.clients {
+		if other == c {
+			m.clients = append(m.clients[:i], m.clients[i+1:]...)
+			break
+		}
+	}
+	if m.claimed == c {
+		m.claimed = nil
+	}
+}
+
+func (m *Mux) shutdown(err error) {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return
+	}
+	m.closed = true
+	m.err = err
+	clients := m.clients
+	close(m.done)
+	m.mu.Unlock()
+
+	for _, c := range clients {
+		c.closeOnce.Do(func() { close(c.done) })
+	}
+}
+
+func (m *Mux) isClosed() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.closed
+}
+
+// Client is one consumer attached to a Mux
+// Read returns port data; Write transmits if the policy allows it.
+// A Client must not be read from concurrently.
+type Client struct {
+	mux      *Mux
+	readOnly bool
+	out      chan []byte
+	pending  []byte // Unread remainder of the last chunk
+
+	done      chan struct{}
+	closeOnce sync.Once
+
+	stats ClientStats // Guarded by mux.mu
+}
+
+// Read returns data received on the port
+// It returns io.EOF once the client is closed or the mux stops.
+func (c *Client) Read(p []byte) (int, error) {
+	if len(c.pending) == 0 {
+		select {
+		case data := <-c.out:
+			c.pending = data
+		case <-c.done:
+			// Deliver what was queued before closing
+			select {
+			case data := <-c.out:
+				c.pending = data
+			default:
+				return 0, io.EOF
+			}
+		}
+	}
+
+	n := copy(p, c.pending)
+	c.pending = c.pending[n:]
+	return n, nil
+}
+
+// Write transmits p to the port
+// It returns ErrNotWriter when the policy does not allow this client to transmit.
+func (c *Client) Write(p []byte) (int, error) {
+	m := c.mux
+
+	m.mu.Lock()
+	select {
+	case <-c.done:
+		m.mu.Unlock()
+		return 0, ErrClosed
+	default:
+	}
+	if m.policy == PolicyClaim && m.claimed == nil && !c.readOnly {
+		m.claimed = c
+	}
+	if !m.isWriter(c) {
+		c.stats.Denied += uint64(len(p))
+		m.mu.Unlock()
+		return 0, ErrNotWriter
+	}
+	m.mu.Unlock()
+
+	m.writeMu.Lock()
+	n, err := m.port.Write(p)
+	m.writeMu.Unlock()
+
+	m.mu.Lock()
+	c.stats.TXBytes += uint64(n)
+	m.mu.Unlock()
+	return n, err
+}
+
+// Release gives up the transmit right under PolicyClaim
+func (c *Client) Release() {
+	m := c.mux
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.claimed == c {
+		m.claimed = nil
+	}
+}
+
+// Stats returns the client's counters
+func (c *Client) Stats() ClientStats {
+	m := c.mux
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := c.stats
+	stats.Writer = m.isWriter(c)
+	return stats
+}
+
+// Close detaches the client from the mux
+func (c *Client) Close() error {
+	c.closeOnce.Do(func() {
+		c.mux.detach(c)
+		close(c.done)
+	})
+	return nil
+}
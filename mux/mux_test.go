@@ -0,0 +1,211 @@
+package mux
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/allbin/go-serial"
+)
+
+// startMux runs a Mux on a PTY until the test ends and returns the PTY master
+func startMux(t *testing.T, opts ...Option) (*Mux, *os.File) {
+	t.Helper()
+
+	master, slavePath, err := serial.OpenPTY()
+	if err != nil {
+		t.Skipf("PTY not available: %v", err)
+	}
+	t.Cleanup(func() { master.Close() })
+
+	port, err := serial.Open(slavePath, serial.WithReadTimeout(100*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Open(%s) failed: %v", slavePath, err)
+	}
+	t.Cleanup(func() { port.Close() })
+
+	m := New(port, opts...)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- m.Run(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; !errors.Is(err, context.Canceled) {
+			t.Errorf("Run returned %v, want context.Canceled", err)
+		}
+	})
+
+	return m, master
+}
+
+// attach adds a client and fails the test on error
+func attach(t *testing.T, m *Mux, name string, opts ...ClientOption) *Client {
+	t.Helper()
+
+	c, err := m.Attach(name, opts...)
+	if err != nil {
+		t.Fatalf("Attach(%s) failed: %v", name, err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// readString reads exactly len(want) bytes from r with a deadline
+func readString(t *testing.T, r io.Reader, want string) {
+	t.Helper()
+
+	got := make(chan string, 1)
+	go func() {
+		buf := make([]byte, len(want))
+		n, _ := io.ReadFull(r, buf)
+		got <- string(buf[:n])
+	}()
+
+	select {
+	case s := <-got:
+		if s != want {
+			t.Errorf("read %q, want %q", s, want)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out reading %q", want)
+	}
+}
+
+// waitFor polls cond until it is true or the deadline passes
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if cond() {
+			return
+		}
+	}
+	t.Fatal("condition not met within 2s")
+}
+
+func TestPolicyFirst(t *testing.T) {
+	m, master := startMux(t)
+
+	watcher := attach(t, m, "watcher", ReadOnly())
+	writer := attach(t, m, "writer")
+	other := attach(t, m, "other")
+
+	// Received data goes to every client
+	master.Write([]byte("hello"))
+	for _, c := range []*Client{watcher, writer, other} {
+		readString(t, c, "hello")
+	}
+
+	// Only the first writable client transmits
+	if _, err := watcher.Write([]byte("x")); !errors.Is(err, ErrNotWriter) {
+		t.Errorf("read-only Write error = %v, want ErrNotWriter", err)
+	}
+	if _, err := other.Write([]byte("x")); !errors.Is(err, ErrNotWriter) {
+		t.Errorf("second client Write error = %v, want ErrNotWriter", err)
+	}
+	if _, err := writer.Write([]byte("cmd")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	readString(t, master, "cmd")
+
+	// The next client takes over when the writer leaves
+	writer.Close()
+	if _, err := other.Write([]byte("next")); err != nil {
+		t.Fatalf("Write after writer left failed: %v", err)
+	}
+	readString(t, master, "next")
+
+	stats := m.Clients()
+	if len(stats) != 2 {
+		t.Fatalf("Clients() returned %d entries, want 2", len(stats))
+	}
+	if got := stats[1]; got.Name != "other" || !got.Writer || got.RXBytes != 5 || got.TXBytes != 4 || got.Denied != 1 {
+		t.Errorf("other stats = %+v", got)
+	}
+	if got := stats[0]; !got.ReadOnly || got.Writer || got.Denied != 1 {
+		t.Errorf("watcher stats = %+v", got)
+	}
+}
+
+func TestPolicyClaim(t *testing.T) {
+	m, master := startMux(t, WithPolicy(PolicyClaim))
+
+	a := attach(t, m, "a")
+	b := attach(t, m, "b")
+
+	// The first client to write holds the transmit right
+	if _, err := b.Write([]byte("b1")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	readString(t, master, "b1")
+	if _, err := a.Write([]byte("a1")); !errors.Is(err, ErrNotWriter) {
+		t.Errorf("Write while b holds the claim error = %v, want ErrNotWriter", err)
+	}
+
+	b.Release()
+	if _, err := a.Write([]byte("a2")); err != nil {
+		t.Fatalf("Write after Release failed: %v", err)
+	}
+	readString(t, master, "a2")
+	if !a.Stats().Writer || b.Stats().Writer {
+		t.Errorf("writer flags a=%v b=%v, want a only", a.Stats().Writer, b.Stats().Writer)
+	}
+}
+
+func TestSlowClientDrops(t *testing.T) {
+	m, master := startMux(t, WithClientQueue(1))
+
+	slow := attach(t, m, "slow")
+	fast := attach(t, m, "fast")
+
+	// The slow client never reads; the fast one still sees everything
+	for _, chunk := range []string{"one", "two", "three"} {
+		master.Write([]byte(chunk))
+		readString(t, fast, chunk)
+	}
+
+	stats := slow.Stats()
+	if stats.RXBytes != 3 || stats.Dropped != 8 {
+		t.Errorf("slow stats = %+v, want 3 RX and 8 dropped bytes", stats)
+	}
+}
+
+func TestServe(t *testing.T) {
+	m, master := startMux(t)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("TCP listener not available: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- m.Serve(ctx, l) }()
+	defer func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Serve returned %v", err)
+		}
+	}()
+
+	local := attach(t, m, "local", ReadOnly())
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+	waitFor(t, func() bool { return len(m.Clients()) == 2 })
+
+	// Network and local clients share the port
+	master.Write([]byte("ping"))
+	readString(t, conn, "ping")
+	readString(t, local, "ping")
+
+	conn.Write([]byte("pong"))
+	readString(t, master, "pong")
+
+	conn.Close()
+	waitFor(t, func() bool { return len(m.Clients()) == 1 })
+}
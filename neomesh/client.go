@@ -0,0 +1,443 @@
+package neomesh
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/allbin/go-serial/framing"
+)
+
+// Predefined errors for NeoMesh communication
+var (
+	ErrHostNack         = errors.New("module rejected command")
+	ErrNotAcknowledged  = errors.New("destination did not acknowledge packet")
+	ErrTimeout          = errors.New("NeoMesh command timed out")
+	ErrClosed           = errors.New("NeoMesh client is closed")
+	ErrPayloadTooLarge  = errors.New("payload exceeds NeoMesh maximum")
+	ErrMalformedMessage = errors.New("malformed NeoMesh message")
+)
+
+// Default timing values, overridable with options
+const (
+	DefaultTimeout    = 1 * time.Second  // Wait for HostAck/HostNack or a direct reply
+	DefaultAckTimeout = 30 * time.Second // Wait for end-to-end Ack/Nack of an acknowledged send
+	DefaultRetries    = 3                // Resends after HostNack
+	DefaultRetryDelay = 10 * time.Millisecond
+)
+
+// receiveBufferSize is the capacity of the incoming packet queue; packets are dropped when it is full
+const receiveBufferSize = 64
+
+// packageAgeUnit is the resolution of the package age field in HostData messages
+const packageAgeUnit = 125 * time.Millisecond
+
+// Packet is application data received from another node
+type Packet struct {
+	Source  uint16        // Originating node ID
+	Port    uint8         // Destination application port
+	Age     time.Duration // Time the packet spent in the network
+	Payload []byte
+}
+
+// NodeInfo describes the locally attached module
+type NodeInfo struct {
+	NodeID   uint16
+	UniqueID []byte // Factory-programmed unique ID, as reported by the module
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithTimeout sets how long to wait for the module to accept a command
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithAckTimeout sets how long SendAcknowledged waits for the destination's Ack or Nack
+func WithAckTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.ackTimeout = timeout
+	}
+}
+
+// WithRetries sets how often a command is resent after HostNack, waiting delay between attempts
+func WithRetries(retries int, delay time.Duration) Option {
+	return func(c *Client) {
+		c.retries = retries
+		c.retryDelay = delay
+	}
+}
+
+// Client exchanges Application API messages with a NeoMesh module
+//
+// The module lowers CTS while it processes a command, so only one command is
+// in flight at a time: each frame is written with a single Write and the next
+// command is held back until the module answers with HostAck or HostNack.
+type Client struct {
+	port       framing.PacketPort
+	timeout    time.Duration
+	ackTimeout time.Duration
+	retries    int
+	retryDelay time.Duration
+
+	cmdMu sync.Mutex // Serializes host commands
+
+	mu      sync.Mutex
+	pending *pendingCommand
+	acks    map[uint16][]chan bool // Acknowledged sends awaiting Ack/Nack, per destination
+	data    chan Packet
+	dropped uint64
+	closed  bool
+	err     error
+	done    chan struct{}
+	cancel  context.CancelFunc
+}
+
+type pendingCommand struct {
+	expect MessageType // Reply that completes the command (HostAck or a direct reply)
+	reply  chan Frame
+}
+
+// New creates a Client and starts reading from rw in a background goroutine
+// rw should be a serial.Port opened with FlowControlCTS and a read timeout so
+// the reader goroutine can observe Close.
+func New(rw io.ReadWriter, opts ...Option) *Client {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &Client{
+		port:       framing.NewPacketPort(rw, Codec{}),
+		timeout:    DefaultTimeout,
+		ackTimeout: DefaultAckTimeout,
+		retries:    DefaultRetries,
+		retryDelay: DefaultRetryDelay,
+		acks:       make(map[uint16][]chan bool),
+		data:       make(chan Packet, receiveBufferSize),
+		done:       make(chan struct{}),
+		cancel:     cancel,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	go c.readLoop(ctx)
+
+	return c
+}
+
+// SendUnacknowledged sends payload to port on node dest without end-to-end acknowledgement
+// Returns once the module has accepted the packet for transmission.
+func (c *Client) SendUnacknowledged(ctx context.Context, dest uint16, port uint8, payload []byte) error {
+	msg, err := sendPayload(dest, port, payload)
+	if err != nil {
+		return err
+	}
+	_, err = c.command(ctx, Frame{Type: MsgUnacknowledgedSend, Payload: msg}, MsgHostAck)
+	return err
+}
+
+// SendAcknowledged sends payload to port on node dest and waits for the destination's acknowledgement
+// Returns ErrNotAcknowledged if the network reports the packet as undeliverable.
+func (c *Client) SendAcknowledged(ctx context.Context, dest uint16, port uint8, payload []byte) error {
+	msg, err := sendPayload(dest, port, payload)
+	if err != nil {
+		return err
+	}
+
+	// Register before sending; the Ack may arrive before command returns
+	ack := make(chan bool, 1)
+	c.mu.Lock()
+	c.acks[dest] = append(c.acks[dest], ack)
+	c.mu.Unlock()
+
+	if _, err := c.command(ctx, Frame{Type: MsgAcknowledgedSend, Payload: msg}, MsgHostAck); err != nil {
+		c.removeAck(dest, ack)
+		return err
+	}
+
+	timer := time.NewTimer(c.ackTimeout)
+	defer timer.Stop()
+
+	select {
+	case ok := <-ack:
+		if !ok {
+			return fmt.Errorf("%w: node 0x%04X", ErrNotAcknowledged, dest)
+		}
+		return nil
+	case <-timer.C:
+		c.removeAck(dest, ack)
+		return fmt.Errorf("%w: no acknowledgement from node 0x%04X", ErrTimeout, dest)
+	case <-ctx.Done():
+		c.removeAck(dest, ack)
+		return ctx.Err()
+	case <-c.done:
+		return c.closedErr()
+	}
+}
+
+// NodeInfo queries the attached module for its node ID and unique ID
+func (c *Client) NodeInfo(ctx context.Context) (*NodeInfo, error) {
+	reply, err := c.command(ctx, Frame{Type: MsgNodeInfoRequest}, MsgNodeInfo)
+	if err != nil {
+		return nil, err
+	}
+	if len(reply.Payload) < 2 {
+		return nil, fmt.Errorf("%w: node info reply of %d bytes", ErrMalformedMessage, len(reply.Payload))
+	}
+	return &NodeInfo{
+		NodeID:   binary.BigEndian.Uint16(reply.Payload),
+		UniqueID: append([]byte(nil), reply.Payload[2:]...),
+	}, nil
+}
+
+// Receive returns the next packet received from the network
+func (c *Client) Receive(ctx context.Context) (*Packet, error) {
+	select {
+	case p := <-c.data:
+		return &p, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.done:
+		// Drain packets that arrived before shutdown
+		select {
+		case p := <-c.data:
+			return &p, nil
+		default:
+			return nil, c.closedErr()
+		}
+	}
+}
+
+// Dropped returns the number of received packets discarded because Receive was not keeping up
+func (c *Client) Dropped() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.dropped
+}
+
+// Close stops the client and fails any command in flight
+// Close does not close the underlying port.
+func (c *Client) Close() error {
+	c.shutdown(ErrClosed)
+	return nil
+}
+
+// Done returns a channel that is closed when the client stops reading
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns the error that stopped the client, if any
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// command writes f and waits for the expected reply, resending after HostNack
+func (c *Client) command(ctx context.Context, f Frame, expect MessageType) (Frame, error) {
+	msg := make([]byte, 0, len(f.Payload)+1)
+	msg = append(msg, byte(f.Type))
+	msg = append(msg, f.Payload...)
+
+	c.cmdMu.Lock()
+	defer c.cmdMu.Unlock()
+
+	for attempt := 0; ; attempt++ {
+		p := &pendingCommand{expect: expect, reply: make(chan Frame, 1)}
+
+		c.mu.Lock()
+		if c.closed {
+			c.mu.Unlock()
+			return Frame{}, c.closedErr()
+		}
+		c.pending = p
+		c.mu.Unlock()
+
+		// A single write keeps the frame inside one CTS window
+		if err := c.port.WritePacket(msg); err != nil {
+			c.clearPending(p)
+			return Frame{}, fmt.Errorf("failed to write %s: %w", f.Type, err)
+		}
+
+		reply, err := c.wait(ctx, p)
+		if err != nil {
+			return Frame{}, fmt.Errorf("%s: %w", f.Type, err)
+		}
+		if reply.Type != MsgHostNack {
+			return reply, nil
+		}
+		if attempt >= c.retries {
+			return Frame{}, fmt.Errorf("%w: %s after %d attempts", ErrHostNack, f.Type, attempt+1)
+		}
+
+		select {
+		case <-time.After(c.retryDelay):
+		case <-ctx.Done():
+			return Frame{}, ctx.Err()
+		case <-c.done:
+			return Frame{}, c.closedErr()
+		}
+	}
+}
+
+func (c *Client) wait(ctx context.Context, p *pendingCommand) (Frame, error) {
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+
+	select {
+	case reply := <-p.reply:
+		return reply, nil
+	case <-timer.C:
+		c.clearPending(p)
+		return Frame{}, ErrTimeout
+	case <-ctx.Done():
+		c.clearPending(p)
+		return Frame{}, ctx.Err()
+	case <-c.done:
+		return Frame{}, c.closedErr()
+	}
+}
+
+func (c *Client) clearPending(p *pendingCommand) {
+	c.mu.Lock()
+	if c.pending == p {
+		c.pending = nil
+	}
+	c.mu.Unlock()
+}
+
+func (c *Client) removeAck(dest uint16, ack chan bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	waiters := c.acks[dest]
+	for i, w := range waiters {
+		if w == ack {
+			waiters = append(waiters[:i], waiters[i+1:]...)
+			break
+		}
+	}
+	if len(waiters) == 0 {
+		delete(c.acks, dest)
+	} else {
+		c.acks[dest] = waiters
+	}
+}
+
+func (c *Client) closedErr() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	return ErrClosed
+}
+
+func (c *Client) shutdown(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+	c.closed = true
+	c.err = err
+	c.pending = nil
+	c.acks = make(map[uint16][]chan bool)
+	c.cancel()
+	close(c.done)
+}
+
+func (c *Client) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+// readLoop decodes frames from the module and dispatches them
+func (c *Client) readLoop(ctx context.Context) {
+	for {
+		msg, _, err := c.port.ReadPacket(ctx)
+		if c.isClosed() {
+			return
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			c.shutdown(err)
+			return
+		}
+		c.handleFrame(Frame{Type: MessageType(msg[0]), Payload: msg[1:]})
+	}
+}
+
+func (c *Client) handleFrame(f Frame) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if p := c.pending; p != nil && (f.Type == p.expect || f.Type == MsgHostNack) {
+		p.reply <- f
+		c.pending = nil
+		return
+	}
+
+	switch f.Type {
+	case MsgAck, MsgNack:
+		if len(f.Payload) < 2 {
+			return
+		}
+		src := binary.BigEndian.Uint16(f.Payload)
+		waiters := c.acks[src]
+		if len(waiters) == 0 {
+			return
+		}
+		waiters[0] <- f.Type == MsgAck
+		if len(waiters) == 1 {
+			delete(c.acks, src)
+		} else {
+			c.acks[src] = waiters[1:]
+		}
+	case MsgHostData:
+		p, err := parseHostData(f.Payload)
+		if err != nil {
+			return
+		}
+		select {
+		case c.data <- *p:
+		default:
+			// Receiver is not keeping up; drop rather than stall command replies
+			c.dropped++
+		}
+	}
+}
+
+// sendPayload builds the [dest hi, dest lo, port, payload...] body of a send command
+func sendPayload(dest uint16, port uint8, payload []byte) ([]byte, error) {
+	if len(payload) > MaxPayloadSize {
+		return nil, fmt.Errorf("%w: %d bytes (max %d)", ErrPayloadTooLarge, len(payload), MaxPayloadSize)
+	}
+	msg := make([]byte, 0, 3+len(payload))
+	msg = binary.BigEndian.AppendUint16(msg, dest)
+	msg = append(msg, port)
+	return append(msg, payload...), nil
+}
+
+// parseHostData decodes [source(2), age(2), port, payload...]
+func parseHostData(b []byte) (*Packet, error) {
+	if len(b) < 5 {
+		return nil, fmt.Errorf("%w: host data of %d bytes", ErrMalformedMessage, len(b))
+	}
+	return &Packet{
+		Source:  binary.BigEndian.Uint16(b[0:2]),
+		Age:     time.Duration(binary.BigEndian.Uint16(b[2:4])) * packageAgeUnit,
+		Port:    b[4],
+		Payload: append([]byte(nil), b[5:]...),
+	}, nil
+}
@@ -0,0 +1,178 @@
+package neomesh
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// fakeModule answers frames written by the client using a handler
+type fakeModule struct {
+	toClient   *io.PipeWriter
+	fromModule *io.PipeReader
+	handler    func(frame []byte) [][]byte
+	written    chan []byte
+}
+
+func newFakeModule(handler func(frame []byte) [][]byte) *fakeModule {
+	r, w := io.Pipe()
+	return &fakeModule{
+		toClient:   w,
+		fromModule: r,
+		handler:    handler,
+		written:    make(chan []byte, 16),
+	}
+}
+
+func (m *fakeModule) Read(p []byte) (int, error) {
+	return m.fromModule.Read(p)
+}
+
+func (m *fakeModule) Write(p []byte) (int, error) {
+	frame := append([]byte(nil), p...)
+	m.written <- frame
+	go func() {
+		for _, reply := range m.handler(frame) {
+			m.toClient.Write(reply)
+		}
+	}()
+	return len(p), nil
+}
+
+func TestSendUnacknowledged(t *testing.T) {
+	module := newFakeModule(func([]byte) [][]byte {
+		return [][]byte{{0x50, 0x00}}
+	})
+	client := New(module)
+	defer client.Close()
+
+	if err := client.SendUnacknowledged(context.Background(), 0x0012, 1, []byte{0xAA, 0xBB}); err != nil {
+		t.Fatalf("SendUnacknowledged failed: %v", err)
+	}
+
+	want := []byte{0x02, 0x05, 0x00, 0x12, 0x01, 0xAA, 0xBB}
+	if got := <-module.written; !bytes.Equal(got, want) {
+		t.Errorf("written = % X, want % X", got, want)
+	}
+}
+
+func TestSendAcknowledged(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   byte
+		wantErr error
+	}{
+		{"ack", 0x52, nil},
+		{"nack", 0x53, ErrNotAcknowledged},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			module := newFakeModule(func([]byte) [][]byte {
+				// Host ack first, then the end-to-end result from node 0x0012
+				return [][]byte{{0x50, 0x00}, {tt.reply, 0x02, 0x00, 0x12}}
+			})
+			client := New(module)
+			defer client.Close()
+
+			err := client.SendAcknowledged(context.Background(), 0x0012, 1, []byte{0x01})
+			if !errors.Is(err, tt.wantErr) && err != tt.wantErr {
+				t.Errorf("SendAcknowledged error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHostNackRetries(t *testing.T) {
+	attempts := 0
+	module := newFakeModule(func([]byte) [][]byte {
+		attempts++
+		if attempts < 3 {
+			return [][]byte{{0x51, 0x00}}
+		}
+		return [][]byte{{0x50, 0x00}}
+	})
+	client := New(module, WithRetries(3, time.Millisecond))
+	defer client.Close()
+
+	if err := client.SendUnacknowledged(context.Background(), 1, 0, nil); err != nil {
+		t.Fatalf("SendUnacknowledged failed: %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+
+	module.handler = func([]byte) [][]byte { return [][]byte{{0x51, 0x00}} }
+	err := client.SendUnacknowledged(context.Background(), 1, 0, nil)
+	if !errors.Is(err, ErrHostNack) {
+		t.Errorf("error = %v, want ErrHostNack", err)
+	}
+}
+
+func TestNodeInfo(t *testing.T) {
+	module := newFakeModule(func(frame []byte) [][]byte {
+		if frame[0] != byte(MsgNodeInfoRequest) {
+			return nil
+		}
+		return [][]byte{{0x5A, 0x07, 0x00, 0x2A, 0x01, 0x02, 0x03, 0x04, 0x05}}
+	})
+	client := New(module)
+	defer client.Close()
+
+	info, err := client.NodeInfo(context.Background())
+	if err != nil {
+		t.Fatalf("NodeInfo failed: %v", err)
+	}
+	if info.NodeID != 0x002A {
+		t.Errorf("NodeID = %#04x, want 0x002a", info.NodeID)
+	}
+	if !bytes.Equal(info.UniqueID, []byte{0x01, 0x02, 0x03, 0x04, 0x05}) {
+		t.Errorf("UniqueID = % X", info.UniqueID)
+	}
+}
+
+func TestReceive(t *testing.T) {
+	module := newFakeModule(func([]byte) [][]byte { return nil })
+	client := New(module)
+	defer client.Close()
+
+	go module.toClient.Write([]byte{0x54, 0x07, 0x00, 0x33, 0x00, 0x10, 0x02, 0xCA, 0xFE})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	p, err := client.Receive(ctx)
+	if err != nil {
+		t.Fatalf("Receive failed: %v", err)
+	}
+	if p.Source != 0x0033 || p.Port != 2 {
+		t.Errorf("Source/Port = %#04x/%d, want 0x0033/2", p.Source, p.Port)
+	}
+	if p.Age != 2*time.Second {
+		t.Errorf("Age = %v, want 2s", p.Age)
+	}
+	if !bytes.Equal(p.Payload, []byte{0xCA, 0xFE}) {
+		t.Errorf("Payload = % X", p.Payload)
+	}
+}
+
+func TestCommandTimeoutAndClose(t *testing.T) {
+	module := newFakeModule(func([]byte) [][]byte { return nil })
+	client := New(module, WithTimeout(20*time.Millisecond))
+
+	if err := client.SendUnacknowledged(context.Background(), 1, 0, nil); !errors.Is(err, ErrTimeout) {
+		t.Errorf("error = %v, want ErrTimeout", err)
+	}
+
+	if err := client.SendUnacknowledged(context.Background(), 1, 0, make([]byte, MaxPayloadSize+1)); !errors.Is(err, ErrPayloadTooLarge) {
+		t.Errorf("oversized payload error = %v, want ErrPayloadTooLarge", err)
+	}
+
+	client.Close()
+	if err := client.SendUnacknowledged(context.Background(), 1, 0, nil); !errors.Is(err, ErrClosed) {
+		t.Errorf("error after Close = %v, want ErrClosed", err)
+	}
+}
@@ -0,0 +1,158 @@
+// Package neomesh implements the NeoCortec NeoMesh host protocol over a serial port.
+//
+// NeoMesh modules exchange binary messages with the host over UART. Every
+// message starts with a one-byte message type and a one-byte payload length:
+//
+//	+------+--------+-----------------+
+//	| type | length | payload (0-255) |
+//	+------+--------+-----------------+
+//
+// The module only accepts data while it asserts CTS, and the CTS window is
+// short (default 488us). The host must therefore start each frame inside the
+// window and send it with a single write. Open the port with CTS flow control
+// so writes are pre-queued and released the moment CTS becomes active:
+//
+//	port, _ := serial.Open("/dev/ttyUSB0",
+//	    serial.WithFlowControl(serial.FlowControlCTS),
+//	    serial.WithInitialRTS(true),
+//	    serial.WithReadTimeout(100*time.Millisecond),
+//	)
+//	client := neomesh.New(port)
+//	defer client.Close()
+//
+//	err := client.SendAcknowledged(ctx, 0x0012, 1, []byte{0x01, 0x02})
+//
+// Message type identifiers follow the NeoCortec NcApi reference implementation.
+//
+// Client speaks the Application API (AAPI). System API (SAPI) frames, used
+// to configure modules, are framed by SAPICodec.
+package neomesh
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/allbin/go-serial/framing"
+)
+
+// MessageType identifies a NeoMesh host protocol message
+type MessageType byte
+
+// Host to module messages (Application API)
+const (
+	MsgUnacknowledgedSend MessageType = 0x02
+	MsgAcknowledgedSend   MessageType = 0x03
+	MsgNodeInfoRequest    MessageType = 0x05
+)
+
+// Module to host messages (Application API)
+const (
+	MsgHostAck  MessageType = 0x50 // Module accepted the last host command
+	MsgHostNack MessageType = 0x51 // Module rejected the last host command (e.g., buffer full)
+	MsgAck      MessageType = 0x52 // Acknowledged send delivered to destination
+	MsgNack     MessageType = 0x53 // Acknowledged send not delivered
+	MsgHostData MessageType = 0x54 // Data received from the network
+	MsgNodeInfo MessageType = 0x5A // Reply to MsgNodeInfoRequest
+)
+
+// MaxPayloadSize is the largest application payload carried by a NeoMesh packet
+const MaxPayloadSize = 20
+
+// headerSize is the number of bytes preceding the payload (type + length)
+const headerSize = 2
+
+func (t MessageType) String() string {
+	switch t {
+	case MsgUnacknowledgedSend:
+		return "UnacknowledgedSend"
+	case MsgAcknowledgedSend:
+		return "AcknowledgedSend"
+	case MsgNodeInfoRequest:
+		return "NodeInfoRequest"
+	case MsgHostAck:
+		return "HostAck"
+	case MsgHostNack:
+		return "HostNack"
+	case MsgAck:
+		return "Ack"
+	case MsgNack:
+		return "Nack"
+	case MsgHostData:
+		return "HostData"
+	case MsgNodeInfo:
+		return "NodeInfo"
+	default:
+		return fmt.Sprintf("MessageType(0x%02X)", byte(t))
+	}
+}
+
+// Frame is a single NeoMesh host protocol message
+type Frame struct {
+	Type    MessageType
+	Payload []byte
+}
+
+// Codec implements framing.Codec for the NeoMesh type/length header
+// Encoded payloads passed to and returned from the codec start with the message type byte.
+type Codec struct{}
+
+var _ framing.Codec = Codec{}
+
+// Encode frames [type, payload...] as [type, length, payload...]
+func (Codec) Encode(msg []byte) ([]byte, error) {
+	if len(msg) == 0 {
+		return nil, fmt.Errorf("%w: missing message type", framing.ErrInvalidFrame)
+	}
+	if len(msg)-1 > 255 {
+		return nil, framing.ErrFrameTooLarge
+	}
+	frame := make([]byte, 0, len(msg)+1)
+	frame = append(frame, msg[0], byte(len(msg)-1))
+	return append(frame, msg[1:]...), nil
+}
+
+// NewDecoder returns a decoder yielding [type, payload...] for each frame
+func (Codec) NewDecoder(r io.Reader) framing.Decoder {
+	return &decoder{r: r, buf: make([]byte, 0, 2*headerSize+255), chunk: make([]byte, 256)}
+}
+
+type decoder struct {
+	r     io.Reader
+	buf   []byte
+	chunk []byte
+}
+
+func (d *decoder) Decode(ctx context.Context) ([]byte, error) {
+	for {
+		if len(d.buf) >= headerSize {
+			total := headerSize + int(d.buf[1])
+			if len(d.buf) >= total {
+				msg := make([]byte, 0, total-1)
+				msg = append(msg, d.buf[0])
+				msg = append(msg, d.buf[headerSize:total]...)
+				d.buf = d.buf[:copy(d.buf, d.buf[total:])]
+				return msg, nil
+			}
+		}
+
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		n, err := d.r.Read(d.chunk)
+		if n > 0 {
+			d.buf = append(d.buf, d.chunk[:n]...)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// EncodeFrame returns the wire representation of f
+func EncodeFrame(f Frame) ([]byte, error) {
+	msg := make([]byte, 0, len(f.Payload)+1)
+	msg = append(msg, byte(f.Type))
+	msg = append(msg, f.Payload...)
+	return Codec{}.Encode(msg)
+}
@@ -0,0 +1,91 @@
+package neomesh
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/allbin/go-serial/framing"
+)
+
+// slowReader returns one byte per read with empty reads in between,
+// mimicking a serial port with VTIME timeouts
+type slowReader struct {
+	data  []byte
+	empty bool
+}
+
+func (r *slowReader) Read(p []byte) (int, error) {
+	r.empty = !r.empty
+	if r.empty {
+		return 0, nil
+	}
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+	p[0] = r.data[0]
+	r.data = r.data[1:]
+	return 1, nil
+}
+
+func TestEncodeFrame(t *testing.T) {
+	tests := []struct {
+		name  string
+		frame Frame
+		want  []byte
+	}{
+		{"empty payload", Frame{Type: MsgNodeInfoRequest}, []byte{0x05, 0x00}},
+		{"send", Frame{Type: MsgAcknowledgedSend, Payload: []byte{0x00, 0x12, 0x01, 0xAA}}, []byte{0x03, 0x04, 0x00, 0x12, 0x01, 0xAA}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EncodeFrame(tt.frame)
+			if err != nil {
+				t.Fatalf("EncodeFrame failed: %v", err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("EncodeFrame = % X, want % X", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := EncodeFrame(Frame{Type: MsgHostData, Payload: make([]byte, 256)}); !errors.Is(err, framing.ErrFrameTooLarge) {
+		t.Errorf("oversized frame error = %v, want ErrFrameTooLarge", err)
+	}
+}
+
+func TestCodecDecode(t *testing.T) {
+	stream := []byte{0x50, 0x00, 0x52, 0x02, 0x00, 0x12, 0x54, 0x06, 0x00, 0x07, 0x00, 0x10, 0x01, 0xAB}
+	dec := Codec{}.NewDecoder(&slowReader{data: stream})
+
+	want := [][]byte{
+		{0x50},
+		{0x52, 0x00, 0x12},
+		{0x54, 0x00, 0x07, 0x00, 0x10, 0x01, 0xAB},
+	}
+	for _, w := range want {
+		msg, err := dec.Decode(context.Background())
+		if err != nil {
+			t.Fatalf("Decode failed: %v", err)
+		}
+		if !bytes.Equal(msg, w) {
+			t.Errorf("Decode = % X, want % X", msg, w)
+		}
+	}
+
+	if _, err := dec.Decode(context.Background()); err != io.EOF {
+		t.Errorf("Decode at end = %v, want io.EOF", err)
+	}
+}
+
+func TestMessageTypeString(t *testing.T) {
+	if got := MsgHostAck.String(); got != "HostAck" {
+		t.Errorf("MsgHostAck.String() = %q", got)
+	}
+	if got := MessageType(0x7F).String(); got != "MessageType(0x7F)" {
+		t.Errorf("unknown String() = %q", got)
+	}
+}
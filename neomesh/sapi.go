@@ -0,0 +1,151 @@
+package neomesh
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/allbin/go-serial/framing"
+)
+
+// SAPICommand identifies a NeoMesh System API command or reply
+type SAPICommand byte
+
+// System API commands
+const (
+	SAPILogin              SAPICommand = 0x01
+	SAPIGetSetting         SAPICommand = 0x02
+	SAPISetSetting         SAPICommand = 0x03
+	SAPICommitSettings     SAPICommand = 0x04
+	SAPIStartProtocolStack SAPICommand = 0x05
+)
+
+// System API replies
+const (
+	SAPIOk      SAPICommand = 0x80 // Command accepted; carries the reply data, if any
+	SAPIError   SAPICommand = 0x81 // Command rejected
+	SAPIBootMsg SAPICommand = 0x82 // Module entered the System API, e.g. after reset
+)
+
+// sapiStart marks the beginning of every System API frame
+const sapiStart = 0x3E
+
+// sapiHeaderSize is the number of bytes preceding the payload (start + command + length)
+const sapiHeaderSize = 3
+
+func (c SAPICommand) String() string {
+	switch c {
+	case SAPILogin:
+		return "Login"
+	case SAPIGetSetting:
+		return "GetSetting"
+	case SAPISetSetting:
+		return "SetSetting"
+	case SAPICommitSettings:
+		return "CommitSettings"
+	case SAPIStartProtocolStack:
+		return "StartProtocolStack"
+	case SAPIOk:
+		return "Ok"
+	case SAPIError:
+		return "Error"
+	case SAPIBootMsg:
+		return "BootMsg"
+	default:
+		return fmt.Sprintf("SAPICommand(0x%02X)", byte(c))
+	}
+}
+
+// SAPIFrame is a single NeoMesh System API message
+type SAPIFrame struct {
+	Command SAPICommand
+	Payload []byte
+}
+
+// SAPICodec implements framing.Codec for System API frames
+//
+// The System API configures a module (login, settings, commit, starting the
+// protocol stack). Its frames carry a start byte ahead of the command and
+// length, so the decoder can find the next frame after line noise:
+//
+//	+------+---------+--------+-----------------+
+//	| 0x3E | command | length | payload (0-255) |
+//	+------+---------+--------+-----------------+
+//
+// As with Codec, messages passed to and returned from the codec start with
+// the command byte. Use it with framing.NewPacketPort while the module's
+// UART is in System API mode.
+type SAPICodec struct{}
+
+var _ framing.Codec = SAPICodec{}
+
+// Encode frames [command, payload...] as [start, command, length, payload...]
+func (SAPICodec) Encode(msg []byte) ([]byte, error) {
+	if len(msg) == 0 {
+		return nil, fmt.Errorf("%w: missing SAPI command", framing.ErrInvalidFrame)
+	}
+	if len(msg)-1 > 255 {
+		return nil, framing.ErrFrameTooLarge
+	}
+	frame := make([]byte, 0, len(msg)+2)
+	frame = append(frame, sapiStart, msg[0], byte(len(msg)-1))
+	return append(frame, msg[1:]...), nil
+}
+
+// NewDecoder returns a decoder yielding [command, payload...] for each frame
+// Bytes before a start byte are discarded.
+func (SAPICodec) NewDecoder(r io.Reader) framing.Decoder {
+	return &sapiDecoder{r: r, buf: make([]byte, 0, 2*sapiHeaderSize+255), chunk: make([]byte, 256)}
+}
+
+type sapiDecoder struct {
+	r     io.Reader
+	buf   []byte
+	chunk []byte
+}
+
+func (d *sapiDecoder) Decode(ctx context.Context) ([]byte, error) {
+	for {
+		d.resync()
+		if len(d.buf) >= sapiHeaderSize {
+			total := sapiHeaderSize + int(d.buf[2])
+			if len(d.buf) >= total {
+				msg := make([]byte, 0, total-2)
+				msg = append(msg, d.buf[1])
+				msg = append(msg, d.buf[sapiHeaderSize:total]...)
+				d.buf = d.buf[:copy(d.buf, d.buf[total:])]
+				return msg, nil
+			}
+		}
+
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		n, err := d.r.Read(d.chunk)
+		if n > 0 {
+			d.buf = append(d.buf, d.chunk[:n]...)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// resync drops buffered bytes preceding the next start byte
+func (d *sapiDecoder) resync() {
+	i := 0
+	for i < len(d.buf) && d.buf[i] != sapiStart {
+		i++
+	}
+	if i > 0 {
+		d.buf = d.buf[:copy(d.buf, d.buf[i:])]
+	}
+}
+
+// EncodeSAPIFrame returns the wire representation of f
+func EncodeSAPIFrame(f SAPIFrame) ([]byte, error) {
+	msg := make([]byte, 0, len(f.Payload)+1)
+	msg = append(msg, byte(f.Command))
+	msg = append(msg, f.Payload...)
+	return SAPICodec{}.Encode(msg)
+}
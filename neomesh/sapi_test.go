@@ -0,0 +1,120 @@
+package neomesh
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/allbin/go-serial/framing"
+)
+
+func TestEncodeSAPIFrame(t *testing.T) {
+	tests := []struct {
+		name  string
+		frame SAPIFrame
+		want  []byte
+	}{
+		{"empty payload", SAPIFrame{Command: SAPICommitSettings}, []byte{0x3E, 0x04, 0x00}},
+		{"set setting", SAPIFrame{Command: SAPISetSetting, Payload: []byte{0x01, 0x00, 0x12}}, []byte{0x3E, 0x03, 0x03, 0x01, 0x00, 0x12}},
+		{"payload with start byte", SAPIFrame{Command: SAPILogin, Payload: []byte{0x3E}}, []byte{0x3E, 0x01, 0x01, 0x3E}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EncodeSAPIFrame(tt.frame)
+			if err != nil {
+				t.Fatalf("EncodeSAPIFrame failed: %v", err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("EncodeSAPIFrame = % X, want % X", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := EncodeSAPIFrame(SAPIFrame{Command: SAPISetSetting, Payload: make([]byte, 256)}); !errors.Is(err, framing.ErrFrameTooLarge) {
+		t.Errorf("oversized frame error = %v, want ErrFrameTooLarge", err)
+	}
+	if _, err := (SAPICodec{}).Encode(nil); !errors.Is(err, framing.ErrInvalidFrame) {
+		t.Errorf("empty message error = %v, want ErrInvalidFrame", err)
+	}
+}
+
+func TestSAPICodecDecode(t *testing.T) {
+	tests := []struct {
+		name   string
+		stream []byte
+		want   [][]byte
+	}{
+		{
+			name:   "back to back",
+			stream: []byte{0x3E, 0x82, 0x00, 0x3E, 0x80, 0x02, 0x00, 0x12},
+			want:   [][]byte{{0x82}, {0x80, 0x00, 0x12}},
+		},
+		{
+			name:   "noise before and between frames",
+			stream: []byte{0x00, 0xFF, 0x3E, 0x80, 0x00, 0x55, 0x3E, 0x81, 0x01, 0x07},
+			want:   [][]byte{{0x80}, {0x81, 0x07}},
+		},
+		{
+			name:   "start byte in payload",
+			stream: []byte{0x3E, 0x80, 0x02, 0x3E, 0x3E, 0x3E, 0x04, 0x00},
+			want:   [][]byte{{0x80, 0x3E, 0x3E}, {0x04}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dec := SAPICodec{}.NewDecoder(&slowReader{data: tt.stream})
+			for _, w := range tt.want {
+				msg, err := dec.Decode(context.Background())
+				if err != nil {
+					t.Fatalf("Decode failed: %v", err)
+				}
+				if !bytes.Equal(msg, w) {
+					t.Errorf("Decode = % X, want % X", msg, w)
+				}
+			}
+			if _, err := dec.Decode(context.Background()); err != io.EOF {
+				t.Errorf("Decode at end = %v, want io.EOF", err)
+			}
+		})
+	}
+}
+
+func TestSAPIPacketPort(t *testing.T) {
+	var wire bytes.Buffer
+	reply := []byte{0x3E, 0x80, 0x01, 0x2A}
+	pp := framing.NewPacketPort(struct {
+		io.Reader
+		io.Writer
+	}{bytes.NewReader(reply), &wire}, SAPICodec{})
+
+	if err := pp.WritePacket([]byte{byte(SAPIGetSetting), 0x01}); err != nil {
+		t.Fatalf("WritePacket failed: %v", err)
+	}
+	if want := []byte{0x3E, 0x02, 0x01, 0x01}; !bytes.Equal(wire.Bytes(), want) {
+		t.Errorf("wrote % X, want % X", wire.Bytes(), want)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	msg, _, err := pp.ReadPacket(ctx)
+	if err != nil {
+		t.Fatalf("ReadPacket failed: %v", err)
+	}
+	if f := (SAPIFrame{Command: SAPICommand(msg[0]), Payload: msg[1:]}); f.Command != SAPIOk || !bytes.Equal(f.Payload, []byte{0x2A}) {
+		t.Errorf("reply = %s % X, want Ok 2A", f.Command, f.Payload)
+	}
+}
+
+func TestSAPICommandString(t *testing.T) {
+	if got := SAPICommitSettings.String(); got != "CommitSettings" {
+		t.Errorf("SAPICommitSettings.String() = %q", got)
+	}
+	if got := SAPICommand(0x7F).String(); got != "SAPICommand(0x7F)" {
+		t.Errorf("unknown String() = %q", got)
+	}
+}
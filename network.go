@@ -0,0 +1,572 @@
+package serial
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Network device URL schemes accepted by Open
+const (
+	SchemeTCP     = "tcp"     // Raw TCP socket (e.g., ser2net raw mode)
+	SchemeRFC2217 = "rfc2217" // Telnet with COM-PORT-OPTION, RFC 2217
+)
+
+const (
+	networkDialTimeout = 10 * time.Second
+	networkBufferSize  = 64 * 1024 // Unread data buffered before the connection is throttled
+)
+
+// netPort is a Port backed by a network connection
+// Raw TCP carries data only: line settings are left to the server and modem
+// signal operations return ErrNotSupported. RFC 2217 applies the Config on
+// open, controls RTS/DTR and tracks the modem state notified by the server.
+type netPort struct {
+	conn    net.Conn
+	config  Config
+	rfc2217 bool
+	tap     *trafficTap
+
+	writeMu sync.Mutex // Serializes data writes and telnet commands
+
+	mu       sync.Mutex
+	closed   bool
+	rx       []byte        // Received data not yet read
+	rxAt     time.Time     // When the oldest data in rx arrived
+	rxErr    error         // Connection error, returned once rx is drained
+	rts, dtr bool          // Last states requested from the server
+	signals  *signalEvent  // Latest modem state notified by the server
+	rxReady  chan struct{} // Signaled when rx grows or rxErr is set
+	rxSpace  chan struct{} // Signaled when rx shrinks
+	done     chan struct{} // Closed by Close
+	loopDone chan struct{} // Closed when readLoop exits
+
+	comPort chan bool    // Result of COM-PORT-OPTION negotiation
+	telnet  telnetParser // Read loop only
+}
+
+// signalEvent is one modem state notification
+// Waiters follow the chain through next, so no change is missed between waits.
+type signalEvent struct {
+	signals ModemSignals
+	changed SignalMask
+	next    *signalEvent
+	ready   chan struct{} // Closed once next is set
+}
+
+// Ensure netPort implements Port and its optional interfaces at compile time
+var (
+	_ Port              = (*netPort)(nil)
+	_ TimestampedReader = (*netPort)(nil)
+	_ Breaker           = (*netPort)(nil)
+)
+
+// isNetworkDevice reports whether device is a URL such as "rfc2217://host:port"
+func isNetworkDevice(device string) bool {
+	return strings.Contains(device, "://")
+}
+
+// openNetwork connects to a tcp:// or rfc2217:// device URL
+func openNetwork(device string, config Config) (Port, error) {
+	scheme, addr, _ := strings.Cut(device, "://")
+
+	var rfc2217 bool
+	switch scheme {
+	case SchemeTCP:
+	case SchemeRFC2217:
+		rfc2217 = true
+	default:
+		return nil, fmt.Errorf("%w: unsupported device scheme %q", ErrInvalidConfig, scheme)
+	}
+
+	if config.FlowControl == FlowControlCTS {
+		return nil, fmt.Errorf("%w: CTS flow control is not supported on network ports", ErrInvalidConfig)
+	}
+
+	conn, err := net.DialTimeout("tcp", addr, networkDialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+
+	p := &netPort{
+		conn:     conn,
+		config:   config,
+		rfc2217:  rfc2217,
+		tap:      newTrafficTap(config),
+		signals:  &signalEvent{ready: make(chan struct{})},
+		rxReady:  make(chan struct{}, 1),
+		rxSpace:  make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		loopDone: make(chan struct{}),
+		comPort:  make(chan bool, 1),
+	}
+	go p.readLoop()
+
+	if rfc2217 {
+		if err := p.startComPort(); err != nil {
+			p.Close()
+			return nil, err
+		}
+	}
+
+	config.Logger.Debug("network port opened", "device", device, "baud", config.BaudRate)
+	return p, nil
+}
+
+// notify signals ch without blocking
+func notify(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// readLoop moves received data into rx until the connection fails
+func (p *netPort) readLoop() {
+	defer close(p.loopDone)
+
+	chunk := make([]byte, 4096)
+	for {
+		n, err := p.conn.Read(chunk)
+		at := time.Now()
+		if n > 0 {
+			data := chunk[:n]
+			if p.rfc2217 {
+				data = p.decodeTelnet(data)
+			}
+			if !p.deliver(data, at) {
+				return
+			}
+		}
+		if err != nil {
+			p.mu.Lock()
+			if p.closed {
+				err = ErrPortClosed
+			} else {
+				p.config.Logger.Warn("network port connection lost", "err", err)
+			}
+			p.rxErr = err
+			p.mu.Unlock()
+			notify(p.rxReady)
+			return
+		}
+	}
+}
+
+// deliver appends data received at at to rx, waiting for the reader while
+// the buffer is full
+func (p *netPort) deliver(data []byte, at time.Time) bool {
+	if len(data) == 0 {
+		return true
+	}
+	for {
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return false
+		}
+		if len(p.rx) < networkBufferSize {
+			if len(p.rx) == 0 {
+				p.rxAt = at
+			}
+			p.rx = append(p.rx, data...)
+			p.mu.Unlock()
+			notify(p.rxReady)
+			return true
+		}
+		p.mu.Unlock()
+
+		select {
+		case <-p.rxSpace:
+		case <-p.done:
+			return false
+		}
+	}
+}
+
+// writeRaw sends bytes to the connection unmodified
+func (p *netPort) writeRaw(data []byte) error {
+	p.writeMu.Lock()
+	defer p.writeMu.Unlock()
+
+	_, err := p.conn.Write(data)
+	return err
+}
+
+// checkOpen returns ErrPortClosed after Close
+func (p *netPort) checkOpen() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrPortClosed
+	}
+	return nil
+}
+
+// Close closes the network connection
+func (p *netPort) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return ErrPortClosed
+	}
+	p.closed = true
+	close(p.done)
+	p.mu.Unlock()
+
+	return p.conn.Close()
+}
+
+// Read reads received data, waiting up to the configured read timeout
+// Like a VTIME read, it returns (0, nil) when no data arrives in time.
+func (p *netPort) Read(buf []byte) (int, error) {
+	n, _, err := p.read(context.Background(), buf)
+	return n, err
+}
+
+// ReadContext reads received data with context cancellation support
+func (p *netPort) ReadContext(ctx context.Context, buf []byte) (int, error) {
+	n, _, err := p.read(ctx, buf)
+	return n, err
+}
+
+// ReadTimestamped reads received data and returns when its first byte came
+// off the connection
+func (p *netPort) ReadTimestamped(ctx context.Context, buf []byte) (int, time.Time, error) {
+	return p.read(ctx, buf)
+}
+
+func (p *netPort) read(ctx context.Context, buf []byte) (n int, at time.Time, err error) {
+	defer func() { p.config.record(ctx, CounterBytesRead, n, err) }()
+
+	var timeout <-chan time.Time
+	if p.config.ReadTimeout > 0 {
+		timer := time.NewTimer(p.config.ReadTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	for {
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return 0, time.Time{}, ErrPortClosed
+		}
+		if len(p.rx) > 0 {
+			// Data left over from a partial read keeps the earlier arrival time
+			n, at := copy(buf, p.rx), p.rxAt
+			// Move the rest to the front rather than reslicing, so the
+			// buffer is reused instead of regrown by every deliver
+			p.rx = p.rx[:copy(p.rx, p.rx[n:])]
+			p.mu.Unlock()
+			p.tap.rx(buf[:n])
+			notify(p.rxSpace)
+			return n, at, nil
+		}
+		if err := p.rxErr; err != nil {
+			p.mu.Unlock()
+			return 0, time.Time{}, err
+		}
+		p.mu.Unlock()
+
+		if timeout == nil {
+			return 0, time.Time{}, nil
+		}
+
+		select {
+		case <-p.rxReady:
+		case <-timeout:
+			return 0, time.Time{}, nil
+		case <-ctx.Done():
+			return 0, time.Time{}, ctx.Err()
+		case <-p.done:
+		}
+	}
+}
+
+// Write sends data to the remote port
+func (p *netPort) Write(data []byte) (n int, err error) {
+	ctx, end := p.config.startSpan(context.Background(), SpanWrite, slog.Int("bytes", len(data)))
+	defer func() {
+		p.config.record(ctx, CounterBytesWritten, n, err)
+		end(err)
+	}()
+
+	return p.write(data)
+}
+
+func (p *netPort) write(data []byte) (int, error) {
+	if err := p.checkOpen(); err != nil {
+		return 0, err
+	}
+
+	out := data
+	if p.rfc2217 {
+		out = appendTelnetEscaped(nil, data)
+	}
+	if err := p.writeRaw(out); err != nil {
+		return 0, err
+	}
+	p.tap.tx(data)
+	return len(data), nil
+}
+
+// WriteContext writes data with context timeout support
+func (p *netPort) WriteContext(ctx context.Context, data []byte) (n int, err error) {
+	ctx, end := p.config.startSpan(ctx, SpanWrite, slog.Int("bytes", len(data)))
+	defer func() {
+		p.config.record(ctx, CounterBytesWritten, n, err)
+		end(err)
+	}()
+
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+	}
+
+	resultCh := make(chan writeResult, 1)
+	go func() {
+		n, err := p.write(data)
+		resultCh <- writeResult{n: n, err: err}
+	}()
+
+	select {
+	case result := <-resultCh:
+		return result.n, result.err
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+// GetCTSStatus returns the CTS state last notified by the server
+func (p *netPort) GetCTSStatus() (bool, error) {
+	signals, err := p.GetModemSignals()
+	if err != nil {
+		return false, err
+	}
+	return signals.CTS, nil
+}
+
+// GetModemSignals returns the notified input signals and the requested RTS/DTR
+func (p *netPort) GetModemSignals() (ModemSignals, error) {
+	if !p.rfc2217 {
+		return ModemSignals{}, ErrNotSupported
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ModemSignals{}, ErrPortClosed
+	}
+	return p.currentSignals(p.signals), nil
+}
+
+// currentSignals combines a notified state with the local RTS/DTR (mu held)
+func (p *netPort) currentSignals(ev *signalEvent) ModemSignals {
+	signals := ev.signals
+	signals.RTS = p.rts
+	signals.DTR = p.dtr
+	return signals
+}
+
+// SetRTS asks the server to set RTS
+func (p *netPort) SetRTS(state bool) error {
+	if err := p.setControl(state, comControlRTSOn, comControlRTSOff); err != nil {
+		return err
+	}
+	p.mu.Lock()
+	p.rts = state
+	p.mu.Unlock()
+	return nil
+}
+
+// GetRTS returns the RTS state last requested from the server
+func (p *netPort) GetRTS() (bool, error) {
+	signals, err := p.GetModemSignals()
+	if err != nil {
+		return false, err
+	}
+	return signals.RTS, nil
+}
+
+// SetDTR asks the server to set DTR
+func (p *netPort) SetDTR(state bool) error {
+	if err := p.setControl(state, comControlDTROn, comControlDTROff); err != nil {
+		return err
+	}
+	p.mu.Lock()
+	p.dtr = state
+	p.mu.Unlock()
+	return nil
+}
+
+// GetDTR returns the DTR state last requested from the server
+func (p *netPort) GetDTR() (bool, error) {
+	signals, err := p.GetModemSignals()
+	if err != nil {
+		return false, err
+	}
+	return signals.DTR, nil
+}
+
+func (p *netPort) setControl(state bool, on, off byte) error {
+	if !p.rfc2217 {
+		return ErrNotSupported
+	}
+	if err := p.checkOpen(); err != nil {
+		return err
+	}
+
+	value := off
+	if state {
+		value = on
+	}
+	return p.comPortCommand(comSetControl, value)
+}
+
+// SendBreak asks the server to hold TX in the break state for duration
+// Data written before the call is sent to the server first, but the server
+// may still be transmitting it when the break starts. Raw TCP returns
+// ErrNotSupported.
+func (p *netPort) SendBreak(duration time.Duration) error {
+	if err := p.setControl(true, comControlBreakOn, comControlBreakOff); err != nil {
+		return err
+	}
+	time.Sleep(duration)
+	return p.setControl(false, comControlBreakOn, comControlBreakOff)
+}
+
+// WaitForSignalChange blocks until the server notifies a change in a monitored signal
+func (p *netPort) WaitForSignalChange(mask SignalMask, timeout time.Duration) (ModemSignals, SignalMask, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	return p.waitForSignal(context.Background(), mask, timer.C)
+}
+
+// WaitForSignalChangeContext waits with context cancellation support
+func (p *netPort) WaitForSignalChangeContext(ctx context.Context, mask SignalMask) (ModemSignals, SignalMask, error) {
+	return p.waitForSignal(ctx, mask, nil)
+}
+
+func (p *netPort) waitForSignal(ctx context.Context, mask SignalMask, timeout <-chan time.Time) (ModemSignals, SignalMask, error) {
+	if mask == 0 {
+		return ModemSignals{}, 0, ErrInvalidSignalMask
+	}
+	if !p.rfc2217 {
+		return ModemSignals{}, 0, ErrNotSupported
+	}
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return ModemSignals{}, 0, ErrPortClosed
+	}
+	ev := p.signals
+	p.mu.Unlock()
+
+	var changed SignalMask
+	for {
+		select {
+		case <-ev.ready:
+		case <-timeout:
+			return ModemSignals{}, 0, ErrSignalTimeout
+		case <-ctx.Done():
+			return ModemSignals{}, 0, ctx.Err()
+		case <-p.done:
+			return ModemSignals{}, 0, ErrPortClosed
+		}
+
+		ev = ev.next
+		changed |= ev.changed
+		if changed&mask != 0 {
+			p.mu.Lock()
+			signals := p.currentSignals(ev)
+			p.mu.Unlock()
+			return signals, changed, nil
+		}
+	}
+}
+
+// DrainOutput returns once written data has been handed to the connection
+// The server's UART buffer is not observable over the network.
+func (p *netPort) DrainOutput() error {
+	return p.checkOpen()
+}
+
+// FlushInput discards unread data and, over RFC 2217, the server's receive buffer
+func (p *netPort) FlushInput() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return ErrPortClosed
+	}
+	p.rx = nil
+	p.mu.Unlock()
+	notify(p.rxSpace)
+
+	if p.rfc2217 {
+		return p.comPortCommand(comPurgeData, comPurgeRX)
+	}
+	return nil
+}
+
+// DrainInput flushes input, then reads until no more data arrives, within
+// DefaultDrainTimeout and DefaultDrainLimit
+func (p *netPort) DrainInput() error {
+	return drainWithDefaults(p.DrainInputContext)
+}
+
+// DrainInputContext flushes input, then discards what still arrives until
+// a read finds none, waiting at most 50 ms for it, or more than limit bytes
+// were discarded
+func (p *netPort) DrainInputContext(ctx context.Context, limit int) (int, error) {
+	if limit <= 0 {
+		limit = DefaultDrainLimit
+	}
+	if err := p.FlushInput(); err != nil {
+		return 0, err
+	}
+
+	scratch := getScratch()
+	defer putScratch(scratch)
+	buf := *scratch
+	drained := 0
+	for {
+		quiet, cancel := context.WithTimeout(ctx, drainQuiet)
+		n, err := p.ReadContext(quiet, buf)
+		cancel()
+		drained += n
+		if err := ctx.Err(); err != nil {
+			return drained, err
+		}
+		if drained > limit {
+			return drained, ErrDrainIncomplete
+		}
+		if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+			return drained, err
+		}
+		if n == 0 {
+			return drained, nil
+		}
+	}
+}
+
+// FlushOutput asks an RFC 2217 server to discard its transmit buffer
+func (p *netPort) FlushOutput() error {
+	if err := p.checkOpen(); err != nil {
+		return err
+	}
+	if p.rfc2217 {
+		return p.comPortCommand(comPurgeData, comPurgeTX)
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package serial
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeServer accepts one connection and records everything the client sends
+type fakeServer struct {
+	listener net.Listener
+	conn     chan net.Conn
+
+	mu       sync.Mutex
+	accepted net.Conn
+	received []byte
+}
+
+func newFakeServer(t *testing.T, onAccept func(net.Conn)) *fakeServer {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("TCP listener not available: %v", err)
+	}
+	s := &fakeServer{listener: l, conn: make(chan net.Conn, 1)}
+	t.Cleanup(func() {
+		l.Close()
+		s.mu.Lock()
+		if s.accepted != nil {
+			s.accepted.Close()
+		}
+		s.mu.Unlock()
+	})
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.accepted = conn
+		s.mu.Unlock()
+		s.conn <- conn
+		if onAccept != nil {
+			onAccept(conn)
+		}
+
+		buf := make([]byte, 1024)
+		for {
+			n, err := conn.Read(buf)
+			s.mu.Lock()
+			s.received = append(s.received, buf[:n]...)
+			s.mu.Unlock()
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	return s
+}
+
+func (s *fakeServer) addr() string {
+	return s.listener.Addr().String()
+}
+
+// waitReceived waits until the client has sent want
+func (s *fakeServer) waitReceived(t *testing.T, want []byte) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		s.mu.Lock()
+		found := bytes.Contains(s.received, want)
+		s.mu.Unlock()
+		if found {
+			return
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t.Fatalf("server did not receive % X; got % X", want, s.received)
+}
+
+func TestOpenTCP(t *testing.T) {
+	server := newFakeServer(t, nil)
+
+	port, err := Open("tcp://"+server.addr(), WithReadTimeout(100*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer port.Close()
+
+	if _, err := port.Write([]byte("hello\xff")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	server.waitReceived(t, []byte("hello\xff"))
+	if _, err := port.Writev([][]byte{[]byte("he"), []byte("ader\xff")}); err != nil {
+		t.Fatalf("Writev failed: %v", err)
+	}
+	server.waitReceived(t, []byte("header\xff"))
+
+	conn := <-server.conn
+	conn.Write([]byte("world"))
+
+	buf := make([]byte, 16)
+	n, err := port.Read(buf)
+	if err != nil || string(buf[:n]) != "world" {
+		t.Errorf("Read = %q, %v, want world", buf[:n], err)
+	}
+
+	// An idle read times out like VTIME: no data, no error
+	if n, err := port.Read(buf); n != 0 || err != nil {
+		t.Errorf("idle Read = %d, %v, want 0, nil", n, err)
+	}
+
+	if _, err := port.GetModemSignals(); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("GetModemSignals error = %v, want ErrNotSupported", err)
+	}
+	if err := port.SetRTS(true); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("SetRTS error = %v, want ErrNotSupported", err)
+	}
+	if err := SendBreak(port, time.Millisecond); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("SendBreak error = %v, want ErrNotSupported", err)
+	}
+
+	port.Close()
+	if _, err := port.Write([]byte("x")); !errors.Is(err, ErrPortClosed) {
+		t.Errorf("Write after Close error = %v, want ErrPortClosed", err)
+	}
+}
+
+func TestOpenNetworkErrors(t *testing.T) {
+	if _, err := Open("udp://127.0.0.1:1"); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("unsupported scheme error = %v, want ErrInvalidConfig", err)
+	}
+	if _, err := Open("tcp://127.0.0.1:1", WithFlowControl(FlowControlCTS)); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("CTS flow control error = %v, want ErrInvalidConfig", err)
+	}
+
+	// Server refuses COM-PORT-OPTION
+	server := newFakeServer(t, func(conn net.Conn) {
+		conn.Write([]byte{telnetIAC, telnetDONT, telnetOptComPort})
+	})
+	if _, err := Open("rfc2217://" + server.addr()); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("refused RFC 2217 error = %v, want ErrNotSupported", err)
+	}
+}
+
+func TestOpenRFC2217(t *testing.T) {
+	server := newFakeServer(t, func(conn net.Conn) {
+		conn.Write([]byte{
+			telnetIAC, telnetDO, telnetOptComPort,
+			telnetIAC, telnetWILL, 1, // ECHO: must be refused
+		})
+	})
+
+	port, err := Open("rfc2217://"+server.addr(),
+		WithBaudRate(9600),
+		WithParity(ParityEven),
+		WithInitialDTR(true),
+	)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer port.Close()
+
+	sb := func(data ...byte) []byte {
+		msg := append([]byte{telnetIAC, telnetSB, telnetOptComPort}, data...)
+		return append(msg, telnetIAC, telnetSE)
+	}
+
+	// Line settings and initial DTR are applied on open
+	server.waitReceived(t, sb(comSetBaudRate, 0x00, 0x00, 0x25, 0x80))
+	server.waitReceived(t, sb(comSetParity, 3))
+	server.waitReceived(t, sb(comSetControl, comControlDTROn))
+	server.waitReceived(t, []byte{telnetIAC, telnetDONT, 1})
+
+	// IAC in data is escaped both ways
+	if _, err := port.Write([]byte("a\xffb")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	server.waitReceived(t, []byte("a\xff\xffb"))
+	if _, err := port.Writev([][]byte{[]byte("c\xff"), []byte("d")}); err != nil {
+		t.Fatalf("Writev failed: %v", err)
+	}
+	server.waitReceived(t, []byte("c\xff\xffd"))
+
+	conn := <-server.conn
+	conn.Write([]byte("x\xff\xffy"))
+	buf := make([]byte, 16)
+	n, err := port.Read(buf)
+	if err != nil || string(buf[:n]) != "x\xffy" {
+		t.Errorf("Read = %q, %v, want x\\xffy", buf[:n], err)
+	}
+
+	// Modem state notifications wake signal waiters
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		conn.Write(sb(comServerModemState, modemStateCTS|modemStateDeltaCTS|modemStateDSR))
+	}()
+	signals, changed, err := port.WaitForSignalChange(SignalCTS, time.Second)
+	if err != nil {
+		t.Fatalf("WaitForSignalChange failed: %v", err)
+	}
+	if !signals.CTS || !signals.DSR || !signals.DTR || changed&SignalCTS == 0 {
+		t.Errorf("signals = %+v changed = %b, want CTS, DSR and DTR with CTS changed", signals, changed)
+	}
+
+	if err := port.SetRTS(true); err != nil {
+		t.Fatalf("SetRTS failed: %v", err)
+	}
+	server.waitReceived(t, sb(comSetControl, comControlRTSOn))
+	if rts, _ := port.GetRTS(); !rts {
+		t.Error("GetRTS = false after SetRTS(true)")
+	}
+
+	if err := port.FlushInput(); err != nil {
+		t.Fatalf("FlushInput failed: %v", err)
+	}
+	server.waitReceived(t, sb(comPurgeData, comPurgeRX))
+
+	if err := SendBreak(port, 10*time.Millisecond); err != nil {
+		t.Fatalf("SendBreak failed: %v", err)
+	}
+	server.waitReceived(t, append(sb(comSetControl, comControlBreakOn), sb(comSetControl, comControlBreakOff)...))
+
+	if _, _, err := port.WaitForSignalChange(SignalDCD, 50*time.Millisecond); !errors.Is(err, ErrSignalTimeout) {
+		t.Errorf("WaitForSignalChange error = %v, want ErrSignalTimeout", err)
+	}
+}
@@ -0,0 +1,193 @@
+package nmea
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+)
+
+// maxSentenceLength bounds the line buffer so garbage without line endings
+// (wrong baud rate, binary protocol) cannot grow it without limit.
+// NMEA limits sentences to 82 characters; vendors exceed this, so allow headroom.
+const maxSentenceLength = 1024
+
+// Reader decodes NMEA sentences from a byte stream such as a serial.Port
+type Reader struct {
+	r        io.Reader
+	talkers  map[string]bool // nil = accept all talkers
+	raw      bool            // Deliver validated Sentence values without typed decoding
+	buf      []byte
+	chunk    []byte
+	mu       sync.Mutex
+	err      error
+	invalid  int // Count of sentences dropped by validation
+	filtered int // Count of sentences dropped by talker filtering
+}
+
+// Option configures a Reader
+type Option func(*Reader)
+
+// WithTalkers restricts delivery to sentences from the given talker IDs (e.g., "GP", "GN")
+func WithTalkers(talkers ...string) Option {
+	return func(r *Reader) {
+		r.talkers = make(map[string]bool, len(talkers))
+		for _, t := range talkers {
+			r.talkers[strings.ToUpper(t)] = true
+		}
+	}
+}
+
+// WithRawSentences delivers validated sentences without decoding them into typed messages
+func WithRawSentences() Option {
+	return func(r *Reader) {
+		r.raw = true
+	}
+}
+
+// NewReader creates a Reader that decodes sentences from r
+func NewReader(r io.Reader, opts ...Option) *Reader {
+	reader := &Reader{
+		r:     r,
+		buf:   make([]byte, 0, 256),
+		chunk: make([]byte, 256),
+	}
+	for _, opt := range opts {
+		opt(reader)
+	}
+	return reader
+}
+
+// Next returns the next valid message that passes the talker filter
+// Sentences failing validation are skipped and counted (see Invalid).
+// Zero-length reads (serial VTIME timeouts) are tolerated.
+func (r *Reader) Next() (Message, error) {
+	return r.next(context.Background())
+}
+
+func (r *Reader) next(ctx context.Context) (Message, error) {
+	for {
+		line, err := r.readLine(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		s, err := ParseSentence(line)
+		if err != nil {
+			r.mu.Lock()
+			r.invalid++
+			r.mu.Unlock()
+			continue
+		}
+
+		if r.talkers != nil && !r.talkers[s.Talker] {
+			r.mu.Lock()
+			r.filtered++
+			r.mu.Unlock()
+			continue
+		}
+
+		if r.raw {
+			return s, nil
+		}
+
+		msg, err := Decode(s)
+		if err != nil {
+			r.mu.Lock()
+			r.invalid++
+			r.mu.Unlock()
+			continue
+		}
+		return msg, nil
+	}
+}
+
+// readLine returns the next line starting with a sentence delimiter
+func (r *Reader) readLine(ctx context.Context) (string, error) {
+	for {
+		if idx := bytes.IndexByte(r.buf, '\n'); idx != -1 {
+			line := string(bytes.TrimRight(r.buf[:idx], "\r"))
+			r.buf = r.buf[:copy(r.buf, r.buf[idx+1:])]
+
+			// Resynchronize on the last start delimiter to skip leading noise
+			if start := strings.LastIndexAny(line, "$!"); start != -1 {
+				return line[start:], nil
+			}
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+
+		n, err := r.r.Read(r.chunk)
+		if n > 0 {
+			r.buf = append(r.buf, r.chunk[:n]...)
+			if len(r.buf) > maxSentenceLength {
+				// Keep only the tail so a sentence split across the boundary survives
+				r.buf = r.buf[:copy(r.buf, r.buf[len(r.buf)-maxSentenceLength/2:])]
+			}
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// Messages decodes sentences in a background goroutine and delivers them on the returned channel
+// The channel is closed when ctx is done or the underlying reader fails; Err reports the cause.
+// Cancellation is observed between reads, so a serial port should be opened with a read timeout.
+func (r *Reader) Messages(ctx context.Context) <-chan Message {
+	out := make(chan Message, 16)
+
+	go func() {
+		defer close(out)
+		for {
+			msg, err := r.next(ctx)
+			if err != nil {
+				r.setErr(err)
+				return
+			}
+			select {
+			case out <- msg:
+			case <-ctx.Done():
+				r.setErr(ctx.Err())
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+func (r *Reader) setErr(err error) {
+	if errors.Is(err, io.EOF) {
+		err = nil
+	}
+	r.mu.Lock()
+	r.err = err
+	r.mu.Unlock()
+}
+
+// Err returns the error that stopped Messages, or nil on clean EOF
+func (r *Reader) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// Invalid returns the number of sentences dropped due to checksum or format errors
+func (r *Reader) Invalid() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.invalid
+}
+
+// Filtered returns the number of valid sentences dropped by the talker filter
+func (r *Reader) Filtered() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.filtered
+}
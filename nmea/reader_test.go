@@ -0,0 +1,153 @@
+package nmea
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// chunkReader returns data in fixed-size chunks with empty reads in between,
+// mimicking a serial port with VTIME timeouts
+type chunkReader struct {
+	data  []byte
+	size  int
+	empty bool
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	r.empty = !r.empty
+	if r.empty {
+		return 0, nil
+	}
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+	n := r.size
+	if n > len(r.data) {
+		n = len(r.data)
+	}
+	n = copy(p, r.data[:n])
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestReaderNext(t *testing.T) {
+	stream := strings.Join([]string{
+		"garbage$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*47",
+		"$GPVTG,054.7,T,034.4,M,005.5,N,010.2,K*49", // bad checksum
+		testRMC,
+		"",
+	}, "\r\n")
+
+	r := NewReader(&chunkReader{data: []byte(stream), size: 7})
+
+	msg, err := r.Next()
+	if err != nil {
+		t.Fatalf("Next failed: %v", err)
+	}
+	if _, ok := msg.(*GGA); !ok {
+		t.Fatalf("first message is %T, want *GGA", msg)
+	}
+
+	msg, err = r.Next()
+	if err != nil {
+		t.Fatalf("Next failed: %v", err)
+	}
+	if _, ok := msg.(*RMC); !ok {
+		t.Fatalf("second message is %T, want *RMC", msg)
+	}
+
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("Next at end = %v, want io.EOF", err)
+	}
+	if r.Invalid() != 1 {
+		t.Errorf("Invalid() = %d, want 1", r.Invalid())
+	}
+}
+
+func TestReaderTalkerFilter(t *testing.T) {
+	stream := testGGA + "\r\n" + testGNS + "\r\n" + testVTG + "\r\n"
+	r := NewReader(strings.NewReader(stream), WithTalkers("gn"))
+
+	msg, err := r.Next()
+	if err != nil {
+		t.Fatalf("Next failed: %v", err)
+	}
+	if msg.Base().Talker != "GN" {
+		t.Errorf("Talker = %s, want GN", msg.Base().Talker)
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("Next at end = %v, want io.EOF", err)
+	}
+	if r.Filtered() != 2 {
+		t.Errorf("Filtered() = %d, want 2", r.Filtered())
+	}
+}
+
+func TestReaderRawSentences(t *testing.T) {
+	r := NewReader(strings.NewReader(testRMC+"\n"), WithRawSentences())
+
+	msg, err := r.Next()
+	if err != nil {
+		t.Fatalf("Next failed: %v", err)
+	}
+	s, ok := msg.(Sentence)
+	if !ok {
+		t.Fatalf("message is %T, want Sentence", msg)
+	}
+	if s.Raw != testRMC {
+		t.Errorf("Raw = %q, want %q", s.Raw, testRMC)
+	}
+}
+
+func TestReaderMessages(t *testing.T) {
+	stream := testGGA + "\n" + testGSV + "\n" + testVTG + "\n"
+	r := NewReader(strings.NewReader(stream))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var types []string
+	for msg := range r.Messages(ctx) {
+		types = append(types, msg.Base().Type)
+	}
+
+	if got := strings.Join(types, ","); got != "GGA,GSV,VTG" {
+		t.Errorf("types = %s, want GGA,GSV,VTG", got)
+	}
+	if err := r.Err(); err != nil {
+		t.Errorf("Err() = %v, want nil on EOF", err)
+	}
+}
+
+func TestReaderMessagesCancel(t *testing.T) {
+	// Reader that never produces data, like an idle port with a read timeout
+	r := NewReader(readerFunc(func(p []byte) (int, error) {
+		time.Sleep(time.Millisecond)
+		return 0, nil
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := r.Messages(ctx)
+	cancel()
+
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Fatal("unexpected message")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Messages did not stop after cancellation")
+	}
+	if r.Err() != context.Canceled {
+		t.Errorf("Err() = %v, want context.Canceled", r.Err())
+	}
+}
+
+type readerFunc func(p []byte) (int, error)
+
+func (f readerFunc) Read(p []byte) (int, error) {
+	return f(p)
+}
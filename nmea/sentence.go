@@ -0,0 +1,422 @@
+// Package nmea decodes NMEA 0183 sentences from a serial stream.
+//
+// GPS/GNSS modules (typically ttyACM or ttyUSB devices) emit a continuous
+// stream of ASCII sentences such as:
+//
+//	$GPRMC,123519,A,4807.038,N,01131.000,E,022.4,084.4,230394,003.1,W*6A
+//
+// Every sentence is checksum-validated before it is delivered. RMC, GGA, GSV
+// and VTG sentences are decoded into typed structs; all other sentence types
+// are delivered as a validated Sentence with raw fields.
+package nmea
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Predefined errors for sentence validation
+var (
+	ErrInvalidSentence  = errors.New("invalid NMEA sentence")
+	ErrChecksumMismatch = errors.New("NMEA checksum mismatch")
+	ErrMissingChecksum  = errors.New("NMEA sentence has no checksum")
+)
+
+// Message is implemented by all decoded sentence types
+type Message interface {
+	Base() Sentence
+}
+
+// Sentence is a checksum-validated NMEA sentence split into its fields
+type Sentence struct {
+	Raw      string   // Full sentence without line ending (e.g., "$GPRMC,...*6A")
+	Talker   string   // Talker ID (e.g., "GP", "GN", "GL")
+	Type     string   // Sentence type (e.g., "RMC", "GGA")
+	Fields   []string // Data fields following the address field
+	Checksum byte     // Transmitted checksum
+}
+
+// Base returns the underlying validated sentence
+func (s Sentence) Base() Sentence {
+	return s
+}
+
+// RMC is the Recommended Minimum Specific GNSS Data sentence
+type RMC struct {
+	Sentence
+	Time              time.Time // UTC date and time of fix
+	Valid             bool      // Status A = valid, V = navigation receiver warning
+	Latitude          float64   // Decimal degrees, negative for south
+	Longitude         float64   // Decimal degrees, negative for west
+	SpeedKnots        float64   // Speed over ground in knots
+	Course            float64   // Course over ground in degrees true
+	MagneticVariation float64   // Degrees, negative for west
+	Mode              string    // Mode indicator (NMEA 2.3+, empty if absent)
+}
+
+// GGA is the Global Positioning System Fix Data sentence
+type GGA struct {
+	Sentence
+	TimeOfDay       time.Duration // UTC time of fix since midnight
+	Latitude        float64       // Decimal degrees, negative for south
+	Longitude       float64       // Decimal degrees, negative for west
+	FixQuality      int           // 0 = invalid, 1 = GPS, 2 = DGPS, ...
+	Satellites      int           // Number of satellites in use
+	HDOP            float64       // Horizontal dilution of precision
+	Altitude        float64       // Antenna altitude above mean sea level (meters)
+	GeoidSeparation float64       // Geoidal separation (meters)
+}
+
+// GSV is the Satellites in View sentence
+type GSV struct {
+	Sentence
+	TotalMessages    int             // Total number of GSV messages in this cycle
+	MessageNumber    int             // Number of this message (1-based)
+	SatellitesInView int             // Total number of satellites in view
+	Satellites       []SatelliteInfo // Up to four satellites per message
+}
+
+// SatelliteInfo describes a single satellite reported in a GSV sentence
+type SatelliteInfo struct {
+	PRN       int // Satellite PRN number
+	Elevation int // Elevation in degrees (0-90)
+	Azimuth   int // Azimuth in degrees true (0-359)
+	SNR       int // Signal-to-noise ratio in dB-Hz (0 when not tracking)
+}
+
+// VTG is the Course Over Ground and Ground Speed sentence
+type VTG struct {
+	Sentence
+	TrueTrack     float64 // Course over ground in degrees true
+	MagneticTrack float64 // Course over ground in degrees magnetic
+	SpeedKnots    float64 // Speed over ground in knots
+	SpeedKPH      float64 // Speed over ground in km/h
+	Mode          string  // Mode indicator (NMEA 2.3+, empty if absent)
+}
+
+// Checksum computes the NMEA checksum (XOR of all characters) of a sentence body
+// The body is everything between the leading '$' or '!' and the '*'
+func Checksum(body string) byte {
+	var sum byte
+	for i := 0; i < len(body); i++ {
+		sum ^= body[i]
+	}
+	return sum
+}
+
+// ParseSentence validates the checksum and splits a sentence into its fields
+func ParseSentence(raw string) (Sentence, error) {
+	raw = strings.TrimRight(raw, "\r\n")
+
+	if len(raw) < 7 || (raw[0] != '$' && raw[0] != '!') {
+		return Sentence{}, fmt.Errorf("%w: %q", ErrInvalidSentence, raw)
+	}
+
+	star := strings.LastIndexByte(raw, '*')
+	if star == -1 {
+		return Sentence{}, ErrMissingChecksum
+	}
+	if len(raw)-star != 3 {
+		return Sentence{}, fmt.Errorf("%w: malformed checksum in %q", ErrInvalidSentence, raw)
+	}
+
+	want, err := strconv.ParseUint(raw[star+1:], 16, 8)
+	if err != nil {
+		return Sentence{}, fmt.Errorf("%w: malformed checksum in %q", ErrInvalidSentence, raw)
+	}
+
+	body := raw[1:star]
+	if got := Checksum(body); got != byte(want) {
+		return Sentence{}, fmt.Errorf("%w: got %02X, want %02X", ErrChecksumMismatch, got, want)
+	}
+
+	parts := strings.Split(body, ",")
+	address := parts[0]
+	if len(address) < 3 {
+		return Sentence{}, fmt.Errorf("%w: short address field %q", ErrInvalidSentence, address)
+	}
+
+	s := Sentence{
+		Raw:      raw,
+		Fields:   parts[1:],
+		Checksum: byte(want),
+	}
+
+	// Proprietary sentences ($P...) have a single-character talker
+	if address[0] == 'P' {
+		s.Talker = "P"
+		s.Type = address[1:]
+	} else {
+		s.Talker = address[:len(address)-3]
+		s.Type = address[len(address)-3:]
+	}
+
+	return s, nil
+}
+
+// Parse validates a sentence and decodes it into a typed message
+// Unsupported sentence types are returned as a plain Sentence
+func Parse(raw string) (Message, error) {
+	s, err := ParseSentence(raw)
+	if err != nil {
+		return nil, err
+	}
+	return Decode(s)
+}
+
+// Decode converts a validated sentence into a typed message
+// Unsupported sentence types are returned unchanged
+func Decode(s Sentence) (Message, error) {
+	switch s.Type {
+	case "RMC":
+		return decodeRMC(s)
+	case "GGA":
+		return decodeGGA(s)
+	case "GSV":
+		return decodeGSV(s)
+	case "VTG":
+		return decodeVTG(s)
+	default:
+		return s, nil
+	}
+}
+
+func decodeRMC(s Sentence) (*RMC, error) {
+	// time, status, lat, N/S, lon, E/W, speed, course, date, magvar, E/W [, mode]
+	if len(s.Fields) < 11 {
+		return nil, fieldCountError(s, 11)
+	}
+	f := s.Fields
+	p := &fieldParser{}
+
+	m := &RMC{
+		Sentence:          s,
+		Valid:             f[1] == "A",
+		Latitude:          p.coordinate(f[2], f[3]),
+		Longitude:         p.coordinate(f[4], f[5]),
+		SpeedKnots:        p.float(f[6]),
+		Course:            p.float(f[7]),
+		MagneticVariation: p.float(f[9]),
+	}
+	if f[10] == "W" {
+		m.MagneticVariation = -m.MagneticVariation
+	}
+	if len(f) > 11 {
+		m.Mode = f[11]
+	}
+
+	tod := p.timeOfDay(f[0])
+	if f[8] != "" {
+		date := p.date(f[8])
+		m.Time = date.Add(tod)
+	}
+
+	if p.err != nil {
+		return nil, fmt.Errorf("%w: RMC: %v", ErrInvalidSentence, p.err)
+	}
+	return m, nil
+}
+
+func decodeGGA(s Sentence) (*GGA, error) {
+	// time, lat, N/S, lon, E/W, quality, sats, hdop, alt, M, geoid, M, [age, station]
+	if len(s.Fields) < 12 {
+		return nil, fieldCountError(s, 12)
+	}
+	f := s.Fields
+	p := &fieldParser{}
+
+	m := &GGA{
+		Sentence:        s,
+		TimeOfDay:       p.timeOfDay(f[0]),
+		Latitude:        p.coordinate(f[1], f[2]),
+		Longitude:       p.coordinate(f[3], f[4]),
+		FixQuality:      p.int(f[5]),
+		Satellites:      p.int(f[6]),
+		HDOP:            p.float(f[7]),
+		Altitude:        p.float(f[8]),
+		GeoidSeparation: p.float(f[10]),
+	}
+
+	if p.err != nil {
+		return nil, fmt.Errorf("%w: GGA: %v", ErrInvalidSentence, p.err)
+	}
+	return m, nil
+}
+
+func decodeGSV(s Sentence) (*GSV, error) {
+	// total, number, in view, then groups of (prn, elevation, azimuth, snr) [, signal id]
+	if len(s.Fields) < 3 {
+		return nil, fieldCountError(s, 3)
+	}
+	f := s.Fields
+	p := &fieldParser{}
+
+	m := &GSV{
+		Sentence:         s,
+		TotalMessages:    p.int(f[0]),
+		MessageNumber:    p.int(f[1]),
+		SatellitesInView: p.int(f[2]),
+	}
+
+	for i := 3; i+3 < len(f); i += 4 {
+		if f[i] == "" {
+			continue
+		}
+		m.Satellites = append(m.Satellites, SatelliteInfo{
+			PRN:       p.int(f[i]),
+			Elevation: p.int(f[i+1]),
+			Azimuth:   p.int(f[i+2]),
+			SNR:       p.int(f[i+3]),
+		})
+	}
+
+	if p.err != nil {
+		return nil, fmt.Errorf("%w: GSV: %v", ErrInvalidSentence, p.err)
+	}
+	return m, nil
+}
+
+func decodeVTG(s Sentence) (*VTG, error) {
+	// true track, T, magnetic track, M, knots, N, kph, K [, mode]
+	if len(s.Fields) < 8 {
+		return nil, fieldCountError(s, 8)
+	}
+	f := s.Fields
+	p := &fieldParser{}
+
+	m := &VTG{
+		Sentence:      s,
+		TrueTrack:     p.float(f[0]),
+		MagneticTrack: p.float(f[2]),
+		SpeedKnots:    p.float(f[4]),
+		SpeedKPH:      p.float(f[6]),
+	}
+	if len(f) > 8 {
+		m.Mode = f[8]
+	}
+
+	if p.err != nil {
+		return nil, fmt.Errorf("%w: VTG: %v", ErrInvalidSentence, p.err)
+	}
+	return m, nil
+}
+
+func fieldCountError(s Sentence, want int) error {
+	return fmt.Errorf("%w: %s has %d fields, want at least %d", ErrInvalidSentence, s.Type, len(s.Fields), want)
+}
+
+// fieldParser parses individual fields and records the first error encountered
+// Empty fields are valid in NMEA and decode to zero values
+type fieldParser struct {
+	err error
+}
+
+func (p *fieldParser) fail(err error) {
+	if p.err == nil {
+		p.err = err
+	}
+}
+
+func (p *fieldParser) float(field string) float64 {
+	if field == "" {
+		return 0
+	}
+	v, err := strconv.ParseFloat(field, 64)
+	if err != nil {
+		p.fail(fmt.Errorf("invalid number %q", field))
+	}
+	return v
+}
+
+func (p *fieldParser) int(field string) int {
+	if field == "" {
+		return 0
+	}
+	v, err := strconv.Atoi(field)
+	if err != nil {
+		p.fail(fmt.Errorf("invalid integer %q", field))
+	}
+	return v
+}
+
+// coordinate converts ddmm.mmmm / dddmm.mmmm plus hemisphere into decimal degrees
+func (p *fieldParser) coordinate(value, hemisphere string) float64 {
+	if value == "" {
+		return 0
+	}
+	dot := strings.IndexByte(value, '.')
+	if dot == -1 {
+		dot = len(value)
+	}
+	if dot < 3 {
+		p.fail(fmt.Errorf("invalid coordinate %q", value))
+		return 0
+	}
+
+	degrees, err := strconv.ParseFloat(value[:dot-2], 64)
+	if err != nil {
+		p.fail(fmt.Errorf("invalid coordinate %q", value))
+		return 0
+	}
+	minutes, err := strconv.ParseFloat(value[dot-2:], 64)
+	if err != nil {
+		p.fail(fmt.Errorf("invalid coordinate %q", value))
+		return 0
+	}
+
+	result := degrees + minutes/60
+	switch hemisphere {
+	case "S", "W":
+		result = -result
+	case "N", "E":
+	default:
+		p.fail(fmt.Errorf("invalid hemisphere %q", hemisphere))
+	}
+	return result
+}
+
+// timeOfDay parses hhmmss[.sss] into a duration since midnight
+func (p *fieldParser) timeOfDay(field string) time.Duration {
+	if field == "" {
+		return 0
+	}
+	if len(field) < 6 {
+		p.fail(fmt.Errorf("invalid time %q", field))
+		return 0
+	}
+	hours, err1 := strconv.Atoi(field[0:2])
+	minutes, err2 := strconv.Atoi(field[2:4])
+	seconds, err3 := strconv.ParseFloat(field[4:], 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		p.fail(fmt.Errorf("invalid time %q", field))
+		return 0
+	}
+	return time.Duration(hours)*time.Hour +
+		time.Duration(minutes)*time.Minute +
+		time.Duration(seconds*float64(time.Second)).Round(time.Millisecond)
+}
+
+// date parses ddmmyy into a UTC date
+// Two-digit years are mapped to 1980-2079 to cover the GPS epoch
+func (p *fieldParser) date(field string) time.Time {
+	if len(field) != 6 {
+		p.fail(fmt.Errorf("invalid date %q", field))
+		return time.Time{}
+	}
+	day, err1 := strconv.Atoi(field[0:2])
+	month, err2 := strconv.Atoi(field[2:4])
+	year, err3 := strconv.Atoi(field[4:6])
+	if err1 != nil || err2 != nil || err3 != nil {
+		p.fail(fmt.Errorf("invalid date %q", field))
+		return time.Time{}
+	}
+	if year < 80 {
+		year += 2000
+	} else {
+		year += 1900
+	}
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,195 @@
+package nmea
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+const (
+	testRMC = "$GPRMC,123519,A,4807.038,N,01131.000,E,022.4,084.4,230394,003.1,W*6A"
+	testGGA = "$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*47"
+	testGSV = "$GPGSV,2,1,08,01,40,083,46,02,17,308,41,12,07,344,39,14,22,228,45*75"
+	testVTG = "$GPVTG,054.7,T,034.4,M,005.5,N,010.2,K*48"
+	testGNS = "$GNGGA,123519,4807.038,S,01131.000,W,1,08,0.9,545.4,M,46.9,M,,*56"
+	testGLL = "$GPGLL,4916.45,N,12311.12,W,225444,A*31"
+)
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+func TestChecksum(t *testing.T) {
+	got := Checksum("GPVTG,054.7,T,034.4,M,005.5,N,010.2,K")
+	if got != 0x48 {
+		t.Errorf("Checksum() = %02X, want 48", got)
+	}
+}
+
+func TestParseSentence(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr error
+	}{
+		{"valid RMC", testRMC, nil},
+		{"valid with CRLF", testGGA + "\r\n", nil},
+		{"bad checksum", "$GPVTG,054.7,T,034.4,M,005.5,N,010.2,K*49", ErrChecksumMismatch},
+		{"missing checksum", "$GPVTG,054.7,T,034.4,M,005.5,N,010.2,K", ErrMissingChecksum},
+		{"no start delimiter", "GPVTG,054.7,T*48", ErrInvalidSentence},
+		{"malformed checksum", "$GPVTG,054.7,T*4", ErrInvalidSentence},
+		{"empty", "", ErrInvalidSentence},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSentence(tt.raw)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseSentenceAddress(t *testing.T) {
+	s, err := ParseSentence(testGNS)
+	if err != nil {
+		t.Fatalf("ParseSentence failed: %v", err)
+	}
+	if s.Talker != "GN" || s.Type != "GGA" {
+		t.Errorf("Talker/Type = %s/%s, want GN/GGA", s.Talker, s.Type)
+	}
+	if s.Checksum != 0x56 {
+		t.Errorf("Checksum = %02X, want 56", s.Checksum)
+	}
+	if len(s.Fields) != 14 {
+		t.Errorf("len(Fields) = %d, want 14", len(s.Fields))
+	}
+}
+
+func TestParseRMC(t *testing.T) {
+	msg, err := Parse(testRMC)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	rmc, ok := msg.(*RMC)
+	if !ok {
+		t.Fatalf("Parse returned %T, want *RMC", msg)
+	}
+
+	wantTime := time.Date(1994, time.March, 23, 12, 35, 19, 0, time.UTC)
+	if !rmc.Time.Equal(wantTime) {
+		t.Errorf("Time = %v, want %v", rmc.Time, wantTime)
+	}
+	if !rmc.Valid {
+		t.Error("Valid = false, want true")
+	}
+	if !almostEqual(rmc.Latitude, 48+7.038/60) {
+		t.Errorf("Latitude = %v", rmc.Latitude)
+	}
+	if !almostEqual(rmc.Longitude, 11+31.0/60) {
+		t.Errorf("Longitude = %v", rmc.Longitude)
+	}
+	if !almostEqual(rmc.SpeedKnots, 22.4) || !almostEqual(rmc.Course, 84.4) {
+		t.Errorf("Speed/Course = %v/%v", rmc.SpeedKnots, rmc.Course)
+	}
+	if !almostEqual(rmc.MagneticVariation, -3.1) {
+		t.Errorf("MagneticVariation = %v, want -3.1", rmc.MagneticVariation)
+	}
+	if rmc.Base().Talker != "GP" {
+		t.Errorf("Talker = %s, want GP", rmc.Base().Talker)
+	}
+}
+
+func TestParseGGA(t *testing.T) {
+	msg, err := Parse(testGNS)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	gga, ok := msg.(*GGA)
+	if !ok {
+		t.Fatalf("Parse returned %T, want *GGA", msg)
+	}
+
+	wantTOD := 12*time.Hour + 35*time.Minute + 19*time.Second
+	if gga.TimeOfDay != wantTOD {
+		t.Errorf("TimeOfDay = %v, want %v", gga.TimeOfDay, wantTOD)
+	}
+	if !almostEqual(gga.Latitude, -(48 + 7.038/60)) {
+		t.Errorf("Latitude = %v, want southern hemisphere", gga.Latitude)
+	}
+	if !almostEqual(gga.Longitude, -(11 + 31.0/60)) {
+		t.Errorf("Longitude = %v, want western hemisphere", gga.Longitude)
+	}
+	if gga.FixQuality != 1 || gga.Satellites != 8 {
+		t.Errorf("FixQuality/Satellites = %d/%d, want 1/8", gga.FixQuality, gga.Satellites)
+	}
+	if !almostEqual(gga.HDOP, 0.9) || !almostEqual(gga.Altitude, 545.4) || !almostEqual(gga.GeoidSeparation, 46.9) {
+		t.Errorf("HDOP/Altitude/Geoid = %v/%v/%v", gga.HDOP, gga.Altitude, gga.GeoidSeparation)
+	}
+}
+
+func TestParseGSV(t *testing.T) {
+	msg, err := Parse(testGSV)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	gsv, ok := msg.(*GSV)
+	if !ok {
+		t.Fatalf("Parse returned %T, want *GSV", msg)
+	}
+
+	if gsv.TotalMessages != 2 || gsv.MessageNumber != 1 || gsv.SatellitesInView != 8 {
+		t.Errorf("header = %d/%d/%d, want 2/1/8", gsv.TotalMessages, gsv.MessageNumber, gsv.SatellitesInView)
+	}
+	if len(gsv.Satellites) != 4 {
+		t.Fatalf("len(Satellites) = %d, want 4", len(gsv.Satellites))
+	}
+	want := SatelliteInfo{PRN: 14, Elevation: 22, Azimuth: 228, SNR: 45}
+	if gsv.Satellites[3] != want {
+		t.Errorf("Satellites[3] = %+v, want %+v", gsv.Satellites[3], want)
+	}
+}
+
+func TestParseVTG(t *testing.T) {
+	msg, err := Parse(testVTG)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	vtg, ok := msg.(*VTG)
+	if !ok {
+		t.Fatalf("Parse returned %T, want *VTG", msg)
+	}
+	if !almostEqual(vtg.TrueTrack, 54.7) || !almostEqual(vtg.MagneticTrack, 34.4) {
+		t.Errorf("tracks = %v/%v", vtg.TrueTrack, vtg.MagneticTrack)
+	}
+	if !almostEqual(vtg.SpeedKnots, 5.5) || !almostEqual(vtg.SpeedKPH, 10.2) {
+		t.Errorf("speeds = %v/%v", vtg.SpeedKnots, vtg.SpeedKPH)
+	}
+}
+
+func TestParseUnsupportedType(t *testing.T) {
+	msg, err := Parse(testGLL)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	s, ok := msg.(Sentence)
+	if !ok {
+		t.Fatalf("Parse returned %T, want Sentence", msg)
+	}
+	if s.Type != "GLL" {
+		t.Errorf("Type = %s, want GLL", s.Type)
+	}
+}
+
+func TestParseShortSentence(t *testing.T) {
+	// Valid checksum but too few fields for RMC
+	_, err := Parse("$GPRMC,123519,A*07")
+	if err == nil {
+		t.Fatal("expected error for short RMC")
+	}
+}
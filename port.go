@@ -3,6 +3,9 @@ package serial
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
 	"sync"
 	"time"
 
@@ -15,10 +18,14 @@ type Port interface {
 	Read(buf []byte) (int, error)
 	Write(data []byte) (int, error)
 	WriteContext(ctx context.Context, data []byte) (int, error)
+	// Writev writes the concatenation of bufs as a single write, so a frame
+	// held in separate slices need not be copied into one first
+	Writev(bufs [][]byte) (int, error)
 	ReadContext(ctx context.Context, buf []byte) (int, error)
 	GetCTSStatus() (bool, error)
 	DrainOutput() error
 	DrainInput() error
+	DrainInputContext(ctx context.Context, limit int) (int, error)
 	FlushInput() error
 	FlushOutput() error
 
@@ -38,11 +45,23 @@ type port struct {
 	fd         int
 	config     Config
 	closed     bool
-	ctsMonitor *ctsMonitor // CTS monitoring for flow control
+	ctsMonitor *ctsMonitor    // CTS monitoring for flow control
+	signals    *signalWatcher // Shared wait for WaitForSignalChange
+	reader     *asyncReader   // Goroutine running cancelable reads
+	tap        *trafficTap    // Traffic mirror, nil unless WithTrafficTap
+	trace      *ioTracer      // Ioctl trace, nil unless WithTrace
 }
 
-// Ensure port implements Port interface at compile time
-var _ Port = (*port)(nil)
+// Ensure port implements Port and its optional interfaces at compile time
+var (
+	_ Port               = (*port)(nil)
+	_ TimestampedReader  = (*port)(nil)
+	_ Breaker            = (*port)(nil)
+	_ LineCounter        = (*port)(nil)
+	_ BufferReporter     = (*port)(nil)
+	_ LineSettingsReader = (*port)(nil)
+	_ CTSLatencyReporter = (*port)(nil)
+)
 
 // FlowControl represents the flow control mode
 type FlowControl int
@@ -64,6 +83,42 @@ const (
 	ParitySpace
 )
 
+// String returns the parity name as accepted by ParseParity
+func (p Parity) String() string {
+	switch p {
+	case ParityNone:
+		return "none"
+	case ParityOdd:
+		return "odd"
+	case ParityEven:
+		return "even"
+	case ParityMark:
+		return "mark"
+	case ParitySpace:
+		return "space"
+	default:
+		return fmt.Sprintf("Parity(%d)", int(p))
+	}
+}
+
+// ParseParity converts a parity name, or its first letter as in "7E1", to a Parity
+func ParseParity(name string) (Parity, error) {
+	switch strings.ToLower(name) {
+	case "none", "n":
+		return ParityNone, nil
+	case "odd", "o":
+		return ParityOdd, nil
+	case "even", "e":
+		return ParityEven, nil
+	case "mark", "m":
+		return ParityMark, nil
+	case "space", "s":
+		return ParitySpace, nil
+	default:
+		return 0, fmt.Errorf("unknown parity %q (want none, odd, even, mark or space)", name)
+	}
+}
+
 // ModemSignals represents modem control signal states
 type ModemSignals struct {
 	CTS bool // Clear To Send
@@ -99,9 +154,20 @@ type writeResult struct {
 // ctsMonitor handles CTS signal monitoring using TIOCMIWAIT
 // It pre-queues write operations and executes them immediately when CTS goes LOW
 type ctsMonitor struct {
-	fd      int
-	stopCh  chan struct{}
-	writeCh chan *writeRequest // Queue for pending writes
+	fd       int
+	log      Logger
+	tap      *trafficTap
+	trace    *ioTracer
+	priority int // SCHED_FIFO priority, 0 to wait on a helper goroutine
+	latency  latencyStats
+	stopCh   chan struct{}
+	writeCh  chan *writeRequest // Queue for pending writes
+}
+
+// ctsWake is the end of a TIOCMIWAIT for CTS
+type ctsWake struct {
+	at  time.Time
+	err error
 }
 
 // getBaudRate converts an integer baud rate to the unix constant
@@ -172,20 +238,31 @@ func getBaudRate(rate int) (uint32, error) {
 	}
 }
 
+// baudRateFromCode converts a CBAUD code back to its baud rate, or 0 for
+// a code getBaudRate does not produce (such as BOTHER)
+func baudRateFromCode(code uint32) int {
+	for _, rate := range tracedRates {
+		if c, _ := getBaudRate(rate); c == code {
+			return rate
+		}
+	}
+	return 0
+}
+
 // getModemStatus retrieves modem control signals using unix package
-func getModemStatus(fd int) (int, error) {
-	return unix.IoctlGetInt(fd, unix.TIOCMGET)
+func getModemStatus(tr *ioTracer, fd int) (int, error) {
+	return tiocmget(tr, fd)
 }
 
 // assertRTS manually asserts the RTS signal using unix package
-func assertRTS(fd int) error {
-	return unix.IoctlSetInt(fd, unix.TIOCMBIS, unix.TIOCM_RTS)
+func assertRTS(tr *ioTracer, fd int) error {
+	return tiocmbis(tr, fd, unix.TIOCM_RTS)
 }
 
 // setDTR sets DTR signal state
-func setDTR(fd int, state bool) error {
+func setDTR(tr *ioTracer, fd int, state bool) error {
 	// Read current modem status
-	status, err := unix.IoctlGetInt(fd, unix.TIOCMGET)
+	status, err := tiocmget(tr, fd)
 	if err != nil {
 		return err
 	}
@@ -198,13 +275,13 @@ func setDTR(fd int, state bool) error {
 	}
 
 	// Write back using TIOCMSET
-	return unix.IoctlSetPointerInt(fd, unix.TIOCMSET, status)
+	return tiocmset(tr, fd, status)
 }
 
 // setRTSSignal sets RTS signal state
-func setRTSSignal(fd int, state bool) error {
+func setRTSSignal(tr *ioTracer, fd int, state bool) error {
 	// Read current modem status
-	status, err := unix.IoctlGetInt(fd, unix.TIOCMGET)
+	status, err := tiocmget(tr, fd)
 	if err != nil {
 		return err
 	}
@@ -217,12 +294,12 @@ func setRTSSignal(fd int, state bool) error {
 	}
 
 	// Write back using TIOCMSET
-	return unix.IoctlSetPointerInt(fd, unix.TIOCMSET, status)
+	return tiocmset(tr, fd, status)
 }
 
 // waitForCTSChange waits for CTS signal changes using TIOCMIWAIT
-func waitForCTSChange(fd int) error {
-	return unix.IoctlSetInt(fd, unix.TIOCMIWAIT, unix.TIOCM_CTS)
+func waitForCTSChange(tr *ioTracer, fd int) error {
+	return tiocmiwait(tr, fd, unix.TIOCM_CTS)
 }
 
 // signalMaskToTIOCM converts SignalMask to unix TIOCM bits
@@ -243,6 +320,18 @@ func signalMaskToTIOCM(mask SignalMask) int {
 	return bits
 }
 
+// modemSignalsFromStatus converts TIOCM bits to ModemSignals
+func modemSignalsFromStatus(status int) ModemSignals {
+	return ModemSignals{
+		CTS: status&unix.TIOCM_CTS != 0,
+		DSR: status&unix.TIOCM_DSR != 0,
+		RI:  status&unix.TIOCM_RI != 0,
+		DCD: status&unix.TIOCM_CAR != 0,
+		RTS: status&unix.TIOCM_RTS != 0,
+		DTR: status&unix.TIOCM_DTR != 0,
+	}
+}
+
 // detectSignalChanges compares old and new signal states to determine what changed
 func detectSignalChanges(oldStatus, newStatus int) SignalMask {
 	var changed SignalMask
@@ -262,11 +351,15 @@ func detectSignalChanges(oldStatus, newStatus int) SignalMask {
 }
 
 // newCTSMonitor creates a new CTS monitor
-func newCTSMonitor(fd int) *ctsMonitor {
+func newCTSMonitor(fd int, log Logger, tap *trafficTap, trace *ioTracer, priority int) *ctsMonitor {
 	return &ctsMonitor{
-		fd:      fd,
-		stopCh:  make(chan struct{}),
-		writeCh: make(chan *writeRequest, 1), // Buffered for one pending write
+		fd:       fd,
+		log:      log,
+		tap:      tap,
+		trace:    trace,
+		priority: priority,
+		stopCh:   make(chan struct{}),
+		writeCh:  make(chan *writeRequest, 1), // Buffered for one pending write
 	}
 }
 
@@ -274,7 +367,19 @@ func newCTSMonitor(fd int) *ctsMonitor {
 // This goroutine pre-queues write operations and executes them immediately when CTS goes LOW
 func (c *ctsMonitor) start() {
 	go func() {
+		if c.priority > 0 {
+			// Never unlocked, so the thread exits with the goroutine
+			// rather than returning to the scheduler at raised priority
+			runtime.LockOSThread()
+			if err := setFIFOPriority(c.priority); err != nil {
+				c.log.Warn("CTS monitor runs at normal priority", "priority", c.priority, "err", err)
+			} else {
+				c.latency.setRealtime(true)
+			}
+		}
+
 		var pendingWrite *writeRequest
+		var woke time.Time // When CTS last changed while pendingWrite waited
 
 		for {
 			// If no pending write, wait for either a write request or stop signal
@@ -288,8 +393,9 @@ func (c *ctsMonitor) start() {
 			}
 
 			// We have a pending write, check if CTS is already active
-			status, err := getModemStatus(c.fd)
+			status, err := getModemStatus(c.trace, c.fd)
 			if err != nil {
+				c.log.Error("failed to read CTS", "err", err)
 				// Send error back and clear pending write
 				if pendingWrite != nil {
 					pendingWrite.resultCh <- writeResult{0, err}
@@ -301,7 +407,17 @@ func (c *ctsMonitor) start() {
 			// Check if CTS is active (TIOCM_CTS bit set = ready to send)
 			if status&unix.TIOCM_CTS != 0 {
 				// CTS is active, write immediately
-				n, err := unix.Write(c.fd, pendingWrite.data)
+				if !woke.IsZero() {
+					c.latency.observe(time.Since(woke))
+					woke = time.Time{}
+				}
+				n, err := writeFD(c.fd, pendingWrite.data)
+				if n > 0 {
+					c.tap.tx(pendingWrite.data[:n])
+				}
+				if c.trace != nil {
+					c.trace.log("write", err, "-> %d of %d bytes on CTS", n, len(pendingWrite.data))
+				}
 				pendingWrite.resultCh <- writeResult{n, err}
 				pendingWrite = nil
 				continue
@@ -309,30 +425,49 @@ func (c *ctsMonitor) start() {
 
 			// CTS is not active, wait for it to change
 			// Use non-blocking wait with timeout to allow checking stop signal
-			done := make(chan error, 1)
-			go func() {
-				done <- waitForCTSChange(c.fd)
-			}()
-
-			select {
-			case <-c.stopCh:
-				// Port closing, send error to pending write
-				if pendingWrite != nil {
+			c.log.Debug("write waiting for CTS", "bytes", len(pendingWrite.data))
+			var wake ctsWake
+			if c.priority > 0 {
+				// Wait on this thread, so the write follows the wakeup
+				// without another goroutine being scheduled in between
+				err := waitForCTSChange(c.trace, c.fd)
+				wake = ctsWake{at: time.Now(), err: err}
+				select {
+				case <-c.stopCh:
 					pendingWrite.resultCh <- writeResult{0, ErrPortClosed}
-					pendingWrite = nil
+					return
+				default:
 				}
-				return
-			case err := <-done:
-				if err != nil {
-					// Error waiting for CTS change
+			} else {
+				done := make(chan ctsWake, 1)
+				go func() {
+					err := waitForCTSChange(c.trace, c.fd)
+					done <- ctsWake{at: time.Now(), err: err}
+				}()
+
+				select {
+				case <-c.stopCh:
+					// Port closing, send error to pending write
 					if pendingWrite != nil {
-						pendingWrite.resultCh <- writeResult{0, err}
+						pendingWrite.resultCh <- writeResult{0, ErrPortClosed}
 						pendingWrite = nil
 					}
 					return
+				case wake = <-done:
+				}
+			}
+
+			if wake.err != nil {
+				// Error waiting for CTS change
+				c.log.Error("failed to wait for CTS change", "err", wake.err)
+				if pendingWrite != nil {
+					pendingWrite.resultCh <- writeResult{0, wake.err}
+					pendingWrite = nil
 				}
-				// CTS changed, loop back to check if it's active now
+				return
 			}
+			// CTS changed, loop back to check if it's active now
+			woke = wake.at
 		}
 	}()
 }
@@ -358,6 +493,7 @@ func (c *ctsMonitor) queueWrite(data []byte, timeout time.Duration) (int, error)
 	case c.writeCh <- req:
 		// Request queued successfully, wait for result
 	case <-timer.C:
+		c.log.Warn("CTS timeout waiting to queue write", "bytes", len(data), "timeout", timeout)
 		return 0, ErrCTSTimeout
 	case <-c.stopCh:
 		return 0, ErrPortClosed
@@ -368,6 +504,7 @@ func (c *ctsMonitor) queueWrite(data []byte, timeout time.Duration) (int, error)
 	case result := <-req.resultCh:
 		return result.n, result.err
 	case <-timer.C:
+		c.log.Warn("CTS timeout waiting to write", "bytes", len(data), "timeout", timeout)
 		return 0, ErrCTSTimeout
 	case <-c.stopCh:
 		return 0, ErrPortClosed
@@ -375,6 +512,8 @@ func (c *ctsMonitor) queueWrite(data []byte, timeout time.Duration) (int, error)
 }
 
 // Open opens a serial port with the given device path and options
+// The device may also be a network URL: "tcp://host:port" for a raw socket or
+// "rfc2217://host:port" for a Telnet COM-PORT-OPTION server (e.g., ser2net).
 func Open(device string, opts ...Option) (Port, error) {
 	// Apply default configuration
 	config := DefaultConfig()
@@ -384,6 +523,21 @@ func Open(device string, opts ...Option) (Port, error) {
 		}
 	}
 
+	ctx, end := config.startSpan(context.Background(), SpanOpen,
+		slog.String("device", device), slog.Int("baud_rate", config.BaudRate))
+	p, err := openPort(device, config)
+	countError(config.Instrumentation, ctx, err)
+	end(err)
+	return p, err
+}
+
+// openPort opens a local or network device with a complete configuration
+func openPort(device string, config Config) (Port, error) {
+	// URL-style devices (tcp://host:port, rfc2217://host:port) are remote ports
+	if isNetworkDevice(device) {
+		return openNetwork(device, config)
+	}
+
 	// Validate flow control configuration
 	if config.FlowControl == FlowControlCTS && config.InitialRTS == nil {
 		return nil, fmt.Errorf("CTS flow control requires WithInitialRTS(true) to assert RTS")
@@ -403,20 +557,24 @@ func Open(device string, opts ...Option) (Port, error) {
 		return nil, fmt.Errorf("failed to open %s: %v", device, err)
 	}
 
+	trace := newIOTracer(config)
+
 	// Configure port with simple termios setup
-	if err := configurePort(fd, config); err != nil {
-		unix.Close(fd)
-		return nil, err
+	if !config.KeepSettings {
+		if err := configurePort(trace, fd, config); err != nil {
+			unix.Close(fd)
+			return nil, err
+		}
 	}
 
 	// Apply initial signal states if configured
 	if config.InitialRTS != nil {
-		if err := setRTSSignal(fd, *config.InitialRTS); err != nil {
+		if err := setRTSSignal(trace, fd, *config.InitialRTS); err != nil {
 			unix.Close(fd)
 			return nil, fmt.Errorf("failed to set initial RTS: %v", err)
 		}
 		// Verify RTS was set
-		status, err := getModemStatus(fd)
+		status, err := getModemStatus(trace, fd)
 		if err != nil {
 			unix.Close(fd)
 			return nil, fmt.Errorf("failed to verify initial RTS: %v", err)
@@ -428,7 +586,7 @@ func Open(device string, opts ...Option) (Port, error) {
 		}
 	}
 	if config.InitialDTR != nil {
-		if err := setDTR(fd, *config.InitialDTR); err != nil {
+		if err := setDTR(trace, fd, *config.InitialDTR); err != nil {
 			unix.Close(fd)
 			return nil, fmt.Errorf("failed to set initial DTR: %v", err)
 		}
@@ -438,21 +596,45 @@ func Open(device string, opts ...Option) (Port, error) {
 		fd:     fd,
 		config: config,
 		closed: false,
+		tap:    newTrafficTap(config),
+		trace:  trace,
 	}
+	p.signals = newSignalWatcher(p.openSignalSource)
+	p.reader = newAsyncReader(fd, p.tap)
 
 	// Set up CTS monitoring if flow control is enabled
 	if config.FlowControl == FlowControlCTS {
-		p.ctsMonitor = newCTSMonitor(fd)
+		p.ctsMonitor = newCTSMonitor(fd, config.Logger, p.tap, trace, config.CTSPriority)
 		p.ctsMonitor.start()
 	}
 
+	config.Logger.Debug("port opened", "device", device, "baud", config.BaudRate,
+		"flow_control", int(config.FlowControl), "read_timeout", config.ReadTimeout)
 	return p, nil
 }
 
+// parityCflag returns the termios c_cflag bits selecting parity
+// Mark and space parity are CMSPAR ("stick" parity), with PARODD choosing
+// a parity bit that is always 1 (mark) rather than always 0 (space).
+func parityCflag(parity Parity) uint32 {
+	switch parity {
+	case ParityOdd:
+		return unix.PARENB | unix.PARODD
+	case ParityEven:
+		return unix.PARENB
+	case ParityMark:
+		return unix.PARENB | unix.CMSPAR | unix.PARODD
+	case ParitySpace:
+		return unix.PARENB | unix.CMSPAR
+	default:
+		return 0
+	}
+}
+
 // configurePort configures the serial port using clean unix package calls
-func configurePort(fd int, config Config) error {
+func configurePort(tr *ioTracer, fd int, config Config) error {
 	// Get current termios settings
-	termios, err := unix.IoctlGetTermios(fd, unix.TCGETS)
+	termios, err := tcgets(tr, fd)
 	if err != nil {
 		return fmt.Errorf("failed to get termios: %v", err)
 	}
@@ -500,12 +682,7 @@ func configurePort(fd int, config Config) error {
 	}
 
 	// Parity
-	switch config.Parity {
-	case ParityOdd:
-		termios.Cflag |= unix.PARENB | unix.PARODD
-	case ParityEven:
-		termios.Cflag |= unix.PARENB
-	}
+	termios.Cflag |= parityCflag(config.Parity)
 
 	// Flow control
 	if config.FlowControl == FlowControlRTSCTS {
@@ -513,7 +690,7 @@ func configurePort(fd int, config Config) error {
 	}
 
 	// Apply settings immediately
-	if err := unix.IoctlSetTermios(fd, unix.TCSETS, termios); err != nil {
+	if err := tcsets(tr, fd, termios); err != nil {
 		return fmt.Errorf("failed to set termios: %v", err)
 	}
 
@@ -522,6 +699,11 @@ func configurePort(fd int, config Config) error {
 
 // Close closes the serial port
 func (p *port) Close() error {
+	// Before taking the lock: the watcher takes it when starting up
+	if p.signals != nil {
+		p.signals.stop()
+	}
+
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
@@ -534,13 +716,38 @@ func (p *port) Close() error {
 		p.ctsMonitor.stop()
 	}
 
+	if p.reader != nil {
+		p.reader.stop()
+	}
+
 	err := unix.Close(p.fd)
 	p.closed = true
+	p.config.Logger.Debug("port closed", "err", err)
 	return err
 }
 
+// readFD is unix.Read reporting 0 bytes rather than -1 on failure, as
+// io.Reader requires
+func readFD(fd int, buf []byte) (int, error) {
+	n, err := unix.Read(fd, buf)
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// writeFD is unix.Write reporting 0 bytes rather than -1 on failure, as
+// io.Writer requires
+func writeFD(fd int, data []byte) (int, error) {
+	n, err := unix.Write(fd, data)
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
 // Read reads data from the serial port
-func (p *port) Read(buf []byte) (int, error) {
+func (p *port) Read(buf []byte) (n int, err error) {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
@@ -548,11 +755,22 @@ func (p *port) Read(buf []byte) (int, error) {
 		return 0, ErrPortClosed
 	}
 
-	return unix.Read(p.fd, buf)
+	n, err = readFD(p.fd, buf)
+	if n > 0 {
+		p.tap.rx(buf[:n])
+	}
+	p.config.record(context.Background(), CounterBytesRead, n, err)
+	return n, err
 }
 
 // Write writes data to the serial port
-func (p *port) Write(data []byte) (int, error) {
+func (p *port) Write(data []byte) (n int, err error) {
+	ctx, end := p.config.startSpan(context.Background(), SpanWrite, slog.Int("bytes", len(data)))
+	defer func() {
+		p.config.record(ctx, CounterBytesWritten, n, err)
+		end(err)
+	}()
+
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
@@ -563,15 +781,29 @@ func (p *port) Write(data []byte) (int, error) {
 	// Handle CTS flow control if enabled
 	// Data is pre-queued and written immediately when CTS goes LOW
 	if p.config.FlowControl == FlowControlCTS && p.ctsMonitor != nil {
-		return p.ctsMonitor.queueWrite(data, p.config.CTSTimeout)
+		_, endWait := p.config.startSpan(ctx, SpanCTSWait)
+		n, err = p.ctsMonitor.queueWrite(data, p.config.CTSTimeout)
+		endWait(err)
+		return n, err
 	}
 
 	// No flow control, perform direct write
-	return unix.Write(p.fd, data)
+	n, err = writeFD(p.fd, data)
+	if n > 0 {
+		p.tap.tx(data[:n])
+	}
+	return n, err
 }
 
 // WriteContext writes data with context timeout support
-func (p *port) WriteContext(ctx context.Context, data []byte) (int, error) {
+// With instrumentation enabled the write span is a child of ctx's span.
+func (p *port) WriteContext(ctx context.Context, data []byte) (n int, err error) {
+	ctx, end := p.config.startSpan(ctx, SpanWrite, slog.Int("bytes", len(data)))
+	defer func() {
+		p.config.record(ctx, CounterBytesWritten, n, err)
+		end(err)
+	}()
+
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
@@ -601,8 +833,10 @@ func (p *port) WriteContext(ctx context.Context, data []byte) (int, error) {
 		resultCh := make(chan writeResult, 1)
 
 		// Queue write in goroutine to allow context cancellation
+		_, endWait := p.config.startSpan(ctx, SpanCTSWait)
 		go func() {
 			n, err := p.ctsMonitor.queueWrite(data, timeout)
+			endWait(err)
 			resultCh <- writeResult{n: n, err: err}
 		}()
 
@@ -623,7 +857,10 @@ func (p *port) WriteContext(ctx context.Context, data []byte) (int, error) {
 	resultCh := make(chan directWriteResult, 1)
 
 	go func() {
-		n, err := unix.Write(p.fd, data)
+		n, err := writeFD(p.fd, data)
+		if n > 0 {
+			p.tap.tx(data[:n])
+		}
 		resultCh <- directWriteResult{n: n, err: err}
 	}()
 
@@ -636,41 +873,43 @@ func (p *port) WriteContext(ctx context.Context, data []byte) (int, error) {
 }
 
 // ReadContext reads data with context timeout support
+// If ctx ends first, the read it started still runs until data or the read
+// timeout arrives: it may write to buf after ReadContext returned, and the
+// bytes it receives are lost.
 func (p *port) ReadContext(ctx context.Context, buf []byte) (int, error) {
+	n, _, err := p.ReadTimestamped(ctx, buf)
+	return n, err
+}
+
+// ReadTimestamped reads data with context timeout support and returns the
+// time the read syscall returned
+func (p *port) ReadTimestamped(ctx context.Context, buf []byte) (n int, at time.Time, err error) {
+	defer func() { p.config.record(ctx, CounterBytesRead, n, err) }()
+
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
 	if p.closed {
-		return 0, ErrPortClosed
+		return 0, time.Time{}, ErrPortClosed
 	}
 
 	// Check if context is already cancelled
 	select {
 	case <-ctx.Done():
-		return 0, ctx.Err()
+		return 0, time.Time{}, ctx.Err()
 	default:
 	}
 
-	// Create channel for read result
-	type readResult struct {
-		n   int
-		err error
-	}
-	resultCh := make(chan readResult, 1)
-
-	// Perform read in goroutine
-	go func() {
-		n, err := unix.Read(p.fd, buf)
-		resultCh <- readResult{n: n, err: err}
-	}()
-
-	// Wait for read completion or context cancellation
-	select {
-	case result := <-resultCh:
-		return result.n, result.err
-	case <-ctx.Done():
-		return 0, ctx.Err()
+	// Without a way to cancel, read on the caller's goroutine
+	if ctx.Done() == nil {
+		n, err = readFD(p.fd, buf)
+		at = time.Now()
+		if n > 0 {
+			p.tap.rx(buf[:n])
+		}
+		return n, at, err
 	}
+	return p.reader.read(ctx, buf)
 }
 
 // GetCTSStatus returns the current CTS status
@@ -682,7 +921,7 @@ func (p *port) GetCTSStatus() (bool, error) {
 		return false, ErrPortClosed
 	}
 
-	status, err := getModemStatus(p.fd)
+	status, err := getModemStatus(p.trace, p.fd)
 	if err != nil {
 		return false, err
 	}
@@ -699,19 +938,12 @@ func (p *port) GetModemSignals() (ModemSignals, error) {
 		return ModemSignals{}, ErrPortClosed
 	}
 
-	status, err := getModemStatus(p.fd)
+	status, err := getModemStatus(p.trace, p.fd)
 	if err != nil {
 		return ModemSignals{}, err
 	}
 
-	return ModemSignals{
-		CTS: status&unix.TIOCM_CTS != 0,
-		DSR: status&unix.TIOCM_DSR != 0,
-		RI:  status&unix.TIOCM_RI != 0,
-		DCD: status&unix.TIOCM_CAR != 0,
-		RTS: status&unix.TIOCM_RTS != 0,
-		DTR: status&unix.TIOCM_DTR != 0,
-	}, nil
+	return modemSignalsFromStatus(status), nil
 }
 
 // SetRTS manually sets the RTS signal state
@@ -726,7 +958,7 @@ func (p *port) SetRTS(state bool) error {
 	}
 
 	// Read current modem status
-	status, err := unix.IoctlGetInt(p.fd, unix.TIOCMGET)
+	status, err := tiocmget(p.trace, p.fd)
 	if err != nil {
 		return err
 	}
@@ -739,7 +971,7 @@ func (p *port) SetRTS(state bool) error {
 	}
 
 	// Write back
-	return unix.IoctlSetPointerInt(p.fd, unix.TIOCMSET, status)
+	return tiocmset(p.trace, p.fd, status)
 }
 
 // GetRTS returns current RTS signal state
@@ -751,7 +983,7 @@ func (p *port) GetRTS() (bool, error) {
 		return false, ErrPortClosed
 	}
 
-	status, err := getModemStatus(p.fd)
+	status, err := getModemStatus(p.trace, p.fd)
 	if err != nil {
 		return false, err
 	}
@@ -771,7 +1003,7 @@ func (p *port) SetDTR(state bool) error {
 	}
 
 	// Read current modem status
-	status, err := unix.IoctlGetInt(p.fd, unix.TIOCMGET)
+	status, err := tiocmget(p.trace, p.fd)
 	if err != nil {
 		return err
 	}
@@ -784,7 +1016,7 @@ func (p *port) SetDTR(state bool) error {
 	}
 
 	// Write back
-	return unix.IoctlSetPointerInt(p.fd, unix.TIOCMSET, status)
+	return tiocmset(p.trace, p.fd, status)
 }
 
 // GetDTR returns current DTR signal state
@@ -796,7 +1028,7 @@ func (p *port) GetDTR() (bool, error) {
 		return false, ErrPortClosed
 	}
 
-	status, err := getModemStatus(p.fd)
+	status, err := getModemStatus(p.trace, p.fd)
 	if err != nil {
 		return false, err
 	}
@@ -806,7 +1038,21 @@ func (p *port) GetDTR() (bool, error) {
 
 // WaitForSignalChange blocks until any monitored signal changes state
 // Returns new signal states and which signal(s) changed
+// All waits on a port share one goroutine blocked in TIOCMIWAIT, so a wait
+// that times out leaves nothing behind.
 func (p *port) WaitForSignalChange(mask SignalMask, timeout time.Duration) (ModemSignals, SignalMask, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	return p.waitForSignal(context.Background(), mask, timer.C)
+}
+
+// WaitForSignalChangeContext waits with context cancellation support
+func (p *port) WaitForSignalChangeContext(ctx context.Context, mask SignalMask) (ModemSignals, SignalMask, error) {
+	return p.waitForSignal(ctx, mask, nil)
+}
+
+func (p *port) waitForSignal(ctx context.Context, mask SignalMask, timeout <-chan time.Time) (ModemSignals, SignalMask, error) {
 	if mask == 0 {
 		return ModemSignals{}, 0, ErrInvalidSignalMask
 	}
@@ -819,152 +1065,157 @@ func (p *port) WaitForSignalChange(mask SignalMask, timeout time.Duration) (Mode
 	fd := p.fd
 	p.mu.RUnlock()
 
+	// Check if context is already cancelled
+	select {
+	case <-ctx.Done():
+		return ModemSignals{}, 0, ctx.Err()
+	default:
+	}
+
 	// Get initial signal state
-	oldStatus, err := getModemStatus(fd)
+	oldStatus, err := getModemStatus(p.trace, fd)
 	if err != nil {
 		return ModemSignals{}, 0, err
 	}
 
-	// Convert mask to TIOCM bits
-	tiocmBits := signalMaskToTIOCM(mask)
-
-	// Channel for wait result
-	type waitResult struct {
-		newStatus int
-		err       error
+	waiter, err := p.signals.subscribe(signalMaskToTIOCM(mask), oldStatus)
+	if err != nil {
+		return ModemSignals{}, 0, err
 	}
-	resultCh := make(chan waitResult, 1)
-
-	// Wait for signal change in goroutine
-	go func() {
-		err := unix.IoctlSetInt(fd, unix.TIOCMIWAIT, tiocmBits)
-		if err != nil {
-			resultCh <- waitResult{err: err}
-			return
-		}
-
-		// Get new status after change
-		newStatus, err := getModemStatus(fd)
-		resultCh <- waitResult{newStatus: newStatus, err: err}
-	}()
-
-	// Wait for result or timeout
-	timer := time.NewTimer(timeout)
-	defer timer.Stop()
 
 	select {
-	case result := <-resultCh:
+	case result := <-waiter.ch:
 		if result.err != nil {
 			return ModemSignals{}, 0, result.err
 		}
-
-		// Detect which signals changed
-		changed := detectSignalChanges(oldStatus, result.newStatus)
-
-		// Convert to ModemSignals
-		signals := ModemSignals{
-			CTS: result.newStatus&unix.TIOCM_CTS != 0,
-			DSR: result.newStatus&unix.TIOCM_DSR != 0,
-			RI:  result.newStatus&unix.TIOCM_RI != 0,
-			DCD: result.newStatus&unix.TIOCM_CAR != 0,
-			RTS: result.newStatus&unix.TIOCM_RTS != 0,
-			DTR: result.newStatus&unix.TIOCM_DTR != 0,
-		}
-
-		return signals, changed, nil
-
-	case <-timer.C:
+		return modemSignalsFromStatus(result.status), detectSignalChanges(oldStatus, result.status), nil
+	case <-timeout:
+		p.signals.unsubscribe(waiter)
 		return ModemSignals{}, 0, ErrSignalTimeout
+	case <-ctx.Done():
+		p.signals.unsubscribe(waiter)
+		return ModemSignals{}, 0, ctx.Err()
 	}
 }
 
-// WaitForSignalChangeContext waits with context cancellation support
-func (p *port) WaitForSignalChangeContext(ctx context.Context, mask SignalMask) (ModemSignals, SignalMask, error) {
-	if mask == 0 {
-		return ModemSignals{}, 0, ErrInvalidSignalMask
+// openSignalSource returns a source for the signal watcher on a duplicate
+// of the port's descriptor
+func (p *port) openSignalSource() (signalSource, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return nil, ErrPortClosed
 	}
+	fd, err := unix.Dup(p.fd)
+	if err != nil {
+		return nil, err
+	}
+	return &fdSignals{fd: fd, trace: p.trace}, nil
+}
 
+// DrainOutput waits until all output written to the port has been transmitted
+// It is tcdrain(3): for a UART it returns once the last bit has left the
+// shift register, so RTS can be released for RS-485 or the line
+// reconfigured without cutting a character short. Writes queued for CTS
+// are not yet written and are not waited for.
+func (p *port) DrainOutput() error {
 	p.mu.RLock()
+	defer p.mu.RUnlock()
+
 	if p.closed {
-		p.mu.RUnlock()
-		return ModemSignals{}, 0, ErrPortClosed
+		return ErrPortClosed
 	}
-	fd := p.fd
-	p.mu.RUnlock()
 
-	// Check if context is already cancelled
-	select {
-	case <-ctx.Done():
-		return ModemSignals{}, 0, ctx.Err()
-	default:
-	}
+	return tcdrain(p.trace, p.fd)
+}
 
-	// Get initial signal state
-	oldStatus, err := getModemStatus(fd)
-	if err != nil {
-		return ModemSignals{}, 0, err
-	}
+// SendBreak transmits pending output, then holds TX in the break state for duration
+func (p *port) SendBreak(duration time.Duration) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
 
-	// Convert mask to TIOCM bits
-	tiocmBits := signalMaskToTIOCM(mask)
+	if p.closed {
+		return ErrPortClosed
+	}
 
-	// Channel for wait result
-	type waitResult struct {
-		newStatus int
-		err       error
+	if err := tcdrain(p.trace, p.fd); err != nil {
+		return err
 	}
-	resultCh := make(chan waitResult, 1)
+	if err := tiocsbrk(p.trace, p.fd); err != nil {
+		return err
+	}
+	time.Sleep(duration)
+	return tioccbrk(p.trace, p.fd)
+}
 
-	// Wait for signal change in goroutine
-	go func() {
-		err := unix.IoctlSetInt(fd, unix.TIOCMIWAIT, tiocmBits)
-		if err != nil {
-			resultCh <- waitResult{err: err}
-			return
-		}
+// LineCounts returns the UART driver's interrupt counters
+// Drivers without counters, including PTYs, report ErrNotSupported.
+func (p *port) LineCounts() (LineCounts, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
 
-		// Get new status after change
-		newStatus, err := getModemStatus(fd)
-		resultCh <- waitResult{newStatus: newStatus, err: err}
-	}()
+	if p.closed {
+		return LineCounts{}, ErrPortClosed
+	}
 
-	// Wait for result or context cancellation
-	select {
-	case result := <-resultCh:
-		if result.err != nil {
-			return ModemSignals{}, 0, result.err
-		}
+	ic, err := tiocgicount(p.trace, p.fd)
+	if err == unix.ENOTTY || err == unix.EINVAL {
+		return LineCounts{}, ErrNotSupported
+	}
+	if err != nil {
+		return LineCounts{}, err
+	}
+	return LineCounts{
+		CTS:           int(ic.cts),
+		DSR:           int(ic.dsr),
+		RI:            int(ic.rng),
+		DCD:           int(ic.dcd),
+		RX:            int(ic.rx),
+		TX:            int(ic.tx),
+		Frame:         int(ic.frame),
+		Overrun:       int(ic.overrun),
+		Parity:        int(ic.parity),
+		Break:         int(ic.brk),
+		BufferOverrun: int(ic.bufOverrun),
+	}, nil
+}
 
-		// Detect which signals changed
-		changed := detectSignalChanges(oldStatus, result.newStatus)
-
-		// Convert to ModemSignals
-		signals := ModemSignals{
-			CTS: result.newStatus&unix.TIOCM_CTS != 0,
-			DSR: result.newStatus&unix.TIOCM_DSR != 0,
-			RI:  result.newStatus&unix.TIOCM_RI != 0,
-			DCD: result.newStatus&unix.TIOCM_CAR != 0,
-			RTS: result.newStatus&unix.TIOCM_RTS != 0,
-			DTR: result.newStatus&unix.TIOCM_DTR != 0,
-		}
+// BufferLevels returns the bytes waiting in the kernel's receive and
+// transmit buffers
+func (p *port) BufferLevels() (BufferLevels, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
 
-		return signals, changed, nil
+	if p.closed {
+		return BufferLevels{}, ErrPortClosed
+	}
 
-	case <-ctx.Done():
-		return ModemSignals{}, 0, ctx.Err()
+	in, err := tiocinq(p.trace, p.fd)
+	if err != nil {
+		return BufferLevels{}, err
+	}
+	out, err := tiocoutq(p.trace, p.fd)
+	if err != nil {
+		return BufferLevels{}, err
 	}
+	return BufferLevels{Input: in, Output: out}, nil
 }
 
-// DrainOutput waits until all output written to the port has been transmitted
-func (p *port) DrainOutput() error {
+// LineSettings reads the driver's current termios back
+func (p *port) LineSettings() (LineSettings, error) {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
 	if p.closed {
-		return ErrPortClosed
+		return LineSettings{}, ErrPortClosed
 	}
 
-	return unix.IoctlSetInt(p.fd, unix.TCSBRK, 1)
+	termios, err := tcgets(p.trace, p.fd)
+	if err != nil {
+		return LineSettings{}, err
+	}
+	return decodeTermios(termios), nil
 }
 
 // FlushInput discards any unread input data in the kernel buffer
@@ -976,34 +1227,78 @@ func (p *port) FlushInput() error {
 		return ErrPortClosed
 	}
 
-	return unix.IoctlSetInt(p.fd, unix.TCFLSH, unix.TCIFLUSH)
+	return tcflsh(p.trace, p.fd, unix.TCIFLUSH)
 }
 
 // DrainInput reads and discards all pending input data until the buffer is empty.
 // It first flushes the kernel buffer, then actively reads until no more data arrives,
-// ensuring data in transit or hardware FIFOs is also cleared.
+// ensuring data in transit or hardware FIFOs is also cleared. A device that keeps
+// sending is given up on after DefaultDrainTimeout or DefaultDrainLimit bytes
+// with ErrDrainIncomplete.
 func (p *port) DrainInput() error {
+	return drainWithDefaults(p.DrainInputContext)
+}
+
+// DrainInputContext flushes input, then discards what still arrives until the
+// line has been quiet for 50 ms, returning the number of bytes discarded
+// It returns ctx.Err() when ctx ends first, and ErrDrainIncomplete once more
+// than limit bytes were discarded (DefaultDrainLimit if limit <= 0). Unlike
+// reads, it does not wait for the read timeout (VTIME).
+func (p *port) DrainInputContext(ctx context.Context, limit int) (int, error) {
+	if limit <= 0 {
+		limit = DefaultDrainLimit
+	}
+
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
 	if p.closed {
-		return ErrPortClosed
+		return 0, ErrPortClosed
 	}
 
 	// Flush kernel buffer first
-	if err := unix.IoctlSetInt(p.fd, unix.TCFLSH, unix.TCIFLUSH); err != nil {
-		return err
+	if err := tcflsh(p.trace, p.fd, unix.TCIFLUSH); err != nil {
+		return 0, err
 	}
 
 	// Read until no more data arrives
-	buf := make([]byte, 256)
+	scratch := getScratch()
+	defer putScratch(scratch)
+	buf := *scratch
+	drained := 0
 	for {
-		n, err := unix.Read(p.fd, buf)
+		if err := ctx.Err(); err != nil {
+			return drained, err
+		}
+		wait := drainQuiet
+		if deadline, ok := ctx.Deadline(); ok {
+			wait = min(wait, time.Until(deadline))
+		}
+		if wait <= 0 {
+			<-ctx.Done()
+			return drained, ctx.Err()
+		}
+		ready, err := pollInput(p.fd, wait)
 		if err != nil {
-			return err
+			return drained, err
+		}
+		if !ready {
+			if wait == drainQuiet {
+				return drained, nil
+			}
+			continue // Cut short by the deadline
+		}
+
+		n, err := readFD(p.fd, buf)
+		if err != nil {
+			return drained, err
 		}
 		if n == 0 {
-			return nil
+			return drained, nil
+		}
+		drained += n
+		if drained > limit {
+			return drained, ErrDrainIncomplete
 		}
 	}
 }
@@ -1017,5 +1312,19 @@ func (p *port) FlushOutput() error {
 		return ErrPortClosed
 	}
 
-	return unix.IoctlSetInt(p.fd, unix.TCFLSH, unix.TCOFLUSH)
+	return tcflsh(p.trace, p.fd, unix.TCOFLUSH)
+}
+
+// PulseDTR deasserts DTR for width and then reasserts it
+// Boards with an auto-reset circuit on DTR (e.g., Arduino) reboot on the
+// falling edge; input received before the pulse is discarded.
+func PulseDTR(p Port, width time.Duration) error {
+	if err := p.SetDTR(false); err != nil {
+		return fmt.Errorf("failed to deassert DTR: %w", err)
+	}
+	time.Sleep(width)
+	if err := p.SetDTR(true); err != nil {
+		return fmt.Errorf("failed to assert DTR: %w", err)
+	}
+	return p.FlushInput()
 }
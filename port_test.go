@@ -5,6 +5,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"golang.org/x/sys/unix"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -117,6 +119,21 @@ func TestInvalidStopBits(t *testing.T) {
 	}
 }
 
+func TestParseParity(t *testing.T) {
+	for _, p := range []Parity{ParityNone, ParityOdd, ParityEven, ParityMark, ParitySpace} {
+		got, err := ParseParity(p.String())
+		if err != nil || got != p {
+			t.Errorf("ParseParity(%q) = %v, %v, want %v", p.String(), got, err, p)
+		}
+	}
+	if got, err := ParseParity("E"); err != nil || got != ParityEven {
+		t.Errorf("ParseParity(E) = %v, %v, want even", got, err)
+	}
+	if _, err := ParseParity("parity"); err == nil {
+		t.Error("ParseParity(parity) succeeded, want error")
+	}
+}
+
 func TestGetBaudRate(t *testing.T) {
 	tests := []struct {
 		input    int
@@ -229,3 +246,41 @@ func TestContextTimeout(t *testing.T) {
 		t.Error("Expected timeout error")
 	}
 }
+
+// failFD points the port's descriptor at /dev/null opened with mode, so
+// reads or writes on it fail with EBADF
+func failFD(t *testing.T, p Port, mode int) {
+	t.Helper()
+	null, err := unix.Open("/dev/null", mode, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unix.Close(null)
+	if err := unix.Dup3(null, p.(*port).fd, unix.O_CLOEXEC); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFailedIOReportsZeroBytes(t *testing.T) {
+	port, _ := openPTYPort(t, WithReadTimeout(100*time.Millisecond))
+	failFD(t, port, unix.O_WRONLY)
+
+	buf := make([]byte, 16)
+	if n, err := port.Read(buf); n != 0 || err == nil {
+		t.Errorf("Read = %d, %v, want 0 and an error", n, err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if n, at, err := ReadTimestamped(ctx, port, buf); n != 0 || err == nil {
+		t.Errorf("ReadTimestamped = %d, %v, %v, want 0 and an error", n, at, err)
+	}
+
+	port, _ = openPTYPort(t)
+	failFD(t, port, unix.O_RDONLY)
+	if n, err := port.Write([]byte("x")); n != 0 || err == nil {
+		t.Errorf("Write = %d, %v, want 0 and an error", n, err)
+	}
+	if n, err := port.WriteContext(ctx, []byte("x")); n != 0 || err == nil {
+		t.Errorf("WriteContext = %d, %v, want 0 and an error", n, err)
+	}
+}
@@ -0,0 +1,51 @@
+package serial
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestOpenPTY(t *testing.T) {
+	master, slavePath, err := OpenPTY()
+	if err != nil {
+		t.Skipf("PTY not available: %v", err)
+	}
+	defer master.Close()
+
+	port, err := Open(slavePath, WithReadTimeout(100*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Open(%s) failed: %v", slavePath, err)
+	}
+	defer port.Close()
+
+	// Master to slave
+	want := []byte{0x00, 0x0A, 0x0D, 0x7F, 0xFF}
+	if _, err := master.Write(want); err != nil {
+		t.Fatalf("master write failed: %v", err)
+	}
+	var got []byte
+	buf := make([]byte, 16)
+	for deadline := time.Now().Add(time.Second); len(got) < len(want) && time.Now().Before(deadline); {
+		n, err := port.Read(buf)
+		if err != nil {
+			t.Fatalf("Read failed: %v", err)
+		}
+		got = append(got, buf[:n]...)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("slave read % X, want % X (raw mode)", got, want)
+	}
+
+	// Slave to master
+	if _, err := port.Write([]byte("pong")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	n, err := master.Read(buf)
+	if err != nil {
+		t.Fatalf("master read failed: %v", err)
+	}
+	if string(buf[:n]) != "pong" {
+		t.Errorf("master read %q, want pong", buf[:n])
+	}
+}
@@ -0,0 +1,47 @@
+package serial
+
+// ReadSlab carves read buffers out of larger blocks, so received data can
+// be handed to consumers without copying it out of a reused buffer and
+// without an allocation per read
+//
+//	slab := serial.NewReadSlab(64 * 1024)
+//	for {
+//	    n, err := port.ReadContext(ctx, slab.Next(4096))
+//	    if n > 0 {
+//	        out <- slab.Take(n) // Owned by the receiver
+//	    }
+//	    ...
+//	}
+//
+// Slices returned by Take belong to the caller and are never written by the
+// slab again; a block is freed once no taken slice refers to it. A
+// ReadSlab is not safe for concurrent use.
+type ReadSlab struct {
+	block     []byte // Taken bytes of the current block
+	blockSize int
+}
+
+// NewReadSlab returns a ReadSlab allocating blocks of blockSize bytes
+func NewReadSlab(blockSize int) *ReadSlab {
+	return &ReadSlab{blockSize: blockSize}
+}
+
+// Next returns a buffer for a read of up to size bytes, starting a new
+// block when the current one has less room left
+func (s *ReadSlab) Next(size int) []byte {
+	used := len(s.block)
+	if cap(s.block)-used < size {
+		s.block = make([]byte, 0, max(s.blockSize, size))
+		used = 0
+	}
+	return s.block[used : used+size]
+}
+
+// Take claims the first n bytes of the buffer Next returned last
+// Capacity is clipped, so appending to the result copies instead of
+// overwriting the next read.
+func (s *ReadSlab) Take(n int) []byte {
+	used := len(s.block)
+	s.block = s.block[:used+n]
+	return s.block[used : used+n : used+n]
+}
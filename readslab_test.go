@@ -0,0 +1,49 @@
+package serial
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestReadSlab(t *testing.T) {
+	slab := NewReadSlab(16)
+
+	buf := slab.Next(8)
+	copy(buf, "abc")
+	first := slab.Take(3)
+
+	buf = slab.Next(8)
+	copy(buf, "defgh")
+	second := slab.Take(5)
+
+	if string(first) != "abc" || string(second) != "defgh" {
+		t.Fatalf("taken %q, %q", first, second)
+	}
+	if cap(first) != 3 {
+		t.Errorf("cap(first) = %d, want 3 so appends cannot overwrite later reads", cap(first))
+	}
+
+	// Only 8 bytes are left, too few for a 10-byte read
+	buf = slab.Next(10)
+	copy(buf, bytes.Repeat([]byte("z"), 10))
+	slab.Take(10)
+	if string(first) != "abc" || string(second) != "defgh" {
+		t.Errorf("a new block overwrote taken data: %q, %q", first, second)
+	}
+
+	// Reads larger than the block size get a block of their own
+	if got := len(slab.Next(100)); got != 100 {
+		t.Errorf("len(Next(100)) = %d", got)
+	}
+}
+
+func TestReadSlabAllocations(t *testing.T) {
+	slab := NewReadSlab(64 * 1024)
+	allocs := testing.AllocsPerRun(1000, func() {
+		slab.Next(4096)
+		slab.Take(16)
+	})
+	if allocs > 0.01 {
+		t.Errorf("%.3f allocations per read, want one per block", allocs)
+	}
+}
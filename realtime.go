@@ -0,0 +1,114 @@
+package serial
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// WithCTSRealtime runs the CTS monitor of FlowControlCTS on a dedicated OS
+// thread at SCHED_FIFO priority (1-99)
+// With FlowControlCTS a write must reach the UART within the window CTS
+// stays active, which for some radio modules is 2 ms. Normally the monitor
+// waits for CTS on a helper goroutine and is then scheduled like any other
+// goroutine; with this option it waits on its own thread and writes from
+// there as soon as it wakes. Raising the priority needs CAP_SYS_NICE or an
+// RLIMIT_RTPRIO of at least priority; without either the thread is still
+// dedicated but runs at normal priority, which CTSLatency reports.
+func WithCTSRealtime(priority int) Option {
+	return func(c *Config) error {
+		if priority < 1 || priority > 99 {
+			return ErrInvalidConfig
+		}
+		c.CTSPriority = priority
+		return nil
+	}
+}
+
+// CTSLatency measures how quickly the CTS monitor writes once CTS goes
+// active: from the wakeup of its TIOCMIWAIT to the write system call
+// Only writes that had to wait for CTS are counted.
+type CTSLatency struct {
+	Writes   int           // Writes sent on a CTS wakeup
+	Last     time.Duration // Latency of the most recent such write
+	Min, Max time.Duration
+	Mean     time.Duration
+	Realtime bool // The monitor thread runs at SCHED_FIFO priority
+}
+
+// CTSLatencyReporter is implemented by ports that measure CTS wakeup latency
+type CTSLatencyReporter interface {
+	// CTSLatency returns the latencies measured since the port was opened
+	CTSLatency() (CTSLatency, error)
+}
+
+// GetCTSLatency returns the CTS wakeup-to-write latencies of p
+// Returns ErrNotSupported if p does not use FlowControlCTS or cannot
+// measure them, such as a network port.
+func GetCTSLatency(p Port) (CTSLatency, error) {
+	if r, ok := p.(CTSLatencyReporter); ok {
+		return r.CTSLatency()
+	}
+	return CTSLatency{}, ErrNotSupported
+}
+
+// CTSLatency returns the CTS monitor's wakeup-to-write latencies
+// Ports without FlowControlCTS have no monitor and report ErrNotSupported.
+func (p *port) CTSLatency() (CTSLatency, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return CTSLatency{}, ErrPortClosed
+	}
+	if p.ctsMonitor == nil {
+		return CTSLatency{}, ErrNotSupported
+	}
+	return p.ctsMonitor.latency.get(), nil
+}
+
+// latencyStats accumulates CTSLatency
+type latencyStats struct {
+	mu    sync.Mutex
+	stats CTSLatency
+	total time.Duration
+}
+
+func (l *latencyStats) observe(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	s := &l.stats
+	if s.Writes == 0 || d < s.Min {
+		s.Min = d
+	}
+	if d > s.Max {
+		s.Max = d
+	}
+	s.Writes++
+	s.Last = d
+	l.total += d
+	s.Mean = l.total / time.Duration(s.Writes)
+}
+
+func (l *latencyStats) setRealtime(realtime bool) {
+	l.mu.Lock()
+	l.stats.Realtime = realtime
+	l.mu.Unlock()
+}
+
+func (l *latencyStats) get() CTSLatency {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.stats
+}
+
+// setFIFOPriority moves the calling thread to SCHED_FIFO at priority
+func setFIFOPriority(priority int) error {
+	return unix.SchedSetAttr(0, &unix.SchedAttr{
+		Size:     unix.SizeofSchedAttr,
+		Policy:   unix.SCHED_FIFO,
+		Priority: uint32(priority),
+	}, 0)
+}
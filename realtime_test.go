@@ -0,0 +1,78 @@
+package serial
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestWithCTSRealtime(t *testing.T) {
+	config := DefaultConfig()
+	if err := WithCTSRealtime(50)(&config); err != nil {
+		t.Fatalf("WithCTSRealtime(50) failed: %v", err)
+	}
+	if config.CTSPriority != 50 {
+		t.Errorf("CTSPriority = %d, want 50", config.CTSPriority)
+	}
+	for _, priority := range []int{0, -1, 100} {
+		if err := WithCTSRealtime(priority)(&config); err != ErrInvalidConfig {
+			t.Errorf("WithCTSRealtime(%d) error = %v, want ErrInvalidConfig", priority, err)
+		}
+	}
+}
+
+func TestLatencyStats(t *testing.T) {
+	var l latencyStats
+	for _, d := range []time.Duration{300 * time.Microsecond, 100 * time.Microsecond, 200 * time.Microsecond} {
+		l.observe(d)
+	}
+	want := CTSLatency{
+		Writes: 3,
+		Last:   200 * time.Microsecond,
+		Min:    100 * time.Microsecond,
+		Max:    300 * time.Microsecond,
+		Mean:   200 * time.Microsecond,
+	}
+	if got := l.get(); got != want {
+		t.Errorf("stats = %+v, want %+v", got, want)
+	}
+}
+
+func TestSetFIFOPriority(t *testing.T) {
+	errCh := make(chan error, 1)
+	go func() {
+		// The thread exits with the goroutine, so the test's threads keep
+		// their scheduling
+		runtime.LockOSThread()
+		if err := setFIFOPriority(10); err != nil {
+			errCh <- err
+			return
+		}
+		attr, err := unix.SchedGetAttr(0, 0)
+		if err == nil && (attr.Policy != unix.SCHED_FIFO || attr.Priority != 10) {
+			err = errors.New("thread not at SCHED_FIFO priority 10")
+		}
+		errCh <- err
+	}()
+
+	err := <-errCh
+	if errors.Is(err, unix.EPERM) {
+		t.Skip("SCHED_FIFO not permitted")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetCTSLatencyNotSupported(t *testing.T) {
+	port, _ := openPTYPort(t)
+	if _, err := GetCTSLatency(port); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("GetCTSLatency without CTS flow control error = %v, want ErrNotSupported", err)
+	}
+	if _, err := GetCTSLatency(struct{ Port }{port}); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("GetCTSLatency on a wrapped port error = %v, want ErrNotSupported", err)
+	}
+}
@@ -0,0 +1,314 @@
+package serial
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// Telnet commands (RFC 854)
+const (
+	telnetSE   = 240
+	telnetSB   = 250
+	telnetWILL = 251
+	telnetWONT = 252
+	telnetDO   = 253
+	telnetDONT = 254
+	telnetIAC  = 255
+)
+
+// Telnet options
+const (
+	telnetOptBinary  = 0
+	telnetOptSGA     = 3
+	telnetOptComPort = 44 // COM-PORT-OPTION, RFC 2217
+)
+
+// COM-PORT-OPTION client commands; server replies add comServerOffset
+const (
+	comSetBaudRate       = 1
+	comSetDataSize       = 2
+	comSetParity         = 3
+	comSetStopSize       = 4
+	comSetControl        = 5
+	comNotifyModemState  = 7
+	comSetModemStateMask = 11
+	comPurgeData         = 12
+	comServerOffset      = 100
+	comServerModemState  = comNotifyModemState + comServerOffset
+)
+
+// SET-CONTROL values
+const (
+	comControlNoFlow   = 1
+	comControlHardware = 3
+	comControlBreakOn  = 5
+	comControlBreakOff = 6
+	comControlDTROn    = 8
+	comControlDTROff   = 9
+	comControlRTSOn    = 11
+	comControlRTSOff   = 12
+)
+
+// PURGE-DATA values
+const (
+	comPurgeRX = 1
+	comPurgeTX = 2
+)
+
+// NOTIFY-MODEMSTATE bits
+const (
+	modemStateDeltaCTS = 0x01
+	modemStateDeltaDSR = 0x02
+	modemStateRIEdge   = 0x04
+	modemStateDeltaDCD = 0x08
+	modemStateCTS      = 0x10
+	modemStateDSR      = 0x20
+	modemStateRI       = 0x40
+	modemStateDCD      = 0x80
+)
+
+// telnetParser holds the decoder state between reads
+type telnetParser struct {
+	state int
+	verb  byte
+	sb    []byte
+}
+
+const (
+	telnetStateData = iota
+	telnetStateIAC
+	telnetStateOption
+	telnetStateSB
+	telnetStateSBIAC
+)
+
+// decodeTelnet strips telnet commands from in and returns the data bytes
+// Data is decoded in place; commands are handled as they complete.
+func (p *netPort) decodeTelnet(in []byte) []byte {
+	t := &p.telnet
+	out := in[:0]
+
+	for _, b := range in {
+		switch t.state {
+		case telnetStateData:
+			if b == telnetIAC {
+				t.state = telnetStateIAC
+			} else {
+				out = append(out, b)
+			}
+		case telnetStateIAC:
+			switch b {
+			case telnetIAC:
+				out = append(out, b)
+				t.state = telnetStateData
+			case telnetWILL, telnetWONT, telnetDO, telnetDONT:
+				t.verb = b
+				t.state = telnetStateOption
+			case telnetSB:
+				t.sb = t.sb[:0]
+				t.state = telnetStateSB
+			default:
+				// NOP, GA and other single-byte commands carry no data
+				t.state = telnetStateData
+			}
+		case telnetStateOption:
+			p.negotiate(t.verb, b)
+			t.state = telnetStateData
+		case telnetStateSB:
+			if b == telnetIAC {
+				t.state = telnetStateSBIAC
+			} else {
+				t.sb = append(t.sb, b)
+			}
+		case telnetStateSBIAC:
+			switch b {
+			case telnetSE:
+				p.subnegotiation(t.sb)
+				t.state = telnetStateData
+			case telnetIAC:
+				t.sb = append(t.sb, b)
+				t.state = telnetStateSB
+			default:
+				t.state = telnetStateSB
+			}
+		}
+	}
+
+	return out
+}
+
+// negotiate answers option requests from the server
+// BINARY, SGA and COM-PORT-OPTION are requested up front, so agreement needs
+// no reply; anything else is refused.
+func (p *netPort) negotiate(verb, opt byte) {
+	supported := opt == telnetOptBinary || opt == telnetOptSGA || opt == telnetOptComPort
+
+	switch verb {
+	case telnetDO:
+		if opt == telnetOptComPort {
+			p.comPortResult(true)
+		}
+		if !supported {
+			p.writeRaw([]byte{telnetIAC, telnetWONT, opt})
+		}
+	case telnetDONT:
+		if opt == telnetOptComPort {
+			p.comPortResult(false)
+		}
+	case telnetWILL:
+		if !supported || opt == telnetOptComPort {
+			p.writeRaw([]byte{telnetIAC, telnetDONT, opt})
+		}
+	}
+}
+
+// comPortResult reports the COM-PORT-OPTION negotiation outcome to startComPort
+func (p *netPort) comPortResult(accepted bool) {
+	select {
+	case p.comPort <- accepted:
+	default:
+	}
+}
+
+// subnegotiation handles COM-PORT-OPTION messages from the server
+// Replies confirming settings are ignored; modem state notifications update signals.
+func (p *netPort) subnegotiation(sb []byte) {
+	if len(sb) < 3 || sb[0] != telnetOptComPort {
+		return
+	}
+	if sb[1] == comServerModemState {
+		p.updateModemState(sb[2])
+	}
+}
+
+// updateModemState publishes a NOTIFY-MODEMSTATE value to signal waiters
+func (p *netPort) updateModemState(state byte) {
+	signals := ModemSignals{
+		CTS: state&modemStateCTS != 0,
+		DSR: state&modemStateDSR != 0,
+		RI:  state&modemStateRI != 0,
+		DCD: state&modemStateDCD != 0,
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	cur := p.signals
+	var changed SignalMask
+	if signals.CTS != cur.signals.CTS || state&modemStateDeltaCTS != 0 {
+		changed |= SignalCTS
+	}
+	if signals.DSR != cur.signals.DSR || state&modemStateDeltaDSR != 0 {
+		changed |= SignalDSR
+	}
+	if signals.RI != cur.signals.RI || state&modemStateRIEdge != 0 {
+		changed |= SignalRI
+	}
+	if signals.DCD != cur.signals.DCD || state&modemStateDeltaDCD != 0 {
+		changed |= SignalDCD
+	}
+	if changed == 0 {
+		return
+	}
+
+	next := &signalEvent{signals: signals, changed: changed, ready: make(chan struct{})}
+	cur.next = next
+	close(cur.ready)
+	p.signals = next
+}
+
+// appendTelnetEscaped appends data to dst, doubling IAC bytes
+func appendTelnetEscaped(dst, data []byte) []byte {
+	for _, b := range data {
+		if b == telnetIAC {
+			dst = append(dst, telnetIAC)
+		}
+		dst = append(dst, b)
+	}
+	return dst
+}
+
+// comPortCommand sends a COM-PORT-OPTION subnegotiation
+func (p *netPort) comPortCommand(cmd byte, value ...byte) error {
+	msg := []byte{telnetIAC, telnetSB, telnetOptComPort, cmd}
+	msg = appendTelnetEscaped(msg, value)
+	msg = append(msg, telnetIAC, telnetSE)
+	return p.writeRaw(msg)
+}
+
+// startComPort negotiates COM-PORT-OPTION and applies the port configuration
+func (p *netPort) startComPort() error {
+	err := p.writeRaw([]byte{
+		telnetIAC, telnetWILL, telnetOptBinary,
+		telnetIAC, telnetDO, telnetOptBinary,
+		telnetIAC, telnetWILL, telnetOptSGA,
+		telnetIAC, telnetDO, telnetOptSGA,
+		telnetIAC, telnetWILL, telnetOptComPort,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start RFC 2217 negotiation: %w", err)
+	}
+
+	timer := time.NewTimer(networkDialTimeout)
+	defer timer.Stop()
+
+	select {
+	case accepted := <-p.comPort:
+		p.config.Logger.Debug("RFC 2217 COM-PORT-OPTION negotiated", "accepted", accepted)
+		if !accepted {
+			return fmt.Errorf("%w: server refused RFC 2217 COM-PORT-OPTION", ErrNotSupported)
+		}
+	case <-p.loopDone:
+		return fmt.Errorf("connection closed during RFC 2217 negotiation: %w", p.rxErr)
+	case <-timer.C:
+		return fmt.Errorf("timeout waiting for RFC 2217 COM-PORT-OPTION")
+	}
+
+	return p.applyComPortConfig()
+}
+
+// applyComPortConfig sends the line settings and initial signal states
+func (p *netPort) applyComPortConfig() error {
+	config := p.config
+
+	parity := map[Parity]byte{
+		ParityNone:  1,
+		ParityOdd:   2,
+		ParityEven:  3,
+		ParityMark:  4,
+		ParitySpace: 5,
+	}[config.Parity]
+
+	flow := byte(comControlNoFlow)
+	if config.FlowControl == FlowControlRTSCTS {
+		flow = comControlHardware
+	}
+
+	commands := [][]byte{
+		append([]byte{comSetBaudRate}, binary.BigEndian.AppendUint32(nil, uint32(config.BaudRate))...),
+		{comSetDataSize, byte(config.DataBits)},
+		{comSetParity, parity},
+		{comSetStopSize, byte(config.StopBits)},
+		{comSetControl, flow},
+		{comSetModemStateMask, 0xFF},
+	}
+	for _, cmd := range commands {
+		if err := p.comPortCommand(cmd[0], cmd[1:]...); err != nil {
+			return fmt.Errorf("failed to configure remote port: %w", err)
+		}
+	}
+
+	if config.InitialRTS != nil {
+		if err := p.SetRTS(*config.InitialRTS); err != nil {
+			return fmt.Errorf("failed to set initial RTS: %w", err)
+		}
+	}
+	if config.InitialDTR != nil {
+		if err := p.SetDTR(*config.InitialDTR); err != nil {
+			return fmt.Errorf("failed to set initial DTR: %w", err)
+		}
+	}
+
+	return nil
+}
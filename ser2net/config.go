@@ -0,0 +1,367 @@
+// Package ser2net reads ser2net YAML configuration and serves its connections
+//
+// Each "connection" entry becomes a bridge.Bridge listening on the accepter's
+// TCP port, so an existing ser2net.yaml can be served unchanged:
+//
+//	connection: &gps
+//	    accepter: tcp,2000
+//	    connector: serialdev,/dev/ttyUSB0,9600n81,local
+//	    options:
+//	      kickolduser: true
+//
+// Supported accepters are "tcp,<port>" and "tcp,<host>,<port>"; telnet and
+// RFC 2217 accepters are reported as errors by Serve. Serial device options
+// ser2net knows but this package does not are collected in
+// Connection.Ignored instead of failing the whole file.
+//
+// In addition to ser2net's own connection options, these keys are accepted
+// under "options":
+//
+//	initial-rts: true       # Assert RTS on open (needed for rtscts)
+//	usb-reset: true         # Reset the USB device after repeated open failures
+//	reconnect-delay: 2s     # Wait before reopening a failed port
+package ser2net
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/allbin/go-serial"
+	"go.yaml.in/yaml/v3"
+)
+
+// ErrInvalidConfig is returned for configuration that cannot be parsed
+var ErrInvalidConfig = errors.New("invalid ser2net configuration")
+
+// Connection is one ser2net "connection" entry
+type Connection struct {
+	Name    string // YAML anchor, or "connection<N>" when there is none
+	Enabled bool
+
+	// Accepter
+	Telnet bool   // The accepter goes through a telnet filter
+	Addr   string // TCP listen address, e.g. ":2000" or "localhost:2000"
+
+	// Connector
+	Device      string
+	BaudRate    int
+	DataBits    int
+	Parity      serial.Parity
+	StopBits    int
+	FlowControl serial.FlowControl
+	Local       bool     // Ignore modem control lines
+	Ignored     []string // Connector options that have no equivalent here
+
+	// Options
+	Banner         string // Sent to clients on connect, with ser2net escapes expanded
+	KickOldUser    bool
+	MaxConnections int
+	InitialRTS     bool
+	USBReset       bool
+	ReconnectDelay time.Duration
+}
+
+// connectionEntry is the YAML layout of a connection
+type connectionEntry struct {
+	Accepter  string    `yaml:"accepter"`
+	Connector string    `yaml:"connector"`
+	Enable    yaml.Node `yaml:"enable"`
+	Options   struct {
+		Banner         string    `yaml:"banner"`
+		KickOldUser    yaml.Node `yaml:"kickolduser"`
+		MaxConnections int       `yaml:"max-connections"`
+		InitialRTS     yaml.Node `yaml:"initial-rts"`
+		USBReset       yaml.Node `yaml:"usb-reset"`
+		ReconnectDelay string    `yaml:"reconnect-delay"`
+	} `yaml:"options"`
+}
+
+// Parse reads every connection from a ser2net YAML file
+// Disabled connections are included with Enabled false. Top-level keys
+// other than "connection" (define, default, led, ...) are skipped.
+func Parse(r io.Reader) ([]Connection, error) {
+	dec := yaml.NewDecoder(r)
+
+	var conns []Connection
+	for {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+		}
+		if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+			continue
+		}
+
+		// ser2net repeats the "connection" key, so walk the mapping node
+		// rather than decoding it into a map
+		top := doc.Content[0]
+		for i := 0; i+1 < len(top.Content); i += 2 {
+			if top.Content[i].Value != "connection" {
+				continue
+			}
+			value := top.Content[i+1]
+			name := value.Anchor
+			if name == "" {
+				name = fmt.Sprintf("connection%d", len(conns))
+			}
+			c, err := parseConnection(name, value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s (line %d): %v", ErrInvalidConfig, name, value.Line, err)
+			}
+			conns = append(conns, c)
+		}
+	}
+	return conns, nil
+}
+
+func parseConnection(name string, node *yaml.Node) (Connection, error) {
+	var entry connectionEntry
+	if err := node.Decode(&entry); err != nil {
+		return Connection{}, err
+	}
+
+	c := Connection{
+		Name:           name,
+		MaxConnections: max(entry.Options.MaxConnections, 1),
+	}
+
+	var err error
+	if c.Enabled, err = parseBool(&entry.Enable, true); err != nil {
+		return c, fmt.Errorf("enable: %w", err)
+	}
+	if c.KickOldUser, err = parseBool(&entry.Options.KickOldUser, false); err != nil {
+		return c, fmt.Errorf("kickolduser: %w", err)
+	}
+	if c.InitialRTS, err = parseBool(&entry.Options.InitialRTS, false); err != nil {
+		return c, fmt.Errorf("initial-rts: %w", err)
+	}
+	if c.USBReset, err = parseBool(&entry.Options.USBReset, false); err != nil {
+		return c, fmt.Errorf("usb-reset: %w", err)
+	}
+	if entry.Options.ReconnectDelay != "" {
+		if c.ReconnectDelay, err = time.ParseDuration(entry.Options.ReconnectDelay); err != nil {
+			return c, fmt.Errorf("reconnect-delay: %w", err)
+		}
+	}
+
+	if err := c.parseAccepter(entry.Accepter); err != nil {
+		return c, err
+	}
+	if err := c.parseConnector(entry.Connector); err != nil {
+		return c, err
+	}
+	c.Banner = expandBanner(entry.Options.Banner, &c)
+	return c, nil
+}
+
+// parseBool accepts YAML 1.1 style on/off and yes/no as well as true/false
+func parseBool(node *yaml.Node, def bool) (bool, error) {
+	if node.Kind == 0 {
+		return def, nil
+	}
+	switch strings.ToLower(node.Value) {
+	case "true", "on", "yes", "1":
+		return true, nil
+	case "false", "off", "no", "0":
+		return false, nil
+	default:
+		return false, fmt.Errorf("invalid boolean %q", node.Value)
+	}
+}
+
+// splitList splits a ser2net comma list, tolerating line breaks and spaces
+func splitList(s string) []string {
+	var fields []string
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// parseAccepter handles "tcp,2000", "tcp,host,2000" and telnet filter prefixes
+func (c *Connection) parseAccepter(s string) error {
+	fields := splitList(s)
+	for len(fields) > 0 && !isTCP(fields[0]) {
+		if strings.HasPrefix(fields[0], "telnet") {
+			c.Telnet = true
+		}
+		fields = fields[1:]
+	}
+	if len(fields) < 2 {
+		return fmt.Errorf("unsupported accepter %q (want tcp,<port>)", s)
+	}
+
+	host, port := "", fields[len(fields)-1]
+	if len(fields) > 2 {
+		host = fields[1]
+	}
+	if _, err := strconv.Atoi(port); err != nil {
+		return fmt.Errorf("invalid accepter port %q", port)
+	}
+	c.Addr = host + ":" + port
+	return nil
+}
+
+func isTCP(field string) bool {
+	return field == "tcp" || strings.HasPrefix(field, "tcp(")
+}
+
+// parseConnector handles "serialdev,<device>,<options>..."
+func (c *Connection) parseConnector(s string) error {
+	fields := splitList(s)
+	if len(fields) < 2 || fields[0] != "serialdev" {
+		return fmt.Errorf("unsupported connector %q (want serialdev,<device>,...)", s)
+	}
+	c.Device = fields[1]
+
+	// ser2net defaults
+	c.BaudRate, c.Parity, c.DataBits, c.StopBits = 9600, serial.ParityNone, 8, 1
+
+	for _, opt := range fields[2:] {
+		switch strings.ToLower(opt) {
+		case "rtscts":
+			c.FlowControl = serial.FlowControlRTSCTS
+		case "-rtscts":
+			c.FlowControl = serial.FlowControlNone
+		case "local":
+			c.Local = true
+		case "-local":
+			c.Local = false
+		default:
+			if !c.parseSpeed(opt) {
+				c.Ignored = append(c.Ignored, opt)
+			}
+		}
+	}
+	return nil
+}
+
+// parseSpeed handles "<baud>[<parity>[<databits>[<stopbits>]]]", e.g. 115200e71
+func (c *Connection) parseSpeed(opt string) bool {
+	digits := 0
+	for digits < len(opt) && opt[digits] >= '0' && opt[digits] <= '9' {
+		digits++
+	}
+	if digits == 0 || len(opt)-digits > 3 {
+		return false
+	}
+	baud, _ := strconv.Atoi(opt[:digits])
+	rest := strings.ToLower(opt[digits:])
+
+	parity, dataBits, stopBits := serial.ParityNone, 8, 1
+	if len(rest) > 0 {
+		switch rest[0] {
+		case 'n':
+			parity = serial.ParityNone
+		case 'e':
+			parity = serial.ParityEven
+		case 'o':
+			parity = serial.ParityOdd
+		case 'm':
+			parity = serial.ParityMark
+		case 's':
+			parity = serial.ParitySpace
+		default:
+			return false
+		}
+	}
+	if len(rest) > 1 {
+		if rest[1] < '5' || rest[1] > '8' {
+			return false
+		}
+		dataBits = int(rest[1] - '0')
+	}
+	if len(rest) > 2 {
+		if rest[2] != '1' && rest[2] != '2' {
+			return false
+		}
+		stopBits = int(rest[2] - '0')
+	}
+
+	c.BaudRate, c.Parity, c.DataBits, c.StopBits = baud, parity, dataBits, stopBits
+	return true
+}
+
+// expandBanner replaces ser2net banner escapes
+// Supported: \r \n \t \\ \d (device), \p (TCP port), \B (serial parameters), \N (name).
+func expandBanner(banner string, c *Connection) string {
+	if banner == "" {
+		return ""
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(banner); i++ {
+		if banner[i] != '\\' || i+1 == len(banner) {
+			b.WriteByte(banner[i])
+			continue
+		}
+		i++
+		switch banner[i] {
+		case 'r':
+			b.WriteByte('\r')
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case '\\':
+			b.WriteByte('\\')
+		case 'd':
+			b.WriteString(c.Device)
+		case 'p':
+			b.WriteString(c.Addr[strings.LastIndex(c.Addr, ":")+1:])
+		case 'B':
+			b.WriteString(c.SerialParams())
+		case 'N':
+			b.WriteString(c.Name)
+		default:
+			b.WriteByte('\\')
+			b.WriteByte(banner[i])
+		}
+	}
+	return b.String()
+}
+
+// SerialParams formats the line settings ser2net style, e.g. "9600N81"
+func (c *Connection) SerialParams() string {
+	parity := "N"
+	switch c.Parity {
+	case serial.ParityEven:
+		parity = "E"
+	case serial.ParityOdd:
+		parity = "O"
+	case serial.ParityMark:
+		parity = "M"
+	case serial.ParitySpace:
+		parity = "S"
+	}
+	params := fmt.Sprintf("%d%s%d%d", c.BaudRate, parity, c.DataBits, c.StopBits)
+	if c.FlowControl == serial.FlowControlRTSCTS {
+		params += ",RTSCTS"
+	}
+	return params
+}
+
+// PortOptions returns the serial options for the connection's device
+func (c *Connection) PortOptions() []serial.Option {
+	opts := []serial.Option{
+		serial.WithBaudRate(c.BaudRate),
+		serial.WithDataBits(c.DataBits),
+		serial.WithParity(c.Parity),
+		serial.WithStopBits(c.StopBits),
+		serial.WithFlowControl(c.FlowControl),
+		serial.WithReadTimeout(100 * time.Millisecond),
+	}
+	if c.InitialRTS || c.FlowControl == serial.FlowControlRTSCTS {
+		opts = append(opts, serial.WithInitialRTS(true))
+	}
+	return opts
+}
@@ -0,0 +1,126 @@
+package ser2net
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/allbin/go-serial"
+)
+
+const sampleConfig = `%YAML 1.1
+---
+define: &banner \r\nser2net port \p device \d [\B]\r\n
+
+connection: &con0096
+    accepter: tcp,2000
+    enable: on
+    options:
+      banner: *banner
+      kickolduser: true
+    connector: serialdev,
+              /dev/ttyS0,
+              9600n81,local
+
+connection: &gps
+    accepter: tcp,localhost,2001
+    connector: serialdev,/dev/ttyUSB0,115200e72,rtscts,nobreak
+    options:
+      max-connections: 4
+      usb-reset: yes
+      reconnect-delay: 2s
+
+connection: &off
+    accepter: telnet(rfc2217),tcp,2002
+    enable: off
+    connector: serialdev,/dev/ttyS1
+`
+
+func TestParse(t *testing.T) {
+	conns, err := Parse(strings.NewReader(sampleConfig))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(conns) != 3 {
+		t.Fatalf("Parse returned %d connections, want 3", len(conns))
+	}
+
+	con := conns[0]
+	if con.Name != "con0096" || !con.Enabled || con.Addr != ":2000" || con.Device != "/dev/ttyS0" ||
+		con.BaudRate != 9600 || !con.Local || !con.KickOldUser || con.MaxConnections != 1 {
+		t.Errorf("con0096 = %+v", con)
+	}
+	if want := "\r\nser2net port 2000 device /dev/ttyS0 [9600N81]\r\n"; con.Banner != want {
+		t.Errorf("banner = %q, want %q", con.Banner, want)
+	}
+
+	gps := conns[1]
+	if gps.Addr != "localhost:2001" || gps.BaudRate != 115200 || gps.Parity != serial.ParityEven ||
+		gps.DataBits != 7 || gps.StopBits != 2 || gps.FlowControl != serial.FlowControlRTSCTS {
+		t.Errorf("gps line settings = %+v", gps)
+	}
+	if gps.MaxConnections != 4 || !gps.USBReset || gps.ReconnectDelay != 2*time.Second {
+		t.Errorf("gps options = %+v", gps)
+	}
+	if len(gps.Ignored) != 1 || gps.Ignored[0] != "nobreak" {
+		t.Errorf("gps ignored = %v, want [nobreak]", gps.Ignored)
+	}
+
+	off := conns[2]
+	if off.Enabled || !off.Telnet || off.BaudRate != 9600 {
+		t.Errorf("off = %+v", off)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+	}{
+		{"bad accepter", "connection: &a\n  accepter: udp,2000\n  connector: serialdev,/dev/ttyS0\n"},
+		{"bad port", "connection: &a\n  accepter: tcp,http\n  connector: serialdev,/dev/ttyS0\n"},
+		{"bad connector", "connection: &a\n  accepter: tcp,2000\n  connector: sol,/dev/ttyS0\n"},
+		{"bad boolean", "connection: &a\n  accepter: tcp,2000\n  enable: maybe\n  connector: serialdev,/dev/ttyS0\n"},
+		{"bad yaml", "connection: [\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(strings.NewReader(tt.config)); !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("Parse error = %v, want ErrInvalidConfig", err)
+			}
+		})
+	}
+}
+
+func TestParseSpeedStickParity(t *testing.T) {
+	tests := []struct {
+		opt    string
+		parity serial.Parity
+		params string
+	}{
+		{"9600m71", serial.ParityMark, "9600M71"},
+		{"19200s81", serial.ParitySpace, "19200S81"},
+	}
+	for _, tt := range tests {
+		var c Connection
+		if !c.parseSpeed(tt.opt) {
+			t.Fatalf("parseSpeed(%q) rejected it", tt.opt)
+		}
+		if c.Parity != tt.parity || c.SerialParams() != tt.params {
+			t.Errorf("parseSpeed(%q) = %s %s, want %s %s", tt.opt, c.Parity, c.SerialParams(), tt.parity, tt.params)
+		}
+
+		// The port options carry the parity through to the termios CMSPAR bits
+		config := serial.DefaultConfig()
+		for _, opt := range c.PortOptions() {
+			if err := opt(&config); err != nil {
+				t.Fatalf("PortOptions for %q: %v", tt.opt, err)
+			}
+		}
+		if config.Parity != tt.parity {
+			t.Errorf("PortOptions for %q set parity %s, want %s", tt.opt, config.Parity, tt.parity)
+		}
+	}
+}
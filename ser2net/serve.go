@@ -0,0 +1,158 @@
+package ser2net
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/allbin/go-serial"
+	"github.com/allbin/go-serial/bridge"
+)
+
+// usbResetAfter is the number of consecutive open failures that trigger a USB reset
+const usbResetAfter = 3
+
+// ErrTelnetUnsupported is returned by Serve for telnet and RFC 2217 accepters
+var ErrTelnetUnsupported = errors.New("telnet accepters are not supported")
+
+// Option configures Serve
+type Option func(*server)
+
+type server struct {
+	onEvent     func(name string, ev bridge.Event)
+	portOptions func(name string) []serial.Option
+}
+
+// WithEventHandler is called for the port and client events of every connection
+// fn may be called concurrently for different connections and must not block.
+func WithEventHandler(fn func(name string, ev bridge.Event)) Option {
+	return func(s *server) {
+		s.onEvent = fn
+	}
+}
+
+// WithPortOptions adds fn's options to every connection's port options
+// Use it for settings the configuration file cannot express, such as a
+// logger or per-connection instrumentation.
+func WithPortOptions(fn func(name string) []serial.Option) Option {
+	return func(s *server) {
+		s.portOptions = fn
+	}
+}
+
+// Serve bridges every enabled connection until ctx is cancelled
+// All listeners are opened before any connection is served, so a port
+// clash or unsupported accepter fails Serve immediately. If one listener
+// fails later, the others are stopped and its error returned.
+func Serve(ctx context.Context, conns []Connection, opts ...Option) error {
+	var s server
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	type served struct {
+		conn     Connection
+		listener net.Listener
+	}
+	var active []served
+	closeAll := func() {
+		for _, a := range active {
+			a.listener.Close()
+		}
+	}
+
+	for _, c := range conns {
+		if !c.Enabled {
+			continue
+		}
+		if c.Telnet {
+			closeAll()
+			return fmt.Errorf("%s: %w", c.Name, ErrTelnetUnsupported)
+		}
+		l, err := net.Listen("tcp", c.Addr)
+		if err != nil {
+			closeAll()
+			return fmt.Errorf("%s: failed to listen: %w", c.Name, err)
+		}
+		active = append(active, served{conn: c, listener: l})
+	}
+	if len(active) == 0 {
+		return fmt.Errorf("%w: no enabled connections", ErrInvalidConfig)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(active))
+	for _, a := range active {
+		var extra []serial.Option
+		if s.portOptions != nil {
+			extra = s.portOptions(a.conn.Name)
+		}
+		b := bridge.New(a.conn.opener(extra), s.bridgeOptions(a.conn)...)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := b.Serve(ctx, a.listener); err != nil {
+				errs <- fmt.Errorf("%s: %w", a.conn.Name, err)
+				cancel()
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+	return <-errs
+}
+
+// bridgeOptions maps the connection's ser2net options to bridge options
+func (s *server) bridgeOptions(c Connection) []bridge.Option {
+	var opts []bridge.Option
+	switch {
+	case c.KickOldUser:
+		opts = append(opts, bridge.WithPolicy(bridge.PolicyTakeover))
+	case c.MaxConnections > 1:
+		opts = append(opts, bridge.WithPolicy(bridge.PolicyBroadcast), bridge.WithMaxClients(c.MaxConnections))
+	default:
+		opts = append(opts, bridge.WithPolicy(bridge.PolicyExclusive))
+	}
+	if c.Banner != "" {
+		opts = append(opts, bridge.WithBanner([]byte(c.Banner)))
+	}
+	if c.ReconnectDelay > 0 {
+		opts = append(opts, bridge.WithReconnectDelay(c.ReconnectDelay))
+	}
+	if s.onEvent != nil {
+		name := c.Name
+		opts = append(opts, bridge.WithEventHandler(func(ev bridge.Event) {
+			s.onEvent(name, ev)
+		}))
+	}
+	return opts
+}
+
+// opener opens the connection's device with extra options appended,
+// resetting its USB device after repeated failures when usb-reset is enabled
+func (c Connection) opener(extra []serial.Option) bridge.Opener {
+	opts := append(c.PortOptions(), extra...)
+	failures := 0
+	return func() (serial.Port, error) {
+		port, err := serial.Open(c.Device, opts...)
+		if err == nil {
+			failures = 0
+			return port, nil
+		}
+
+		failures++
+		if c.USBReset && failures == usbResetAfter {
+			if rerr := serial.ResetUSBDeviceContext(context.Background(), c.Device, extra...); rerr != nil {
+				return nil, fmt.Errorf("%w (USB reset failed: %v)", err, rerr)
+			}
+			return nil, fmt.Errorf("%w (USB device reset)", err)
+		}
+		return nil, err
+	}
+}
@@ -0,0 +1,74 @@
+package ser2net
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/allbin/go-serial"
+)
+
+func TestServe(t *testing.T) {
+	master, slavePath, err := serial.OpenPTY()
+	if err != nil {
+		t.Skipf("PTY not available: %v", err)
+	}
+	defer master.Close()
+
+	// Reserve a free port for the accepter
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("TCP listener not available: %v", err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	conns := []Connection{{
+		Name: "pty", Enabled: true, Addr: addr, Device: slavePath,
+		BaudRate: 9600, DataBits: 8, StopBits: 1, MaxConnections: 1,
+		Banner: "hello\r\n",
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- Serve(ctx, conns) }()
+	defer func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Serve returned %v", err)
+		}
+	}()
+
+	var conn net.Conn
+	for deadline := time.Now().Add(2 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if conn, err = net.Dial("tcp", addr); err == nil || time.Now().After(deadline) {
+			break
+		}
+	}
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 7)
+	if _, err := io.ReadFull(conn, buf); err != nil || string(buf) != "hello\r\n" {
+		t.Fatalf("banner = %q, %v", buf, err)
+	}
+
+	conn.Write([]byte("ping"))
+	master.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err := io.ReadFull(master, buf[:4]); err != nil || string(buf[:4]) != "ping" {
+		t.Errorf("device received %q, %v, want ping", buf[:4], err)
+	}
+}
+
+func TestServeTelnetUnsupported(t *testing.T) {
+	conns := []Connection{{Name: "t", Enabled: true, Telnet: true, Addr: "127.0.0.1:0"}}
+	if err := Serve(context.Background(), conns); !errors.Is(err, ErrTelnetUnsupported) {
+		t.Errorf("Serve error = %v, want ErrTelnetUnsupported", err)
+	}
+}
@@ -0,0 +1,218 @@
+package session
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	serial "github.com/allbin/go-serial"
+)
+
+// LinkTypeUser0 is the default pcapng link type (DLT_USER0)
+// Map it to a dissector in Wireshark under Preferences > Protocols > DLT_USER,
+// e.g. "mbrtu" for Modbus RTU.
+const LinkTypeUser0 = 147
+
+// pcapng block types and option codes
+const (
+	blockSectionHeader    = 0x0A0D0D0A
+	blockInterface        = 0x00000001
+	blockEnhancedPacket   = 0x00000006
+	byteOrderMagic        = 0x1A2B3C4D
+	optEnd                = 0
+	optComment            = 1
+	optIfName             = 2
+	optIfTSResol          = 9
+	optEPBFlags           = 2
+	epbFlagInbound        = 0x1
+	epbFlagOutbound       = 0x2
+	pcapngNanosecondResol = 9
+)
+
+// PcapngOption configures a PcapngWriter
+type PcapngOption func(*PcapngWriter)
+
+// WithLinkType sets the interface link type (default LinkTypeUser0)
+func WithLinkType(linkType uint16) PcapngOption {
+	return func(p *PcapngWriter) {
+		p.linkType = linkType
+	}
+}
+
+// WithInterfaceName records the port name in the interface description
+func WithInterfaceName(name string) PcapngOption {
+	return func(p *PcapngWriter) {
+		p.ifName = name
+	}
+}
+
+// PcapngWriter writes serial traffic as a pcapng capture for Wireshark
+// Each RX or TX chunk becomes one packet with nanosecond timestamp and
+// inbound/outbound direction flags; signal changes become empty packets
+// carrying a comment such as "CTS=1 DSR=0 RI=0 DCD=0 RTS=1 DTR=1".
+// Like Recorder, it is safe for concurrent use and its write errors are sticky.
+type PcapngWriter struct {
+	linkType uint16
+	ifName   string
+
+	mu      sync.Mutex
+	w       *bufio.Writer
+	signals *serial.ModemSignals
+	err     error
+}
+
+// NewPcapngWriter writes the section and interface headers to w
+// Call Flush before closing w.
+func NewPcapngWriter(w io.Writer, opts ...PcapngOption) (*PcapngWriter, error) {
+	p := &PcapngWriter{
+		linkType: LinkTypeUser0,
+		w:        bufio.NewWriter(w),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	// Section header: byte-order magic, version 1.0, unknown section length
+	shb := binary.LittleEndian.AppendUint32(nil, byteOrderMagic)
+	shb = binary.LittleEndian.AppendUint16(shb, 1)
+	shb = binary.LittleEndian.AppendUint16(shb, 0)
+	shb = binary.LittleEndian.AppendUint64(shb, ^uint64(0))
+	shb = appendOption(shb, optEnd, nil)
+	p.writeBlock(blockSectionHeader, shb)
+
+	// Interface description: link type, no snap length, nanosecond timestamps
+	idb := binary.LittleEndian.AppendUint16(nil, p.linkType)
+	idb = binary.LittleEndian.AppendUint16(idb, 0)
+	idb = binary.LittleEndian.AppendUint32(idb, 0)
+	if p.ifName != "" {
+		idb = appendOption(idb, optIfName, []byte(p.ifName))
+	}
+	idb = appendOption(idb, optIfTSResol, []byte{pcapngNanosecondResol})
+	idb = appendOption(idb, optEnd, nil)
+	p.writeBlock(blockInterface, idb)
+
+	if p.err != nil {
+		return nil, p.err
+	}
+	return p, nil
+}
+
+// RecordRX writes data received from the device
+func (p *PcapngWriter) RecordRX(data []byte) {
+	p.WriteEvent(time.Now(), Event{Kind: KindRX, Data: data})
+}
+
+// RecordTX writes data written to the device
+func (p *PcapngWriter) RecordTX(data []byte) {
+	p.WriteEvent(time.Now(), Event{Kind: KindTX, Data: data})
+}
+
+// RecordSignals writes the modem signal state if it differs from the last one
+func (p *PcapngWriter) RecordSignals(s serial.ModemSignals) {
+	p.WriteEvent(time.Now(), Event{Kind: KindSignals, Signals: s})
+}
+
+// WriteEvent writes ev as a packet timestamped at
+// It converts recordings: for each event of a Reader, call
+// WriteEvent(r.Start().Add(ev.Offset), ev).
+func (p *PcapngWriter) WriteEvent(at time.Time, ev Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var flags uint32
+	var comment string
+	switch ev.Kind {
+	case KindRX:
+		flags = epbFlagInbound
+	case KindTX:
+		flags = epbFlagOutbound
+	case KindSignals:
+		if p.signals != nil && *p.signals == ev.Signals {
+			return
+		}
+		p.signals = &ev.Signals
+		comment = formatSignals(ev.Signals)
+	default:
+		return
+	}
+	if ev.Kind != KindSignals && len(ev.Data) == 0 {
+		return
+	}
+
+	ts := uint64(at.UnixNano())
+	epb := binary.LittleEndian.AppendUint32(nil, 0) // Interface 0
+	epb = binary.LittleEndian.AppendUint32(epb, uint32(ts>>32))
+	epb = binary.LittleEndian.AppendUint32(epb, uint32(ts))
+	epb = binary.LittleEndian.AppendUint32(epb, uint32(len(ev.Data)))
+	epb = binary.LittleEndian.AppendUint32(epb, uint32(len(ev.Data)))
+	epb = append(epb, ev.Data...)
+	epb = pad32(epb)
+	if flags != 0 {
+		epb = appendOption(epb, optEPBFlags, binary.LittleEndian.AppendUint32(nil, flags))
+	}
+	if comment != "" {
+		epb = appendOption(epb, optComment, []byte(comment))
+	}
+	epb = appendOption(epb, optEnd, nil)
+	p.writeBlock(blockEnhancedPacket, epb)
+}
+
+// Flush writes buffered packets to the underlying writer
+func (p *PcapngWriter) Flush() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.err != nil {
+		return p.err
+	}
+	p.err = p.w.Flush()
+	return p.err
+}
+
+// Err returns the first write error encountered, if any
+func (p *PcapngWriter) Err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+// writeBlock frames body (already padded) with the block type and lengths
+func (p *PcapngWriter) writeBlock(blockType uint32, body []byte) {
+	if p.err != nil {
+		return
+	}
+	total := uint32(12 + len(body))
+	block := binary.LittleEndian.AppendUint32(make([]byte, 0, total), blockType)
+	block = binary.LittleEndian.AppendUint32(block, total)
+	block = append(block, body...)
+	block = binary.LittleEndian.AppendUint32(block, total)
+	_, p.err = p.w.Write(block)
+}
+
+// appendOption appends a padded pcapng option
+func appendOption(b []byte, code uint16, value []byte) []byte {
+	b = binary.LittleEndian.AppendUint16(b, code)
+	b = binary.LittleEndian.AppendUint16(b, uint16(len(value)))
+	return pad32(append(b, value...))
+}
+
+func pad32(b []byte) []byte {
+	for len(b)%4 != 0 {
+		b = append(b, 0)
+	}
+	return b
+}
+
+func formatSignals(s serial.ModemSignals) string {
+	bit := func(v bool) int {
+		if v {
+			return 1
+		}
+		return 0
+	}
+	return fmt.Sprintf("CTS=%d DSR=%d RI=%d DCD=%d RTS=%d DTR=%d",
+		bit(s.CTS), bit(s.DSR), bit(s.RI), bit(s.DCD), bit(s.RTS), bit(s.DTR))
+}
@@ -0,0 +1,155 @@
+package session
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+	"time"
+
+	serial "github.com/allbin/go-serial"
+)
+
+type pcapngBlock struct {
+	typ  uint32
+	body []byte
+}
+
+// readBlocks splits a little-endian pcapng stream into blocks
+func readBlocks(t *testing.T, b []byte) []pcapngBlock {
+	t.Helper()
+	var blocks []pcapngBlock
+	for len(b) > 0 {
+		if len(b) < 12 {
+			t.Fatalf("truncated block: %d bytes left", len(b))
+		}
+		typ := binary.LittleEndian.Uint32(b)
+		total := binary.LittleEndian.Uint32(b[4:])
+		if total%4 != 0 || int(total) > len(b) {
+			t.Fatalf("block length %d invalid", total)
+		}
+		if trailer := binary.LittleEndian.Uint32(b[total-4:]); trailer != total {
+			t.Fatalf("trailing length %d, want %d", trailer, total)
+		}
+		blocks = append(blocks, pcapngBlock{typ: typ, body: b[8 : total-4]})
+		b = b[total:]
+	}
+	return blocks
+}
+
+// packetOptions returns an enhanced packet's data and options by code
+func packetOptions(body []byte) (time.Time, []byte, map[uint16][]byte) {
+	ts := uint64(binary.LittleEndian.Uint32(body[4:]))<<32 | uint64(binary.LittleEndian.Uint32(body[8:]))
+	n := binary.LittleEndian.Uint32(body[12:])
+	data := body[20 : 20+n]
+
+	opts := make(map[uint16][]byte)
+	rest := body[20+(n+3)/4*4:]
+	for len(rest) >= 4 {
+		code := binary.LittleEndian.Uint16(rest)
+		length := binary.LittleEndian.Uint16(rest[2:])
+		if code == optEnd {
+			break
+		}
+		opts[code] = rest[4 : 4+length]
+		rest = rest[4+(length+3)/4*4:]
+	}
+	return time.Unix(0, int64(ts)), data, opts
+}
+
+func TestPcapngWriter(t *testing.T) {
+	var buf bytes.Buffer
+	pw, err := NewPcapngWriter(&buf, WithInterfaceName("/dev/ttyUSB0"))
+	if err != nil {
+		t.Fatalf("NewPcapngWriter failed: %v", err)
+	}
+
+	at := time.Unix(1700000000, 123456789)
+	pw.WriteEvent(at, Event{Kind: KindTX, Data: []byte{0x01, 0x03, 0x00}})
+	pw.WriteEvent(at.Add(time.Millisecond), Event{Kind: KindRX, Data: []byte("OK\r\n")})
+	pw.WriteEvent(at, Event{Kind: KindRX}) // Empty, not written
+	pw.RecordSignals(serial.ModemSignals{CTS: true, RTS: true})
+	pw.RecordSignals(serial.ModemSignals{CTS: true, RTS: true}) // Unchanged, not written
+	if err := pw.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	blocks := readBlocks(t, buf.Bytes())
+	if len(blocks) != 5 {
+		t.Fatalf("got %d blocks, want 5", len(blocks))
+	}
+	if blocks[0].typ != blockSectionHeader || binary.LittleEndian.Uint32(blocks[0].body) != byteOrderMagic {
+		t.Errorf("first block is not a little-endian section header")
+	}
+	if blocks[1].typ != blockInterface {
+		t.Fatalf("second block type = %#x, want interface description", blocks[1].typ)
+	}
+	if lt := binary.LittleEndian.Uint16(blocks[1].body); lt != LinkTypeUser0 {
+		t.Errorf("link type = %d, want %d", lt, LinkTypeUser0)
+	}
+	if !bytes.Contains(blocks[1].body, []byte("/dev/ttyUSB0")) {
+		t.Error("interface block missing if_name")
+	}
+
+	tests := []struct {
+		at      time.Time
+		data    string
+		flags   uint32
+		comment string
+	}{
+		{at, "\x01\x03\x00", epbFlagOutbound, ""},
+		{at.Add(time.Millisecond), "OK\r\n", epbFlagInbound, ""},
+		{time.Time{}, "", 0, "CTS=1 DSR=0 RI=0 DCD=0 RTS=1 DTR=0"},
+	}
+	for i, tt := range tests {
+		b := blocks[i+2]
+		if b.typ != blockEnhancedPacket {
+			t.Fatalf("block %d type = %#x, want enhanced packet", i+2, b.typ)
+		}
+		ts, data, opts := packetOptions(b.body)
+		if !tt.at.IsZero() && !ts.Equal(tt.at) {
+			t.Errorf("packet %d time = %v, want %v", i, ts, tt.at)
+		}
+		if string(data) != tt.data {
+			t.Errorf("packet %d data = %q, want %q", i, data, tt.data)
+		}
+		var flags uint32
+		if f, ok := opts[optEPBFlags]; ok {
+			flags = binary.LittleEndian.Uint32(f)
+		}
+		if flags != tt.flags {
+			t.Errorf("packet %d flags = %#x, want %#x", i, flags, tt.flags)
+		}
+		if got := string(opts[optComment]); got != tt.comment {
+			t.Errorf("packet %d comment = %q, want %q", i, got, tt.comment)
+		}
+	}
+}
+
+func TestRecordToPcapng(t *testing.T) {
+	var buf bytes.Buffer
+	pw, err := NewPcapngWriter(&buf)
+	if err != nil {
+		t.Fatalf("NewPcapngWriter failed: %v", err)
+	}
+
+	replay, err := NewReplayPort(recording(t, Event{Kind: KindRX, Data: []byte("hello")}))
+	if err != nil {
+		t.Fatalf("NewReplayPort failed: %v", err)
+	}
+	port := Record(replay, pw)
+	port.Write([]byte("ping"))
+	buf2 := make([]byte, 16)
+	port.Read(buf2)
+	pw.Flush()
+
+	blocks := readBlocks(t, buf.Bytes())
+	var payloads []string
+	for _, b := range blocks[2:] {
+		_, data, _ := packetOptions(b.body)
+		payloads = append(payloads, string(data))
+	}
+	if got := strings.Join(payloads, ","); got != "ping,hello" {
+		t.Errorf("packets = %q, want %q", got, "ping,hello")
+	}
+}
@@ -0,0 +1,106 @@
+package session
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Reader decodes events from a recording
+type Reader struct {
+	r      *bufio.Reader
+	start  time.Time
+	offset time.Duration
+}
+
+// NewReader reads and validates the recording header from r
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+
+	header := make([]byte, len(magic)+9)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, fmt.Errorf("%w: short header: %v", ErrInvalidRecording, err)
+	}
+	if string(header[:len(magic)]) != magic {
+		return nil, fmt.Errorf("%w: bad magic %q", ErrInvalidRecording, header[:len(magic)])
+	}
+	if v := header[len(magic)]; v != version {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, v)
+	}
+
+	return &Reader{
+		r:     br,
+		start: time.Unix(0, int64(binary.BigEndian.Uint64(header[len(magic)+1:]))),
+	}, nil
+}
+
+// Start returns the wall-clock time the recording began
+func (r *Reader) Start() time.Time {
+	return r.start
+}
+
+// Next returns the next event, or io.EOF at the end of the recording
+func (r *Reader) Next() (Event, error) {
+	kind, err := r.r.ReadByte()
+	if err != nil {
+		return Event{}, err
+	}
+
+	delta, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return Event{}, truncated(err)
+	}
+	length, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return Event{}, truncated(err)
+	}
+	if length > maxRecordSize {
+		return Event{}, fmt.Errorf("%w: record of %d bytes", ErrInvalidRecording, length)
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r.r, data); err != nil {
+		return Event{}, truncated(err)
+	}
+
+	r.offset += time.Duration(delta)
+	ev := Event{Kind: Kind(kind), Offset: r.offset}
+
+	switch ev.Kind {
+	case KindRX, KindTX:
+		ev.Data = data
+	case KindSignals:
+		if len(data) != 1 {
+			return Event{}, fmt.Errorf("%w: signal record of %d bytes", ErrInvalidRecording, len(data))
+		}
+		ev.Signals = decodeSignals(data[0])
+	default:
+		return Event{}, fmt.Errorf("%w: unknown record kind %d", ErrInvalidRecording, kind)
+	}
+	return ev, nil
+}
+
+// ReadAll returns all remaining events
+func (r *Reader) ReadAll() ([]Event, error) {
+	var events []Event
+	for {
+		ev, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return events, nil
+		}
+		if err != nil {
+			return events, err
+		}
+		events = append(events, ev)
+	}
+}
+
+func truncated(err error) error {
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	return fmt.Errorf("%w: %v", ErrInvalidRecording, err)
+}
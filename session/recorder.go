@@ -0,0 +1,234 @@
+package session
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"sync"
+	"time"
+
+	serial "github.com/allbin/go-serial"
+)
+
+// Recorder writes session events to a stream
+// Recorder methods are safe for concurrent use. Write errors are sticky:
+// after the first failure further events are discarded and Err reports it.
+type Recorder struct {
+	mu      sync.Mutex
+	w       *bufio.Writer
+	start   time.Time // Carries the monotonic clock reading used for offsets
+	last    time.Duration
+	signals *serial.ModemSignals // Last recorded signal state
+	err     error
+}
+
+// NewRecorder writes a recording header to w and returns a Recorder
+// Call Flush before closing w.
+func NewRecorder(w io.Writer) (*Recorder, error) {
+	r := &Recorder{
+		w:     bufio.NewWriter(w),
+		start: time.Now(),
+	}
+
+	header := make([]byte, 0, len(magic)+9)
+	header = append(header, magic...)
+	header = append(header, version)
+	header = binary.BigEndian.AppendUint64(header, uint64(r.start.UnixNano()))
+	if _, err := r.w.Write(header); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// RecordRX records data received from the device
+func (r *Recorder) RecordRX(data []byte) {
+	r.record(KindRX, data)
+}
+
+// RecordTX records data written to the device
+func (r *Recorder) RecordTX(data []byte) {
+	r.record(KindTX, data)
+}
+
+// RecordSignals records the modem signal state if it differs from the last recorded state
+func (r *Recorder) RecordSignals(s serial.ModemSignals) {
+	r.mu.Lock()
+	if r.signals != nil && *r.signals == s {
+		r.mu.Unlock()
+		return
+	}
+	r.signals = &s
+	r.mu.Unlock()
+
+	r.record(KindSignals, []byte{encodeSignals(s)})
+}
+
+func (r *Recorder) record(kind Kind, data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	offset := time.Since(r.start)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return
+	}
+	// Offsets from concurrent callers may arrive out of order; never go backwards
+	delta := offset - r.last
+	if delta < 0 {
+		delta = 0
+	} else {
+		r.last = offset
+	}
+
+	var hdr [1 + 2*binary.MaxVarintLen64]byte
+	hdr[0] = byte(kind)
+	n := 1 + binary.PutUvarint(hdr[1:], uint64(delta))
+	n += binary.PutUvarint(hdr[n:], uint64(len(data)))
+
+	if _, err := r.w.Write(hdr[:n]); err != nil {
+		r.err = err
+		return
+	}
+	if _, err := r.w.Write(data); err != nil {
+		r.err = err
+	}
+}
+
+// Flush writes buffered events to the underlying writer
+func (r *Recorder) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return r.err
+	}
+	r.err = r.w.Flush()
+	return r.err
+}
+
+// Err returns the first write error encountered, if any
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// Sink receives traffic from Record
+// Recorder and PcapngWriter are Sinks.
+type Sink interface {
+	RecordRX(data []byte)
+	RecordTX(data []byte)
+	RecordSignals(s serial.ModemSignals)
+}
+
+// recordingPort passes all calls through to a Port while recording traffic
+type recordingPort struct {
+	serial.Port
+	rec Sink
+}
+
+// Record returns a Port that records all data and observed signal changes on p to rec
+// Signal states are recorded whenever GetModemSignals or WaitForSignalChange
+// report a change.
+func Record(p serial.Port, rec Sink) serial.Port {
+	return &recordingPort{Port: p, rec: rec}
+}
+
+func (p *recordingPort) Read(buf []byte) (int, error) {
+	n, err := p.Port.Read(buf)
+	p.rec.RecordRX(completed(buf, n))
+	return n, err
+}
+
+func (p *recordingPort) ReadContext(ctx context.Context, buf []byte) (int, error) {
+	n, err := p.Port.ReadContext(ctx, buf)
+	p.rec.RecordRX(completed(buf, n))
+	return n, err
+}
+
+// ReadTimestamped passes through the wrapped port's arrival timestamps
+func (p *recordingPort) ReadTimestamped(ctx context.Context, buf []byte) (int, time.Time, error) {
+	n, at, err := serial.ReadTimestamped(ctx, p.Port, buf)
+	p.rec.RecordRX(completed(buf, n))
+	return n, at, err
+}
+
+// SendBreak passes through to the wrapped port; breaks are not recorded
+func (p *recordingPort) SendBreak(duration time.Duration) error {
+	return serial.SendBreak(p.Port, duration)
+}
+
+// LineCounts passes through to the wrapped port's driver counters
+func (p *recordingPort) LineCounts() (serial.LineCounts, error) {
+	return serial.GetLineCounts(p.Port)
+}
+
+// BufferLevels passes through to the wrapped port's kernel buffer levels
+func (p *recordingPort) BufferLevels() (serial.BufferLevels, error) {
+	return serial.GetBufferLevels(p.Port)
+}
+
+// LineSettings passes through to the wrapped port's driver settings
+func (p *recordingPort) LineSettings() (serial.LineSettings, error) {
+	return serial.GetLineSettings(p.Port)
+}
+
+func (p *recordingPort) Write(data []byte) (int, error) {
+	n, err := p.Port.Write(data)
+	p.rec.RecordTX(completed(data, n))
+	return n, err
+}
+
+func (p *recordingPort) WriteContext(ctx context.Context, data []byte) (int, error) {
+	n, err := p.Port.WriteContext(ctx, data)
+	p.rec.RecordTX(completed(data, n))
+	return n, err
+}
+
+// Writev records the buffers as one TX chunk, as the wrapped port writes them
+func (p *recordingPort) Writev(bufs [][]byte) (int, error) {
+	n, err := p.Port.Writev(bufs)
+	if n > 0 {
+		p.rec.RecordTX(completed(bytes.Join(bufs, nil), n))
+	}
+	return n, err
+}
+
+// completed returns the first n bytes of buf that a read or write
+// transferred, or nil if it transferred none; n is clamped to buf, as a
+// failed call may report -1
+func completed(buf []byte, n int) []byte {
+	if n <= 0 {
+		return nil
+	}
+	return buf[:min(n, len(buf))]
+}
+
+func (p *recordingPort) GetModemSignals() (serial.ModemSignals, error) {
+	s, err := p.Port.GetModemSignals()
+	if err == nil {
+		p.rec.RecordSignals(s)
+	}
+	return s, err
+}
+
+func (p *recordingPort) WaitForSignalChange(mask serial.SignalMask, timeout time.Duration) (serial.ModemSignals, serial.SignalMask, error) {
+	s, changed, err := p.Port.WaitForSignalChange(mask, timeout)
+	if err == nil {
+		p.rec.RecordSignals(s)
+	}
+	return s, changed, err
+}
+
+func (p *recordingPort) WaitForSignalChangeContext(ctx context.Context, mask serial.SignalMask) (serial.ModemSignals, serial.SignalMask, error) {
+	s, changed, err := p.Port.WaitForSignalChangeContext(ctx, mask)
+	if err == nil {
+		p.rec.RecordSignals(s)
+	}
+	return s, changed, err
+}
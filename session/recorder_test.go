@@ -0,0 +1,101 @@
+package session
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	serial "github.com/allbin/go-serial"
+)
+
+func TestRecorderRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	rec, err := NewRecorder(&buf)
+	if err != nil {
+		t.Fatalf("NewRecorder failed: %v", err)
+	}
+
+	rec.RecordTX([]byte("AT\r"))
+	time.Sleep(10 * time.Millisecond)
+	rec.RecordRX([]byte("OK\r\n"))
+	rec.RecordRX(nil) // Empty reads are not recorded
+	rec.RecordSignals(serial.ModemSignals{CTS: true, DTR: true})
+	rec.RecordSignals(serial.ModemSignals{CTS: true, DTR: true}) // Unchanged, not recorded
+	rec.RecordSignals(serial.ModemSignals{DCD: true})
+	if err := rec.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	r, err := NewReader(&buf)
+	if err != nil {
+		t.Fatalf("NewReader failed: %v", err)
+	}
+	if time.Since(r.Start()) > time.Minute {
+		t.Errorf("Start = %v, want recent", r.Start())
+	}
+
+	events, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+	want := []Event{
+		{Kind: KindTX, Data: []byte("AT\r")},
+		{Kind: KindRX, Data: []byte("OK\r\n")},
+		{Kind: KindSignals, Signals: serial.ModemSignals{CTS: true, DTR: true}},
+		{Kind: KindSignals, Signals: serial.ModemSignals{DCD: true}},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d", len(events), len(want))
+	}
+	for i, ev := range events {
+		if ev.Kind != want[i].Kind || !bytes.Equal(ev.Data, want[i].Data) || ev.Signals != want[i].Signals {
+			t.Errorf("event %d = %+v, want %+v", i, ev, want[i])
+		}
+		if i > 0 && ev.Offset < events[i-1].Offset {
+			t.Errorf("event %d offset %v before previous %v", i, ev.Offset, events[i-1].Offset)
+		}
+	}
+	if gap := events[1].Offset - events[0].Offset; gap < 10*time.Millisecond {
+		t.Errorf("RX gap = %v, want >= 10ms", gap)
+	}
+}
+
+func TestReaderInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"empty", nil, ErrInvalidRecording},
+		{"bad magic", []byte("XXXX\x01\x00\x00\x00\x00\x00\x00\x00\x00"), ErrInvalidRecording},
+		{"bad version", []byte("SREC\x09\x00\x00\x00\x00\x00\x00\x00\x00"), ErrUnsupportedVersion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewReader(bytes.NewReader(tt.data)); !errors.Is(err, tt.want) {
+				t.Errorf("NewReader error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	rec, _ := NewRecorder(&buf)
+	rec.RecordRX([]byte("truncated"))
+	rec.Flush()
+	data := buf.Bytes()[:buf.Len()-3]
+
+	r, err := NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("NewReader failed: %v", err)
+	}
+	_, err = r.Next()
+	if !errors.Is(err, ErrInvalidRecording) {
+		t.Errorf("truncated record error = %v, want ErrInvalidRecording", err)
+	}
+	if errors.Is(err, io.EOF) {
+		t.Error("truncated record must not look like a clean io.EOF")
+	}
+}
@@ -0,0 +1,447 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	serial "github.com/allbin/go-serial"
+)
+
+// ReplayOption configures replay timing
+type ReplayOption func(*replayConfig)
+
+type replayConfig struct {
+	speed     float64
+	maxGap    time.Duration
+	direction Kind
+	onSignals func(serial.ModemSignals)
+}
+
+// WithSpeed scales playback speed (2.0 plays twice as fast); values <= 0 are ignored
+func WithSpeed(factor float64) ReplayOption {
+	return func(c *replayConfig) {
+		if factor > 0 {
+			c.speed = factor
+		}
+	}
+}
+
+// WithMaxGap caps idle periods between events, shortening long silent stretches
+func WithMaxGap(gap time.Duration) ReplayOption {
+	return func(c *replayConfig) {
+		c.maxGap = gap
+	}
+}
+
+// WithDirection selects which side of the recording Replay writes: KindRX
+// (the default) plays the device to the application, KindTX re-sends what
+// the application wrote, to play captured traffic to a device again
+func WithDirection(kind Kind) ReplayOption {
+	return func(c *replayConfig) {
+		if kind == KindRX || kind == KindTX {
+			c.direction = kind
+		}
+	}
+}
+
+// WithSignalHandler is called by Replay for each recorded signal change
+// A PTY cannot carry modem signals, so this is the hook for simulating them.
+func WithSignalHandler(fn func(serial.ModemSignals)) ReplayOption {
+	return func(c *replayConfig) {
+		c.onSignals = fn
+	}
+}
+
+func newReplayConfig(opts []ReplayOption) replayConfig {
+	cfg := replayConfig{speed: 1, direction: KindRX}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// scheduler maps recorded offsets to playback offsets
+type scheduler struct {
+	cfg      replayConfig
+	recorded time.Duration
+	playback time.Duration
+}
+
+func (s *scheduler) next(offset time.Duration) time.Duration {
+	gap := offset - s.recorded
+	if s.cfg.maxGap > 0 && gap > s.cfg.maxGap {
+		gap = s.cfg.maxGap
+	}
+	s.recorded = offset
+	s.playback += time.Duration(float64(gap) / s.cfg.speed)
+	return s.playback
+}
+
+// sleepUntil waits for the deadline, returning early with ctx's error
+func sleepUntil(ctx context.Context, deadline time.Time) error {
+	d := time.Until(deadline)
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Replay writes the RX side of a recording to w, preserving the gaps between chunks
+// TX events are skipped, or with WithDirection(KindTX) the RX events.
+// Typically w is the master side of a PTY from serial.OpenPTY, or a port
+// when re-sending TX. Returns nil when the recording ends.
+func Replay(ctx context.Context, r *Reader, w io.Writer, opts ...ReplayOption) error {
+	sched := &scheduler{cfg: newReplayConfig(opts)}
+	skip := KindTX
+	if sched.cfg.direction == KindTX {
+		skip = KindRX
+	}
+	base := time.Now()
+
+	for {
+		ev, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		due := base.Add(sched.next(ev.Offset))
+		if ev.Kind == skip {
+			continue
+		}
+		if err := sleepUntil(ctx, due); err != nil {
+			return err
+		}
+
+		switch ev.Kind {
+		case KindRX, KindTX:
+			if _, err := w.Write(ev.Data); err != nil {
+				return err
+			}
+		case KindSignals:
+			if sched.cfg.onSignals != nil {
+				sched.cfg.onSignals(ev.Signals)
+			}
+		}
+	}
+}
+
+// ReplayPort is an in-memory Port that plays back a recording
+// Reads return recorded RX chunks at their original times, modem signals
+// follow the recorded changes, and writes are collected for inspection.
+// The playback clock starts when the port is created.
+type ReplayPort struct {
+	mu      sync.Mutex
+	base    time.Time
+	events  []Event
+	due     []time.Duration // Playback offset of each event
+	rxIdx   int             // Next event to consider for reads
+	pending []byte          // Remainder of a chunk larger than the caller's buffer
+	written []byte
+	rts     bool
+	dtr     bool
+	closed  bool
+	done    chan struct{}
+}
+
+var _ serial.Port = (*ReplayPort)(nil)
+
+// NewReplayPort reads the whole recording and returns a port replaying it
+func NewReplayPort(r *Reader, opts ...ReplayOption) (*ReplayPort, error) {
+	events, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	sched := &scheduler{cfg: newReplayConfig(opts)}
+	due := make([]time.Duration, len(events))
+	for i, ev := range events {
+		due[i] = sched.next(ev.Offset)
+	}
+
+	return &ReplayPort{
+		base:   time.Now(),
+		events: events,
+		due:    due,
+		done:   make(chan struct{}),
+	}, nil
+}
+
+// Written returns a copy of everything written to the port
+func (p *ReplayPort) Written() []byte {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]byte(nil), p.written...)
+}
+
+func (p *ReplayPort) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.closed {
+		p.closed = true
+		close(p.done)
+	}
+	return nil
+}
+
+func (p *ReplayPort) Read(buf []byte) (int, error) {
+	return p.ReadContext(context.Background(), buf)
+}
+
+// ReadContext waits for the next recorded RX chunk and returns io.EOF after the last one
+func (p *ReplayPort) ReadContext(ctx context.Context, buf []byte) (int, error) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return 0, serial.ErrPortClosed
+	}
+	if len(p.pending) > 0 {
+		n := copy(buf, p.pending)
+		p.pending = p.pending[n:]
+		p.mu.Unlock()
+		return n, nil
+	}
+
+	idx := p.rxIdx
+	for idx < len(p.events) && p.events[idx].Kind != KindRX {
+		idx++
+	}
+	if idx == len(p.events) {
+		p.rxIdx = idx
+		p.mu.Unlock()
+		return 0, io.EOF
+	}
+	deadline := p.base.Add(p.due[idx])
+	p.mu.Unlock()
+
+	if err := p.wait(ctx, deadline); err != nil {
+		return 0, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.rxIdx > idx {
+		// Another reader consumed this chunk while we waited
+		return 0, nil
+	}
+	p.rxIdx = idx + 1
+	n := copy(buf, p.events[idx].Data)
+	p.pending = p.events[idx].Data[n:]
+	return n, nil
+}
+
+func (p *ReplayPort) wait(ctx context.Context, deadline time.Time) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-p.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	if err := sleepUntil(ctx, deadline); err != nil {
+		select {
+		case <-p.done:
+			return serial.ErrPortClosed
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *ReplayPort) Write(data []byte) (int, error) {
+	return p.WriteContext(context.Background(), data)
+}
+
+func (p *ReplayPort) WriteContext(ctx context.Context, data []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return 0, serial.ErrPortClosed
+	}
+	p.written = append(p.written, data...)
+	return len(data), nil
+}
+
+// Writev records the concatenation of bufs like a Write
+func (p *ReplayPort) Writev(bufs [][]byte) (int, error) {
+	return p.Write(bytes.Join(bufs, nil))
+}
+
+// signalsAt returns the recorded signal state at playback offset t and the index of the next signal event
+func (p *ReplayPort) signalsAt(t time.Duration) (serial.ModemSignals, int) {
+	var s serial.ModemSignals
+	for i, ev := range p.events {
+		if ev.Kind != KindSignals {
+			continue
+		}
+		if p.due[i] > t {
+			return s, i
+		}
+		s = ev.Signals
+	}
+	return s, len(p.events)
+}
+
+func (p *ReplayPort) GetModemSignals() (serial.ModemSignals, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return serial.ModemSignals{}, serial.ErrPortClosed
+	}
+	s, _ := p.signalsAt(time.Since(p.base))
+	s.RTS, s.DTR = p.rts, p.dtr
+	return s, nil
+}
+
+func (p *ReplayPort) GetCTSStatus() (bool, error) {
+	s, err := p.GetModemSignals()
+	return s.CTS, err
+}
+
+func (p *ReplayPort) SetRTS(state bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rts = state
+	return nil
+}
+
+func (p *ReplayPort) GetRTS() (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.rts, nil
+}
+
+func (p *ReplayPort) SetDTR(state bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.dtr = state
+	return nil
+}
+
+func (p *ReplayPort) GetDTR() (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.dtr, nil
+}
+
+func (p *ReplayPort) WaitForSignalChange(mask serial.SignalMask, timeout time.Duration) (serial.ModemSignals, serial.SignalMask, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	s, changed, err := p.WaitForSignalChangeContext(ctx, mask)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return s, 0, serial.ErrSignalTimeout
+	}
+	return s, changed, err
+}
+
+// WaitForSignalChangeContext waits for the next recorded change among the signals in mask
+func (p *ReplayPort) WaitForSignalChangeContext(ctx context.Context, mask serial.SignalMask) (serial.ModemSignals, serial.SignalMask, error) {
+	if mask == 0 {
+		return serial.ModemSignals{}, 0, serial.ErrInvalidSignalMask
+	}
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return serial.ModemSignals{}, 0, serial.ErrPortClosed
+	}
+	prev, idx := p.signalsAt(time.Since(p.base))
+	var changed serial.SignalMask
+	for ; idx < len(p.events); idx++ {
+		ev := p.events[idx]
+		if ev.Kind != KindSignals {
+			continue
+		}
+		if changed = signalChanges(prev, ev.Signals) & mask; changed != 0 {
+			break
+		}
+		prev = ev.Signals
+	}
+	p.mu.Unlock()
+
+	if idx == len(p.events) {
+		// No further changes in the recording; wait for cancellation
+		<-ctx.Done()
+		return serial.ModemSignals{}, 0, ctx.Err()
+	}
+	if err := p.wait(ctx, p.base.Add(p.due[idx])); err != nil {
+		return serial.ModemSignals{}, 0, err
+	}
+
+	s := p.events[idx].Signals
+	p.mu.Lock()
+	s.RTS, s.DTR = p.rts, p.dtr
+	p.mu.Unlock()
+	return s, changed, nil
+}
+
+// signalChanges reports which input signals differ between a and b
+func signalChanges(a, b serial.ModemSignals) serial.SignalMask {
+	var m serial.SignalMask
+	if a.CTS != b.CTS {
+		m |= serial.SignalCTS
+	}
+	if a.DSR != b.DSR {
+		m |= serial.SignalDSR
+	}
+	if a.RI != b.RI {
+		m |= serial.SignalRI
+	}
+	if a.DCD != b.DCD {
+		m |= serial.SignalDCD
+	}
+	return m
+}
+
+func (p *ReplayPort) DrainOutput() error { return nil }
+func (p *ReplayPort) FlushOutput() error { return nil }
+
+// FlushInput discards the unread remainder of the current chunk
+func (p *ReplayPort) FlushInput() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pending = nil
+	return nil
+}
+
+// DrainInput discards all RX chunks that are already due
+func (p *ReplayPort) DrainInput() error {
+	_, err := p.DrainInputContext(context.Background(), 0)
+	return err
+}
+
+// DrainInputContext discards the RX chunks that are already due, like
+// DrainInput, returning their size; it never waits, and limit is ignored
+func (p *ReplayPort) DrainInputContext(ctx context.Context, limit int) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	drained := len(p.pending)
+	p.pending = nil
+	now := time.Since(p.base)
+	for p.rxIdx < len(p.events) && p.due[p.rxIdx] <= now {
+		if p.events[p.rxIdx].Kind == KindRX {
+			drained += len(p.events[p.rxIdx].Data)
+		}
+		p.rxIdx++
+	}
+	return drained, nil
+}
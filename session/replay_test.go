@@ -0,0 +1,319 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	serial "github.com/allbin/go-serial"
+)
+
+// recording builds a recording from events with explicit offsets
+func recording(t *testing.T, events ...Event) *Reader {
+	t.Helper()
+
+	var buf bytes.Buffer
+	rec, err := NewRecorder(&buf)
+	if err != nil {
+		t.Fatalf("NewRecorder failed: %v", err)
+	}
+	// Rewind the recorder clock so each event lands at its offset
+	for _, ev := range events {
+		rec.start = time.Now().Add(-ev.Offset)
+		switch ev.Kind {
+		case KindSignals:
+			rec.RecordSignals(ev.Signals)
+		default:
+			rec.record(ev.Kind, ev.Data)
+		}
+	}
+	if err := rec.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	r, err := NewReader(&buf)
+	if err != nil {
+		t.Fatalf("NewReader failed: %v", err)
+	}
+	return r
+}
+
+// timedWriter records when each write happens
+type timedWriter struct {
+	data  []byte
+	times []time.Time
+}
+
+func (w *timedWriter) Write(p []byte) (int, error) {
+	w.data = append(w.data, p...)
+	w.times = append(w.times, time.Now())
+	return len(p), nil
+}
+
+func TestReplayPreservesGaps(t *testing.T) {
+	r := recording(t,
+		Event{Kind: KindRX, Offset: 0, Data: []byte("a")},
+		Event{Kind: KindTX, Offset: 10 * time.Millisecond, Data: []byte("ignored")},
+		Event{Kind: KindRX, Offset: 40 * time.Millisecond, Data: []byte("b")},
+		Event{Kind: KindSignals, Offset: 50 * time.Millisecond, Signals: serial.ModemSignals{CTS: true}},
+	)
+
+	var signals []serial.ModemSignals
+	w := &timedWriter{}
+	err := Replay(context.Background(), r, w, WithSignalHandler(func(s serial.ModemSignals) {
+		signals = append(signals, s)
+	}))
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+
+	if string(w.data) != "ab" {
+		t.Errorf("replayed %q, want ab (TX skipped)", w.data)
+	}
+	if gap := w.times[1].Sub(w.times[0]); gap < 35*time.Millisecond {
+		t.Errorf("gap = %v, want ~40ms", gap)
+	}
+	if len(signals) != 1 || !signals[0].CTS {
+		t.Errorf("signals = %+v, want one CTS change", signals)
+	}
+}
+
+func TestReplaySpeedAndMaxGap(t *testing.T) {
+	r := recording(t,
+		Event{Kind: KindRX, Offset: 0, Data: []byte("a")},
+		Event{Kind: KindRX, Offset: 10 * time.Second, Data: []byte("b")},
+		Event{Kind: KindRX, Offset: 10*time.Second + 40*time.Millisecond, Data: []byte("c")},
+	)
+
+	w := &timedWriter{}
+	start := time.Now()
+	if err := Replay(context.Background(), r, w, WithMaxGap(50*time.Millisecond), WithSpeed(2)); err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("replay took %v, want idle gap capped", elapsed)
+	}
+	// 50ms (capped) / 2 + 40ms / 2
+	if total := w.times[2].Sub(w.times[0]); total < 40*time.Millisecond {
+		t.Errorf("total = %v, want ~45ms", total)
+	}
+}
+
+func TestReplayTX(t *testing.T) {
+	r := recording(t,
+		Event{Kind: KindTX, Offset: 0, Data: []byte("AT\r")},
+		Event{Kind: KindRX, Offset: 10 * time.Millisecond, Data: []byte("OK")},
+		Event{Kind: KindTX, Offset: 30 * time.Millisecond, Data: []byte("ATI\r")},
+	)
+
+	w := &timedWriter{}
+	if err := Replay(context.Background(), r, w, WithDirection(KindTX)); err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if string(w.data) != "AT\rATI\r" {
+		t.Errorf("replayed %q, want the TX side only", w.data)
+	}
+	if gap := w.times[1].Sub(w.times[0]); gap < 25*time.Millisecond {
+		t.Errorf("gap = %v, want ~30ms", gap)
+	}
+}
+
+func TestReplayCancel(t *testing.T) {
+	r := recording(t, Event{Kind: KindRX, Offset: time.Hour, Data: []byte("late")})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := Replay(ctx, r, io.Discard); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Replay error = %v, want DeadlineExceeded", err)
+	}
+}
+
+func TestReplayPort(t *testing.T) {
+	r := recording(t,
+		Event{Kind: KindRX, Offset: 0, Data: []byte("hello")},
+		Event{Kind: KindSignals, Offset: 30 * time.Millisecond, Signals: serial.ModemSignals{CTS: true}},
+		Event{Kind: KindRX, Offset: 30 * time.Millisecond, Data: []byte("!")},
+	)
+	port, err := NewReplayPort(r)
+	if err != nil {
+		t.Fatalf("NewReplayPort failed: %v", err)
+	}
+	defer port.Close()
+
+	buf := make([]byte, 3)
+	var got []byte
+	for {
+		n, err := port.Read(buf)
+		got = append(got, buf[:n]...)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Read failed: %v", err)
+		}
+	}
+	if string(got) != "hello!" {
+		t.Errorf("read %q, want hello!", got)
+	}
+
+	cts, _ := port.GetCTSStatus()
+	if !cts {
+		t.Error("CTS should follow the recording after 30ms")
+	}
+
+	port.Write([]byte("tx"))
+	if string(port.Written()) != "tx" {
+		t.Errorf("Written = %q, want tx", port.Written())
+	}
+}
+
+func TestReplayPortWaitForSignalChange(t *testing.T) {
+	r := recording(t,
+		Event{Kind: KindSignals, Offset: 10 * time.Millisecond, Signals: serial.ModemSignals{DSR: true}},
+		Event{Kind: KindSignals, Offset: 20 * time.Millisecond, Signals: serial.ModemSignals{DSR: true, CTS: true}},
+	)
+	port, err := NewReplayPort(r)
+	if err != nil {
+		t.Fatalf("NewReplayPort failed: %v", err)
+	}
+	defer port.Close()
+
+	signals, changed, err := port.WaitForSignalChange(serial.SignalCTS, time.Second)
+	if err != nil {
+		t.Fatalf("WaitForSignalChange failed: %v", err)
+	}
+	if changed != serial.SignalCTS || !signals.CTS || !signals.DSR {
+		t.Errorf("got signals %+v changed %v, want CTS change", signals, changed)
+	}
+
+	if _, _, err := port.WaitForSignalChange(serial.SignalCTS, 10*time.Millisecond); !errors.Is(err, serial.ErrSignalTimeout) {
+		t.Errorf("error = %v, want ErrSignalTimeout", err)
+	}
+}
+
+func TestRecordPort(t *testing.T) {
+	inner, err := NewReplayPort(recording(t, Event{Kind: KindRX, Data: []byte("rx")}))
+	if err != nil {
+		t.Fatalf("NewReplayPort failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	rec, _ := NewRecorder(&buf)
+	port := Record(inner, rec)
+
+	port.Write([]byte("tx"))
+	port.Writev([][]byte{[]byte("ve"), []byte("ctor")})
+	io.ReadAll(port)
+	port.GetModemSignals()
+	rec.Flush()
+
+	r, _ := NewReader(&buf)
+	events, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+	kinds := make([]Kind, len(events))
+	for i, ev := range events {
+		kinds[i] = ev.Kind
+	}
+	if len(kinds) != 4 || kinds[0] != KindTX || kinds[1] != KindTX || kinds[2] != KindRX || kinds[3] != KindSignals {
+		t.Fatalf("recorded kinds %v, want [TX TX RX SIGNALS]", kinds)
+	}
+	if string(events[1].Data) != "vector" {
+		t.Errorf("Writev recorded %q, want the joined buffers", events[1].Data)
+	}
+	if string(inner.Written()) != "txvector" {
+		t.Errorf("replay port received %q, want txvector", inner.Written())
+	}
+}
+
+// failingPort fails every read and write the way a device port does when
+// the system call fails, reporting -1 bytes
+type failingPort struct {
+	serial.Port
+}
+
+func (failingPort) Read([]byte) (int, error) { return -1, errors.New("read failed") }
+func (failingPort) ReadContext(context.Context, []byte) (int, error) {
+	return -1, errors.New("read failed")
+}
+func (failingPort) Write([]byte) (int, error) { return -1, errors.New("write failed") }
+func (failingPort) WriteContext(context.Context, []byte) (int, error) {
+	return -1, errors.New("write failed")
+}
+func (failingPort) Writev([][]byte) (int, error) { return -1, errors.New("write failed") }
+
+func TestRecordPortErrors(t *testing.T) {
+	var buf bytes.Buffer
+	rec, _ := NewRecorder(&buf)
+	port := Record(failingPort{}, rec)
+
+	data := make([]byte, 8)
+	ctx := context.Background()
+	if _, err := port.Read(data); err == nil {
+		t.Error("Read did not fail")
+	}
+	if _, err := port.ReadContext(ctx, data); err == nil {
+		t.Error("ReadContext did not fail")
+	}
+	if _, _, err := serial.ReadTimestamped(ctx, port, data); err == nil {
+		t.Error("ReadTimestamped did not fail")
+	}
+	if _, err := port.Write(data); err == nil {
+		t.Error("Write did not fail")
+	}
+	if _, err := port.WriteContext(ctx, data); err == nil {
+		t.Error("WriteContext did not fail")
+	}
+	if _, err := port.Writev([][]byte{data}); err == nil {
+		t.Error("Writev did not fail")
+	}
+	rec.Flush()
+
+	r, _ := NewReader(&buf)
+	events, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+	if len(events) != 0 {
+		t.Errorf("recorded %d events for failed calls, want none", len(events))
+	}
+}
+
+func TestReplayThroughPTY(t *testing.T) {
+	master, slavePath, err := serial.OpenPTY()
+	if err != nil {
+		t.Skipf("PTY not available: %v", err)
+	}
+	defer master.Close()
+
+	port, err := serial.Open(slavePath, serial.WithReadTimeout(100*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Open(%s) failed: %v", slavePath, err)
+	}
+	defer port.Close()
+
+	r := recording(t,
+		Event{Kind: KindRX, Offset: 0, Data: []byte("$GPGGA")},
+		Event{Kind: KindRX, Offset: 20 * time.Millisecond, Data: []byte("\r\n")},
+	)
+	if err := Replay(context.Background(), r, master); err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+
+	var got []byte
+	buf := make([]byte, 64)
+	for deadline := time.Now().Add(time.Second); len(got) < 8 && time.Now().Before(deadline); {
+		n, err := port.Read(buf)
+		if err != nil {
+			t.Fatalf("Read failed: %v", err)
+		}
+		got = append(got, buf[:n]...)
+	}
+	if string(got) != "$GPGGA\r\n" {
+		t.Errorf("read %q from PTY, want $GPGGA\\r\\n", got)
+	}
+}
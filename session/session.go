@@ -0,0 +1,119 @@
+// Package session records serial traffic to a compact binary file and replays it
+// with the original timing.
+//
+// A recording holds RX and TX data and modem signal changes, each stamped with
+// a monotonic offset from the start of the session. Wrap a port with Record to
+// capture everything that passes through it:
+//
+//	f, _ := os.Create("field-bug.srec")
+//	rec, _ := session.NewRecorder(f)
+//	port = session.Record(port, rec)
+//	... use port as usual ...
+//	rec.Flush()
+//
+// Replay the RX side into a PTY (so unmodified code can open the slave device)
+// or use a ReplayPort directly in tests:
+//
+//	r, _ := session.NewReader(f)
+//	master, slavePath, _ := serial.OpenPTY()
+//	go session.Replay(ctx, r, master)
+//	port, _ := serial.Open(slavePath)
+//
+// A PcapngWriter can take the Recorder's place to produce a capture file for
+// Wireshark instead.
+package session
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	serial "github.com/allbin/go-serial"
+)
+
+// Predefined errors for recordings
+var (
+	ErrInvalidRecording   = errors.New("invalid session recording")
+	ErrUnsupportedVersion = errors.New("unsupported session recording version")
+)
+
+// File format:
+//
+//	header: magic "SREC" | version (1 byte) | start time (int64 unix nanoseconds, big-endian)
+//	record: kind (1 byte) | offset delta (uvarint ns since previous record) | length (uvarint) | data
+//
+// Signal records carry one byte with a signalBit per asserted signal.
+const (
+	magic   = "SREC"
+	version = 1
+)
+
+// maxRecordSize bounds the length field when reading so corrupt files fail fast
+const maxRecordSize = 1 << 20
+
+// Kind identifies the type of a recorded event
+type Kind byte
+
+const (
+	KindRX      Kind = 1 // Data received from the device
+	KindTX      Kind = 2 // Data written to the device
+	KindSignals Kind = 3 // Modem signal state changed
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindRX:
+		return "RX"
+	case KindTX:
+		return "TX"
+	case KindSignals:
+		return "SIGNALS"
+	default:
+		return fmt.Sprintf("Kind(%d)", byte(k))
+	}
+}
+
+// Event is a single recorded occurrence
+type Event struct {
+	Kind    Kind
+	Offset  time.Duration       // Monotonic time since the start of the recording
+	Data    []byte              // RX/TX bytes
+	Signals serial.ModemSignals // Signal state for KindSignals
+}
+
+// Bit positions for encoding ModemSignals in signal records
+const (
+	signalBitCTS = 1 << iota
+	signalBitDSR
+	signalBitRI
+	signalBitDCD
+	signalBitRTS
+	signalBitDTR
+)
+
+func encodeSignals(s serial.ModemSignals) byte {
+	var b byte
+	for _, f := range []struct {
+		set bool
+		bit byte
+	}{
+		{s.CTS, signalBitCTS}, {s.DSR, signalBitDSR}, {s.RI, signalBitRI},
+		{s.DCD, signalBitDCD}, {s.RTS, signalBitRTS}, {s.DTR, signalBitDTR},
+	} {
+		if f.set {
+			b |= f.bit
+		}
+	}
+	return b
+}
+
+func decodeSignals(b byte) serial.ModemSignals {
+	return serial.ModemSignals{
+		CTS: b&signalBitCTS != 0,
+		DSR: b&signalBitDSR != 0,
+		RI:  b&signalBitRI != 0,
+		DCD: b&signalBitDCD != 0,
+		RTS: b&signalBitRTS != 0,
+		DTR: b&signalBitDTR != 0,
+	}
+}
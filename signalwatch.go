@@ -0,0 +1,152 @@
+package serial
+
+import (
+	"sync"
+
+	"golang.org/x/sys/unix"
+)
+
+// allSignalInputs are the modem inputs TIOCMIWAIT can wait for
+const allSignalInputs = unix.TIOCM_CTS | unix.TIOCM_DSR | unix.TIOCM_RI | unix.TIOCM_CAR
+
+// signalSource is what a signalWatcher waits on
+type signalSource interface {
+	// waitSignals blocks until a modem input changes
+	waitSignals() error
+	// signalStatus returns the TIOCM bits of the current modem state
+	signalStatus() (int, error)
+	Close() error
+}
+
+// signalWatcher shares one goroutine blocked in TIOCMIWAIT among all
+// WaitForSignalChange calls on a port
+// The ioctl cannot be interrupted, so a goroutine per call would stay
+// blocked after its caller timed out until the next signal change; with
+// the watcher, a caller that gives up only unsubscribes. The goroutine
+// exits once a change leaves nobody waiting, so an idle port has none.
+type signalWatcher struct {
+	open func() (signalSource, error) // Source for a new watcher goroutine
+
+	mu      sync.Mutex
+	running bool // A goroutine is waiting on a source
+	stopped bool // The port closed
+	waiters map[*signalWaiter]struct{}
+}
+
+// signalWaiter is one WaitForSignalChange call
+type signalWaiter struct {
+	bits int // TIOCM bits of the lines waited for
+	old  int // TIOCM status when the wait began
+	ch   chan signalResult
+}
+
+// signalResult ends a wait with the new TIOCM status, or an error
+type signalResult struct {
+	status int
+	err    error
+}
+
+func newSignalWatcher(open func() (signalSource, error)) *signalWatcher {
+	return &signalWatcher{open: open, waiters: make(map[*signalWaiter]struct{})}
+}
+
+// subscribe starts waiting for a line in bits to differ from old, starting
+// the watcher goroutine if none is running
+func (w *signalWatcher) subscribe(bits, old int) (*signalWaiter, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.stopped {
+		return nil, ErrPortClosed
+	}
+	if !w.running {
+		src, err := w.open()
+		if err != nil {
+			return nil, err
+		}
+		w.running = true
+		go w.run(src)
+	}
+
+	sw := &signalWaiter{bits: bits, old: old, ch: make(chan signalResult, 1)}
+	w.waiters[sw] = struct{}{}
+	return sw, nil
+}
+
+// unsubscribe ends a wait that timed out or was cancelled
+func (w *signalWatcher) unsubscribe(sw *signalWaiter) {
+	w.mu.Lock()
+	delete(w.waiters, sw)
+	w.mu.Unlock()
+}
+
+// stop fails all waits with ErrPortClosed; a goroutine still blocked in
+// the ioctl exits, releasing its source, at the next signal change
+func (w *signalWatcher) stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.stopped = true
+	for sw := range w.waiters {
+		sw.ch <- signalResult{err: ErrPortClosed}
+		delete(w.waiters, sw)
+	}
+}
+
+func (w *signalWatcher) run(src signalSource) {
+	defer src.Close()
+	for {
+		// The state is read before every wait, so changes between a
+		// waiter's own reading and the ioctl are not missed
+		status, err := src.signalStatus()
+		if w.deliver(status, err) {
+			return
+		}
+		if err := src.waitSignals(); err != nil {
+			w.deliver(0, err)
+			return
+		}
+	}
+}
+
+// deliver ends the waits whose lines differ in status, or all of them on
+// an error, and reports whether the goroutine should exit: after an error,
+// once the port closed or when nobody is waiting any more
+func (w *signalWatcher) deliver(status int, err error) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.stopped {
+		err = ErrPortClosed
+	}
+	for sw := range w.waiters {
+		if err != nil || (status^sw.old)&sw.bits != 0 {
+			sw.ch <- signalResult{status: status, err: err}
+			delete(w.waiters, sw)
+		}
+	}
+	if err != nil || len(w.waiters) == 0 {
+		w.running = false
+		return true
+	}
+	return false
+}
+
+// fdSignals waits on a duplicate of a port's descriptor, which stays valid
+// while the ioctl blocks even if the port is closed meanwhile
+type fdSignals struct {
+	fd    int
+	trace *ioTracer
+}
+
+func (s *fdSignals) waitSignals() error {
+	return tiocmiwait(s.trace, s.fd, allSignalInputs)
+}
+
+func (s *fdSignals) signalStatus() (int, error) {
+	return getModemStatus(s.trace, s.fd)
+}
+
+func (s *fdSignals) Close() error {
+	return unix.Close(s.fd)
+}
@@ -0,0 +1,193 @@
+package serial
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// fakeSignals is a signalSource driven by the test: each value sent on
+// changes becomes the new status and wakes waitSignals
+type fakeSignals struct {
+	changes chan int
+	fail    chan error
+
+	mu     sync.Mutex
+	status int
+	closed bool
+}
+
+func (f *fakeSignals) waitSignals() error {
+	select {
+	case status := <-f.changes:
+		f.mu.Lock()
+		f.status = status
+		f.mu.Unlock()
+		return nil
+	case err := <-f.fail:
+		return err
+	}
+}
+
+func (f *fakeSignals) signalStatus() (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.status, nil
+}
+
+func (f *fakeSignals) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	return nil
+}
+
+func (f *fakeSignals) isClosed() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.closed
+}
+
+// newFakeWatcher returns a watcher whose goroutines wait on the sources it
+// records in opened
+func newFakeWatcher(t *testing.T) (*signalWatcher, *[]*fakeSignals) {
+	t.Helper()
+	var opened []*fakeSignals
+	w := newSignalWatcher(func() (signalSource, error) {
+		f := &fakeSignals{changes: make(chan int), fail: make(chan error)}
+		opened = append(opened, f)
+		return f, nil
+	})
+	return w, &opened
+}
+
+func receive(t *testing.T, sw *signalWaiter) signalResult {
+	t.Helper()
+	select {
+	case r := <-sw.ch:
+		return r
+	case <-time.After(time.Second):
+		t.Fatal("wait not ended")
+		return signalResult{}
+	}
+}
+
+func TestSignalWatcherDeliversMatchingLines(t *testing.T) {
+	w, opened := newFakeWatcher(t)
+
+	cts, err := w.subscribe(unix.TIOCM_CTS, 0)
+	if err != nil {
+		t.Fatalf("subscribe failed: %v", err)
+	}
+	dsr, _ := w.subscribe(unix.TIOCM_DSR, 0)
+	if len(*opened) != 1 {
+		t.Fatalf("opened %d sources, want 1 shared", len(*opened))
+	}
+	src := (*opened)[0]
+
+	src.changes <- unix.TIOCM_CTS
+	if r := receive(t, cts); r.err != nil || r.status != unix.TIOCM_CTS {
+		t.Errorf("CTS wait = %+v", r)
+	}
+	select {
+	case r := <-dsr.ch:
+		t.Fatalf("DSR wait ended by a CTS change: %+v", r)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	src.changes <- unix.TIOCM_CTS | unix.TIOCM_DSR
+	if r := receive(t, dsr); r.status != unix.TIOCM_CTS|unix.TIOCM_DSR {
+		t.Errorf("DSR wait = %+v", r)
+	}
+
+	// Nobody is left waiting, so the goroutine exits and releases the source
+	deadline := time.Now().Add(time.Second)
+	for !src.isClosed() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if !src.isClosed() {
+		t.Error("idle watcher kept its source open")
+	}
+}
+
+func TestSignalWatcherStateChangedBeforeWait(t *testing.T) {
+	w, _ := newFakeWatcher(t)
+
+	// The caller saw CAR up, but it dropped before the goroutine's first
+	// reading, with no change left for the ioctl to report
+	sw, _ := w.subscribe(unix.TIOCM_CAR, unix.TIOCM_CAR)
+	if r := receive(t, sw); r.err != nil || r.status != 0 {
+		t.Errorf("wait = %+v, want status 0", r)
+	}
+}
+
+func TestSignalWatcherTimeoutsDoNotLeak(t *testing.T) {
+	w, opened := newFakeWatcher(t)
+	before := runtime.NumGoroutine()
+
+	// One wait stays pending throughout, keeping the goroutine in the ioctl
+	pending, err := w.subscribe(unix.TIOCM_CTS, 0)
+	if err != nil {
+		t.Fatalf("subscribe failed: %v", err)
+	}
+	for range 200 {
+		sw, err := w.subscribe(unix.TIOCM_DSR, 0)
+		if err != nil {
+			t.Fatalf("subscribe failed: %v", err)
+		}
+		w.unsubscribe(sw)
+	}
+
+	if n := runtime.NumGoroutine() - before; n > 1 {
+		t.Errorf("%d goroutines running after 200 abandoned waits, want 1", n)
+	}
+	if len(*opened) != 1 {
+		t.Errorf("opened %d sources, want 1", len(*opened))
+	}
+
+	// Ending the last wait ends the goroutine
+	(*opened)[0].changes <- unix.TIOCM_CTS
+	receive(t, pending)
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := runtime.NumGoroutine() - before; n > 0 {
+		t.Errorf("%d goroutines left after the last wait ended", n)
+	}
+}
+
+func TestSignalWatcherError(t *testing.T) {
+	w, opened := newFakeWatcher(t)
+	sw, _ := w.subscribe(unix.TIOCM_CTS, 0)
+
+	errIO := errors.New("device gone")
+	(*opened)[0].fail <- errIO
+	if r := receive(t, sw); !errors.Is(r.err, errIO) {
+		t.Errorf("wait error = %v, want %v", r.err, errIO)
+	}
+
+	// The next wait starts a new goroutine
+	sw, _ = w.subscribe(unix.TIOCM_CTS, 0)
+	if len(*opened) != 2 {
+		t.Fatalf("opened %d sources after an error, want 2", len(*opened))
+	}
+	w.unsubscribe(sw)
+}
+
+func TestSignalWatcherStop(t *testing.T) {
+	w, _ := newFakeWatcher(t)
+	sw, _ := w.subscribe(unix.TIOCM_CTS, 0)
+
+	w.stop()
+	if r := receive(t, sw); !errors.Is(r.err, ErrPortClosed) {
+		t.Errorf("wait error after stop = %v, want ErrPortClosed", r.err)
+	}
+	if _, err := w.subscribe(unix.TIOCM_CTS, 0); !errors.Is(err, ErrPortClosed) {
+		t.Errorf("subscribe after stop = %v, want ErrPortClosed", err)
+	}
+}
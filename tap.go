@@ -0,0 +1,101 @@
+package serial
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// TapFormat selects how WithTrafficTap mirrors traffic
+type TapFormat int
+
+const (
+	// TapRaw copies RX and TX bytes unchanged, interleaved in wire order
+	TapRaw TapFormat = iota
+	// TapHex writes one line per chunk: "15:04:05.000000 TX 41 54 0d"
+	TapHex
+	// TapHexdump writes a timestamped header per chunk followed by
+	// offset, hex and ASCII columns like hexdump -C
+	TapHexdump
+)
+
+// String returns the format name as accepted by ParseTapFormat
+func (f TapFormat) String() string {
+	switch f {
+	case TapRaw:
+		return "raw"
+	case TapHex:
+		return "hex"
+	case TapHexdump:
+		return "hexdump"
+	default:
+		return fmt.Sprintf("TapFormat(%d)", int(f))
+	}
+}
+
+// ParseTapFormat converts a format name to a TapFormat
+func ParseTapFormat(name string) (TapFormat, error) {
+	switch name {
+	case "raw":
+		return TapRaw, nil
+	case "hex":
+		return TapHex, nil
+	case "hexdump":
+		return TapHexdump, nil
+	default:
+		return 0, fmt.Errorf("unknown tap format %q (want raw, hex or hexdump)", name)
+	}
+}
+
+// WithTrafficTap mirrors every byte read from and written to the port to w
+// Writes are mirrored when they reach the device, so a CTS-queued write
+// appears when CTS allows it out, not when Write was called. Errors writing
+// to w are ignored; the tap never affects port I/O.
+func WithTrafficTap(w io.Writer, format TapFormat) Option {
+	return func(c *Config) error {
+		if w == nil || format < TapRaw || format > TapHexdump {
+			return ErrInvalidConfig
+		}
+		c.Tap = w
+		c.TapFormat = format
+		return nil
+	}
+}
+
+// trafficTap serializes mirrored traffic; a nil *trafficTap discards it
+type trafficTap struct {
+	mu     sync.Mutex
+	w      io.Writer
+	format TapFormat
+}
+
+func newTrafficTap(config Config) *trafficTap {
+	if config.Tap == nil {
+		return nil
+	}
+	return &trafficTap{w: config.Tap, format: config.TapFormat}
+}
+
+func (t *trafficTap) rx(data []byte) { t.mirror("RX", data) }
+func (t *trafficTap) tx(data []byte) { t.mirror("TX", data) }
+
+func (t *trafficTap) mirror(dir string, data []byte) {
+	if t == nil || len(data) == 0 {
+		return
+	}
+	now := time.Now().Format("15:04:05.000000")
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch t.format {
+	case TapHex:
+		fmt.Fprintf(t.w, "%s %s % x\n", now, dir, data)
+	case TapHexdump:
+		fmt.Fprintf(t.w, "%s %s %d bytes\n%s", now, dir, len(data), hex.Dump(data))
+	default:
+		t.w.Write(data)
+	}
+}
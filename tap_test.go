@@ -0,0 +1,77 @@
+package serial
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTapFormat(t *testing.T) {
+	for _, f := range []TapFormat{TapRaw, TapHex, TapHexdump} {
+		got, err := ParseTapFormat(f.String())
+		if err != nil || got != f {
+			t.Errorf("ParseTapFormat(%q) = %v, %v, want %v", f.String(), got, err, f)
+		}
+	}
+	if _, err := ParseTapFormat("binary"); err == nil {
+		t.Error("ParseTapFormat(binary) succeeded, want error")
+	}
+
+	config := DefaultConfig()
+	if err := WithTrafficTap(nil, TapRaw)(&config); err == nil {
+		t.Error("WithTrafficTap(nil) succeeded, want error")
+	}
+	if err := WithTrafficTap(&bytes.Buffer{}, TapFormat(9))(&config); err == nil {
+		t.Error("WithTrafficTap with unknown format succeeded, want error")
+	}
+}
+
+func TestTrafficTapFormats(t *testing.T) {
+	tests := []struct {
+		format TapFormat
+		want   string // Regexp for the output of one TX chunk
+	}{
+		{TapRaw, `^AT\r$`},
+		{TapHex, `^\d\d:\d\d:\d\d\.\d{6} TX 41 54 0d\n$`},
+		{TapHexdump, `^\d\d:\d\d:\d\d\.\d{6} TX 3 bytes\n00000000  41 54 0d +\|AT\.\|\n$`},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		tap := &trafficTap{w: &buf, format: tt.format}
+		tap.tx([]byte("AT\r"))
+		tap.rx(nil) // Empty chunks are not mirrored
+		if !regexp.MustCompile(tt.want).MatchString(buf.String()) {
+			t.Errorf("%v output = %q, want match for %q", tt.format, buf.String(), tt.want)
+		}
+	}
+}
+
+func TestTrafficTapPort(t *testing.T) {
+	master, slavePath, err := OpenPTY()
+	if err != nil {
+		t.Skipf("PTY not available: %v", err)
+	}
+	defer master.Close()
+
+	var buf bytes.Buffer
+	port, err := Open(slavePath, WithReadTimeout(100*time.Millisecond), WithTrafficTap(&buf, TapHex))
+	if err != nil {
+		t.Fatalf("Open(%s) failed: %v", slavePath, err)
+	}
+	defer port.Close()
+
+	if _, err := port.Write([]byte("hi")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	master.Write([]byte("ok"))
+	if _, err := port.Read(make([]byte, 16)); err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], "TX 68 69") || !strings.HasSuffix(lines[1], "RX 6f 6b") {
+		t.Errorf("tap output = %q, want TX 68 69 then RX 6f 6b", buf.String())
+	}
+}
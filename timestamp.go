@@ -0,0 +1,28 @@
+package serial
+
+import (
+	"context"
+	"time"
+)
+
+// TimestampedReader is implemented by ports that record when read data arrived
+// Serial ports take the timestamp as the read syscall returns and network
+// ports when the data came off the connection, so it excludes the goroutine
+// and channel hops between the device and the caller.
+type TimestampedReader interface {
+	// ReadTimestamped is ReadContext that also returns when the data arrived
+	ReadTimestamped(ctx context.Context, buf []byte) (int, time.Time, error)
+}
+
+// ReadTimestamped reads from p like ReadContext and returns when the data arrived
+// Ports that do not implement TimestampedReader are stamped with time.Now
+// after the read. The time carries Go's monotonic clock reading, so
+// differences between timestamps are unaffected by NTP steps; use Sub, not
+// wall-clock arithmetic, for latency analysis.
+func ReadTimestamped(ctx context.Context, p Port, buf []byte) (int, time.Time, error) {
+	if tr, ok := p.(TimestampedReader); ok {
+		return tr.ReadTimestamped(ctx, buf)
+	}
+	n, err := p.ReadContext(ctx, buf)
+	return n, time.Now(), err
+}
@@ -0,0 +1,43 @@
+package serial
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestReadTimestamped(t *testing.T) {
+	master, slavePath, err := OpenPTY()
+	if err != nil {
+		t.Skipf("PTY not available: %v", err)
+	}
+	defer master.Close()
+
+	port, err := Open(slavePath, WithReadTimeout(500*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Open(%s) failed: %v", slavePath, err)
+	}
+	defer port.Close()
+
+	tests := []struct {
+		name string
+		port Port
+	}{
+		{"TimestampedReader", port},
+		{"fallback", struct{ Port }{port}}, // Hides ReadTimestamped
+	}
+	for _, tt := range tests {
+		before := time.Now()
+		master.Write([]byte("ok"))
+
+		buf := make([]byte, 16)
+		n, at, err := ReadTimestamped(context.Background(), tt.port, buf)
+		after := time.Now()
+		if err != nil || string(buf[:n]) != "ok" {
+			t.Fatalf("%s: ReadTimestamped = %q, %v, want \"ok\"", tt.name, buf[:n], err)
+		}
+		if at.Before(before) || at.After(after) {
+			t.Errorf("%s: timestamp %v outside [%v, %v]", tt.name, at, before, after)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package serial
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// WithTrace writes a line to w for every termios and modem-control ioctl the
+// port makes, with the resulting signal states
+// Lines start with monotonic seconds since the port was opened, so gaps such
+// as a write waiting 480ms for CTS can be read off directly:
+//
+//	0.000112 TCSETS cflag=0x10bd iflag=0x0 oflag=0x0 lflag=0x0 speed=115200 vmin=0 vtime=25
+//	0.000131 TIOCMGET -> RTS|DTR
+//	0.002087 TIOCMIWAIT CTS waiting
+//	0.482301 TIOCMIWAIT CTS -> woke after 480.214ms
+//	0.482318 TIOCMGET -> CTS|RTS|DTR
+//
+// Tracing is meant for debugging; it costs a formatted write per ioctl.
+func WithTrace(w io.Writer) Option {
+	return func(c *Config) error {
+		if w == nil {
+			return ErrInvalidConfig
+		}
+		c.Trace = w
+		return nil
+	}
+}
+
+// ioTracer writes ioctl trace lines; a nil *ioTracer traces nothing
+type ioTracer struct {
+	mu    sync.Mutex
+	w     io.Writer
+	start time.Time // Carries the monotonic clock reading
+}
+
+func newIOTracer(config Config) *ioTracer {
+	if config.Trace == nil {
+		return nil
+	}
+	return &ioTracer{w: config.Trace, start: time.Now()}
+}
+
+// log writes op followed by its result, or by err if it failed
+func (t *ioTracer) log(op string, err error, format string, args ...any) {
+	elapsed := time.Since(t.start).Seconds()
+	result := fmt.Sprintf(format, args...)
+	if err != nil {
+		result = "-> error: " + err.Error()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fmt.Fprintf(t.w, "%10.6f %s %s\n", elapsed, op, result)
+}
+
+// formatTIOCM lists the asserted modem lines in status
+func formatTIOCM(status int) string {
+	var names []string
+	for _, line := range []struct {
+		bit  int
+		name string
+	}{
+		{unix.TIOCM_CTS, "CTS"}, {unix.TIOCM_DSR, "DSR"}, {unix.TIOCM_RI, "RI"},
+		{unix.TIOCM_CAR, "DCD"}, {unix.TIOCM_RTS, "RTS"}, {unix.TIOCM_DTR, "DTR"},
+	} {
+		if status&line.bit != 0 {
+			names = append(names, line.name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, "|")
+}
+
+// tracedRates are the rates getBaudRate accepts, for naming CBAUD codes
+var tracedRates = []int{
+	50, 75, 110, 134, 150, 200, 300, 600, 1200, 1800, 2400, 4800, 9600, 19200, 38400,
+	57600, 115200, 230400, 460800, 500000, 576000, 921600, 1000000, 1152000, 1500000,
+	2000000, 2500000, 3000000, 3500000, 4000000,
+}
+
+func formatTermios(t *unix.Termios) string {
+	speed := fmt.Sprintf("%#x", t.Cflag&unix.CBAUD)
+	if rate := baudRateFromCode(t.Cflag & unix.CBAUD); rate != 0 {
+		speed = fmt.Sprint(rate)
+	}
+	return fmt.Sprintf("cflag=%#x iflag=%#x oflag=%#x lflag=%#x speed=%s vmin=%d vtime=%d",
+		t.Cflag, t.Iflag, t.Oflag, t.Lflag, speed, t.Cc[unix.VMIN], t.Cc[unix.VTIME])
+}
+
+// The functions below wrap each ioctl the port uses and trace it when tr is set
+
+func tiocmget(tr *ioTracer, fd int) (int, error) {
+	status, err := unix.IoctlGetInt(fd, unix.TIOCMGET)
+	if tr != nil {
+		tr.log("TIOCMGET", err, "-> %s", formatTIOCM(status))
+	}
+	return status, err
+}
+
+func tiocmset(tr *ioTracer, fd int, status int) error {
+	err := unix.IoctlSetPointerInt(fd, unix.TIOCMSET, status)
+	if tr != nil {
+		tr.log("TIOCMSET", err, "%s", formatTIOCM(status))
+	}
+	return err
+}
+
+func tiocmbis(tr *ioTracer, fd int, bits int) error {
+	err := unix.IoctlSetInt(fd, unix.TIOCMBIS, bits)
+	if tr != nil {
+		tr.log("TIOCMBIS", err, "%s", formatTIOCM(bits))
+	}
+	return err
+}
+
+// tiocmiwait blocks until one of the lines in bits changes
+func tiocmiwait(tr *ioTracer, fd int, bits int) error {
+	if tr == nil {
+		return unix.IoctlSetInt(fd, unix.TIOCMIWAIT, bits)
+	}
+
+	op := "TIOCMIWAIT " + formatTIOCM(bits)
+	tr.log(op, nil, "waiting")
+	start := time.Now()
+	err := unix.IoctlSetInt(fd, unix.TIOCMIWAIT, bits)
+	tr.log(op, err, "-> woke after %v", time.Since(start).Round(time.Microsecond))
+	return err
+}
+
+func tcgets(tr *ioTracer, fd int) (*unix.Termios, error) {
+	termios, err := unix.IoctlGetTermios(fd, unix.TCGETS)
+	if tr != nil {
+		if err != nil {
+			tr.log("TCGETS", err, "")
+		} else {
+			tr.log("TCGETS", nil, "-> %s", formatTermios(termios))
+		}
+	}
+	return termios, err
+}
+
+func tcsets(tr *ioTracer, fd int, termios *unix.Termios) error {
+	err := unix.IoctlSetTermios(fd, unix.TCSETS, termios)
+	if tr != nil {
+		tr.log("TCSETS", err, "%s", formatTermios(termios))
+	}
+	return err
+}
+
+func tcflsh(tr *ioTracer, fd int, queue int) error {
+	err := unix.IoctlSetInt(fd, unix.TCFLSH, queue)
+	if tr != nil {
+		name := map[int]string{unix.TCIFLUSH: "input", unix.TCOFLUSH: "output", unix.TCIOFLUSH: "both"}[queue]
+		tr.log("TCFLSH", err, "%s", name)
+	}
+	return err
+}
+
+func tiocsbrk(tr *ioTracer, fd int) error {
+	err := unix.IoctlSetInt(fd, unix.TIOCSBRK, 0)
+	if tr != nil {
+		tr.log("TIOCSBRK", err, "break on")
+	}
+	return err
+}
+
+func tioccbrk(tr *ioTracer, fd int) error {
+	err := unix.IoctlSetInt(fd, unix.TIOCCBRK, 0)
+	if tr != nil {
+		tr.log("TIOCCBRK", err, "break off")
+	}
+	return err
+}
+
+// tcdrain waits until output written to fd has been transmitted
+// On Linux tcdrain(3) is TCSBRK with a non-zero argument: the kernel waits
+// for the tty buffer and the driver's wait_until_sent, which for a UART
+// covers its FIFO and shift register, and skips the break that an argument
+// of 0 would send. Go installs its signal handlers with SA_RESTART, so the
+// kernel restarts the wait after a signal; retrying on EINTR is defensive,
+// for handlers installed without SA_RESTART by cgo or other non-Go code.
+func tcdrain(tr *ioTracer, fd int) error {
+	if tr == nil {
+		return ignoringEINTR(func() error {
+			return unix.IoctlSetInt(fd, unix.TCSBRK, 1)
+		})
+	}
+
+	start := time.Now()
+	err := ignoringEINTR(func() error {
+		return unix.IoctlSetInt(fd, unix.TCSBRK, 1)
+	})
+	tr.log("TCSBRK", err, "1 (drain) -> returned after %v", time.Since(start).Round(time.Microsecond))
+	return err
+}
+
+// ignoringEINTR calls fn until it fails with an error other than EINTR
+func ignoringEINTR(fn func() error) error {
+	for {
+		if err := fn(); err != unix.EINTR {
+			return err
+		}
+	}
+}
+
+// tiocinq returns the number of received bytes waiting to be read
+func tiocinq(tr *ioTracer, fd int) (int, error) {
+	n, err := unix.IoctlGetInt(fd, unix.TIOCINQ)
+	if tr != nil {
+		tr.log("TIOCINQ", err, "-> %d", n)
+	}
+	return n, err
+}
+
+// tiocoutq returns the number of written bytes not yet transmitted
+func tiocoutq(tr *ioTracer, fd int) (int, error) {
+	n, err := unix.IoctlGetInt(fd, unix.TIOCOUTQ)
+	if tr != nil {
+		tr.log("TIOCOUTQ", err, "-> %d", n)
+	}
+	return n, err
+}
+
+// serialIcounter mirrors the kernel's struct serial_icounter_struct
+type serialIcounter struct {
+	cts, dsr, rng, dcd     int32
+	rx, tx                 int32
+	frame, overrun, parity int32
+	brk, bufOverrun        int32
+	reserved               [9]int32
+}
+
+func tiocgicount(tr *ioTracer, fd int) (serialIcounter, error) {
+	var ic serialIcounter
+	var err error
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), unix.TIOCGICOUNT, uintptr(unsafe.Pointer(&ic))); errno != 0 {
+		err = errno
+	}
+	if tr != nil {
+		tr.log("TIOCGICOUNT", err, "-> rx=%d tx=%d frame=%d overrun=%d parity=%d brk=%d", ic.rx, ic.tx, ic.frame, ic.overrun, ic.parity, ic.brk)
+	}
+	return ic, err
+}
@@ -0,0 +1,65 @@
+package serial
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestFormatTIOCM(t *testing.T) {
+	tests := []struct {
+		status int
+		want   string
+	}{
+		{0, "none"},
+		{unix.TIOCM_CTS, "CTS"},
+		{unix.TIOCM_CTS | unix.TIOCM_RTS | unix.TIOCM_DTR, "CTS|RTS|DTR"},
+		{unix.TIOCM_CAR | unix.TIOCM_RI, "RI|DCD"},
+	}
+	for _, tt := range tests {
+		if got := formatTIOCM(tt.status); got != tt.want {
+			t.Errorf("formatTIOCM(%#x) = %q, want %q", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestWithTrace(t *testing.T) {
+	config := DefaultConfig()
+	if err := WithTrace(nil)(&config); err == nil {
+		t.Error("WithTrace(nil) succeeded, want error")
+	}
+
+	master, slavePath, err := OpenPTY()
+	if err != nil {
+		t.Skipf("PTY not available: %v", err)
+	}
+	defer master.Close()
+
+	var buf bytes.Buffer
+	port, err := Open(slavePath, WithBaudRate(9600), WithReadTimeout(100*time.Millisecond), WithTrace(&buf))
+	if err != nil {
+		t.Fatalf("Open(%s) failed: %v", slavePath, err)
+	}
+	port.GetModemSignals()
+	port.FlushInput()
+	if err := SendBreak(port, 10*time.Millisecond); err != nil {
+		t.Errorf("SendBreak failed: %v", err)
+	}
+	port.Close()
+
+	line := regexp.MustCompile(`^ *\d+\.\d{6} [A-Z]`)
+	for _, l := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if !line.MatchString(l) {
+			t.Errorf("trace line %q lacks a monotonic timestamp", l)
+		}
+	}
+	for _, want := range []string{"TCGETS", "TCSETS cflag=", "speed=9600 vmin=0 vtime=1", "TIOCMGET", "TCFLSH input", "TCSBRK 1 (drain)", "TIOCSBRK break on", "TIOCCBRK break off"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("trace missing %q:\n%s", want, buf.String())
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package serial
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// Backoff between Transact attempts, doubling after each failure up to the maximum
+const (
+	transactBackoff    = 50 * time.Millisecond
+	maxTransactBackoff = time.Second
+)
+
+// Matcher reports whether the bytes received so far form a complete response
+type Matcher func(response []byte) bool
+
+// MatchSuffix matches responses ending with suffix (e.g., "\r\n" or "OK\r\n")
+func MatchSuffix(suffix []byte) Matcher {
+	return func(response []byte) bool {
+		return bytes.HasSuffix(response, suffix)
+	}
+}
+
+// MatchLength matches responses of at least n bytes (fixed-size replies)
+func MatchLength(n int) Matcher {
+	return func(response []byte) bool {
+		return len(response) >= n
+	}
+}
+
+// MatchRegexp matches responses containing a match for re (e.g., `OK\r\n|ERROR`)
+func MatchRegexp(re *regexp.Regexp) Matcher {
+	return func(response []byte) bool {
+		return re.Match(response)
+	}
+}
+
+// Transact writes request and collects input until match accepts it
+//
+// Each attempt discards stale input, writes the request and reads until match
+// returns true or timeout elapses. Timed-out attempts are retried up to retries
+// times with exponential backoff (negative retries count as none); other
+// errors are returned immediately.
+// Returns the bytes received in the successful attempt, or in the last
+// attempt if none matched.
+//
+// Timeout resolution is bounded by the port's read timeout, since reads are
+// not interrupted mid-call (an interrupted read could swallow the next
+// attempt's response).
+func Transact(ctx context.Context, p Port, request []byte, match Matcher, retries int, timeout time.Duration) ([]byte, error) {
+	retries = max(retries, 0)
+	backoff := transactBackoff
+	var lastResp []byte
+	var lastErr error
+
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			timer := time.NewTimer(backoff)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, ctx.Err()
+			}
+			backoff = min(2*backoff, maxTransactBackoff)
+		}
+
+		resp, err := transactOnce(ctx, p, request, match, timeout)
+		if err == nil {
+			return resp, nil
+		}
+		if !errors.Is(err, ErrReadTimeout) {
+			return resp, err
+		}
+		lastResp, lastErr = resp, err
+	}
+
+	return lastResp, fmt.Errorf("no matching response after %d attempts: %w", retries+1, lastErr)
+}
+
+func transactOnce(ctx context.Context, p Port, request []byte, match Matcher, timeout time.Duration) ([]byte, error) {
+	if err := p.FlushInput(); err != nil {
+		return nil, fmt.Errorf("failed to flush stale input: %w", err)
+	}
+	if _, err := p.WriteContext(ctx, request); err != nil {
+		return nil, fmt.Errorf("failed to write request: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	var resp []byte
+	scratch := getScratch()
+	defer putScratch(scratch)
+	chunk := *scratch
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return resp, err
+		}
+		if time.Now().After(deadline) {
+			return resp, fmt.Errorf("%w: %d bytes received without a match", ErrReadTimeout, len(resp))
+		}
+
+		n, err := p.Read(chunk)
+		if n > 0 {
+			resp = append(resp, chunk[:n]...)
+			if match(resp) {
+				return resp, nil
+			}
+		}
+		if err != nil {
+			return resp, err
+		}
+	}
+}
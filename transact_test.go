@@ -0,0 +1,134 @@
+package serial
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMatchers(t *testing.T) {
+	if !MatchSuffix([]byte("\r\n"))([]byte("OK\r\n")) {
+		t.Error("MatchSuffix should match OK\\r\\n")
+	}
+	if MatchSuffix([]byte("\r\n"))([]byte("OK\r")) {
+		t.Error("MatchSuffix should not match partial suffix")
+	}
+	if MatchLength(4)([]byte{1, 2, 3}) || !MatchLength(4)([]byte{1, 2, 3, 4, 5}) {
+		t.Error("MatchLength(4) should match 4 or more bytes only")
+	}
+	if match := MatchRegexp(regexp.MustCompile(`\+CSQ: \d+`)); match([]byte("+CSQ: ")) || !match([]byte("\r\n+CSQ: 17,99\r\n")) {
+		t.Error("MatchRegexp should match once the pattern is complete")
+	}
+}
+
+func TestTransactRetriesAndFlushesStaleInput(t *testing.T) {
+	master, slavePath, err := OpenPTY()
+	if err != nil {
+		t.Skipf("PTY not available: %v", err)
+	}
+	defer master.Close()
+
+	port, err := Open(slavePath, WithReadTimeout(100*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Open(%s) failed: %v", slavePath, err)
+	}
+	defer port.Close()
+
+	// Stale bytes waiting in the input buffer before the transaction
+	master.Write([]byte("stale garbage"))
+	time.Sleep(20 * time.Millisecond)
+
+	// Device ignores the first request and answers the second
+	go func() {
+		r := bufio.NewReader(master)
+		for i := 0; ; i++ {
+			if _, err := r.ReadString('\n'); err != nil {
+				return
+			}
+			if i > 0 {
+				master.Write([]byte("PONG\r\n"))
+			}
+		}
+	}()
+
+	resp, err := Transact(context.Background(), port, []byte("PING\n"), MatchSuffix([]byte("\r\n")), 2, 200*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Transact failed: %v", err)
+	}
+	if string(resp) != "PONG\r\n" {
+		t.Errorf("response = %q, want PONG\\r\\n", resp)
+	}
+}
+
+func TestTransactTimeout(t *testing.T) {
+	master, slavePath, err := OpenPTY()
+	if err != nil {
+		t.Skipf("PTY not available: %v", err)
+	}
+	defer master.Close()
+
+	port, err := Open(slavePath, WithReadTimeout(100*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Open(%s) failed: %v", slavePath, err)
+	}
+	defer port.Close()
+
+	start := time.Now()
+	_, err = Transact(context.Background(), port, []byte("PING\n"), MatchLength(1), 1, 100*time.Millisecond)
+	if !errors.Is(err, ErrReadTimeout) {
+		t.Fatalf("Transact error = %v, want ErrReadTimeout", err)
+	}
+	// Two attempts of ~100ms (plus read timeout granularity) and one 50ms backoff
+	if elapsed := time.Since(start); elapsed < 250*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("Transact took %v, want two attempts with backoff", elapsed)
+	}
+
+	// The last attempt's partial response is returned with the error
+	go func() {
+		master.Read(make([]byte, 16))
+		master.Write([]byte("NOPE"))
+	}()
+	resp, err := Transact(context.Background(), port, []byte("PING\n"), MatchSuffix([]byte("OK")), 0, 300*time.Millisecond)
+	if !errors.Is(err, ErrReadTimeout) || string(resp) != "NOPE" {
+		t.Errorf("Transact = %q, %v, want \"NOPE\" with ErrReadTimeout", resp, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Transact(ctx, port, []byte("PING\n"), MatchLength(1), 3, time.Second); !errors.Is(err, context.Canceled) {
+		t.Errorf("Transact with cancelled context error = %v, want context.Canceled", err)
+	}
+}
+
+func TestTransactNegativeRetries(t *testing.T) {
+	master, slavePath, err := OpenPTY()
+	if err != nil {
+		t.Skipf("PTY not available: %v", err)
+	}
+	defer master.Close()
+
+	port, err := Open(slavePath, WithReadTimeout(100*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Open(%s) failed: %v", slavePath, err)
+	}
+	defer port.Close()
+
+	// Negative retries still make one attempt
+	go func() {
+		master.Read(make([]byte, 16))
+		master.Write([]byte("PONG\r\n"))
+	}()
+	resp, err := Transact(context.Background(), port, []byte("PING\n"), MatchSuffix([]byte("\r\n")), -1, time.Second)
+	if err != nil || string(resp) != "PONG\r\n" {
+		t.Errorf("Transact = %q, %v, want PONG\\r\\n", resp, err)
+	}
+
+	_, err = Transact(context.Background(), port, []byte("PING\n"), MatchLength(1), -3, 100*time.Millisecond)
+	if !errors.Is(err, ErrReadTimeout) || !strings.Contains(err.Error(), "after 1 attempts") {
+		t.Errorf("Transact error = %v, want ErrReadTimeout after 1 attempt", err)
+	}
+}
@@ -1,7 +1,9 @@
 package serial
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"os/exec"
 	"time"
 )
@@ -19,6 +21,33 @@ import (
 // - ErrUSBInfoNotAvailable if device is not USB or metadata unavailable
 // - error if reset fails
 func ResetUSBDevice(portPath string) error {
+	return ResetUSBDeviceContext(context.Background(), portPath)
+}
+
+// ResetUSBDeviceContext is ResetUSBDevice with cancellation and port options
+// Only the options' Logger and Instrumentation are used, so a supervisor can
+// pass the options it opens the port with and see resets in the same trace.
+func ResetUSBDeviceContext(ctx context.Context, portPath string, opts ...Option) error {
+	config := DefaultConfig()
+	for _, opt := range opts {
+		if err := opt(&config); err != nil {
+			return err
+		}
+	}
+
+	ctx, end := config.startSpan(ctx, SpanUSBReset, slog.String("device", portPath))
+	err := resetUSBDevice(ctx, portPath)
+	countError(config.Instrumentation, ctx, err)
+	end(err)
+	if err != nil {
+		config.Logger.Warn("USB reset failed", "device", portPath, "err", err)
+	} else {
+		config.Logger.Info("USB device reset", "device", portPath)
+	}
+	return err
+}
+
+func resetUSBDevice(ctx context.Context, portPath string) error {
 	// Get port info to find USB bus/device numbers
 	info, err := GetPortInfo(portPath)
 	if err != nil {
@@ -39,16 +68,19 @@ func ResetUSBDevice(portPath string) error {
 	usbPath := fmt.Sprintf("%03s/%03s", info.BusNumber, info.DeviceNumber)
 
 	// Execute USB reset
-	cmd := exec.Command("usbreset", usbPath)
+	cmd := exec.CommandContext(ctx, "usbreset", usbPath)
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("usbreset failed: %w (output: %s)", err, string(output))
 	}
 
 	// Wait for device to re-enumerate
 	// USB devices typically take 1-2 seconds to become available again
-	time.Sleep(2 * time.Second)
-
-	return nil
+	select {
+	case <-time.After(2 * time.Second):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 // ResetUSBDeviceBySerial resets a USB device by its serial number
@@ -0,0 +1,197 @@
+package serial
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	virtualPairBitsPerChar = 10 // On-wire size of a byte (8N1) used for throttling
+	virtualPairBufferSize  = 4096
+)
+
+// VirtualPairOption configures a VirtualPair
+type VirtualPairOption func(*virtualPairConfig)
+
+type virtualPairConfig struct {
+	baudRate  int
+	ctsOpen   time.Duration
+	ctsClosed time.Duration
+}
+
+// WithPairBaudRate limits throughput in each direction to what a UART at rate
+// (8N1) could carry. Zero, the default, forwards data as fast as possible.
+func WithPairBaudRate(rate int) VirtualPairOption {
+	return func(c *virtualPairConfig) {
+		c.baudRate = rate
+	}
+}
+
+// WithPairCTSWindow simulates a device that periodically deasserts CTS:
+// data flows for open, then is held back for closed, repeating. Held data is
+// delivered when the next window opens, as with hardware flow control.
+func WithPairCTSWindow(open, closed time.Duration) VirtualPairOption {
+	return func(c *virtualPairConfig) {
+		c.ctsOpen = open
+		c.ctsClosed = closed
+	}
+}
+
+// VirtualPair is a null modem made of two linked PTYs
+// Bytes written to PathA can be read from PathB and vice versa. Both paths
+// can be opened with Open (or any other program) and reopened as often as
+// needed while the pair exists. Modem signal ioctls are not supported on PTYs.
+type VirtualPair struct {
+	PathA string
+	PathB string
+
+	config  virtualPairConfig
+	masters [2]*os.File
+	holders [2]*os.File // Keep the slaves open so master reads never fail with EIO
+	start   time.Time
+
+	done      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// OpenVirtualPair creates two raw-mode PTYs and starts forwarding between them
+func OpenVirtualPair(opts ...VirtualPairOption) (*VirtualPair, error) {
+	var config virtualPairConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if config.baudRate < 0 {
+		return nil, fmt.Errorf("%w: baud rate %d", ErrInvalidConfig, config.baudRate)
+	}
+	if config.ctsOpen < 0 || config.ctsClosed < 0 || (config.ctsClosed > 0 && config.ctsOpen == 0) {
+		return nil, fmt.Errorf("%w: CTS window must have a positive open time", ErrInvalidConfig)
+	}
+
+	v := &VirtualPair{
+		config: config,
+		start:  time.Now(),
+		done:   make(chan struct{}),
+	}
+	paths := [2]string{}
+	for i := range v.masters {
+		master, path, err := OpenPTY()
+		if err != nil {
+			v.closeFiles()
+			return nil, err
+		}
+		v.masters[i] = master
+		paths[i] = path
+
+		holder, err := os.OpenFile(path, os.O_RDWR|unix.O_NOCTTY, 0)
+		if err != nil {
+			v.closeFiles()
+			return nil, fmt.Errorf("failed to open %s: %w", path, err)
+		}
+		v.holders[i] = holder
+	}
+	v.PathA, v.PathB = paths[0], paths[1]
+
+	v.wg.Add(2)
+	go v.forward(v.masters[0], v.masters[1])
+	go v.forward(v.masters[1], v.masters[0])
+
+	return v, nil
+}
+
+// Close stops forwarding and removes both PTYs
+// Programs that still have a path open will see their reads fail.
+func (v *VirtualPair) Close() error {
+	v.closeOnce.Do(func() {
+		close(v.done)
+		v.closeFiles()
+		v.wg.Wait()
+	})
+	return nil
+}
+
+func (v *VirtualPair) closeFiles() {
+	for _, f := range append(v.masters[:], v.holders[:]...) {
+		if f != nil {
+			f.Close()
+		}
+	}
+}
+
+// forward copies bytes written to src's slave into dst's slave
+func (v *VirtualPair) forward(src, dst *os.File) {
+	defer v.wg.Done()
+
+	var charTime time.Duration
+	chunkSize := virtualPairBufferSize
+	if v.config.baudRate > 0 {
+		charTime = time.Second * virtualPairBitsPerChar / time.Duration(v.config.baudRate)
+		// Deliver in roughly 10ms slices so throughput is smooth rather than bursty
+		chunkSize = max(1, int(10*time.Millisecond/charTime))
+	}
+
+	buf := make([]byte, virtualPairBufferSize)
+	var next time.Time // When the line is free for the next chunk
+	for {
+		n, err := src.Read(buf)
+		if err != nil {
+			return
+		}
+
+		data := buf[:n]
+		for len(data) > 0 {
+			if !v.waitCTS() {
+				return
+			}
+
+			chunk := data[:min(len(data), chunkSize)]
+			if charTime > 0 {
+				if now := time.Now(); next.Before(now) {
+					next = now
+				}
+				next = next.Add(time.Duration(len(chunk)) * charTime)
+				if !v.sleep(time.Until(next)) {
+					return
+				}
+			}
+
+			if _, err := dst.Write(chunk); err != nil {
+				return
+			}
+			data = data[len(chunk):]
+		}
+	}
+}
+
+// waitCTS blocks while the simulated CTS window is closed
+func (v *VirtualPair) waitCTS() bool {
+	if v.config.ctsClosed == 0 {
+		return true
+	}
+	period := v.config.ctsOpen + v.config.ctsClosed
+	phase := time.Since(v.start) % period
+	if phase < v.config.ctsOpen {
+		return true
+	}
+	return v.sleep(period - phase)
+}
+
+// sleep waits for d, returning false if the pair is closed meanwhile
+func (v *VirtualPair) sleep(d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-v.done:
+		return false
+	}
+}
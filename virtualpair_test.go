@@ -0,0 +1,105 @@
+package serial
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+// openPairPorts opens both ends of a virtual pair as Ports
+func openPairPorts(t *testing.T, opts ...VirtualPairOption) (Port, Port) {
+	t.Helper()
+
+	pair, err := OpenVirtualPair(opts...)
+	if err != nil {
+		t.Skipf("PTY not available: %v", err)
+	}
+	t.Cleanup(func() { pair.Close() })
+
+	a, err := Open(pair.PathA, WithReadTimeout(100*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Open(%s) failed: %v", pair.PathA, err)
+	}
+	t.Cleanup(func() { a.Close() })
+	b, err := Open(pair.PathB, WithReadTimeout(100*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Open(%s) failed: %v", pair.PathB, err)
+	}
+	t.Cleanup(func() { b.Close() })
+
+	return a, b
+}
+
+// readN reads until n bytes arrive or the deadline passes
+func readN(t *testing.T, p Port, n int, timeout time.Duration) []byte {
+	t.Helper()
+
+	var got []byte
+	buf := make([]byte, 256)
+	for deadline := time.Now().Add(timeout); len(got) < n && time.Now().Before(deadline); {
+		m, err := p.Read(buf)
+		if err != nil {
+			t.Fatalf("Read failed: %v", err)
+		}
+		got = append(got, buf[:m]...)
+	}
+	return got
+}
+
+func TestVirtualPair(t *testing.T) {
+	a, b := openPairPorts(t)
+
+	want := []byte{0x00, 0x0A, 0x0D, 0x11, 0x13, 0xFF}
+	if _, err := a.Write(want); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if got := readN(t, b, len(want), time.Second); !bytes.Equal(got, want) {
+		t.Errorf("B read % X, want % X", got, want)
+	}
+
+	if _, err := b.Write([]byte("pong")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if got := readN(t, a, 4, time.Second); string(got) != "pong" {
+		t.Errorf("A read %q, want pong", got)
+	}
+}
+
+func TestVirtualPairBaudRate(t *testing.T) {
+	// 50 bytes at 2400 baud (8N1) take about 208ms
+	a, b := openPairPorts(t, WithPairBaudRate(2400))
+
+	want := bytes.Repeat([]byte("x"), 50)
+	start := time.Now()
+	if _, err := a.Write(want); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	got := readN(t, b, len(want), 2*time.Second)
+	elapsed := time.Since(start)
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("B read %d bytes, want %d", len(got), len(want))
+	}
+	if elapsed < 180*time.Millisecond {
+		t.Errorf("transfer took %v, want at least 180ms at 2400 baud", elapsed)
+	}
+}
+
+func TestVirtualPairInvalidOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  VirtualPairOption
+	}{
+		{"negative baud", WithPairBaudRate(-1)},
+		{"closed window without open time", WithPairCTSWindow(0, time.Second)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := OpenVirtualPair(tt.opt); !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("OpenVirtualPair error = %v, want ErrInvalidConfig", err)
+			}
+		})
+	}
+}
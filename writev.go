@@ -0,0 +1,101 @@
+package serial
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net"
+
+	"golang.org/x/sys/unix"
+)
+
+// iovMax is IOV_MAX on Linux: writev(2) fails with EINVAL when given more
+// buffers than this
+const iovMax = 1024
+
+// vectorLen returns the total length of bufs
+func vectorLen(bufs [][]byte) int {
+	total := 0
+	for _, b := range bufs {
+		total += len(b)
+	}
+	return total
+}
+
+// Writev writes bufs with writev(2), in one call per iovMax buffers
+// With FlowControlCTS the buffers are joined and queued like a Write, as
+// CTS gates whole writes. A write of fewer bytes than bufs hold returns
+// io.ErrShortWrite.
+func (p *port) Writev(bufs [][]byte) (n int, err error) {
+	total := vectorLen(bufs)
+	ctx, end := p.config.startSpan(context.Background(), SpanWrite, slog.Int("bytes", total))
+	defer func() {
+		p.config.record(ctx, CounterBytesWritten, n, err)
+		end(err)
+	}()
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return 0, ErrPortClosed
+	}
+	if total == 0 {
+		return 0, nil
+	}
+
+	if p.config.FlowControl == FlowControlCTS && p.ctsMonitor != nil {
+		_, endWait := p.config.startSpan(ctx, SpanCTSWait)
+		n, err = p.ctsMonitor.queueWrite(bytes.Join(bufs, nil), p.config.CTSTimeout)
+		endWait(err)
+		return n, err
+	}
+
+	for rest := bufs; len(rest) > 0 && err == nil; {
+		chunk := rest[:min(len(rest), iovMax)]
+		rest = rest[len(chunk):]
+
+		var written int
+		written, err = unix.Writev(p.fd, chunk)
+		n += max(written, 0)
+		if err == nil && written < vectorLen(chunk) {
+			err = io.ErrShortWrite
+		}
+	}
+	if n > 0 && p.tap != nil {
+		p.tap.tx(bytes.Join(bufs, nil)[:n])
+	}
+	return n, err
+}
+
+// Writev writes bufs to a raw TCP connection with one writev(2); RFC 2217
+// connections escape the data, which joins the buffers anyway
+func (p *netPort) Writev(bufs [][]byte) (n int, err error) {
+	total := vectorLen(bufs)
+	ctx, end := p.config.startSpan(context.Background(), SpanWrite, slog.Int("bytes", total))
+	defer func() {
+		p.config.record(ctx, CounterBytesWritten, n, err)
+		end(err)
+	}()
+
+	if p.rfc2217 {
+		return p.write(bytes.Join(bufs, nil))
+	}
+	if err := p.checkOpen(); err != nil {
+		return 0, err
+	}
+
+	// WriteTo consumes the net.Buffers it is called on, so not the caller's
+	nb := append(net.Buffers(nil), bufs...)
+	p.writeMu.Lock()
+	written, err := nb.WriteTo(p.conn)
+	p.writeMu.Unlock()
+	if err != nil {
+		return int(written), err
+	}
+	if p.tap != nil {
+		p.tap.tx(bytes.Join(bufs, nil))
+	}
+	return total, nil
+}
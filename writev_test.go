@@ -0,0 +1,100 @@
+package serial
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWritev(t *testing.T) {
+	master, slavePath, err := OpenPTY()
+	if err != nil {
+		t.Skipf("PTY not available: %v", err)
+	}
+	defer master.Close()
+
+	var tap bytes.Buffer
+	port, err := Open(slavePath, WithReadTimeout(100*time.Millisecond), WithTrafficTap(&tap, TapHex))
+	if err != nil {
+		t.Fatalf("Open(%s) failed: %v", slavePath, err)
+	}
+	defer port.Close()
+
+	frame := []byte{0x02, 'h', 'i', 0x2A}
+	n, err := port.Writev([][]byte{frame[:1], nil, frame[1:3], frame[3:]})
+	if err != nil {
+		t.Fatalf("Writev failed: %v", err)
+	}
+	if n != len(frame) {
+		t.Errorf("Writev wrote %d bytes, want %d", n, len(frame))
+	}
+
+	got := make([]byte, len(frame))
+	if _, err := io.ReadFull(master, got); err != nil {
+		t.Fatalf("master read failed: %v", err)
+	}
+	if !bytes.Equal(got, frame) {
+		t.Errorf("master read % X, want % X", got, frame)
+	}
+
+	lines := strings.Split(strings.TrimSpace(tap.String()), "\n")
+	if len(lines) != 1 || !strings.HasSuffix(lines[0], "TX 02 68 69 2a") {
+		t.Errorf("tap output = %q, want one TX line for the Writev", tap.String())
+	}
+
+	if n, err := port.Writev(nil); n != 0 || err != nil {
+		t.Errorf("Writev(nil) = %d, %v, want 0, nil", n, err)
+	}
+
+	port.Close()
+	if _, err := port.Writev([][]byte{frame}); err != ErrPortClosed {
+		t.Errorf("Writev after Close error = %v, want ErrPortClosed", err)
+	}
+}
+
+func TestWritevManyBuffers(t *testing.T) {
+	master, slavePath, err := OpenPTY()
+	if err != nil {
+		t.Skipf("PTY not available: %v", err)
+	}
+	defer master.Close()
+
+	port, err := Open(slavePath, WithReadTimeout(100*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Open(%s) failed: %v", slavePath, err)
+	}
+	defer port.Close()
+
+	// More buffers than one writev(2) accepts
+	bufs := make([][]byte, 2*iovMax+10)
+	var want []byte
+	for i := range bufs {
+		bufs[i] = []byte{byte(i)}
+		want = append(want, byte(i))
+	}
+
+	got := make(chan []byte, 1)
+	go func() {
+		buf := make([]byte, len(want))
+		n, _ := io.ReadFull(master, buf)
+		got <- buf[:n]
+	}()
+
+	n, err := port.Writev(bufs)
+	if err != nil {
+		t.Fatalf("Writev of %d buffers failed: %v", len(bufs), err)
+	}
+	if n != len(want) {
+		t.Errorf("Writev wrote %d bytes, want %d", n, len(want))
+	}
+	select {
+	case data := <-got:
+		if !bytes.Equal(data, want) {
+			t.Errorf("master read %d bytes differing from the %d written", len(data), len(want))
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out reading the written buffers")
+	}
+}
@@ -0,0 +1,181 @@
+package xmodem
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/allbin/go-serial/framing"
+)
+
+// errBadBlock is returned by readBlock for a block damaged in transit
+var errBadBlock = errors.New("damaged block")
+
+// Receive receives one XMODEM transfer over rw into w and returns the
+// number of bytes written
+// It asks for CRC mode unless WithChecksum is given, falling back to the
+// checksum when the sender does not answer three requests. The padding of
+// the last block is written as well, as XMODEM does not send the length.
+func Receive(ctx context.Context, rw io.ReadWriter, w io.Writer, opts ...Option) (int64, error) {
+	c := newConn(ctx, rw, opts)
+	first, crc, err := c.start(!c.opts.checksum, true)
+	if err != nil {
+		return 0, c.fail(err)
+	}
+	n, err := c.receiveData(first, crc, w, Progress{Size: -1})
+	if err != nil {
+		return n, c.fail(err)
+	}
+	return n, nil
+}
+
+// start asks the sender to start until the first byte of a block or EOT
+// arrives, returning it and whether CRC mode was agreed on; with fallback,
+// CRC requests that go unanswered three times fall back to the checksum
+func (c *conn) start(crc, fallback bool) (byte, bool, error) {
+	deadline := time.Now().Add(c.opts.startTimeout)
+	cans := 0
+	for tries := 0; ; tries++ {
+		if crc && fallback && tries == 3 {
+			crc = false
+		}
+		request := byte(NAK)
+		if crc {
+			request = CRC
+		}
+		if err := c.write([]byte{request}); err != nil {
+			return 0, crc, err
+		}
+
+		wait := min(pollInterval, time.Until(deadline))
+		for {
+			b, err := c.readByte(wait)
+			if errors.Is(err, errQuiet) {
+				if time.Now().After(deadline) {
+					return 0, crc, fmt.Errorf("%w waiting for the sender", ErrTimeout)
+				}
+				break
+			}
+			if err != nil {
+				return 0, crc, err
+			}
+			switch b {
+			case SOH, STX, EOT:
+				return b, crc, nil
+			case CAN:
+				if cans++; cans == 2 {
+					return 0, crc, ErrCanceled
+				}
+				continue
+			}
+			cans = 0
+		}
+	}
+}
+
+// readBlock reads the rest of a block started by head, returning its
+// sequence number and data, or errBadBlock if it was damaged
+func (c *conn) readBlock(head byte, crc bool) (byte, []byte, error) {
+	size := 128
+	if head == STX {
+		size = 1024
+	}
+	checkSize := 1
+	if crc {
+		checkSize = 2
+	}
+	block := make([]byte, 2+size+checkSize)
+	if err := c.readFull(block); err != nil {
+		if errors.Is(err, errQuiet) {
+			return 0, nil, errBadBlock
+		}
+		return 0, nil, err
+	}
+	seq, data, check := block[0], block[2:2+size], block[2+size:]
+	if block[1] != ^seq {
+		return 0, nil, errBadBlock
+	}
+	if crc {
+		if binary.BigEndian.Uint16(check) != framing.CRC16XModemSum(data) {
+			return 0, nil, errBadBlock
+		}
+	} else if check[0] != checksum(data) {
+		return 0, nil, errBadBlock
+	}
+	return seq, data, nil
+}
+
+// receiveData receives numbered blocks into w until EOT, starting with the
+// block or EOT that first begins; a progress.Size of 0 or more is the
+// file size, which the data is cut to
+func (c *conn) receiveData(first byte, crc bool, w io.Writer, progress Progress) (int64, error) {
+	expected := byte(1)
+	errs := 0
+	b := first
+	for {
+		switch b {
+		case EOT:
+			return progress.Bytes, c.write([]byte{ACK})
+		case CAN:
+			if next, err := c.readByte(byteTimeout); err == nil && next == CAN {
+				return progress.Bytes, ErrCanceled
+			}
+		case SOH, STX:
+			seq, data, err := c.readBlock(b, crc)
+			switch {
+			case errors.Is(err, errBadBlock):
+				if errs++; errs > c.opts.retries {
+					return progress.Bytes, ErrTooManyRetries
+				}
+				if err := c.purge(); err != nil {
+					return progress.Bytes, err
+				}
+				if err := c.write([]byte{NAK}); err != nil {
+					return progress.Bytes, err
+				}
+			case err != nil:
+				return progress.Bytes, err
+			case seq == expected-1:
+				// Our ACK was lost and the sender repeated the block
+				if err := c.write([]byte{ACK}); err != nil {
+					return progress.Bytes, err
+				}
+			case seq != expected:
+				return progress.Bytes, fmt.Errorf("%w: got block %d, want %d", ErrSync, seq, expected)
+			default:
+				if progress.Size >= 0 {
+					data = data[:min(int64(len(data)), progress.Size-progress.Bytes)]
+				}
+				if _, err := w.Write(data); err != nil {
+					return progress.Bytes, err
+				}
+				if err := c.write([]byte{ACK}); err != nil {
+					return progress.Bytes, err
+				}
+				expected++
+				errs = 0
+				progress.Bytes += int64(len(data))
+				c.opts.progress(progress)
+			}
+		}
+
+		var err error
+		b, err = c.readByte(c.opts.timeout)
+		if errors.Is(err, errQuiet) {
+			if errs++; errs > c.opts.retries {
+				return progress.Bytes, fmt.Errorf("%w waiting for block %d", ErrTimeout, expected)
+			}
+			if err := c.write([]byte{NAK}); err != nil {
+				return progress.Bytes, err
+			}
+			b = 0
+			continue
+		}
+		if err != nil {
+			return progress.Bytes, err
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package xmodem
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/allbin/go-serial/framing"
+)
+
+// errNak is returned by waitAck when the receiver asked for a repeat
+var errNak = errors.New("block not acknowledged")
+
+// Send transmits the data of r as one XMODEM transfer over rw
+// The receiver's request picks the 8-bit checksum or CRC-16, and With1K
+// 1024-byte blocks in CRC mode. The last block is padded with SUB.
+func Send(ctx context.Context, rw io.ReadWriter, r io.Reader, opts ...Option) error {
+	c := newConn(ctx, rw, opts)
+	crc, err := c.waitStart()
+	if err != nil {
+		return c.fail(err)
+	}
+	if err := c.sendData(r, Progress{Size: -1}, crc, crc && c.opts.oneK); err != nil {
+		return c.fail(err)
+	}
+	return nil
+}
+
+// waitStart waits for the receiver to ask for a transfer, reporting
+// whether it asked for CRC mode
+func (c *conn) waitStart() (bool, error) {
+	deadline := time.Now().Add(c.opts.startTimeout)
+	cans := 0
+	for {
+		b, err := c.readByte(time.Until(deadline))
+		if err != nil {
+			return false, timeoutError(err, "the receiver")
+		}
+		switch b {
+		case CRC:
+			return true, nil
+		case NAK:
+			return false, nil
+		case CAN:
+			if cans++; cans == 2 {
+				return false, ErrCanceled
+			}
+			continue
+		}
+		cans = 0
+	}
+}
+
+// sendData sends the data of r in numbered blocks followed by EOT
+func (c *conn) sendData(r io.Reader, progress Progress, crc, oneK bool) error {
+	blockSize := 128
+	if oneK {
+		blockSize = 1024
+	}
+	buf := make([]byte, blockSize)
+	seq := byte(1)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			// A short last block goes in a 128-byte block to save padding
+			size := blockSize
+			if n <= 128 {
+				size = 128
+			}
+			data := buf[:size]
+			for i := n; i < size; i++ {
+				data[i] = SUB
+			}
+			if err := c.sendBlock(encodeBlock(seq, data, crc)); err != nil {
+				return err
+			}
+			seq++
+			progress.Bytes += int64(n)
+			c.opts.progress(progress)
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return c.sendEOT()
+}
+
+// sendEOT ends a file; receivers may NAK the first EOT to make sure
+func (c *conn) sendEOT() error {
+	for range c.opts.retries + 1 {
+		if err := c.write([]byte{EOT}); err != nil {
+			return err
+		}
+		err := c.waitAck()
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, errNak) && !errors.Is(err, errQuiet) {
+			return err
+		}
+	}
+	return ErrTooManyRetries
+}
+
+// sendBlock sends block until the receiver acknowledges it
+func (c *conn) sendBlock(block []byte) error {
+	for range c.opts.retries + 1 {
+		if err := c.write(block); err != nil {
+			return err
+		}
+		err := c.waitAck()
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, errNak) && !errors.Is(err, errQuiet) {
+			return err
+		}
+	}
+	return ErrTooManyRetries
+}
+
+// waitAck waits for the response to a block or EOT: nil for ACK, errNak
+// for NAK and errQuiet for none
+func (c *conn) waitAck() error {
+	deadline := time.Now().Add(c.opts.timeout)
+	cans := 0
+	for {
+		b, err := c.readByte(time.Until(deadline))
+		if err != nil {
+			return err
+		}
+		switch b {
+		case ACK:
+			return nil
+		case NAK:
+			return errNak
+		case CAN:
+			if cans++; cans == 2 {
+				return ErrCanceled
+			}
+			continue
+		}
+		cans = 0
+	}
+}
+
+// encodeBlock frames data, of 128 or 1024 bytes, as block seq
+func encodeBlock(seq byte, data []byte, crc bool) []byte {
+	head := byte(SOH)
+	if len(data) == 1024 {
+		head = STX
+	}
+	b := make([]byte, 0, 3+len(data)+2)
+	b = append(b, head, seq, ^seq)
+	b = append(b, data...)
+	if crc {
+		return binary.BigEndian.AppendUint16(b, framing.CRC16XModemSum(data))
+	}
+	return append(b, checksum(data))
+}
+
+// checksum is the 8-bit sum of original XMODEM
+func checksum(data []byte) byte {
+	var sum byte
+	for _, b := range data {
+		sum += b
+	}
+	return sum
+}
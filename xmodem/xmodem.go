@@ -0,0 +1,217 @@
+// Package xmodem implements the XMODEM and YMODEM file transfer protocols,
+// as spoken by bootloaders, modems and the lrzsz tools (sx, rx, sb, rb).
+//
+// XMODEM sends one unnamed file in 128-byte blocks, or 1024-byte blocks
+// with XMODEM-1K, protected by an 8-bit checksum or a CRC-16. The
+// receiver chooses the check: it starts the transfer by sending 'C' for
+// CRC or NAK for the checksum, and Send follows. XMODEM carries no length,
+// so the received data keeps the SUB (0x1A) padding of the last block.
+//
+// YMODEM sends a batch of named files, each preceded by a header block with
+// its name, size and modification time, so receivers write exact sizes.
+//
+//	port, _ := serial.Open("/dev/ttyUSB0", serial.WithReadTimeout(100*time.Millisecond))
+//	defer port.Close()
+//
+//	f, _ := os.Open("firmware.bin")
+//	err := xmodem.Send(ctx, port, f, xmodem.With1K())
+//
+// The port must return from Read within a short time when no data arrives,
+// as a serial.Port with a read timeout does, so that the protocol timeouts
+// and ctx are honored.
+package xmodem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Protocol control bytes
+const (
+	SOH = 0x01 // Start of a 128-byte block
+	STX = 0x02 // Start of a 1024-byte block
+	EOT = 0x04 // End of transmission
+	ACK = 0x06
+	NAK = 0x15 // Negative acknowledgement; also requests checksum mode
+	CAN = 0x18 // Cancel; two in a row abort the transfer
+	CRC = 'C'  // Requests CRC mode
+	SUB = 0x1A // Padding of the last XMODEM block
+)
+
+// Predefined errors for transfers
+var (
+	ErrCanceled       = errors.New("transfer canceled by peer")
+	ErrTimeout        = errors.New("transfer timed out")
+	ErrTooManyRetries = errors.New("too many retries")
+	ErrSync           = errors.New("block sequence lost")
+)
+
+// Default option values
+const (
+	DefaultRetries      = 10
+	DefaultTimeout      = 10 * time.Second
+	DefaultStartTimeout = 60 * time.Second
+)
+
+// byteTimeout bounds the gap between bytes within a block
+const byteTimeout = time.Second
+
+// pollInterval is how often a receiver repeats its request to start
+const pollInterval = 3 * time.Second
+
+// Progress reports how far a file has been transferred
+type Progress struct {
+	Name  string // File name, empty for XMODEM
+	Bytes int64  // Bytes sent or received so far
+	Size  int64  // Size of the file, or -1 if unknown
+}
+
+// Option configures a transfer
+type Option func(*options)
+
+type options struct {
+	oneK         bool
+	checksum     bool
+	retries      int
+	timeout      time.Duration
+	startTimeout time.Duration
+	progress     func(Progress)
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		retries:      DefaultRetries,
+		timeout:      DefaultTimeout,
+		startTimeout: DefaultStartTimeout,
+		progress:     func(Progress) {},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// With1K sends 1024-byte blocks (XMODEM-1K) when the receiver asked for
+// CRC mode; YMODEM always does
+func With1K() Option {
+	return func(o *options) { o.oneK = true }
+}
+
+// WithChecksum makes a receiver ask for the 8-bit checksum instead of
+// CRC-16, for senders that only know the original XMODEM
+func WithChecksum() Option {
+	return func(o *options) { o.checksum = true }
+}
+
+// WithRetries sets how many times a block is repeated before giving up
+func WithRetries(n int) Option {
+	return func(o *options) { o.retries = n }
+}
+
+// WithTimeout sets how long to wait for an acknowledgement or a block
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) { o.timeout = d }
+}
+
+// WithStartTimeout sets how long to wait for the other side to start
+func WithStartTimeout(d time.Duration) Option {
+	return func(o *options) { o.startTimeout = d }
+}
+
+// WithProgress calls fn after every block, from the transferring goroutine
+func WithProgress(fn func(Progress)) Option {
+	return func(o *options) { o.progress = fn }
+}
+
+// errQuiet is returned by conn reads that time out
+var errQuiet = errors.New("no data")
+
+// conn reads single bytes with timeouts from a port and cancels on errors
+type conn struct {
+	ctx  context.Context
+	rw   io.ReadWriter
+	buf  []byte // Bytes read but not yet consumed
+	read []byte
+	opts options
+}
+
+func newConn(ctx context.Context, rw io.ReadWriter, opts []Option) *conn {
+	return &conn{ctx: ctx, rw: rw, read: make([]byte, 1024), opts: newOptions(opts)}
+}
+
+// readByte returns the next byte, or errQuiet if none arrives in timeout
+func (c *conn) readByte(timeout time.Duration) (byte, error) {
+	deadline := time.Now().Add(timeout)
+	for len(c.buf) == 0 {
+		if err := c.ctx.Err(); err != nil {
+			return 0, err
+		}
+		if time.Now().After(deadline) {
+			return 0, errQuiet
+		}
+		n, err := c.rw.Read(c.read)
+		if n > 0 {
+			c.buf = append(c.buf, c.read[:n]...)
+		} else if err != nil {
+			return 0, err
+		}
+	}
+	b := c.buf[0]
+	c.buf = c.buf[1:]
+	return b, nil
+}
+
+// readFull fills p, allowing byteTimeout between bytes
+func (c *conn) readFull(p []byte) error {
+	for i := range p {
+		b, err := c.readByte(byteTimeout)
+		if err != nil {
+			return err
+		}
+		p[i] = b
+	}
+	return nil
+}
+
+// purge discards input until the line has been quiet for byteTimeout, so
+// the rest of a damaged block is not taken for the start of the next
+func (c *conn) purge() error {
+	for {
+		if _, err := c.readByte(byteTimeout); err != nil {
+			if errors.Is(err, errQuiet) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+func (c *conn) write(p []byte) error {
+	_, err := c.rw.Write(p)
+	return err
+}
+
+// cancel tells the other side to abort, ignoring errors as the transfer
+// has failed already
+func (c *conn) cancel() {
+	c.rw.Write([]byte{CAN, CAN, CAN})
+}
+
+// fail cancels the transfer unless the peer did, and returns err
+func (c *conn) fail(err error) error {
+	if !errors.Is(err, ErrCanceled) {
+		c.cancel()
+	}
+	return err
+}
+
+// timeoutError converts an internal read timeout into ErrTimeout
+func timeoutError(err error, what string) error {
+	if errors.Is(err, errQuiet) {
+		return fmt.Errorf("%w waiting for %s", ErrTimeout, what)
+	}
+	return err
+}
@@ -0,0 +1,285 @@
+package xmodem
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+)
+
+// pipeEnd is one end of an in-memory line whose reads return empty after a
+// short time, like a serial port with a read timeout
+type pipeEnd struct {
+	in      chan []byte
+	out     chan []byte
+	pending []byte
+	// corrupt, if set, may change data before it is written
+	corrupt func([]byte)
+}
+
+func (p *pipeEnd) Read(b []byte) (int, error) {
+	if len(p.pending) == 0 {
+		select {
+		case data := <-p.in:
+			p.pending = data
+		case <-time.After(10 * time.Millisecond):
+			return 0, nil
+		}
+	}
+	n := copy(b, p.pending)
+	p.pending = p.pending[n:]
+	return n, nil
+}
+
+func (p *pipeEnd) Write(b []byte) (int, error) {
+	data := bytes.Clone(b)
+	if p.corrupt != nil {
+		p.corrupt(data)
+	}
+	p.out <- data
+	return len(b), nil
+}
+
+func newLine() (*pipeEnd, *pipeEnd) {
+	a, b := make(chan []byte, 1024), make(chan []byte, 1024)
+	return &pipeEnd{in: a, out: b}, &pipeEnd{in: b, out: a}
+}
+
+func testData(n int) []byte {
+	data := make([]byte, n)
+	rand.New(rand.NewSource(int64(n))).Read(data)
+	return data
+}
+
+// padded returns data padded with SUB to a whole number of blocks, the
+// last of which is a 128-byte block if the rest fits in one
+func padded(data []byte, blockSize int) []byte {
+	out := bytes.Clone(data)
+	rest := len(data) % blockSize
+	switch {
+	case rest == 0:
+	case rest <= 128:
+		out = append(out, bytes.Repeat([]byte{SUB}, 128-rest)...)
+	default:
+		out = append(out, bytes.Repeat([]byte{SUB}, blockSize-rest)...)
+	}
+	return out
+}
+
+// transfer runs Send and Receive against each other
+func transfer(t *testing.T, sender, receiver *pipeEnd, data []byte, sendOpts, recvOpts []Option) ([]byte, error, error) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var sendErr error
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		sendErr = Send(ctx, sender, bytes.NewReader(data), sendOpts...)
+	}()
+	var got bytes.Buffer
+	n, recvErr := Receive(ctx, receiver, &got, recvOpts...)
+	wg.Wait()
+	if recvErr == nil && n != int64(got.Len()) {
+		t.Errorf("Receive returned %d, wrote %d bytes", n, got.Len())
+	}
+	return got.Bytes(), sendErr, recvErr
+}
+
+func TestSendReceive(t *testing.T) {
+	tests := []struct {
+		name      string
+		size      int
+		blockSize int
+		sendOpts  []Option
+		recvOpts  []Option
+	}{
+		{"CRC", 1000, 128, nil, nil},
+		{"checksum", 1000, 128, nil, []Option{WithChecksum()}},
+		{"1K", 3000, 1024, []Option{With1K()}, nil},
+		{"1K short last block", 2100, 1024, []Option{With1K()}, nil},
+		{"1K in checksum mode", 300, 128, []Option{With1K()}, []Option{WithChecksum()}},
+		{"whole blocks", 512, 128, nil, nil},
+		{"empty", 0, 128, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender, receiver := newLine()
+			data := testData(tt.size)
+			got, sendErr, recvErr := transfer(t, sender, receiver, data, tt.sendOpts, tt.recvOpts)
+			if sendErr != nil || recvErr != nil {
+				t.Fatalf("Send: %v, Receive: %v", sendErr, recvErr)
+			}
+			if want := padded(data, tt.blockSize); !bytes.Equal(got, want) {
+				t.Errorf("received %d bytes, want %d", len(got), len(want))
+			}
+		})
+	}
+}
+
+func TestSendReceiveRetry(t *testing.T) {
+	sender, receiver := newLine()
+	blocks := 0
+	sender.corrupt = func(data []byte) {
+		if len(data) > 100 {
+			if blocks++; blocks == 2 {
+				data[50] ^= 0xFF
+			}
+		}
+	}
+	data := testData(500)
+	got, sendErr, recvErr := transfer(t, sender, receiver, data, nil, nil)
+	if sendErr != nil || recvErr != nil {
+		t.Fatalf("Send: %v, Receive: %v", sendErr, recvErr)
+	}
+	if !bytes.Equal(got, padded(data, 128)) {
+		t.Error("received data differs")
+	}
+	if blocks != 5 {
+		t.Errorf("sent %d blocks, want 5 with one repeat", blocks)
+	}
+}
+
+func TestSendCanceled(t *testing.T) {
+	sender, receiver := newLine()
+	receiver.Write([]byte{CRC})
+	receiver.Write([]byte{CAN, CAN})
+	err := Send(context.Background(), sender, bytes.NewReader(testData(300)), WithTimeout(time.Second))
+	if !errors.Is(err, ErrCanceled) {
+		t.Errorf("got %v, want ErrCanceled", err)
+	}
+}
+
+func TestSendTimeout(t *testing.T) {
+	sender, _ := newLine()
+	err := Send(context.Background(), sender, bytes.NewReader(testData(10)), WithStartTimeout(50*time.Millisecond))
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("got %v, want ErrTimeout", err)
+	}
+	// The receiver is told to give up
+	if got := <-sender.out; !bytes.Equal(got, []byte{CAN, CAN, CAN}) {
+		t.Errorf("sent %x, want CAN CAN CAN", got)
+	}
+}
+
+// failingLine fails every read, reporting -1 bytes like a serial port
+// whose read system call failed
+type failingLine struct {
+	io.Writer
+}
+
+var errLineRead = errors.New("read failed")
+
+func (failingLine) Read([]byte) (int, error) { return -1, errLineRead }
+
+func TestReadError(t *testing.T) {
+	err := Send(context.Background(), failingLine{io.Discard}, bytes.NewReader(testData(10)), WithStartTimeout(time.Second))
+	if !errors.Is(err, errLineRead) {
+		t.Errorf("got %v, want the read error", err)
+	}
+}
+
+func TestReceiveContext(t *testing.T) {
+	_, receiver := newLine()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := Receive(ctx, receiver, io.Discard)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want context.DeadlineExceeded", err)
+	}
+}
+
+type bufferCloser struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (b *bufferCloser) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestBatch(t *testing.T) {
+	modTime := time.Unix(1700000000, 0)
+	files := []struct {
+		header Header
+		data   []byte
+	}{
+		{Header{Name: "firmware.bin", Size: 3000, ModTime: modTime}, testData(3000)},
+		{Header{Name: "empty.txt", Size: 0}, nil},
+		{Header{Name: "config.txt", Size: 1024, ModTime: modTime}, testData(1024)},
+	}
+	var send []File
+	for _, f := range files {
+		send = append(send, File{Header: f.header, Data: bytes.NewReader(f.data)})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	sender, receiver := newLine()
+	sendErr := make(chan error, 1)
+	go func() { sendErr <- SendBatch(ctx, sender, send) }()
+
+	var outputs []*bufferCloser
+	got, err := ReceiveBatch(ctx, receiver, func(Header) (io.WriteCloser, error) {
+		out := &bufferCloser{}
+		outputs = append(outputs, out)
+		return out, nil
+	})
+	if err != nil {
+		t.Fatalf("ReceiveBatch: %v", err)
+	}
+	if err := <-sendErr; err != nil {
+		t.Fatalf("SendBatch: %v", err)
+	}
+
+	if len(got) != len(files) {
+		t.Fatalf("received %d files, want %d", len(got), len(files))
+	}
+	for i, f := range files {
+		if got[i] != f.header {
+			t.Errorf("file %d: header %+v, want %+v", i, got[i], f.header)
+		}
+		if !bytes.Equal(outputs[i].Bytes(), f.data) {
+			t.Errorf("file %d: received %d bytes, want %d", i, outputs[i].Len(), len(f.data))
+		}
+		if !outputs[i].closed {
+			t.Errorf("file %d: not closed", i)
+		}
+	}
+}
+
+func TestHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		header Header
+	}{
+		{"full", Header{Name: "a.bin", Size: 12345, ModTime: time.Unix(1700000000, 0)}},
+		{"no time", Header{Name: "a.bin", Size: 7}},
+		{"no size", Header{Name: "a.bin", Size: -1}},
+		{"long name", Header{Name: string(bytes.Repeat([]byte("n"), 200)), Size: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := encodeHeader(tt.header)
+			if len(data) != 128 && len(data) != 1024 {
+				t.Errorf("header block of %d bytes", len(data))
+			}
+			got, err := decodeHeader(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.header {
+				t.Errorf("got %+v, want %+v", got, tt.header)
+			}
+		})
+	}
+}
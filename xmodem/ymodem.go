@@ -0,0 +1,184 @@
+package xmodem
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Header describes a file of a YMODEM batch
+type Header struct {
+	Name    string
+	Size    int64     // Size in bytes, or -1 if unknown
+	ModTime time.Time // Zero if unknown
+}
+
+// File is a file to send with SendBatch
+type File struct {
+	Header
+	Data io.Reader
+}
+
+// SendBatch sends files as one YMODEM batch over rw
+// Data goes in 1024-byte blocks with CRC-16; receivers that ask for the
+// checksum are refused, as YMODEM requires CRC mode.
+func SendBatch(ctx context.Context, rw io.ReadWriter, files []File, opts ...Option) error {
+	c := newConn(ctx, rw, opts)
+	for _, f := range files {
+		if err := c.sendFile(f); err != nil {
+			return c.fail(err)
+		}
+	}
+	// An empty header ends the batch
+	if err := c.waitCRC(); err != nil {
+		return c.fail(err)
+	}
+	if err := c.sendBlock(encodeBlock(0, make([]byte, 128), true)); err != nil {
+		return c.fail(err)
+	}
+	return nil
+}
+
+// sendFile sends the header block of f, then its data
+func (c *conn) sendFile(f File) error {
+	if err := c.waitCRC(); err != nil {
+		return err
+	}
+	if err := c.sendBlock(encodeBlock(0, encodeHeader(f.Header), true)); err != nil {
+		return err
+	}
+	// The receiver asks again to start the data
+	if err := c.waitCRC(); err != nil {
+		return err
+	}
+	return c.sendData(f.Data, Progress{Name: f.Name, Size: f.Size}, true, true)
+}
+
+// waitCRC waits for the receiver to ask for a block in CRC mode
+func (c *conn) waitCRC() error {
+	crc, err := c.waitStart()
+	if err == nil && !crc {
+		err = errors.New("receiver asked for checksum mode, YMODEM needs CRC")
+	}
+	return err
+}
+
+// ReceiveBatch receives a YMODEM batch over rw, writing each file to what
+// create returns for its header and closing it after the last byte
+// Files are cut to the size in their header. It returns the headers of the
+// files received in full.
+func ReceiveBatch(ctx context.Context, rw io.ReadWriter, create func(Header) (io.WriteCloser, error), opts ...Option) ([]Header, error) {
+	c := newConn(ctx, rw, opts)
+	var received []Header
+	for {
+		h, done, err := c.receiveHeader()
+		if err != nil {
+			return received, c.fail(err)
+		}
+		if done {
+			return received, nil
+		}
+
+		w, err := create(h)
+		if err != nil {
+			return received, c.fail(err)
+		}
+		first, _, err := c.start(true, false)
+		if err == nil {
+			_, err = c.receiveData(first, true, w, Progress{Name: h.Name, Size: h.Size})
+		}
+		if cerr := w.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return received, c.fail(err)
+		}
+		received = append(received, h)
+	}
+}
+
+// receiveHeader asks for and acknowledges the next header block, reporting
+// true for the empty header that ends the batch
+func (c *conn) receiveHeader() (Header, bool, error) {
+	for range c.opts.retries + 1 {
+		first, _, err := c.start(true, false)
+		if err != nil {
+			return Header{}, false, err
+		}
+		if first == EOT {
+			// The sender repeated the EOT of the previous file
+			if err := c.write([]byte{ACK}); err != nil {
+				return Header{}, false, err
+			}
+			continue
+		}
+		seq, data, err := c.readBlock(first, true)
+		if errors.Is(err, errBadBlock) || (err == nil && seq != 0) {
+			if err := c.purge(); err != nil {
+				return Header{}, false, err
+			}
+			continue
+		}
+		if err != nil {
+			return Header{}, false, err
+		}
+		if err := c.write([]byte{ACK}); err != nil {
+			return Header{}, false, err
+		}
+		if data[0] == 0 {
+			return Header{}, true, nil
+		}
+		h, err := decodeHeader(data)
+		return h, false, err
+	}
+	return Header{}, false, ErrTooManyRetries
+}
+
+// encodeHeader builds the data of block 0: the name, a NUL, then the
+// decimal size and octal modification time, padded with NULs
+func encodeHeader(h Header) []byte {
+	b := append([]byte(h.Name), 0)
+	if h.Size >= 0 {
+		b = strconv.AppendInt(b, h.Size, 10)
+		if !h.ModTime.IsZero() {
+			b = append(b, ' ')
+			b = strconv.AppendInt(b, h.ModTime.Unix(), 8)
+		}
+	}
+	size := 128
+	if len(b) >= size {
+		size = 1024
+	}
+	return append(b, make([]byte, size-len(b))...)
+}
+
+// decodeHeader parses the data of block 0; fields after the modification
+// time, such as the mode, are ignored
+func decodeHeader(data []byte) (Header, error) {
+	h := Header{Size: -1}
+	name, rest, ok := bytes.Cut(data, []byte{0})
+	if !ok || len(name) == 0 {
+		return h, fmt.Errorf("invalid YMODEM header")
+	}
+	h.Name = string(name)
+	info, _, _ := bytes.Cut(rest, []byte{0})
+	fields := strings.Fields(string(info))
+	if len(fields) > 0 {
+		size, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return h, fmt.Errorf("invalid YMODEM file size %q", fields[0])
+		}
+		h.Size = size
+	}
+	if len(fields) > 1 {
+		if mtime, err := strconv.ParseInt(fields[1], 8, 64); err == nil && mtime > 0 {
+			h.ModTime = time.Unix(mtime, 0)
+		}
+	}
+	return h, nil
+}